@@ -44,8 +44,14 @@ resource "intune_settings_catalog_policy" "windows_security" {
 
   # Assign to all devices, excluding test devices
   assignment {
-    include_groups = [data.azuread_group.all_devices.id]
-    exclude_groups = [data.azuread_group.test_devices.id]
+    target {
+      type     = "group"
+      group_id = data.azuread_group.all_devices.id
+    }
+    target {
+      type     = "exclusion"
+      group_id = data.azuread_group.test_devices.id
+    }
   }
 }
 
@@ -147,7 +153,9 @@ resource "intune_compliance_policy" "windows" {
 
   # Assign to all devices
   assignment {
-    all_devices = true
+    target {
+      type = "all_devices"
+    }
   }
 }
 
@@ -28,7 +28,9 @@ resource "intune_settings_catalog_policy" "example" {
 
   # Assign to all devices directly in the policy
   assignment {
-    all_devices = true
+    target {
+      type = "all_devices"
+    }
   }
 }
 
@@ -0,0 +1,61 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+	"github.com/MANCHTOOLS/tofutune/internal/provider"
+)
+
+// runExport implements the export subcommand, which writes the configuration of an existing
+// tenant as HCL with import blocks. Credentials are read from the same ARM_* environment
+// variables the provider uses.
+func runExport(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("output", "", "file to write the configuration to (default: standard output)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: terraform-provider-intune export [-output file]")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "Generates configuration with import blocks for the scope tags, assignment filters and")
+		fmt.Fprintln(flags.Output(), "policies of a tenant. Authentication uses the ARM_* environment variables.")
+		fmt.Fprintln(flags.Output())
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	auth, err := clients.NewAuthenticator(ctx, provider.AuthConfigFromEnv())
+	if err != nil {
+		return fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	graphClient := clients.NewGraphClient(auth, fmt.Sprintf("TofuTune/%s", version), &clients.GraphClientOptions{
+		MaxRetries:   clients.DefaultMaxRetries,
+		MaxRetryWait: clients.DefaultMaxRetryWait,
+		Cloud:        auth.GetCloud(),
+	})
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return provider.NewExporter(graphClient).Export(ctx, w)
+}
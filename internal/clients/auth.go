@@ -6,11 +6,16 @@ package clients
 import (
 	"context"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
@@ -49,22 +54,28 @@ type AuthConfig struct {
 	// OIDC Token File Path for federated authentication
 	OIDCTokenFilePath string
 
-	// OIDC Request URL for federated authentication (GitHub Actions)
+	// OIDC Request URL for federated authentication (GitHub Actions or Azure DevOps)
 	OIDCRequestURL string
 
-	// OIDC Request Token for federated authentication (GitHub Actions)
+	// OIDC Request Token for federated authentication (GitHub Actions or Azure DevOps)
 	OIDCRequestToken string
 
-	// Environment (public, usgovernment, china, germany)
+	// Azure DevOps service connection ID, selects the Azure DevOps OIDC flow when set
+	ADOServiceConnectionID string
+
+	// Environment (public, usgovernment, usgovernmentl4, usgovernmentl5, china)
 	Environment string
 
-	// Custom metadata host
+	// Custom metadata host used to discover the cloud endpoints
 	MetadataHost string
 
 	// Auxiliary Tenant IDs for multi-tenant scenarios
 	AuxiliaryTenantIDs []string
 }
 
+// OIDCTokenExchangeAudience is the audience requested for federated tokens exchanged with Microsoft Entra ID
+const OIDCTokenExchangeAudience = "api://AzureADTokenExchange"
+
 // AuthMethod represents the authentication method being used
 type AuthMethod string
 
@@ -81,6 +92,7 @@ type Authenticator struct {
 	credential azcore.TokenCredential
 	config     *AuthConfig
 	method     AuthMethod
+	cloud      *CloudEnvironment
 }
 
 // NewAuthenticator creates a new authenticator based on the provided configuration
@@ -89,8 +101,14 @@ func NewAuthenticator(ctx context.Context, config *AuthConfig) (*Authenticator,
 		return nil, errors.New("authentication configuration is required")
 	}
 
+	cloud, err := ResolveCloudEnvironment(ctx, config.Environment, config.MetadataHost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve cloud environment: %w", err)
+	}
+
 	auth := &Authenticator{
 		config: config,
+		cloud:  cloud,
 	}
 
 	// Try authentication methods in order of precedence (matching azuread provider)
@@ -100,8 +118,6 @@ func NewAuthenticator(ctx context.Context, config *AuthConfig) (*Authenticator,
 	// 4. Managed Identity
 	// 5. Azure CLI
 
-	var err error
-
 	// Check for OIDC authentication
 	if config.OIDCToken != "" || config.OIDCTokenFilePath != "" || (config.OIDCRequestURL != "" && config.OIDCRequestToken != "") {
 		auth.credential, err = auth.createOIDCCredential(ctx)
@@ -149,7 +165,10 @@ func NewAuthenticator(ctx context.Context, config *AuthConfig) (*Authenticator,
 
 	// If we still don't have a credential, try default credential chain
 	if auth.credential == nil {
-		auth.credential, err = azidentity.NewDefaultAzureCredential(nil)
+		auth.credential, err = azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+			ClientOptions: auth.clientOptions(),
+			TenantID:      config.TenantID,
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to create any authentication credential: %w", err)
 		}
@@ -180,40 +199,40 @@ func (a *Authenticator) GetMethod() AuthMethod {
 	return a.method
 }
 
-// createOIDCCredential creates an OIDC credential for federated authentication
-func (a *Authenticator) createOIDCCredential(ctx context.Context) (azcore.TokenCredential, error) {
-	var token string
+// GetCloud returns the cloud environment the authenticator was configured for
+func (a *Authenticator) GetCloud() *CloudEnvironment {
+	return a.cloud
+}
 
-	// Get token from various sources
-	if a.config.OIDCToken != "" {
-		token = a.config.OIDCToken
-	} else if a.config.OIDCTokenFilePath != "" {
-		tokenBytes, err := os.ReadFile(a.config.OIDCTokenFilePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read OIDC token file: %w", err)
-		}
-		token = strings.TrimSpace(string(tokenBytes))
-	} else if a.config.OIDCRequestURL != "" && a.config.OIDCRequestToken != "" {
-		// GitHub Actions OIDC - fetch token from GitHub's OIDC provider
-		var err error
-		token, err = a.fetchGitHubOIDCToken(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch GitHub OIDC token: %w", err)
-		}
+// clientOptions returns the azcore client options pointing credentials at the configured cloud
+func (a *Authenticator) clientOptions() azcore.ClientOptions {
+	return azcore.ClientOptions{
+		Cloud: a.cloud.AzureCloudConfiguration(),
 	}
+}
 
-	if token == "" {
+// createOIDCCredential creates an OIDC credential for federated authentication.
+// The assertion is obtained again every time the credential requests a new access token,
+// because federated tokens issued by CI/CD systems expire after a few minutes.
+func (a *Authenticator) createOIDCCredential(ctx context.Context) (azcore.TokenCredential, error) {
+	getAssertion := a.oidcAssertion()
+	if getAssertion == nil {
 		return nil, errors.New("no OIDC token available")
 	}
 
+	// Fetch an assertion up front so that a misconfiguration is reported now instead of on first use
+	if _, err := getAssertion(ctx); err != nil {
+		return nil, err
+	}
+
 	// Create the client assertion credential
 	cred, err := azidentity.NewClientAssertionCredential(
 		a.config.TenantID,
 		a.config.ClientID,
-		func(ctx context.Context) (string, error) {
-			return token, nil
+		getAssertion,
+		&azidentity.ClientAssertionCredentialOptions{
+			ClientOptions: a.clientOptions(),
 		},
-		nil,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OIDC credential: %w", err)
@@ -222,16 +241,129 @@ func (a *Authenticator) createOIDCCredential(ctx context.Context) (azcore.TokenC
 	return cred, nil
 }
 
+// oidcAssertion returns the function that obtains the client assertion from the configured
+// source, or nil when no OIDC source is configured
+func (a *Authenticator) oidcAssertion() func(ctx context.Context) (string, error) {
+	switch {
+	case a.config.OIDCToken != "":
+		token := a.config.OIDCToken
+		return func(ctx context.Context) (string, error) {
+			return token, nil
+		}
+	case a.config.OIDCTokenFilePath != "":
+		return a.readOIDCTokenFile
+	case a.config.OIDCRequestURL != "" && a.config.OIDCRequestToken != "":
+		if a.config.ADOServiceConnectionID != "" {
+			// Azure DevOps service connection
+			return a.fetchAzureDevOpsOIDCToken
+		}
+		// GitHub Actions OIDC - fetch token from GitHub's OIDC provider
+		return a.fetchGitHubOIDCToken
+	default:
+		return nil
+	}
+}
+
+// readOIDCTokenFile reads the OIDC token from the configured file. The file is read on every
+// call so that tokens rotated on disk (e.g. Kubernetes workload identity) are picked up.
+func (a *Authenticator) readOIDCTokenFile(ctx context.Context) (string, error) {
+	tokenBytes, err := os.ReadFile(a.config.OIDCTokenFilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read OIDC token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(tokenBytes))
+	if token == "" {
+		return "", fmt.Errorf("OIDC token file %s is empty", a.config.OIDCTokenFilePath)
+	}
+	return token, nil
+}
+
 // fetchGitHubOIDCToken fetches an OIDC token from GitHub Actions
 func (a *Authenticator) fetchGitHubOIDCToken(ctx context.Context) (string, error) {
-	// This would make an HTTP request to GitHub's OIDC provider
-	// For now, we'll return an error indicating this needs implementation
-	// In a real implementation, you would:
-	// 1. Make a GET request to a.config.OIDCRequestURL
-	// 2. Include Authorization header with "bearer " + a.config.OIDCRequestToken
-	// 3. Parse the JSON response to get the "value" field containing the token
-
-	return "", errors.New("GitHub OIDC token fetch not yet implemented - provide token directly via oidc_token")
+	reqURL, err := url.Parse(a.config.OIDCRequestURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse GitHub OIDC request URL: %w", err)
+	}
+	query := reqURL.Query()
+	query.Set("audience", OIDCTokenExchangeAudience)
+	reqURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GitHub OIDC request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+a.config.OIDCRequestToken)
+	req.Header.Set("Accept", "application/json")
+
+	var result struct {
+		Value string `json:"value"`
+	}
+	if err := doOIDCRequest(req, &result); err != nil {
+		return "", fmt.Errorf("failed to fetch GitHub OIDC token: %w", err)
+	}
+	if result.Value == "" {
+		return "", errors.New("failed to fetch GitHub OIDC token: response did not contain a token")
+	}
+
+	return result.Value, nil
+}
+
+// fetchAzureDevOpsOIDCToken fetches an OIDC token for a service connection from Azure DevOps Pipelines
+func (a *Authenticator) fetchAzureDevOpsOIDCToken(ctx context.Context) (string, error) {
+	reqURL, err := url.Parse(a.config.OIDCRequestURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse Azure DevOps OIDC request URL: %w", err)
+	}
+	query := reqURL.Query()
+	query.Set("api-version", "7.1")
+	query.Set("serviceConnectionId", a.config.ADOServiceConnectionID)
+	reqURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Azure DevOps OIDC request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+a.config.OIDCRequestToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	var result struct {
+		OIDCToken string `json:"oidcToken"`
+	}
+	if err := doOIDCRequest(req, &result); err != nil {
+		return "", fmt.Errorf("failed to fetch Azure DevOps OIDC token: %w", err)
+	}
+	if result.OIDCToken == "" {
+		return "", errors.New("failed to fetch Azure DevOps OIDC token: response did not contain a token")
+	}
+
+	return result.OIDCToken, nil
+}
+
+// doOIDCRequest sends a request to an OIDC token endpoint and decodes the JSON response into result
+func doOIDCRequest(req *http.Request, result interface{}) error {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
 }
 
 // createClientSecretCredential creates a client secret credential
@@ -240,7 +372,9 @@ func (a *Authenticator) createClientSecretCredential() (azcore.TokenCredential,
 		a.config.TenantID,
 		a.config.ClientID,
 		a.config.ClientSecret,
-		nil,
+		&azidentity.ClientSecretCredentialOptions{
+			ClientOptions: a.clientOptions(),
+		},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client secret credential: %w", err)
@@ -301,7 +435,9 @@ func (a *Authenticator) createClientCertificateCredential() (azcore.TokenCredent
 		a.config.ClientID,
 		certs,
 		key,
-		nil,
+		&azidentity.ClientCertificateCredentialOptions{
+			ClientOptions: a.clientOptions(),
+		},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client certificate credential: %w", err)
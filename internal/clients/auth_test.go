@@ -0,0 +1,205 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// oidcRequest is a request received by a fake OIDC token endpoint
+type oidcRequest struct {
+	method        string
+	query         map[string]string
+	authorization string
+}
+
+// newOIDCServer returns a token endpoint that answers every request with handler and records
+// the requests it receives. Each request gets a new token, numbered from 1.
+func newOIDCServer(t *testing.T, handler func(w http.ResponseWriter, n int64)) (*httptest.Server, *[]oidcRequest) {
+	t.Helper()
+
+	var requests []oidcRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := make(map[string]string)
+		for key := range r.URL.Query() {
+			query[key] = r.URL.Query().Get(key)
+		}
+		requests = append(requests, oidcRequest{
+			method:        r.Method,
+			query:         query,
+			authorization: r.Header.Get("Authorization"),
+		})
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, int64(len(requests)))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestGitHubOIDCAssertion(t *testing.T) {
+	ctx := context.Background()
+	server, requests := newOIDCServer(t, func(w http.ResponseWriter, n int64) {
+		fmt.Fprintf(w, `{"count": 1, "value": "github-token-%d"}`, n)
+	})
+
+	auth := &Authenticator{config: &AuthConfig{
+		OIDCRequestURL:   server.URL + "/token?api-version=2.0",
+		OIDCRequestToken: "request-token",
+	}}
+	getAssertion := auth.oidcAssertion()
+	if getAssertion == nil {
+		t.Fatalf("expected an assertion source for the GitHub request URL")
+	}
+
+	// Every token refresh fetches a new assertion instead of reusing the first one
+	for i := 1; i <= 2; i++ {
+		token, err := getAssertion(ctx)
+		if err != nil {
+			t.Fatalf("fetching assertion %d: %s", i, err)
+		}
+		if want := fmt.Sprintf("github-token-%d", i); token != want {
+			t.Errorf("expected %q, got %q", want, token)
+		}
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 requests to the token endpoint, got %d", len(*requests))
+	}
+	request := (*requests)[0]
+	if request.method != http.MethodGet {
+		t.Errorf("expected a GET request, got %s", request.method)
+	}
+	if request.query["audience"] != OIDCTokenExchangeAudience {
+		t.Errorf("expected audience %q, got %q", OIDCTokenExchangeAudience, request.query["audience"])
+	}
+	if request.query["api-version"] != "2.0" {
+		t.Errorf("expected the query of the request URL to be kept, got %v", request.query)
+	}
+	if request.authorization != "Bearer request-token" {
+		t.Errorf("expected the request token as bearer token, got %q", request.authorization)
+	}
+}
+
+func TestAzureDevOpsOIDCAssertion(t *testing.T) {
+	ctx := context.Background()
+	server, requests := newOIDCServer(t, func(w http.ResponseWriter, n int64) {
+		fmt.Fprintf(w, `{"oidcToken": "ado-token-%d"}`, n)
+	})
+
+	auth := &Authenticator{config: &AuthConfig{
+		OIDCRequestURL:         server.URL + "/oidctoken",
+		OIDCRequestToken:       "system-access-token",
+		ADOServiceConnectionID: "00000000-0000-0000-0000-0000000000aa",
+	}}
+	getAssertion := auth.oidcAssertion()
+	if getAssertion == nil {
+		t.Fatalf("expected an assertion source for the Azure DevOps request URL")
+	}
+
+	for i := 1; i <= 2; i++ {
+		token, err := getAssertion(ctx)
+		if err != nil {
+			t.Fatalf("fetching assertion %d: %s", i, err)
+		}
+		if want := fmt.Sprintf("ado-token-%d", i); token != want {
+			t.Errorf("expected %q, got %q", want, token)
+		}
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("expected 2 requests to the token endpoint, got %d", len(*requests))
+	}
+	request := (*requests)[0]
+	if request.method != http.MethodPost {
+		t.Errorf("expected a POST request, got %s", request.method)
+	}
+	if request.query["api-version"] != "7.1" || request.query["serviceConnectionId"] != "00000000-0000-0000-0000-0000000000aa" {
+		t.Errorf("unexpected query: %v", request.query)
+	}
+	if _, ok := request.query["audience"]; ok {
+		t.Errorf("expected no audience parameter for Azure DevOps, got %v", request.query)
+	}
+	if request.authorization != "Bearer system-access-token" {
+		t.Errorf("expected the system access token as bearer token, got %q", request.authorization)
+	}
+}
+
+func TestOIDCAssertionErrors(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name         string
+		connectionID string
+		status       int
+		body         string
+		want         string
+	}{
+		{"github error status", "", http.StatusUnauthorized, `{"message": "Bad credentials"}`, `failed to fetch GitHub OIDC token: token endpoint returned status 401: {"message": "Bad credentials"}`},
+		{"github missing token", "", http.StatusOK, `{"count": 0}`, "failed to fetch GitHub OIDC token: response did not contain a token"},
+		{"github invalid body", "", http.StatusOK, `<html>`, "failed to fetch GitHub OIDC token: failed to parse response"},
+		{"ado error status", "connection", http.StatusForbidden, `{"message": "Access denied"}`, `failed to fetch Azure DevOps OIDC token: token endpoint returned status 403: {"message": "Access denied"}`},
+		{"ado missing token", "connection", http.StatusOK, `{"oidcToken": ""}`, "failed to fetch Azure DevOps OIDC token: response did not contain a token"},
+	} {
+		server, _ := newOIDCServer(t, func(w http.ResponseWriter, n int64) {
+			w.WriteHeader(tc.status)
+			fmt.Fprint(w, tc.body)
+		})
+
+		auth := &Authenticator{config: &AuthConfig{
+			OIDCRequestURL:         server.URL,
+			OIDCRequestToken:       "request-token",
+			ADOServiceConnectionID: tc.connectionID,
+		}}
+		_, err := auth.oidcAssertion()(ctx)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestOIDCAssertionSources(t *testing.T) {
+	ctx := context.Background()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A static token takes precedence over the token file and request URL
+	auth := &Authenticator{config: &AuthConfig{
+		OIDCToken:         "static",
+		OIDCTokenFilePath: tokenFile,
+		OIDCRequestURL:    "http://127.0.0.1:1",
+		OIDCRequestToken:  "request-token",
+	}}
+	if token, err := auth.oidcAssertion()(ctx); err != nil || token != "static" {
+		t.Errorf("expected the static token, got %q (%v)", token, err)
+	}
+
+	// The token file is read again on every call so rotated tokens are picked up
+	auth = &Authenticator{config: &AuthConfig{OIDCTokenFilePath: tokenFile}}
+	getAssertion := auth.oidcAssertion()
+	if token, err := getAssertion(ctx); err != nil || token != "first" {
+		t.Errorf("expected the token from the file, got %q (%v)", token, err)
+	}
+	if err := os.WriteFile(tokenFile, []byte("second"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := getAssertion(ctx); err != nil || token != "second" {
+		t.Errorf("expected the rotated token from the file, got %q (%v)", token, err)
+	}
+
+	// A request URL without a request token is not an OIDC source
+	auth = &Authenticator{config: &AuthConfig{OIDCRequestURL: "http://127.0.0.1:1"}}
+	if auth.oidcAssertion() != nil {
+		t.Errorf("expected no assertion source without a request token")
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// MaxBatchSize is the maximum number of requests Graph accepts in a single JSON batch
+	MaxBatchSize = 20
+
+	// PathBatch is the JSON batching endpoint
+	PathBatch = "/$batch"
+)
+
+// BatchRequest is a single request within a JSON batch
+type BatchRequest struct {
+	// ID identifies the request within the batch. When empty, the index of the request is used.
+	ID string
+
+	// Method is the HTTP method of the request
+	Method string
+
+	// Path is the request path relative to the API version, e.g. /deviceManagement/roleScopeTags
+	Path string
+
+	// Body is marshalled as the JSON body of the request
+	Body interface{}
+
+	// DependsOn lists the IDs of earlier requests that must complete before this one is executed
+	DependsOn []string
+}
+
+// BatchResponse is the outcome of a single request within a JSON batch
+type BatchResponse struct {
+	// ID is the ID of the matching request
+	ID string
+
+	// Status is the HTTP status code of the sub-response. Requests that were retried individually
+	// report 200 on success and 0 on failure.
+	Status int
+
+	// Body is the raw sub-response body
+	Body json.RawMessage
+
+	// Err is set when the sub-request failed
+	Err error
+}
+
+// Decode decodes the body of a successful sub-response into v
+func (r *BatchResponse) Decode(v interface{}) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	if len(r.Body) == 0 {
+		return fmt.Errorf("batch request %s returned an empty response", r.ID)
+	}
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		return fmt.Errorf("failed to parse batch response %s: %w", r.ID, err)
+	}
+	return nil
+}
+
+// batchRequestPayload is the wire format of a request within a JSON batch
+type batchRequestPayload struct {
+	ID        string            `json:"id"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Body      interface{}       `json:"body,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	DependsOn []string          `json:"dependsOn,omitempty"`
+}
+
+// batchResponsePayload is the wire format of a response within a JSON batch
+type batchResponsePayload struct {
+	ID      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Batch sends the given requests through the JSON batching endpoint and returns one response
+// per request, in request order. Requests are split into batches of at most MaxBatchSize;
+// requests linked through DependsOn are always kept in the same batch. Sub-requests that were
+// throttled, or failed transiently, are retried individually. An error is only returned when
+// the batch itself could not be executed; failures of single requests are reported through
+// BatchResponse.Err.
+func (c *GraphClient) Batch(ctx context.Context, requests []BatchRequest) ([]BatchResponse, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	requests = append([]BatchRequest(nil), requests...)
+	index := make(map[string]int, len(requests))
+	for i := range requests {
+		if requests[i].ID == "" {
+			requests[i].ID = strconv.Itoa(i + 1)
+		}
+		if _, ok := index[requests[i].ID]; ok {
+			return nil, fmt.Errorf("duplicate batch request id %q", requests[i].ID)
+		}
+		for _, dep := range requests[i].DependsOn {
+			if _, ok := index[dep]; !ok {
+				return nil, fmt.Errorf("batch request %q depends on %q, which is not an earlier request", requests[i].ID, dep)
+			}
+		}
+		index[requests[i].ID] = i
+	}
+
+	chunks, err := splitBatch(requests, index)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]BatchResponse, len(requests))
+	for _, chunk := range chunks {
+		if err := c.sendBatch(ctx, requests, chunk, responses); err != nil {
+			return nil, err
+		}
+	}
+
+	return responses, nil
+}
+
+// splitBatch groups request indexes into batches of at most MaxBatchSize, keeping requests that
+// depend on each other together and preserving request order within a batch
+func splitBatch(requests []BatchRequest, index map[string]int) ([][]int, error) {
+	// Requests linked through dependsOn form a group; each request joins the group of the
+	// first request it depends on, and groups of further dependencies are merged into it.
+	group := make([]int, len(requests))
+	for i := range requests {
+		group[i] = i
+		for _, dep := range requests[i].DependsOn {
+			from, to := group[index[dep]], group[i]
+			for j := 0; j <= i; j++ {
+				if group[j] == to {
+					group[j] = from
+				}
+			}
+		}
+	}
+
+	var order []int
+	members := make(map[int][]int)
+	for i := range requests {
+		if _, ok := members[group[i]]; !ok {
+			order = append(order, group[i])
+		}
+		members[group[i]] = append(members[group[i]], i)
+	}
+
+	var chunks [][]int
+	var current []int
+	for _, g := range order {
+		m := members[g]
+		if len(m) > MaxBatchSize {
+			return nil, fmt.Errorf("batch request %q has a dependency chain of %d requests, which exceeds the maximum of %d", requests[m[0]].ID, len(m), MaxBatchSize)
+		}
+		if len(current)+len(m) > MaxBatchSize {
+			chunks = append(chunks, current)
+			current = nil
+		}
+		current = append(current, m...)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+
+	// Keep the original request order inside each batch so dependencies precede dependents
+	for _, chunk := range chunks {
+		sort.Ints(chunk)
+	}
+
+	return chunks, nil
+}
+
+// sendBatch executes one JSON batch and stores the sub-responses at their request index
+func (c *GraphClient) sendBatch(ctx context.Context, requests []BatchRequest, chunk []int, responses []BatchResponse) error {
+	payload := struct {
+		Requests []batchRequestPayload `json:"requests"`
+	}{}
+	for _, i := range chunk {
+		req := requests[i]
+		item := batchRequestPayload{
+			ID:        req.ID,
+			Method:    req.Method,
+			URL:       req.Path,
+			DependsOn: req.DependsOn,
+		}
+		if req.Body != nil {
+			item.Body = req.Body
+			item.Headers = map[string]string{"Content-Type": "application/json"}
+		}
+		payload.Requests = append(payload.Requests, item)
+	}
+
+	respBody, err := c.doRawRequest(ctx, http.MethodPost, PathBatch, payload)
+	if err != nil {
+		return fmt.Errorf("failed to execute batch: %w", err)
+	}
+
+	var result struct {
+		Responses []batchResponsePayload `json:"responses"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("failed to parse batch response: %w", err)
+	}
+
+	received := make(map[string]batchResponsePayload, len(result.Responses))
+	for _, r := range result.Responses {
+		received[r.ID] = r
+	}
+
+	// Sub-responses are processed in request order so a retried dependency is complete
+	// before the requests depending on it are retried
+	succeeded := make(map[string]bool, len(chunk))
+	for _, i := range chunk {
+		req := requests[i]
+		sub, ok := received[req.ID]
+		if !ok {
+			responses[i] = BatchResponse{ID: req.ID, Err: fmt.Errorf("batch response did not contain request %s", req.ID)}
+			continue
+		}
+
+		if c.shouldRetryBatchItem(req, sub, succeeded) {
+			responses[i] = c.retryBatchItem(ctx, req, sub)
+		} else {
+			responses[i] = newBatchResponse(sub)
+		}
+
+		if responses[i].Err == nil {
+			succeeded[req.ID] = true
+		}
+	}
+
+	return nil
+}
+
+// shouldRetryBatchItem reports whether a sub-request should be sent again on its own
+func (c *GraphClient) shouldRetryBatchItem(req BatchRequest, sub batchResponsePayload, succeeded map[string]bool) bool {
+	if c.maxRetries <= 0 {
+		return false
+	}
+
+	if sub.Status == http.StatusFailedDependency {
+		// The request was skipped because a dependency failed; retry it once every dependency
+		// has completed after its own retry
+		for _, dep := range req.DependsOn {
+			if !succeeded[dep] {
+				return false
+			}
+		}
+		return len(req.DependsOn) > 0
+	}
+
+	return isRetryableStatus(sub.Status, isIdempotentMethod(req.Method))
+}
+
+// retryBatchItem sends a single sub-request outside of the batch, honouring its Retry-After header
+func (c *GraphClient) retryBatchItem(ctx context.Context, req BatchRequest, sub batchResponsePayload) BatchResponse {
+	header := http.Header{}
+	for k, v := range sub.Headers {
+		header.Set(k, v)
+	}
+
+	wait := c.retryDelay(0, header)
+	if sub.Status == http.StatusFailedDependency {
+		wait = 0
+	}
+
+	tflog.Debug(ctx, "Retrying batch request individually", map[string]interface{}{
+		"id":     req.ID,
+		"method": req.Method,
+		"path":   req.Path,
+		"status": sub.Status,
+		"wait":   wait.String(),
+	})
+
+	if err := sleepContext(ctx, wait); err != nil {
+		return BatchResponse{ID: req.ID, Status: sub.Status, Err: err}
+	}
+
+	body, err := c.doRawRequest(ctx, req.Method, req.Path, req.Body)
+	if err != nil {
+		return BatchResponse{ID: req.ID, Err: err}
+	}
+
+	return BatchResponse{ID: req.ID, Status: http.StatusOK, Body: body}
+}
+
+// newBatchResponse converts a wire sub-response into a BatchResponse, turning error statuses into errors
+func newBatchResponse(sub batchResponsePayload) BatchResponse {
+	resp := BatchResponse{
+		ID:     sub.ID,
+		Status: sub.Status,
+		Body:   sub.Body,
+	}
+
+	if sub.Status >= 400 {
+		_, resp.Err = checkGraphResponse(sub.Status, sub.Body)
+		resp.Body = nil
+	}
+
+	return resp
+}
@@ -0,0 +1,204 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+// CloudEnvironment describes the endpoints of a Microsoft cloud
+type CloudEnvironment struct {
+	// Name is the provider-facing name of the environment (e.g. public, usgovernment)
+	Name string
+
+	// MetadataName is the name used by the Azure metadata service (e.g. AzureCloud)
+	MetadataName string
+
+	// AuthorityHost is the Microsoft Entra ID login endpoint
+	AuthorityHost string
+
+	// GraphEndpoint is the Microsoft Graph endpoint without the API version
+	GraphEndpoint string
+}
+
+// GraphScope returns the token scope for Microsoft Graph in this environment
+func (e *CloudEnvironment) GraphScope() string {
+	return strings.TrimSuffix(e.GraphEndpoint, "/") + "/.default"
+}
+
+// AzureCloudConfiguration returns the azidentity cloud configuration for this environment
+func (e *CloudEnvironment) AzureCloudConfiguration() cloud.Configuration {
+	return cloud.Configuration{
+		ActiveDirectoryAuthorityHost: e.AuthorityHost,
+		Services:                     map[cloud.ServiceName]cloud.ServiceConfiguration{},
+	}
+}
+
+// Known cloud environments
+var (
+	// PublicCloud is the global Azure cloud
+	PublicCloud = CloudEnvironment{
+		Name:          "public",
+		MetadataName:  "AzureCloud",
+		AuthorityHost: "https://login.microsoftonline.com/",
+		GraphEndpoint: DefaultGraphEndpoint,
+	}
+
+	// USGovernmentL4Cloud is the US Government cloud (GCC High)
+	USGovernmentL4Cloud = CloudEnvironment{
+		Name:          "usgovernmentl4",
+		MetadataName:  "AzureUSGovernment",
+		AuthorityHost: "https://login.microsoftonline.us/",
+		GraphEndpoint: "https://graph.microsoft.us",
+	}
+
+	// USGovernmentL5Cloud is the US Government Department of Defense cloud (DoD)
+	USGovernmentL5Cloud = CloudEnvironment{
+		Name:          "usgovernmentl5",
+		MetadataName:  "AzureUSGovernment",
+		AuthorityHost: "https://login.microsoftonline.us/",
+		GraphEndpoint: "https://dod-graph.microsoft.us",
+	}
+
+	// ChinaCloud is the Azure China cloud operated by 21Vianet
+	ChinaCloud = CloudEnvironment{
+		Name:          "china",
+		MetadataName:  "AzureChinaCloud",
+		AuthorityHost: "https://login.chinacloudapi.cn/",
+		GraphEndpoint: "https://microsoftgraph.chinacloudapi.cn",
+	}
+)
+
+// cloudEnvironments maps the supported environment names to their endpoints.
+// "usgovernment" is an alias for the L4 (GCC High) cloud, matching the azuread provider.
+var cloudEnvironments = map[string]CloudEnvironment{
+	"public":         PublicCloud,
+	"usgovernment":   USGovernmentL4Cloud,
+	"usgovernmentl4": USGovernmentL4Cloud,
+	"usgovernmentl5": USGovernmentL5Cloud,
+	"china":          ChinaCloud,
+}
+
+// CloudEnvironmentNames returns the supported environment names
+func CloudEnvironmentNames() []string {
+	names := make([]string, 0, len(cloudEnvironments))
+	for name := range cloudEnvironments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupCloudEnvironment returns the environment registered under the given name.
+// An empty name selects the public cloud.
+func LookupCloudEnvironment(name string) (*CloudEnvironment, error) {
+	if name == "" {
+		env := PublicCloud
+		return &env, nil
+	}
+
+	env, ok := cloudEnvironments[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown environment %q, supported values are: %s", name, strings.Join(CloudEnvironmentNames(), ", "))
+	}
+	return &env, nil
+}
+
+// ResolveCloudEnvironment resolves the cloud environment from the configured name and,
+// when metadataHost is set, from the Azure metadata service on that host
+func ResolveCloudEnvironment(ctx context.Context, name, metadataHost string) (*CloudEnvironment, error) {
+	env, err := LookupCloudEnvironment(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadataHost == "" {
+		return env, nil
+	}
+
+	return discoverCloudEnvironment(ctx, metadataHost, env)
+}
+
+// cloudMetadata is the subset of the Azure metadata endpoint response used by the provider
+type cloudMetadata struct {
+	Name           string `json:"name"`
+	Authentication struct {
+		LoginEndpoint string `json:"loginEndpoint"`
+	} `json:"authentication"`
+	MicrosoftGraphResourceID string `json:"microsoftGraphResourceId"`
+}
+
+// discoverCloudEnvironment queries the metadata service on metadataHost and overlays the
+// discovered endpoints on top of the configured environment
+func discoverCloudEnvironment(ctx context.Context, metadataHost string, env *CloudEnvironment) (*CloudEnvironment, error) {
+	host := strings.TrimSuffix(metadataHost, "/")
+	if !strings.HasPrefix(host, "https://") && !strings.HasPrefix(host, "http://") {
+		host = "https://" + host
+	}
+	metadataURL := fmt.Sprintf("%s/metadata/endpoints?api-version=2022-09-01", host)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metadata host %s: %w", metadataHost, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata host %s returned status %d: %s", metadataHost, resp.StatusCode, string(body))
+	}
+
+	// Newer API versions return a list of environments, older ones a single object
+	var candidates []cloudMetadata
+	if err := json.Unmarshal(body, &candidates); err != nil {
+		var single cloudMetadata
+		if err := json.Unmarshal(body, &single); err != nil {
+			return nil, fmt.Errorf("failed to parse metadata response: %w", err)
+		}
+		candidates = []cloudMetadata{single}
+	}
+
+	var metadata *cloudMetadata
+	for i := range candidates {
+		if len(candidates) == 1 || strings.EqualFold(candidates[i].Name, env.MetadataName) {
+			metadata = &candidates[i]
+			break
+		}
+	}
+	if metadata == nil {
+		return nil, fmt.Errorf("metadata host %s does not describe environment %q", metadataHost, env.MetadataName)
+	}
+
+	discovered := *env
+	if metadata.Name != "" {
+		discovered.MetadataName = metadata.Name
+	}
+	if metadata.Authentication.LoginEndpoint != "" {
+		discovered.AuthorityHost = metadata.Authentication.LoginEndpoint
+	}
+	if metadata.MicrosoftGraphResourceID != "" {
+		discovered.GraphEndpoint = strings.TrimSuffix(metadata.MicrosoftGraphResourceID, "/")
+	}
+
+	return &discovered, nil
+}
@@ -0,0 +1,141 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLookupCloudEnvironment(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		wantName      string
+		wantGraph     string
+		wantAuthority string
+	}{
+		{"", "public", "https://graph.microsoft.com", "https://login.microsoftonline.com/"},
+		{"public", "public", "https://graph.microsoft.com", "https://login.microsoftonline.com/"},
+		{"usgovernment", "usgovernmentl4", "https://graph.microsoft.us", "https://login.microsoftonline.us/"},
+		{"USGovernmentL4", "usgovernmentl4", "https://graph.microsoft.us", "https://login.microsoftonline.us/"},
+		{"usgovernmentl5", "usgovernmentl5", "https://dod-graph.microsoft.us", "https://login.microsoftonline.us/"},
+		{"china", "china", "https://microsoftgraph.chinacloudapi.cn", "https://login.chinacloudapi.cn/"},
+	} {
+		env, err := LookupCloudEnvironment(tc.name)
+		if err != nil {
+			t.Errorf("%q: %s", tc.name, err)
+			continue
+		}
+		if env.Name != tc.wantName || env.GraphEndpoint != tc.wantGraph || env.AuthorityHost != tc.wantAuthority {
+			t.Errorf("%q: got %+v", tc.name, env)
+		}
+		if want := tc.wantGraph + "/.default"; env.GraphScope() != want {
+			t.Errorf("%q: expected scope %q, got %q", tc.name, want, env.GraphScope())
+		}
+	}
+
+	_, err := LookupCloudEnvironment("germany")
+	if err == nil || !strings.Contains(err.Error(), "usgovernmentl4") {
+		t.Errorf("expected an error listing the supported environments, got %v", err)
+	}
+}
+
+func TestLookupCloudEnvironmentReturnsCopy(t *testing.T) {
+	env, err := LookupCloudEnvironment("public")
+	if err != nil {
+		t.Fatalf("LookupCloudEnvironment: %s", err)
+	}
+	env.GraphEndpoint = "https://graph.example.com"
+
+	if PublicCloud.GraphEndpoint != DefaultGraphEndpoint {
+		t.Errorf("modifying a looked up environment changed the registry: %s", PublicCloud.GraphEndpoint)
+	}
+}
+
+// newMetadataServer returns a metadata host that serves body on the endpoints path
+func newMetadataServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metadata/endpoints" || r.URL.Query().Get("api-version") == "" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResolveCloudEnvironmentDiscovery(t *testing.T) {
+	ctx := context.Background()
+
+	environments := `[
+		{"name": "AzureCloud", "authentication": {"loginEndpoint": "https://login.microsoftonline.com/"}, "microsoftGraphResourceId": "https://graph.microsoft.com/"},
+		{"name": "AzureUSGovernment", "authentication": {"loginEndpoint": "https://login.example.us/"}, "microsoftGraphResourceId": "https://graph.example.us/"}
+	]`
+	server := newMetadataServer(t, http.StatusOK, environments)
+
+	// The configured environment selects the entry and the discovered endpoints override its own
+	env, err := ResolveCloudEnvironment(ctx, "usgovernment", server.URL+"/")
+	if err != nil {
+		t.Fatalf("ResolveCloudEnvironment: %s", err)
+	}
+	if env.Name != "usgovernmentl4" || env.AuthorityHost != "https://login.example.us/" || env.GraphEndpoint != "https://graph.example.us" {
+		t.Errorf("unexpected environment: %+v", env)
+	}
+	if env.GraphScope() != "https://graph.example.us/.default" {
+		t.Errorf("unexpected scope: %s", env.GraphScope())
+	}
+	if USGovernmentL4Cloud.GraphEndpoint != "https://graph.microsoft.us" {
+		t.Errorf("discovery changed the registered environment: %+v", USGovernmentL4Cloud)
+	}
+
+	// Without a metadata host the registered endpoints are used
+	env, err = ResolveCloudEnvironment(ctx, "usgovernment", "")
+	if err != nil {
+		t.Fatalf("ResolveCloudEnvironment: %s", err)
+	}
+	if env.GraphEndpoint != "https://graph.microsoft.us" {
+		t.Errorf("unexpected environment without a metadata host: %+v", env)
+	}
+
+	// A host that describes a single environment is used whatever its name
+	single := newMetadataServer(t, http.StatusOK, `{"name": "AzureStackCloud", "authentication": {"loginEndpoint": "https://login.stack.example/"}, "microsoftGraphResourceId": "https://graph.stack.example"}`)
+	env, err = ResolveCloudEnvironment(ctx, "", single.URL)
+	if err != nil {
+		t.Fatalf("ResolveCloudEnvironment: %s", err)
+	}
+	if env.MetadataName != "AzureStackCloud" || env.AuthorityHost != "https://login.stack.example/" || env.GraphEndpoint != "https://graph.stack.example" {
+		t.Errorf("unexpected environment from a single entry: %+v", env)
+	}
+}
+
+func TestResolveCloudEnvironmentDiscoveryErrors(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name   string
+		env    string
+		status int
+		body   string
+		want   string
+	}{
+		{"unknown environment", "germany", http.StatusOK, `[]`, "unknown environment"},
+		{"error status", "public", http.StatusInternalServerError, `oops`, "returned status 500"},
+		{"invalid body", "public", http.StatusOK, `not json`, "failed to parse metadata response"},
+		{"no matching entry", "china", http.StatusOK, `[{"name": "AzureCloud"}, {"name": "AzureUSGovernment"}]`, `does not describe environment "AzureChinaCloud"`},
+	} {
+		server := newMetadataServer(t, tc.status, tc.body)
+		_, err := ResolveCloudEnvironment(ctx, tc.env, server.URL)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tc.name, tc.want, err)
+		}
+	}
+}
@@ -7,12 +7,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 const (
@@ -22,25 +25,80 @@ const (
 	// GraphAPIVersion is the API version to use (beta needed for many Intune features)
 	GraphAPIVersion = "beta"
 
-	// GraphScope is the scope required for Microsoft Graph API
+	// GraphScope is the scope required for Microsoft Graph API in the public cloud
 	GraphScope = "https://graph.microsoft.com/.default"
 )
 
 // GraphClient provides access to Microsoft Graph API for Intune operations
 type GraphClient struct {
-	auth       *Authenticator
-	httpClient *http.Client
-	baseURL    string
-	userAgent  string
+	tokens       TokenSource
+	httpClient   *http.Client
+	baseURL      string
+	scope        string
+	userAgent    string
+	maxRetries   int
+	maxRetryWait time.Duration
+}
+
+// TokenSource provides access tokens for Graph API requests. It is implemented by Authenticator.
+type TokenSource interface {
+	GetToken(ctx context.Context, scopes []string) (string, error)
+}
+
+// GraphClientOptions holds optional settings for the Graph API client
+type GraphClientOptions struct {
+	// MaxRetries is the maximum number of times a throttled or transiently failing request is retried
+	MaxRetries int
+
+	// MaxRetryWait is the upper bound for the delay between two attempts
+	MaxRetryWait time.Duration
+
+	// Cloud selects the Graph endpoint and token scope. When nil, the cloud resolved by the
+	// authenticator is used, falling back to the public cloud.
+	Cloud *CloudEnvironment
+
+	// BaseURL overrides the Graph endpoint including the API version, e.g. for a local test server
+	BaseURL string
+
+	// TokenSource overrides the source of access tokens. When nil, the authenticator is used.
+	TokenSource TokenSource
 }
 
 // NewGraphClient creates a new Graph API client
-func NewGraphClient(auth *Authenticator, userAgent string) *GraphClient {
+func NewGraphClient(auth *Authenticator, userAgent string, opts *GraphClientOptions) *GraphClient {
+	if opts == nil {
+		opts = &GraphClientOptions{
+			MaxRetries:   DefaultMaxRetries,
+			MaxRetryWait: DefaultMaxRetryWait,
+		}
+	}
+
+	env := opts.Cloud
+	if env == nil && auth != nil {
+		env = auth.GetCloud()
+	}
+	if env == nil {
+		env = &PublicCloud
+	}
+
+	baseURL := fmt.Sprintf("%s/%s", strings.TrimSuffix(env.GraphEndpoint, "/"), GraphAPIVersion)
+	if opts.BaseURL != "" {
+		baseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	}
+
+	var tokens TokenSource = opts.TokenSource
+	if tokens == nil && auth != nil {
+		tokens = auth
+	}
+
 	return &GraphClient{
-		auth:       auth,
-		httpClient: &http.Client{Timeout: 60 * time.Second},
-		baseURL:    fmt.Sprintf("%s/%s", DefaultGraphEndpoint, GraphAPIVersion),
-		userAgent:  userAgent,
+		tokens:       tokens,
+		httpClient:   &http.Client{Timeout: 60 * time.Second},
+		baseURL:      baseURL,
+		scope:        env.GraphScope(),
+		userAgent:    userAgent,
+		maxRetries:   opts.MaxRetries,
+		maxRetryWait: opts.MaxRetryWait,
 	}
 }
 
@@ -56,6 +114,7 @@ type GraphResponse struct {
 
 // GraphError represents an error from the Graph API
 type GraphError struct {
+	StatusCode int          `json:"-"`
 	Code       string       `json:"code"`
 	Message    string       `json:"message"`
 	InnerError *GraphError  `json:"innerError,omitempty"`
@@ -63,37 +122,124 @@ type GraphError struct {
 }
 
 func (e *GraphError) Error() string {
-	if e.InnerError != nil {
+	// Graph also uses innerError for request metadata without a code or message
+	if e.InnerError != nil && (e.InnerError.Code != "" || e.InnerError.Message != "") {
 		return fmt.Sprintf("%s: %s (inner: %s)", e.Code, e.Message, e.InnerError.Error())
 	}
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
-// doRequest performs an HTTP request to the Graph API
+// IsNotFound reports whether err, or any error it wraps, is a Graph API error for a missing resource
+func IsNotFound(err error) bool {
+	var graphErr *GraphError
+	if !errors.As(err, &graphErr) {
+		return false
+	}
+	if graphErr.StatusCode == http.StatusNotFound {
+		return true
+	}
+	switch graphErr.Code {
+	case "NotFound", "ResourceNotFound", "itemNotFound", "Request_ResourceNotFound":
+		return true
+	default:
+		return false
+	}
+}
+
+// doRequest performs an HTTP request to the Graph API and parses the result as a GraphResponse
 func (c *GraphClient) doRequest(ctx context.Context, method, path string, body interface{}) (*GraphResponse, error) {
-	// Get access token
-	token, err := c.auth.GetToken(ctx, []string{GraphScope})
+	respBody, err := c.doRawRequest(ctx, method, path, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get access token: %w", err)
+		return nil, err
+	}
+
+	var graphResp GraphResponse
+	if len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, &graphResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w (body: %s)", err, string(respBody))
+		}
 	}
 
+	return &graphResp, nil
+}
+
+// doRawRequest performs an HTTP request to the Graph API, retrying throttled and transient failures,
+// and returns the raw response body of a successful request
+func (c *GraphClient) doRawRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
 	// Build URL
 	reqURL := fmt.Sprintf("%s%s", c.baseURL, path)
 
-	// Prepare body
-	var bodyReader io.Reader
+	// Prepare body once so it can be replayed on every attempt
+	var bodyBytes []byte
 	if body != nil {
-		bodyBytes, err := json.Marshal(body)
+		var err error
+		bodyBytes, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
+	}
+
+	idempotent := isIdempotentMethod(method)
+
+	for attempt := 0; ; attempt++ {
+		statusCode, header, respBody, err := c.sendRequest(ctx, method, reqURL, bodyBytes)
+		if err != nil {
+			if attempt < c.maxRetries && isRetryableError(ctx, err, idempotent) {
+				wait := c.retryDelay(attempt, nil)
+				tflog.Debug(ctx, "Retrying Graph API request after transport error", map[string]interface{}{
+					"method":  method,
+					"path":    path,
+					"attempt": attempt + 1,
+					"wait":    wait.String(),
+					"error":   err.Error(),
+				})
+				if err := sleepContext(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, err
+		}
+
+		if attempt < c.maxRetries && isRetryableStatus(statusCode, idempotent) {
+			wait := c.retryDelay(attempt, header)
+			tflog.Debug(ctx, "Retrying Graph API request after throttled or transient response", map[string]interface{}{
+				"method":  method,
+				"path":    path,
+				"status":  statusCode,
+				"attempt": attempt + 1,
+				"wait":    wait.String(),
+			})
+			if err := sleepContext(ctx, wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		return checkGraphResponse(statusCode, respBody)
+	}
+}
+
+// sendRequest performs a single HTTP attempt and returns the status code, headers and body
+func (c *GraphClient) sendRequest(ctx context.Context, method, reqURL string, bodyBytes []byte) (int, http.Header, []byte, error) {
+	// Get access token
+	if c.tokens == nil {
+		return 0, nil, nil, fmt.Errorf("no token source configured")
+	}
+	token, err := c.tokens.GetToken(ctx, []string{c.scope})
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	var bodyReader io.Reader
+	if bodyBytes != nil {
 		bodyReader = bytes.NewReader(bodyBytes)
 	}
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return 0, nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -107,33 +253,39 @@ func (c *GraphClient) doRequest(ctx context.Context, method, path string, body i
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return 0, nil, nil, &transportError{err: err}
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return 0, nil, nil, &transportError{err: fmt.Errorf("failed to read response body: %w", err)}
 	}
 
-	// Parse response
-	var graphResp GraphResponse
-	if len(respBody) > 0 {
-		if err := json.Unmarshal(respBody, &graphResp); err != nil {
-			return nil, fmt.Errorf("failed to parse response: %w (body: %s)", err, string(respBody))
-		}
+	return resp.StatusCode, resp.Header, respBody, nil
+}
+
+// checkGraphResponse returns the body of a successful response or the error reported by Graph
+func checkGraphResponse(statusCode int, respBody []byte) ([]byte, error) {
+	if statusCode < 400 {
+		return respBody, nil
 	}
 
 	// Check for errors
-	if resp.StatusCode >= 400 {
-		if graphResp.Error != nil {
-			return nil, graphResp.Error
-		}
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+	var errResp struct {
+		Error *GraphError `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error != nil {
+		errResp.Error.StatusCode = statusCode
+		return nil, errResp.Error
 	}
+	return nil, fmt.Errorf("request failed with status %d: %s", statusCode, string(respBody))
+}
 
-	return &graphResp, nil
+// ResourceURL returns the absolute URL of a Graph path, as used by @odata.bind references
+func (c *GraphClient) ResourceURL(path string) string {
+	return c.baseURL + path
 }
 
 // Get performs a GET request
@@ -162,6 +314,54 @@ func (c *GraphClient) Delete(ctx context.Context, path string) error {
 	return err
 }
 
+// GetInto performs a GET request and decodes the response body into T
+func GetInto[T any](ctx context.Context, c *GraphClient, path string) (*T, error) {
+	return requestInto[T](ctx, c, http.MethodGet, path, nil)
+}
+
+// PostInto performs a POST request and decodes the response body into T
+func PostInto[T any](ctx context.Context, c *GraphClient, path string, body interface{}) (*T, error) {
+	return requestInto[T](ctx, c, http.MethodPost, path, body)
+}
+
+// ListInto retrieves all items from a paginated endpoint and decodes each of them into T
+func ListInto[T any](ctx context.Context, c *GraphClient, path string) ([]T, error) {
+	items, err := c.ListAll(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		var value T
+		if err := json.Unmarshal(item, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse item: %w (body: %s)", err, string(item))
+		}
+		result = append(result, value)
+	}
+
+	return result, nil
+}
+
+// requestInto performs a request and decodes the full response body into T
+func requestInto[T any](ctx context.Context, c *GraphClient, method, path string, body interface{}) (*T, error) {
+	respBody, err := c.doRawRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(respBody) == 0 {
+		return nil, fmt.Errorf("%s %s returned an empty response", method, path)
+	}
+
+	var value T
+	if err := json.Unmarshal(respBody, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w (body: %s)", err, string(respBody))
+	}
+
+	return &value, nil
+}
+
 // ListAll retrieves all items from a paginated endpoint
 func (c *GraphClient) ListAll(ctx context.Context, path string) ([]json.RawMessage, error) {
 	var allItems []json.RawMessage
@@ -244,23 +444,32 @@ type SettingInstanceTemplateRef struct {
 	SettingInstanceTemplateId string `json:"settingInstanceTemplateId,omitempty"`
 }
 
+// SettingValueTemplateRef references a setting value template
+type SettingValueTemplateRef struct {
+	SettingValueTemplateId string `json:"settingValueTemplateId"`
+	UseTemplateDefault     bool   `json:"useTemplateDefault"`
+}
+
 // SimpleSettingValue represents a simple setting value
 type SimpleSettingValue struct {
-	ODataType string      `json:"@odata.type"`
-	Value     interface{} `json:"value"`
+	ODataType                     string                   `json:"@odata.type"`
+	Value                         interface{}              `json:"value"`
+	SettingValueTemplateReference *SettingValueTemplateRef `json:"settingValueTemplateReference,omitempty"`
 }
 
 // ChoiceSettingValue represents a choice setting value
 type ChoiceSettingValue struct {
-	ODataType string                           `json:"@odata.type,omitempty"`
-	Value     string                           `json:"value"`
-	Children  []SettingsCatalogPolicySetting   `json:"children,omitempty"`
+	ODataType                     string                   `json:"@odata.type,omitempty"`
+	Value                         string                   `json:"value"`
+	Children                      []SettingInstance        `json:"children,omitempty"`
+	SettingValueTemplateReference *SettingValueTemplateRef `json:"settingValueTemplateReference,omitempty"`
 }
 
 // GroupSettingValue represents a group setting value
 type GroupSettingValue struct {
-	ODataType string                         `json:"@odata.type,omitempty"`
-	Children  []SettingsCatalogPolicySetting `json:"children,omitempty"`
+	ODataType                     string                   `json:"@odata.type,omitempty"`
+	Children                      []SettingInstance        `json:"children,omitempty"`
+	SettingValueTemplateReference *SettingValueTemplateRef `json:"settingValueTemplateReference,omitempty"`
 }
 
 // SettingsCatalogTemplateReference references a settings catalog template
@@ -271,6 +480,48 @@ type SettingsCatalogTemplateReference struct {
 	TemplateDisplayVersion string `json:"templateDisplayVersion,omitempty"`
 }
 
+// ConfigurationPolicyTemplate represents a template Settings Catalog policies, such as
+// endpoint security policies, are created from
+type ConfigurationPolicyTemplate struct {
+	ID                   string `json:"id"`
+	BaseId               string `json:"baseId,omitempty"`
+	Version              int    `json:"version,omitempty"`
+	DisplayName          string `json:"displayName"`
+	Description          string `json:"description,omitempty"`
+	DisplayVersion       string `json:"displayVersion,omitempty"`
+	LifecycleState       string `json:"lifecycleState,omitempty"`
+	Platforms            string `json:"platforms,omitempty"`
+	Technologies         string `json:"technologies,omitempty"`
+	TemplateFamily       string `json:"templateFamily,omitempty"`
+	SettingTemplateCount int    `json:"settingTemplateCount,omitempty"`
+}
+
+// ConfigurationSettingTemplate represents a setting of a configuration policy template
+type ConfigurationSettingTemplate struct {
+	ID                      string                   `json:"id,omitempty"`
+	SettingInstanceTemplate *SettingInstanceTemplate `json:"settingInstanceTemplate"`
+}
+
+// SettingInstanceTemplate describes a setting instance of a template and the templates of its values
+type SettingInstanceTemplate struct {
+	ODataType                            string                 `json:"@odata.type,omitempty"`
+	SettingInstanceTemplateId            string                 `json:"settingInstanceTemplateId"`
+	SettingDefinitionId                  string                 `json:"settingDefinitionId"`
+	IsRequired                           bool                   `json:"isRequired,omitempty"`
+	SimpleSettingValueTemplate           *SettingValueTemplate  `json:"simpleSettingValueTemplate,omitempty"`
+	ChoiceSettingValueTemplate           *SettingValueTemplate  `json:"choiceSettingValueTemplate,omitempty"`
+	GroupSettingValueTemplate            *SettingValueTemplate  `json:"groupSettingValueTemplate,omitempty"`
+	SimpleSettingCollectionValueTemplate []SettingValueTemplate `json:"simpleSettingCollectionValueTemplate,omitempty"`
+	GroupSettingCollectionValueTemplate  []SettingValueTemplate `json:"groupSettingCollectionValueTemplate,omitempty"`
+}
+
+// SettingValueTemplate describes a setting value of a template and the templates of its child settings
+type SettingValueTemplate struct {
+	ODataType              string                    `json:"@odata.type,omitempty"`
+	SettingValueTemplateId string                    `json:"settingValueTemplateId"`
+	Children               []SettingInstanceTemplate `json:"children,omitempty"`
+}
+
 // CompliancePolicy represents an Intune device compliance policy
 type CompliancePolicy struct {
 	ODataType                       string                      `json:"@odata.type,omitempty"`
@@ -315,11 +566,88 @@ type CompliancePolicy struct {
 	TpmRequired                     bool   `json:"tpmRequired,omitempty"`
 	DeviceCompliancePolicyScript    *DeviceCompliancePolicyScript `json:"deviceCompliancePolicyScript,omitempty"`
 	ValidOperatingSystemBuildRanges []OperatingSystemVersionRange `json:"validOperatingSystemBuildRanges,omitempty"`
+
+	// Properties holds the properties of the derived type, decoded with json.Number for numbers.
+	// It is how the properties of policy types other than Windows are read and written; when
+	// encoding, fields set in the struct take precedence.
+	Properties map[string]interface{} `json:"-"`
+}
+
+// compliancePolicyFields has the fields of CompliancePolicy without its JSON methods
+type compliancePolicyFields CompliancePolicy
+
+// compliancePolicyProperties are the properties common to all compliance policy types
+var compliancePolicyProperties = []string{
+	"@odata.type", "id", "displayName", "description", "createdDateTime", "lastModifiedDateTime", "roleScopeTagIds", "version", "scheduledActionsForRule",
+}
+
+// MarshalJSON encodes the policy together with the properties of its derived type
+func (p CompliancePolicy) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(compliancePolicyFields(p))
+	if err != nil || len(p.Properties) == 0 {
+		return data, err
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	for k, v := range p.Properties {
+		if _, ok := body[k]; !ok {
+			body[k] = v
+		}
+	}
+	return json.Marshal(body)
+}
+
+// UnmarshalJSON decodes a policy, keeping the properties of the derived type in Properties
+func (p *CompliancePolicy) UnmarshalJSON(data []byte) error {
+	var fields compliancePolicyFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var props map[string]interface{}
+	if err := decoder.Decode(&props); err != nil {
+		return err
+	}
+	for _, name := range compliancePolicyProperties {
+		delete(props, name)
+	}
+	for name := range props {
+		// Annotations such as @odata.context describe the response, not the policy
+		if strings.HasPrefix(name, "@odata.") {
+			delete(props, name)
+		}
+	}
+
+	*p = CompliancePolicy(fields)
+	p.Properties = props
+	return nil
+}
+
+// SettingsCompliancePolicy represents a compliance policy built from Settings Catalog settings,
+// which is how Linux compliance policies are defined
+type SettingsCompliancePolicy struct {
+	ID                      string                         `json:"id,omitempty"`
+	Name                    string                         `json:"name"`
+	Description             string                         `json:"description"`
+	Platforms               string                         `json:"platforms"`
+	Technologies            string                         `json:"technologies"`
+	CreatedDateTime         string                         `json:"createdDateTime,omitempty"`
+	LastModifiedDateTime    string                         `json:"lastModifiedDateTime,omitempty"`
+	RoleScopeTagIds         []string                       `json:"roleScopeTagIds,omitempty"`
+	SettingCount            int                            `json:"settingCount,omitempty"`
+	Settings                []SettingsCatalogPolicySetting `json:"settings"`
+	ScheduledActionsForRule []ComplianceScheduledAction    `json:"scheduledActionsForRule,omitempty"`
 }
 
 // ComplianceScheduledAction represents a scheduled action for compliance
 type ComplianceScheduledAction struct {
-	RuleName                      string                       `json:"ruleName,omitempty"`
+	ID                            string                         `json:"id,omitempty"`
+	RuleName                      string                         `json:"ruleName,omitempty"`
 	ScheduledActionConfigurations []ScheduledActionConfiguration `json:"scheduledActionConfigurations,omitempty"`
 }
 
@@ -338,6 +666,45 @@ type DeviceCompliancePolicyScript struct {
 	RulesContent             string `json:"rulesContent,omitempty"`
 }
 
+// DeviceComplianceScript represents a PowerShell discovery script for custom compliance.
+// DetectionScriptContent is base64 encoded.
+type DeviceComplianceScript struct {
+	ID                     string   `json:"id,omitempty"`
+	DisplayName            string   `json:"displayName"`
+	Description            string   `json:"description"`
+	Publisher              string   `json:"publisher"`
+	DetectionScriptContent string   `json:"detectionScriptContent,omitempty"`
+	RunAsAccount           string   `json:"runAsAccount,omitempty"`
+	EnforceSignatureCheck  bool     `json:"enforceSignatureCheck"`
+	RunAs32Bit             bool     `json:"runAs32Bit"`
+	RoleScopeTagIds        []string `json:"roleScopeTagIds,omitempty"`
+	CreatedDateTime        string   `json:"createdDateTime,omitempty"`
+	LastModifiedDateTime   string   `json:"lastModifiedDateTime,omitempty"`
+}
+
+// NotificationMessageTemplate represents a notification message template used by the
+// notification actions of compliance policies. BrandingOptions is a comma-separated flags value.
+type NotificationMessageTemplate struct {
+	ID                            string                         `json:"id,omitempty"`
+	DisplayName                   string                         `json:"displayName"`
+	Description                   string                         `json:"description"`
+	DefaultLocale                 string                         `json:"defaultLocale,omitempty"`
+	BrandingOptions               string                         `json:"brandingOptions,omitempty"`
+	RoleScopeTagIds               []string                       `json:"roleScopeTagIds,omitempty"`
+	LastModifiedDateTime          string                         `json:"lastModifiedDateTime,omitempty"`
+	LocalizedNotificationMessages []LocalizedNotificationMessage `json:"localizedNotificationMessages,omitempty"`
+}
+
+// LocalizedNotificationMessage represents the message of a notification message template in one locale
+type LocalizedNotificationMessage struct {
+	ID                   string `json:"id,omitempty"`
+	Locale               string `json:"locale"`
+	Subject              string `json:"subject"`
+	MessageTemplate      string `json:"messageTemplate"`
+	IsDefault            bool   `json:"isDefault"`
+	LastModifiedDateTime string `json:"lastModifiedDateTime,omitempty"`
+}
+
 // OperatingSystemVersionRange represents an OS version range
 type OperatingSystemVersionRange struct {
 	Description         string `json:"description,omitempty"`
@@ -345,6 +712,225 @@ type OperatingSystemVersionRange struct {
 	HighestVersion      string `json:"highestVersion,omitempty"`
 }
 
+// DeviceConfiguration represents a device configuration profile. Profiles are derived types
+// whose type-specific properties are kept in Properties.
+type DeviceConfiguration struct {
+	ODataType            string
+	ID                   string
+	DisplayName          string
+	Description          string
+	RoleScopeTagIds      []string
+	CreatedDateTime      string
+	LastModifiedDateTime string
+
+	// Properties holds the properties of the derived type, decoded with json.Number for numbers
+	Properties map[string]interface{}
+}
+
+// deviceConfigurationProperties are the properties DeviceConfiguration holds in its own fields
+var deviceConfigurationProperties = []string{"@odata.type", "id", "displayName", "description", "roleScopeTagIds", "createdDateTime", "lastModifiedDateTime"}
+
+// MarshalJSON encodes the profile with its type-specific properties
+func (d DeviceConfiguration) MarshalJSON() ([]byte, error) {
+	body := make(map[string]interface{}, len(d.Properties)+len(deviceConfigurationProperties))
+	for k, v := range d.Properties {
+		body[k] = v
+	}
+	body["@odata.type"] = d.ODataType
+	body["displayName"] = d.DisplayName
+	body["description"] = d.Description
+	if d.RoleScopeTagIds != nil {
+		body["roleScopeTagIds"] = d.RoleScopeTagIds
+	}
+	return json.Marshal(body)
+}
+
+// UnmarshalJSON decodes a profile, keeping the properties of the derived type in Properties
+func (d *DeviceConfiguration) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var props map[string]interface{}
+	if err := decoder.Decode(&props); err != nil {
+		return err
+	}
+
+	str := func(name string) string {
+		s, _ := props[name].(string)
+		return s
+	}
+	*d = DeviceConfiguration{
+		ODataType:            str("@odata.type"),
+		ID:                   str("id"),
+		DisplayName:          str("displayName"),
+		Description:          str("description"),
+		CreatedDateTime:      str("createdDateTime"),
+		LastModifiedDateTime: str("lastModifiedDateTime"),
+	}
+	if tags, ok := props["roleScopeTagIds"].([]interface{}); ok {
+		d.RoleScopeTagIds = make([]string, 0, len(tags))
+		for _, tag := range tags {
+			if tag, ok := tag.(string); ok {
+				d.RoleScopeTagIds = append(d.RoleScopeTagIds, tag)
+			}
+		}
+	}
+
+	for _, name := range deviceConfigurationProperties {
+		delete(props, name)
+	}
+	for name := range props {
+		// Annotations such as @odata.context describe the response, not the profile
+		if strings.HasPrefix(name, "@odata.") {
+			delete(props, name)
+		}
+	}
+	d.Properties = props
+	return nil
+}
+
+// WindowsFeatureUpdateProfile represents a Windows feature update profile, which keeps devices
+// on a feature update version
+type WindowsFeatureUpdateProfile struct {
+	ID                                                string                        `json:"id,omitempty"`
+	DisplayName                                       string                        `json:"displayName"`
+	Description                                       string                        `json:"description"`
+	FeatureUpdateVersion                              string                        `json:"featureUpdateVersion"`
+	InstallLatestWindows10OnWindows11IneligibleDevice bool                          `json:"installLatestWindows10OnWindows11IneligibleDevice"`
+	RolloutSettings                                   *WindowsUpdateRolloutSettings `json:"rolloutSettings"`
+	RoleScopeTagIds                                   []string                      `json:"roleScopeTagIds,omitempty"`
+	EndOfSupportDate                                  string                        `json:"endOfSupportDate,omitempty"`
+	CreatedDateTime                                   string                        `json:"createdDateTime,omitempty"`
+	LastModifiedDateTime                              string                        `json:"lastModifiedDateTime,omitempty"`
+}
+
+// WindowsUpdateRolloutSettings represents when a Windows update is offered to devices. Without
+// an end the update is offered from the start; with an end it is offered to groups of devices
+// every interval between start and end.
+type WindowsUpdateRolloutSettings struct {
+	OfferStartDateTimeInUTC string `json:"offerStartDateTimeInUTC,omitempty"`
+	OfferEndDateTimeInUTC   string `json:"offerEndDateTimeInUTC,omitempty"`
+	OfferIntervalInDays     int    `json:"offerIntervalInDays,omitempty"`
+}
+
+// WindowsQualityUpdateProfile represents a Windows quality update profile, which expedites a
+// quality update release to devices that have not installed it yet
+type WindowsQualityUpdateProfile struct {
+	ID                           string                                 `json:"id,omitempty"`
+	DisplayName                  string                                 `json:"displayName"`
+	Description                  string                                 `json:"description"`
+	ExpeditedUpdateSettings      *ExpeditedWindowsQualityUpdateSettings `json:"expeditedUpdateSettings,omitempty"`
+	ReleaseDateDisplayText       string                                 `json:"releaseDateDisplayText,omitempty"`
+	DeployableContentDisplayName string                                 `json:"deployableContentDisplayName,omitempty"`
+	RoleScopeTagIds              []string                               `json:"roleScopeTagIds,omitempty"`
+	CreatedDateTime              string                                 `json:"createdDateTime,omitempty"`
+	LastModifiedDateTime         string                                 `json:"lastModifiedDateTime,omitempty"`
+}
+
+// ExpeditedWindowsQualityUpdateSettings represents the release a quality update profile expedites
+// and how long devices may wait before they restart to install it
+type ExpeditedWindowsQualityUpdateSettings struct {
+	QualityUpdateRelease  string `json:"qualityUpdateRelease"`
+	DaysUntilForcedReboot int    `json:"daysUntilForcedReboot"`
+}
+
+// WindowsDriverUpdateProfile represents a Windows driver update profile. Drivers of manual
+// profiles are deployed once approved; automatic profiles approve recommended drivers after the
+// deferral.
+type WindowsDriverUpdateProfile struct {
+	ID                       string   `json:"id,omitempty"`
+	DisplayName              string   `json:"displayName"`
+	Description              string   `json:"description"`
+	ApprovalType             string   `json:"approvalType,omitempty"`
+	DeploymentDeferralInDays int      `json:"deploymentDeferralInDays"`
+	RoleScopeTagIds          []string `json:"roleScopeTagIds,omitempty"`
+	CreatedDateTime          string   `json:"createdDateTime,omitempty"`
+	LastModifiedDateTime     string   `json:"lastModifiedDateTime,omitempty"`
+}
+
+// WindowsDriverUpdateInventory represents a driver applicable to the devices of a driver update
+// profile, with its approval status in the profile
+type WindowsDriverUpdateInventory struct {
+	ID                    string `json:"id"`
+	Name                  string `json:"name"`
+	Version               string `json:"version"`
+	Manufacturer          string `json:"manufacturer"`
+	DriverClass           string `json:"driverClass"`
+	ReleaseDateTime       string `json:"releaseDateTime,omitempty"`
+	ApplicableDeviceCount int    `json:"applicableDeviceCount"`
+	ApprovalStatus        string `json:"approvalStatus"`
+	Category              string `json:"category"`
+	DeployDateTime        string `json:"deployDateTime,omitempty"`
+}
+
+// Driver approval actions of driver update profiles
+const (
+	DriverActionApprove = "approve"
+	DriverActionDecline = "decline"
+	DriverActionSuspend = "suspend"
+)
+
+// WindowsAutopilotDeploymentProfile represents a Windows Autopilot deployment profile. The OData
+// type selects how devices join: azureADWindowsAutopilotDeploymentProfile for Microsoft Entra
+// join, activeDirectoryWindowsAutopilotDeploymentProfile for hybrid join.
+type WindowsAutopilotDeploymentProfile struct {
+	ODataType                              string                      `json:"@odata.type"`
+	ID                                     string                      `json:"id,omitempty"`
+	DisplayName                            string                      `json:"displayName"`
+	Description                            string                      `json:"description"`
+	Language                               string                      `json:"language"`
+	DeviceNameTemplate                     string                      `json:"deviceNameTemplate"`
+	DeviceType                             string                      `json:"deviceType,omitempty"`
+	EnableWhiteGlove                       bool                        `json:"enableWhiteGlove"`
+	ExtractHardwareHash                    bool                        `json:"extractHardwareHash"`
+	HybridAzureADJoinSkipConnectivityCheck *bool                       `json:"hybridAzureADJoinSkipConnectivityCheck,omitempty"`
+	OutOfBoxExperienceSettings             *OutOfBoxExperienceSettings `json:"outOfBoxExperienceSettings,omitempty"`
+	RoleScopeTagIds                        []string                    `json:"roleScopeTagIds,omitempty"`
+	CreatedDateTime                        string                      `json:"createdDateTime,omitempty"`
+	LastModifiedDateTime                   string                      `json:"lastModifiedDateTime,omitempty"`
+}
+
+// OutOfBoxExperienceSettings holds the out-of-box experience settings of an Autopilot profile
+type OutOfBoxExperienceSettings struct {
+	HidePrivacySettings       bool   `json:"hidePrivacySettings"`
+	HideEULA                  bool   `json:"hideEULA"`
+	UserType                  string `json:"userType"`
+	DeviceUsageType           string `json:"deviceUsageType"`
+	SkipKeyboardSelectionPage bool   `json:"skipKeyboardSelectionPage"`
+	HideEscapeLink            bool   `json:"hideEscapeLink"`
+}
+
+// OData types of Autopilot deployment profiles
+const (
+	ODataTypeAzureADAutopilotProfile       = "#microsoft.graph.azureADWindowsAutopilotDeploymentProfile"
+	ODataTypeHybridAzureADAutopilotProfile = "#microsoft.graph.activeDirectoryWindowsAutopilotDeploymentProfile"
+)
+
+// EnrollmentCompletionPageConfiguration represents an Enrollment Status Page, a device enrollment
+// configuration that tracks app and policy installation during enrollment. Its priority is
+// read-only and changed with the setPriority action.
+type EnrollmentCompletionPageConfiguration struct {
+	ODataType                            string   `json:"@odata.type"`
+	ID                                   string   `json:"id,omitempty"`
+	DisplayName                          string   `json:"displayName"`
+	Description                          string   `json:"description"`
+	Priority                             int      `json:"priority,omitempty"`
+	ShowInstallationProgress             bool     `json:"showInstallationProgress"`
+	InstallProgressTimeoutInMinutes      int      `json:"installProgressTimeoutInMinutes"`
+	CustomErrorMessage                   string   `json:"customErrorMessage"`
+	AllowLogCollectionOnInstallFailure   bool     `json:"allowLogCollectionOnInstallFailure"`
+	AllowDeviceResetOnInstallFailure     bool     `json:"allowDeviceResetOnInstallFailure"`
+	AllowDeviceUseOnInstallFailure       bool     `json:"allowDeviceUseOnInstallFailure"`
+	BlockDeviceSetupRetryByUser          bool     `json:"blockDeviceSetupRetryByUser"`
+	TrackInstallProgressForAutopilotOnly bool     `json:"trackInstallProgressForAutopilotOnly"`
+	SelectedMobileAppIds                 []string `json:"selectedMobileAppIds"`
+	RoleScopeTagIds                      []string `json:"roleScopeTagIds,omitempty"`
+	CreatedDateTime                      string   `json:"createdDateTime,omitempty"`
+	LastModifiedDateTime                 string   `json:"lastModifiedDateTime,omitempty"`
+}
+
+// ODataTypeEnrollmentCompletionPage is the OData type of Enrollment Status Page configurations
+const ODataTypeEnrollmentCompletionPage = "#microsoft.graph.windows10EnrollmentCompletionPageConfiguration"
+
 // EndpointSecurityPolicy represents an endpoint security policy
 type EndpointSecurityPolicy struct {
 	ODataType            string   `json:"@odata.type,omitempty"`
@@ -360,6 +946,29 @@ type EndpointSecurityPolicy struct {
 	IsAssigned           bool     `json:"isAssigned,omitempty"`
 }
 
+// EndpointSecurityTemplate represents a template endpoint security intents are created from
+type EndpointSecurityTemplate struct {
+	ODataType         string `json:"@odata.type,omitempty"`
+	ID                string `json:"id,omitempty"`
+	DisplayName       string `json:"displayName"`
+	Description       string `json:"description,omitempty"`
+	VersionInfo       string `json:"versionInfo,omitempty"`
+	IsDeprecated      bool   `json:"isDeprecated,omitempty"`
+	IntentCount       int    `json:"intentCount,omitempty"`
+	TemplateType      string `json:"templateType,omitempty"`
+	PlatformType      string `json:"platformType,omitempty"`
+	TemplateSubtype   string `json:"templateSubtype,omitempty"`
+	PublishedDateTime string `json:"publishedDateTime,omitempty"`
+}
+
+// IntentSetting represents a setting of an endpoint security intent
+type IntentSetting struct {
+	ODataType    string `json:"@odata.type,omitempty"`
+	ID           string `json:"id,omitempty"`
+	DefinitionId string `json:"definitionId"`
+	ValueJson    string `json:"valueJson,omitempty"`
+}
+
 // PolicyAssignment represents a policy assignment
 type PolicyAssignment struct {
 	ODataType string           `json:"@odata.type,omitempty"`
@@ -397,6 +1006,37 @@ type SettingDefinition struct {
 	ReferredSettingInformationList []ReferredSettingInformation `json:"referredSettingInformationList,omitempty"`
 	AccessTypes          string   `json:"accessTypes,omitempty"`
 	Applicability        *Applicability `json:"applicability,omitempty"`
+
+	// Choice setting definitions
+	Options         []SettingOption `json:"options,omitempty"`
+	DefaultOptionId string          `json:"defaultOptionId,omitempty"`
+
+	// Simple setting definitions
+	ValueDefinition *SettingValueDefinition `json:"valueDefinition,omitempty"`
+	DefaultValue    *SimpleSettingValue     `json:"defaultValue,omitempty"`
+
+	// Collection and group definitions
+	MinimumCount *int     `json:"minimumCount,omitempty"`
+	MaximumCount *int     `json:"maximumCount,omitempty"`
+	ChildIds     []string `json:"childIds,omitempty"`
+}
+
+// SettingOption is an option of a choice setting definition
+type SettingOption struct {
+	ItemId      string `json:"itemId"`
+	DisplayName string `json:"displayName,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// SettingValueDefinition constrains the values of a simple setting
+type SettingValueDefinition struct {
+	ODataType     string `json:"@odata.type,omitempty"`
+	MinimumValue  *int64 `json:"minimumValue,omitempty"`
+	MaximumValue  *int64 `json:"maximumValue,omitempty"`
+	MinimumLength *int64 `json:"minimumLength,omitempty"`
+	MaximumLength *int64 `json:"maximumLength,omitempty"`
+	Format        string `json:"format,omitempty"`
+	IsSecret      bool   `json:"isSecret,omitempty"`
 }
 
 // Occurrence represents occurrence constraints for a setting
@@ -450,6 +1090,9 @@ const (
 
 	// Compliance Policies
 	PathCompliancePolicies          = "/deviceManagement/deviceCompliancePolicies"
+	PathSettingsCompliancePolicies  = "/deviceManagement/compliancePolicies"
+	PathDeviceComplianceScripts     = "/deviceManagement/deviceComplianceScripts"
+	PathNotificationMessageTemplates = "/deviceManagement/notificationMessageTemplates"
 
 	// Endpoint Security
 	PathEndpointSecurityPolicies    = "/deviceManagement/intents"
@@ -458,6 +1101,15 @@ const (
 	// Device Configuration
 	PathDeviceConfigurations        = "/deviceManagement/deviceConfigurations"
 
+	// Windows Updates
+	PathWindowsFeatureUpdateProfiles = "/deviceManagement/windowsFeatureUpdateProfiles"
+	PathWindowsQualityUpdateProfiles = "/deviceManagement/windowsQualityUpdateProfiles"
+	PathWindowsDriverUpdateProfiles  = "/deviceManagement/windowsDriverUpdateProfiles"
+
+	// Enrollment
+	PathAutopilotDeploymentProfiles    = "/deviceManagement/windowsAutopilotDeploymentProfiles"
+	PathDeviceEnrollmentConfigurations = "/deviceManagement/deviceEnrollmentConfigurations"
+
 	// Assignments
 	PathAssignments                 = "/assignments"
 
@@ -470,46 +1122,23 @@ const (
 
 // CreateSettingsCatalogPolicy creates a new Settings Catalog policy
 func (c *GraphClient) CreateSettingsCatalogPolicy(ctx context.Context, policy *SettingsCatalogPolicy) (*SettingsCatalogPolicy, error) {
-	resp, err := c.Post(ctx, PathSettingsCatalogPolicies, policy)
+	created, err := PostInto[SettingsCatalogPolicy](ctx, c, PathSettingsCatalogPolicies, policy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create settings catalog policy: %w", err)
 	}
 
-	// Parse the response into a policy
-	var created SettingsCatalogPolicy
-	respBytes, _ := json.Marshal(resp)
-	if err := json.Unmarshal(respBytes, &created); err != nil {
-		return nil, fmt.Errorf("failed to parse created policy: %w", err)
-	}
-
-	// The ID is in the response
-	if created.ID == "" {
-		created.ID = resp.ID
-	}
-
-	return &created, nil
+	return created, nil
 }
 
 // GetSettingsCatalogPolicy retrieves a Settings Catalog policy by ID
 func (c *GraphClient) GetSettingsCatalogPolicy(ctx context.Context, id string) (*SettingsCatalogPolicy, error) {
 	path := fmt.Sprintf("%s('%s')?$expand=settings", PathSettingsCatalogPolicies, id)
-	resp, err := c.Get(ctx, path)
+	policy, err := GetInto[SettingsCatalogPolicy](ctx, c, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get settings catalog policy: %w", err)
 	}
 
-	// The response is the policy itself, not in Value
-	respBytes, _ := json.Marshal(resp)
-	var policy SettingsCatalogPolicy
-	if err := json.Unmarshal(respBytes, &policy); err != nil {
-		return nil, fmt.Errorf("failed to parse policy: %w", err)
-	}
-
-	if policy.ID == "" {
-		policy.ID = resp.ID
-	}
-
-	return &policy, nil
+	return policy, nil
 }
 
 // UpdateSettingsCatalogPolicy updates a Settings Catalog policy
@@ -530,15 +1159,46 @@ func (c *GraphClient) DeleteSettingsCatalogPolicy(ctx context.Context, id string
 	return c.Delete(ctx, path)
 }
 
+// GetSettingsCatalogPolicySettings retrieves all settings currently configured on a Settings Catalog policy
+func (c *GraphClient) GetSettingsCatalogPolicySettings(ctx context.Context, policyId string) ([]SettingsCatalogPolicySetting, error) {
+	path := fmt.Sprintf("%s('%s')/settings", PathSettingsCatalogPolicies, policyId)
+	settings, err := ListInto[SettingsCatalogPolicySetting](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings catalog policy settings: %w", err)
+	}
+
+	return settings, nil
+}
+
 // UpdateSettingsCatalogPolicySettings updates the settings of a Settings Catalog policy
+// Graph only accepts settings as part of a full policy replacement, so the current policy is read
+// first and sent back with the new settings.
 func (c *GraphClient) UpdateSettingsCatalogPolicySettings(ctx context.Context, policyId string, settings []SettingsCatalogPolicySetting) error {
-	path := fmt.Sprintf("%s('%s')/settings", PathSettingsCatalogPolicies, policyId)
+	path := fmt.Sprintf("%s('%s')", PathSettingsCatalogPolicies, policyId)
+
+	policy, err := GetInto[SettingsCatalogPolicy](ctx, c, path)
+	if err != nil {
+		return fmt.Errorf("failed to update settings catalog policy settings: %w", err)
+	}
+
+	if settings == nil {
+		settings = []SettingsCatalogPolicySetting{}
+	}
 
+	// Read-only properties are rejected in a PUT body; settings are always sent so they can be cleared
 	body := map[string]interface{}{
-		"settings": settings,
+		"name":            policy.Name,
+		"description":     policy.Description,
+		"platforms":       policy.Platforms,
+		"technologies":    policy.Technologies,
+		"roleScopeTagIds": policy.RoleScopeTagIds,
+		"settings":        settings,
+	}
+	if policy.TemplateReference != nil {
+		body["templateReference"] = policy.TemplateReference
 	}
 
-	_, err := c.Put(ctx, path, body)
+	_, err = c.Put(ctx, path, body)
 	if err != nil {
 		return fmt.Errorf("failed to update settings catalog policy settings: %w", err)
 	}
@@ -548,43 +1208,23 @@ func (c *GraphClient) UpdateSettingsCatalogPolicySettings(ctx context.Context, p
 
 // CreateCompliancePolicy creates a new compliance policy
 func (c *GraphClient) CreateCompliancePolicy(ctx context.Context, policy *CompliancePolicy) (*CompliancePolicy, error) {
-	resp, err := c.Post(ctx, PathCompliancePolicies, policy)
+	created, err := PostInto[CompliancePolicy](ctx, c, PathCompliancePolicies, policy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create compliance policy: %w", err)
 	}
 
-	respBytes, _ := json.Marshal(resp)
-	var created CompliancePolicy
-	if err := json.Unmarshal(respBytes, &created); err != nil {
-		return nil, fmt.Errorf("failed to parse created policy: %w", err)
-	}
-
-	if created.ID == "" {
-		created.ID = resp.ID
-	}
-
-	return &created, nil
+	return created, nil
 }
 
 // GetCompliancePolicy retrieves a compliance policy by ID
 func (c *GraphClient) GetCompliancePolicy(ctx context.Context, id string) (*CompliancePolicy, error) {
 	path := fmt.Sprintf("%s/%s", PathCompliancePolicies, id)
-	resp, err := c.Get(ctx, path)
+	policy, err := GetInto[CompliancePolicy](ctx, c, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get compliance policy: %w", err)
 	}
 
-	respBytes, _ := json.Marshal(resp)
-	var policy CompliancePolicy
-	if err := json.Unmarshal(respBytes, &policy); err != nil {
-		return nil, fmt.Errorf("failed to parse policy: %w", err)
-	}
-
-	if policy.ID == "" {
-		policy.ID = resp.ID
-	}
-
-	return &policy, nil
+	return policy, nil
 }
 
 // UpdateCompliancePolicy updates a compliance policy
@@ -604,59 +1244,222 @@ func (c *GraphClient) DeleteCompliancePolicy(ctx context.Context, id string) err
 	return c.Delete(ctx, path)
 }
 
-// GetPolicyAssignments retrieves assignments for a policy
-func (c *GraphClient) GetPolicyAssignments(ctx context.Context, policyPath string, policyId string) ([]PolicyAssignment, error) {
-	path := fmt.Sprintf("%s('%s')%s", policyPath, policyId, PathAssignments)
-	resp, err := c.Get(ctx, path)
+// GetCompliancePolicyScheduledActions retrieves the scheduled actions of a compliance policy,
+// with their action configurations
+func (c *GraphClient) GetCompliancePolicyScheduledActions(ctx context.Context, id string) ([]ComplianceScheduledAction, error) {
+	path := fmt.Sprintf("%s/%s/scheduledActionsForRule?$expand=scheduledActionConfigurations", PathCompliancePolicies, id)
+	actions, err := ListInto[ComplianceScheduledAction](ctx, c, path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get policy assignments: %w", err)
+		return nil, fmt.Errorf("failed to get compliance policy scheduled actions: %w", err)
 	}
 
-	var assignments []PolicyAssignment
-	if resp.Value != nil {
-		if err := json.Unmarshal(resp.Value, &assignments); err != nil {
-			return nil, fmt.Errorf("failed to parse assignments: %w", err)
-		}
-	}
-
-	return assignments, nil
+	return actions, nil
 }
 
-// AssignPolicy assigns a policy to groups
-func (c *GraphClient) AssignPolicy(ctx context.Context, policyPath string, policyId string, assignments []PolicyAssignment) error {
-	path := fmt.Sprintf("%s('%s')/assign", policyPath, policyId)
-
+// ScheduleCompliancePolicyActions replaces the scheduled actions of a compliance policy
+func (c *GraphClient) ScheduleCompliancePolicyActions(ctx context.Context, id string, actions []ComplianceScheduledAction) error {
+	path := fmt.Sprintf("%s/%s/scheduleActionsForRules", PathCompliancePolicies, id)
 	body := map[string]interface{}{
-		"assignments": assignments,
+		"deviceComplianceScheduledActionForRules": actions,
 	}
 
-	_, err := c.Post(ctx, path, body)
-	if err != nil {
-		return fmt.Errorf("failed to assign policy: %w", err)
+	if _, err := c.Post(ctx, path, body); err != nil {
+		return fmt.Errorf("failed to schedule compliance policy actions: %w", err)
 	}
 
 	return nil
 }
 
-// ListSettingDefinitions lists setting definitions for the Settings Catalog
-func (c *GraphClient) ListSettingDefinitions(ctx context.Context, filter string) ([]SettingDefinition, error) {
-	path := "/deviceManagement/configurationSettings"
-	if filter != "" {
-		path = fmt.Sprintf("%s?$filter=%s", path, url.QueryEscape(filter))
+// CreateSettingsCompliancePolicy creates a new Settings Catalog based compliance policy
+func (c *GraphClient) CreateSettingsCompliancePolicy(ctx context.Context, policy *SettingsCompliancePolicy) (*SettingsCompliancePolicy, error) {
+	created, err := PostInto[SettingsCompliancePolicy](ctx, c, PathSettingsCompliancePolicies, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compliance policy: %w", err)
 	}
 
-	items, err := c.ListAll(ctx, path)
+	return created, nil
+}
+
+// GetSettingsCompliancePolicy retrieves a Settings Catalog based compliance policy by ID, with its settings
+func (c *GraphClient) GetSettingsCompliancePolicy(ctx context.Context, id string) (*SettingsCompliancePolicy, error) {
+	path := fmt.Sprintf("%s('%s')?$expand=settings", PathSettingsCompliancePolicies, id)
+	policy, err := GetInto[SettingsCompliancePolicy](ctx, c, path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list setting definitions: %w", err)
+		return nil, fmt.Errorf("failed to get compliance policy: %w", err)
 	}
 
-	var definitions []SettingDefinition
-	for _, item := range items {
-		var def SettingDefinition
-		if err := json.Unmarshal(item, &def); err != nil {
-			continue
-		}
-		definitions = append(definitions, def)
+	return policy, nil
+}
+
+// UpdateSettingsCompliancePolicy replaces a Settings Catalog based compliance policy and its
+// settings. Graph only accepts settings as part of a full replacement.
+func (c *GraphClient) UpdateSettingsCompliancePolicy(ctx context.Context, id string, policy *SettingsCompliancePolicy) (*SettingsCompliancePolicy, error) {
+	path := fmt.Sprintf("%s('%s')", PathSettingsCompliancePolicies, id)
+
+	// Read-only properties are rejected in a PUT body, and scheduled actions are not replaced
+	body := *policy
+	body.ID = ""
+	body.CreatedDateTime = ""
+	body.LastModifiedDateTime = ""
+	body.SettingCount = 0
+	body.ScheduledActionsForRule = nil
+	if body.Settings == nil {
+		body.Settings = []SettingsCatalogPolicySetting{}
+	}
+
+	if _, err := c.Put(ctx, path, body); err != nil {
+		return nil, fmt.Errorf("failed to update compliance policy: %w", err)
+	}
+
+	return c.GetSettingsCompliancePolicy(ctx, id)
+}
+
+// GetSettingsCompliancePolicyScheduledActions retrieves the scheduled actions of a Settings
+// Catalog based compliance policy, with their action configurations
+func (c *GraphClient) GetSettingsCompliancePolicyScheduledActions(ctx context.Context, id string) ([]ComplianceScheduledAction, error) {
+	path := fmt.Sprintf("%s('%s')/scheduledActionsForRule?$expand=scheduledActionConfigurations", PathSettingsCompliancePolicies, id)
+	actions, err := ListInto[ComplianceScheduledAction](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compliance policy scheduled actions: %w", err)
+	}
+
+	return actions, nil
+}
+
+// SetSettingsCompliancePolicyScheduledActions replaces the scheduled actions of a Settings Catalog
+// based compliance policy
+func (c *GraphClient) SetSettingsCompliancePolicyScheduledActions(ctx context.Context, id string, actions []ComplianceScheduledAction) error {
+	path := fmt.Sprintf("%s('%s')/setScheduledActions", PathSettingsCompliancePolicies, id)
+	body := map[string]interface{}{
+		"scheduledActions": actions,
+	}
+
+	if _, err := c.Post(ctx, path, body); err != nil {
+		return fmt.Errorf("failed to set compliance policy scheduled actions: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSettingsCompliancePolicy deletes a Settings Catalog based compliance policy
+func (c *GraphClient) DeleteSettingsCompliancePolicy(ctx context.Context, id string) error {
+	path := fmt.Sprintf("%s('%s')", PathSettingsCompliancePolicies, id)
+	return c.Delete(ctx, path)
+}
+
+// ListConfigurationPolicyTemplates lists the templates available for template-backed Settings Catalog policies
+func (c *GraphClient) ListConfigurationPolicyTemplates(ctx context.Context) ([]ConfigurationPolicyTemplate, error) {
+	templates, err := ListInto[ConfigurationPolicyTemplate](ctx, c, PathSettingsCatalogDefinitions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configuration policy templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// GetConfigurationPolicyTemplate retrieves a configuration policy template by ID
+func (c *GraphClient) GetConfigurationPolicyTemplate(ctx context.Context, id string) (*ConfigurationPolicyTemplate, error) {
+	path := fmt.Sprintf("%s('%s')", PathSettingsCatalogDefinitions, id)
+	template, err := GetInto[ConfigurationPolicyTemplate](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration policy template: %w", err)
+	}
+
+	return template, nil
+}
+
+// GetConfigurationPolicyTemplateSettings retrieves the setting templates of a configuration policy template
+func (c *GraphClient) GetConfigurationPolicyTemplateSettings(ctx context.Context, id string) ([]ConfigurationSettingTemplate, error) {
+	path := fmt.Sprintf("%s('%s')/settingTemplates", PathSettingsCatalogDefinitions, id)
+	settings, err := ListInto[ConfigurationSettingTemplate](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration policy template settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// ListEndpointSecurityTemplates lists the templates available for endpoint security intents
+func (c *GraphClient) ListEndpointSecurityTemplates(ctx context.Context) ([]EndpointSecurityTemplate, error) {
+	templates, err := ListInto[EndpointSecurityTemplate](ctx, c, PathEndpointSecurityTemplates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoint security templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// GetEndpointSecurityPolicySettings retrieves the settings of an endpoint security intent
+func (c *GraphClient) GetEndpointSecurityPolicySettings(ctx context.Context, id string) ([]IntentSetting, error) {
+	path := fmt.Sprintf("%s/%s/settings", PathEndpointSecurityPolicies, id)
+	settings, err := ListInto[IntentSetting](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endpoint security policy settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// UpdateEndpointSecurityPolicySettings writes setting instances to an endpoint security intent.
+// Settings that are not included keep their current value.
+func (c *GraphClient) UpdateEndpointSecurityPolicySettings(ctx context.Context, id string, settings []IntentSetting) error {
+	path := fmt.Sprintf("%s/%s/updateSettings", PathEndpointSecurityPolicies, id)
+	if _, err := c.Post(ctx, path, map[string]interface{}{"settings": settings}); err != nil {
+		return fmt.Errorf("failed to update endpoint security policy settings: %w", err)
+	}
+
+	return nil
+}
+
+// GetEndpointSecurityTemplateSettings retrieves the setting instances an endpoint security
+// template defines, which determine the settings and value types its intents accept
+func (c *GraphClient) GetEndpointSecurityTemplateSettings(ctx context.Context, templateId string) ([]IntentSetting, error) {
+	path := fmt.Sprintf("%s/%s/settings", PathEndpointSecurityTemplates, templateId)
+	settings, err := ListInto[IntentSetting](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endpoint security template settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// GetPolicyAssignments retrieves assignments for a policy
+func (c *GraphClient) GetPolicyAssignments(ctx context.Context, policyPath string, policyId string) ([]PolicyAssignment, error) {
+	path := fmt.Sprintf("%s('%s')%s", policyPath, policyId, PathAssignments)
+	assignments, err := ListInto[PolicyAssignment](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy assignments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// AssignPolicy assigns a policy to groups
+func (c *GraphClient) AssignPolicy(ctx context.Context, policyPath string, policyId string, assignments []PolicyAssignment) error {
+	path := fmt.Sprintf("%s('%s')/assign", policyPath, policyId)
+
+	body := map[string]interface{}{
+		"assignments": assignments,
+	}
+
+	_, err := c.Post(ctx, path, body)
+	if err != nil {
+		return fmt.Errorf("failed to assign policy: %w", err)
+	}
+
+	return nil
+}
+
+// ListSettingDefinitions lists setting definitions for the Settings Catalog
+func (c *GraphClient) ListSettingDefinitions(ctx context.Context, filter string) ([]SettingDefinition, error) {
+	path := "/deviceManagement/configurationSettings"
+	if filter != "" {
+		path = fmt.Sprintf("%s?$filter=%s", path, url.QueryEscape(filter))
+	}
+
+	definitions, err := ListInto[SettingDefinition](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list setting definitions: %w", err)
 	}
 
 	return definitions, nil
@@ -665,63 +1468,479 @@ func (c *GraphClient) ListSettingDefinitions(ctx context.Context, filter string)
 // GetSettingDefinition retrieves a specific setting definition
 func (c *GraphClient) GetSettingDefinition(ctx context.Context, id string) (*SettingDefinition, error) {
 	path := fmt.Sprintf("/deviceManagement/configurationSettings('%s')", id)
-	resp, err := c.Get(ctx, path)
+	def, err := GetInto[SettingDefinition](ctx, c, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get setting definition: %w", err)
 	}
 
-	respBytes, _ := json.Marshal(resp)
-	var def SettingDefinition
-	if err := json.Unmarshal(respBytes, &def); err != nil {
-		return nil, fmt.Errorf("failed to parse setting definition: %w", err)
+	return def, nil
+}
+
+// GetSettingDefinitions retrieves several setting definitions using JSON batching. Definitions
+// that do not exist are missing from the result.
+func (c *GraphClient) GetSettingDefinitions(ctx context.Context, ids []string) (map[string]*SettingDefinition, error) {
+	requests := make([]BatchRequest, 0, len(ids))
+	for _, id := range ids {
+		requests = append(requests, BatchRequest{
+			Method: http.MethodGet,
+			Path:   fmt.Sprintf("/deviceManagement/configurationSettings('%s')", id),
+		})
+	}
+
+	responses, err := c.Batch(ctx, requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get setting definitions: %w", err)
 	}
 
-	return &def, nil
+	definitions := make(map[string]*SettingDefinition, len(ids))
+	for i, resp := range responses {
+		if IsNotFound(resp.Err) {
+			continue
+		}
+		var def SettingDefinition
+		if err := resp.Decode(&def); err != nil {
+			return nil, fmt.Errorf("failed to get setting definition %s: %w", ids[i], err)
+		}
+		definitions[ids[i]] = &def
+	}
+
+	return definitions, nil
 }
 
 // ============================================================================
-// Scope Tag Methods
+// Device Compliance Script Methods
 // ============================================================================
 
-// CreateScopeTag creates a new role scope tag
-func (c *GraphClient) CreateScopeTag(ctx context.Context, tag *ScopeTag) (*ScopeTag, error) {
-	resp, err := c.Post(ctx, PathScopeTags, tag)
+// CreateDeviceComplianceScript creates a new custom compliance discovery script
+func (c *GraphClient) CreateDeviceComplianceScript(ctx context.Context, script *DeviceComplianceScript) (*DeviceComplianceScript, error) {
+	created, err := PostInto[DeviceComplianceScript](ctx, c, PathDeviceComplianceScripts, script)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create scope tag: %w", err)
+		return nil, fmt.Errorf("failed to create device compliance script: %w", err)
 	}
 
-	respBytes, _ := json.Marshal(resp)
-	var created ScopeTag
-	if err := json.Unmarshal(respBytes, &created); err != nil {
-		return nil, fmt.Errorf("failed to parse created scope tag: %w", err)
+	return created, nil
+}
+
+// GetDeviceComplianceScript retrieves a custom compliance discovery script by ID, with its content
+func (c *GraphClient) GetDeviceComplianceScript(ctx context.Context, id string) (*DeviceComplianceScript, error) {
+	path := fmt.Sprintf("%s/%s", PathDeviceComplianceScripts, id)
+	script, err := GetInto[DeviceComplianceScript](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device compliance script: %w", err)
 	}
 
-	if created.ID == "" {
-		created.ID = resp.ID
+	return script, nil
+}
+
+// UpdateDeviceComplianceScript updates a custom compliance discovery script
+func (c *GraphClient) UpdateDeviceComplianceScript(ctx context.Context, id string, script *DeviceComplianceScript) (*DeviceComplianceScript, error) {
+	path := fmt.Sprintf("%s/%s", PathDeviceComplianceScripts, id)
+	_, err := c.Patch(ctx, path, script)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update device compliance script: %w", err)
 	}
 
-	return &created, nil
+	return c.GetDeviceComplianceScript(ctx, id)
 }
 
-// GetScopeTag retrieves a scope tag by ID
-func (c *GraphClient) GetScopeTag(ctx context.Context, id string) (*ScopeTag, error) {
-	path := fmt.Sprintf("%s/%s", PathScopeTags, id)
-	resp, err := c.Get(ctx, path)
+// DeleteDeviceComplianceScript deletes a custom compliance discovery script
+func (c *GraphClient) DeleteDeviceComplianceScript(ctx context.Context, id string) error {
+	path := fmt.Sprintf("%s/%s", PathDeviceComplianceScripts, id)
+	return c.Delete(ctx, path)
+}
+
+// ============================================================================
+// Notification Message Template Methods
+// ============================================================================
+
+// CreateNotificationMessageTemplate creates a new notification message template. Localized
+// messages are created separately.
+func (c *GraphClient) CreateNotificationMessageTemplate(ctx context.Context, template *NotificationMessageTemplate) (*NotificationMessageTemplate, error) {
+	created, err := PostInto[NotificationMessageTemplate](ctx, c, PathNotificationMessageTemplates, template)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get scope tag: %w", err)
+		return nil, fmt.Errorf("failed to create notification message template: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetNotificationMessageTemplate retrieves a notification message template by ID, with its
+// localized messages
+func (c *GraphClient) GetNotificationMessageTemplate(ctx context.Context, id string) (*NotificationMessageTemplate, error) {
+	path := fmt.Sprintf("%s/%s?$expand=localizedNotificationMessages", PathNotificationMessageTemplates, id)
+	template, err := GetInto[NotificationMessageTemplate](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification message template: %w", err)
+	}
+
+	return template, nil
+}
+
+// UpdateNotificationMessageTemplate updates the properties of a notification message template
+func (c *GraphClient) UpdateNotificationMessageTemplate(ctx context.Context, id string, template *NotificationMessageTemplate) error {
+	path := fmt.Sprintf("%s/%s", PathNotificationMessageTemplates, id)
+	if _, err := c.Patch(ctx, path, template); err != nil {
+		return fmt.Errorf("failed to update notification message template: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteNotificationMessageTemplate deletes a notification message template
+func (c *GraphClient) DeleteNotificationMessageTemplate(ctx context.Context, id string) error {
+	path := fmt.Sprintf("%s/%s", PathNotificationMessageTemplates, id)
+	return c.Delete(ctx, path)
+}
+
+// CreateLocalizedNotificationMessage adds a localized message to a notification message template
+func (c *GraphClient) CreateLocalizedNotificationMessage(ctx context.Context, templateId string, message *LocalizedNotificationMessage) (*LocalizedNotificationMessage, error) {
+	path := fmt.Sprintf("%s/%s/localizedNotificationMessages", PathNotificationMessageTemplates, templateId)
+	created, err := PostInto[LocalizedNotificationMessage](ctx, c, path, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create localized notification message: %w", err)
+	}
+
+	return created, nil
+}
+
+// UpdateLocalizedNotificationMessage updates a localized message of a notification message template
+func (c *GraphClient) UpdateLocalizedNotificationMessage(ctx context.Context, templateId string, message *LocalizedNotificationMessage) error {
+	path := fmt.Sprintf("%s/%s/localizedNotificationMessages/%s", PathNotificationMessageTemplates, templateId, message.ID)
+	if _, err := c.Patch(ctx, path, message); err != nil {
+		return fmt.Errorf("failed to update localized notification message: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteLocalizedNotificationMessage deletes a localized message of a notification message template
+func (c *GraphClient) DeleteLocalizedNotificationMessage(ctx context.Context, templateId, messageId string) error {
+	path := fmt.Sprintf("%s/%s/localizedNotificationMessages/%s", PathNotificationMessageTemplates, templateId, messageId)
+	return c.Delete(ctx, path)
+}
+
+// ============================================================================
+// Device Configuration Methods
+// ============================================================================
+
+// CreateDeviceConfiguration creates a new device configuration profile
+func (c *GraphClient) CreateDeviceConfiguration(ctx context.Context, profile *DeviceConfiguration) (*DeviceConfiguration, error) {
+	created, err := PostInto[DeviceConfiguration](ctx, c, PathDeviceConfigurations, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create device configuration: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetDeviceConfiguration retrieves a device configuration profile by ID
+func (c *GraphClient) GetDeviceConfiguration(ctx context.Context, id string) (*DeviceConfiguration, error) {
+	path := fmt.Sprintf("%s/%s", PathDeviceConfigurations, id)
+	profile, err := GetInto[DeviceConfiguration](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device configuration: %w", err)
+	}
+
+	return profile, nil
+}
+
+// UpdateDeviceConfiguration updates a device configuration profile. Graph requires the
+// @odata.type of the profile in the body.
+func (c *GraphClient) UpdateDeviceConfiguration(ctx context.Context, id string, profile *DeviceConfiguration) (*DeviceConfiguration, error) {
+	path := fmt.Sprintf("%s/%s", PathDeviceConfigurations, id)
+	_, err := c.Patch(ctx, path, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update device configuration: %w", err)
+	}
+
+	return c.GetDeviceConfiguration(ctx, id)
+}
+
+// DeleteDeviceConfiguration deletes a device configuration profile
+func (c *GraphClient) DeleteDeviceConfiguration(ctx context.Context, id string) error {
+	path := fmt.Sprintf("%s/%s", PathDeviceConfigurations, id)
+	return c.Delete(ctx, path)
+}
+
+// GetOmaSettingPlainTextValue returns the plain text of an encrypted OMA-URI setting of a custom
+// profile, which Graph only returns encrypted when the profile is read
+func (c *GraphClient) GetOmaSettingPlainTextValue(ctx context.Context, profileId, secretReferenceValueId string) (string, error) {
+	path := fmt.Sprintf("%s/%s/getOmaSettingPlainTextValue(secretReferenceValueId='%s')", PathDeviceConfigurations, profileId, secretReferenceValueId)
+	result, err := GetInto[struct {
+		Value string `json:"value"`
+	}](ctx, c, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get OMA-URI setting value: %w", err)
+	}
+
+	return result.Value, nil
+}
+
+// ============================================================================
+// Windows Update Profile Methods
+// ============================================================================
+
+// CreateWindowsFeatureUpdateProfile creates a new Windows feature update profile
+func (c *GraphClient) CreateWindowsFeatureUpdateProfile(ctx context.Context, profile *WindowsFeatureUpdateProfile) (*WindowsFeatureUpdateProfile, error) {
+	created, err := PostInto[WindowsFeatureUpdateProfile](ctx, c, PathWindowsFeatureUpdateProfiles, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Windows feature update profile: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetWindowsFeatureUpdateProfile retrieves a Windows feature update profile by ID
+func (c *GraphClient) GetWindowsFeatureUpdateProfile(ctx context.Context, id string) (*WindowsFeatureUpdateProfile, error) {
+	path := fmt.Sprintf("%s/%s", PathWindowsFeatureUpdateProfiles, id)
+	profile, err := GetInto[WindowsFeatureUpdateProfile](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Windows feature update profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// UpdateWindowsFeatureUpdateProfile updates a Windows feature update profile
+func (c *GraphClient) UpdateWindowsFeatureUpdateProfile(ctx context.Context, id string, profile *WindowsFeatureUpdateProfile) (*WindowsFeatureUpdateProfile, error) {
+	path := fmt.Sprintf("%s/%s", PathWindowsFeatureUpdateProfiles, id)
+	_, err := c.Patch(ctx, path, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update Windows feature update profile: %w", err)
+	}
+
+	return c.GetWindowsFeatureUpdateProfile(ctx, id)
+}
+
+// DeleteWindowsFeatureUpdateProfile deletes a Windows feature update profile
+func (c *GraphClient) DeleteWindowsFeatureUpdateProfile(ctx context.Context, id string) error {
+	path := fmt.Sprintf("%s/%s", PathWindowsFeatureUpdateProfiles, id)
+	return c.Delete(ctx, path)
+}
+
+// CreateWindowsQualityUpdateProfile creates a new Windows quality update profile
+func (c *GraphClient) CreateWindowsQualityUpdateProfile(ctx context.Context, profile *WindowsQualityUpdateProfile) (*WindowsQualityUpdateProfile, error) {
+	created, err := PostInto[WindowsQualityUpdateProfile](ctx, c, PathWindowsQualityUpdateProfiles, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Windows quality update profile: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetWindowsQualityUpdateProfile retrieves a Windows quality update profile by ID
+func (c *GraphClient) GetWindowsQualityUpdateProfile(ctx context.Context, id string) (*WindowsQualityUpdateProfile, error) {
+	path := fmt.Sprintf("%s/%s", PathWindowsQualityUpdateProfiles, id)
+	profile, err := GetInto[WindowsQualityUpdateProfile](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Windows quality update profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// UpdateWindowsQualityUpdateProfile updates a Windows quality update profile
+func (c *GraphClient) UpdateWindowsQualityUpdateProfile(ctx context.Context, id string, profile *WindowsQualityUpdateProfile) (*WindowsQualityUpdateProfile, error) {
+	path := fmt.Sprintf("%s/%s", PathWindowsQualityUpdateProfiles, id)
+	_, err := c.Patch(ctx, path, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update Windows quality update profile: %w", err)
+	}
+
+	return c.GetWindowsQualityUpdateProfile(ctx, id)
+}
+
+// DeleteWindowsQualityUpdateProfile deletes a Windows quality update profile
+func (c *GraphClient) DeleteWindowsQualityUpdateProfile(ctx context.Context, id string) error {
+	path := fmt.Sprintf("%s/%s", PathWindowsQualityUpdateProfiles, id)
+	return c.Delete(ctx, path)
+}
+
+// CreateWindowsDriverUpdateProfile creates a new Windows driver update profile
+func (c *GraphClient) CreateWindowsDriverUpdateProfile(ctx context.Context, profile *WindowsDriverUpdateProfile) (*WindowsDriverUpdateProfile, error) {
+	created, err := PostInto[WindowsDriverUpdateProfile](ctx, c, PathWindowsDriverUpdateProfiles, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Windows driver update profile: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetWindowsDriverUpdateProfile retrieves a Windows driver update profile by ID
+func (c *GraphClient) GetWindowsDriverUpdateProfile(ctx context.Context, id string) (*WindowsDriverUpdateProfile, error) {
+	path := fmt.Sprintf("%s/%s", PathWindowsDriverUpdateProfiles, id)
+	profile, err := GetInto[WindowsDriverUpdateProfile](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Windows driver update profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// UpdateWindowsDriverUpdateProfile updates a Windows driver update profile. The approval type
+// cannot be changed.
+func (c *GraphClient) UpdateWindowsDriverUpdateProfile(ctx context.Context, id string, profile *WindowsDriverUpdateProfile) (*WindowsDriverUpdateProfile, error) {
+	path := fmt.Sprintf("%s/%s", PathWindowsDriverUpdateProfiles, id)
+	_, err := c.Patch(ctx, path, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update Windows driver update profile: %w", err)
+	}
+
+	return c.GetWindowsDriverUpdateProfile(ctx, id)
+}
+
+// DeleteWindowsDriverUpdateProfile deletes a Windows driver update profile
+func (c *GraphClient) DeleteWindowsDriverUpdateProfile(ctx context.Context, id string) error {
+	path := fmt.Sprintf("%s/%s", PathWindowsDriverUpdateProfiles, id)
+	return c.Delete(ctx, path)
+}
+
+// ListWindowsDriverUpdateInventories lists the drivers applicable to the devices of a driver
+// update profile
+func (c *GraphClient) ListWindowsDriverUpdateInventories(ctx context.Context, profileId string) ([]WindowsDriverUpdateInventory, error) {
+	path := fmt.Sprintf("%s/%s/driverInventories", PathWindowsDriverUpdateProfiles, profileId)
+	drivers, err := ListInto[WindowsDriverUpdateInventory](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list driver inventories: %w", err)
+	}
+
+	return drivers, nil
+}
+
+// ExecuteWindowsDriverUpdateAction approves, declines or suspends drivers of a driver update
+// profile. Approved drivers are deployed from deploymentDate, or immediately when it is empty.
+func (c *GraphClient) ExecuteWindowsDriverUpdateAction(ctx context.Context, profileId, action string, driverIds []string, deploymentDate string) error {
+	path := fmt.Sprintf("%s/%s/executeAction", PathWindowsDriverUpdateProfiles, profileId)
+	body := map[string]interface{}{
+		"actionName": action,
+		"driverIds":  driverIds,
 	}
+	if deploymentDate != "" {
+		body["deploymentDate"] = deploymentDate
+	}
+	if _, err := c.Post(ctx, path, body); err != nil {
+		return fmt.Errorf("failed to %s drivers: %w", action, err)
+	}
+
+	return nil
+}
+
+// ============================================================================
+// Enrollment Methods
+// ============================================================================
+
+// CreateAutopilotDeploymentProfile creates a new Windows Autopilot deployment profile
+func (c *GraphClient) CreateAutopilotDeploymentProfile(ctx context.Context, profile *WindowsAutopilotDeploymentProfile) (*WindowsAutopilotDeploymentProfile, error) {
+	created, err := PostInto[WindowsAutopilotDeploymentProfile](ctx, c, PathAutopilotDeploymentProfiles, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Autopilot deployment profile: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetAutopilotDeploymentProfile retrieves a Windows Autopilot deployment profile by ID
+func (c *GraphClient) GetAutopilotDeploymentProfile(ctx context.Context, id string) (*WindowsAutopilotDeploymentProfile, error) {
+	path := fmt.Sprintf("%s/%s", PathAutopilotDeploymentProfiles, id)
+	profile, err := GetInto[WindowsAutopilotDeploymentProfile](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Autopilot deployment profile: %w", err)
+	}
+
+	return profile, nil
+}
+
+// UpdateAutopilotDeploymentProfile updates a Windows Autopilot deployment profile
+func (c *GraphClient) UpdateAutopilotDeploymentProfile(ctx context.Context, id string, profile *WindowsAutopilotDeploymentProfile) (*WindowsAutopilotDeploymentProfile, error) {
+	path := fmt.Sprintf("%s/%s", PathAutopilotDeploymentProfiles, id)
+	_, err := c.Patch(ctx, path, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update Autopilot deployment profile: %w", err)
+	}
+
+	return c.GetAutopilotDeploymentProfile(ctx, id)
+}
+
+// DeleteAutopilotDeploymentProfile deletes a Windows Autopilot deployment profile
+func (c *GraphClient) DeleteAutopilotDeploymentProfile(ctx context.Context, id string) error {
+	path := fmt.Sprintf("%s/%s", PathAutopilotDeploymentProfiles, id)
+	return c.Delete(ctx, path)
+}
+
+// CreateEnrollmentCompletionPageConfiguration creates a new Enrollment Status Page. Intune gives
+// it the lowest priority.
+func (c *GraphClient) CreateEnrollmentCompletionPageConfiguration(ctx context.Context, config *EnrollmentCompletionPageConfiguration) (*EnrollmentCompletionPageConfiguration, error) {
+	created, err := PostInto[EnrollmentCompletionPageConfiguration](ctx, c, PathDeviceEnrollmentConfigurations, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Enrollment Status Page: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetEnrollmentCompletionPageConfiguration retrieves an Enrollment Status Page by ID
+func (c *GraphClient) GetEnrollmentCompletionPageConfiguration(ctx context.Context, id string) (*EnrollmentCompletionPageConfiguration, error) {
+	path := fmt.Sprintf("%s/%s", PathDeviceEnrollmentConfigurations, id)
+	config, err := GetInto[EnrollmentCompletionPageConfiguration](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Enrollment Status Page: %w", err)
+	}
+
+	return config, nil
+}
+
+// UpdateEnrollmentCompletionPageConfiguration updates an Enrollment Status Page. The priority is
+// not part of the update, see SetEnrollmentConfigurationPriority.
+func (c *GraphClient) UpdateEnrollmentCompletionPageConfiguration(ctx context.Context, id string, config *EnrollmentCompletionPageConfiguration) (*EnrollmentCompletionPageConfiguration, error) {
+	path := fmt.Sprintf("%s/%s", PathDeviceEnrollmentConfigurations, id)
+	_, err := c.Patch(ctx, path, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update Enrollment Status Page: %w", err)
+	}
+
+	return c.GetEnrollmentCompletionPageConfiguration(ctx, id)
+}
 
-	respBytes, _ := json.Marshal(resp)
-	var tag ScopeTag
-	if err := json.Unmarshal(respBytes, &tag); err != nil {
-		return nil, fmt.Errorf("failed to parse scope tag: %w", err)
+// DeleteEnrollmentCompletionPageConfiguration deletes an Enrollment Status Page
+func (c *GraphClient) DeleteEnrollmentCompletionPageConfiguration(ctx context.Context, id string) error {
+	path := fmt.Sprintf("%s/%s", PathDeviceEnrollmentConfigurations, id)
+	return c.Delete(ctx, path)
+}
+
+// SetEnrollmentConfigurationPriority moves a device enrollment configuration to a priority.
+// Intune shifts the other configurations of the same type to keep their priorities unique.
+func (c *GraphClient) SetEnrollmentConfigurationPriority(ctx context.Context, id string, priority int) error {
+	path := fmt.Sprintf("%s/%s/setPriority", PathDeviceEnrollmentConfigurations, id)
+	if _, err := c.Post(ctx, path, map[string]interface{}{"priority": priority}); err != nil {
+		return fmt.Errorf("failed to set enrollment configuration priority: %w", err)
 	}
 
-	if tag.ID == "" {
-		tag.ID = resp.ID
+	return nil
+}
+
+// ============================================================================
+// Scope Tag Methods
+// ============================================================================
+
+// CreateScopeTag creates a new role scope tag
+func (c *GraphClient) CreateScopeTag(ctx context.Context, tag *ScopeTag) (*ScopeTag, error) {
+	created, err := PostInto[ScopeTag](ctx, c, PathScopeTags, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scope tag: %w", err)
 	}
 
-	return &tag, nil
+	return created, nil
+}
+
+// GetScopeTag retrieves a scope tag by ID
+func (c *GraphClient) GetScopeTag(ctx context.Context, id string) (*ScopeTag, error) {
+	path := fmt.Sprintf("%s/%s", PathScopeTags, id)
+	tag, err := GetInto[ScopeTag](ctx, c, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scope tag: %w", err)
+	}
+
+	return tag, nil
 }
 
 // UpdateScopeTag updates a scope tag
@@ -743,20 +1962,11 @@ func (c *GraphClient) DeleteScopeTag(ctx context.Context, id string) error {
 
 // ListScopeTags lists all scope tags
 func (c *GraphClient) ListScopeTags(ctx context.Context) ([]ScopeTag, error) {
-	items, err := c.ListAll(ctx, PathScopeTags)
+	tags, err := ListInto[ScopeTag](ctx, c, PathScopeTags)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list scope tags: %w", err)
 	}
 
-	var tags []ScopeTag
-	for _, item := range items {
-		var tag ScopeTag
-		if err := json.Unmarshal(item, &tag); err != nil {
-			continue
-		}
-		tags = append(tags, tag)
-	}
-
 	return tags, nil
 }
 
@@ -766,43 +1976,23 @@ func (c *GraphClient) ListScopeTags(ctx context.Context) ([]ScopeTag, error) {
 
 // CreateAssignmentFilter creates a new assignment filter
 func (c *GraphClient) CreateAssignmentFilter(ctx context.Context, filter *AssignmentFilter) (*AssignmentFilter, error) {
-	resp, err := c.Post(ctx, PathAssignmentFilters, filter)
+	created, err := PostInto[AssignmentFilter](ctx, c, PathAssignmentFilters, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create assignment filter: %w", err)
 	}
 
-	respBytes, _ := json.Marshal(resp)
-	var created AssignmentFilter
-	if err := json.Unmarshal(respBytes, &created); err != nil {
-		return nil, fmt.Errorf("failed to parse created assignment filter: %w", err)
-	}
-
-	if created.ID == "" {
-		created.ID = resp.ID
-	}
-
-	return &created, nil
+	return created, nil
 }
 
 // GetAssignmentFilter retrieves an assignment filter by ID
 func (c *GraphClient) GetAssignmentFilter(ctx context.Context, id string) (*AssignmentFilter, error) {
 	path := fmt.Sprintf("%s/%s", PathAssignmentFilters, id)
-	resp, err := c.Get(ctx, path)
+	filter, err := GetInto[AssignmentFilter](ctx, c, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get assignment filter: %w", err)
 	}
 
-	respBytes, _ := json.Marshal(resp)
-	var filter AssignmentFilter
-	if err := json.Unmarshal(respBytes, &filter); err != nil {
-		return nil, fmt.Errorf("failed to parse assignment filter: %w", err)
-	}
-
-	if filter.ID == "" {
-		filter.ID = resp.ID
-	}
-
-	return &filter, nil
+	return filter, nil
 }
 
 // UpdateAssignmentFilter updates an assignment filter
@@ -824,19 +2014,10 @@ func (c *GraphClient) DeleteAssignmentFilter(ctx context.Context, id string) err
 
 // ListAssignmentFilters lists all assignment filters
 func (c *GraphClient) ListAssignmentFilters(ctx context.Context) ([]AssignmentFilter, error) {
-	items, err := c.ListAll(ctx, PathAssignmentFilters)
+	filters, err := ListInto[AssignmentFilter](ctx, c, PathAssignmentFilters)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list assignment filters: %w", err)
 	}
 
-	var filters []AssignmentFilter
-	for _, item := range items {
-		var filter AssignmentFilter
-		if err := json.Unmarshal(item, &filter); err != nil {
-			continue
-		}
-		filters = append(filters, filter)
-	}
-
 	return filters, nil
 }
@@ -0,0 +1,173 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+type staticTokenSource string
+
+func (s staticTokenSource) GetToken(ctx context.Context, scopes []string) (string, error) {
+	return string(s), nil
+}
+
+func newTestClient(t *testing.T) (*GraphClient, *fakegraph.Server) {
+	t.Helper()
+
+	graph := fakegraph.NewServer()
+	t.Cleanup(graph.Close)
+
+	client := NewGraphClient(nil, "TofuTune/test", &GraphClientOptions{
+		BaseURL:      graph.BaseURL(),
+		TokenSource:  staticTokenSource(fakegraph.Token),
+		MaxRetries:   3,
+		MaxRetryWait: 10 * time.Millisecond,
+	})
+	return client, graph
+}
+
+func TestGraphClientRetriesThrottledRequests(t *testing.T) {
+	client, graph := newTestClient(t)
+	ctx := context.Background()
+
+	graph.Throttle(2)
+	tag, err := client.CreateScopeTag(ctx, &ScopeTag{DisplayName: "Helpdesk"})
+	if err != nil {
+		t.Fatalf("CreateScopeTag: %s", err)
+	}
+	if tag.ID == "" {
+		t.Errorf("expected the created scope tag to have an ID")
+	}
+
+	graph.Throttle(4)
+	if _, err := client.GetScopeTag(ctx, tag.ID); err == nil {
+		t.Errorf("expected an error once retries are exhausted")
+	}
+}
+
+func TestGraphClientErrors(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	_, err := client.GetScopeTag(ctx, "42")
+	if !IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	err = client.DeleteScopeTag(ctx, "0")
+	if err == nil || IsNotFound(err) {
+		t.Errorf("expected deleting the built-in scope tag to fail, got %v", err)
+	}
+
+	unauthenticated := NewGraphClient(nil, "TofuTune/test", &GraphClientOptions{
+		BaseURL:     client.baseURL,
+		TokenSource: staticTokenSource("invalid"),
+	})
+	_, err = unauthenticated.ListScopeTags(ctx)
+	var graphErr *GraphError
+	if !errors.As(err, &graphErr) || graphErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a 401 Graph error, got %v", err)
+	}
+}
+
+func TestGraphClientFollowsNextLinks(t *testing.T) {
+	client, graph := newTestClient(t)
+	ctx := context.Background()
+	graph.PageSize = 2
+
+	for i := 0; i < 4; i++ {
+		if _, err := client.CreateScopeTag(ctx, &ScopeTag{DisplayName: fmt.Sprintf("Tag %d", i)}); err != nil {
+			t.Fatalf("CreateScopeTag: %s", err)
+		}
+	}
+
+	tags, err := client.ListScopeTags(ctx)
+	if err != nil {
+		t.Fatalf("ListScopeTags: %s", err)
+	}
+	if len(tags) != 5 {
+		t.Errorf("expected 5 scope tags across 3 pages, got %d", len(tags))
+	}
+}
+
+func TestGraphClientBatch(t *testing.T) {
+	client, graph := newTestClient(t)
+	ctx := context.Background()
+
+	tag, err := client.CreateScopeTag(ctx, &ScopeTag{DisplayName: "Helpdesk"})
+	if err != nil {
+		t.Fatalf("CreateScopeTag: %s", err)
+	}
+
+	// The first sub-request is throttled and retried on its own; the dependent request is
+	// skipped by Graph and retried after it
+	graph.Throttle(1)
+	responses, err := client.Batch(ctx, []BatchRequest{
+		{ID: "update", Method: http.MethodPatch, Path: PathScopeTags + "/" + tag.ID, Body: map[string]interface{}{"description": "Updated"}},
+		{ID: "get", Method: http.MethodGet, Path: PathScopeTags + "/" + tag.ID, DependsOn: []string{"update"}},
+		{ID: "missing", Method: http.MethodGet, Path: PathScopeTags + "/42"},
+	})
+	if err != nil {
+		t.Fatalf("Batch: %s", err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("expected 3 responses, got %d", len(responses))
+	}
+
+	if responses[0].Err != nil {
+		t.Errorf("update: %s", responses[0].Err)
+	}
+
+	var got ScopeTag
+	if err := responses[1].Decode(&got); err != nil {
+		t.Fatalf("get: %s", err)
+	}
+	if got.Description != "Updated" {
+		t.Errorf("expected the dependent request to see the update, got description %q", got.Description)
+	}
+
+	if !IsNotFound(responses[2].Err) {
+		t.Errorf("expected a not found error for the missing scope tag, got %v", responses[2].Err)
+	}
+}
+
+func TestSplitBatch(t *testing.T) {
+	requests := make([]BatchRequest, 45)
+	index := make(map[string]int, len(requests))
+	for i := range requests {
+		requests[i].ID = fmt.Sprint(i)
+		index[requests[i].ID] = i
+	}
+	// Requests 18 to 22 form a chain that must not be split across batches
+	for i := 19; i <= 22; i++ {
+		requests[i].DependsOn = []string{fmt.Sprint(i - 1)}
+	}
+
+	chunks, err := splitBatch(requests, index)
+	if err != nil {
+		t.Fatalf("splitBatch: %s", err)
+	}
+
+	total := 0
+	for _, chunk := range chunks {
+		if len(chunk) > MaxBatchSize {
+			t.Errorf("batch of %d requests exceeds the maximum", len(chunk))
+		}
+		total += len(chunk)
+	}
+	if total != len(requests) {
+		t.Errorf("expected %d requests across batches, got %d", len(requests), total)
+	}
+	if len(chunks[0]) != 18 || chunks[1][0] != 18 {
+		t.Errorf("expected the dependency chain to start the second batch, got %v", chunks)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the default number of retries for throttled or transient failures
+	DefaultMaxRetries = 5
+
+	// DefaultMaxRetryWait is the default upper bound for the delay between two attempts
+	DefaultMaxRetryWait = 60 * time.Second
+
+	// retryBaseDelay is the initial delay used for exponential backoff
+	retryBaseDelay = 1 * time.Second
+)
+
+// transportError wraps an error that occurred while sending a request or receiving its response
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("failed to execute request: %s", e.err)
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// isIdempotentMethod reports whether repeating a request with the given method has the same effect as sending it once
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableStatus reports whether a response status warrants another attempt.
+// Throttling responses (429, 503) are rejected before the request is processed and are
+// always safe to retry. Other server errors may have been partially applied, so they are
+// only retried for idempotent methods.
+func isRetryableStatus(statusCode int, idempotent bool) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	default:
+		return false
+	}
+}
+
+// isRetryableError reports whether a transport error warrants another attempt.
+// Errors raised before the connection was established are always retried; connection
+// resets and timeouts are only retried for idempotent methods, because the server may
+// already have acted on the request.
+func isRetryableError(ctx context.Context, err error, idempotent bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var tErr *transportError
+	if !errors.As(err, &tErr) {
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	if !idempotent {
+		return false
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return false
+}
+
+// retryDelay returns how long to wait before the next attempt. A Retry-After header sent by
+// the server takes precedence; otherwise exponential backoff with full jitter is used.
+// The result never exceeds the configured maximum wait.
+func (c *GraphClient) retryDelay(attempt int, header http.Header) time.Duration {
+	if wait, ok := parseRetryAfter(header); ok {
+		return c.capRetryWait(wait)
+	}
+
+	backoff := retryBaseDelay << attempt
+	if backoff <= 0 || (c.maxRetryWait > 0 && backoff > c.maxRetryWait) {
+		backoff = c.maxRetryWait
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	return c.capRetryWait(time.Duration(rand.Int64N(int64(backoff)) + 1))
+}
+
+// capRetryWait limits a delay to the configured maximum wait
+func (c *GraphClient) capRetryWait(wait time.Duration) time.Duration {
+	if c.maxRetryWait > 0 && wait > c.maxRetryWait {
+		return c.maxRetryWait
+	}
+	return wait
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(header http.Header) (time.Duration, bool) {
+	if header == nil {
+		return 0, false
+	}
+
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// sleepContext waits for the given duration or until the context is cancelled
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,1236 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package fakegraph
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entity sets served by the fake
+const (
+	collConfigurationPolicies = "configurationPolicies"
+	collCompliancePolicies    = "deviceCompliancePolicies"
+	collIntents               = "intents"
+	collRoleScopeTags         = "roleScopeTags"
+	collAssignmentFilters     = "assignmentFilters"
+	collTemplates             = "templates"
+	collPolicyTemplates       = "configurationPolicyTemplates"
+	collDeviceConfigurations  = "deviceConfigurations"
+	collSettingsCompliance    = "compliancePolicies"
+	collComplianceScripts     = "deviceComplianceScripts"
+	collNotificationTemplates = "notificationMessageTemplates"
+	collFeatureUpdates        = "windowsFeatureUpdateProfiles"
+	collQualityUpdates        = "windowsQualityUpdateProfiles"
+	collDriverUpdates         = "windowsDriverUpdateProfiles"
+	collAutopilotProfiles     = "windowsAutopilotDeploymentProfiles"
+	collEnrollmentConfigs     = "deviceEnrollmentConfigurations"
+)
+
+// Exported names of the entity sets, for use with Object, Update and Remove
+const (
+	ConfigurationPolicies    = collConfigurationPolicies
+	DeviceCompliancePolicies = collCompliancePolicies
+	Intents                  = collIntents
+	RoleScopeTags            = collRoleScopeTags
+	AssignmentFilters        = collAssignmentFilters
+	Templates                = collTemplates
+	PolicyTemplates          = collPolicyTemplates
+	DeviceConfigurations     = collDeviceConfigurations
+	CompliancePolicies       = collSettingsCompliance
+	ComplianceScripts        = collComplianceScripts
+	NotificationTemplates    = collNotificationTemplates
+	FeatureUpdateProfiles    = collFeatureUpdates
+	QualityUpdateProfiles    = collQualityUpdates
+	DriverUpdateProfiles     = collDriverUpdates
+	AutopilotProfiles        = collAutopilotProfiles
+	EnrollmentConfigurations = collEnrollmentConfigs
+)
+
+// readOnlyProperties are computed by the service and ignored in request bodies
+var readOnlyProperties = []string{"id", "createdDateTime", "lastModifiedDateTime", "settingCount", "isAssigned", "version"}
+
+// secretProperties are accepted in request bodies but returned as null, like the pre-shared keys
+// of Wi-Fi profiles
+var secretProperties = []string{"preSharedKey"}
+
+// create validates a POST body and stores a new entity
+func (s *Server) create(name string, c *collection, body map[string]interface{}) (int, interface{}, *apiError) {
+	if body == nil {
+		return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "A request body is required."}
+	}
+
+	props := copyMap(body)
+	for _, key := range readOnlyProperties {
+		delete(props, key)
+	}
+	e := &entity{props: props}
+
+	now := timestamp()
+	switch name {
+	case collConfigurationPolicies:
+		if apiErr := requireProperties(props, "name", "platforms", "technologies"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		settings, apiErr := s.policySettings(props)
+		if apiErr != nil {
+			return 0, nil, apiErr
+		}
+		if apiErr := s.checkTemplateReference(props, settings); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		e.settings = settings
+		setDefault(props, "description", "")
+		setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+		props["isAssigned"] = false
+		props["settingCount"] = len(settings)
+
+	case collSettingsCompliance:
+		if apiErr := requireProperties(props, "name", "platforms", "technologies"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		actions, _ := props["scheduledActionsForRule"].([]interface{})
+		delete(props, "scheduledActionsForRule")
+		if len(actions) == 0 {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "scheduledActionsForRule is required when creating a compliance policy."}
+		}
+		if apiErr := validateScheduledActions(actions); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		settings, apiErr := s.policySettings(props)
+		if apiErr != nil {
+			return 0, nil, apiErr
+		}
+		e.settings = settings
+		e.actions = s.withActionIDs(actions)
+		setDefault(props, "description", "")
+		setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+		props["settingCount"] = len(settings)
+
+	case collCompliancePolicies:
+		if apiErr := requireProperties(props, "@odata.type", "displayName"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		if !strings.HasSuffix(props["@odata.type"].(string), "CompliancePolicy") {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%s' is not a compliance policy type.", props["@odata.type"])}
+		}
+		actions, _ := props["scheduledActionsForRule"].([]interface{})
+		delete(props, "scheduledActionsForRule")
+		if len(actions) == 0 {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "scheduledActionsForRule is required when creating a compliance policy."}
+		}
+		if apiErr := validateScheduledActions(actions); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		if apiErr := s.validateCompliancePolicyScript(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		e.actions = s.withActionIDs(actions)
+		setDefault(props, "description", "")
+		setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+		props["version"] = 1
+
+	case collComplianceScripts:
+		if apiErr := requireProperties(props, "displayName", "detectionScriptContent"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		if apiErr := validateComplianceScript(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		setDefault(props, "description", "")
+		setDefault(props, "publisher", "")
+		setDefault(props, "runAsAccount", "system")
+		setDefault(props, "enforceSignatureCheck", false)
+		setDefault(props, "runAs32Bit", false)
+		setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+		props["version"] = "1"
+
+	case collNotificationTemplates:
+		if apiErr := requireProperties(props, "displayName"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		if apiErr := validateBrandingOptions(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		delete(props, "localizedNotificationMessages")
+		setDefault(props, "description", "")
+		setDefault(props, "defaultLocale", "")
+		setDefault(props, "brandingOptions", "none")
+		setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+
+	case collFeatureUpdates:
+		if apiErr := requireProperties(props, "displayName", "featureUpdateVersion"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		if apiErr := validateFeatureUpdate(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		setDefault(props, "description", "")
+		setDefault(props, "installLatestWindows10OnWindows11IneligibleDevice", false)
+		setDefault(props, "rolloutSettings", nil)
+		setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+
+	case collQualityUpdates:
+		if apiErr := requireProperties(props, "displayName", "expeditedUpdateSettings"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		if apiErr := validateQualityUpdate(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		setDefault(props, "description", "")
+		setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+
+	case collDriverUpdates:
+		if apiErr := requireProperties(props, "displayName", "approvalType"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		if apiErr := validateDriverUpdate(props, props["approvalType"]); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		setDefault(props, "description", "")
+		setDefault(props, "deploymentDeferralInDays", float64(0))
+		setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+		e.drivers = []interface{}{}
+
+	case collAutopilotProfiles:
+		if apiErr := requireProperties(props, "@odata.type", "displayName"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		setDefault(props, "description", "")
+		setDefault(props, "language", "os-default")
+		setDefault(props, "deviceNameTemplate", "")
+		setDefault(props, "deviceType", "windowsPc")
+		setDefault(props, "enableWhiteGlove", false)
+		setDefault(props, "extractHardwareHash", false)
+		setDefault(props, "outOfBoxExperienceSettings", map[string]interface{}{
+			"hidePrivacySettings":       true,
+			"hideEULA":                  true,
+			"userType":                  "standard",
+			"deviceUsageType":           "singleUser",
+			"skipKeyboardSelectionPage": true,
+			"hideEscapeLink":            true,
+		})
+		setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+		if apiErr := validateAutopilotProfile(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+
+	case collEnrollmentConfigs:
+		if apiErr := requireProperties(props, "@odata.type", "displayName"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		if props["@odata.type"] != "#microsoft.graph.windows10EnrollmentCompletionPageConfiguration" {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%s' is not a supported enrollment configuration type.", props["@odata.type"])}
+		}
+		if apiErr := validateEnrollmentStatusPage(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		// New configurations get the lowest priority
+		lowest := 0.0
+		for _, other := range c.items {
+			if p, _ := other.props["priority"].(float64); p > lowest {
+				lowest = p
+			}
+		}
+		props["priority"] = lowest + 1
+		props["deviceEnrollmentConfigurationType"] = "windows10EnrollmentCompletionPageConfiguration"
+		setDefault(props, "description", "")
+		setDefault(props, "selectedMobileAppIds", []interface{}{})
+		setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+
+	case collIntents:
+		if apiErr := requireProperties(props, "displayName", "templateId"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		var defaults []interface{}
+		if templates := s.collections[collTemplates]; len(templates.items) > 0 {
+			template, ok := templates.items[props["templateId"].(string)]
+			if !ok {
+				return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Template '%s' does not exist.", props["templateId"])}
+			}
+			defaults = template.settings
+		}
+		settings, _ := props["settings"].([]interface{})
+		delete(props, "settings")
+		e.settings = mergeIntentSettings(defaults, settings)
+		setDefault(props, "description", "")
+		setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+		props["isAssigned"] = false
+
+	case collDeviceConfigurations:
+		if apiErr := requireProperties(props, "@odata.type", "displayName"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		if !strings.HasPrefix(props["@odata.type"].(string), "#microsoft.graph.") {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%s' is not a device configuration type.", props["@odata.type"])}
+		}
+		if apiErr := validateOmaSettings(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		e.secrets = make(map[string]interface{})
+		s.storeSecrets(e, props)
+		setDefault(props, "description", "")
+		setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+		props["version"] = 1
+
+	case collRoleScopeTags:
+		if apiErr := requireProperties(props, "displayName"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		setDefault(props, "description", "")
+		props["isBuiltIn"] = false
+
+	case collAssignmentFilters:
+		if apiErr := requireProperties(props, "displayName", "platform", "rule"); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		setDefault(props, "description", "")
+		setDefault(props, "roleScopeTags", []interface{}{"0"})
+		setDefault(props, "assignmentFilterManagementType", "devices")
+	}
+
+	// Role scope tags use numeric IDs, everything else uses GUIDs
+	id := s.newID()
+	if name == collRoleScopeTags {
+		id = strconv.Itoa(s.nextID)
+	}
+	props["id"] = id
+	if name != collRoleScopeTags {
+		props["createdDateTime"] = now
+		props["lastModifiedDateTime"] = now
+	}
+
+	c.add(id, e)
+	return http.StatusCreated, copyMap(props), nil
+}
+
+// patch validates a PATCH body and merges it into a stored entity
+func (s *Server) patch(name string, e *entity, body map[string]interface{}) (int, interface{}, *apiError) {
+	if body == nil {
+		return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "A request body is required."}
+	}
+
+	props := copyMap(body)
+	for _, key := range readOnlyProperties {
+		delete(props, key)
+	}
+
+	switch name {
+	case collConfigurationPolicies:
+		if _, ok := props["settings"]; ok {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "Settings cannot be updated with PATCH, replace the policy with PUT instead."}
+		}
+		for _, key := range []string{"platforms", "technologies"} {
+			if v, ok := props[key]; ok && v != e.props[key] {
+				return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("The property '%s' cannot be changed.", key)}
+			}
+		}
+
+	case collCompliancePolicies:
+		// Derived types must be named in every PATCH body
+		if props["@odata.type"] != e.props["@odata.type"] {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "The @odata.type of the policy must be specified and cannot be changed."}
+		}
+		// Scheduled actions are managed through the scheduleActionsForRules action
+		delete(props, "scheduledActionsForRule")
+		if apiErr := s.validateCompliancePolicyScript(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		if v, ok := e.props["version"].(int); ok {
+			e.props["version"] = v + 1
+		}
+
+	case collIntents:
+		delete(props, "settings")
+
+	case collFeatureUpdates:
+		if apiErr := validateFeatureUpdate(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+
+	case collQualityUpdates:
+		if apiErr := validateQualityUpdate(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+
+	case collDriverUpdates:
+		if v, ok := props["approvalType"]; ok && v != e.props["approvalType"] {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "The property 'approvalType' cannot be changed."}
+		}
+		if apiErr := validateDriverUpdate(props, e.props["approvalType"]); apiErr != nil {
+			return 0, nil, apiErr
+		}
+
+	case collAutopilotProfiles:
+		if props["@odata.type"] != e.props["@odata.type"] {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "The @odata.type of the profile must be specified and cannot be changed."}
+		}
+		merged := copyMap(e.props)
+		for k, v := range props {
+			merged[k] = v
+		}
+		if apiErr := validateAutopilotProfile(merged); apiErr != nil {
+			return 0, nil, apiErr
+		}
+
+	case collEnrollmentConfigs:
+		if _, ok := props["priority"]; ok {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "The property 'priority' cannot be updated, use the setPriority action instead."}
+		}
+		if apiErr := validateEnrollmentStatusPage(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+
+	case collComplianceScripts:
+		if apiErr := validateComplianceScript(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+
+	case collNotificationTemplates:
+		if apiErr := validateBrandingOptions(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		delete(props, "localizedNotificationMessages")
+
+	case collDeviceConfigurations:
+		if props["@odata.type"] != e.props["@odata.type"] {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "The @odata.type of the profile must be specified and cannot be changed."}
+		}
+		if apiErr := validateOmaSettings(props); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		s.storeSecrets(e, props)
+		if v, ok := e.props["version"].(int); ok {
+			e.props["version"] = v + 1
+		}
+
+	case collRoleScopeTags:
+		if builtIn, _ := e.props["isBuiltIn"].(bool); builtIn {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "Built-in scope tags cannot be modified."}
+		}
+	}
+
+	for k, v := range props {
+		e.props[k] = v
+	}
+	e.touch()
+
+	return http.StatusNoContent, nil, nil
+}
+
+// replacePolicy handles PUT on a Settings Catalog policy, which replaces the policy and its settings
+func (s *Server) replacePolicy(e *entity, body map[string]interface{}) (int, interface{}, *apiError) {
+	if body == nil {
+		return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "A request body is required."}
+	}
+
+	props := copyMap(body)
+	for _, key := range readOnlyProperties {
+		if _, ok := props[key]; ok {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("The property '%s' is read-only.", key)}
+		}
+	}
+	if apiErr := requireProperties(props, "name", "platforms", "technologies"); apiErr != nil {
+		return 0, nil, apiErr
+	}
+	for _, key := range []string{"platforms", "technologies"} {
+		if props[key] != e.props[key] {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("The property '%s' cannot be changed.", key)}
+		}
+	}
+
+	settings, apiErr := s.policySettings(props)
+	if apiErr != nil {
+		return 0, nil, apiErr
+	}
+	if templateID(props) != templateID(e.props) {
+		return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "The property 'templateReference' cannot be changed."}
+	}
+	if apiErr := s.checkTemplateReference(props, settings); apiErr != nil {
+		return 0, nil, apiErr
+	}
+
+	for _, key := range readOnlyProperties {
+		if v, ok := e.props[key]; ok {
+			props[key] = v
+		}
+	}
+	setDefault(props, "description", "")
+	setDefault(props, "roleScopeTagIds", []interface{}{"0"})
+	props["settingCount"] = len(settings)
+
+	e.props = props
+	e.settings = settings
+	e.touch()
+
+	return http.StatusNoContent, nil, nil
+}
+
+// policySettings extracts and validates the settings of a Settings Catalog policy body
+func (s *Server) policySettings(props map[string]interface{}) ([]interface{}, *apiError) {
+	raw, _ := props["settings"].([]interface{})
+	delete(props, "settings")
+
+	settings := make([]interface{}, 0, len(raw))
+	for i, item := range raw {
+		setting, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Setting %d is not an object.", i)}
+		}
+		instance, ok := setting["settingInstance"].(map[string]interface{})
+		if !ok {
+			return nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Setting %d has no settingInstance.", i)}
+		}
+		if apiErr := s.validateSettingInstance(instance, fmt.Sprintf("Setting %d", i)); apiErr != nil {
+			return nil, apiErr
+		}
+
+		stored := copyMap(setting)
+		stored["id"] = strconv.Itoa(i)
+		settings = append(settings, stored)
+	}
+
+	return settings, nil
+}
+
+// templateID returns the template ID of a configuration policy, or an empty string
+func templateID(props map[string]interface{}) string {
+	reference, _ := props["templateReference"].(map[string]interface{})
+	id, _ := reference["templateId"].(string)
+	return id
+}
+
+// checkTemplateReference checks a template-backed configuration policy against its template, if
+// templates are registered: the template must exist, platforms and technologies must match it and
+// every setting must reference the setting template of its definition. The template reference is
+// completed with the template family, name and version.
+func (s *Server) checkTemplateReference(props map[string]interface{}, settings []interface{}) *apiError {
+	id := templateID(props)
+	if id == "" {
+		delete(props, "templateReference")
+		return nil
+	}
+	templates := s.collections[collPolicyTemplates]
+	if len(templates.items) == 0 {
+		return nil
+	}
+	template, ok := templates.items[id]
+	if !ok {
+		return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Template '%s' does not exist.", id)}
+	}
+	for _, key := range []string{"platforms", "technologies"} {
+		if props[key] != template.props[key] {
+			return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("The property '%s' must be '%v' for template '%s'.", key, template.props[key], id)}
+		}
+	}
+
+	instanceTemplates := make(map[string]string)
+	for _, item := range template.settings {
+		instance, _ := item.(map[string]interface{})["settingInstanceTemplate"].(map[string]interface{})
+		definitionID, _ := instance["settingDefinitionId"].(string)
+		instanceTemplates[definitionID], _ = instance["settingInstanceTemplateId"].(string)
+	}
+	for _, item := range settings {
+		instance := item.(map[string]interface{})["settingInstance"].(map[string]interface{})
+		definitionID := instance["settingDefinitionId"].(string)
+		reference, _ := instance["settingInstanceTemplateReference"].(map[string]interface{})
+		want, ok := instanceTemplates[definitionID]
+		if !ok || reference["settingInstanceTemplateId"] != want {
+			return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Setting '%s' does not reference a setting template of template '%s'.", definitionID, id)}
+		}
+	}
+
+	props["templateReference"] = map[string]interface{}{
+		"templateId":             id,
+		"templateFamily":         template.props["templateFamily"],
+		"templateDisplayName":    template.props["displayName"],
+		"templateDisplayVersion": template.props["displayVersion"],
+	}
+	return nil
+}
+
+// settingInstanceValues maps setting instance types to the property holding their value
+var settingInstanceValues = map[string]string{
+	"#microsoft.graph.deviceManagementConfigurationSimpleSettingInstance":           "simpleSettingValue",
+	"#microsoft.graph.deviceManagementConfigurationSimpleSettingCollectionInstance": "simpleSettingCollectionValue",
+	"#microsoft.graph.deviceManagementConfigurationChoiceSettingInstance":           "choiceSettingValue",
+	"#microsoft.graph.deviceManagementConfigurationChoiceSettingCollectionInstance": "choiceSettingCollectionValue",
+	"#microsoft.graph.deviceManagementConfigurationGroupSettingInstance":            "groupSettingValue",
+	"#microsoft.graph.deviceManagementConfigurationGroupSettingCollectionInstance":  "groupSettingCollectionValue",
+}
+
+// validateSettingInstance checks that a setting instance and its children carry the value
+// property matching their type, and that their definitions exist when definitions are registered
+func (s *Server) validateSettingInstance(instance map[string]interface{}, name string) *apiError {
+	odataType, _ := instance["@odata.type"].(string)
+	definitionID, _ := instance["settingDefinitionId"].(string)
+	if odataType == "" || definitionID == "" {
+		return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("%s must specify @odata.type and settingDefinitionId.", name)}
+	}
+	if len(s.definitions) > 0 {
+		if _, ok := s.definitions[definitionID]; !ok {
+			return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Setting definition '%s' was not found.", definitionID)}
+		}
+	}
+
+	property, ok := settingInstanceValues[odataType]
+	if !ok {
+		return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("%s has unknown setting instance type '%s'.", name, odataType)}
+	}
+	value, ok := instance[property]
+	if !ok || value == nil {
+		return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Setting instance '%s' of type '%s' requires %s.", definitionID, odataType, property)}
+	}
+
+	// Values holding child settings are objects, or arrays of objects for group collections
+	var values []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		values = v
+	default:
+		values = []interface{}{v}
+	}
+	for _, v := range values {
+		obj, _ := v.(map[string]interface{})
+		children, _ := obj["children"].([]interface{})
+		for i, child := range children {
+			// Children are setting instances themselves, not wrapped in a setting
+			childInstance, _ := child.(map[string]interface{})
+			if apiErr := s.validateSettingInstance(childInstance, fmt.Sprintf("Child %d of '%s'", i, definitionID)); apiErr != nil {
+				return apiErr
+			}
+		}
+	}
+
+	return nil
+}
+
+// expand returns the representation of an entity for GET, including requested navigation properties
+func (s *Server) expand(name string, e *entity, expand string) map[string]interface{} {
+	result := copyMap(e.props)
+	if expand == "" {
+		return result
+	}
+
+	for _, nav := range strings.Split(expand, ",") {
+		nav = strings.TrimSpace(nav)
+		if i := strings.Index(nav, "("); i >= 0 {
+			nav = nav[:i]
+		}
+		switch {
+		case nav == "settings" && (name == collConfigurationPolicies || name == collIntents || name == collSettingsCompliance):
+			result["settings"] = copyValue(e.settings)
+		case nav == "assignments":
+			result["assignments"] = copyValue(e.assignments)
+		case nav == "scheduledActionsForRule" && (name == collCompliancePolicies || name == collSettingsCompliance):
+			result["scheduledActionsForRule"] = copyValue(e.actions)
+		case nav == "localizedNotificationMessages" && name == collNotificationTemplates:
+			result["localizedNotificationMessages"] = copyValue(e.messages)
+		}
+	}
+
+	return result
+}
+
+// scheduledActionTypes are the action types accepted in scheduled action configurations
+var scheduledActionTypes = map[string]bool{
+	"noAction":                     true,
+	"notification":                 true,
+	"block":                        true,
+	"retire":                       true,
+	"wipe":                         true,
+	"removeResourceAccessProfiles": true,
+	"pushNotification":             true,
+	"remoteLock":                   true,
+}
+
+// validateScheduledActions checks that the rules contain exactly one block action, and that
+// email notifications name their template
+func validateScheduledActions(actions []interface{}) *apiError {
+	blocks := 0
+	for _, item := range actions {
+		rule, ok := item.(map[string]interface{})
+		if !ok {
+			return &apiError{http.StatusBadRequest, "BadRequest", "Invalid scheduled action rule."}
+		}
+		configs, _ := rule["scheduledActionConfigurations"].([]interface{})
+		for _, c := range configs {
+			config, ok := c.(map[string]interface{})
+			if !ok {
+				return &apiError{http.StatusBadRequest, "BadRequest", "Invalid scheduled action configuration."}
+			}
+			actionType, _ := config["actionType"].(string)
+			if !scheduledActionTypes[actionType] {
+				return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%s' is not a valid actionType.", actionType)}
+			}
+			if actionType == "block" {
+				blocks++
+			}
+			if templateID, _ := config["notificationTemplateId"].(string); actionType == "notification" && (templateID == "" || templateID == noNotificationTemplate) {
+				return &apiError{http.StatusBadRequest, "BadRequest", "Notification actions require a notificationTemplateId."}
+			}
+		}
+	}
+
+	if blocks != 1 {
+		return &apiError{http.StatusBadRequest, "BadRequest", "Exactly one block action must be configured."}
+	}
+	return nil
+}
+
+// noNotificationTemplate is the template ID Graph returns for actions without a notification template
+const noNotificationTemplate = "00000000-0000-0000-0000-000000000000"
+
+// withActionIDs returns a copy of the scheduled action rules with IDs assigned, and the
+// notification properties Graph returns for every action. The caller holds the lock.
+func (s *Server) withActionIDs(actions []interface{}) []interface{} {
+	result := copyValue(actions).([]interface{})
+	for _, item := range result {
+		rule := item.(map[string]interface{})
+		rule["id"] = s.newID()
+		configs, _ := rule["scheduledActionConfigurations"].([]interface{})
+		for _, c := range configs {
+			config := c.(map[string]interface{})
+			config["id"] = s.newID()
+			setDefault(config, "notificationTemplateId", noNotificationTemplate)
+			setDefault(config, "notificationMessageCCList", []interface{}{})
+		}
+	}
+	return result
+}
+
+// validateIntentSettings checks setting instances written to an intent against the settings of
+// its template, if the template defines any
+func (s *Server) validateIntentSettings(intent *entity, settings []interface{}) *apiError {
+	templateID, _ := intent.props["templateId"].(string)
+	template := s.lookup(collTemplates, templateID)
+	if template == nil || len(template.settings) == 0 {
+		return nil
+	}
+
+	odataTypes := make(map[string]interface{}, len(template.settings))
+	for _, setting := range template.settings {
+		setting := setting.(map[string]interface{})
+		odataTypes[setting["definitionId"].(string)] = setting["@odata.type"]
+	}
+
+	for _, item := range settings {
+		setting, _ := item.(map[string]interface{})
+		definitionID, _ := setting["definitionId"].(string)
+		odataType, ok := odataTypes[definitionID]
+		if !ok {
+			return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Setting definition '%s' is not part of template '%s'.", definitionID, templateID)}
+		}
+		if setting["@odata.type"] != odataType {
+			return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Setting '%s' must be a %v, got %v.", definitionID, odataType, setting["@odata.type"])}
+		}
+		if valueJSON, _ := setting["valueJson"].(string); !json.Valid([]byte(valueJSON)) {
+			return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Setting '%s' has an invalid valueJson.", definitionID)}
+		}
+	}
+	return nil
+}
+
+// mergeIntentSettings applies setting instances to the settings of an intent, keyed by definition ID
+func mergeIntentSettings(existing, updates []interface{}) []interface{} {
+	result := copyValue(existing).([]interface{})
+	for _, item := range updates {
+		update, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		definitionID, _ := update["definitionId"].(string)
+
+		replaced := false
+		for i, current := range result {
+			if current.(map[string]interface{})["definitionId"] == definitionID {
+				stored := copyMap(update)
+				stored["id"] = current.(map[string]interface{})["id"]
+				result[i] = stored
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			stored := copyMap(update)
+			stored["id"] = definitionID
+			result = append(result, stored)
+		}
+	}
+	return result
+}
+
+// storeSecrets moves the secret properties of a request body into the secrets of an entity,
+// leaving null in their place. References bound with @odata.bind are not returned either, so they
+// are kept with the secrets. The values of encrypted OMA-URI settings are replaced by a cipher
+// text and a secretReferenceValueId, which getOmaSettingPlainTextValue resolves. The caller
+// holds the lock.
+func (s *Server) storeSecrets(e *entity, props map[string]interface{}) {
+	for _, key := range secretProperties {
+		if v, ok := props[key]; ok {
+			e.secrets[key] = v
+			props[key] = nil
+		}
+	}
+	for key, v := range props {
+		if strings.HasSuffix(key, "@odata.bind") {
+			e.secrets[key] = v
+			delete(props, key)
+		}
+	}
+
+	settings, _ := props["omaSettings"].([]interface{})
+	for _, item := range settings {
+		setting, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if encrypted, _ := setting["isEncrypted"].(bool); !encrypted {
+			setting["secretReferenceValueId"] = nil
+			continue
+		}
+		id := s.newID()
+		e.secrets[id] = setting["value"]
+		setting["value"] = base64.StdEncoding.EncodeToString([]byte("encrypted:" + id))
+		setting["secretReferenceValueId"] = id
+	}
+}
+
+// omaSettingValueKinds maps the OData types of OMA-URI settings to the JSON kind of their value
+var omaSettingValueKinds = map[string]string{
+	"#microsoft.graph.omaSettingString":        "string",
+	"#microsoft.graph.omaSettingInteger":       "number",
+	"#microsoft.graph.omaSettingBoolean":       "bool",
+	"#microsoft.graph.omaSettingFloatingPoint": "number",
+	"#microsoft.graph.omaSettingDateTime":      "string",
+	"#microsoft.graph.omaSettingBase64":        "string",
+	"#microsoft.graph.omaSettingStringXml":     "binary",
+}
+
+// validateOmaSettings checks the types and values of the OMA-URI settings of a custom profile
+func validateOmaSettings(props map[string]interface{}) *apiError {
+	settings, _ := props["omaSettings"].([]interface{})
+	for _, item := range settings {
+		setting, ok := item.(map[string]interface{})
+		if !ok {
+			return &apiError{http.StatusBadRequest, "BadRequest", "An OMA-URI setting must be an object."}
+		}
+		if apiErr := requireProperties(setting, "@odata.type", "displayName", "omaUri"); apiErr != nil {
+			return apiErr
+		}
+		odataType := setting["@odata.type"].(string)
+		kind, ok := omaSettingValueKinds[odataType]
+		if !ok {
+			return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%s' is not an OMA-URI setting type.", odataType)}
+		}
+
+		valid := false
+		switch value := setting["value"].(type) {
+		case string:
+			if kind == "binary" {
+				_, err := base64.StdEncoding.DecodeString(value)
+				valid = err == nil
+			} else {
+				valid = kind == "string"
+			}
+		case float64:
+			valid = kind == "number"
+		case bool:
+			valid = kind == "bool"
+		}
+		if !valid {
+			return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Invalid value for %s of setting '%s'.", odataType, setting["omaUri"])}
+		}
+	}
+	return nil
+}
+
+// validateComplianceScript checks the content and run-as account of a compliance script body
+func validateComplianceScript(props map[string]interface{}) *apiError {
+	if content, ok := props["detectionScriptContent"]; ok {
+		s, _ := content.(string)
+		if _, err := base64.StdEncoding.DecodeString(s); err != nil || s == "" {
+			return &apiError{http.StatusBadRequest, "BadRequest", "The property 'detectionScriptContent' must be base64 encoded."}
+		}
+	}
+	if account, ok := props["runAsAccount"]; ok && account != "system" && account != "user" {
+		return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%v' is not a valid runAsAccount.", account)}
+	}
+	return nil
+}
+
+// validateCompliancePolicyScript checks that the custom compliance script of a compliance policy
+// body exists and that its rules are base64 encoded. The caller holds the lock.
+func (s *Server) validateCompliancePolicyScript(props map[string]interface{}) *apiError {
+	script, ok := props["deviceCompliancePolicyScript"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	id, _ := script["deviceComplianceScriptId"].(string)
+	if s.lookup(collComplianceScripts, id) == nil {
+		return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Device compliance script '%s' does not exist.", id)}
+	}
+	rules, _ := script["rulesContent"].(string)
+	if _, err := base64.StdEncoding.DecodeString(rules); err != nil || rules == "" {
+		return &apiError{http.StatusBadRequest, "BadRequest", "The property 'rulesContent' must be base64 encoded."}
+	}
+	return nil
+}
+
+// requireProperties returns an error naming the first missing or empty property
+func requireProperties(props map[string]interface{}, names ...string) *apiError {
+	for _, name := range names {
+		v, ok := props[name]
+		if !ok || v == nil || v == "" {
+			return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("The property '%s' is required.", name)}
+		}
+	}
+	return nil
+}
+
+// setDefault sets a property when the request did not provide it
+func setDefault(props map[string]interface{}, name string, value interface{}) {
+	if _, ok := props[name]; !ok {
+		props[name] = value
+	}
+}
+
+// brandingOptions are the flags accepted in the brandingOptions of notification message templates
+var brandingOptions = map[string]bool{
+	"none":                      true,
+	"includeCompanyLogo":        true,
+	"includeCompanyName":        true,
+	"includeContactInformation": true,
+	"includeCompanyPortalLink":  true,
+	"includeDeviceDetails":      true,
+}
+
+// validateBrandingOptions checks the comma-separated flags of a notification message template
+func validateBrandingOptions(props map[string]interface{}) *apiError {
+	value, ok := props["brandingOptions"].(string)
+	if !ok {
+		return nil
+	}
+	for _, flag := range strings.Split(value, ",") {
+		if !brandingOptions[strings.TrimSpace(flag)] {
+			return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%s' is not a valid brandingOptions value.", value)}
+		}
+	}
+	return nil
+}
+
+// featureUpdateEndOfSupport are the feature update versions the fake offers, with the end of
+// their support
+var featureUpdateEndOfSupport = map[string]string{
+	"Windows 10, version 22H2": "2025-10-14T00:00:00Z",
+	"Windows 11, version 22H2": "2025-10-14T00:00:00Z",
+	"Windows 11, version 23H2": "2026-11-10T00:00:00Z",
+	"Windows 11, version 24H2": "2027-10-12T00:00:00Z",
+}
+
+// validateFeatureUpdate checks the version and rollout settings of a feature update profile like
+// Intune, and derives the end of support of the version
+func validateFeatureUpdate(props map[string]interface{}) *apiError {
+	delete(props, "endOfSupportDate")
+	if version, ok := props["featureUpdateVersion"].(string); ok {
+		endOfSupport, known := featureUpdateEndOfSupport[version]
+		if !known {
+			return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%s' is not a supported feature update version.", version)}
+		}
+		props["endOfSupportDate"] = endOfSupport
+	}
+
+	rollout, ok := props["rolloutSettings"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	start, _ := rollout["offerStartDateTimeInUTC"].(string)
+	end, _ := rollout["offerEndDateTimeInUTC"].(string)
+	interval, _ := rollout["offerIntervalInDays"].(float64)
+	if end != "" && start == "" {
+		return &apiError{http.StatusBadRequest, "BadRequest", "offerEndDateTimeInUTC requires offerStartDateTimeInUTC."}
+	}
+	if (end != "") != (interval > 0) {
+		return &apiError{http.StatusBadRequest, "BadRequest", "offerEndDateTimeInUTC and offerIntervalInDays must be set together."}
+	}
+	if end != "" && end <= start {
+		return &apiError{http.StatusBadRequest, "BadRequest", "offerEndDateTimeInUTC must be after offerStartDateTimeInUTC."}
+	}
+	return nil
+}
+
+// validateQualityUpdate checks the expedited release of a quality update profile like Intune, and
+// derives the display texts of the release
+func validateQualityUpdate(props map[string]interface{}) *apiError {
+	delete(props, "releaseDateDisplayText")
+	delete(props, "deployableContentDisplayName")
+	settings, ok := props["expeditedUpdateSettings"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	release, _ := settings["qualityUpdateRelease"].(string)
+	t, err := time.Parse(time.RFC3339, release)
+	if err != nil {
+		return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%s' is not a quality update release.", release)}
+	}
+	if days, _ := settings["daysUntilForcedReboot"].(float64); days < 0 || days > 2 {
+		return &apiError{http.StatusBadRequest, "BadRequest", "daysUntilForcedReboot must be between 0 and 2."}
+	}
+	props["releaseDateDisplayText"] = t.Format("01/02/2006")
+	props["deployableContentDisplayName"] = t.Format("2006.01") + " B Security Updates"
+	return nil
+}
+
+// validateDriverUpdate checks the approval type and deferral of a driver update profile like
+// Intune. Only automatic profiles defer their approvals.
+func validateDriverUpdate(props map[string]interface{}, approvalType interface{}) *apiError {
+	if approvalType != "manual" && approvalType != "automatic" {
+		return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%v' is not a valid approvalType value.", approvalType)}
+	}
+	days, _ := props["deploymentDeferralInDays"].(float64)
+	if days < 0 || days > 30 {
+		return &apiError{http.StatusBadRequest, "BadRequest", "deploymentDeferralInDays must be between 0 and 30."}
+	}
+	if days > 0 && approvalType != "automatic" {
+		return &apiError{http.StatusBadRequest, "BadRequest", "deploymentDeferralInDays is only supported for automatic approval."}
+	}
+	return nil
+}
+
+// deviceNameTemplate matches the device name templates of Autopilot profiles: letters, digits,
+// hyphens and the %SERIAL% and %RAND:x% macros
+var deviceNameTemplate = regexp.MustCompile(`^([A-Za-z0-9-]|%SERIAL%|%RAND:[1-9][0-9]*%)+$`)
+
+// validateAutopilotProfile checks the join type specific settings and the out-of-box experience
+// of an Autopilot profile like Intune
+func validateAutopilotProfile(props map[string]interface{}) *apiError {
+	hybrid := false
+	switch props["@odata.type"] {
+	case "#microsoft.graph.azureADWindowsAutopilotDeploymentProfile":
+		if _, ok := props["hybridAzureADJoinSkipConnectivityCheck"]; ok {
+			return &apiError{http.StatusBadRequest, "BadRequest", "hybridAzureADJoinSkipConnectivityCheck is only supported for hybrid Azure AD join."}
+		}
+	case "#microsoft.graph.activeDirectoryWindowsAutopilotDeploymentProfile":
+		hybrid = true
+	default:
+		return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%s' is not an Autopilot deployment profile type.", props["@odata.type"])}
+	}
+
+	if template, _ := props["deviceNameTemplate"].(string); template != "" {
+		if hybrid {
+			return &apiError{http.StatusBadRequest, "BadRequest", "Device name templates are not supported for hybrid Azure AD join, use a domain join profile instead."}
+		}
+		if !deviceNameTemplate.MatchString(template) {
+			return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%s' is not a valid device name template.", template)}
+		}
+	}
+
+	oobe, _ := props["outOfBoxExperienceSettings"].(map[string]interface{})
+	if userType := oobe["userType"]; userType != "administrator" && userType != "standard" {
+		return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%v' is not a valid userType value.", userType)}
+	}
+	switch oobe["deviceUsageType"] {
+	case "singleUser":
+	case "shared":
+		// Self-deploying mode
+		if hybrid {
+			return &apiError{http.StatusBadRequest, "BadRequest", "Self-deploying mode is not supported for hybrid Azure AD join."}
+		}
+		if whiteGlove, _ := props["enableWhiteGlove"].(bool); whiteGlove {
+			return &apiError{http.StatusBadRequest, "BadRequest", "Pre-provisioning is not supported in self-deploying mode."}
+		}
+	default:
+		return &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%v' is not a valid deviceUsageType value.", oobe["deviceUsageType"])}
+	}
+	return nil
+}
+
+// validateEnrollmentStatusPage checks the installation timeout of an Enrollment Status Page
+func validateEnrollmentStatusPage(props map[string]interface{}) *apiError {
+	if timeout, ok := props["installProgressTimeoutInMinutes"].(float64); ok && (timeout < 1 || timeout > 1440) {
+		return &apiError{http.StatusBadRequest, "BadRequest", "installProgressTimeoutInMinutes must be between 1 and 1440."}
+	}
+	return nil
+}
+
+// setEnrollmentPriority moves an enrollment configuration to a priority and shifts the
+// configurations in between, keeping priorities unique. The caller holds the lock.
+func (s *Server) setEnrollmentPriority(e *entity, body map[string]interface{}) (int, interface{}, *apiError) {
+	priority, _ := body["priority"].(float64)
+	if priority < 1 {
+		return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "The priority must be at least 1."}
+	}
+	current, _ := e.props["priority"].(float64)
+
+	for _, other := range s.collections[collEnrollmentConfigs].items {
+		if other == e {
+			continue
+		}
+		p, _ := other.props["priority"].(float64)
+		switch {
+		case priority < current && p >= priority && p < current:
+			other.props["priority"] = p + 1
+		case priority > current && p > current && p <= priority:
+			other.props["priority"] = p - 1
+		}
+	}
+	e.props["priority"] = priority
+	e.touch()
+	return http.StatusNoContent, nil, nil
+}
+
+// createAutopilotAssignment adds a single assignment to an Autopilot profile, which has no assign
+// action. Autopilot profiles can only be assigned to devices. The caller holds the lock.
+func (s *Server) createAutopilotAssignment(e *entity, body map[string]interface{}) (int, interface{}, *apiError) {
+	assignment, apiErr := newAssignment(body, s.newID())
+	if apiErr != nil {
+		return 0, nil, apiErr
+	}
+	target := assignment["target"].(map[string]interface{})
+	if target["@odata.type"] == "#microsoft.graph.allLicensedUsersAssignmentTarget" {
+		return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "Autopilot profiles can only be assigned to devices."}
+	}
+	for _, existing := range e.assignments {
+		other := existing.(map[string]interface{})["target"].(map[string]interface{})
+		if other["@odata.type"] == target["@odata.type"] && other["groupId"] == target["groupId"] {
+			return 0, nil, &apiError{http.StatusConflict, "Conflict", "The profile is already assigned to this target."}
+		}
+	}
+
+	e.assignments = append(e.assignments, assignment)
+	return http.StatusCreated, copyMap(assignment), nil
+}
+
+// deleteAutopilotAssignment removes a single assignment from an Autopilot profile. The caller
+// holds the lock.
+func deleteAutopilotAssignment(e *entity, id string) (int, interface{}, *apiError) {
+	for i, a := range e.assignments {
+		if a.(map[string]interface{})["id"] == id {
+			e.assignments = append(e.assignments[:i:i], e.assignments[i+1:]...)
+			return http.StatusNoContent, nil, nil
+		}
+	}
+	return notFound(id)
+}
+
+// driverApprovalStatus is the approval status each driver action sets
+var driverApprovalStatus = map[string]string{
+	"approve": "approved",
+	"decline": "declined",
+	"suspend": "suspended",
+}
+
+// executeDriverAction approves, declines or suspends drivers of a driver update profile. The
+// caller holds the lock.
+func executeDriverAction(e *entity, body map[string]interface{}) (int, interface{}, *apiError) {
+	action, _ := body["actionName"].(string)
+	status, ok := driverApprovalStatus[action]
+	if !ok {
+		return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("'%s' is not a valid actionName value.", action)}
+	}
+	ids, _ := body["driverIds"].([]interface{})
+	if len(ids) == 0 {
+		return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "The driverIds property is required."}
+	}
+
+	drivers := make(map[string]map[string]interface{}, len(e.drivers))
+	for _, d := range e.drivers {
+		driver := d.(map[string]interface{})
+		drivers[driver["id"].(string)] = driver
+	}
+	for _, id := range ids {
+		if _, ok := drivers[fmt.Sprint(id)]; !ok {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Driver '%v' is not applicable to the profile.", id)}
+		}
+	}
+
+	deploymentDate, _ := body["deploymentDate"].(string)
+	if deploymentDate == "" {
+		deploymentDate = timestamp()
+	}
+	for _, id := range ids {
+		driver := drivers[fmt.Sprint(id)]
+		driver["approvalStatus"] = status
+		if action == "approve" {
+			driver["deployDateTime"] = deploymentDate
+		} else {
+			driver["deployDateTime"] = nil
+		}
+	}
+	return http.StatusOK, map[string]interface{}{
+		"successfulDriverIds": copyValue(ids),
+		"failedDriverIds":     []interface{}{},
+		"notFoundDriverIds":   []interface{}{},
+	}, nil
+}
+
+// createLocalizedMessage adds a localized message to a notification message template. Locales
+// must be unique within a template. The caller holds the lock.
+func (s *Server) createLocalizedMessage(e *entity, body map[string]interface{}) (int, interface{}, *apiError) {
+	if body == nil {
+		return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "A request body is required."}
+	}
+	props := copyMap(body)
+	for _, key := range readOnlyProperties {
+		delete(props, key)
+	}
+	if apiErr := requireProperties(props, "locale", "subject", "messageTemplate"); apiErr != nil {
+		return 0, nil, apiErr
+	}
+	for _, item := range e.messages {
+		if strings.EqualFold(fmt.Sprint(item.(map[string]interface{})["locale"]), fmt.Sprint(props["locale"])) {
+			return 0, nil, &apiError{http.StatusConflict, "Conflict", fmt.Sprintf("A message for locale '%s' already exists.", props["locale"])}
+		}
+	}
+	setDefault(props, "isDefault", false)
+	templateID, _ := e.props["id"].(string)
+	props["id"] = fmt.Sprintf("%s_%s", templateID, props["locale"])
+	props["lastModifiedDateTime"] = timestamp()
+
+	e.messages = append(e.messages, props)
+	e.touch()
+	return http.StatusCreated, copyMap(props), nil
+}
+
+// routeLocalizedMessage handles a single localized message of a notification message template.
+// The caller holds the lock.
+func (s *Server) routeLocalizedMessage(method string, e *entity, id string, body map[string]interface{}) (int, interface{}, *apiError) {
+	for i, item := range e.messages {
+		message := item.(map[string]interface{})
+		if message["id"] != id {
+			continue
+		}
+		switch method {
+		case http.MethodGet:
+			return http.StatusOK, copyMap(message), nil
+		case http.MethodPatch:
+			if body == nil {
+				return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "A request body is required."}
+			}
+			props := copyMap(body)
+			for _, key := range append(readOnlyProperties, "locale") {
+				delete(props, key)
+			}
+			for k, v := range props {
+				message[k] = v
+			}
+			message["lastModifiedDateTime"] = timestamp()
+			e.touch()
+			return http.StatusNoContent, nil, nil
+		case http.MethodDelete:
+			e.messages = append(e.messages[:i], e.messages[i+1:]...)
+			e.touch()
+			return http.StatusNoContent, nil, nil
+		}
+		return 0, nil, &apiError{http.StatusMethodNotAllowed, "BadRequest", fmt.Sprintf("%s is not supported for localized messages.", method)}
+	}
+	return notFound(id)
+}
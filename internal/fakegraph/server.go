@@ -0,0 +1,817 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package fakegraph provides an in-process fake of the Microsoft Graph endpoints used by the
+// provider, so resources can be exercised without a real tenant.
+package fakegraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Token is the bearer token the server accepts by default
+	Token = "fake-graph-token"
+
+	// DefaultPageSize is the number of items returned per page of a collection
+	DefaultPageSize = 25
+
+	// maxBatchSize is the maximum number of requests accepted in a JSON batch
+	maxBatchSize = 20
+
+	// apiVersion is the path prefix the server is mounted on
+	apiVersion = "/beta"
+)
+
+// Server is an in-process fake Microsoft Graph API
+type Server struct {
+	*httptest.Server
+
+	// PageSize is the number of items returned per page of a collection
+	PageSize int
+
+	mu          sync.Mutex
+	nextID      int
+	collections map[string]*collection
+	definitions map[string]map[string]interface{}
+	throttle    int
+	requests    []string
+}
+
+// collection holds the entities of one entity set in insertion order
+type collection struct {
+	items map[string]*entity
+	order []string
+}
+
+// entity is a stored object together with its navigation properties
+type entity struct {
+	props       map[string]interface{}
+	settings    []interface{}
+	assignments []interface{}
+	actions     []interface{}
+	messages    []interface{}
+	drivers     []interface{}
+	secrets     map[string]interface{}
+}
+
+// apiError is an error response in the format used by Graph
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+// NewServer starts a fake Graph server. Close must be called when it is no longer needed.
+func NewServer() *Server {
+	s := &Server{
+		PageSize:    DefaultPageSize,
+		collections: make(map[string]*collection),
+		definitions: make(map[string]map[string]interface{}),
+	}
+	for _, name := range []string{collConfigurationPolicies, collCompliancePolicies, collIntents, collRoleScopeTags, collAssignmentFilters, collTemplates, collPolicyTemplates, collDeviceConfigurations, collSettingsCompliance, collComplianceScripts, collNotificationTemplates, collFeatureUpdates, collQualityUpdates, collDriverUpdates, collAutopilotProfiles, collEnrollmentConfigs} {
+		s.collections[name] = &collection{items: make(map[string]*entity)}
+	}
+
+	// Every tenant has the built-in Default scope tag
+	s.collections[collRoleScopeTags].add("0", &entity{props: map[string]interface{}{
+		"id":          "0",
+		"displayName": "Default",
+		"description": "Default scope tag",
+		"isBuiltIn":   true,
+	}})
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// BaseURL returns the URL the Graph client should use, including the API version
+func (s *Server) BaseURL() string {
+	return s.URL + apiVersion
+}
+
+// Throttle makes the next n requests, including requests inside a batch, fail with 429
+func (s *Server) Throttle(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttle = n
+}
+
+// Requests returns the method and path of every HTTP request received so far
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Object returns a copy of a stored object, or nil if it does not exist
+func (s *Server) Object(collectionName, id string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.collections[collectionName]
+	if !ok {
+		return nil
+	}
+	e, ok := c.items[id]
+	if !ok {
+		return nil
+	}
+	return copyMap(e.props)
+}
+
+// Settings returns a copy of the settings stored on a policy
+func (s *Server) Settings(collectionName, id string) []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.lookup(collectionName, id); e != nil {
+		return copyValue(e.settings).([]interface{})
+	}
+	return nil
+}
+
+// Assignments returns a copy of the assignments stored on a policy
+func (s *Server) Assignments(collectionName, id string) []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.lookup(collectionName, id); e != nil {
+		return copyValue(e.assignments).([]interface{})
+	}
+	return nil
+}
+
+// LocalizedMessages returns a copy of the localized messages stored on a notification message template
+func (s *Server) LocalizedMessages(templateID string) []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.lookup(collNotificationTemplates, templateID); e != nil && e.messages != nil {
+		return copyValue(e.messages).([]interface{})
+	}
+	return nil
+}
+
+// ScheduledActions returns a copy of the scheduled action rules stored on a compliance policy
+func (s *Server) ScheduledActions(collectionName, id string) []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.lookup(collectionName, id); e != nil && e.actions != nil {
+		return copyValue(e.actions).([]interface{})
+	}
+	return nil
+}
+
+// Secrets returns a copy of the secret properties written to an object, which Graph does not return
+func (s *Server) Secrets(collectionName, id string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.lookup(collectionName, id); e != nil {
+		return copyMap(e.secrets)
+	}
+	return nil
+}
+
+// DriverInventories returns a copy of the drivers applicable to a driver update profile
+func (s *Server) DriverInventories(profileID string) []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.lookup(collDriverUpdates, profileID); e != nil && e.drivers != nil {
+		return copyValue(e.drivers).([]interface{})
+	}
+	return nil
+}
+
+// SetDriverInventories replaces the drivers applicable to a driver update profile, simulating
+// Intune syncing the inventory of the assigned devices
+func (s *Server) SetDriverInventories(profileID string, drivers []interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.lookup(collDriverUpdates, profileID); e != nil {
+		e.drivers = copyValue(drivers).([]interface{})
+	}
+}
+
+// SetAssignments replaces the assignments of a stored object, simulating a change made outside
+// of Terraform or Graph returning assignments in a different order
+func (s *Server) SetAssignments(collectionName, id string, assignments []interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.lookup(collectionName, id); e != nil {
+		e.assignments = copyValue(assignments).([]interface{})
+	}
+}
+
+// SetScheduledActions replaces the scheduled action rules of a compliance policy as given,
+// simulating a change made outside of Terraform or Graph returning actions in a different order
+func (s *Server) SetScheduledActions(collectionName, id string, actions []interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.lookup(collectionName, id); e != nil {
+		e.actions = copyValue(actions).([]interface{})
+	}
+}
+
+// Update merges props into a stored object, simulating a change made outside of Terraform
+func (s *Server) Update(collectionName, id string, props map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.lookup(collectionName, id); e != nil {
+		for k, v := range props {
+			e.props[k] = v
+		}
+	}
+}
+
+// Remove deletes a stored object, simulating a deletion made outside of Terraform
+func (s *Server) Remove(collectionName, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.collections[collectionName]; ok {
+		c.remove(id)
+	}
+}
+
+// AddSettingDefinition registers a Settings Catalog setting definition. Once at least one
+// definition is registered, policies referencing unknown definitions are rejected.
+func (s *Server) AddSettingDefinition(definition map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := definition["id"].(string)
+	s.definitions[id] = copyMap(definition)
+}
+
+// AddTemplate registers an endpoint security template. Once at least one template is registered,
+// intents referencing unknown templates are rejected. The setting instances under "settings" are
+// the defaults of new intents, and intents of the template only accept settings among them.
+func (s *Server) AddTemplate(template map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	props := copyMap(template)
+	settings, _ := props["settings"].([]interface{})
+	delete(props, "settings")
+	id, _ := props["id"].(string)
+	s.collections[collTemplates].add(id, &entity{props: props, settings: mergeIntentSettings(nil, settings)})
+}
+
+// AddPolicyTemplate registers a configuration policy template with the setting templates under
+// "settingTemplates". Once at least one template is registered, configuration policies must
+// reference an existing template and its setting templates.
+func (s *Server) AddPolicyTemplate(template map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	props := copyMap(template)
+	settingTemplates, _ := props["settingTemplates"].([]interface{})
+	delete(props, "settingTemplates")
+	id, _ := props["id"].(string)
+	s.collections[collPolicyTemplates].add(id, &entity{props: props, settings: settingTemplates})
+}
+
+// serveHTTP handles a request arriving over the network
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+	s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+Token {
+		writeError(w, &apiError{http.StatusUnauthorized, "InvalidAuthenticationToken", "Access token is empty or invalid."})
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, apiVersion+"/") {
+		writeError(w, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Invalid version in %s", r.URL.Path)})
+		return
+	}
+
+	if r.URL.Path == apiVersion+"/$batch" {
+		s.serveBatch(w, r)
+		return
+	}
+
+	s.serveRequest(w, r)
+}
+
+// serveRequest dispatches a single, possibly batched, request
+func (s *Server) serveRequest(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.throttle > 0 {
+		s.throttle--
+		w.Header().Set("Retry-After", "0")
+		writeError(w, &apiError{http.StatusTooManyRequests, "TooManyRequests", "Too many requests, retry after 0 seconds."})
+		return
+	}
+
+	var body map[string]interface{}
+	if r.Body != nil {
+		data, _ := io.ReadAll(r.Body)
+		if len(bytes.TrimSpace(data)) > 0 {
+			if err := json.Unmarshal(data, &body); err != nil {
+				writeError(w, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Invalid JSON body: %s", err)})
+				return
+			}
+		}
+	}
+
+	status, result, apiErr := s.route(r.Method, splitPath(strings.TrimPrefix(r.URL.Path, apiVersion)), r.URL.Query().Get("$expand"), r.URL.Query().Get("$skiptoken"), body)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	writeJSON(w, status, result)
+}
+
+// pathSegment matches OData key segments such as configurationPolicies('id')
+var pathSegment = regexp.MustCompile(`^([^(]+)\('([^']*)'\)$`)
+
+// plainTextValueCall matches calls of the getOmaSettingPlainTextValue function
+var plainTextValueCall = regexp.MustCompile(`^getOmaSettingPlainTextValue\(secretReferenceValueId='([^']*)'\)$`)
+
+// splitPath splits a request path into segments, turning key segments into separate segments
+func splitPath(path string) []string {
+	var segments []string
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		if m := pathSegment.FindStringSubmatch(part); m != nil {
+			segments = append(segments, m[1], m[2])
+			continue
+		}
+		segments = append(segments, part)
+	}
+	return segments
+}
+
+// route executes a request against the store. The caller holds the lock.
+func (s *Server) route(method string, segments []string, expand, skipToken string, body map[string]interface{}) (int, interface{}, *apiError) {
+	if len(segments) < 2 || segments[0] != "deviceManagement" {
+		return notFound(strings.Join(segments, "/"))
+	}
+
+	name := segments[1]
+	if name == "configurationSettings" {
+		return s.routeDefinitions(method, segments[2:], skipToken)
+	}
+
+	c, ok := s.collections[name]
+	if !ok {
+		return notFound(name)
+	}
+	if (name == collTemplates || name == collPolicyTemplates) && method != http.MethodGet {
+		return 0, nil, &apiError{http.StatusMethodNotAllowed, "BadRequest", "Templates are read-only."}
+	}
+	base := "/deviceManagement/" + name
+
+	switch len(segments) {
+	case 2:
+		switch method {
+		case http.MethodGet:
+			values := c.values()
+			if name == collComplianceScripts {
+				// Script content is only returned when a single script is read
+				for i, v := range values {
+					script := copyMap(v.(map[string]interface{}))
+					delete(script, "detectionScriptContent")
+					values[i] = script
+				}
+			}
+			return s.list(base, values, skipToken)
+		case http.MethodPost:
+			return s.create(name, c, body)
+		}
+	case 3:
+		e, ok := c.items[segments[2]]
+		if !ok {
+			return notFound(segments[2])
+		}
+		switch method {
+		case http.MethodGet:
+			return http.StatusOK, s.expand(name, e, expand), nil
+		case http.MethodPatch:
+			return s.patch(name, e, body)
+		case http.MethodPut:
+			if name == collConfigurationPolicies || name == collSettingsCompliance {
+				return s.replacePolicy(e, body)
+			}
+		case http.MethodDelete:
+			if builtIn, _ := e.props["isBuiltIn"].(bool); builtIn {
+				return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "Built-in objects cannot be deleted."}
+			}
+			c.remove(segments[2])
+			return http.StatusNoContent, nil, nil
+		}
+	case 4:
+		e, ok := c.items[segments[2]]
+		if !ok {
+			return notFound(segments[2])
+		}
+		return s.routeNavigation(method, name, base+"/"+segments[2], e, segments[3], skipToken, body)
+	case 5:
+		e, ok := c.items[segments[2]]
+		if !ok {
+			return notFound(segments[2])
+		}
+		if name == collNotificationTemplates && segments[3] == "localizedNotificationMessages" {
+			return s.routeLocalizedMessage(method, e, segments[4], body)
+		}
+		if name == collAutopilotProfiles && segments[3] == "assignments" && method == http.MethodDelete {
+			return deleteAutopilotAssignment(e, segments[4])
+		}
+	}
+
+	return 0, nil, &apiError{http.StatusMethodNotAllowed, "BadRequest", fmt.Sprintf("No HTTP resource was found that matches %s %s", method, strings.Join(segments, "/"))}
+}
+
+// routeNavigation handles navigation properties and actions of a stored entity
+func (s *Server) routeNavigation(method, name, base string, e *entity, nav, skipToken string, body map[string]interface{}) (int, interface{}, *apiError) {
+	switch {
+	case nav == "assignments" && method == http.MethodGet:
+		return s.list(base+"/assignments", e.assignments, skipToken)
+
+	case nav == "assign" && method == http.MethodPost && name == collEnrollmentConfigs:
+		return s.assign(e, body, "enrollmentConfigurationAssignments")
+
+	// Autopilot profiles have no assign action, their assignments are created one at a time
+	case nav == "assign" && method == http.MethodPost && name != collAutopilotProfiles:
+		return s.assign(e, body, "assignments")
+
+	case nav == "assignments" && method == http.MethodPost && name == collAutopilotProfiles:
+		return s.createAutopilotAssignment(e, body)
+
+	case nav == "setPriority" && method == http.MethodPost && name == collEnrollmentConfigs:
+		return s.setEnrollmentPriority(e, body)
+
+	case nav == "settings" && method == http.MethodGet && (name == collConfigurationPolicies || name == collIntents || name == collTemplates || name == collSettingsCompliance):
+		return s.list(base+"/settings", e.settings, skipToken)
+
+	case nav == "settingTemplates" && method == http.MethodGet && name == collPolicyTemplates:
+		return s.list(base+"/settingTemplates", e.settings, skipToken)
+
+	case nav == "categories" && method == http.MethodGet && name == collIntents:
+		return s.list(base+"/categories", nil, skipToken)
+
+	case nav == "updateSettings" && method == http.MethodPost && name == collIntents:
+		settings, _ := body["settings"].([]interface{})
+		if apiErr := s.validateIntentSettings(e, settings); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		e.settings = mergeIntentSettings(e.settings, settings)
+		e.touch()
+		return http.StatusNoContent, nil, nil
+
+	case strings.HasPrefix(nav, "getOmaSettingPlainTextValue(") && method == http.MethodGet && name == collDeviceConfigurations:
+		m := plainTextValueCall.FindStringSubmatch(nav)
+		if m == nil {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "The secretReferenceValueId parameter is required."}
+		}
+		value, ok := e.secrets[m[1]]
+		if !ok {
+			return notFound(m[1])
+		}
+		return http.StatusOK, map[string]interface{}{
+			"@odata.context": s.URL + apiVersion + "/$metadata#Edm.String",
+			"value":          value,
+		}, nil
+
+	case nav == "localizedNotificationMessages" && method == http.MethodGet && name == collNotificationTemplates:
+		return s.list(base+"/localizedNotificationMessages", e.messages, skipToken)
+
+	case nav == "localizedNotificationMessages" && method == http.MethodPost && name == collNotificationTemplates:
+		return s.createLocalizedMessage(e, body)
+
+	case nav == "driverInventories" && method == http.MethodGet && name == collDriverUpdates:
+		return s.list(base+"/driverInventories", e.drivers, skipToken)
+
+	case nav == "executeAction" && method == http.MethodPost && name == collDriverUpdates:
+		return executeDriverAction(e, body)
+
+	case nav == "scheduledActionsForRule" && method == http.MethodGet && (name == collCompliancePolicies || name == collSettingsCompliance):
+		return s.list(base+"/scheduledActionsForRule", e.actions, skipToken)
+
+	case nav == "setScheduledActions" && method == http.MethodPost && name == collSettingsCompliance:
+		actions, _ := body["scheduledActions"].([]interface{})
+		if apiErr := validateScheduledActions(actions); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		e.actions = s.withActionIDs(actions)
+		e.touch()
+		return http.StatusNoContent, nil, nil
+
+	case nav == "scheduleActionsForRules" && method == http.MethodPost && name == collCompliancePolicies:
+		actions, _ := body["deviceComplianceScheduledActionForRules"].([]interface{})
+		if apiErr := validateScheduledActions(actions); apiErr != nil {
+			return 0, nil, apiErr
+		}
+		e.actions = s.withActionIDs(actions)
+		e.touch()
+		return http.StatusNoContent, nil, nil
+	}
+
+	return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Resource not found for the segment '%s'.", nav)}
+}
+
+// routeDefinitions serves the Settings Catalog setting definitions
+func (s *Server) routeDefinitions(method string, segments []string, skipToken string) (int, interface{}, *apiError) {
+	if method != http.MethodGet {
+		return 0, nil, &apiError{http.StatusMethodNotAllowed, "BadRequest", "Setting definitions are read-only."}
+	}
+
+	if len(segments) == 0 {
+		ids := make([]string, 0, len(s.definitions))
+		for id := range s.definitions {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		values := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			values = append(values, copyMap(s.definitions[id]))
+		}
+		return s.list("/deviceManagement/configurationSettings", values, skipToken)
+	}
+
+	def, ok := s.definitions[segments[0]]
+	if !ok {
+		return notFound(segments[0])
+	}
+	return http.StatusOK, copyMap(def), nil
+}
+
+// list returns one page of a collection, with a next link when more items are available
+func (s *Server) list(path string, values []interface{}, skipToken string) (int, interface{}, *apiError) {
+	offset := 0
+	if skipToken != "" {
+		var err error
+		offset, err = strconv.Atoi(skipToken)
+		if err != nil || offset < 0 {
+			return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", "Invalid $skiptoken."}
+		}
+	}
+
+	pageSize := s.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	page := []interface{}{}
+	if offset < len(values) {
+		end := offset + pageSize
+		if end > len(values) {
+			end = len(values)
+		}
+		page = copyValue(values[offset:end]).([]interface{})
+	}
+
+	result := map[string]interface{}{
+		"@odata.context": s.URL + apiVersion + "/$metadata#" + path,
+		"value":          page,
+	}
+	if offset+pageSize < len(values) {
+		result["@odata.nextLink"] = fmt.Sprintf("%s%s%s?$skiptoken=%d", s.URL, apiVersion, path, offset+pageSize)
+	}
+	return http.StatusOK, result, nil
+}
+
+// assign replaces the assignments of an entity with the assignments listed in the key property of
+// the body
+func (s *Server) assign(e *entity, body map[string]interface{}, key string) (int, interface{}, *apiError) {
+	raw, ok := body[key].([]interface{})
+	if !ok {
+		return 0, nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("The %s property is required.", key)}
+	}
+
+	policyID, _ := e.props["id"].(string)
+	assignments := make([]interface{}, 0, len(raw))
+	for i, item := range raw {
+		stored, apiErr := newAssignment(item, fmt.Sprintf("%s_%d", policyID, i))
+		if apiErr != nil {
+			return 0, nil, apiErr
+		}
+		assignments = append(assignments, stored)
+	}
+
+	e.assignments = assignments
+	if _, ok := e.props["isAssigned"]; ok {
+		e.props["isAssigned"] = len(assignments) > 0
+	}
+
+	return http.StatusOK, map[string]interface{}{"value": copyValue(assignments)}, nil
+}
+
+// newAssignment validates the target of an assignment and returns a copy of it with an ID
+func newAssignment(item interface{}, id string) (map[string]interface{}, *apiError) {
+	a, ok := item.(map[string]interface{})
+	if !ok {
+		return nil, &apiError{http.StatusBadRequest, "BadRequest", "Invalid assignment."}
+	}
+	target, ok := a["target"].(map[string]interface{})
+	if !ok {
+		return nil, &apiError{http.StatusBadRequest, "BadRequest", "Assignment target is required."}
+	}
+	odataType, _ := target["@odata.type"].(string)
+	switch odataType {
+	case "#microsoft.graph.groupAssignmentTarget", "#microsoft.graph.exclusionGroupAssignmentTarget":
+		if groupID, _ := target["groupId"].(string); groupID == "" {
+			return nil, &apiError{http.StatusBadRequest, "BadRequest", "groupId is required for group assignment targets."}
+		}
+	case "#microsoft.graph.allDevicesAssignmentTarget", "#microsoft.graph.allLicensedUsersAssignmentTarget":
+	default:
+		return nil, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Unsupported assignment target type '%s'.", odataType)}
+	}
+
+	stored := copyMap(a)
+	stored["id"] = id
+	stored["target"] = copyMap(target)
+	return stored, nil
+}
+
+// serveBatch executes a JSON batch request
+func (s *Server) serveBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, &apiError{http.StatusMethodNotAllowed, "BadRequest", "$batch only supports POST."})
+		return
+	}
+
+	var batch struct {
+		Requests []struct {
+			ID        string            `json:"id"`
+			Method    string            `json:"method"`
+			URL       string            `json:"url"`
+			Headers   map[string]string `json:"headers"`
+			Body      json.RawMessage   `json:"body"`
+			DependsOn []string          `json:"dependsOn"`
+		} `json:"requests"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		writeError(w, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Invalid batch payload: %s", err)})
+		return
+	}
+	if len(batch.Requests) > maxBatchSize {
+		writeError(w, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Number of batch request steps exceeds the maximum value of %d.", maxBatchSize)})
+		return
+	}
+
+	statuses := make(map[string]int, len(batch.Requests))
+	responses := make([]map[string]interface{}, 0, len(batch.Requests))
+	for _, item := range batch.Requests {
+		failedDependency := false
+		for _, dep := range item.DependsOn {
+			if status, ok := statuses[dep]; !ok || status >= 400 {
+				failedDependency = true
+			}
+		}
+
+		rec := httptest.NewRecorder()
+		if failedDependency {
+			writeError(rec, &apiError{http.StatusFailedDependency, "FailedDependency", "Request failed because a dependency failed."})
+		} else {
+			sub, err := http.NewRequest(item.Method, apiVersion+"/"+strings.TrimPrefix(item.URL, "/"), bytes.NewReader(item.Body))
+			if err != nil {
+				writeError(rec, &apiError{http.StatusBadRequest, "BadRequest", fmt.Sprintf("Invalid request URL: %s", err)})
+			} else {
+				s.serveRequest(rec, sub)
+			}
+		}
+
+		statuses[item.ID] = rec.Code
+		response := map[string]interface{}{
+			"id":     item.ID,
+			"status": rec.Code,
+		}
+		headers := map[string]string{}
+		for _, key := range []string{"Content-Type", "Retry-After"} {
+			if v := rec.Header().Get(key); v != "" {
+				headers[key] = v
+			}
+		}
+		if len(headers) > 0 {
+			response["headers"] = headers
+		}
+		if rec.Body.Len() > 0 {
+			response["body"] = json.RawMessage(rec.Body.Bytes())
+		}
+		responses = append(responses, response)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"responses": responses})
+}
+
+// newID returns a new GUID-formatted identifier. The caller holds the lock.
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.nextID)
+}
+
+// lookup returns a stored entity, or nil. The caller holds the lock.
+func (s *Server) lookup(collectionName, id string) *entity {
+	c, ok := s.collections[collectionName]
+	if !ok {
+		return nil
+	}
+	return c.items[id]
+}
+
+// add stores an entity under the given ID
+func (c *collection) add(id string, e *entity) {
+	c.items[id] = e
+	c.order = append(c.order, id)
+}
+
+// remove deletes the entity with the given ID
+func (c *collection) remove(id string) {
+	if _, ok := c.items[id]; !ok {
+		return
+	}
+	delete(c.items, id)
+	for i, existing := range c.order {
+		if existing == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// values returns the stored entities in insertion order
+func (c *collection) values() []interface{} {
+	values := make([]interface{}, 0, len(c.order))
+	for _, id := range c.order {
+		values = append(values, c.items[id].props)
+	}
+	return values
+}
+
+// touch updates the last modified timestamp of an entity
+func (e *entity) touch() {
+	if _, ok := e.props["lastModifiedDateTime"]; ok {
+		e.props["lastModifiedDateTime"] = timestamp()
+	}
+}
+
+// timestamp returns the current time in the format used by Graph
+func timestamp() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05.0000000Z")
+}
+
+// notFound returns the error Intune reports for a missing object
+func notFound(key string) (int, interface{}, *apiError) {
+	return 0, nil, &apiError{http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("Resource '%s' was not found.", key)}
+}
+
+// writeError writes an error response in the Graph format
+func writeError(w http.ResponseWriter, e *apiError) {
+	writeJSON(w, e.status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    e.code,
+			"message": e.message,
+			"innerError": map[string]interface{}{
+				"date":       time.Now().UTC().Format(time.RFC3339),
+				"request-id": "00000000-0000-0000-0000-000000000000",
+			},
+		},
+	})
+}
+
+// writeJSON writes a JSON response, or an empty body for 204 No Content
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	if status == http.StatusNoContent || value == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// copyMap returns a deep copy of a JSON object
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	return copyValue(m).(map[string]interface{})
+}
+
+// copyValue returns a deep copy of a decoded JSON value
+func copyValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			out[k] = copyValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, item := range value {
+			out[i] = copyValue(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
@@ -4,41 +4,55 @@
 package provider
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
-	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	"github.com/MANCHTOOLS/tofutune/internal/clients"
 )
 
+// Assignment target types, as used by the type attribute of target blocks
+const (
+	AssignmentTargetGroup      = "group"
+	AssignmentTargetExclusion  = "exclusion"
+	AssignmentTargetAllDevices = "all_devices"
+	AssignmentTargetAllUsers   = "all_users"
+)
+
+// assignmentTargetODataTypes maps assignment target types to the OData types of Graph
+var assignmentTargetODataTypes = map[string]string{
+	AssignmentTargetGroup:      "#microsoft.graph.groupAssignmentTarget",
+	AssignmentTargetExclusion:  "#microsoft.graph.exclusionGroupAssignmentTarget",
+	AssignmentTargetAllDevices: "#microsoft.graph.allDevicesAssignmentTarget",
+	AssignmentTargetAllUsers:   "#microsoft.graph.allLicensedUsersAssignmentTarget",
+}
+
 // AssignmentModel represents an inline assignment block
 type AssignmentModel struct {
-	IncludeGroups types.List   `tfsdk:"include_groups"`
-	ExcludeGroups types.List   `tfsdk:"exclude_groups"`
-	AllDevices    types.Bool   `tfsdk:"all_devices"`
-	AllUsers      types.Bool   `tfsdk:"all_users"`
-	FilterID      types.String `tfsdk:"filter_id"`
-	FilterType    types.String `tfsdk:"filter_type"`
+	Targets []AssignmentTargetModel `tfsdk:"target"`
 }
 
-// AssignmentModelAttrTypes returns the attribute types for AssignmentModel
-func AssignmentModelAttrTypes() map[string]attr.Type {
-	return map[string]attr.Type{
-		"include_groups": types.ListType{ElemType: types.StringType},
-		"exclude_groups": types.ListType{ElemType: types.StringType},
-		"all_devices":    types.BoolType,
-		"all_users":      types.BoolType,
-		"filter_id":      types.StringType,
-		"filter_type":    types.StringType,
-	}
+// AssignmentTargetModel represents a target block: one group, exclusion, all devices or all
+// users, with its own assignment filter
+type AssignmentTargetModel struct {
+	Type       types.String `tfsdk:"type"`
+	GroupID    types.String `tfsdk:"group_id"`
+	FilterID   types.String `tfsdk:"filter_id"`
+	FilterType types.String `tfsdk:"filter_type"`
 }
 
 // AssignmentBlockSchema returns the schema for the assignment block
@@ -46,124 +60,183 @@ func AssignmentBlockSchema() schema.ListNestedBlock {
 	return schema.ListNestedBlock{
 		Description: "Assignment configuration for this policy. If not specified, the policy will not be assigned to any groups.",
 		MarkdownDescription: `
-Assignment configuration for this policy. Multiple assignment blocks can be specified.
-
-Each assignment block can target:
-- Specific Azure AD groups (include_groups)
-- All devices (all_devices = true)
-- All users (all_users = true)
-
-Exclusions can be specified with exclude_groups.
+Assignment configuration for this policy. If not specified, the policy will not be assigned.
+
+The block holds one ` + "`target`" + ` block per group, exclusion group, all devices or all users
+target, each with its own assignment filter:
+
+` + "```hcl" + `
+assignment {
+  target {
+    type        = "group"
+    group_id    = data.azuread_group.pilot.id
+    filter_id   = intune_assignment_filter.corporate.id
+    filter_type = "include"
+  }
+  target {
+    type     = "exclusion"
+    group_id = data.azuread_group.test_devices.id
+  }
+  target {
+    type = "all_users"
+  }
+}
+` + "```" + `
 `,
+		Validators: []validator.List{
+			listvalidator.SizeAtMost(1),
+		},
+		NestedObject: schema.NestedBlockObject{
+			Blocks: map[string]schema.Block{
+				"target": AssignmentTargetBlockSchema(),
+			},
+		},
+	}
+}
+
+// AssignmentTargetBlockSchema returns the schema for target blocks
+func AssignmentTargetBlockSchema() schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		Description: "A target of the assignment. Specify one block per target.",
+		Validators: []validator.List{
+			listvalidator.SizeAtLeast(1),
+		},
 		NestedObject: schema.NestedBlockObject{
 			Attributes: map[string]schema.Attribute{
-				"include_groups": schema.ListAttribute{
-					Description: "List of Azure AD group IDs to include in the assignment.",
-					Optional:    true,
-					ElementType: types.StringType,
-				},
-				"exclude_groups": schema.ListAttribute{
-					Description: "List of Azure AD group IDs to exclude from the assignment.",
-					Optional:    true,
-					ElementType: types.StringType,
-				},
-				"all_devices": schema.BoolAttribute{
-					Description: "Assign to all devices.",
-					Optional:    true,
+				"type": schema.StringAttribute{
+					Description: "The target type. Valid values: group, exclusion, all_devices, all_users.",
+					Required:    true,
+					Validators: []validator.String{
+						stringvalidator.OneOf(
+							AssignmentTargetGroup,
+							AssignmentTargetExclusion,
+							AssignmentTargetAllDevices,
+							AssignmentTargetAllUsers,
+						),
+					},
 				},
-				"all_users": schema.BoolAttribute{
-					Description: "Assign to all licensed users.",
+				"group_id": schema.StringAttribute{
+					Description: "The ID of the Azure AD group. Required for group and exclusion targets.",
 					Optional:    true,
 				},
 				"filter_id": schema.StringAttribute{
-					Description: "The ID of an assignment filter to apply.",
+					Description: "The ID of an assignment filter to apply to this target. Not supported for exclusion targets.",
 					Optional:    true,
 				},
 				"filter_type": schema.StringAttribute{
-					Description: "The type of filter: 'include' or 'exclude'.",
+					Description: "The type of filter: 'include' or 'exclude'. Defaults to include when filter_id is set.",
 					Optional:    true,
 					Validators: []validator.String{
 						stringvalidator.OneOf("include", "exclude"),
 					},
 				},
 			},
+			Validators: []validator.Object{
+				assignmentTargetValidator{},
+			},
 		},
 	}
 }
 
-// BuildAssignmentsFromBlocks builds assignment objects from assignment blocks
-func BuildAssignmentsFromBlocks(ctx context.Context, assignments []AssignmentModel, diags *diag.Diagnostics) []clients.PolicyAssignment {
-	var result []clients.PolicyAssignment
+// assignmentTargetValidator checks that group_id is set exactly for group and exclusion targets,
+// and that filters are only set on targets that support them
+type assignmentTargetValidator struct{}
 
-	for _, assignment := range assignments {
-		// Handle include groups
-		if !assignment.IncludeGroups.IsNull() {
-			var groupIds []string
-			diags.Append(assignment.IncludeGroups.ElementsAs(ctx, &groupIds, false)...)
-			if diags.HasError() {
-				return nil
-			}
+// Description describes the validation
+func (v assignmentTargetValidator) Description(ctx context.Context) string {
+	return "group_id must be set for group and exclusion targets only, and filters cannot be applied to exclusion targets"
+}
 
-			for _, groupId := range groupIds {
-				target := &clients.AssignmentTarget{
-					ODataType: "#microsoft.graph.groupAssignmentTarget",
-					GroupId:   groupId,
-				}
-				addFilterToTarget(target, assignment)
-				result = append(result, clients.PolicyAssignment{Target: target})
-			}
-		}
+// MarkdownDescription describes the validation in Markdown
+func (v assignmentTargetValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
 
-		// Handle all devices
-		if !assignment.AllDevices.IsNull() && assignment.AllDevices.ValueBool() {
-			target := &clients.AssignmentTarget{
-				ODataType: "#microsoft.graph.allDevicesAssignmentTarget",
-			}
-			addFilterToTarget(target, assignment)
-			result = append(result, clients.PolicyAssignment{Target: target})
-		}
+// ValidateObject validates a target block
+func (v assignmentTargetValidator) ValidateObject(ctx context.Context, req validator.ObjectRequest, resp *validator.ObjectResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
 
-		// Handle all users
-		if !assignment.AllUsers.IsNull() && assignment.AllUsers.ValueBool() {
-			target := &clients.AssignmentTarget{
-				ODataType: "#microsoft.graph.allLicensedUsersAssignmentTarget",
-			}
-			addFilterToTarget(target, assignment)
-			result = append(result, clients.PolicyAssignment{Target: target})
-		}
+	var target AssignmentTargetModel
+	resp.Diagnostics.Append(req.ConfigValue.As(ctx, &target, basetypes.ObjectAsOptions{})...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-		// Handle exclude groups
-		if !assignment.ExcludeGroups.IsNull() {
-			var groupIds []string
-			diags.Append(assignment.ExcludeGroups.ElementsAs(ctx, &groupIds, false)...)
-			if diags.HasError() {
-				return nil
-			}
+	validateAssignmentTarget(target, req.Path, &resp.Diagnostics)
+}
 
-			for _, groupId := range groupIds {
-				result = append(result, clients.PolicyAssignment{
-					Target: &clients.AssignmentTarget{
-						ODataType: "#microsoft.graph.exclusionGroupAssignmentTarget",
-						GroupId:   groupId,
-					},
-				})
-			}
+// validateAssignmentTarget checks a target whose attributes are at p. Targets of unknown type
+// are skipped.
+func validateAssignmentTarget(target AssignmentTargetModel, p path.Path, diags *diag.Diagnostics) {
+	if target.Type.IsNull() || target.Type.IsUnknown() {
+		return
+	}
+
+	targetType := target.Type.ValueString()
+	switch targetType {
+	case AssignmentTargetGroup, AssignmentTargetExclusion:
+		if target.GroupID.IsNull() {
+			diags.AddAttributeError(
+				p.AtName("group_id"),
+				"Missing Assignment Group",
+				fmt.Sprintf("group_id must be set for %s targets.", targetType),
+			)
+		}
+	default:
+		if !target.GroupID.IsNull() {
+			diags.AddAttributeError(
+				p.AtName("group_id"),
+				"Unexpected Assignment Group",
+				fmt.Sprintf("group_id cannot be set for %s targets.", targetType),
+			)
 		}
 	}
 
+	if targetType == AssignmentTargetExclusion && !target.FilterID.IsNull() {
+		diags.AddAttributeError(
+			p.AtName("filter_id"),
+			"Unsupported Assignment Filter",
+			"Assignment filters cannot be applied to exclusion targets.",
+		)
+	}
+	if target.FilterID.IsNull() && !target.FilterType.IsNull() {
+		diags.AddAttributeError(
+			p.AtName("filter_type"),
+			"Missing Assignment Filter",
+			"filter_type requires filter_id.",
+		)
+	}
+}
+
+// BuildAssignmentsFromBlocks builds assignment objects from assignment blocks
+func BuildAssignmentsFromBlocks(ctx context.Context, assignments []AssignmentModel, diags *diag.Diagnostics) []clients.PolicyAssignment {
+	var result []clients.PolicyAssignment
+	for _, assignment := range assignments {
+		result = append(result, BuildAssignmentTargets(assignment.Targets)...)
+	}
 	return result
 }
 
-// addFilterToTarget adds filter configuration to an assignment target
-func addFilterToTarget(target *clients.AssignmentTarget, assignment AssignmentModel) {
-	if !assignment.FilterID.IsNull() && assignment.FilterID.ValueString() != "" {
-		target.DeviceAndAppManagementAssignmentFilterId = assignment.FilterID.ValueString()
-		filterType := "include"
-		if !assignment.FilterType.IsNull() {
-			filterType = assignment.FilterType.ValueString()
+// BuildAssignmentTargets builds assignment objects from target blocks
+func BuildAssignmentTargets(targets []AssignmentTargetModel) []clients.PolicyAssignment {
+	result := make([]clients.PolicyAssignment, 0, len(targets))
+	for _, t := range targets {
+		target := &clients.AssignmentTarget{
+			ODataType: assignmentTargetODataTypes[t.Type.ValueString()],
+			GroupId:   t.GroupID.ValueString(),
 		}
-		target.DeviceAndAppManagementAssignmentFilterType = filterType
+		if filterID := t.FilterID.ValueString(); filterID != "" {
+			target.DeviceAndAppManagementAssignmentFilterId = filterID
+			target.DeviceAndAppManagementAssignmentFilterType = "include"
+			if !t.FilterType.IsNull() {
+				target.DeviceAndAppManagementAssignmentFilterType = t.FilterType.ValueString()
+			}
+		}
+		result = append(result, clients.PolicyAssignment{Target: target})
 	}
+	return result
 }
 
 // AssignPolicy creates or updates policy assignments
@@ -173,12 +246,7 @@ func AssignPolicy(ctx context.Context, client *clients.GraphClient, policyType,
 		return fmt.Errorf("unknown policy type: %s", policyType)
 	}
 
-	body := map[string]interface{}{
-		"assignments": assignments,
-	}
-
-	_, err := client.Post(ctx, assignPath, body)
-	if err != nil {
+	if err := postAssignments(ctx, client, policyType, assignPath, assignments); err != nil {
 		return fmt.Errorf("failed to assign policy: %w", err)
 	}
 
@@ -191,6 +259,89 @@ func AssignPolicy(ctx context.Context, client *clients.GraphClient, policyType,
 	return nil
 }
 
+// postAssignments replaces the assignments of a policy through its assign path. Enrollment
+// configurations name the assignments of the request body differently, and Autopilot profiles
+// have no assign action at all, so their assignments are synchronized one at a time.
+func postAssignments(ctx context.Context, client *clients.GraphClient, policyType, assignPath string, assignments interface{}) error {
+	switch policyType {
+	case PolicyTypeAutopilot:
+		return syncAssignments(ctx, client, assignPath, assignments)
+	case PolicyTypeEnrollmentStatusPage:
+		_, err := client.Post(ctx, assignPath, map[string]interface{}{
+			"enrollmentConfigurationAssignments": assignments,
+		})
+		return err
+	default:
+		_, err := client.Post(ctx, assignPath, map[string]interface{}{
+			"assignments": assignments,
+		})
+		return err
+	}
+}
+
+// syncAssignments makes the assignment collection at assignmentsPath hold the targets of
+// assignments, deleting the assignments that are no longer wanted and creating the missing ones.
+// Assignments whose target is unchanged are kept, so devices do not lose their profile while it
+// is being reassigned.
+func syncAssignments(ctx context.Context, client *clients.GraphClient, assignmentsPath string, assignments interface{}) error {
+	raw, err := json.Marshal(assignments)
+	if err != nil {
+		return err
+	}
+	var wanted []map[string]interface{}
+	if err := json.Unmarshal(raw, &wanted); err != nil {
+		return err
+	}
+
+	current, err := clients.ListInto[map[string]interface{}](ctx, client, assignmentsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read assignments: %w", err)
+	}
+
+	pending := make(map[string]int, len(wanted))
+	for _, assignment := range wanted {
+		pending[assignmentTargetKey(assignment["target"])]++
+	}
+	for _, assignment := range current {
+		key := assignmentTargetKey(assignment["target"])
+		if pending[key] > 0 {
+			pending[key]--
+			continue
+		}
+		id, _ := assignment["id"].(string)
+		if err := client.Delete(ctx, fmt.Sprintf("%s/%s", assignmentsPath, id)); err != nil && !clients.IsNotFound(err) {
+			return err
+		}
+	}
+
+	for _, assignment := range wanted {
+		key := assignmentTargetKey(assignment["target"])
+		if pending[key] == 0 {
+			continue
+		}
+		pending[key]--
+		if _, err := client.Post(ctx, assignmentsPath, map[string]interface{}{"target": assignment["target"]}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// assignmentTargetKey identifies an assignment target by its type, group and filter. The filter
+// type is ignored on targets without a filter, which Graph reports with filter type none.
+func assignmentTargetKey(target interface{}) string {
+	t, _ := target.(map[string]interface{})
+	odataType, _ := t["@odata.type"].(string)
+	groupId, _ := t["groupId"].(string)
+	filterId, _ := t["deviceAndAppManagementAssignmentFilterId"].(string)
+	filterType := ""
+	if filterId != "" {
+		filterType, _ = t["deviceAndAppManagementAssignmentFilterType"].(string)
+	}
+	return fmt.Sprintf("%s|%s|%s|%s", odataType, groupId, filterId, filterType)
+}
+
 // ReadPolicyAssignments reads the current assignments for a policy
 func ReadPolicyAssignments(ctx context.Context, client *clients.GraphClient, policyType, policyId string) ([]AssignmentModel, error) {
 	readPath := getAssignmentsReadPath(policyType, policyId)
@@ -198,94 +349,274 @@ func ReadPolicyAssignments(ctx context.Context, client *clients.GraphClient, pol
 		return nil, fmt.Errorf("unknown policy type: %s", policyType)
 	}
 
-	response, err := client.Get(ctx, readPath)
+	apiAssignments, err := clients.ListInto[clients.PolicyAssignment](ctx, client, readPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read assignments: %w", err)
 	}
 
-	if response.Value == nil {
-		return nil, nil
+	return buildAssignmentModels(ctx, apiAssignments), nil
+}
+
+// policyReadResult holds a policy and its assignments read in a single batch
+type policyReadResult[T any] struct {
+	Policy *T
+
+	// Assignments holds the policy assignments when they were requested
+	Assignments []AssignmentModel
+
+	// AssignmentsErr is set when the policy was read but its assignments could not be
+	AssignmentsErr error
+}
+
+// readPolicyWithAssignments reads a policy and, when withAssignments is set, its assignments
+// in a single $batch round trip instead of two separate requests. This halves the number of
+// requests made while refreshing large states.
+func readPolicyWithAssignments[T any](ctx context.Context, client *clients.GraphClient, policyType, policyPath, policyId string, withAssignments bool) (*policyReadResult[T], error) {
+	if !withAssignments {
+		policy, err := clients.GetInto[T](ctx, client, policyPath)
+		if err != nil {
+			return nil, err
+		}
+		return &policyReadResult[T]{Policy: policy}, nil
 	}
 
-	var apiAssignments []struct {
-		ID     string `json:"id"`
-		Target struct {
-			ODataType                                  string `json:"@odata.type"`
-			GroupId                                    string `json:"groupId"`
-			DeviceAndAppManagementAssignmentFilterId   string `json:"deviceAndAppManagementAssignmentFilterId"`
-			DeviceAndAppManagementAssignmentFilterType string `json:"deviceAndAppManagementAssignmentFilterType"`
-		} `json:"target"`
+	readPath := getAssignmentsReadPath(policyType, policyId)
+	if readPath == "" {
+		return nil, fmt.Errorf("unknown policy type: %s", policyType)
+	}
+
+	responses, err := client.Batch(ctx, []clients.BatchRequest{
+		{ID: "policy", Method: http.MethodGet, Path: policyPath},
+		{ID: "assignments", Method: http.MethodGet, Path: readPath},
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err := json.Unmarshal(response.Value, &apiAssignments); err != nil {
-		return nil, fmt.Errorf("failed to parse assignments: %w", err)
+	result := &policyReadResult[T]{}
+	if responses[0].Err != nil {
+		return nil, responses[0].Err
+	}
+	var policy T
+	if err := responses[0].Decode(&policy); err != nil {
+		return nil, err
 	}
+	result.Policy = &policy
 
-	// Group assignments by type for building AssignmentModel objects
-	var includeGroups []string
-	var excludeGroups []string
-	var allDevices, allUsers bool
-	var filterID, filterType string
+	var page struct {
+		Value    []clients.PolicyAssignment `json:"value"`
+		NextLink string                     `json:"@odata.nextLink"`
+	}
+	if err := responses[1].Decode(&page); err != nil {
+		result.AssignmentsErr = fmt.Errorf("failed to read assignments: %w", err)
+		return result, nil
+	}
 
+	// Batch responses only carry the first page; fetch the remaining pages directly
+	if page.NextLink != "" {
+		result.Assignments, result.AssignmentsErr = ReadPolicyAssignments(ctx, client, policyType, policyId)
+		return result, nil
+	}
+
+	result.Assignments = buildAssignmentModels(ctx, page.Value)
+	return result, nil
+}
+
+// buildAssignmentModels converts the assignments returned by the API into a single AssignmentModel
+func buildAssignmentModels(ctx context.Context, apiAssignments []clients.PolicyAssignment) []AssignmentModel {
+	targets := assignmentTargetModels(apiAssignments)
+	if len(targets) == 0 {
+		return nil
+	}
+	return []AssignmentModel{{Targets: targets}}
+}
+
+// assignmentTargetModels converts the assignments returned by the API into target blocks.
+// Targets of types the provider does not manage are left out.
+func assignmentTargetModels(apiAssignments []clients.PolicyAssignment) []AssignmentTargetModel {
+	var targets []AssignmentTargetModel
 	for _, a := range apiAssignments {
-		switch a.Target.ODataType {
-		case "#microsoft.graph.groupAssignmentTarget":
-			includeGroups = append(includeGroups, a.Target.GroupId)
-			if a.Target.DeviceAndAppManagementAssignmentFilterId != "" {
-				filterID = a.Target.DeviceAndAppManagementAssignmentFilterId
-				filterType = a.Target.DeviceAndAppManagementAssignmentFilterType
-			}
-		case "#microsoft.graph.exclusionGroupAssignmentTarget":
-			excludeGroups = append(excludeGroups, a.Target.GroupId)
-		case "#microsoft.graph.allDevicesAssignmentTarget":
-			allDevices = true
-			if a.Target.DeviceAndAppManagementAssignmentFilterId != "" {
-				filterID = a.Target.DeviceAndAppManagementAssignmentFilterId
-				filterType = a.Target.DeviceAndAppManagementAssignmentFilterType
+		if a.Target == nil {
+			continue
+		}
+		targetType := assignmentTargetType(a.Target.ODataType)
+		if targetType == "" {
+			continue
+		}
+
+		target := AssignmentTargetModel{
+			Type:       types.StringValue(targetType),
+			GroupID:    types.StringNull(),
+			FilterID:   types.StringNull(),
+			FilterType: types.StringNull(),
+		}
+		if a.Target.GroupId != "" {
+			target.GroupID = types.StringValue(a.Target.GroupId)
+		}
+		// Graph reports targets without a filter with filter type none
+		if a.Target.DeviceAndAppManagementAssignmentFilterId != "" {
+			target.FilterID = types.StringValue(a.Target.DeviceAndAppManagementAssignmentFilterId)
+			target.FilterType = types.StringValue(a.Target.DeviceAndAppManagementAssignmentFilterType)
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// assignmentTargetType returns the target type of an OData assignment target type, or an empty
+// string for types the provider does not manage
+func assignmentTargetType(odataType string) string {
+	for targetType, targetODataType := range assignmentTargetODataTypes {
+		if targetODataType == odataType {
+			return targetType
+		}
+	}
+	return ""
+}
+
+// PreserveAssignmentTargets orders the targets read from the API like the prior targets, which
+// Graph does not preserve, and keeps an omitted filter_type where the API reports the default.
+// Targets that are not in the prior state are appended.
+func PreserveAssignmentTargets(prior, read []AssignmentTargetModel) []AssignmentTargetModel {
+	if len(read) == 0 {
+		return nil
+	}
+
+	used := make([]bool, len(read))
+	result := make([]AssignmentTargetModel, 0, len(read))
+	for _, p := range prior {
+		for i, r := range read {
+			if used[i] || !r.Type.Equal(p.Type) || !r.GroupID.Equal(p.GroupID) {
+				continue
 			}
-		case "#microsoft.graph.allLicensedUsersAssignmentTarget":
-			allUsers = true
-			if a.Target.DeviceAndAppManagementAssignmentFilterId != "" {
-				filterID = a.Target.DeviceAndAppManagementAssignmentFilterId
-				filterType = a.Target.DeviceAndAppManagementAssignmentFilterType
+			used[i] = true
+			if p.FilterType.IsNull() && r.FilterID.Equal(p.FilterID) && r.FilterType.ValueString() == "include" {
+				r.FilterType = types.StringNull()
 			}
+			result = append(result, r)
+			break
 		}
 	}
+	for i, r := range read {
+		if !used[i] {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// PreserveAssignments applies PreserveAssignmentTargets to the assignment blocks of a policy
+func PreserveAssignments(prior, read []AssignmentModel) []AssignmentModel {
+	var priorTargets, readTargets []AssignmentTargetModel
+	for _, assignment := range prior {
+		priorTargets = append(priorTargets, assignment.Targets...)
+	}
+	for _, assignment := range read {
+		readTargets = append(readTargets, assignment.Targets...)
+	}
 
-	// If no assignments, return nil
-	if len(includeGroups) == 0 && len(excludeGroups) == 0 && !allDevices && !allUsers {
-		return nil, nil
+	targets := PreserveAssignmentTargets(priorTargets, readTargets)
+	if len(targets) == 0 {
+		return nil
 	}
+	return []AssignmentModel{{Targets: targets}}
+}
 
-	// Build a single AssignmentModel that represents all assignments
-	assignment := AssignmentModel{
-		AllDevices: types.BoolValue(allDevices),
-		AllUsers:   types.BoolValue(allUsers),
+// assignmentStateUpgraders upgrades the state of policy resources from schema version 0, whose
+// assignment blocks listed groups and applied a single filter to all of their targets
+func assignmentStateUpgraders() map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				upgradeStateJSON(req, resp, func(state map[string]interface{}) {
+					blocks, _ := state["assignment"].([]interface{})
+					var targets []interface{}
+					for _, block := range blocks {
+						if block, ok := block.(map[string]interface{}); ok {
+							targets = append(targets, legacyAssignmentTargets(block)...)
+						}
+					}
+					if len(targets) > 0 {
+						state["assignment"] = []interface{}{map[string]interface{}{"target": targets}}
+					} else if blocks != nil {
+						state["assignment"] = []interface{}{}
+					}
+				})
+			},
+		},
 	}
+}
 
-	if len(includeGroups) > 0 {
-		includeList, _ := types.ListValueFrom(ctx, types.StringType, includeGroups)
-		assignment.IncludeGroups = includeList
-	} else {
-		assignment.IncludeGroups = types.ListNull(types.StringType)
+// legacyAssignmentTargets converts the flat assignment attributes of schema version 0 into target
+// blocks, in the order the assignments were created in, and removes them from values
+func legacyAssignmentTargets(values map[string]interface{}) []interface{} {
+	filterID, _ := values["filter_id"].(string)
+	filterType := values["filter_type"]
+	target := func(targetType string, groupID interface{}, withFilter bool) interface{} {
+		t := map[string]interface{}{"type": targetType, "group_id": groupID}
+		if withFilter && filterID != "" {
+			t["filter_id"] = filterID
+			t["filter_type"] = filterType
+		}
+		return t
 	}
 
-	if len(excludeGroups) > 0 {
-		excludeList, _ := types.ListValueFrom(ctx, types.StringType, excludeGroups)
-		assignment.ExcludeGroups = excludeList
-	} else {
-		assignment.ExcludeGroups = types.ListNull(types.StringType)
+	var targets []interface{}
+	includeGroups, _ := values["include_groups"].([]interface{})
+	for _, groupID := range includeGroups {
+		targets = append(targets, target(AssignmentTargetGroup, groupID, true))
+	}
+	if allDevices, _ := values["all_devices"].(bool); allDevices {
+		targets = append(targets, target(AssignmentTargetAllDevices, nil, true))
+	}
+	if allUsers, _ := values["all_users"].(bool); allUsers {
+		targets = append(targets, target(AssignmentTargetAllUsers, nil, true))
+	}
+	excludeGroups, _ := values["exclude_groups"].([]interface{})
+	for _, groupID := range excludeGroups {
+		targets = append(targets, target(AssignmentTargetExclusion, groupID, false))
 	}
 
-	if filterID != "" {
-		assignment.FilterID = types.StringValue(filterID)
-		assignment.FilterType = types.StringValue(filterType)
-	} else {
-		assignment.FilterID = types.StringNull()
-		assignment.FilterType = types.StringNull()
+	for _, key := range []string{"include_groups", "exclude_groups", "all_devices", "all_users", "filter_id", "filter_type"} {
+		delete(values, key)
 	}
+	return targets
+}
 
-	return []AssignmentModel{assignment}, nil
+// upgradeStateJSON upgrades a state by rewriting its JSON representation. Attributes the
+// current schema adds are left out and become null.
+func upgradeStateJSON(req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse, upgrade func(state map[string]interface{})) {
+	if req.RawState == nil || req.RawState.JSON == nil {
+		resp.Diagnostics.AddError(
+			"Unable to Upgrade Resource State",
+			"The prior state has no JSON representation. Please report this issue to the provider developers.",
+		)
+		return
+	}
+
+	// Decode numbers exactly, so they survive the round trip
+	decoder := json.NewDecoder(bytes.NewReader(req.RawState.JSON))
+	decoder.UseNumber()
+	var state map[string]interface{}
+	if err := decoder.Decode(&state); err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Upgrade Resource State",
+			fmt.Sprintf("Could not decode the prior state: %s", err),
+		)
+		return
+	}
+
+	upgrade(state)
+
+	upgraded, err := json.Marshal(state)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Upgrade Resource State",
+			fmt.Sprintf("Could not encode the upgraded state: %s", err),
+		)
+		return
+	}
+	resp.DynamicValue = &tfprotov6.DynamicValue{JSON: upgraded}
 }
 
 // getAssignPath returns the API path for creating/updating assignments
@@ -299,6 +630,19 @@ func getAssignPath(policyType, policyId string) string {
 		return fmt.Sprintf("/deviceManagement/intents/%s/assign", policyId)
 	case PolicyTypeDeviceConfig:
 		return fmt.Sprintf("/deviceManagement/deviceConfigurations/%s/assign", policyId)
+	case PolicyTypeLinuxCompliance:
+		return fmt.Sprintf("/deviceManagement/compliancePolicies('%s')/assign", policyId)
+	case PolicyTypeFeatureUpdate:
+		return fmt.Sprintf("/deviceManagement/windowsFeatureUpdateProfiles/%s/assign", policyId)
+	case PolicyTypeQualityUpdate:
+		return fmt.Sprintf("/deviceManagement/windowsQualityUpdateProfiles/%s/assign", policyId)
+	case PolicyTypeDriverUpdate:
+		return fmt.Sprintf("/deviceManagement/windowsDriverUpdateProfiles/%s/assign", policyId)
+	case PolicyTypeAutopilot:
+		// Autopilot profiles have no assign action, see postAssignments
+		return fmt.Sprintf("/deviceManagement/windowsAutopilotDeploymentProfiles/%s/assignments", policyId)
+	case PolicyTypeEnrollmentStatusPage:
+		return fmt.Sprintf("/deviceManagement/deviceEnrollmentConfigurations/%s/assign", policyId)
 	default:
 		return ""
 	}
@@ -315,6 +659,18 @@ func getAssignmentsReadPath(policyType, policyId string) string {
 		return fmt.Sprintf("/deviceManagement/intents/%s/assignments", policyId)
 	case PolicyTypeDeviceConfig:
 		return fmt.Sprintf("/deviceManagement/deviceConfigurations/%s/assignments", policyId)
+	case PolicyTypeLinuxCompliance:
+		return fmt.Sprintf("/deviceManagement/compliancePolicies('%s')/assignments", policyId)
+	case PolicyTypeFeatureUpdate:
+		return fmt.Sprintf("/deviceManagement/windowsFeatureUpdateProfiles/%s/assignments", policyId)
+	case PolicyTypeQualityUpdate:
+		return fmt.Sprintf("/deviceManagement/windowsQualityUpdateProfiles/%s/assignments", policyId)
+	case PolicyTypeDriverUpdate:
+		return fmt.Sprintf("/deviceManagement/windowsDriverUpdateProfiles/%s/assignments", policyId)
+	case PolicyTypeAutopilot:
+		return fmt.Sprintf("/deviceManagement/windowsAutopilotDeploymentProfiles/%s/assignments", policyId)
+	case PolicyTypeEnrollmentStatusPage:
+		return fmt.Sprintf("/deviceManagement/deviceEnrollmentConfigurations/%s/assignments", policyId)
 	default:
 		return ""
 	}
@@ -0,0 +1,252 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// windowsComplianceODataType is the OData type of the compliance policies configured with the
+// top-level attributes of intune_compliance_policy
+const windowsComplianceODataType = "#microsoft.graph.windows10CompliancePolicy"
+
+// windowsCompliancePlatform is the platform of Windows 10/11 compliance policies
+const windowsCompliancePlatform = "windows10"
+
+// compliancePlatform is a compliance policy type other than Windows: a block whose attributes map
+// to the properties of the type
+type compliancePlatform struct {
+	block       string
+	platform    string
+	odataType   string
+	description string
+	fields      []profileField
+}
+
+// Validation shared by the platform blocks
+var (
+	osVersionPattern        = regexp.MustCompile(`^\d+(\.\d+){0,3}$`)
+	osVersionPatternMessage = "must be a version number such as 14.5 or 10.0.1"
+
+	securityPatchLevelPattern        = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])$`)
+	securityPatchLevelPatternMessage = "must be a date in the format YYYY-MM-DD"
+
+	threatProtectionLevels = []string{"unavailable", "secured", "low", "medium", "high", "notSet"}
+
+	complianceOsVersionFields = []profileField{
+		{name: "os_minimum_version", property: "osMinimumVersion", kind: profileString, pattern: osVersionPattern, patternMessage: osVersionPatternMessage,
+			description: "The minimum OS version."},
+		{name: "os_maximum_version", property: "osMaximumVersion", kind: profileString, pattern: osVersionPattern, patternMessage: osVersionPatternMessage,
+			description: "The maximum OS version."},
+	}
+
+	complianceThreatProtectionFields = []profileField{
+		{name: "device_threat_protection_enabled", property: "deviceThreatProtectionEnabled", kind: profileBool,
+			description: "Require the device to be evaluated by a Mobile Threat Defense partner."},
+		{name: "device_threat_protection_required_security_level", property: "deviceThreatProtectionRequiredSecurityLevel", kind: profileString, values: threatProtectionLevels,
+			description: "The highest Mobile Threat Defense threat level allowed."},
+		{name: "advanced_threat_protection_required_security_level", property: "advancedThreatProtectionRequiredSecurityLevel", kind: profileString, values: threatProtectionLevels,
+			description: "The highest Microsoft Defender for Endpoint risk score allowed."},
+	}
+)
+
+// compliancePlatforms are the compliance policy types besides Windows
+var compliancePlatforms = []compliancePlatform{
+	{
+		block:       "macos",
+		platform:    "macOS",
+		odataType:   "#microsoft.graph.macOSCompliancePolicy",
+		description: "Settings of a macOS compliance policy.",
+		fields: concatProfileFields([]profileField{
+			{name: "password_required", property: "passwordRequired", kind: profileBool, description: "Require a password to unlock the device."},
+			{name: "password_block_simple", property: "passwordBlockSimple", kind: profileBool, description: "Block simple passwords like 1234 or 1111."},
+			{name: "password_required_type", property: "passwordRequiredType", kind: profileString, values: []string{"deviceDefault", "alphanumeric", "numeric"},
+				description: "The type of password required."},
+			{name: "password_minimum_length", property: "passwordMinimumLength", kind: profileInt64, minimum: 4, maximum: 14,
+				description: "The minimum password length (4-14)."},
+			{name: "password_minimum_character_set_count", property: "passwordMinimumCharacterSetCount", kind: profileInt64, minimum: 0, maximum: 4,
+				description: "The minimum number of non-alphanumeric characters (0-4)."},
+			{name: "password_minutes_of_inactivity_before_lock", property: "passwordMinutesOfInactivityBeforeLock", kind: profileInt64, minimum: 1, maximum: 60,
+				description: "Minutes of inactivity before a password is required (1-60)."},
+			{name: "password_expiration_days", property: "passwordExpirationDays", kind: profileInt64, minimum: 1, maximum: 65535,
+				description: "Days until the password expires (1-65535)."},
+			{name: "password_previous_password_block_count", property: "passwordPreviousPasswordBlockCount", kind: profileInt64, minimum: 1, maximum: 24,
+				description: "The number of previous passwords that cannot be reused (1-24)."},
+			{name: "os_minimum_build_version", property: "osMinimumBuildVersion", kind: profileString, description: "The minimum OS build version, e.g. 23F79."},
+			{name: "os_maximum_build_version", property: "osMaximumBuildVersion", kind: profileString, description: "The maximum OS build version."},
+			{name: "system_integrity_protection_enabled", property: "systemIntegrityProtectionEnabled", kind: profileBool, description: "Require System Integrity Protection."},
+			{name: "storage_require_encryption", property: "storageRequireEncryption", kind: profileBool, description: "Require FileVault encryption."},
+			{name: "firewall_enabled", property: "firewallEnabled", kind: profileBool, description: "Require the firewall to be enabled."},
+			{name: "firewall_block_all_incoming", property: "firewallBlockAllIncoming", kind: profileBool, description: "Require the firewall to block all incoming connections."},
+			{name: "firewall_enable_stealth_mode", property: "firewallEnableStealthMode", kind: profileBool, description: "Require the firewall stealth mode."},
+			{name: "gatekeeper_allowed_app_source", property: "gatekeeperAllowedAppSource", kind: profileString,
+				values:      []string{"notConfigured", "macAppStore", "macAppStoreAndIdentifiedDevelopers", "anywhere"},
+				description: "The app sources Gatekeeper must be restricted to."},
+		}, complianceOsVersionFields, complianceThreatProtectionFields),
+	},
+	{
+		block:       "ios",
+		platform:    "iOS",
+		odataType:   "#microsoft.graph.iosCompliancePolicy",
+		description: "Settings of an iOS/iPadOS compliance policy.",
+		fields: concatProfileFields([]profileField{
+			{name: "passcode_required", property: "passcodeRequired", kind: profileBool, description: "Require a passcode to unlock the device."},
+			{name: "passcode_block_simple", property: "passcodeBlockSimple", kind: profileBool, description: "Block simple passcodes like 1234 or 1111."},
+			{name: "passcode_required_type", property: "passcodeRequiredType", kind: profileString, values: []string{"deviceDefault", "alphanumeric", "numeric"},
+				description: "The type of passcode required."},
+			{name: "passcode_minimum_length", property: "passcodeMinimumLength", kind: profileInt64, minimum: 4, maximum: 14,
+				description: "The minimum passcode length (4-14)."},
+			{name: "passcode_minimum_character_set_count", property: "passcodeMinimumCharacterSetCount", kind: profileInt64, minimum: 0, maximum: 4,
+				description: "The minimum number of non-alphanumeric characters (0-4)."},
+			{name: "passcode_minutes_of_inactivity_before_lock", property: "passcodeMinutesOfInactivityBeforeLock", kind: profileInt64, minimum: 0, maximum: 240,
+				description: "Minutes of inactivity before a passcode is required (0-240, 0 for immediately)."},
+			{name: "passcode_minutes_of_inactivity_before_screen_timeout", property: "passcodeMinutesOfInactivityBeforeScreenTimeout", kind: profileInt64, minimum: 1, maximum: 15,
+				description: "Minutes of inactivity before the screen times out (1-15)."},
+			{name: "passcode_expiration_days", property: "passcodeExpirationDays", kind: profileInt64, minimum: 1, maximum: 65535,
+				description: "Days until the passcode expires (1-65535)."},
+			{name: "passcode_previous_passcode_block_count", property: "passcodePreviousPasscodeBlockCount", kind: profileInt64, minimum: 1, maximum: 24,
+				description: "The number of previous passcodes that cannot be reused (1-24)."},
+			{name: "os_minimum_build_version", property: "osMinimumBuildVersion", kind: profileString, description: "The minimum OS build version, e.g. 21F90."},
+			{name: "os_maximum_build_version", property: "osMaximumBuildVersion", kind: profileString, description: "The maximum OS build version."},
+			{name: "security_block_jailbroken_devices", property: "securityBlockJailbrokenDevices", kind: profileBool, description: "Mark jailbroken devices as not compliant."},
+			{name: "managed_email_profile_required", property: "managedEmailProfileRequired", kind: profileBool, description: "Require an email profile managed by Intune."},
+		}, complianceOsVersionFields, complianceThreatProtectionFields),
+	},
+	{
+		block:       "android_device_owner",
+		platform:    "androidDeviceOwner",
+		odataType:   "#microsoft.graph.androidDeviceOwnerCompliancePolicy",
+		description: "Settings of an Android Enterprise fully managed, dedicated or corporate-owned work profile compliance policy.",
+		fields: concatProfileFields([]profileField{
+			{name: "password_required", property: "passwordRequired", kind: profileBool, description: "Require a password to unlock the device."},
+			{name: "password_required_type", property: "passwordRequiredType", kind: profileString,
+				values: []string{
+					"deviceDefault", "required", "numeric", "numericComplex", "alphabetic", "alphanumeric", "alphanumericWithSymbols",
+					"lowSecurityBiometric", "customPassword",
+				},
+				description: "The type of password required."},
+			{name: "password_minimum_length", property: "passwordMinimumLength", kind: profileInt64, minimum: 4, maximum: 16,
+				description: "The minimum password length (4-16)."},
+			{name: "password_minimum_letter_characters", property: "passwordMinimumLetterCharacters", kind: profileInt64, minimum: 1, maximum: 16,
+				description: "The minimum number of letters in the password (1-16)."},
+			{name: "password_minimum_lower_case_characters", property: "passwordMinimumLowerCaseCharacters", kind: profileInt64, minimum: 1, maximum: 16,
+				description: "The minimum number of lower-case letters in the password (1-16)."},
+			{name: "password_minimum_upper_case_characters", property: "passwordMinimumUpperCaseCharacters", kind: profileInt64, minimum: 1, maximum: 16,
+				description: "The minimum number of upper-case letters in the password (1-16)."},
+			{name: "password_minimum_non_letter_characters", property: "passwordMinimumNonLetterCharacters", kind: profileInt64, minimum: 1, maximum: 16,
+				description: "The minimum number of non-letters in the password (1-16)."},
+			{name: "password_minimum_numeric_characters", property: "passwordMinimumNumericCharacters", kind: profileInt64, minimum: 1, maximum: 16,
+				description: "The minimum number of digits in the password (1-16)."},
+			{name: "password_minimum_symbol_characters", property: "passwordMinimumSymbolCharacters", kind: profileInt64, minimum: 1, maximum: 16,
+				description: "The minimum number of symbols in the password (1-16)."},
+			{name: "password_minutes_of_inactivity_before_lock", property: "passwordMinutesOfInactivityBeforeLock", kind: profileInt64, minimum: 1, maximum: 1440,
+				description: "Minutes of inactivity before a password is required (1-1440)."},
+			{name: "password_expiration_days", property: "passwordExpirationDays", kind: profileInt64, minimum: 1, maximum: 365,
+				description: "Days until the password expires (1-365)."},
+			{name: "password_previous_password_count_to_block", property: "passwordPreviousPasswordCountToBlock", kind: profileInt64, minimum: 1, maximum: 24,
+				description: "The number of previous passwords that cannot be reused (1-24)."},
+			{name: "min_android_security_patch_level", property: "minAndroidSecurityPatchLevel", kind: profileString,
+				pattern: securityPatchLevelPattern, patternMessage: securityPatchLevelPatternMessage,
+				description: "The oldest security patch level allowed, e.g. 2024-05-01."},
+			{name: "storage_require_encryption", property: "storageRequireEncryption", kind: profileBool, description: "Require encryption of the device storage."},
+			{name: "require_no_pending_system_updates", property: "requireNoPendingSystemUpdates", kind: profileBool, description: "Require that no system update is pending."},
+			{name: "security_require_intune_app_integrity", property: "securityRequireIntuneAppIntegrity", kind: profileBool, description: "Require the Intune app to be the version distributed by Google Play."},
+			{name: "security_require_safety_net_attestation_basic_integrity", property: "securityRequireSafetyNetAttestationBasicIntegrity", kind: profileBool,
+				description: "Require the Play Integrity basic integrity check."},
+			{name: "security_require_safety_net_attestation_certified_device", property: "securityRequireSafetyNetAttestationCertifiedDevice", kind: profileBool,
+				description: "Require the Play Integrity certified device check."},
+		}, complianceOsVersionFields, complianceThreatProtectionFields),
+	},
+	{
+		block:       "android_work_profile",
+		platform:    "androidWorkProfile",
+		odataType:   "#microsoft.graph.androidWorkProfileCompliancePolicy",
+		description: "Settings of an Android Enterprise personally-owned work profile compliance policy.",
+		fields: concatProfileFields([]profileField{
+			{name: "password_required", property: "passwordRequired", kind: profileBool, description: "Require a password to unlock the device."},
+			{name: "password_required_type", property: "passwordRequiredType", kind: profileString,
+				values: []string{
+					"deviceDefault", "alphabetic", "alphanumeric", "alphanumericWithSymbols", "lowSecurityBiometric", "numeric", "numericComplex", "any",
+				},
+				description: "The type of password required."},
+			{name: "required_password_complexity", property: "requiredPasswordComplexity", kind: profileString, values: []string{"none", "low", "medium", "high"},
+				description: "The password complexity required on Android 12 and later."},
+			{name: "password_minimum_length", property: "passwordMinimumLength", kind: profileInt64, minimum: 4, maximum: 16,
+				description: "The minimum password length (4-16)."},
+			{name: "password_minutes_of_inactivity_before_lock", property: "passwordMinutesOfInactivityBeforeLock", kind: profileInt64, minimum: 1, maximum: 1440,
+				description: "Minutes of inactivity before a password is required (1-1440)."},
+			{name: "password_expiration_days", property: "passwordExpirationDays", kind: profileInt64, minimum: 1, maximum: 365,
+				description: "Days until the password expires (1-365)."},
+			{name: "password_previous_password_block_count", property: "passwordPreviousPasswordBlockCount", kind: profileInt64, minimum: 1, maximum: 24,
+				description: "The number of previous passwords that cannot be reused (1-24)."},
+			{name: "password_sign_in_failure_count_before_factory_reset", property: "passwordSignInFailureCountBeforeFactoryReset", kind: profileInt64, minimum: 4, maximum: 11,
+				description: "The number of failed sign-ins before the device is wiped (4-11)."},
+			{name: "min_android_security_patch_level", property: "minAndroidSecurityPatchLevel", kind: profileString,
+				pattern: securityPatchLevelPattern, patternMessage: securityPatchLevelPatternMessage,
+				description: "The oldest security patch level allowed, e.g. 2024-05-01."},
+			{name: "storage_require_encryption", property: "storageRequireEncryption", kind: profileBool, description: "Require encryption of the device storage."},
+			{name: "security_block_jailbroken_devices", property: "securityBlockJailbrokenDevices", kind: profileBool, description: "Mark rooted devices as not compliant."},
+			{name: "security_prevent_install_apps_from_unknown_sources", property: "securityPreventInstallAppsFromUnknownSources", kind: profileBool,
+				description: "Block apps from unknown sources."},
+			{name: "security_disable_usb_debugging", property: "securityDisableUsbDebugging", kind: profileBool, description: "Require USB debugging to be disabled."},
+			{name: "security_require_verify_apps", property: "securityRequireVerifyApps", kind: profileBool, description: "Require Google Play Protect app verification."},
+			{name: "security_require_google_play_services", property: "securityRequireGooglePlayServices", kind: profileBool, description: "Require Google Play services."},
+			{name: "security_require_up_to_date_security_providers", property: "securityRequireUpToDateSecurityProviders", kind: profileBool,
+				description: "Require an up-to-date security provider."},
+			{name: "security_require_company_portal_app_integrity", property: "securityRequireCompanyPortalAppIntegrity", kind: profileBool,
+				description: "Require the Company Portal app to be the version distributed by Google Play."},
+			{name: "security_require_safety_net_attestation_basic_integrity", property: "securityRequireSafetyNetAttestationBasicIntegrity", kind: profileBool,
+				description: "Require the Play Integrity basic integrity check."},
+			{name: "security_require_safety_net_attestation_certified_device", property: "securityRequireSafetyNetAttestationCertifiedDevice", kind: profileBool,
+				description: "Require the Play Integrity certified device check."},
+		}, complianceOsVersionFields, complianceThreatProtectionFields),
+	},
+}
+
+// concatProfileFields returns the fields of several lists in one new list
+func concatProfileFields(lists ...[]profileField) []profileField {
+	var fields []profileField
+	for _, list := range lists {
+		fields = append(fields, list...)
+	}
+	return fields
+}
+
+// findCompliancePlatform returns the platform of an OData type, or nil for Windows and unsupported types
+func findCompliancePlatform(odataType string) *compliancePlatform {
+	for i := range compliancePlatforms {
+		if compliancePlatforms[i].odataType == odataType {
+			return &compliancePlatforms[i]
+		}
+	}
+	return nil
+}
+
+// compliancePlatformValues are the values of the platform attribute
+func compliancePlatformValues() []string {
+	values := []string{windowsCompliancePlatform}
+	for _, p := range compliancePlatforms {
+		values = append(values, p.platform)
+	}
+	return values
+}
+
+// schema returns the block of the platform
+func (p *compliancePlatform) schema() schema.ListNestedBlock {
+	attributes, blocks := profileFieldSchemas(p.fields)
+	return schema.ListNestedBlock{
+		Description: p.description + " Conflicts with the Windows attributes and the other platform blocks.",
+		Validators: []validator.List{
+			listvalidator.SizeAtMost(1),
+		},
+		NestedObject: schema.NestedBlockObject{
+			Attributes: attributes,
+			Blocks:     blocks,
+		},
+	}
+}
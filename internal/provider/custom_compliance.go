@@ -0,0 +1,270 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// CustomComplianceModel represents a custom_compliance block
+type CustomComplianceModel struct {
+	ScriptID  types.String `tfsdk:"script_id"`
+	RulesJSON types.String `tfsdk:"rules_json"`
+}
+
+// customComplianceAttrTypes are the attribute types of a custom_compliance block
+var customComplianceAttrTypes = map[string]attr.Type{
+	"script_id":  types.StringType,
+	"rules_json": types.StringType,
+}
+
+// Values accepted in custom compliance rules
+var (
+	complianceRuleDataTypes = []string{"Boolean", "Int64", "Double", "String", "DateTime", "Version"}
+	complianceRuleOperators = []string{"IsEquals", "NotEquals", "GreaterThan", "GreaterEquals", "LessThan", "LessEquals"}
+
+	// complianceRuleOrderedTypes are the data types the comparison operators apply to
+	complianceRuleOrderedTypes = []string{"Int64", "Double", "DateTime", "Version"}
+
+	complianceRuleVersionPattern = regexp.MustCompile(`^\d+(\.\d+){1,3}$`)
+)
+
+// complianceRules is the JSON document of custom compliance rules
+type complianceRules struct {
+	Rules []complianceRule `json:"Rules"`
+}
+
+// complianceRule is a rule evaluated against a setting returned by the discovery script
+type complianceRule struct {
+	SettingName        string                          `json:"SettingName"`
+	Operator           string                          `json:"Operator"`
+	DataType           string                          `json:"DataType"`
+	Operand            interface{}                     `json:"Operand"`
+	MoreInfoUrl        string                          `json:"MoreInfoUrl"`
+	RemediationStrings []complianceRuleRemediationText `json:"RemediationStrings"`
+}
+
+// complianceRuleRemediationText is the message shown to users for a rule, in one language
+type complianceRuleRemediationText struct {
+	Language    string `json:"Language"`
+	Title       string `json:"Title"`
+	Description string `json:"Description"`
+}
+
+// customComplianceBlockSchema returns the schema for the custom_compliance block of compliance policies
+func customComplianceBlockSchema() schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		Description: "Custom compliance settings evaluated by a discovery script. Windows only.",
+		Validators: []validator.List{
+			listvalidator.SizeAtMost(1),
+		},
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"script_id": schema.StringAttribute{
+					Description: "The ID of the intune_device_compliance_script that discovers the settings.",
+					Required:    true,
+				},
+				"rules_json": schema.StringAttribute{
+					Description: "The JSON document of rules the discovered settings are checked against: an object whose " +
+						"Rules list has a SettingName, Operator, DataType, Operand, MoreInfoUrl and RemediationStrings per rule.",
+					Required: true,
+					Validators: []validator.String{
+						complianceRulesValidator{},
+					},
+				},
+			},
+		},
+	}
+}
+
+// complianceRulesValidator checks custom compliance rules against the rules schema
+type complianceRulesValidator struct{}
+
+// Description describes the validation
+func (v complianceRulesValidator) Description(ctx context.Context) string {
+	return "value must be a valid custom compliance rules document"
+}
+
+// MarkdownDescription describes the validation in Markdown
+func (v complianceRulesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString validates the rules document
+func (v complianceRulesValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	for _, err := range validateComplianceRules(req.ConfigValue.ValueString()) {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Custom Compliance Rules", err)
+	}
+}
+
+// validateComplianceRules returns the problems of a custom compliance rules document
+func validateComplianceRules(rulesJSON string) []string {
+	decoder := json.NewDecoder(strings.NewReader(rulesJSON))
+	decoder.DisallowUnknownFields()
+	decoder.UseNumber()
+
+	var rules complianceRules
+	if err := decoder.Decode(&rules); err != nil {
+		return []string{fmt.Sprintf("The rules are not a valid rules document: %s", err)}
+	}
+	if len(rules.Rules) == 0 {
+		return []string{"The rules document must contain at least one rule in Rules."}
+	}
+
+	var problems []string
+	for i, rule := range rules.Rules {
+		for _, err := range validateComplianceRule(rule) {
+			problems = append(problems, fmt.Sprintf("Rule %d (%s): %s", i+1, rule.SettingName, err))
+		}
+	}
+	return problems
+}
+
+// validateComplianceRule returns the problems of a single rule
+func validateComplianceRule(rule complianceRule) []string {
+	var problems []string
+	if rule.SettingName == "" {
+		problems = append(problems, "SettingName is required.")
+	}
+	if !containsString(complianceRuleDataTypes, rule.DataType) {
+		problems = append(problems, fmt.Sprintf("DataType %q is not one of %s.", rule.DataType, strings.Join(complianceRuleDataTypes, ", ")))
+	}
+	switch {
+	case !containsString(complianceRuleOperators, rule.Operator):
+		problems = append(problems, fmt.Sprintf("Operator %q is not one of %s.", rule.Operator, strings.Join(complianceRuleOperators, ", ")))
+	case rule.Operator != "IsEquals" && rule.Operator != "NotEquals" && containsString(complianceRuleDataTypes, rule.DataType) && !containsString(complianceRuleOrderedTypes, rule.DataType):
+		problems = append(problems, fmt.Sprintf("Operator %s cannot be used with DataType %s.", rule.Operator, rule.DataType))
+	}
+	if rule.Operand == nil {
+		problems = append(problems, "Operand is required.")
+	} else if err := checkComplianceRuleOperand(rule.DataType, rule.Operand); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if u, err := url.Parse(rule.MoreInfoUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, fmt.Sprintf("MoreInfoUrl %q is not an http or https URL.", rule.MoreInfoUrl))
+	}
+	if len(rule.RemediationStrings) == 0 {
+		problems = append(problems, "RemediationStrings must contain at least one entry.")
+	}
+	for _, text := range rule.RemediationStrings {
+		if text.Language == "" || text.Title == "" || text.Description == "" {
+			problems = append(problems, "Every entry of RemediationStrings needs a Language, Title and Description.")
+			break
+		}
+	}
+	return problems
+}
+
+// checkComplianceRuleOperand checks that an operand matches the data type of its rule. Operands
+// may be given as JSON strings or as JSON values of the data type.
+func checkComplianceRuleOperand(dataType string, operand interface{}) error {
+	value := fmt.Sprint(operand)
+	var err error
+	switch dataType {
+	case "Boolean":
+		_, err = strconv.ParseBool(value)
+	case "Int64":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "Double":
+		_, err = strconv.ParseFloat(value, 64)
+	case "DateTime":
+		_, err = time.Parse(time.RFC3339, value)
+	case "Version":
+		if !complianceRuleVersionPattern.MatchString(value) {
+			err = fmt.Errorf("not a version number")
+		}
+	case "String":
+		if _, ok := operand.(string); !ok {
+			err = fmt.Errorf("not a string")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("Operand %v is not a valid %s value.", operand, dataType)
+	}
+	return nil
+}
+
+// buildCustomCompliance converts a custom_compliance block into the script of a compliance
+// policy, or returns nil without a block. Graph takes the rules base64 encoded.
+func buildCustomCompliance(ctx context.Context, list types.List, diags *diag.Diagnostics) *clients.DeviceCompliancePolicyScript {
+	var blocks []CustomComplianceModel
+	diags.Append(list.ElementsAs(ctx, &blocks, false)...)
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	return &clients.DeviceCompliancePolicyScript{
+		DeviceComplianceScriptId: blocks[0].ScriptID.ValueString(),
+		RulesContent:             base64.StdEncoding.EncodeToString([]byte(blocks[0].RulesJSON.ValueString())),
+	}
+}
+
+// customComplianceValue converts the script of a compliance policy into a custom_compliance
+// block. Rules that are semantically equal to the prior rules keep their prior formatting.
+func customComplianceValue(ctx context.Context, prior types.List, script *clients.DeviceCompliancePolicyScript, diags *diag.Diagnostics) types.List {
+	objectType := types.ObjectType{AttrTypes: customComplianceAttrTypes}
+	if script == nil {
+		if len(prior.Elements()) == 0 {
+			return prior
+		}
+		return types.ListValueMust(objectType, []attr.Value{})
+	}
+
+	rules := script.RulesContent
+	if decoded, err := base64.StdEncoding.DecodeString(rules); err == nil {
+		rules = string(decoded)
+	}
+
+	var priorBlocks []CustomComplianceModel
+	if len(prior.Elements()) > 0 {
+		diags.Append(prior.ElementsAs(ctx, &priorBlocks, false)...)
+	}
+	if len(priorBlocks) > 0 && sameJSON(priorBlocks[0].RulesJSON.ValueString(), rules) {
+		rules = priorBlocks[0].RulesJSON.ValueString()
+	}
+
+	value, d := types.ListValueFrom(ctx, objectType, []CustomComplianceModel{{
+		ScriptID:  types.StringValue(script.DeviceComplianceScriptId),
+		RulesJSON: types.StringValue(rules),
+	}})
+	diags.Append(d...)
+	return value
+}
+
+// sameJSON reports whether two JSON documents have the same value
+func sameJSON(a, b string) bool {
+	var va, vb interface{}
+	decode := func(s string, v *interface{}) error {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(s)))
+		decoder.UseNumber()
+		return decoder.Decode(v)
+	}
+	if decode(a, &va) != nil || decode(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -101,9 +101,11 @@ resource "intune_settings_catalog_policy" "surface_policy" {
   technologies = "mdm"
 
   assignment {
-    all_devices = true
-    filter_id   = local.surface_filter.id
-    filter_type = "include"
+    target {
+      type        = "all_devices"
+      filter_id   = local.surface_filter.id
+      filter_type = "include"
+    }
   }
 }
 ` + "```" + `
@@ -0,0 +1,191 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ datasource.DataSource = &DriverInventoryDataSource{}
+
+// NewDriverInventoryDataSource returns a new driver inventory data source
+func NewDriverInventoryDataSource() datasource.DataSource {
+	return &DriverInventoryDataSource{}
+}
+
+// DriverInventoryDataSource defines the data source implementation
+type DriverInventoryDataSource struct {
+	client *clients.GraphClient
+}
+
+// DriverDataModel describes a single driver of a driver inventory
+type DriverDataModel struct {
+	ID                    types.String `tfsdk:"id"`
+	Name                  types.String `tfsdk:"name"`
+	Version               types.String `tfsdk:"version"`
+	Manufacturer          types.String `tfsdk:"manufacturer"`
+	DriverClass           types.String `tfsdk:"driver_class"`
+	ReleaseDateTime       types.String `tfsdk:"release_date_time"`
+	ApplicableDeviceCount types.Int64  `tfsdk:"applicable_device_count"`
+	ApprovalStatus        types.String `tfsdk:"approval_status"`
+	Category              types.String `tfsdk:"category"`
+	DeployDateTime        types.String `tfsdk:"deploy_date_time"`
+}
+
+// DriverInventoryDataSourceModel describes the data source data model
+type DriverInventoryDataSourceModel struct {
+	ProfileID types.String      `tfsdk:"profile_id"`
+	Drivers   []DriverDataModel `tfsdk:"drivers"`
+}
+
+// Metadata returns the data source type name
+func (d *DriverInventoryDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_driver_inventory"
+}
+
+// Schema defines the schema for the data source
+func (d *DriverInventoryDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Retrieves the drivers applicable to the devices of a Windows driver update profile.",
+		MarkdownDescription: `
+Retrieves the drivers applicable to the devices of a Windows driver update profile.
+
+Intune builds the inventory from the devices assigned to the profile, so a new profile has no
+drivers until its devices have reported. Drivers can be approved with
+` + "`intune_windows_driver_update_approval`" + `.
+
+## Example Usage
+
+` + "```hcl" + `
+data "intune_driver_inventory" "pilot" {
+  profile_id = intune_windows_driver_update_profile.pilot.id
+}
+
+output "drivers_to_review" {
+  value = [
+    for driver in data.intune_driver_inventory.pilot.drivers : "${driver.manufacturer} ${driver.name} ${driver.version}"
+    if driver.approval_status == "needsReview"
+  ]
+}
+` + "```" + `
+`,
+		Attributes: map[string]schema.Attribute{
+			"profile_id": schema.StringAttribute{
+				Description: "The ID of the driver update profile.",
+				Required:    true,
+			},
+			"drivers": schema.ListNestedAttribute{
+				Description: "The drivers applicable to the devices of the profile.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Description: "The unique identifier of the driver.",
+							Computed:    true,
+						},
+						"name": schema.StringAttribute{
+							Description: "The name of the driver.",
+							Computed:    true,
+						},
+						"version": schema.StringAttribute{
+							Description: "The version of the driver.",
+							Computed:    true,
+						},
+						"manufacturer": schema.StringAttribute{
+							Description: "The manufacturer of the driver.",
+							Computed:    true,
+						},
+						"driver_class": schema.StringAttribute{
+							Description: "The class of the driver, e.g. Firmware or Display.",
+							Computed:    true,
+						},
+						"release_date_time": schema.StringAttribute{
+							Description: "The date and time the driver was released.",
+							Computed:    true,
+						},
+						"applicable_device_count": schema.Int64Attribute{
+							Description: "The number of devices of the profile the driver applies to.",
+							Computed:    true,
+						},
+						"approval_status": schema.StringAttribute{
+							Description: "The approval status of the driver: needsReview, declined, approved or suspended.",
+							Computed:    true,
+						},
+						"category": schema.StringAttribute{
+							Description: "The category of the driver: recommended, previouslyApproved or other.",
+							Computed:    true,
+						},
+						"deploy_date_time": schema.StringAttribute{
+							Description: "The date and time an approved driver is deployed from.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source
+func (d *DriverInventoryDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = providerData.GraphClient
+}
+
+// Read refreshes the Terraform state with the latest data
+func (d *DriverInventoryDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data DriverInventoryDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	drivers, err := d.client.ListWindowsDriverUpdateInventories(ctx, data.ProfileID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Driver Inventory",
+			fmt.Sprintf("Could not read drivers of profile ID %s: %s", data.ProfileID.ValueString(), err),
+		)
+		return
+	}
+
+	data.Drivers = make([]DriverDataModel, 0, len(drivers))
+	for _, driver := range drivers {
+		data.Drivers = append(data.Drivers, DriverDataModel{
+			ID:                    types.StringValue(driver.ID),
+			Name:                  types.StringValue(driver.Name),
+			Version:               types.StringValue(driver.Version),
+			Manufacturer:          types.StringValue(driver.Manufacturer),
+			DriverClass:           types.StringValue(driver.DriverClass),
+			ReleaseDateTime:       optionalStringValue(types.StringNull(), driver.ReleaseDateTime),
+			ApplicableDeviceCount: types.Int64Value(int64(driver.ApplicableDeviceCount)),
+			ApprovalStatus:        types.StringValue(driver.ApprovalStatus),
+			Category:              types.StringValue(driver.Category),
+			DeployDateTime:        optionalStringValue(types.StringNull(), driver.DeployDateTime),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,330 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ datasource.DataSource = &EndpointSecurityTemplateDataSource{}
+
+// endpointSecurityTemplateSubtypes maps the template types used by the provider to the
+// templateSubtype values of Graph, which misspells endpoint detection and response
+var endpointSecurityTemplateSubtypes = map[string]string{
+	"antivirus":                    "antivirus",
+	"diskEncryption":               "diskEncryption",
+	"firewall":                     "firewall",
+	"endpointDetectionAndResponse": "endpointDetectionReponse",
+	"attackSurfaceReduction":       "attackSurfaceReduction",
+	"accountProtection":            "accountProtection",
+}
+
+// securityTemplateType is the Graph templateType of endpoint security templates, as
+// opposed to security baselines
+const securityTemplateType = "securityTemplate"
+
+// NewEndpointSecurityTemplateDataSource creates a new data source instance
+func NewEndpointSecurityTemplateDataSource() datasource.DataSource {
+	return &EndpointSecurityTemplateDataSource{}
+}
+
+// EndpointSecurityTemplateDataSource defines the data source implementation
+type EndpointSecurityTemplateDataSource struct {
+	client *clients.GraphClient
+}
+
+// EndpointSecurityTemplateDataModel describes a single endpoint security template
+type EndpointSecurityTemplateDataModel struct {
+	ID                types.String `tfsdk:"id"`
+	DisplayName       types.String `tfsdk:"display_name"`
+	Description       types.String `tfsdk:"description"`
+	Subtype           types.String `tfsdk:"subtype"`
+	Platform          types.String `tfsdk:"platform"`
+	Version           types.String `tfsdk:"version"`
+	IsDeprecated      types.Bool   `tfsdk:"is_deprecated"`
+	PublishedDateTime types.String `tfsdk:"published_date_time"`
+}
+
+// EndpointSecurityTemplateDataSourceModel describes the data source data model
+type EndpointSecurityTemplateDataSourceModel struct {
+	ID                types.String                        `tfsdk:"id"`
+	Subtype           types.String                        `tfsdk:"subtype"`
+	Platform          types.String                        `tfsdk:"platform"`
+	Version           types.String                        `tfsdk:"version"`
+	LatestOnly        types.Bool                          `tfsdk:"latest_only"`
+	IncludeDeprecated types.Bool                          `tfsdk:"include_deprecated"`
+	Templates         []EndpointSecurityTemplateDataModel `tfsdk:"templates"`
+}
+
+// Metadata returns the data source type name
+func (d *EndpointSecurityTemplateDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_endpoint_security_template"
+}
+
+// Schema defines the schema for the data source
+func (d *EndpointSecurityTemplateDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	subtypes := make([]string, 0, len(endpointSecurityTemplateSubtypes))
+	for subtype := range endpointSecurityTemplateSubtypes {
+		subtypes = append(subtypes, subtype)
+	}
+	sort.Strings(subtypes)
+
+	resp.Schema = schema.Schema{
+		Description: "Looks up the templates endpoint security policies are created from.",
+		MarkdownDescription: `
+Looks up the templates endpoint security policies are created from.
+
+Templates are listed newest first, and ` + "`id`" + ` is the ID of the newest matching template. Deprecated
+templates are left out unless ` + "`include_deprecated`" + ` is set.
+
+## Example Usage
+
+` + "```hcl" + `
+data "intune_endpoint_security_template" "firewall" {
+  subtype  = "firewall"
+  platform = "windows10AndLater"
+}
+
+resource "intune_endpoint_security_policy" "firewall" {
+  display_name  = "Corporate Firewall Settings"
+  template_id   = data.intune_endpoint_security_template.firewall.id
+  settings_json = jsonencode({})
+}
+` + "```" + `
+`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the newest matching template.",
+				Computed:    true,
+			},
+			"subtype": schema.StringAttribute{
+				Description: "Only return templates of this type. Valid values: antivirus, diskEncryption, firewall, " +
+					"endpointDetectionAndResponse, attackSurfaceReduction, accountProtection.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(subtypes...),
+				},
+			},
+			"platform": schema.StringAttribute{
+				Description: "Only return templates for this platform, e.g. windows10AndLater or macOS.",
+				Optional:    true,
+			},
+			"version": schema.StringAttribute{
+				Description: "Only return templates with this version.",
+				Optional:    true,
+			},
+			"latest_only": schema.BoolAttribute{
+				Description: "Only return the newest template of each type and platform. Defaults to false.",
+				Optional:    true,
+			},
+			"include_deprecated": schema.BoolAttribute{
+				Description: "Also return deprecated templates. Defaults to false.",
+				Optional:    true,
+			},
+			"templates": schema.ListNestedAttribute{
+				Description: "The matching templates, newest first.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Description: "The unique identifier for the template.",
+							Computed:    true,
+						},
+						"display_name": schema.StringAttribute{
+							Description: "The display name of the template.",
+							Computed:    true,
+						},
+						"description": schema.StringAttribute{
+							Description: "The description of the template.",
+							Computed:    true,
+						},
+						"subtype": schema.StringAttribute{
+							Description: "The template type, as used by template_type of intune_endpoint_security_policy.",
+							Computed:    true,
+						},
+						"platform": schema.StringAttribute{
+							Description: "The platform the template applies to.",
+							Computed:    true,
+						},
+						"version": schema.StringAttribute{
+							Description: "The template version.",
+							Computed:    true,
+						},
+						"is_deprecated": schema.BoolAttribute{
+							Description: "Whether the template is deprecated.",
+							Computed:    true,
+						},
+						"published_date_time": schema.StringAttribute{
+							Description: "The date and time the template was published.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source
+func (d *EndpointSecurityTemplateDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = providerData.GraphClient
+}
+
+// Read reads the data source
+func (d *EndpointSecurityTemplateDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data EndpointSecurityTemplateDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	query := endpointSecurityTemplateQuery{
+		Subtype:           data.Subtype.ValueString(),
+		Platform:          data.Platform.ValueString(),
+		Version:           data.Version.ValueString(),
+		LatestOnly:        data.LatestOnly.ValueBool(),
+		IncludeDeprecated: data.IncludeDeprecated.ValueBool(),
+	}
+
+	tflog.Debug(ctx, "Reading endpoint security templates", map[string]interface{}{
+		"subtype":  query.Subtype,
+		"platform": query.Platform,
+		"version":  query.Version,
+	})
+
+	templates, err := d.client.ListEndpointSecurityTemplates(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Endpoint Security Templates",
+			fmt.Sprintf("Could not list templates: %s", err),
+		)
+		return
+	}
+
+	matches := findEndpointSecurityTemplates(templates, query)
+	if len(matches) == 0 {
+		resp.Diagnostics.AddError(
+			"Endpoint Security Template Not Found",
+			fmt.Sprintf("No endpoint security template matches %s.", query),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(matches[0].ID)
+	data.Templates = make([]EndpointSecurityTemplateDataModel, 0, len(matches))
+	for _, template := range matches {
+		data.Templates = append(data.Templates, EndpointSecurityTemplateDataModel{
+			ID:                types.StringValue(template.ID),
+			DisplayName:       types.StringValue(template.DisplayName),
+			Description:       types.StringValue(template.Description),
+			Subtype:           types.StringValue(endpointSecurityTemplateType(template.TemplateSubtype)),
+			Platform:          types.StringValue(template.PlatformType),
+			Version:           types.StringValue(template.VersionInfo),
+			IsDeprecated:      types.BoolValue(template.IsDeprecated),
+			PublishedDateTime: types.StringValue(template.PublishedDateTime),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// endpointSecurityTemplateQuery selects endpoint security templates. Empty fields match any template.
+type endpointSecurityTemplateQuery struct {
+	// Subtype is a template type as used by template_type, e.g. endpointDetectionAndResponse
+	Subtype           string
+	Platform          string
+	Version           string
+	LatestOnly        bool
+	IncludeDeprecated bool
+}
+
+// String describes the query for error messages
+func (q endpointSecurityTemplateQuery) String() string {
+	s := "type " + orAny(q.Subtype) + ", platform " + orAny(q.Platform) + " and version " + orAny(q.Version)
+	if !q.IncludeDeprecated {
+		s += " that is not deprecated"
+	}
+	return s
+}
+
+// orAny quotes a query value, or returns "any" for an empty one
+func orAny(value string) string {
+	if value == "" {
+		return "any"
+	}
+	return fmt.Sprintf("%q", value)
+}
+
+// endpointSecurityTemplateType returns the template type used by the provider for a Graph templateSubtype
+func endpointSecurityTemplateType(subtype string) string {
+	for templateType, graphSubtype := range endpointSecurityTemplateSubtypes {
+		if graphSubtype == subtype {
+			return templateType
+		}
+	}
+	return subtype
+}
+
+// findEndpointSecurityTemplates returns the endpoint security templates matching query, newest first
+func findEndpointSecurityTemplates(templates []clients.EndpointSecurityTemplate, query endpointSecurityTemplateQuery) []clients.EndpointSecurityTemplate {
+	var matches []clients.EndpointSecurityTemplate
+	for _, template := range templates {
+		if template.TemplateType != securityTemplateType ||
+			(query.Subtype != "" && template.TemplateSubtype != endpointSecurityTemplateSubtypes[query.Subtype]) ||
+			(query.Platform != "" && template.PlatformType != query.Platform) ||
+			(query.Version != "" && template.VersionInfo != query.Version) ||
+			(template.IsDeprecated && !query.IncludeDeprecated) {
+			continue
+		}
+		matches = append(matches, template)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].PublishedDateTime != matches[j].PublishedDateTime {
+			return matches[i].PublishedDateTime > matches[j].PublishedDateTime
+		}
+		return matches[i].VersionInfo > matches[j].VersionInfo
+	})
+
+	if !query.LatestOnly {
+		return matches
+	}
+
+	latest := matches[:0]
+	seen := make(map[string]bool)
+	for _, template := range matches {
+		key := template.TemplateSubtype + "/" + template.PlatformType
+		if !seen[key] {
+			seen[key] = true
+			latest = append(latest, template)
+		}
+	}
+	return latest
+}
@@ -34,15 +34,16 @@ type PolicyDataSource struct {
 
 // PolicyDataSourceModel describes the data source data model
 type PolicyDataSourceModel struct {
-	ID                   types.String `tfsdk:"id"`
-	DisplayName          types.String `tfsdk:"display_name"`
-	Description          types.String `tfsdk:"description"`
-	PolicyType           types.String `tfsdk:"policy_type"`
-	Platforms            types.String `tfsdk:"platforms"`
-	Technologies         types.String `tfsdk:"technologies"`
-	CreatedDateTime      types.String `tfsdk:"created_date_time"`
-	LastModifiedDateTime types.String `tfsdk:"last_modified_date_time"`
-	RoleScopeTagIds      types.List   `tfsdk:"role_scope_tag_ids"`
+	ID                   types.String            `tfsdk:"id"`
+	DisplayName          types.String            `tfsdk:"display_name"`
+	Description          types.String            `tfsdk:"description"`
+	PolicyType           types.String            `tfsdk:"policy_type"`
+	Platforms            types.String            `tfsdk:"platforms"`
+	Technologies         types.String            `tfsdk:"technologies"`
+	CreatedDateTime      types.String            `tfsdk:"created_date_time"`
+	LastModifiedDateTime types.String            `tfsdk:"last_modified_date_time"`
+	RoleScopeTagIds      types.List              `tfsdk:"role_scope_tag_ids"`
+	AssignmentTargets    []AssignmentTargetModel `tfsdk:"assignment_targets"`
 }
 
 // Metadata returns the data source type name
@@ -74,7 +75,10 @@ data "intune_policy" "existing" {
 resource "intune_policy_assignment" "assign" {
   policy_id   = data.intune_policy.existing.id
   policy_type = "settings_catalog"
-  all_devices = true
+
+  target {
+    type = "all_devices"
+  }
 }
 ` + "```" + `
 
@@ -104,7 +108,7 @@ data "intune_policy" "by_id" {
 				Computed:    true,
 			},
 			"policy_type": schema.StringAttribute{
-				Description: "The type of policy to search for. Valid values: settings_catalog, compliance, endpoint_security, device_configuration.",
+				Description: "The type of policy to search for. Valid values: settings_catalog, compliance, endpoint_security, device_configuration, linux_compliance, feature_update, quality_update, driver_update, autopilot, enrollment_status_page.",
 				Required:    true,
 				Validators: []validator.String{
 					stringvalidator.OneOf(
@@ -112,6 +116,12 @@ data "intune_policy" "by_id" {
 						PolicyTypeCompliance,
 						PolicyTypeEndpointSecurity,
 						PolicyTypeDeviceConfig,
+						PolicyTypeLinuxCompliance,
+						PolicyTypeFeatureUpdate,
+						PolicyTypeQualityUpdate,
+						PolicyTypeDriverUpdate,
+						PolicyTypeAutopilot,
+						PolicyTypeEnrollmentStatusPage,
 					),
 				},
 			},
@@ -136,6 +146,30 @@ data "intune_policy" "by_id" {
 				Computed:    true,
 				ElementType: types.StringType,
 			},
+			"assignment_targets": schema.ListNestedAttribute{
+				Description: "The groups, exclusion groups, all devices and all users targets the policy is assigned to.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Description: "The target type: group, exclusion, all_devices or all_users.",
+							Computed:    true,
+						},
+						"group_id": schema.StringAttribute{
+							Description: "The ID of the Azure AD group of group and exclusion targets.",
+							Computed:    true,
+						},
+						"filter_id": schema.StringAttribute{
+							Description: "The ID of the assignment filter of the target.",
+							Computed:    true,
+						},
+						"filter_type": schema.StringAttribute{
+							Description: "The assignment filter type of the target: include or exclude.",
+							Computed:    true,
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -196,6 +230,18 @@ func (d *PolicyDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		basePath = "/deviceManagement/intents"
 	case PolicyTypeDeviceConfig:
 		basePath = "/deviceManagement/deviceConfigurations"
+	case PolicyTypeLinuxCompliance:
+		basePath = "/deviceManagement/compliancePolicies"
+	case PolicyTypeFeatureUpdate:
+		basePath = "/deviceManagement/windowsFeatureUpdateProfiles"
+	case PolicyTypeQualityUpdate:
+		basePath = "/deviceManagement/windowsQualityUpdateProfiles"
+	case PolicyTypeDriverUpdate:
+		basePath = "/deviceManagement/windowsDriverUpdateProfiles"
+	case PolicyTypeAutopilot:
+		basePath = "/deviceManagement/windowsAutopilotDeploymentProfiles"
+	case PolicyTypeEnrollmentStatusPage:
+		basePath = "/deviceManagement/deviceEnrollmentConfigurations"
 	default:
 		resp.Diagnostics.AddError(
 			"Invalid Policy Type",
@@ -204,39 +250,7 @@ func (d *PolicyDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		return
 	}
 
-	var policyData map[string]interface{}
-
-	if id != "" {
-		// Get policy by ID
-		path := basePath
-		if policyType == PolicyTypeSettingsCatalog {
-			path = fmt.Sprintf("%s('%s')", basePath, id)
-		} else {
-			path = fmt.Sprintf("%s/%s", basePath, id)
-		}
-
-		response, err := d.client.Get(ctx, path)
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error Reading Policy",
-				fmt.Sprintf("Could not read policy: %s", err),
-			)
-			return
-		}
-
-		respBytes, _ := json.Marshal(response)
-		if err := json.Unmarshal(respBytes, &policyData); err != nil {
-			resp.Diagnostics.AddError(
-				"Error Parsing Response",
-				fmt.Sprintf("Could not parse policy response: %s", err),
-			)
-			return
-		}
-
-		if policyData["id"] == nil || policyData["id"] == "" {
-			policyData["id"] = response.ID
-		}
-	} else {
+	if id == "" {
 		// Search by display name
 		items, err := d.client.ListAll(ctx, basePath)
 		if err != nil {
@@ -262,12 +276,12 @@ func (d *PolicyDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 			}
 
 			if strings.EqualFold(name, displayName) {
-				policyData = policy
+				id, _ = policy["id"].(string)
 				break
 			}
 		}
 
-		if policyData == nil {
+		if id == "" {
 			resp.Diagnostics.AddError(
 				"Policy Not Found",
 				fmt.Sprintf("No policy found with display name '%s'", displayName),
@@ -276,6 +290,34 @@ func (d *PolicyDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		}
 	}
 
+	// Read the policy and its assignments in a single $batch round trip
+	path := fmt.Sprintf("%s/%s", basePath, id)
+	if policyType == PolicyTypeSettingsCatalog || policyType == PolicyTypeLinuxCompliance {
+		path = fmt.Sprintf("%s('%s')", basePath, id)
+	}
+
+	result, err := readPolicyWithAssignments[map[string]interface{}](ctx, d.client, policyType, path, id, true)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Policy",
+			fmt.Sprintf("Could not read policy: %s", err),
+		)
+		return
+	}
+	if result.AssignmentsErr != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Policy Assignments",
+			fmt.Sprintf("Could not read policy assignments: %s", result.AssignmentsErr),
+		)
+		return
+	}
+	policyData := *result.Policy
+
+	data.AssignmentTargets = []AssignmentTargetModel{}
+	for _, assignment := range result.Assignments {
+		data.AssignmentTargets = append(data.AssignmentTargets, assignment.Targets...)
+	}
+
 	// Update the model from the response
 	if id, ok := policyData["id"].(string); ok {
 		data.ID = types.StringValue(id)
@@ -0,0 +1,146 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAccScopeTagsDataSource(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.apply("intune_scope_tag", nil, `{"display_name": "Helpdesk"}`)
+
+	tags := env.readDataSource("intune_scope_tags", `{}`)["scope_tags"].([]interface{})
+	if len(tags) != 2 {
+		t.Fatalf("expected the Default and Helpdesk scope tags, got %v", tags)
+	}
+	assertAttr(t, tags[0].(map[string]interface{}), "is_built_in", true)
+}
+
+func TestAccAssignmentFiltersDataSource(t *testing.T) {
+	env := newTestEnv(t)
+	env.graph.PageSize = 2
+
+	for i := 0; i < 3; i++ {
+		env.apply("intune_assignment_filter", nil, fmt.Sprintf(`{
+			"display_name": "Windows %d",
+			"platform": "windows10AndLater",
+			"rule": "(device.osVersion -startsWith \"10.0\")"
+		}`, i))
+	}
+	env.apply("intune_assignment_filter", nil, `{
+		"display_name": "macOS",
+		"platform": "macOS",
+		"rule": "(device.osVersion -startsWith \"14\")"
+	}`)
+
+	all := env.readDataSource("intune_assignment_filters", `{}`)["filters"].([]interface{})
+	if len(all) != 4 {
+		t.Errorf("expected 4 filters across pages, got %d", len(all))
+	}
+
+	windows := env.readDataSource("intune_assignment_filters", `{"platform": "windows10AndLater"}`)["filters"].([]interface{})
+	if len(windows) != 3 {
+		t.Errorf("expected 3 Windows filters, got %d", len(windows))
+	}
+}
+
+func TestAccPolicyDataSource(t *testing.T) {
+	env := newTestEnv(t)
+
+	policy := env.apply("intune_settings_catalog_policy", nil, `{
+		"name": "Defender baseline",
+		"platforms": "windows10",
+		"technologies": "mdm",
+		"assignment": [{"target": [{"type": "group", "group_id": "00000000-0000-0000-0000-000000000001", "filter_id": "f1", "filter_type": "include"}]}]
+	}`)
+
+	data := env.readDataSource("intune_policy", fmt.Sprintf(`{"id": %q, "policy_type": "settings_catalog"}`, policy.id()))
+	assertAttr(t, data, "display_name", "Defender baseline")
+	assertAttr(t, data, "platforms", "windows10")
+
+	targets := data["assignment_targets"].([]interface{})
+	if len(targets) != 1 {
+		t.Fatalf("expected 1 assignment target, got %v", targets)
+	}
+	target := targets[0].(map[string]interface{})
+	assertAttr(t, target, "type", "group")
+	assertAttr(t, target, "group_id", "00000000-0000-0000-0000-000000000001")
+	assertAttr(t, target, "filter_type", "include")
+
+	// A lookup by display name lists the policies once and reads the match with its assignments
+	// in a single batch
+	before := len(env.graph.Requests())
+	data = env.readDataSource("intune_policy", `{"display_name": "defender BASELINE", "policy_type": "settings_catalog"}`)
+	assertAttr(t, data, "id", policy.id())
+	if n := len(data["assignment_targets"].([]interface{})); n != 1 {
+		t.Errorf("expected 1 assignment target, got %d", n)
+	}
+	var requests []string
+	for _, request := range env.graph.Requests()[before:] {
+		requests = append(requests, strings.SplitN(request, "?", 2)[0])
+	}
+	if want := "[GET /beta/deviceManagement/configurationPolicies POST /beta/$batch]"; fmt.Sprint(requests) != want {
+		t.Errorf("expected requests %s, got %v", want, requests)
+	}
+}
+
+func TestAccEndpointSecurityTemplateDataSource(t *testing.T) {
+	env := newTestEnv(t)
+	addEndpointSecurityTemplates(env)
+
+	ids := func(data map[string]interface{}) []string {
+		var ids []string
+		for _, template := range data["templates"].([]interface{}) {
+			ids = append(ids, template.(map[string]interface{})["id"].(string))
+		}
+		return ids
+	}
+
+	for name, tc := range map[string]struct {
+		config string
+		want   string
+	}{
+		"all":                {`{}`, "[fw-mac fw-2 edr-1 fw-1]"},
+		"subtype":            {`{"subtype": "firewall"}`, "[fw-mac fw-2 fw-1]"},
+		"platform":           {`{"subtype": "firewall", "platform": "windows10AndLater"}`, "[fw-2 fw-1]"},
+		"version":            {`{"subtype": "firewall", "version": "1"}`, "[fw-mac fw-1]"},
+		"latest only":        {`{"latest_only": true}`, "[fw-mac fw-2 edr-1]"},
+		"include deprecated": {`{"subtype": "firewall", "platform": "windows10AndLater", "include_deprecated": true}`, "[fw-3 fw-2 fw-1]"},
+		"graph subtype":      {`{"subtype": "endpointDetectionAndResponse"}`, "[edr-1]"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := env.readDataSource("intune_endpoint_security_template", tc.config)
+			if got := fmt.Sprint(ids(data)); got != tc.want {
+				t.Errorf("expected templates %s, got %s", tc.want, got)
+			}
+		})
+	}
+
+	data := env.readDataSource("intune_endpoint_security_template", `{"subtype": "endpointDetectionAndResponse"}`)
+	assertAttr(t, data, "id", "edr-1")
+	assertAttr(t, data["templates"].([]interface{})[0].(map[string]interface{}), "subtype", "endpointDetectionAndResponse")
+}
+
+func TestAccDriverInventoryDataSource(t *testing.T) {
+	env := newTestEnv(t)
+
+	profile := env.apply("intune_windows_driver_update_profile", nil, `{"display_name": "Drivers - pilot", "approval_type": "manual"}`)
+	addDriverInventory(env, profile.id())
+
+	drivers := env.readDataSource("intune_driver_inventory", fmt.Sprintf(`{"profile_id": %q}`, profile.id()))["drivers"].([]interface{})
+	if len(drivers) != 3 {
+		t.Fatalf("expected 3 drivers, got %d", len(drivers))
+	}
+	display := drivers[0].(map[string]interface{})
+	assertAttr(t, display, "id", "driver-display")
+	assertAttr(t, display, "driver_class", "Display")
+	assertAttr(t, display, "applicable_device_count", float64(42))
+	assertAttr(t, display, "approval_status", "needsReview")
+	assertAttr(t, display, "deploy_date_time", nil)
+	assertAttr(t, drivers[2].(map[string]interface{}), "deploy_date_time", "2026-08-01T00:00:00Z")
+}
@@ -0,0 +1,580 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// profileFieldKind is the type of an attribute of a typed profile block
+type profileFieldKind int
+
+const (
+	profileString profileFieldKind = iota
+	profileBool
+	profileInt64
+	profileStringList
+
+	// profileSecret is a sensitive string Graph does not return, so its configured value is kept
+	profileSecret
+
+	// profileReference is the ID of another device configuration, bound with @odata.bind. Graph
+	// does not return it, so its configured value is kept.
+	profileReference
+
+	// profileObjects is a list of nested blocks
+	profileObjects
+
+	// profileOmaSettings is a list of oma_setting blocks
+	profileOmaSettings
+)
+
+// profileField maps an attribute of a typed profile block to a property of the profile
+type profileField struct {
+	name        string
+	property    string
+	kind        profileFieldKind
+	description string
+	required    bool
+
+	// values are the accepted values of a string attribute
+	values []string
+
+	// pattern is a regular expression string values must match, described by patternMessage
+	pattern        *regexp.Regexp
+	patternMessage string
+
+	// minimum and maximum bound an int64 attribute, if maximum is set
+	minimum int64
+	maximum int64
+
+	// fields are the attributes of nested blocks
+	fields []profileField
+}
+
+// deviceConfigurationProfile is a profile type with first-class support: a block whose attributes
+// map to the properties of the type
+type deviceConfigurationProfile struct {
+	block       string
+	odataType   string
+	description string
+	fields      []profileField
+}
+
+// Values shared by the certificate profiles
+var (
+	certificateSubjectNameFormats = []string{
+		"commonName", "commonNameIncludingEmail", "commonNameAsEmail", "custom", "commonNameAsIMEI",
+		"commonNameAsSerialNumber", "commonNameAsAadDeviceId", "commonNameAsIntuneDeviceId", "commonNameAsDurableDeviceId",
+	}
+	certificateKeyStorageProviders = []string{
+		"useTpmKspOtherwiseUseSoftwareKsp", "useTpmKspOtherwiseFail", "usePassportForWorkKspOtherwiseFail", "useSoftwareKsp",
+	}
+	certificateValidityPeriodScales = []string{"days", "months", "years"}
+
+	certificateFields = []profileField{
+		{name: "subject_name_format", property: "subjectNameFormat", kind: profileString, values: certificateSubjectNameFormats,
+			description: "The format of the certificate subject name."},
+		{name: "subject_name_format_string", property: "subjectNameFormatString", kind: profileString,
+			description: "The custom subject name format, used with subject_name_format custom."},
+		{name: "subject_alternative_name_type", property: "subjectAlternativeNameType", kind: profileString,
+			description: "Comma-separated subject alternative name types, e.g. userPrincipalName,emailAddress."},
+		{name: "key_storage_provider", property: "keyStorageProvider", kind: profileString, values: certificateKeyStorageProviders,
+			description: "The key storage provider of the private key."},
+		{name: "certificate_validity_period_scale", property: "certificateValidityPeriodScale", kind: profileString, values: certificateValidityPeriodScales,
+			description: "The unit of the certificate validity period."},
+		{name: "certificate_validity_period_value", property: "certificateValidityPeriodValue", kind: profileInt64,
+			description: "The certificate validity period, in certificate_validity_period_scale units."},
+		{name: "renewal_threshold_percentage", property: "renewalThresholdPercentage", kind: profileInt64,
+			description: "The remaining lifetime, in percent, at which the certificate is renewed."},
+		{name: "extended_key_usage", property: "extendedKeyUsages", kind: profileObjects,
+			description: "An extended key usage of the certificate.",
+			fields: []profileField{
+				{name: "name", property: "name", kind: profileString, required: true, description: "The name of the extended key usage, e.g. Client Authentication."},
+				{name: "object_identifier", property: "objectIdentifier", kind: profileString, required: true, description: "The object identifier, e.g. 1.3.6.1.5.5.7.3.2."},
+			}},
+	}
+
+	updateRingAccessValues = []string{"notConfigured", "enabled", "disabled"}
+)
+
+// deviceConfigurationProfiles are the profile types with first-class support
+var deviceConfigurationProfiles = []deviceConfigurationProfile{
+	{
+		block:       "custom",
+		odataType:   "#microsoft.graph.windows10CustomConfiguration",
+		description: "A Windows 10/11 custom profile of OMA-URI settings.",
+		fields: []profileField{
+			{name: "oma_setting", property: "omaSettings", kind: profileOmaSettings},
+		},
+	},
+	{
+		block:       "trusted_root_certificate",
+		odataType:   "#microsoft.graph.windows81TrustedRootCertificate",
+		description: "A trusted root or intermediate certificate for Windows 8.1 and later.",
+		fields: []profileField{
+			{name: "certificate", property: "trustedRootCertificate", kind: profileString, required: true,
+				description: "The base64 encoded DER certificate."},
+			{name: "cert_file_name", property: "certFileName", kind: profileString, required: true,
+				description: "The file name shown for the certificate."},
+			{name: "destination_store", property: "destinationStore", kind: profileString,
+				values:      []string{"computerCertStoreRoot", "computerCertStoreIntermediate", "userCertStoreIntermediate"},
+				description: "The certificate store the certificate is installed in."},
+		},
+	},
+	{
+		block:       "wifi",
+		odataType:   "#microsoft.graph.windowsWifiConfiguration",
+		description: "A Windows Wi-Fi profile.",
+		fields: []profileField{
+			{name: "ssid", property: "ssid", kind: profileString, required: true, description: "The SSID of the network."},
+			{name: "network_name", property: "networkName", kind: profileString, required: true, description: "The name of the network."},
+			{name: "security_type", property: "wifiSecurityType", kind: profileString, required: true,
+				values:      []string{"open", "wpaPersonal", "wpaEnterprise", "wep", "wpa2Personal", "wpa2Enterprise"},
+				description: "The security type of the network."},
+			{name: "pre_shared_key", property: "preSharedKey", kind: profileSecret,
+				description: "The pre-shared key of WPA/WPA2 personal networks. Graph does not return the key, so changes made outside of OpenTofu are not detected."},
+			{name: "connect_automatically", property: "connectAutomatically", kind: profileBool, description: "Connect automatically when the network is in range."},
+			{name: "connect_to_preferred_network", property: "connectToPreferredNetwork", kind: profileBool, description: "Connect to a more preferred network when available."},
+			{name: "connect_when_network_name_is_hidden", property: "connectWhenNetworkNameIsHidden", kind: profileBool, description: "Connect even if the network does not broadcast its SSID."},
+			{name: "force_fips_compliance", property: "forceFIPSCompliance", kind: profileBool, description: "Force FIPS 140-2 compliant encryption."},
+			{name: "metered_connection_limit", property: "meteredConnectionLimit", kind: profileString,
+				values: []string{"unrestricted", "fixed", "variable"}, description: "The metered connection limit."},
+			{name: "proxy_setting", property: "proxySetting", kind: profileString,
+				values: []string{"none", "manual", "automatic"}, description: "How the proxy is configured."},
+			{name: "proxy_manual_address", property: "proxyManualAddress", kind: profileString, description: "The address of a manual proxy."},
+			{name: "proxy_manual_port", property: "proxyManualPort", kind: profileInt64, description: "The port of a manual proxy."},
+			{name: "proxy_automatic_configuration_url", property: "proxyAutomaticConfigurationUrl", kind: profileString, description: "The URL of the proxy auto-configuration script."},
+		},
+	},
+	{
+		block:       "vpn",
+		odataType:   "#microsoft.graph.windows10VpnConfiguration",
+		description: "A Windows 10/11 VPN profile.",
+		fields: []profileField{
+			{name: "connection_name", property: "connectionName", kind: profileString, required: true, description: "The name of the connection shown to users."},
+			{name: "connection_type", property: "connectionType", kind: profileString, required: true,
+				values: []string{
+					"pulseSecure", "f5EdgeClient", "dellSonicWallMobileConnect", "checkPointCapsuleVpn", "automatic", "ikEv2",
+					"l2tp", "pptp", "citrix", "paloAltoGlobalProtect", "ciscoAnyConnect", "microsoftTunnel",
+				},
+				description: "The connection type."},
+			{name: "authentication_method", property: "authenticationMethod", kind: profileString,
+				values: []string{"certificate", "usernameAndPassword", "customEapXml", "derivedCredential"}, description: "The authentication method."},
+			{name: "remember_user_credentials", property: "rememberUserCredentials", kind: profileBool, description: "Remember the credentials of the user."},
+			{name: "enable_split_tunneling", property: "enableSplitTunneling", kind: profileBool, description: "Enable split tunneling."},
+			{name: "enable_always_on", property: "enableAlwaysOn", kind: profileBool, description: "Connect automatically and keep the connection up."},
+			{name: "enable_device_tunnel", property: "enableDeviceTunnel", kind: profileBool, description: "Use a device tunnel instead of a user tunnel."},
+			{name: "enable_conditional_access", property: "enableConditionalAccess", kind: profileBool, description: "Enable conditional access for the connection."},
+			{name: "only_associated_apps_can_use_connection", property: "onlyAssociatedAppsCanUseConnection", kind: profileBool, description: "Only allow associated apps to use the connection."},
+			{name: "dns_suffixes", property: "dnsSuffixes", kind: profileStringList, description: "DNS suffixes of the connection."},
+			{name: "trusted_network_domains", property: "trustedNetworkDomains", kind: profileStringList, description: "Domains on which the VPN is not connected automatically."},
+			{name: "server", property: "servers", kind: profileObjects, description: "A VPN server.",
+				fields: []profileField{
+					{name: "address", property: "address", kind: profileString, required: true, description: "The address of the server."},
+					{name: "description", property: "description", kind: profileString, description: "The description of the server."},
+					{name: "is_default_server", property: "isDefaultServer", kind: profileBool, description: "Whether this is the default server."},
+				}},
+		},
+	},
+	{
+		block:       "scep_certificate",
+		odataType:   "#microsoft.graph.windows81SCEPCertificateProfile",
+		description: "A SCEP certificate profile for Windows 8.1 and later.",
+		fields: append([]profileField{
+			{name: "root_certificate_id", property: "rootCertificate", kind: profileReference, required: true,
+				description: "The ID of the trusted root certificate profile of the issuing CA."},
+			{name: "scep_server_urls", property: "scepServerUrls", kind: profileStringList, required: true, description: "The URLs of the SCEP servers."},
+			{name: "key_usage", property: "keyUsage", kind: profileString,
+				description: "Comma-separated key usages: keyEncipherment, digitalSignature."},
+			{name: "key_size", property: "keySize", kind: profileString, values: []string{"size1024", "size2048", "size4096"}, description: "The key size."},
+			{name: "hash_algorithm", property: "hashAlgorithm", kind: profileString,
+				description: "Comma-separated hash algorithms: sha1, sha2."},
+		}, certificateFields...),
+	},
+	{
+		block:       "pkcs_certificate",
+		odataType:   "#microsoft.graph.windows10PkcsCertificateProfile",
+		description: "A PKCS certificate profile for Windows 10/11.",
+		fields: append([]profileField{
+			{name: "certification_authority", property: "certificationAuthority", kind: profileString, required: true, description: "The FQDN of the certification authority."},
+			{name: "certification_authority_name", property: "certificationAuthorityName", kind: profileString, required: true, description: "The name of the certification authority."},
+			{name: "certificate_template_name", property: "certificateTemplateName", kind: profileString, required: true, description: "The name of the certificate template."},
+		}, certificateFields...),
+	},
+	{
+		block:       "update_ring",
+		odataType:   "#microsoft.graph.windowsUpdateForBusinessConfiguration",
+		description: "A Windows Update for Business update ring.",
+		fields: []profileField{
+			{name: "quality_updates_deferral_period_in_days", property: "qualityUpdatesDeferralPeriodInDays", kind: profileInt64, description: "Days quality updates are deferred (0-30)."},
+			{name: "feature_updates_deferral_period_in_days", property: "featureUpdatesDeferralPeriodInDays", kind: profileInt64, description: "Days feature updates are deferred (0-365)."},
+			{name: "quality_updates_paused", property: "qualityUpdatesPaused", kind: profileBool, description: "Pause quality updates."},
+			{name: "feature_updates_paused", property: "featureUpdatesPaused", kind: profileBool, description: "Pause feature updates."},
+			{name: "feature_updates_rollback_window_in_days", property: "featureUpdatesRollbackWindowInDays", kind: profileInt64, description: "Days a feature update can be uninstalled (2-60)."},
+			{name: "business_ready_updates_only", property: "businessReadyUpdatesOnly", kind: profileString,
+				values:      []string{"userDefined", "all", "businessReadyOnly", "windowsInsiderBuildFast", "windowsInsiderBuildSlow", "windowsInsiderBuildRelease"},
+				description: "The servicing channel."},
+			{name: "automatic_update_mode", property: "automaticUpdateMode", kind: profileString,
+				values: []string{
+					"userDefined", "notifyDownload", "autoInstallAtMaintenanceTime", "autoInstallAndRebootAtMaintenanceTime",
+					"autoInstallAndRebootAtScheduledTime", "autoInstallAndRebootWithoutEndUserControl", "windowsDefault",
+				},
+				description: "How updates are installed."},
+			{name: "microsoft_update_service_allowed", property: "microsoftUpdateServiceAllowed", kind: profileBool, description: "Allow updates for other Microsoft products."},
+			{name: "drivers_excluded", property: "driversExcluded", kind: profileBool, description: "Exclude Windows drivers from updates."},
+			{name: "allow_windows11_upgrade", property: "allowWindows11Upgrade", kind: profileBool, description: "Allow eligible Windows 10 devices to upgrade to Windows 11."},
+			{name: "deadline_for_feature_updates_in_days", property: "deadlineForFeatureUpdatesInDays", kind: profileInt64, description: "Days before feature updates are installed automatically."},
+			{name: "deadline_for_quality_updates_in_days", property: "deadlineForQualityUpdatesInDays", kind: profileInt64, description: "Days before quality updates are installed automatically."},
+			{name: "deadline_grace_period_in_days", property: "deadlineGracePeriodInDays", kind: profileInt64, description: "Days after the deadline before a restart is enforced."},
+			{name: "postpone_reboot_until_after_deadline", property: "postponeRebootUntilAfterDeadline", kind: profileBool, description: "Postpone restarts until the deadline."},
+			{name: "user_pause_access", property: "userPauseAccess", kind: profileString, values: updateRingAccessValues, description: "Whether users can pause updates."},
+			{name: "user_windows_update_scan_access", property: "userWindowsUpdateScanAccess", kind: profileString, values: updateRingAccessValues, description: "Whether users can scan for updates."},
+			{name: "update_notification_level", property: "updateNotificationLevel", kind: profileString,
+				values:      []string{"notConfigured", "defaultNotifications", "restartWarningsOnly", "disableAllNotifications"},
+				description: "Which update notifications are shown."},
+		},
+	},
+}
+
+// findDeviceConfigurationProfile returns the typed profile of an OData type, or nil
+func findDeviceConfigurationProfile(odataType string) *deviceConfigurationProfile {
+	for i := range deviceConfigurationProfiles {
+		if deviceConfigurationProfiles[i].odataType == odataType {
+			return &deviceConfigurationProfiles[i]
+		}
+	}
+	return nil
+}
+
+// schema returns the block of the profile
+func (p *deviceConfigurationProfile) schema() schema.ListNestedBlock {
+	attributes, blocks := profileFieldSchemas(p.fields)
+	return schema.ListNestedBlock{
+		Description: p.description + " Conflicts with settings_json and the other profile blocks.",
+		Validators: []validator.List{
+			listvalidator.SizeAtMost(1),
+		},
+		NestedObject: schema.NestedBlockObject{
+			Attributes: attributes,
+			Blocks:     blocks,
+		},
+	}
+}
+
+// profileFieldSchemas returns the attributes and nested blocks of profile fields
+func profileFieldSchemas(fields []profileField) (map[string]schema.Attribute, map[string]schema.Block) {
+	attributes := make(map[string]schema.Attribute)
+	blocks := make(map[string]schema.Block)
+	for _, f := range fields {
+		switch f.kind {
+		case profileString, profileSecret, profileReference:
+			attribute := schema.StringAttribute{
+				Description: f.description,
+				Required:    f.required,
+				Optional:    !f.required,
+				Sensitive:   f.kind == profileSecret,
+			}
+			if len(f.values) > 0 {
+				attribute.Validators = append(attribute.Validators, stringvalidator.OneOf(f.values...))
+			}
+			if f.pattern != nil {
+				attribute.Validators = append(attribute.Validators, stringvalidator.RegexMatches(f.pattern, f.patternMessage))
+			}
+			attributes[f.name] = attribute
+		case profileBool:
+			attributes[f.name] = schema.BoolAttribute{Description: f.description, Optional: true}
+		case profileInt64:
+			attribute := schema.Int64Attribute{Description: f.description, Optional: true}
+			if f.maximum != 0 {
+				attribute.Validators = []validator.Int64{int64validator.Between(f.minimum, f.maximum)}
+			}
+			attributes[f.name] = attribute
+		case profileStringList:
+			attributes[f.name] = schema.ListAttribute{
+				Description: f.description,
+				Required:    f.required,
+				Optional:    !f.required,
+				ElementType: types.StringType,
+			}
+		case profileObjects:
+			nestedAttributes, nestedBlocks := profileFieldSchemas(f.fields)
+			blocks[f.name] = schema.ListNestedBlock{
+				Description: f.description,
+				NestedObject: schema.NestedBlockObject{
+					Attributes: nestedAttributes,
+					Blocks:     nestedBlocks,
+				},
+			}
+		case profileOmaSettings:
+			blocks[f.name] = omaSettingBlockSchema()
+		}
+	}
+	return attributes, blocks
+}
+
+// profileAttrTypes returns the attribute types of a block of profile fields
+func profileAttrTypes(fields []profileField) map[string]attr.Type {
+	attrTypes := make(map[string]attr.Type, len(fields))
+	for _, f := range fields {
+		switch f.kind {
+		case profileString, profileSecret, profileReference:
+			attrTypes[f.name] = types.StringType
+		case profileBool:
+			attrTypes[f.name] = types.BoolType
+		case profileInt64:
+			attrTypes[f.name] = types.Int64Type
+		case profileStringList:
+			attrTypes[f.name] = types.ListType{ElemType: types.StringType}
+		case profileObjects:
+			attrTypes[f.name] = types.ListType{ElemType: types.ObjectType{AttrTypes: profileAttrTypes(f.fields)}}
+		case profileOmaSettings:
+			attrTypes[f.name] = types.ListType{ElemType: types.ObjectType{AttrTypes: omaSettingAttrTypes}}
+		}
+	}
+	return attrTypes
+}
+
+// objectType returns the type of the profile block
+func (p *deviceConfigurationProfile) objectType() types.ObjectType {
+	return types.ObjectType{AttrTypes: profileAttrTypes(p.fields)}
+}
+
+// build returns the properties of the profile configured in a block. Unset booleans are false.
+// Other unset attributes are left out on create; on update, strings and lists are cleared, while
+// numbers, enums, secrets and references keep their value in Intune.
+func (p *deviceConfigurationProfile) build(ctx context.Context, client *clients.GraphClient, block types.List, update bool, diags *diag.Diagnostics) map[string]interface{} {
+	return buildProfileBlock(ctx, client, p.fields, block, update, diags)
+}
+
+// buildProfileBlock returns the properties configured in a block of profile fields
+func buildProfileBlock(ctx context.Context, client *clients.GraphClient, fields []profileField, block types.List, update bool, diags *diag.Diagnostics) map[string]interface{} {
+	props := make(map[string]interface{})
+	elements := block.Elements()
+	if len(elements) == 0 {
+		return props
+	}
+	object, ok := elements[0].(types.Object)
+	if !ok {
+		return props
+	}
+	buildProfileFields(ctx, client, fields, object.Attributes(), props, update, diags)
+	return props
+}
+
+// buildProfileFields sets the properties of profile fields from attribute values
+func buildProfileFields(ctx context.Context, client *clients.GraphClient, fields []profileField, values map[string]attr.Value, props map[string]interface{}, update bool, diags *diag.Diagnostics) {
+	for _, f := range fields {
+		value := values[f.name]
+		if value == nil || value.IsUnknown() {
+			continue
+		}
+		if value.IsNull() {
+			switch {
+			case f.kind == profileBool:
+				props[f.property] = false
+			case !update:
+			case f.kind == profileString && len(f.values) == 0:
+				props[f.property] = nil
+			case f.kind == profileStringList || f.kind == profileObjects || f.kind == profileOmaSettings:
+				props[f.property] = []interface{}{}
+			}
+			continue
+		}
+
+		switch f.kind {
+		case profileString, profileSecret:
+			props[f.property] = value.(types.String).ValueString()
+		case profileReference:
+			path := fmt.Sprintf("%s/%s", clients.PathDeviceConfigurations, value.(types.String).ValueString())
+			props[f.property+"@odata.bind"] = client.ResourceURL(path)
+		case profileBool:
+			props[f.property] = value.(types.Bool).ValueBool()
+		case profileInt64:
+			props[f.property] = value.(types.Int64).ValueInt64()
+		case profileStringList:
+			var list []string
+			diags.Append(value.(types.List).ElementsAs(ctx, &list, false)...)
+			props[f.property] = list
+		case profileObjects:
+			objects := make([]interface{}, 0)
+			for _, element := range value.(types.List).Elements() {
+				object := make(map[string]interface{})
+				buildProfileFields(ctx, client, f.fields, element.(types.Object).Attributes(), object, false, diags)
+				objects = append(objects, object)
+			}
+			props[f.property] = objects
+		case profileOmaSettings:
+			var settings []OmaSettingModel
+			diags.Append(value.(types.List).ElementsAs(ctx, &settings, false)...)
+			omaSettings, err := buildOmaSettings(settings)
+			if err != nil {
+				diags.AddError("Invalid OMA-URI Setting", err.Error())
+				continue
+			}
+			props[f.property] = omaSettings
+		}
+	}
+}
+
+// read returns the block of the profile for the properties read from Graph. Unset attributes stay
+// null while Graph reports an empty value, and unset numbers and enums, which build leaves alone,
+// stay null whatever their value. Without a prior block, such as after an import, all non-empty
+// values are read.
+func (p *deviceConfigurationProfile) read(ctx context.Context, prior types.List, props map[string]interface{}, diags *diag.Diagnostics) types.List {
+	return readProfileBlock(ctx, p.fields, prior, props, diags)
+}
+
+// readProfileBlock returns a block of profile fields for the properties read from Graph
+func readProfileBlock(ctx context.Context, fields []profileField, prior types.List, props map[string]interface{}, diags *diag.Diagnostics) types.List {
+	objectType := types.ObjectType{AttrTypes: profileAttrTypes(fields)}
+
+	var priorValues map[string]attr.Value
+	if elements := prior.Elements(); len(elements) > 0 {
+		if object, ok := elements[0].(types.Object); ok {
+			priorValues = object.Attributes()
+		}
+	}
+
+	object, d := types.ObjectValue(objectType.AttrTypes, readProfileFields(ctx, fields, priorValues, props, diags))
+	diags.Append(d...)
+	list, d := types.ListValue(objectType, []attr.Value{object})
+	diags.Append(d...)
+	return list
+}
+
+// readProfileFields returns the attribute values of profile fields
+func readProfileFields(ctx context.Context, fields []profileField, prior map[string]attr.Value, props map[string]interface{}, diags *diag.Diagnostics) map[string]attr.Value {
+	values := make(map[string]attr.Value, len(fields))
+	for _, f := range fields {
+		priorValue := prior[f.name]
+		priorNull := priorValue == nil || priorValue.IsNull()
+		unmanaged := prior != nil && priorNull
+		raw := props[f.property]
+
+		switch f.kind {
+		case profileString:
+			s, _ := raw.(string)
+			if (s == "" || (unmanaged && len(f.values) > 0)) && priorNull {
+				values[f.name] = types.StringNull()
+			} else {
+				values[f.name] = types.StringValue(s)
+			}
+
+		case profileSecret, profileReference:
+			if priorNull {
+				values[f.name] = types.StringNull()
+			} else {
+				values[f.name] = priorValue
+			}
+
+		case profileBool:
+			b, _ := raw.(bool)
+			if !b && priorNull {
+				values[f.name] = types.BoolNull()
+			} else {
+				values[f.name] = types.BoolValue(b)
+			}
+
+		case profileInt64:
+			n, err := jsonInt64(raw)
+			switch {
+			case err != nil:
+				diags.AddError("Unexpected Profile Property", fmt.Sprintf("Property %s: %s", f.property, err))
+				values[f.name] = types.Int64Null()
+			case raw == nil || unmanaged || (n == 0 && priorNull):
+				values[f.name] = types.Int64Null()
+			default:
+				values[f.name] = types.Int64Value(n)
+			}
+
+		case profileStringList:
+			items, _ := raw.([]interface{})
+			if len(items) == 0 && priorNull {
+				values[f.name] = types.ListNull(types.StringType)
+				continue
+			}
+			list := make([]string, 0, len(items))
+			for _, item := range items {
+				if s, ok := item.(string); ok {
+					list = append(list, s)
+				}
+			}
+			value, d := types.ListValueFrom(ctx, types.StringType, list)
+			diags.Append(d...)
+			values[f.name] = value
+
+		case profileObjects:
+			objectType := types.ObjectType{AttrTypes: profileAttrTypes(f.fields)}
+			items, _ := raw.([]interface{})
+			if len(items) == 0 && priorNull {
+				values[f.name] = types.ListNull(objectType)
+				continue
+			}
+			var priorElements []attr.Value
+			if !priorNull {
+				priorElements = priorValue.(types.List).Elements()
+			}
+			elements := make([]attr.Value, 0, len(items))
+			for i, item := range items {
+				object, _ := item.(map[string]interface{})
+				var priorObject map[string]attr.Value
+				if i < len(priorElements) {
+					priorObject = priorElements[i].(types.Object).Attributes()
+				}
+				element, d := types.ObjectValue(objectType.AttrTypes, readProfileFields(ctx, f.fields, priorObject, object, diags))
+				diags.Append(d...)
+				elements = append(elements, element)
+			}
+			value, d := types.ListValue(objectType, elements)
+			diags.Append(d...)
+			values[f.name] = value
+
+		case profileOmaSettings:
+			objectType := types.ObjectType{AttrTypes: omaSettingAttrTypes}
+			var priorSettings []OmaSettingModel
+			if !priorNull {
+				diags.Append(priorValue.(types.List).ElementsAs(ctx, &priorSettings, false)...)
+			}
+			items, _ := raw.([]interface{})
+			settings := omaSettingModels(priorSettings, items)
+			if len(settings) == 0 && priorNull {
+				values[f.name] = types.ListNull(objectType)
+				continue
+			}
+			value, d := types.ListValueFrom(ctx, objectType, settings)
+			diags.Append(d...)
+			values[f.name] = value
+		}
+	}
+	return values
+}
+
+// jsonInt64 converts a number decoded with json.Number to an int64. Missing values are 0.
+func jsonInt64(raw interface{}) (int64, error) {
+	switch v := raw.(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		return v.Int64()
+	case float64:
+		return int64(v), nil
+	default:
+		return 0, fmt.Errorf("expected a number, got %T", raw)
+	}
+}
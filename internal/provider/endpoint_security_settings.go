@@ -0,0 +1,224 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// OData types of endpoint security intent setting instances
+const (
+	odataIntentBooleanSetting         = "#microsoft.graph.deviceManagementBooleanSettingInstance"
+	odataIntentIntegerSetting         = "#microsoft.graph.deviceManagementIntegerSettingInstance"
+	odataIntentStringSetting          = "#microsoft.graph.deviceManagementStringSettingInstance"
+	odataIntentCollectionSetting      = "#microsoft.graph.deviceManagementCollectionSettingInstance"
+	odataIntentComplexSetting         = "#microsoft.graph.deviceManagementComplexSettingInstance"
+	odataIntentAbstractComplexSetting = "#microsoft.graph.deviceManagementAbstractComplexSettingInstance"
+)
+
+// DecodeSettingsJSON parses settings_json, keeping numbers exact
+func DecodeSettingsJSON(s string) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+
+	var settings map[string]interface{}
+	if err := decoder.Decode(&settings); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the settings object")
+	}
+	return settings, nil
+}
+
+// intentSettingShortName returns the setting name of a definition ID, e.g. firewallBlockStatefulFTP
+// for deviceConfiguration--windows10EndpointProtectionConfiguration_firewallBlockStatefulFTP
+func intentSettingShortName(definitionId string) string {
+	name := definitionId
+	if i := strings.LastIndex(name, "--"); i >= 0 {
+		name = name[i+2:]
+	}
+	if i := strings.Index(name, "_"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// findIntentSetting returns the setting a settings_json key refers to. Keys are either a full
+// definition ID or the setting name after the configuration class. Ambiguous setting names
+// return all candidates.
+func findIntentSetting(key string, settings []clients.IntentSetting) []clients.IntentSetting {
+	var matches []clients.IntentSetting
+	for _, setting := range settings {
+		if setting.DefinitionId == key {
+			return []clients.IntentSetting{setting}
+		}
+		if intentSettingShortName(setting.DefinitionId) == key {
+			matches = append(matches, setting)
+		}
+	}
+	return matches
+}
+
+// BuildIntentSettings maps settings_json to typed setting instances of the template settings.
+// Keys that match no setting, match several, or hold a value of the wrong type are reported as
+// errors on settings_json.
+func BuildIntentSettings(settings map[string]interface{}, templateSettings []clients.IntentSetting, diags *diag.Diagnostics) []clients.IntentSetting {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]clients.IntentSetting, 0, len(keys))
+	for _, key := range keys {
+		matches := findIntentSetting(key, templateSettings)
+		switch len(matches) {
+		case 0:
+			diags.AddAttributeError(
+				path.Root("settings_json"),
+				"Unknown Endpoint Security Setting",
+				fmt.Sprintf("The template of this policy has no setting %q. Use a definition ID or setting name of the template.", key),
+			)
+			continue
+		case 1:
+		default:
+			ids := make([]string, 0, len(matches))
+			for _, match := range matches {
+				ids = append(ids, match.DefinitionId)
+			}
+			diags.AddAttributeError(
+				path.Root("settings_json"),
+				"Ambiguous Endpoint Security Setting",
+				fmt.Sprintf("The setting name %q matches several settings of the template, use one of these definition IDs instead: %s", key, strings.Join(ids, ", ")),
+			)
+			continue
+		}
+
+		valueJson, err := encodeIntentValue(matches[0].ODataType, settings[key])
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("settings_json"),
+				"Invalid Endpoint Security Setting Value",
+				fmt.Sprintf("Setting %q: %s", key, err),
+			)
+			continue
+		}
+
+		result = append(result, clients.IntentSetting{
+			ODataType:    matches[0].ODataType,
+			DefinitionId: matches[0].DefinitionId,
+			ValueJson:    valueJson,
+		})
+	}
+
+	return result
+}
+
+// encodeIntentValue checks a value against the type of a setting instance and encodes it as valueJson.
+// null clears the setting regardless of its type.
+func encodeIntentValue(odataType string, value interface{}) (string, error) {
+	if value != nil {
+		var ok bool
+		var want string
+		switch odataType {
+		case odataIntentBooleanSetting:
+			_, ok = value.(bool)
+			want = "a boolean"
+		case odataIntentIntegerSetting:
+			n, isNumber := value.(json.Number)
+			_, err := n.Int64()
+			ok = isNumber && err == nil
+			want = "an integer"
+		case odataIntentStringSetting:
+			_, ok = value.(string)
+			want = "a string"
+		case odataIntentCollectionSetting:
+			_, ok = value.([]interface{})
+			want = "a list"
+		case odataIntentComplexSetting, odataIntentAbstractComplexSetting:
+			_, ok = value.(map[string]interface{})
+			want = "an object"
+		default:
+			return "", fmt.Errorf("setting type %s is not supported", odataType)
+		}
+		if !ok {
+			return "", fmt.Errorf("expected %s, got %s", want, jsonTypeName(value))
+		}
+	}
+
+	valueJson, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(valueJson), nil
+}
+
+// jsonTypeName describes the type of a decoded JSON value for error messages
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case bool:
+		return "a boolean"
+	case json.Number:
+		return "a number"
+	case string:
+		return "a string"
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return "null"
+}
+
+// IntentSettingsJSON builds settings_json from the settings of an intent. Only the keys of the
+// prior settings_json are included, so the template defaults of unmanaged settings do not show
+// up as drift; without prior settings every setting is included, keyed by definition ID. If the
+// result is equivalent to the prior settings_json, the prior string is returned unchanged.
+func IntentSettingsJSON(prior string, settings []clients.IntentSetting) (string, error) {
+	var priorSettings map[string]interface{}
+	if prior != "" {
+		priorSettings, _ = DecodeSettingsJSON(prior)
+	}
+
+	result := make(map[string]json.RawMessage, len(settings))
+	if priorSettings == nil {
+		for _, setting := range settings {
+			if json.Valid([]byte(setting.ValueJson)) {
+				result[setting.DefinitionId] = json.RawMessage(setting.ValueJson)
+			}
+		}
+	} else {
+		for key := range priorSettings {
+			if matches := findIntentSetting(key, settings); len(matches) == 1 && json.Valid([]byte(matches[0].ValueJson)) {
+				result[key] = json.RawMessage(matches[0].ValueJson)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(result); err != nil {
+		return "", err
+	}
+	settingsJSON := strings.TrimSuffix(buf.String(), "\n")
+
+	if priorSettings != nil {
+		if current, err := DecodeSettingsJSON(settingsJSON); err == nil && reflect.DeepEqual(current, priorSettings) {
+			return prior, nil
+		}
+	}
+	return settingsJSON, nil
+}
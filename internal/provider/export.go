@@ -0,0 +1,589 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Exporter generates OpenTofu configuration for the objects in an Intune tenant. Every object
+// becomes a resource with an import block, so that `tofu plan` adopts the existing objects
+// instead of creating new ones. References to scope tags and assignment filters are written as
+// references to the exported resources.
+type Exporter struct {
+	client *clients.GraphClient
+
+	// names holds the resource names used so far, per resource type
+	names map[string]map[string]bool
+
+	// scopeTags and filters map object IDs to the address of their exported resource
+	scopeTags map[string]string
+	filters   map[string]string
+
+	// complianceScripts maps compliance script IDs to the address of their exported resource
+	complianceScripts map[string]string
+}
+
+// NewExporter creates an exporter reading from the given client
+func NewExporter(client *clients.GraphClient) *Exporter {
+	return &Exporter{
+		client:    client,
+		names:     make(map[string]map[string]bool),
+		scopeTags: make(map[string]string),
+		filters:   make(map[string]string),
+
+		complianceScripts: make(map[string]string),
+	}
+}
+
+// Export reads scope tags, assignment filters, Settings Catalog policies, compliance scripts,
+// compliance policies, Linux compliance policies and endpoint security policies and writes their
+// configuration to w
+func (e *Exporter) Export(ctx context.Context, w io.Writer) error {
+	// Scope tags and filters go first, so policies can reference them
+	steps := []func(context.Context) ([]*hclBlock, error){
+		e.exportScopeTags,
+		e.exportAssignmentFilters,
+		e.exportSettingsCatalogPolicies,
+		e.exportComplianceScripts,
+		e.exportCompliancePolicies,
+		e.exportLinuxCompliancePolicies,
+		e.exportEndpointSecurityPolicies,
+	}
+
+	var blocks []*hclBlock
+	for _, step := range steps {
+		stepBlocks, err := step(ctx)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, stepBlocks...)
+	}
+
+	fmt.Fprintln(w, "# Generated by terraform-provider-intune export. Review before applying.")
+	for _, block := range blocks {
+		fmt.Fprintln(w)
+		block.write(w, 0)
+	}
+
+	return nil
+}
+
+// resource starts a resource block with a unique name derived from displayName, followed by the
+// import block adopting the object with the given import ID
+func (e *Exporter) resource(typeName, displayName, importID string) (*hclBlock, *hclBlock, string) {
+	used := e.names[typeName]
+	if used == nil {
+		used = make(map[string]bool)
+		e.names[typeName] = used
+	}
+
+	base := hclIdentifier(displayName)
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[name] = true
+
+	address := typeName + "." + name
+	importBlock := &hclBlock{typ: "import"}
+	importBlock.attr("to", address)
+	importBlock.attr("id", hclString(importID))
+
+	return &hclBlock{typ: "resource", labels: []string{typeName, name}}, importBlock, address
+}
+
+// skipped returns a comment noting an object that is not exported
+func skipped(kind, displayName, reason string) *hclBlock {
+	return &hclBlock{comments: []string{fmt.Sprintf("Skipped %s %q: %s", kind, displayName, reason)}}
+}
+
+// scopeTagIDs renders role scope tag IDs, referencing exported scope tags. Nothing is rendered
+// when only the default scope tag is set, matching an omitted attribute.
+func (e *Exporter) scopeTagIDs(block *hclBlock, name string, ids []string) {
+	if len(ids) == 0 || (len(ids) == 1 && ids[0] == DefaultScopeTagID) {
+		return
+	}
+
+	exprs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if address, ok := e.scopeTags[id]; ok {
+			exprs = append(exprs, address+".id")
+		} else {
+			exprs = append(exprs, hclString(id))
+		}
+	}
+	block.attr(name, hclList(exprs))
+}
+
+// optionalAttr renders an optional string attribute
+func optionalAttr(block *hclBlock, name, value string) {
+	if value != "" {
+		block.attr(name, hclString(value))
+	}
+}
+
+// exportScopeTags exports custom role scope tags. Built-in tags cannot be managed and are only
+// referenced by ID.
+func (e *Exporter) exportScopeTags(ctx context.Context) ([]*hclBlock, error) {
+	tags, err := e.client.ListScopeTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].DisplayName < tags[j].DisplayName })
+
+	var blocks []*hclBlock
+	for _, tag := range tags {
+		if tag.IsBuiltIn || tag.ID == DefaultScopeTagID {
+			continue
+		}
+
+		res, imp, address := e.resource("intune_scope_tag", tag.DisplayName, tag.ID)
+		res.attr("display_name", hclString(tag.DisplayName))
+		optionalAttr(res, "description", tag.Description)
+		e.scopeTags[tag.ID] = address
+
+		blocks = append(blocks, res, imp)
+	}
+
+	return blocks, nil
+}
+
+// exportAssignmentFilters exports assignment filters
+func (e *Exporter) exportAssignmentFilters(ctx context.Context) ([]*hclBlock, error) {
+	filters, err := e.client.ListAssignmentFilters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(filters, func(i, j int) bool { return filters[i].DisplayName < filters[j].DisplayName })
+
+	var blocks []*hclBlock
+	for _, filter := range filters {
+		res, imp, address := e.resource("intune_assignment_filter", filter.DisplayName, filter.ID)
+		res.attr("display_name", hclString(filter.DisplayName))
+		optionalAttr(res, "description", filter.Description)
+		res.attr("platform", hclString(filter.Platform))
+		res.attr("rule", hclString(filter.Rule))
+		e.scopeTagIDs(res, "role_scope_tags", filter.RoleScopeTags)
+		e.filters[filter.ID] = address
+
+		blocks = append(blocks, res, imp)
+	}
+
+	return blocks, nil
+}
+
+// exportSettingsCatalogPolicies exports Settings Catalog policies, with their settings as a
+// separate intune_settings_catalog_policy_settings resource
+func (e *Exporter) exportSettingsCatalogPolicies(ctx context.Context) ([]*hclBlock, error) {
+	policies, err := clients.ListInto[clients.SettingsCatalogPolicy](ctx, e.client, clients.PathSettingsCatalogPolicies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settings catalog policies: %w", err)
+	}
+	sort.SliceStable(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+
+	var blocks []*hclBlock
+	for _, policy := range policies {
+		if policy.TemplateReference != nil && endpointSecurityTemplateTypeForFamily(policy.TemplateReference.TemplateFamily) != "" {
+			esBlocks, err := e.exportEndpointSecurityConfigurationPolicy(ctx, policy)
+			if err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, esBlocks...)
+			continue
+		}
+
+		res, imp, address := e.resource("intune_settings_catalog_policy", policy.Name, policy.ID)
+		res.attr("name", hclString(policy.Name))
+		optionalAttr(res, "description", policy.Description)
+		res.attr("platforms", hclString(policy.Platforms))
+		res.attr("technologies", hclString(policy.Technologies))
+		if policy.TemplateReference != nil && policy.TemplateReference.TemplateId != "" {
+			res.attr("template_id", hclString(policy.TemplateReference.TemplateId))
+		}
+		e.scopeTagIDs(res, "role_scope_tag_ids", policy.RoleScopeTagIds)
+		if err := e.assignments(ctx, res, PolicyTypeSettingsCatalog, policy.ID); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, res, imp)
+
+		apiSettings, err := e.client.GetSettingsCatalogPolicySettings(ctx, policy.ID)
+		if err != nil {
+			return nil, err
+		}
+		settings, unsupported := FlattenSettingInstances(apiSettings)
+		if len(settings) == 0 && len(unsupported) == 0 {
+			continue
+		}
+
+		settingsRes, settingsImp, _ := e.resource("intune_settings_catalog_policy_settings", policy.Name, policy.ID)
+		for _, definitionID := range unsupported {
+			settingsRes.comments = append(settingsRes.comments, fmt.Sprintf("Setting %s has a type the provider does not support and was left out.", definitionID))
+		}
+		settingsRes.attr("policy_id", address+".id")
+		writeSettingBlocks(settingsRes, "setting", settings)
+		blocks = append(blocks, settingsRes, settingsImp)
+	}
+
+	return blocks, nil
+}
+
+// exportEndpointSecurityConfigurationPolicy exports a Settings Catalog policy created from an
+// endpoint security template, with its settings inline
+func (e *Exporter) exportEndpointSecurityConfigurationPolicy(ctx context.Context, policy clients.SettingsCatalogPolicy) ([]*hclBlock, error) {
+	apiSettings, err := e.client.GetSettingsCatalogPolicySettings(ctx, policy.ID)
+	if err != nil {
+		return nil, err
+	}
+	settings, unsupported := FlattenSettingInstances(apiSettings)
+
+	res, imp, _ := e.resource("intune_endpoint_security_configuration_policy", policy.Name, policy.ID)
+	for _, definitionID := range unsupported {
+		res.comments = append(res.comments, fmt.Sprintf("Setting %s has a type the provider does not support and was left out.", definitionID))
+	}
+	res.attr("name", hclString(policy.Name))
+	optionalAttr(res, "description", policy.Description)
+	res.attr("template_id", hclString(policy.TemplateReference.TemplateId))
+	e.scopeTagIDs(res, "role_scope_tag_ids", policy.RoleScopeTagIds)
+	writeSettingBlocks(res, "setting", settings)
+	if err := e.assignments(ctx, res, PolicyTypeSettingsCatalog, policy.ID); err != nil {
+		return nil, err
+	}
+
+	return []*hclBlock{res, imp}, nil
+}
+
+// writeSettingBlocks renders settings and their children as nested blocks
+func writeSettingBlocks(parent *hclBlock, blockType string, settings []SettingModel) {
+	for _, setting := range settings {
+		block := parent.block(blockType)
+		block.attr("definition_id", hclString(setting.DefinitionID.ValueString()))
+		block.attr("value_type", hclString(setting.ValueType.ValueString()))
+		if !setting.Value.IsNull() && !setting.Value.IsUnknown() {
+			block.attr("value", hclString(setting.Value.ValueString()))
+		}
+		writeSettingBlocks(block, "children", setting.Children)
+	}
+}
+
+// exportComplianceScripts exports device compliance scripts. Graph only returns the content of a
+// script when it is read on its own.
+func (e *Exporter) exportComplianceScripts(ctx context.Context) ([]*hclBlock, error) {
+	scripts, err := clients.ListInto[clients.DeviceComplianceScript](ctx, e.client, clients.PathDeviceComplianceScripts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device compliance scripts: %w", err)
+	}
+	sort.SliceStable(scripts, func(i, j int) bool { return scripts[i].DisplayName < scripts[j].DisplayName })
+
+	var blocks []*hclBlock
+	for _, listed := range scripts {
+		script, err := e.client.GetDeviceComplianceScript(ctx, listed.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		var data DeviceComplianceScriptResourceModel
+		var diags diag.Diagnostics
+		(&DeviceComplianceScriptResource{}).updateModel(ctx, &data, script, &diags)
+		if diags.HasError() {
+			return nil, fmt.Errorf("failed to convert device compliance script %s: %s", script.ID, diags.Errors()[0].Detail())
+		}
+
+		res, imp, address := e.resource("intune_device_compliance_script", script.DisplayName, script.ID)
+		skip := []string{"id", "detection_script_content_sha256", "created_date_time", "last_modified_date_time", "role_scope_tag_ids"}
+		if script.RunAsAccount == "system" {
+			skip = append(skip, "run_as_account")
+		}
+		writeModelAttributes(res, data, skip...)
+		e.scopeTagIDs(res, "role_scope_tag_ids", script.RoleScopeTagIds)
+		e.complianceScripts[script.ID] = address
+
+		blocks = append(blocks, res, imp)
+	}
+
+	return blocks, nil
+}
+
+// exportCompliancePolicies exports the compliance policies of the platforms intune_compliance_policy
+// supports
+func (e *Exporter) exportCompliancePolicies(ctx context.Context) ([]*hclBlock, error) {
+	policies, err := clients.ListInto[clients.CompliancePolicy](ctx, e.client, clients.PathCompliancePolicies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list compliance policies: %w", err)
+	}
+	sort.SliceStable(policies, func(i, j int) bool { return policies[i].DisplayName < policies[j].DisplayName })
+
+	var blocks []*hclBlock
+	for i := range policies {
+		policy := &policies[i]
+		platform := findCompliancePlatform(policy.ODataType)
+		if platform == nil && policy.ODataType != windowsComplianceODataType {
+			blocks = append(blocks, skipped("compliance policy", policy.DisplayName, fmt.Sprintf("%s is not supported", policy.ODataType)))
+			continue
+		}
+
+		var data CompliancePolicyResourceModel
+		var diags diag.Diagnostics
+		(&CompliancePolicyResource{}).updateModel(&data, policy, &diags)
+		if diags.HasError() {
+			return nil, fmt.Errorf("failed to convert compliance policy %s: %s", policy.ID, diags.Errors()[0].Detail())
+		}
+
+		res, imp, _ := e.resource("intune_compliance_policy", policy.DisplayName, policy.ID)
+		skip := []string{"id", "type", "platform", "created_date_time", "last_modified_date_time", "role_scope_tag_ids"}
+		if platform != nil {
+			// The Windows attributes conflict with the platform blocks
+			for name := range data.windowsSettings() {
+				skip = append(skip, name)
+			}
+		}
+		writeModelAttributes(res, data, skip...)
+		e.scopeTagIDs(res, "role_scope_tag_ids", policy.RoleScopeTagIds)
+		if platform != nil {
+			if elements := data.platformBlocks()[platform.block].Elements(); len(elements) > 0 {
+				writeProfileFields(res.block(platform.block), platform.fields, elements[0].(types.Object).Attributes())
+			}
+		}
+		if script := policy.DeviceCompliancePolicyScript; script != nil {
+			block := res.block("custom_compliance")
+			if address, ok := e.complianceScripts[script.DeviceComplianceScriptId]; ok {
+				block.attr("script_id", address+".id")
+			} else {
+				block.attr("script_id", hclString(script.DeviceComplianceScriptId))
+			}
+			rules := data.CustomCompliance.Elements()[0].(types.Object).Attributes()["rules_json"].(types.String)
+			block.attr("rules_json", hclString(rules.ValueString()))
+		}
+		for _, r := range policy.ValidOperatingSystemBuildRanges {
+			block := res.block("valid_os_build_range")
+			optionalAttr(block, "description", r.Description)
+			block.attr("lowest_version", hclString(r.LowestVersion))
+			block.attr("highest_version", hclString(r.HighestVersion))
+		}
+		actions, err := e.client.GetCompliancePolicyScheduledActions(ctx, policy.ID)
+		if err != nil {
+			return nil, err
+		}
+		writeScheduledActions(res, actions)
+		if err := e.assignments(ctx, res, PolicyTypeCompliance, policy.ID); err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, res, imp)
+	}
+
+	return blocks, nil
+}
+
+// exportLinuxCompliancePolicies exports the Settings Catalog based compliance policies of Linux.
+// Graph lists them separately from the compliance policies of the other platforms.
+func (e *Exporter) exportLinuxCompliancePolicies(ctx context.Context) ([]*hclBlock, error) {
+	policies, err := clients.ListInto[clients.SettingsCompliancePolicy](ctx, e.client, clients.PathSettingsCompliancePolicies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Linux compliance policies: %w", err)
+	}
+	sort.SliceStable(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+
+	var blocks []*hclBlock
+	for _, listed := range policies {
+		if listed.Platforms != linuxCompliancePlatforms {
+			blocks = append(blocks, skipped("compliance policy", listed.Name, fmt.Sprintf("platform %s is not supported", listed.Platforms)))
+			continue
+		}
+
+		// Settings are only returned when they are expanded on a single policy
+		policy, err := e.client.GetSettingsCompliancePolicy(ctx, listed.ID)
+		if err != nil {
+			return nil, err
+		}
+		settings, unsupported := FlattenSettingInstances(policy.Settings)
+
+		res, imp, _ := e.resource("intune_linux_compliance_policy", policy.Name, policy.ID)
+		for _, definitionID := range unsupported {
+			res.comments = append(res.comments, fmt.Sprintf("Setting %s has a type the provider does not support and was left out.", definitionID))
+		}
+		res.attr("name", hclString(policy.Name))
+		optionalAttr(res, "description", policy.Description)
+		e.scopeTagIDs(res, "role_scope_tag_ids", policy.RoleScopeTagIds)
+		writeSettingBlocks(res, "setting", settings)
+		actions, err := e.client.GetSettingsCompliancePolicyScheduledActions(ctx, policy.ID)
+		if err != nil {
+			return nil, err
+		}
+		writeScheduledActions(res, actions)
+		if err := e.assignments(ctx, res, PolicyTypeLinuxCompliance, policy.ID); err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, res, imp)
+	}
+
+	return blocks, nil
+}
+
+// writeScheduledActions renders the scheduled actions of a compliance policy. Nothing is rendered
+// for the default action of policies without scheduled_actions_for_rule blocks.
+func writeScheduledActions(res *hclBlock, actions []clients.ComplianceScheduledAction) {
+	if isDefaultScheduledActions(actions) {
+		return
+	}
+
+	for _, action := range actions {
+		rule := res.block("scheduled_actions_for_rule")
+		rule.attr("rule_name", hclString(scheduledActionRuleName(action)))
+		for _, config := range action.ScheduledActionConfigurations {
+			block := rule.block("scheduled_action_configurations")
+			block.attr("action_type", hclString(config.ActionType))
+			block.attr("grace_period_hours", strconv.Itoa(config.GracePeriodHours))
+			optionalAttr(block, "notification_template_id", scheduledActionTemplate(config.NotificationTemplateId))
+			if len(config.NotificationMessageCCList) > 0 {
+				block.attr("notification_message_cc_list", hclStringList(config.NotificationMessageCCList))
+			}
+		}
+	}
+}
+
+// exportEndpointSecurityPolicies exports endpoint security intents. settings_json holds the
+// decoded value of each intent setting, keyed by definition ID.
+func (e *Exporter) exportEndpointSecurityPolicies(ctx context.Context) ([]*hclBlock, error) {
+	policies, err := clients.ListInto[clients.EndpointSecurityPolicy](ctx, e.client, clients.PathEndpointSecurityPolicies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoint security policies: %w", err)
+	}
+	sort.SliceStable(policies, func(i, j int) bool { return policies[i].DisplayName < policies[j].DisplayName })
+
+	var blocks []*hclBlock
+	for _, policy := range policies {
+		intentSettings, err := e.client.GetEndpointSecurityPolicySettings(ctx, policy.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		settingsJSON, err := IntentSettingsJSON("", intentSettings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode settings of endpoint security policy %s: %w", policy.ID, err)
+		}
+
+		res, imp, _ := e.resource("intune_endpoint_security_policy", policy.DisplayName, policy.ID)
+		res.attr("display_name", hclString(policy.DisplayName))
+		optionalAttr(res, "description", policy.Description)
+		res.attr("template_id", hclString(policy.TemplateId))
+		e.scopeTagIDs(res, "role_scope_tag_ids", policy.RoleScopeTagIds)
+		res.attr("settings_json", hclString(settingsJSON))
+		if err := e.assignments(ctx, res, PolicyTypeEndpointSecurity, policy.ID); err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, res, imp)
+	}
+
+	return blocks, nil
+}
+
+// writeProfileFields renders the attributes and nested blocks of profile fields that are set. False
+// booleans are left out, as they match the defaults.
+func writeProfileFields(block *hclBlock, fields []profileField, values map[string]attr.Value) {
+	for _, f := range fields {
+		value := values[f.name]
+		if value == nil || value.IsNull() || value.IsUnknown() {
+			continue
+		}
+
+		switch v := value.(type) {
+		case types.String:
+			block.attr(f.name, hclString(v.ValueString()))
+		case types.Bool:
+			if v.ValueBool() {
+				block.attr(f.name, "true")
+			}
+		case types.Int64:
+			block.attr(f.name, strconv.FormatInt(v.ValueInt64(), 10))
+		case types.List:
+			if f.kind == profileObjects {
+				for _, element := range v.Elements() {
+					writeProfileFields(block.block(f.name), f.fields, element.(types.Object).Attributes())
+				}
+				continue
+			}
+			var items []string
+			for _, element := range v.Elements() {
+				if s, ok := element.(types.String); ok {
+					items = append(items, s.ValueString())
+				}
+			}
+			block.attr(f.name, hclStringList(items))
+		}
+	}
+}
+
+// assignments renders the assignments of a policy as an assignment block
+func (e *Exporter) assignments(ctx context.Context, res *hclBlock, policyType, policyID string) error {
+	assignments, err := ReadPolicyAssignments(ctx, e.client, policyType, policyID)
+	if err != nil {
+		return err
+	}
+
+	for _, assignment := range assignments {
+		block := res.block("assignment")
+		for _, target := range assignment.Targets {
+			targetBlock := block.block("target")
+			targetBlock.attr("type", hclString(target.Type.ValueString()))
+			optionalAttr(targetBlock, "group_id", target.GroupID.ValueString())
+			if filterID := target.FilterID.ValueString(); filterID != "" {
+				if address, ok := e.filters[filterID]; ok {
+					targetBlock.attr("filter_id", address+".id")
+				} else {
+					targetBlock.attr("filter_id", hclString(filterID))
+				}
+				targetBlock.attr("filter_type", hclString(target.FilterType.ValueString()))
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeModelAttributes renders the string, bool and number attributes of a resource model that
+// are set, in declaration order. False booleans are left out, as they match the defaults.
+func writeModelAttributes(block *hclBlock, model interface{}, skip ...string) {
+	omit := make(map[string]bool, len(skip))
+	for _, name := range skip {
+		omit[name] = true
+	}
+
+	v := reflect.ValueOf(model)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("tfsdk")
+		if name == "" || omit[name] {
+			continue
+		}
+
+		switch value := v.Field(i).Interface().(type) {
+		case types.String:
+			if !value.IsNull() && !value.IsUnknown() && value.ValueString() != "" {
+				block.attr(name, hclString(value.ValueString()))
+			}
+		case types.Bool:
+			if value.ValueBool() {
+				block.attr(name, "true")
+			}
+		case types.Int64:
+			if !value.IsNull() && !value.IsUnknown() {
+				block.attr(name, strconv.FormatInt(value.ValueInt64(), 10))
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// hclBlock is a block of generated configuration. A block without a type only writes its comments.
+type hclBlock struct {
+	comments []string
+	typ      string
+	labels   []string
+	attrs    []hclAttr
+	blocks   []*hclBlock
+}
+
+// hclAttr is an attribute of a generated block with its value already rendered as an expression
+type hclAttr struct {
+	name string
+	expr string
+}
+
+// attr appends an attribute with a rendered expression
+func (b *hclBlock) attr(name, expr string) {
+	b.attrs = append(b.attrs, hclAttr{name: name, expr: expr})
+}
+
+// block appends a nested block and returns it
+func (b *hclBlock) block(typ string, labels ...string) *hclBlock {
+	nested := &hclBlock{typ: typ, labels: labels}
+	b.blocks = append(b.blocks, nested)
+	return nested
+}
+
+// write renders the block at the given indentation, aligning attribute names like `tofu fmt`
+func (b *hclBlock) write(w io.Writer, indent int) {
+	prefix := strings.Repeat("  ", indent)
+	for _, comment := range b.comments {
+		fmt.Fprintf(w, "%s# %s\n", prefix, comment)
+	}
+	if b.typ == "" {
+		return
+	}
+
+	header := b.typ
+	for _, label := range b.labels {
+		header += " " + hclString(label)
+	}
+	fmt.Fprintf(w, "%s%s {\n", prefix, header)
+
+	width := 0
+	for _, a := range b.attrs {
+		if len(a.name) > width {
+			width = len(a.name)
+		}
+	}
+	for _, a := range b.attrs {
+		fmt.Fprintf(w, "%s  %-*s = %s\n", prefix, width, a.name, a.expr)
+	}
+
+	for i, nested := range b.blocks {
+		if i > 0 || len(b.attrs) > 0 {
+			fmt.Fprintln(w)
+		}
+		nested.write(w, indent+1)
+	}
+	fmt.Fprintf(w, "%s}\n", prefix)
+}
+
+// hclString renders a quoted string literal, escaping template sequences
+func hclString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			// ${ and %{ start template sequences, doubling the marker escapes them
+			sb.WriteRune(r)
+			sb.WriteRune(r)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(&sb, `\u%04X`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// hclList renders a list of rendered expressions
+func hclList(exprs []string) string {
+	return "[" + strings.Join(exprs, ", ") + "]"
+}
+
+// hclStringList renders a list of string literals
+func hclStringList(values []string) string {
+	exprs := make([]string, 0, len(values))
+	for _, v := range values {
+		exprs = append(exprs, hclString(v))
+	}
+	return hclList(exprs)
+}
+
+// hclIdentifier turns a display name into a valid, lowercase resource name
+func hclIdentifier(name string) string {
+	var sb strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			underscore = false
+		} else if !underscore && sb.Len() > 0 {
+			sb.WriteByte('_')
+			underscore = true
+		}
+	}
+
+	id := strings.TrimSuffix(sb.String(), "_")
+	if id == "" {
+		return "unnamed"
+	}
+	if id[0] >= '0' && id[0] <= '9' {
+		id = "_" + id
+	}
+	return id
+}
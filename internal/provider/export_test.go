@@ -0,0 +1,174 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+func TestExporter(t *testing.T) {
+	env := newTestEnv(t)
+	addSettingDefinitions(env)
+	addLinuxComplianceDefinitions(env)
+
+	tag := env.apply("intune_scope_tag", nil, `{"display_name": "Helpdesk EU", "description": "First line"}`)
+	filter := env.apply("intune_assignment_filter", nil, fmt.Sprintf(`{
+		"display_name": "Corporate devices",
+		"platform": "windows10AndLater",
+		"rule": "(device.deviceOwnership -eq \"Corporate\")",
+		"role_scope_tags": [%q]
+	}`, tag.id()))
+
+	policy := env.apply("intune_settings_catalog_policy", nil, fmt.Sprintf(`{
+		"name": "Defender baseline",
+		"platforms": "windows10",
+		"technologies": "mdm",
+		"role_scope_tag_ids": [%q],
+		"assignment": [{"target": [
+			{"type": "group", "group_id": "00000000-0000-0000-0000-000000000001", "filter_id": %q, "filter_type": "include"},
+			{"type": "exclusion", "group_id": "00000000-0000-0000-0000-000000000002"}
+		]}]
+	}`, tag.id(), filter.id()))
+	env.apply("intune_settings_catalog_policy_settings", nil, fmt.Sprintf(`{
+		"policy_id": %q,
+		"setting": [{
+			"definition_id": "device_vendor_msft_bitlocker_requiredeviceencryption",
+			"value_type": "choice",
+			"value": "device_vendor_msft_bitlocker_requiredeviceencryption_1",
+			"children": [{
+				"definition_id": "device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication",
+				"value_type": "choice",
+				"value": "device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication_0"
+			}]
+		}]
+	}`, policy.id()))
+
+	// Two policies with the same name get distinct resource names
+	compliance := env.apply("intune_compliance_policy", nil, `{
+		"display_name": "Windows baseline",
+		"bitlocker_enabled": true,
+		"password_minimum_length": 12,
+		"valid_os_build_range": [{"description": "Windows 11 23H2", "lowest_version": "10.0.22631", "highest_version": "10.0.22631"}]
+	}`)
+	env.apply("intune_compliance_policy", nil, `{
+		"display_name": "Windows baseline",
+		"description": "${not a template}",
+		"scheduled_actions_for_rule": [{"scheduled_action_configurations": [{"action_type": "block", "grace_period_hours": 24}, {"action_type": "retire", "grace_period_hours": 720}]}]
+	}`)
+	env.apply("intune_compliance_policy", nil, `{"display_name": "Mac baseline", "macos": [{"storage_require_encryption": true, "os_minimum_version": "14.0"}]}`)
+	script := env.apply("intune_device_compliance_script", nil, `{"display_name": "BIOS version", "detection_script_content": "return '{}'", "run_as_32_bit": true}`)
+	env.apply("intune_compliance_policy", nil, fmt.Sprintf(`{
+		"display_name": "BIOS",
+		"custom_compliance": [{"script_id": %q, "rules_json": %q}]
+	}`, script.id(), testComplianceRules))
+
+	linux := env.apply("intune_linux_compliance_policy", nil, `{
+		"name": "Linux baseline",
+		"setting": [{"definition_id": "linux_deviceencryption_required", "value_type": "choice", "value": "linux_deviceencryption_required_1"}],
+		"scheduled_actions_for_rule": [{"scheduled_action_configurations": [{"action_type": "block", "grace_period_hours": 24}]}],
+		"assignment": [{"target": [{"type": "all_devices"}]}]
+	}`)
+
+	if _, err := env.client().Post(env.ctx, clients.PathSettingsCompliancePolicies, map[string]interface{}{
+		"name": "Mac settings", "platforms": "macOS", "technologies": "mdm", "settings": []interface{}{},
+		"scheduledActionsForRule": []interface{}{map[string]interface{}{"ruleName": "PasswordRequired", "scheduledActionConfigurations": []interface{}{map[string]interface{}{"actionType": "block"}}}},
+	}); err != nil {
+		t.Fatalf("seeding compliance policy: %s", err)
+	}
+
+	intent := env.apply("intune_endpoint_security_policy", nil, `{
+		"display_name": "Firewall",
+		"template_id": "4356d05c-a4ab-4a07-9ece-739f7c792910",
+		"settings_json": "{}"
+	}`)
+	if _, err := env.client().Post(env.ctx, fmt.Sprintf("/deviceManagement/intents/%s/updateSettings", intent.id()), map[string]interface{}{
+		"settings": []interface{}{map[string]interface{}{"definitionId": "deviceConfiguration--windows10EndpointProtectionConfiguration_firewallBlockStatefulFTP", "valueJson": "true"}},
+	}); err != nil {
+		t.Fatalf("seeding intent settings: %s", err)
+	}
+
+	env.graph.AddPolicyTemplate(policyTemplate("de-1", "endpointSecurityDiskEncryption", 1, testRequireEncryption))
+	bitlocker := env.apply("intune_endpoint_security_configuration_policy", nil, `{
+		"name": "BitLocker",
+		"template_type": "diskEncryption",
+		"setting": [{
+			"definition_id": "device_vendor_msft_bitlocker_requiredeviceencryption",
+			"value_type": "choice",
+			"value": "device_vendor_msft_bitlocker_requiredeviceencryption_1"
+		}]
+	}`)
+
+	var out bytes.Buffer
+	if err := NewExporter(env.client()).Export(env.ctx, &out); err != nil {
+		t.Fatalf("Export: %s", err)
+	}
+	hcl := out.String()
+
+	// Attribute alignment depends on the other attributes of a block, compare without it
+	collapse := func(s string) string { return strings.Join(strings.Fields(s), " ") }
+
+	for _, want := range []string{
+		`resource "intune_scope_tag" "helpdesk_eu" {`,
+		`  description  = "First line"`,
+		fmt.Sprintf("import {\n  to = intune_scope_tag.helpdesk_eu\n  id = %q\n}", tag.id()),
+		`  rule            = "(device.deviceOwnership -eq \"Corporate\")"`,
+		`  role_scope_tags = [intune_scope_tag.helpdesk_eu.id]`,
+		`  role_scope_tag_ids = [intune_scope_tag.helpdesk_eu.id]`,
+		"  assignment {\n    target {\n      type        = \"group\"\n      group_id    = \"00000000-0000-0000-0000-000000000001\"\n      filter_id   = intune_assignment_filter.corporate_devices.id\n      filter_type = \"include\"\n    }\n    target {\n      type     = \"exclusion\"\n      group_id = \"00000000-0000-0000-0000-000000000002\"\n    }\n  }",
+		`  policy_id = intune_settings_catalog_policy.defender_baseline.id`,
+		"  setting {\n    definition_id = \"device_vendor_msft_bitlocker_requiredeviceencryption\"",
+		"    children {\n      definition_id = \"device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication\"",
+		fmt.Sprintf("import {\n  to = intune_settings_catalog_policy_settings.defender_baseline\n  id = %q\n}", policy.id()),
+		fmt.Sprintf("import {\n  to = intune_compliance_policy.windows_baseline\n  id = %q\n}", compliance.id()),
+		`resource "intune_compliance_policy" "windows_baseline_2" {`,
+		`  password_minimum_length = 12`,
+		`  bitlocker_enabled       = true`,
+		"  valid_os_build_range {\n    description = \"Windows 11 23H2\"\n    lowest_version = \"10.0.22631.0\"\n    highest_version = \"10.0.22631.65535\"\n  }",
+		`  description  = "$${not a template}"`,
+		"  scheduled_actions_for_rule {\n    rule_name = \"DeviceNotCompliant\"\n    scheduled_action_configurations {\n      action_type = \"block\"\n      grace_period_hours = 24\n    }\n    scheduled_action_configurations {\n      action_type = \"retire\"\n      grace_period_hours = 720\n    }\n  }",
+		"resource \"intune_compliance_policy\" \"mac_baseline\" {\n  display_name = \"Mac baseline\"\n  macos {\n    storage_require_encryption = true\n    os_minimum_version = \"14.0\"\n  }\n}",
+		"resource \"intune_device_compliance_script\" \"bios_version\" {\n  display_name = \"BIOS version\"\n  detection_script_content = \"return '{}'\"\n  run_as_32_bit = true\n}",
+		"  custom_compliance {\n    script_id  = intune_device_compliance_script.bios_version.id",
+		"resource \"intune_linux_compliance_policy\" \"linux_baseline\" {\n  name = \"Linux baseline\"\n  setting {\n    definition_id = \"linux_deviceencryption_required\"\n    value_type = \"choice\"\n    value = \"linux_deviceencryption_required_1\"\n  }\n  scheduled_actions_for_rule {\n    rule_name = \"DeviceNotCompliant\"\n    scheduled_action_configurations {\n      action_type = \"block\"\n      grace_period_hours = 24\n    }\n  }\n  assignment {\n    target {\n      type = \"all_devices\"\n    }\n  }\n}",
+		fmt.Sprintf("import {\n  to = intune_linux_compliance_policy.linux_baseline\n  id = %q\n}", linux.id()),
+		`# Skipped compliance policy "Mac settings": platform macOS is not supported`,
+		`resource "intune_endpoint_security_configuration_policy" "bitlocker" {`,
+		`  template_id = "de-1"`,
+		fmt.Sprintf("import {\n  to = intune_endpoint_security_configuration_policy.bitlocker\n  id = %q\n}", bitlocker.id()),
+		`  settings_json = "{\"deviceConfiguration--windows10EndpointProtectionConfiguration_firewallBlockStatefulFTP\":true}"`,
+	} {
+		if !strings.Contains(collapse(hcl), collapse(want)) {
+			t.Errorf("expected the export to contain:\n%s\n\ngot:\n%s", want, hcl)
+		}
+	}
+
+	// Template-backed endpoint security policies are not exported as Settings Catalog policies
+	if strings.Contains(hcl, `resource "intune_settings_catalog_policy" "bitlocker"`) {
+		t.Errorf("expected the endpoint security policy to be exported once, got:\n%s", hcl)
+	}
+
+	// The built-in default scope tag is referenced by ID and not exported
+	if strings.Contains(hcl, `"Default"`) {
+		t.Errorf("expected the built-in scope tag to be left out, got:\n%s", hcl)
+	}
+}
+
+func TestHCLIdentifier(t *testing.T) {
+	for name, want := range map[string]string{
+		"Defender baseline":    "defender_baseline",
+		"  Win10 -- BitLocker": "win10_bitlocker",
+		"2024 Baseline":        "_2024_baseline",
+		"Überprüfung":          "berpr_fung",
+		"!!!":                  "unnamed",
+	} {
+		if got := hclIdentifier(name); got != want {
+			t.Errorf("hclIdentifier(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// DefaultScopeTagID is the ID of the built-in Default scope tag, which Intune applies to objects
+// created without explicit scope tags
+const DefaultScopeTagID = "0"
+
+// optionalStringValue returns the API value for an optional attribute. An empty API value keeps
+// a null attribute null, so leaving the attribute unset does not produce a diff.
+func optionalStringValue(current types.String, value string) types.String {
+	if value == "" && current.IsNull() {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
+
+// roleScopeTagIdsValue returns the API scope tags for an optional role_scope_tag_ids attribute.
+// Only the Default scope tag is implied when the attribute is unset, so it keeps a null attribute null.
+func roleScopeTagIdsValue(ctx context.Context, current types.List, ids []string, diags *diag.Diagnostics) types.List {
+	if current.IsNull() && (len(ids) == 0 || (len(ids) == 1 && ids[0] == DefaultScopeTagID)) {
+		return types.ListNull(types.StringType)
+	}
+
+	list, d := types.ListValueFrom(ctx, types.StringType, ids)
+	diags.Append(d...)
+	return list
+}
+
+// dateTimeAttribute parses a configured RFC 3339 date-time. It reports false for values that are
+// unset, unknown or invalid, adding an error for invalid values.
+func dateTimeAttribute(value types.String, p path.Path, diags *diag.Diagnostics) (time.Time, bool) {
+	if value.IsNull() || value.IsUnknown() {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			p,
+			"Invalid Date",
+			fmt.Sprintf("%q is not an RFC 3339 date-time, e.g. 2026-11-02T00:00:00Z.", value.ValueString()),
+		)
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// normalizeDateTime returns the UTC form Graph takes date-times in. Values that are not RFC 3339
+// date-times are returned as given.
+func normalizeDateTime(s string) string {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// dateTimeValue converts a date-time read from Graph, keeping the configured spelling of the
+// same instant
+func dateTimeValue(current types.String, value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+	if !current.IsNull() && !current.IsUnknown() && normalizeDateTime(current.ValueString()) == normalizeDateTime(value) {
+		return current
+	}
+	return types.StringValue(value)
+}
@@ -0,0 +1,394 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// omaSettingODataTypes maps the value types of OMA-URI settings to their OData types
+var omaSettingODataTypes = map[string]string{
+	"string":         "#microsoft.graph.omaSettingString",
+	"integer":        "#microsoft.graph.omaSettingInteger",
+	"boolean":        "#microsoft.graph.omaSettingBoolean",
+	"base64":         "#microsoft.graph.omaSettingBase64",
+	"xml":            "#microsoft.graph.omaSettingStringXml",
+	"date_time":      "#microsoft.graph.omaSettingDateTime",
+	"floating_point": "#microsoft.graph.omaSettingFloatingPoint",
+}
+
+// omaSettingValueTypes are the value types of OMA-URI settings, in documentation order
+var omaSettingValueTypes = []string{"string", "integer", "boolean", "base64", "xml", "date_time", "floating_point"}
+
+// OmaSettingModel represents an oma_setting block
+type OmaSettingModel struct {
+	DisplayName types.String `tfsdk:"display_name"`
+	Description types.String `tfsdk:"description"`
+	OmaURI      types.String `tfsdk:"oma_uri"`
+	ValueType   types.String `tfsdk:"value_type"`
+	Value       types.String `tfsdk:"value"`
+	SecretValue types.String `tfsdk:"secret_value"`
+
+	// SecretValueSHA256 is the hash of the plain text of an encrypted setting in Intune
+	SecretValueSHA256 types.String `tfsdk:"secret_value_sha256"`
+}
+
+// omaSettingAttrTypes are the attribute types of an oma_setting block
+var omaSettingAttrTypes = map[string]attr.Type{
+	"display_name":        types.StringType,
+	"description":         types.StringType,
+	"oma_uri":             types.StringType,
+	"value_type":          types.StringType,
+	"value":               types.StringType,
+	"secret_value":        types.StringType,
+	"secret_value_sha256": types.StringType,
+}
+
+// omaSettingBlockSchema returns the schema for oma_setting blocks
+func omaSettingBlockSchema() schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		Description: "An OMA-URI setting. Specify one block per setting.",
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"display_name": schema.StringAttribute{
+					Description: "The display name of the setting.",
+					Required:    true,
+				},
+				"description": schema.StringAttribute{
+					Description: "The description of the setting.",
+					Optional:    true,
+				},
+				"oma_uri": schema.StringAttribute{
+					Description: "The OMA-URI of the setting, e.g. ./Device/Vendor/MSFT/Policy/Config/Start/HideSleep.",
+					Required:    true,
+				},
+				"value_type": schema.StringAttribute{
+					Description: "The type of the value. Valid values: string, integer, boolean, base64, xml, date_time " +
+						"(RFC 3339), floating_point.",
+					Required: true,
+					Validators: []validator.String{
+						stringvalidator.OneOf(omaSettingValueTypes...),
+					},
+				},
+				"value": schema.StringAttribute{
+					Description: "The value of the setting, formatted as a string. Integer, boolean, date-time and " +
+						"floating point values are converted; xml values are the XML document itself. Conflicts with secret_value.",
+					Optional: true,
+				},
+				"secret_value": schema.StringAttribute{
+					Description: "The value of a string setting that Intune stores encrypted. The value is hidden in " +
+						"plans and never read back into state; changes made in Intune are detected through " +
+						"secret_value_sha256. Conflicts with value.",
+					Optional:  true,
+					Sensitive: true,
+				},
+				"secret_value_sha256": schema.StringAttribute{
+					Description: "The SHA-256 hash of the plain text of an encrypted setting in Intune, read through " +
+						"getOmaSettingPlainTextValue.",
+					Computed: true,
+					PlanModifiers: []planmodifier.String{
+						omaSecretValueHashModifier{},
+					},
+				},
+			},
+		},
+	}
+}
+
+// omaSettingValue converts the string value of an OMA-URI setting to the JSON value of its type
+func omaSettingValue(valueType, value string) (interface{}, error) {
+	switch valueType {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a 32-bit integer", value)
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return b, nil
+	case "floating_point":
+		f, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a floating point number", value)
+		}
+		return float32(f), nil
+	case "date_time":
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an RFC 3339 date-time", value)
+		}
+		return t.UTC().Format(time.RFC3339Nano), nil
+	case "base64":
+		if _, err := base64.StdEncoding.DecodeString(value); err != nil {
+			return nil, fmt.Errorf("value is not base64 encoded: %s", err)
+		}
+		return value, nil
+	case "xml":
+		// Graph takes XML settings as binary, which is base64 encoded in JSON
+		if err := checkXML(value); err != nil {
+			return nil, fmt.Errorf("value is not well-formed XML: %s", err)
+		}
+		return base64.StdEncoding.EncodeToString([]byte(value)), nil
+	default:
+		return value, nil
+	}
+}
+
+// checkXML returns an error if s is not a well-formed XML document
+func checkXML(s string) error {
+	decoder := xml.NewDecoder(strings.NewReader(s))
+	elements := 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			elements++
+		}
+	}
+	if elements == 0 {
+		return fmt.Errorf("no root element")
+	}
+	return nil
+}
+
+// formatOmaSettingValue formats the JSON value of an OMA-URI setting as a string
+func formatOmaSettingValue(valueType string, value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		if valueType == "xml" {
+			if decoded, err := base64.StdEncoding.DecodeString(v); err == nil {
+				return string(decoded)
+			}
+		}
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// sameOmaSettingValue reports whether two string values of an OMA-URI setting are equivalent
+func sameOmaSettingValue(valueType, a, b string) bool {
+	if a == b {
+		return true
+	}
+	va, errA := omaSettingValue(valueType, a)
+	vb, errB := omaSettingValue(valueType, b)
+	return errA == nil && errB == nil && va == vb
+}
+
+// validateOmaSettings checks that each OMA-URI setting has one value that matches its value type
+func validateOmaSettings(settings []OmaSettingModel, p path.Path, diags *diag.Diagnostics) {
+	for i, setting := range settings {
+		if setting.ValueType.IsUnknown() || setting.Value.IsUnknown() || setting.SecretValue.IsUnknown() {
+			continue
+		}
+		if setting.Value.IsNull() == setting.SecretValue.IsNull() {
+			diags.AddAttributeError(
+				p.AtListIndex(i),
+				"Invalid OMA-URI Setting",
+				fmt.Sprintf("Setting %s must have exactly one of value and secret_value.", setting.OmaURI.ValueString()),
+			)
+			continue
+		}
+		if !setting.SecretValue.IsNull() {
+			if setting.ValueType.ValueString() != "string" {
+				diags.AddAttributeError(
+					p.AtListIndex(i).AtName("secret_value"),
+					"Invalid OMA-URI Setting",
+					fmt.Sprintf("Setting %s has a secret_value, which is only supported for string settings.", setting.OmaURI.ValueString()),
+				)
+			}
+			continue
+		}
+		if _, err := omaSettingValue(setting.ValueType.ValueString(), setting.Value.ValueString()); err != nil {
+			diags.AddAttributeError(
+				p.AtListIndex(i).AtName("value"),
+				"Invalid OMA-URI Setting Value",
+				fmt.Sprintf("Setting %s has an invalid %s value: %s", setting.OmaURI.ValueString(), setting.ValueType.ValueString(), err),
+			)
+		}
+	}
+}
+
+// buildOmaSettings builds the omaSettings of a custom profile. Secret values are sent in plain
+// text with isEncrypted, and Intune encrypts them.
+func buildOmaSettings(settings []OmaSettingModel) ([]interface{}, error) {
+	result := make([]interface{}, 0, len(settings))
+	for _, setting := range settings {
+		var value interface{}
+		encrypted := !setting.SecretValue.IsNull()
+		if encrypted {
+			value = setting.SecretValue.ValueString()
+		} else {
+			var err error
+			value, err = omaSettingValue(setting.ValueType.ValueString(), setting.Value.ValueString())
+			if err != nil {
+				return nil, fmt.Errorf("setting %s: %w", setting.OmaURI.ValueString(), err)
+			}
+		}
+		result = append(result, map[string]interface{}{
+			"@odata.type": omaSettingODataTypes[setting.ValueType.ValueString()],
+			"displayName": setting.DisplayName.ValueString(),
+			"description": setting.Description.ValueString(),
+			"omaUri":      setting.OmaURI.ValueString(),
+			"value":       value,
+			"isEncrypted": encrypted,
+		})
+	}
+	return result, nil
+}
+
+// resolveOmaSettingSecrets replaces the cipher text of encrypted OMA-URI settings read from a
+// profile with their plain text, which Graph only returns through getOmaSettingPlainTextValue
+func resolveOmaSettingSecrets(ctx context.Context, client *clients.GraphClient, profileId string, raw []interface{}) error {
+	for _, item := range raw {
+		setting, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		encrypted, _ := setting["isEncrypted"].(bool)
+		secretId, _ := setting["secretReferenceValueId"].(string)
+		if !encrypted || secretId == "" {
+			continue
+		}
+		value, err := client.GetOmaSettingPlainTextValue(ctx, profileId, secretId)
+		if err != nil {
+			return fmt.Errorf("setting %v: %w", setting["omaUri"], err)
+		}
+		setting["value"] = value
+	}
+	return nil
+}
+
+// omaSettingModels converts the omaSettings of a profile into oma_setting blocks. Settings of
+// value types the provider does not support are left out. Encrypted settings must have been
+// resolved with resolveOmaSettingSecrets; only the hash of their plain text is kept.
+func omaSettingModels(prior []OmaSettingModel, raw []interface{}) []OmaSettingModel {
+	var result []OmaSettingModel
+	for _, item := range raw {
+		setting, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		odataType, _ := setting["@odata.type"].(string)
+		valueType := ""
+		for t, settingType := range omaSettingODataTypes {
+			if settingType == odataType {
+				valueType = t
+			}
+		}
+		if valueType == "" {
+			continue
+		}
+
+		displayName, _ := setting["displayName"].(string)
+		description, _ := setting["description"].(string)
+		omaURI, _ := setting["omaUri"].(string)
+		value := types.StringValue(formatOmaSettingValue(valueType, setting["value"]))
+		model := OmaSettingModel{
+			DisplayName: types.StringValue(displayName),
+			Description: types.StringValue(description),
+			OmaURI:      types.StringValue(omaURI),
+			ValueType:   types.StringValue(valueType),
+			Value:       value,
+			SecretValue: types.StringNull(),
+		}
+		model.SecretValueSHA256 = types.StringNull()
+		if encrypted, _ := setting["isEncrypted"].(bool); encrypted {
+			model.Value = types.StringNull()
+			model.SecretValueSHA256 = omaSecretValueHash(value.ValueString())
+		}
+
+		// Keep the configured spelling of equivalent values, such as TRUE for true
+		if i := len(result); i < len(prior) {
+			model.Description = optionalStringValue(prior[i].Description, description)
+			// The plain text of a secret is not stored; the configured value is kept while its
+			// hash matches Intune, and cleared when the secret was changed outside of OpenTofu
+			if !prior[i].SecretValue.IsNull() && omaSecretValueHash(prior[i].SecretValue.ValueString()).Equal(model.SecretValueSHA256) {
+				model.SecretValue = prior[i].SecretValue
+			}
+			if prior[i].ValueType.Equal(model.ValueType) && !model.Value.IsNull() && sameOmaSettingValue(valueType, prior[i].Value.ValueString(), model.Value.ValueString()) {
+				model.Value = prior[i].Value
+			}
+		} else if description == "" {
+			model.Description = types.StringNull()
+		}
+		result = append(result, model)
+	}
+	return result
+}
+
+// omaSecretValueHash returns the hex encoded SHA-256 hash of the plain text of a secret value
+func omaSecretValueHash(value string) types.String {
+	sum := sha256.Sum256([]byte(value))
+	return types.StringValue(hex.EncodeToString(sum[:]))
+}
+
+// omaSecretValueHashModifier plans secret_value_sha256 as the hash of the configured secret_value
+type omaSecretValueHashModifier struct{}
+
+// Description describes the plan modification
+func (m omaSecretValueHashModifier) Description(ctx context.Context) string {
+	return "Plans the hash of the configured secret value."
+}
+
+// MarkdownDescription describes the plan modification in Markdown
+func (m omaSecretValueHashModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+// PlanModifyString sets the planned hash from the secret_value of the same setting
+func (m omaSecretValueHashModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var secret types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, req.Path.ParentPath().AtName("secret_value"), &secret)...)
+	if resp.Diagnostics.HasError() || secret.IsUnknown() {
+		return
+	}
+	if secret.IsNull() {
+		resp.PlanValue = types.StringNull()
+		return
+	}
+	resp.PlanValue = omaSecretValueHash(secret.ValueString())
+}
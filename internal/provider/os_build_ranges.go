@@ -0,0 +1,261 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// OsBuildRangeModel represents a valid_os_build_range block
+type OsBuildRangeModel struct {
+	Description    types.String `tfsdk:"description"`
+	LowestVersion  types.String `tfsdk:"lowest_version"`
+	HighestVersion types.String `tfsdk:"highest_version"`
+}
+
+// osBuildRangeAttrTypes are the attribute types of a valid_os_build_range block
+var osBuildRangeAttrTypes = map[string]attr.Type{
+	"description":     types.StringType,
+	"lowest_version":  types.StringType,
+	"highest_version": types.StringType,
+}
+
+// Windows versions are major.minor.build with an optional revision, e.g. 10.0.22631.2506
+var (
+	windowsVersionPattern        = regexp.MustCompile(`^\d+\.\d+\.\d+(\.\d+)?$`)
+	windowsVersionPatternMessage = "must be a Windows version such as 10.0.22631 or 10.0.22631.2506"
+)
+
+// maxWindowsRevision is the revision that completes a version without revision as upper bound
+const maxWindowsRevision = 65535
+
+// windowsVersion is a parsed Windows version: major, minor, build and revision
+type windowsVersion [4]uint64
+
+// parseWindowsVersion parses a Windows version. A version without revision covers every revision
+// of its build: it is completed with revision 0 as lower bound, and with the highest revision as
+// upper bound.
+func parseWindowsVersion(s string, upper bool) (windowsVersion, bool) {
+	var v windowsVersion
+	if !windowsVersionPattern.MatchString(s) {
+		return v, false
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) == 3 && upper {
+		v[3] = maxWindowsRevision
+	}
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// String returns the four-part form of the version
+func (v windowsVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", v[0], v[1], v[2], v[3])
+}
+
+// compare returns -1, 0 or 1 when the version is lower than, equal to or higher than o
+func (v windowsVersion) compare(o windowsVersion) int {
+	for i := range v {
+		switch {
+		case v[i] < o[i]:
+			return -1
+		case v[i] > o[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// normalizeWindowsVersion returns the four-part form Graph stores a Windows version in. Values
+// that are not Windows versions are returned as given.
+func normalizeWindowsVersion(s string, upper bool) string {
+	if v, ok := parseWindowsVersion(s, upper); ok {
+		return v.String()
+	}
+	return s
+}
+
+// windowsVersionValue converts a Windows version read from Graph, keeping the configured spelling
+// of the same version
+func windowsVersionValue(current types.String, value string, upper bool) types.String {
+	if !current.IsNull() && !current.IsUnknown() && normalizeWindowsVersion(current.ValueString(), upper) == normalizeWindowsVersion(value, upper) {
+		return current
+	}
+	return types.StringValue(value)
+}
+
+// osBuildRangeBlockSchema returns the schema for the valid_os_build_range blocks of compliance policies
+func osBuildRangeBlockSchema() schema.ListNestedBlock {
+	versionValidators := []validator.String{
+		stringvalidator.RegexMatches(windowsVersionPattern, windowsVersionPatternMessage),
+	}
+	return schema.ListNestedBlock{
+		Description: "A range of Windows builds devices may run, e.g. one per supported feature update. Devices " +
+			"must run a build of one of the ranges. Ranges must not overlap. Windows only.",
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"description": schema.StringAttribute{
+					Description: "A description of the range, e.g. Windows 11 23H2.",
+					Optional:    true,
+				},
+				"lowest_version": schema.StringAttribute{
+					Description: "The lowest version of the range, e.g. 10.0.22631.2506. A version without revision " +
+						"starts at revision 0 of its build. Intune stores 10.0.22631 as 10.0.22631.0; the configured " +
+						"form is kept in plans and state.",
+					Required:   true,
+					Validators: versionValidators,
+				},
+				"highest_version": schema.StringAttribute{
+					Description: "The highest version of the range, e.g. 10.0.22631.9999. A version without revision " +
+						"includes every revision of its build. Intune stores 10.0.22631 as 10.0.22631.65535; the " +
+						"configured form is kept in plans and state.",
+					Required:   true,
+					Validators: versionValidators,
+				},
+			},
+		},
+	}
+}
+
+// validateWindowsVersions checks that os_minimum_version is not higher than os_maximum_version,
+// and that every valid_os_build_range block is ordered and does not overlap another one
+func validateWindowsVersions(ctx context.Context, data *CompliancePolicyResourceModel, diags *diag.Diagnostics) {
+	if !data.OsMinimumVersion.IsUnknown() && !data.OsMaximumVersion.IsUnknown() {
+		minimum, minOK := parseWindowsVersion(data.OsMinimumVersion.ValueString(), false)
+		maximum, maxOK := parseWindowsVersion(data.OsMaximumVersion.ValueString(), true)
+		if minOK && maxOK && minimum.compare(maximum) > 0 {
+			diags.AddAttributeError(
+				path.Root("os_minimum_version"),
+				"Invalid OS Version Range",
+				fmt.Sprintf("os_minimum_version %s is higher than os_maximum_version %s.", data.OsMinimumVersion.ValueString(), data.OsMaximumVersion.ValueString()),
+			)
+		}
+	}
+
+	if data.ValidOsBuildRanges.IsUnknown() || len(data.ValidOsBuildRanges.Elements()) == 0 {
+		return
+	}
+
+	var blocks []OsBuildRangeModel
+	diags.Append(data.ValidOsBuildRanges.ElementsAs(ctx, &blocks, false)...)
+
+	type buildRange struct {
+		index           int
+		lowest, highest windowsVersion
+	}
+	var ranges []buildRange
+	for i, block := range blocks {
+		lowest, lowOK := parseWindowsVersion(block.LowestVersion.ValueString(), false)
+		highest, highOK := parseWindowsVersion(block.HighestVersion.ValueString(), true)
+		if !lowOK || !highOK {
+			continue
+		}
+		if lowest.compare(highest) > 0 {
+			diags.AddAttributeError(
+				path.Root("valid_os_build_range").AtListIndex(i),
+				"Invalid OS Build Range",
+				fmt.Sprintf("lowest_version %s is higher than highest_version %s.", block.LowestVersion.ValueString(), block.HighestVersion.ValueString()),
+			)
+			continue
+		}
+		ranges = append(ranges, buildRange{index: i, lowest: lowest, highest: highest})
+	}
+
+	// Compare each range with the range reaching highest among the ones starting before it, which
+	// is not necessarily the previous one: 10.0.1 - 10.0.100 contains both 10.0.2 - 10.0.3 and
+	// 10.0.50 - 10.0.60
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].lowest.compare(ranges[j].lowest) < 0 })
+	for i, widest := 1, 0; i < len(ranges); i++ {
+		previous, current := ranges[widest], ranges[i]
+		if current.lowest.compare(previous.highest) <= 0 {
+			diags.AddAttributeError(
+				path.Root("valid_os_build_range").AtListIndex(current.index),
+				"Overlapping OS Build Ranges",
+				fmt.Sprintf("The range %s - %s overlaps the range %s - %s.", current.lowest, current.highest, previous.lowest, previous.highest),
+			)
+		}
+		if current.highest.compare(previous.highest) > 0 {
+			widest = i
+		}
+	}
+}
+
+// buildOsBuildRanges converts valid_os_build_range blocks into the normalized build ranges of a
+// compliance policy
+func buildOsBuildRanges(ctx context.Context, list types.List, diags *diag.Diagnostics) []clients.OperatingSystemVersionRange {
+	var blocks []OsBuildRangeModel
+	if len(list.Elements()) > 0 {
+		diags.Append(list.ElementsAs(ctx, &blocks, false)...)
+	}
+
+	ranges := make([]clients.OperatingSystemVersionRange, 0, len(blocks))
+	for _, block := range blocks {
+		ranges = append(ranges, clients.OperatingSystemVersionRange{
+			Description:    block.Description.ValueString(),
+			LowestVersion:  normalizeWindowsVersion(block.LowestVersion.ValueString(), false),
+			HighestVersion: normalizeWindowsVersion(block.HighestVersion.ValueString(), true),
+		})
+	}
+	return ranges
+}
+
+// osBuildRangesValue converts the build ranges of a compliance policy into valid_os_build_range
+// blocks. Versions equal to the prior versions keep their prior spelling.
+func osBuildRangesValue(ctx context.Context, prior types.List, ranges []clients.OperatingSystemVersionRange, diags *diag.Diagnostics) types.List {
+	objectType := types.ObjectType{AttrTypes: osBuildRangeAttrTypes}
+	if len(ranges) == 0 {
+		if len(prior.Elements()) == 0 {
+			return prior
+		}
+		return types.ListValueMust(objectType, []attr.Value{})
+	}
+
+	var priorBlocks []OsBuildRangeModel
+	if len(prior.Elements()) > 0 {
+		diags.Append(prior.ElementsAs(ctx, &priorBlocks, false)...)
+	}
+
+	blocks := make([]OsBuildRangeModel, 0, len(ranges))
+	for i, r := range ranges {
+		previous := OsBuildRangeModel{
+			Description:    types.StringNull(),
+			LowestVersion:  types.StringNull(),
+			HighestVersion: types.StringNull(),
+		}
+		if i < len(priorBlocks) {
+			previous = priorBlocks[i]
+		}
+		blocks = append(blocks, OsBuildRangeModel{
+			Description:    optionalStringValue(previous.Description, r.Description),
+			LowestVersion:  windowsVersionValue(previous.LowestVersion, r.LowestVersion, false),
+			HighestVersion: windowsVersionValue(previous.HighestVersion, r.HighestVersion, true),
+		})
+	}
+
+	value, d := types.ListValueFrom(ctx, objectType, blocks)
+	diags.Append(d...)
+	return value
+}
@@ -0,0 +1,41 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"sync"
+)
+
+// policyLocks serializes read-modify-write cycles against a single policy.
+// Terraform applies sibling resources in parallel, so resources that share a
+// policy (for example several intune_settings_catalog_policy_settings blocks
+// pointing at the same policy_id) must not interleave their reads and writes.
+var policyLocks = newKeyedMutex()
+
+// keyedMutex provides one mutex per key
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// newKeyedMutex creates an empty keyed mutex
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{
+		locks: make(map[string]*sync.Mutex),
+	}
+}
+
+// Lock acquires the mutex for the given key and returns the matching unlock function
+func (k *keyedMutex) Lock(key string) func() {
+	k.mu.Lock()
+	lock, ok := k.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		k.locks[key] = lock
+	}
+	k.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
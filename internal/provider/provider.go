@@ -7,12 +7,17 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
@@ -25,6 +30,10 @@ var _ provider.Provider = &IntuneProvider{}
 // IntuneProvider defines the provider implementation
 type IntuneProvider struct {
 	version string
+
+	// clientOptions, when set, replaces authentication and endpoint discovery.
+	// It is used by the acceptance tests to point the provider at a local Graph server.
+	clientOptions *clients.GraphClientOptions
 }
 
 // IntuneProviderModel describes the provider data model
@@ -47,23 +56,31 @@ type IntuneProviderModel struct {
 	UseAzureCLI types.Bool `tfsdk:"use_cli"`
 
 	// OIDC authentication
-	UseOIDC           types.Bool   `tfsdk:"use_oidc"`
-	OIDCToken         types.String `tfsdk:"oidc_token"`
-	OIDCTokenFilePath types.String `tfsdk:"oidc_token_file_path"`
-	OIDCRequestURL    types.String `tfsdk:"oidc_request_url"`
-	OIDCRequestToken  types.String `tfsdk:"oidc_request_token"`
+	UseOIDC                types.Bool   `tfsdk:"use_oidc"`
+	OIDCToken              types.String `tfsdk:"oidc_token"`
+	OIDCTokenFilePath      types.String `tfsdk:"oidc_token_file_path"`
+	OIDCRequestURL         types.String `tfsdk:"oidc_request_url"`
+	OIDCRequestToken       types.String `tfsdk:"oidc_request_token"`
+	ADOServiceConnectionID types.String `tfsdk:"ado_pipeline_service_connection_id"`
 
 	// Multi-tenant
 	AuxiliaryTenantIDs types.List `tfsdk:"auxiliary_tenant_ids"`
 
 	// Metadata
 	MetadataHost types.String `tfsdk:"metadata_host"`
+
+	// Retry behaviour
+	MaxRetries   types.Int64 `tfsdk:"max_retries"`
+	MaxRetryWait types.Int64 `tfsdk:"max_retry_wait"`
 }
 
 // ProviderData contains the configured clients for resources
 type ProviderData struct {
 	GraphClient *clients.GraphClient
 	Auth        *clients.Authenticator
+
+	// SettingDefinitions caches Settings Catalog definitions used for plan-time validation
+	SettingDefinitions *SettingDefinitionCache
 }
 
 // New creates a new provider instance
@@ -113,9 +130,13 @@ The following Microsoft Graph API permissions are required:
 				Optional:    true,
 			},
 			"environment": schema.StringAttribute{
-				Description: "The cloud environment to use. Possible values are: public, usgovernment, china. Defaults to public. " +
+				Description: "The cloud environment to use. Possible values are: public, usgovernment, usgovernmentl4, usgovernmentl5, china. " +
+					"usgovernment is an alias for usgovernmentl4 (GCC High), usgovernmentl5 selects the DoD cloud. Defaults to public. " +
 					"This can also be sourced from the ARM_ENVIRONMENT environment variable.",
 				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOfCaseInsensitive(clients.CloudEnvironmentNames()...),
+				},
 			},
 			"client_id": schema.StringAttribute{
 				Description: "The Client ID which should be used for service principal authentication. " +
@@ -171,24 +192,48 @@ The following Microsoft Graph API permissions are required:
 				Optional: true,
 			},
 			"oidc_request_url": schema.StringAttribute{
-				Description: "The URL for the OIDC provider (GitHub Actions). " +
-					"This can also be sourced from the ACTIONS_ID_TOKEN_REQUEST_URL environment variable.",
+				Description: "The URL for the OIDC provider (GitHub Actions or Azure DevOps). " +
+					"This can also be sourced from the ARM_OIDC_REQUEST_URL, ACTIONS_ID_TOKEN_REQUEST_URL or SYSTEM_OIDCREQUESTURI environment variables.",
 				Optional: true,
 			},
 			"oidc_request_token": schema.StringAttribute{
-				Description: "The bearer token for the OIDC provider (GitHub Actions). " +
-					"This can also be sourced from the ACTIONS_ID_TOKEN_REQUEST_TOKEN environment variable.",
+				Description: "The bearer token for the OIDC provider (GitHub Actions or Azure DevOps). " +
+					"This can also be sourced from the ARM_OIDC_REQUEST_TOKEN, ACTIONS_ID_TOKEN_REQUEST_TOKEN or SYSTEM_ACCESSTOKEN environment variables.",
 				Optional:  true,
 				Sensitive: true,
 			},
+			"ado_pipeline_service_connection_id": schema.StringAttribute{
+				Description: "The ID of the Azure DevOps service connection to request an OIDC token for. " +
+					"Setting this selects Azure DevOps Pipelines OIDC instead of GitHub Actions. " +
+					"This can also be sourced from the ARM_ADO_PIPELINE_SERVICE_CONNECTION_ID or ARM_OIDC_AZURE_SERVICE_CONNECTION_ID environment variables.",
+				Optional: true,
+			},
 			"auxiliary_tenant_ids": schema.ListAttribute{
 				Description: "A list of additional Tenant IDs for multi-tenant authentication.",
 				Optional:    true,
 				ElementType: types.StringType,
 			},
 			"metadata_host": schema.StringAttribute{
-				Description: "The hostname which should be used for the Azure Metadata Service.",
-				Optional:    true,
+				Description: "The hostname which should be used for the Azure Metadata Service. When set, the login and Microsoft Graph " +
+					"endpoints are discovered from this host instead of the built-in environment. " +
+					"This can also be sourced from the ARM_METADATA_HOSTNAME environment variable.",
+				Optional: true,
+			},
+			"max_retries": schema.Int64Attribute{
+				Description: "The maximum number of times a throttled (429/503) or transiently failing Microsoft Graph request is retried. " +
+					"Set to 0 to disable retries. Defaults to 5.",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"max_retry_wait": schema.Int64Attribute{
+				Description: "The maximum number of seconds to wait between two attempts of a retried Microsoft Graph request. " +
+					"Retry-After delays requested by the service are capped to this value. Defaults to 60.",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
 			},
 		},
 	}
@@ -204,7 +249,94 @@ func (p *IntuneProvider) Configure(ctx context.Context, req provider.ConfigureRe
 		return
 	}
 
+	if p.clientOptions != nil {
+		graphClient := clients.NewGraphClient(nil, fmt.Sprintf("TofuTune/%s", p.version), p.clientOptions)
+		providerData := &ProviderData{
+			GraphClient:        graphClient,
+			SettingDefinitions: NewSettingDefinitionCache(graphClient),
+		}
+		resp.DataSourceData = providerData
+		resp.ResourceData = providerData
+		return
+	}
+
 	// Build authentication configuration from provider config and environment variables
+	authConfig, diags := newAuthConfig(ctx, config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Validate required configuration
+	if authConfig.TenantID == "" && !authConfig.UseAzureCLI {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("tenant_id"),
+			"Missing Tenant ID",
+			"The provider cannot create the Intune API client as there is a missing or empty value for the tenant ID. "+
+				"Set the tenant_id value in the configuration or use the ARM_TENANT_ID environment variable. "+
+				"If using Azure CLI authentication, the tenant can be inferred from the CLI context.",
+		)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Create authenticator
+	auth, err := clients.NewAuthenticator(ctx, authConfig)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Create Authenticator",
+			fmt.Sprintf("An unexpected error occurred when creating the authenticator: %s", err),
+		)
+		return
+	}
+
+	tflog.Debug(ctx, "Authentication configured", map[string]interface{}{
+		"method":      string(auth.GetMethod()),
+		"environment": auth.GetCloud().Name,
+		"graph":       auth.GetCloud().GraphEndpoint,
+	})
+
+	// Create Graph client
+	clientOpts := &clients.GraphClientOptions{
+		MaxRetries:   clients.DefaultMaxRetries,
+		MaxRetryWait: clients.DefaultMaxRetryWait,
+		Cloud:        auth.GetCloud(),
+	}
+	if !config.MaxRetries.IsNull() {
+		clientOpts.MaxRetries = int(config.MaxRetries.ValueInt64())
+	}
+	if !config.MaxRetryWait.IsNull() {
+		clientOpts.MaxRetryWait = time.Duration(config.MaxRetryWait.ValueInt64()) * time.Second
+	}
+
+	userAgent := fmt.Sprintf("TofuTune/%s", p.version)
+	graphClient := clients.NewGraphClient(auth, userAgent, clientOpts)
+
+	// Create provider data
+	providerData := &ProviderData{
+		GraphClient:        graphClient,
+		Auth:               auth,
+		SettingDefinitions: NewSettingDefinitionCache(graphClient),
+	}
+
+	resp.DataSourceData = providerData
+	resp.ResourceData = providerData
+
+	tflog.Info(ctx, "Intune provider configured successfully")
+}
+
+// AuthConfigFromEnv builds the authentication configuration from the ARM_* environment variables
+// alone, as the provider does when none of its authentication attributes are set
+func AuthConfigFromEnv() *clients.AuthConfig {
+	authConfig, _ := newAuthConfig(context.Background(), IntuneProviderModel{})
+	return authConfig
+}
+
+// newAuthConfig builds the authentication configuration from the provider configuration,
+// falling back to the ARM_* environment variables for attributes that are not set
+func newAuthConfig(ctx context.Context, config IntuneProviderModel) (*clients.AuthConfig, diag.Diagnostics) {
 	authConfig := &clients.AuthConfig{}
 
 	// Tenant ID
@@ -292,15 +424,33 @@ func (p *IntuneProvider) Configure(ctx context.Context, req provider.ConfigureRe
 			authConfig.OIDCTokenFilePath = v
 		}
 
+		if !config.ADOServiceConnectionID.IsNull() {
+			authConfig.ADOServiceConnectionID = config.ADOServiceConnectionID.ValueString()
+		} else if v := os.Getenv("ARM_ADO_PIPELINE_SERVICE_CONNECTION_ID"); v != "" {
+			authConfig.ADOServiceConnectionID = v
+		} else if v := os.Getenv("ARM_OIDC_AZURE_SERVICE_CONNECTION_ID"); v != "" {
+			authConfig.ADOServiceConnectionID = v
+		}
+
+		// Azure DevOps exposes the request URL and token under its own variable names
+		requestURLEnv, requestTokenEnv := "ACTIONS_ID_TOKEN_REQUEST_URL", "ACTIONS_ID_TOKEN_REQUEST_TOKEN"
+		if authConfig.ADOServiceConnectionID != "" {
+			requestURLEnv, requestTokenEnv = "SYSTEM_OIDCREQUESTURI", "SYSTEM_ACCESSTOKEN"
+		}
+
 		if !config.OIDCRequestURL.IsNull() {
 			authConfig.OIDCRequestURL = config.OIDCRequestURL.ValueString()
-		} else if v := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL"); v != "" {
+		} else if v := os.Getenv("ARM_OIDC_REQUEST_URL"); v != "" {
+			authConfig.OIDCRequestURL = v
+		} else if v := os.Getenv(requestURLEnv); v != "" {
 			authConfig.OIDCRequestURL = v
 		}
 
 		if !config.OIDCRequestToken.IsNull() {
 			authConfig.OIDCRequestToken = config.OIDCRequestToken.ValueString()
-		} else if v := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN"); v != "" {
+		} else if v := os.Getenv("ARM_OIDC_REQUEST_TOKEN"); v != "" {
+			authConfig.OIDCRequestToken = v
+		} else if v := os.Getenv(requestTokenEnv); v != "" {
 			authConfig.OIDCRequestToken = v
 		}
 	}
@@ -308,61 +458,21 @@ func (p *IntuneProvider) Configure(ctx context.Context, req provider.ConfigureRe
 	// Metadata Host
 	if !config.MetadataHost.IsNull() {
 		authConfig.MetadataHost = config.MetadataHost.ValueString()
+	} else if v := os.Getenv("ARM_METADATA_HOSTNAME"); v != "" {
+		authConfig.MetadataHost = v
 	}
 
 	// Auxiliary Tenant IDs
 	if !config.AuxiliaryTenantIDs.IsNull() {
 		var tenantIDs []string
-		resp.Diagnostics.Append(config.AuxiliaryTenantIDs.ElementsAs(ctx, &tenantIDs, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		diags := config.AuxiliaryTenantIDs.ElementsAs(ctx, &tenantIDs, false)
+		if diags.HasError() {
+			return nil, diags
 		}
 		authConfig.AuxiliaryTenantIDs = tenantIDs
 	}
 
-	// Validate required configuration
-	if authConfig.TenantID == "" && !authConfig.UseAzureCLI {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("tenant_id"),
-			"Missing Tenant ID",
-			"The provider cannot create the Intune API client as there is a missing or empty value for the tenant ID. "+
-				"Set the tenant_id value in the configuration or use the ARM_TENANT_ID environment variable. "+
-				"If using Azure CLI authentication, the tenant can be inferred from the CLI context.",
-		)
-	}
-
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Create authenticator
-	auth, err := clients.NewAuthenticator(ctx, authConfig)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unable to Create Authenticator",
-			fmt.Sprintf("An unexpected error occurred when creating the authenticator: %s", err),
-		)
-		return
-	}
-
-	tflog.Debug(ctx, "Authentication configured", map[string]interface{}{
-		"method": string(auth.GetMethod()),
-	})
-
-	// Create Graph client
-	userAgent := fmt.Sprintf("TofuTune/%s", p.version)
-	graphClient := clients.NewGraphClient(auth, userAgent)
-
-	// Create provider data
-	providerData := &ProviderData{
-		GraphClient: graphClient,
-		Auth:        auth,
-	}
-
-	resp.DataSourceData = providerData
-	resp.ResourceData = providerData
-
-	tflog.Info(ctx, "Intune provider configured successfully")
+	return authConfig, nil
 }
 
 // Resources defines the resources implemented in the provider
@@ -371,8 +481,21 @@ func (p *IntuneProvider) Resources(ctx context.Context) []func() resource.Resour
 		NewSettingsCatalogPolicyResource,
 		NewSettingsCatalogPolicySettingsResource,
 		NewCompliancePolicyResource,
+		NewLinuxCompliancePolicyResource,
+		NewDeviceComplianceScriptResource,
+		NewNotificationMessageTemplateResource,
 		NewEndpointSecurityPolicyResource,
+		NewEndpointSecurityConfigurationPolicyResource,
+		NewDeviceConfigurationResource,
+		NewCustomOmaURIProfileResource,
+		NewWindowsFeatureUpdateProfileResource,
+		NewWindowsQualityUpdateProfileResource,
+		NewWindowsDriverUpdateProfileResource,
+		NewWindowsDriverUpdateApprovalResource,
+		NewAutopilotDeploymentProfileResource,
+		NewEnrollmentStatusPageResource,
 		NewPolicyAssignmentResource,
+		NewPolicyGroupAssignmentResource,
 		NewScopeTagResource,
 		NewAssignmentFilterResource,
 	}
@@ -386,5 +509,7 @@ func (p *IntuneProvider) DataSources(ctx context.Context) []func() datasource.Da
 		NewPolicyDataSource,
 		NewScopeTagsDataSource,
 		NewAssignmentFiltersDataSource,
+		NewEndpointSecurityTemplateDataSource,
+		NewDriverInventoryDataSource,
 	}
 }
@@ -0,0 +1,803 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+// The acceptance tests drive the provider through the plugin protocol, the same way Terraform
+// does, against an in-process fake of Microsoft Graph. They need neither a tenant nor a
+// Terraform binary. Each step mirrors a Terraform operation:
+//
+//   - apply:       validate, plan and apply a configuration, checking the result matches the plan
+//   - assertNoOp:  plan the same configuration again and require an empty plan
+//   - refresh:     read the resource back from the API
+//   - importState: import by ID and read the resource
+//   - destroy:     plan and apply the deletion
+
+// staticTokenSource returns the same access token for every request
+type staticTokenSource string
+
+func (s staticTokenSource) GetToken(ctx context.Context, scopes []string) (string, error) {
+	return string(s), nil
+}
+
+// testEnv is a provider server connected to a fake Graph API
+type testEnv struct {
+	t      *testing.T
+	ctx    context.Context
+	graph  *fakegraph.Server
+	server tfprotov6.ProviderServer
+
+	// clientOptions connects Graph clients to the fake
+	clientOptions *clients.GraphClientOptions
+
+	resourceSchemas   map[string]*tfprotov6.Schema
+	dataSourceSchemas map[string]*tfprotov6.Schema
+}
+
+// testResource is the state of a managed resource between steps
+type testResource struct {
+	typeName string
+	state    tftypes.Value
+	private  []byte
+}
+
+// newTestEnv starts a fake Graph API and a configured provider server using it
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	graph := fakegraph.NewServer()
+	t.Cleanup(graph.Close)
+
+	clientOptions := &clients.GraphClientOptions{
+		BaseURL:      graph.BaseURL(),
+		TokenSource:  staticTokenSource(fakegraph.Token),
+		MaxRetries:   3,
+		MaxRetryWait: 10 * time.Millisecond,
+	}
+	p := &IntuneProvider{
+		version:       "test",
+		clientOptions: clientOptions,
+	}
+
+	e := &testEnv{
+		t:             t,
+		ctx:           context.Background(),
+		graph:         graph,
+		server:        providerserver.NewProtocol6(p)(),
+		clientOptions: clientOptions,
+	}
+
+	schemaResp, err := e.server.GetProviderSchema(e.ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("GetProviderSchema: %s", err)
+	}
+	e.checkDiagnostics("GetProviderSchema", schemaResp.Diagnostics)
+	e.resourceSchemas = schemaResp.ResourceSchemas
+	e.dataSourceSchemas = schemaResp.DataSourceSchemas
+
+	providerConfig := e.value(schemaResp.Provider, "{}")
+	configResp, err := e.server.ConfigureProvider(e.ctx, &tfprotov6.ConfigureProviderRequest{
+		TerraformVersion: "1.9.0",
+		Config:           e.dynamicValue(providerConfig),
+	})
+	if err != nil {
+		t.Fatalf("ConfigureProvider: %s", err)
+	}
+	e.checkDiagnostics("ConfigureProvider", configResp.Diagnostics)
+
+	return e
+}
+
+// client returns a Graph client connected to the fake
+func (e *testEnv) client() *clients.GraphClient {
+	return clients.NewGraphClient(nil, "test", e.clientOptions)
+}
+
+// apply plans and applies a JSON configuration for a resource, starting from prior (nil to create)
+func (e *testEnv) apply(typeName string, prior *testResource, config string) *testResource {
+	e.t.Helper()
+
+	res, diags := e.tryApply(typeName, prior, config)
+	e.checkDiagnostics("apply "+typeName, diags)
+	return res
+}
+
+// applyExpectError applies a configuration and returns the error diagnostics it produced,
+// failing the test if there were none
+func (e *testEnv) applyExpectError(typeName string, prior *testResource, config string) string {
+	e.t.Helper()
+
+	_, diags := e.tryApply(typeName, prior, config)
+	msg := errorSummary(diags)
+	if msg == "" {
+		e.t.Fatalf("apply %s: expected an error, got none", typeName)
+	}
+	return msg
+}
+
+// tryApply runs validate, plan and apply and returns the first error diagnostics encountered
+func (e *testEnv) tryApply(typeName string, prior *testResource, config string) (*testResource, []*tfprotov6.Diagnostic) {
+	e.t.Helper()
+
+	schema := e.resourceSchema(typeName)
+	configValue := e.value(schema, config)
+
+	validateResp, err := e.server.ValidateResourceConfig(e.ctx, &tfprotov6.ValidateResourceConfigRequest{
+		TypeName: typeName,
+		Config:   e.dynamicValue(configValue),
+	})
+	if err != nil {
+		e.t.Fatalf("ValidateResourceConfig: %s", err)
+	}
+	if hasErrors(validateResp.Diagnostics) {
+		return nil, validateResp.Diagnostics
+	}
+
+	priorState := tftypes.NewValue(schema.ValueType(), nil)
+	var priorPrivate []byte
+	if prior != nil {
+		priorState = prior.state
+		priorPrivate = prior.private
+	}
+
+	planned, plannedPrivate, requiresReplace, diags := e.plan(typeName, priorState, priorPrivate, configValue)
+	if hasErrors(diags) {
+		return nil, diags
+	}
+
+	if prior != nil && len(requiresReplace) > 0 {
+		e.destroy(prior)
+		priorState = tftypes.NewValue(schema.ValueType(), nil)
+		priorPrivate = nil
+		planned, plannedPrivate, _, diags = e.plan(typeName, priorState, priorPrivate, configValue)
+		if hasErrors(diags) {
+			return nil, diags
+		}
+	}
+
+	applyResp, err := e.server.ApplyResourceChange(e.ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:       typeName,
+		PriorState:     e.dynamicValue(priorState),
+		PlannedState:   e.dynamicValue(planned),
+		Config:         e.dynamicValue(configValue),
+		PlannedPrivate: plannedPrivate,
+	})
+	if err != nil {
+		e.t.Fatalf("ApplyResourceChange: %s", err)
+	}
+	if hasErrors(applyResp.Diagnostics) {
+		return nil, applyResp.Diagnostics
+	}
+
+	newState := e.unmarshal(schema, applyResp.NewState)
+	if problems := inconsistencies("", planned, newState); len(problems) > 0 {
+		e.t.Fatalf("apply %s: provider produced inconsistent result after apply:\n  %s", typeName, strings.Join(problems, "\n  "))
+	}
+
+	return &testResource{typeName: typeName, state: newState, private: applyResp.Private}, nil
+}
+
+// plan runs PlanResourceChange for a configuration against prior state
+func (e *testEnv) plan(typeName string, prior tftypes.Value, priorPrivate []byte, config tftypes.Value) (tftypes.Value, []byte, []*tftypes.AttributePath, []*tfprotov6.Diagnostic) {
+	e.t.Helper()
+
+	schema := e.resourceSchema(typeName)
+	resp, err := e.server.PlanResourceChange(e.ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       e.dynamicValue(prior),
+		ProposedNewState: e.dynamicValue(proposedNewState(schema.Block, prior, config)),
+		Config:           e.dynamicValue(config),
+		PriorPrivate:     priorPrivate,
+	})
+	if err != nil {
+		e.t.Fatalf("PlanResourceChange: %s", err)
+	}
+	if hasErrors(resp.Diagnostics) {
+		return tftypes.Value{}, nil, nil, resp.Diagnostics
+	}
+
+	return e.unmarshal(schema, resp.PlannedState), resp.PlannedPrivate, resp.RequiresReplace, resp.Diagnostics
+}
+
+// assertNoOp plans the configuration against the current state and fails on any planned change
+func (e *testEnv) assertNoOp(res *testResource, config string) {
+	e.t.Helper()
+
+	schema := e.resourceSchema(res.typeName)
+	planned, _, requiresReplace, diags := e.plan(res.typeName, res.state, res.private, e.value(schema, config))
+	e.checkDiagnostics("plan "+res.typeName, diags)
+
+	if len(requiresReplace) > 0 {
+		e.t.Fatalf("plan %s: expected no changes, resource must be replaced because of %v", res.typeName, requiresReplace)
+	}
+	if !planned.Equal(res.state) {
+		e.t.Fatalf("plan %s: expected no changes, got:\n  %s", res.typeName, strings.Join(differences(res.state, planned), "\n  "))
+	}
+}
+
+// refresh reads the resource from the API and returns the refreshed resource, or nil if it was removed
+func (e *testEnv) refresh(res *testResource) *testResource {
+	e.t.Helper()
+
+	schema := e.resourceSchema(res.typeName)
+	resp, err := e.server.ReadResource(e.ctx, &tfprotov6.ReadResourceRequest{
+		TypeName:     res.typeName,
+		CurrentState: e.dynamicValue(res.state),
+		Private:      res.private,
+	})
+	if err != nil {
+		e.t.Fatalf("ReadResource: %s", err)
+	}
+	e.checkDiagnostics("refresh "+res.typeName, resp.Diagnostics)
+
+	newState := e.unmarshal(schema, resp.NewState)
+	if newState.IsNull() {
+		return nil
+	}
+	return &testResource{typeName: res.typeName, state: newState, private: resp.Private}
+}
+
+// importState imports a resource by ID and reads it, as terraform import does
+func (e *testEnv) importState(typeName, id string) *testResource {
+	e.t.Helper()
+
+	resp, err := e.server.ImportResourceState(e.ctx, &tfprotov6.ImportResourceStateRequest{
+		TypeName: typeName,
+		ID:       id,
+	})
+	if err != nil {
+		e.t.Fatalf("ImportResourceState: %s", err)
+	}
+	e.checkDiagnostics("import "+typeName, resp.Diagnostics)
+	if len(resp.ImportedResources) != 1 {
+		e.t.Fatalf("import %s: expected 1 imported resource, got %d", typeName, len(resp.ImportedResources))
+	}
+
+	imported := resp.ImportedResources[0]
+	res := e.refresh(&testResource{
+		typeName: typeName,
+		state:    e.unmarshal(e.resourceSchema(typeName), imported.State),
+		private:  imported.Private,
+	})
+	if res == nil {
+		e.t.Fatalf("import %s: resource %s does not exist", typeName, id)
+	}
+	return res
+}
+
+// upgradeState upgrades a JSON state of a prior schema version, as Terraform does before every
+// operation on a state written by an older provider version
+func (e *testEnv) upgradeState(typeName string, version int64, state string) *testResource {
+	e.t.Helper()
+
+	resp, err := e.server.UpgradeResourceState(e.ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: typeName,
+		Version:  version,
+		RawState: &tfprotov6.RawState{JSON: []byte(state)},
+	})
+	if err != nil {
+		e.t.Fatalf("UpgradeResourceState: %s", err)
+	}
+	e.checkDiagnostics("upgrade "+typeName, resp.Diagnostics)
+
+	return &testResource{typeName: typeName, state: e.unmarshal(e.resourceSchema(typeName), resp.UpgradedState)}
+}
+
+// moveState moves a resource to another resource type, as a moved block does
+func (e *testEnv) moveState(res *testResource, typeName string) *testResource {
+	e.t.Helper()
+
+	rawState, err := json.Marshal(toGo(res.state))
+	if err != nil {
+		e.t.Fatalf("encoding state: %s", err)
+	}
+
+	resp, err := e.server.MoveResourceState(e.ctx, &tfprotov6.MoveResourceStateRequest{
+		SourceProviderAddress: "registry.opentofu.org/manchtools/intune",
+		SourceTypeName:        res.typeName,
+		SourceState:           &tfprotov6.RawState{JSON: rawState},
+		SourcePrivate:         res.private,
+		TargetTypeName:        typeName,
+	})
+	if err != nil {
+		e.t.Fatalf("MoveResourceState: %s", err)
+	}
+	e.checkDiagnostics("move "+res.typeName+" to "+typeName, resp.Diagnostics)
+
+	return &testResource{
+		typeName: typeName,
+		state:    e.unmarshal(e.resourceSchema(typeName), resp.TargetState),
+		private:  resp.TargetPrivate,
+	}
+}
+
+// destroy plans and applies the deletion of a resource
+func (e *testEnv) destroy(res *testResource) {
+	e.t.Helper()
+
+	schema := e.resourceSchema(res.typeName)
+	null := tftypes.NewValue(schema.ValueType(), nil)
+
+	planResp, err := e.server.PlanResourceChange(e.ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         res.typeName,
+		PriorState:       e.dynamicValue(res.state),
+		ProposedNewState: e.dynamicValue(null),
+		Config:           e.dynamicValue(null),
+		PriorPrivate:     res.private,
+	})
+	if err != nil {
+		e.t.Fatalf("PlanResourceChange: %s", err)
+	}
+	e.checkDiagnostics("plan destroy "+res.typeName, planResp.Diagnostics)
+
+	applyResp, err := e.server.ApplyResourceChange(e.ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:       res.typeName,
+		PriorState:     e.dynamicValue(res.state),
+		PlannedState:   e.dynamicValue(null),
+		Config:         e.dynamicValue(null),
+		PlannedPrivate: planResp.PlannedPrivate,
+	})
+	if err != nil {
+		e.t.Fatalf("ApplyResourceChange: %s", err)
+	}
+	e.checkDiagnostics("destroy "+res.typeName, applyResp.Diagnostics)
+}
+
+// readDataSource reads a data source with the given JSON configuration
+func (e *testEnv) readDataSource(typeName, config string) map[string]interface{} {
+	e.t.Helper()
+
+	schema, ok := e.dataSourceSchemas[typeName]
+	if !ok {
+		e.t.Fatalf("unknown data source %s", typeName)
+	}
+	configValue := e.value(schema, config)
+
+	validateResp, err := e.server.ValidateDataResourceConfig(e.ctx, &tfprotov6.ValidateDataResourceConfigRequest{
+		TypeName: typeName,
+		Config:   e.dynamicValue(configValue),
+	})
+	if err != nil {
+		e.t.Fatalf("ValidateDataResourceConfig: %s", err)
+	}
+	e.checkDiagnostics("validate "+typeName, validateResp.Diagnostics)
+
+	resp, err := e.server.ReadDataSource(e.ctx, &tfprotov6.ReadDataSourceRequest{
+		TypeName: typeName,
+		Config:   e.dynamicValue(configValue),
+	})
+	if err != nil {
+		e.t.Fatalf("ReadDataSource: %s", err)
+	}
+	e.checkDiagnostics("read "+typeName, resp.Diagnostics)
+
+	return toGo(e.unmarshal(schema, resp.State)).(map[string]interface{})
+}
+
+// attrs returns the resource state as Go values
+func (r *testResource) attrs() map[string]interface{} {
+	return toGo(r.state).(map[string]interface{})
+}
+
+// id returns the id attribute of the resource
+func (r *testResource) id() string {
+	id, _ := r.attrs()["id"].(string)
+	return id
+}
+
+// resourceSchema returns the schema of a resource type
+func (e *testEnv) resourceSchema(typeName string) *tfprotov6.Schema {
+	e.t.Helper()
+
+	schema, ok := e.resourceSchemas[typeName]
+	if !ok {
+		e.t.Fatalf("unknown resource type %s", typeName)
+	}
+	return schema
+}
+
+// value converts a JSON configuration into a value of the schema type. Omitted attributes are
+// null and omitted list or set blocks are empty, matching what Terraform sends for HCL.
+func (e *testEnv) value(schema *tfprotov6.Schema, config string) tftypes.Value {
+	e.t.Helper()
+
+	v, err := tftypes.ValueFromJSON([]byte(config), schema.ValueType())
+	if err != nil {
+		e.t.Fatalf("invalid test configuration %s: %s", config, err)
+	}
+	return normalizeBlocks(schema.Block, v)
+}
+
+// dynamicValue encodes a value for the plugin protocol
+func (e *testEnv) dynamicValue(v tftypes.Value) *tfprotov6.DynamicValue {
+	e.t.Helper()
+
+	dv, err := tfprotov6.NewDynamicValue(v.Type(), v)
+	if err != nil {
+		e.t.Fatalf("encoding value: %s", err)
+	}
+	return &dv
+}
+
+// unmarshal decodes a value returned over the plugin protocol
+func (e *testEnv) unmarshal(schema *tfprotov6.Schema, dv *tfprotov6.DynamicValue) tftypes.Value {
+	e.t.Helper()
+
+	if dv == nil {
+		return tftypes.NewValue(schema.ValueType(), nil)
+	}
+	v, err := dv.Unmarshal(schema.ValueType())
+	if err != nil {
+		e.t.Fatalf("decoding value: %s", err)
+	}
+	return v
+}
+
+// checkDiagnostics fails the test on error diagnostics
+func (e *testEnv) checkDiagnostics(step string, diags []*tfprotov6.Diagnostic) {
+	e.t.Helper()
+
+	if msg := errorSummary(diags); msg != "" {
+		e.t.Fatalf("%s: %s", step, msg)
+	}
+}
+
+// hasErrors reports whether diags contains an error
+func hasErrors(diags []*tfprotov6.Diagnostic) bool {
+	return errorSummary(diags) != ""
+}
+
+// errorSummary joins the summaries and details of all error diagnostics
+func errorSummary(diags []*tfprotov6.Diagnostic) string {
+	var msgs []string
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			msg := d.Summary + ": " + d.Detail
+			if d.Attribute != nil {
+				msg = d.Attribute.String() + ": " + msg
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// normalizeBlocks replaces null list and set blocks with empty collections
+func normalizeBlocks(block *tfprotov6.SchemaBlock, v tftypes.Value) tftypes.Value {
+	if block == nil || v.IsNull() || !v.IsKnown() {
+		return v
+	}
+
+	var attrs map[string]tftypes.Value
+	if err := v.As(&attrs); err != nil {
+		return v
+	}
+
+	for _, nested := range block.BlockTypes {
+		current, ok := attrs[nested.TypeName]
+		if !ok {
+			continue
+		}
+		switch nested.Nesting {
+		case tfprotov6.SchemaNestedBlockNestingModeList, tfprotov6.SchemaNestedBlockNestingModeSet:
+			if current.IsNull() {
+				attrs[nested.TypeName] = tftypes.NewValue(current.Type(), []tftypes.Value{})
+				continue
+			}
+			var elems []tftypes.Value
+			if err := current.As(&elems); err == nil {
+				for i := range elems {
+					elems[i] = normalizeBlocks(nested.Block, elems[i])
+				}
+				attrs[nested.TypeName] = tftypes.NewValue(current.Type(), elems)
+			}
+		case tfprotov6.SchemaNestedBlockNestingModeSingle, tfprotov6.SchemaNestedBlockNestingModeGroup:
+			attrs[nested.TypeName] = normalizeBlocks(nested.Block, current)
+		}
+	}
+
+	return tftypes.NewValue(v.Type(), attrs)
+}
+
+// proposedNewState merges configuration and prior state the way Terraform core does before
+// planning: configured values win, and computed attributes that are not configured keep their
+// prior value. Nested blocks and nested attributes are merged element by element.
+func proposedNewState(block *tfprotov6.SchemaBlock, prior, config tftypes.Value) tftypes.Value {
+	if config.IsNull() || !config.IsKnown() {
+		return config
+	}
+	if prior.IsNull() || !prior.IsKnown() {
+		prior = tftypes.NewValue(config.Type(), nil)
+	}
+
+	var configAttrs, priorAttrs map[string]tftypes.Value
+	_ = config.As(&configAttrs)
+	if !prior.IsNull() {
+		_ = prior.As(&priorAttrs)
+	}
+
+	result := make(map[string]tftypes.Value, len(configAttrs))
+	for name, value := range configAttrs {
+		result[name] = value
+	}
+
+	for _, attr := range block.Attributes {
+		configValue := configAttrs[attr.Name]
+		priorValue, ok := priorAttrs[attr.Name]
+		if !ok {
+			priorValue = tftypes.NewValue(configValue.Type(), nil)
+		}
+
+		switch {
+		case attr.Computed && configValue.IsNull():
+			result[attr.Name] = priorValue
+		case attr.NestedType != nil:
+			result[attr.Name] = proposedNestedAttribute(attr.NestedType, priorValue, configValue)
+		}
+	}
+
+	for _, nested := range block.BlockTypes {
+		configValue := configAttrs[nested.TypeName]
+		priorValue, ok := priorAttrs[nested.TypeName]
+		if !ok {
+			priorValue = tftypes.NewValue(configValue.Type(), nil)
+		}
+
+		switch nested.Nesting {
+		case tfprotov6.SchemaNestedBlockNestingModeList, tfprotov6.SchemaNestedBlockNestingModeSet:
+			result[nested.TypeName] = proposedElements(configValue, priorValue, func(p, c tftypes.Value) tftypes.Value {
+				return proposedNewState(nested.Block, p, c)
+			})
+		default:
+			result[nested.TypeName] = proposedNewState(nested.Block, priorValue, configValue)
+		}
+	}
+
+	return tftypes.NewValue(config.Type(), result)
+}
+
+// proposedNestedAttribute merges a nested attribute like proposedNewState merges a block
+func proposedNestedAttribute(object *tfprotov6.SchemaObject, prior, config tftypes.Value) tftypes.Value {
+	block := &tfprotov6.SchemaBlock{Attributes: object.Attributes}
+	switch object.Nesting {
+	case tfprotov6.SchemaObjectNestingModeList, tfprotov6.SchemaObjectNestingModeSet:
+		return proposedElements(config, prior, func(p, c tftypes.Value) tftypes.Value {
+			return proposedNewState(block, p, c)
+		})
+	case tfprotov6.SchemaObjectNestingModeSingle:
+		return proposedNewState(block, prior, config)
+	default:
+		return config
+	}
+}
+
+// proposedElements merges the elements of a list by index
+func proposedElements(config, prior tftypes.Value, merge func(prior, config tftypes.Value) tftypes.Value) tftypes.Value {
+	if config.IsNull() || !config.IsKnown() {
+		return config
+	}
+
+	var configElems, priorElems []tftypes.Value
+	_ = config.As(&configElems)
+	if !prior.IsNull() && prior.IsKnown() {
+		_ = prior.As(&priorElems)
+	}
+
+	result := make([]tftypes.Value, len(configElems))
+	for i, elem := range configElems {
+		priorElem := tftypes.NewValue(elem.Type(), nil)
+		if i < len(priorElems) {
+			priorElem = priorElems[i]
+		}
+		result[i] = merge(priorElem, elem)
+	}
+
+	return tftypes.NewValue(config.Type(), result)
+}
+
+// inconsistencies lists the known planned values that differ from the applied state
+func inconsistencies(path string, planned, actual tftypes.Value) []string {
+	if !planned.IsKnown() {
+		return nil
+	}
+	if planned.IsNull() || actual.IsNull() || !actual.IsKnown() {
+		if !planned.Equal(actual) {
+			return []string{fmt.Sprintf("%s: planned %s, got %s", displayPath(path), planned, actual)}
+		}
+		return nil
+	}
+
+	switch {
+	case planned.Type().Is(tftypes.Object{}):
+		var plannedAttrs, actualAttrs map[string]tftypes.Value
+		_ = planned.As(&plannedAttrs)
+		_ = actual.As(&actualAttrs)
+		var problems []string
+		for _, name := range sortedKeys(plannedAttrs) {
+			problems = append(problems, inconsistencies(path+"."+name, plannedAttrs[name], actualAttrs[name])...)
+		}
+		return problems
+
+	case planned.Type().Is(tftypes.List{}):
+		var plannedElems, actualElems []tftypes.Value
+		_ = planned.As(&plannedElems)
+		_ = actual.As(&actualElems)
+		if len(plannedElems) != len(actualElems) {
+			return []string{fmt.Sprintf("%s: planned %d elements, got %d", displayPath(path), len(plannedElems), len(actualElems))}
+		}
+		var problems []string
+		for i := range plannedElems {
+			problems = append(problems, inconsistencies(fmt.Sprintf("%s[%d]", path, i), plannedElems[i], actualElems[i])...)
+		}
+		return problems
+
+	default:
+		if planned.Equal(actual) {
+			return nil
+		}
+		// Sets and maps containing unknown values cannot be compared element by element
+		if !planned.IsFullyKnown() {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: planned %s, got %s", displayPath(path), planned, actual)}
+	}
+}
+
+// differences lists the attribute paths that differ between two values
+func differences(before, after tftypes.Value) []string {
+	diffs, err := before.Diff(after)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var result []string
+	for _, d := range diffs {
+		// Only report leaves; parents of changed values are reported as well
+		if d.Value1 != nil && d.Value2 != nil && d.Value1.Type().Is(tftypes.Object{}) && !d.Value1.IsNull() && !d.Value2.IsNull() {
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s: %v => %v", d.Path, d.Value1, d.Value2))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// toGo converts a value into plain Go values for assertions. Unknown values become "<unknown>".
+func toGo(v tftypes.Value) interface{} {
+	if !v.IsKnown() {
+		return "<unknown>"
+	}
+	if v.IsNull() {
+		return nil
+	}
+
+	switch {
+	case v.Type().Is(tftypes.String):
+		var s string
+		_ = v.As(&s)
+		return s
+	case v.Type().Is(tftypes.Bool):
+		var b bool
+		_ = v.As(&b)
+		return b
+	case v.Type().Is(tftypes.Number):
+		var n big.Float
+		_ = v.As(&n)
+		if i, accuracy := n.Int64(); accuracy == big.Exact {
+			return i
+		}
+		f, _ := n.Float64()
+		return f
+	case v.Type().Is(tftypes.Object{}), v.Type().Is(tftypes.Map{}):
+		var attrs map[string]tftypes.Value
+		_ = v.As(&attrs)
+		result := make(map[string]interface{}, len(attrs))
+		for k, attr := range attrs {
+			result[k] = toGo(attr)
+		}
+		return result
+	default:
+		var elems []tftypes.Value
+		_ = v.As(&elems)
+		result := make([]interface{}, len(elems))
+		for i, elem := range elems {
+			result[i] = toGo(elem)
+		}
+		return result
+	}
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys(m map[string]tftypes.Value) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// displayPath renders an attribute path built by inconsistencies
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return strings.TrimPrefix(path, ".")
+}
+
+// assertAttr fails the test if the attribute does not have the expected value
+func assertAttr(t *testing.T, attrs map[string]interface{}, name string, want interface{}) {
+	t.Helper()
+
+	if got := attrs[name]; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("attribute %s: got %v, want %v", name, got, want)
+	}
+}
+
+func TestAuthConfigFromEnvironment(t *testing.T) {
+	for _, name := range []string{
+		"ARM_TENANT_ID", "ARM_ENVIRONMENT", "ARM_METADATA_HOSTNAME", "ARM_CLIENT_ID", "ARM_CLIENT_SECRET",
+		"ARM_USE_MSI", "ARM_USE_OIDC", "ARM_OIDC_TOKEN", "ARM_OIDC_REQUEST_URL", "ARM_OIDC_REQUEST_TOKEN",
+		"ARM_ADO_PIPELINE_SERVICE_CONNECTION_ID", "ARM_OIDC_AZURE_SERVICE_CONNECTION_ID",
+		"ACTIONS_ID_TOKEN_REQUEST_URL", "ACTIONS_ID_TOKEN_REQUEST_TOKEN", "SYSTEM_OIDCREQUESTURI", "SYSTEM_ACCESSTOKEN",
+	} {
+		t.Setenv(name, "")
+	}
+
+	t.Setenv("ARM_TENANT_ID", "tenant")
+	t.Setenv("ARM_ENVIRONMENT", "usgovernment")
+	t.Setenv("ARM_METADATA_HOSTNAME", "management.local.azurestack.external")
+	t.Setenv("ARM_CLIENT_ID", "client")
+	t.Setenv("ARM_OIDC_AZURE_SERVICE_CONNECTION_ID", "connection")
+	t.Setenv("SYSTEM_OIDCREQUESTURI", "https://dev.azure.com/oidctoken")
+	t.Setenv("SYSTEM_ACCESSTOKEN", "system-access-token")
+
+	// OIDC variables are only read when OIDC is enabled
+	config := AuthConfigFromEnv()
+	if config.TenantID != "tenant" || config.Environment != "usgovernment" || config.ClientID != "client" || !config.UseAzureCLI {
+		t.Errorf("unexpected configuration: %+v", config)
+	}
+	if config.MetadataHost != "management.local.azurestack.external" {
+		t.Errorf("expected the metadata host from ARM_METADATA_HOSTNAME, got %q", config.MetadataHost)
+	}
+	if config.ADOServiceConnectionID != "" || config.OIDCRequestURL != "" {
+		t.Errorf("expected no OIDC settings without ARM_USE_OIDC, got %+v", config)
+	}
+
+	t.Setenv("ARM_USE_OIDC", "true")
+	config = AuthConfigFromEnv()
+	if config.ADOServiceConnectionID != "connection" || config.OIDCRequestURL != "https://dev.azure.com/oidctoken" || config.OIDCRequestToken != "system-access-token" {
+		t.Errorf("expected the Azure DevOps OIDC settings, got %+v", config)
+	}
+
+	// Provider attributes take precedence over the environment
+	config, diags := newAuthConfig(context.Background(), IntuneProviderModel{
+		TenantID:     types.StringValue("configured-tenant"),
+		MetadataHost: types.StringValue("metadata.example.com"),
+	})
+	if diags.HasError() {
+		t.Fatalf("newAuthConfig: %v", diags)
+	}
+	if config.TenantID != "configured-tenant" || config.MetadataHost != "metadata.example.com" || config.ClientID != "client" {
+		t.Errorf("unexpected configuration: %+v", config)
+	}
+}
@@ -122,9 +122,11 @@ resource "intune_settings_catalog_policy" "surface_settings" {
   technologies = "mdm"
 
   assignment {
-    all_devices = true
-    filter_id   = intune_assignment_filter.surface_pro.id
-    filter_type = "include"
+    target {
+      type        = "all_devices"
+      filter_id   = intune_assignment_filter.surface_pro.id
+      filter_type = "include"
+    }
   }
 }
 ` + "```" + `
@@ -256,7 +258,7 @@ func (r *AssignmentFilterResource) Create(ctx context.Context, req resource.Crea
 	}
 
 	// Handle role scope tags
-	if !data.RoleScopeTags.IsNull() {
+	if !data.RoleScopeTags.IsNull() && !data.RoleScopeTags.IsUnknown() {
 		var tags []string
 		resp.Diagnostics.Append(data.RoleScopeTags.ElementsAs(ctx, &tags, false)...)
 		if resp.Diagnostics.HasError() {
@@ -278,7 +280,7 @@ func (r *AssignmentFilterResource) Create(ctx context.Context, req resource.Crea
 	// Update the model with the created filter data
 	data.ID = types.StringValue(created.ID)
 	data.DisplayName = types.StringValue(created.DisplayName)
-	data.Description = types.StringValue(created.Description)
+	data.Description = optionalStringValue(data.Description, created.Description)
 	data.Platform = types.StringValue(created.Platform)
 	data.Rule = types.StringValue(created.Rule)
 	data.CreatedDateTime = types.StringValue(created.CreatedDateTime)
@@ -314,7 +316,7 @@ func (r *AssignmentFilterResource) Read(ctx context.Context, req resource.ReadRe
 	filter, err := r.client.GetAssignmentFilter(ctx, data.ID.ValueString())
 	if err != nil {
 		// Check if the resource was deleted outside of Terraform
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			resp.State.RemoveResource(ctx)
 			return
 		}
@@ -327,7 +329,7 @@ func (r *AssignmentFilterResource) Read(ctx context.Context, req resource.ReadRe
 
 	// Update the model with the read data
 	data.DisplayName = types.StringValue(filter.DisplayName)
-	data.Description = types.StringValue(filter.Description)
+	data.Description = optionalStringValue(data.Description, filter.Description)
 	data.Platform = types.StringValue(filter.Platform)
 	data.Rule = types.StringValue(filter.Rule)
 	data.CreatedDateTime = types.StringValue(filter.CreatedDateTime)
@@ -363,7 +365,7 @@ func (r *AssignmentFilterResource) Update(ctx context.Context, req resource.Upda
 	}
 
 	// Handle role scope tags
-	if !data.RoleScopeTags.IsNull() {
+	if !data.RoleScopeTags.IsNull() && !data.RoleScopeTags.IsUnknown() {
 		var tags []string
 		resp.Diagnostics.Append(data.RoleScopeTags.ElementsAs(ctx, &tags, false)...)
 		if resp.Diagnostics.HasError() {
@@ -384,7 +386,7 @@ func (r *AssignmentFilterResource) Update(ctx context.Context, req resource.Upda
 
 	// Update the model with the updated filter data
 	data.DisplayName = types.StringValue(updated.DisplayName)
-	data.Description = types.StringValue(updated.Description)
+	data.Description = optionalStringValue(data.Description, updated.Description)
 	data.Platform = types.StringValue(updated.Platform)
 	data.Rule = types.StringValue(updated.Rule)
 	data.LastModifiedDateTime = types.StringValue(updated.LastModifiedDateTime)
@@ -413,7 +415,7 @@ func (r *AssignmentFilterResource) Delete(ctx context.Context, req resource.Dele
 	err := r.client.DeleteAssignmentFilter(ctx, data.ID.ValueString())
 	if err != nil {
 		// Ignore "not found" errors as the resource is already deleted
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			return
 		}
 		resp.Diagnostics.AddError(
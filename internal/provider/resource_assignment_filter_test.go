@@ -0,0 +1,55 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+func TestAccAssignmentFilterResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Corporate Windows",
+		"platform": "windows10AndLater",
+		"rule": "(device.deviceOwnership -eq \"Corporate\")"
+	}`
+	res := env.apply("intune_assignment_filter", nil, config)
+	assertAttr(t, res.attrs(), "platform", "windows10AndLater")
+	env.assertNoOp(res, config)
+
+	updated := `{
+		"display_name": "Corporate Windows",
+		"description": "Corporate owned Windows devices",
+		"platform": "windows10AndLater",
+		"rule": "(device.deviceOwnership -eq \"Corporate\") and (device.osVersion -startsWith \"10.0.2\")"
+	}`
+	res = env.apply("intune_assignment_filter", res, updated)
+	rule, _ := env.graph.Object(fakegraph.AssignmentFilters, res.id())["rule"].(string)
+	if !strings.Contains(rule, "osVersion") {
+		t.Errorf("rule in Graph was not updated: %s", rule)
+	}
+	env.assertNoOp(res, updated)
+
+	imported := env.importState("intune_assignment_filter", res.id())
+	env.assertNoOp(imported, updated)
+
+	// Changing the platform replaces the filter
+	replaced := env.apply("intune_assignment_filter", res, `{
+		"display_name": "Corporate macOS",
+		"platform": "macOS",
+		"rule": "(device.deviceOwnership -eq \"Corporate\")"
+	}`)
+	if replaced.id() == res.id() {
+		t.Errorf("expected a new filter after changing the platform")
+	}
+	if env.graph.Object(fakegraph.AssignmentFilters, res.id()) != nil {
+		t.Errorf("filter %s still exists after replacement", res.id())
+	}
+
+	env.destroy(replaced)
+}
@@ -0,0 +1,694 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &AutopilotDeploymentProfileResource{}
+var _ resource.ResourceWithImportState = &AutopilotDeploymentProfileResource{}
+var _ resource.ResourceWithValidateConfig = &AutopilotDeploymentProfileResource{}
+
+// Join types of Autopilot deployment profiles
+const (
+	autopilotJoinAzureAD       = "azure_ad"
+	autopilotJoinHybridAzureAD = "hybrid_azure_ad"
+)
+
+// autopilotJoinODataTypes maps join types to the OData types of Autopilot profiles
+var autopilotJoinODataTypes = map[string]string{
+	autopilotJoinAzureAD:       clients.ODataTypeAzureADAutopilotProfile,
+	autopilotJoinHybridAzureAD: clients.ODataTypeHybridAzureADAutopilotProfile,
+}
+
+// Deployment modes of Autopilot deployment profiles, and the device usage types Graph uses for them
+const (
+	autopilotModeUserDriven    = "user_driven"
+	autopilotModeSelfDeploying = "self_deploying"
+)
+
+var autopilotDeviceUsageTypes = map[string]string{
+	autopilotModeUserDriven:    "singleUser",
+	autopilotModeSelfDeploying: "shared",
+}
+
+// autopilotLanguagePattern matches the OOBE language of Autopilot profiles: the operating system
+// default, a choice of the user or a locale such as en-US
+var autopilotLanguagePattern = regexp.MustCompile(`^(os-default|user-select|[a-z]{2,3}(-[A-Za-z0-9]{2,8})+)$`)
+
+// NewAutopilotDeploymentProfileResource returns a new Autopilot deployment profile resource
+func NewAutopilotDeploymentProfileResource() resource.Resource {
+	return &AutopilotDeploymentProfileResource{}
+}
+
+// AutopilotDeploymentProfileResource defines the resource implementation
+type AutopilotDeploymentProfileResource struct {
+	client *clients.GraphClient
+}
+
+// AutopilotDeploymentProfileResourceModel describes the resource data model
+type AutopilotDeploymentProfileResourceModel struct {
+	ID                           types.String      `tfsdk:"id"`
+	Type                         types.String      `tfsdk:"type"`
+	DisplayName                  types.String      `tfsdk:"display_name"`
+	Description                  types.String      `tfsdk:"description"`
+	JoinType                     types.String      `tfsdk:"join_type"`
+	HybridSkipConnectivityCheck  types.Bool        `tfsdk:"hybrid_skip_connectivity_check"`
+	DeploymentMode               types.String      `tfsdk:"deployment_mode"`
+	DeviceNameTemplate           types.String      `tfsdk:"device_name_template"`
+	Language                     types.String      `tfsdk:"language"`
+	PreProvisioningAllowed       types.Bool        `tfsdk:"pre_provisioning_allowed"`
+	ConvertAllTargetedDevices    types.Bool        `tfsdk:"convert_all_targeted_devices"`
+	OOBEUserAccountType          types.String      `tfsdk:"oobe_user_account_type"`
+	OOBEHidePrivacySettings      types.Bool        `tfsdk:"oobe_hide_privacy_settings"`
+	OOBEHideEULA                 types.Bool        `tfsdk:"oobe_hide_eula"`
+	OOBEHideChangeAccountOptions types.Bool        `tfsdk:"oobe_hide_change_account_options"`
+	OOBESkipKeyboardSelection    types.Bool        `tfsdk:"oobe_skip_keyboard_selection"`
+	RoleScopeTagIds              types.List        `tfsdk:"role_scope_tag_ids"`
+	CreatedDateTime              types.String      `tfsdk:"created_date_time"`
+	LastModifiedDateTime         types.String      `tfsdk:"last_modified_date_time"`
+	Assignment                   []AssignmentModel `tfsdk:"assignment"`
+}
+
+// Metadata returns the resource type name
+func (r *AutopilotDeploymentProfileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_autopilot_deployment_profile"
+}
+
+// Schema defines the schema for the resource
+func (r *AutopilotDeploymentProfileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a Windows Autopilot deployment profile.",
+		MarkdownDescription: `
+Manages a Windows Autopilot deployment profile.
+
+Deployment profiles configure the out-of-box experience (OOBE) of the devices registered with
+Windows Autopilot they are assigned to. Devices either join Microsoft Entra ID (` + "`azure_ad`" + `) or
+join an on-premises domain and Microsoft Entra ID (` + "`hybrid_azure_ad`" + `); the join type cannot
+be changed, changing it replaces the profile. Hybrid joined devices are named by their domain
+join profile, so ` + "`device_name_template`" + ` is only supported for ` + "`azure_ad`" + `, like the
+` + "`self_deploying`" + ` deployment mode.
+
+Autopilot profiles are assigned to devices: ` + "`all_users`" + ` targets are not supported. Unlike
+policies, Graph assigns them one target at a time, so targets that stay assigned are left alone
+while the assignments change.
+
+## Device Name Template
+
+Names are at most 15 characters of letters, digits and hyphens, and must contain a macro so
+every device gets a unique name:
+
+- ` + "`%SERIAL%`" + ` is replaced by the serial number of the device, truncated to fit
+- ` + "`%RAND:x%`" + ` is replaced by x random digits, which count towards the 15 characters
+
+## Example Usage
+
+` + "```hcl" + `
+resource "intune_autopilot_deployment_profile" "corporate" {
+  display_name             = "Corporate laptops"
+  join_type                = "azure_ad"
+  device_name_template     = "CORP-%SERIAL%"
+  language                 = "en-US"
+  pre_provisioning_allowed = true
+
+  assignment {
+    target {
+      type     = "group"
+      group_id = azuread_group.autopilot_devices.object_id
+    }
+  }
+}
+
+resource "intune_autopilot_deployment_profile" "kiosk" {
+  display_name         = "Kiosks"
+  join_type            = "azure_ad"
+  deployment_mode      = "self_deploying"
+  device_name_template = "KIOSK-%RAND:5%"
+}
+` + "```" + `
+
+## Import
+
+Autopilot deployment profiles can be imported using the profile ID:
+
+` + "```shell" + `
+terraform import intune_autopilot_deployment_profile.example 00000000-0000-0000-0000-000000000000
+` + "```" + `
+`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for the profile.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Description: "The policy type for use with policy assignments. Always 'autopilot' for this resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"display_name": schema.StringAttribute{
+				Description: "The display name of the profile.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the profile.",
+				Optional:    true,
+			},
+			"join_type": schema.StringAttribute{
+				Description: "How devices join. Valid values: azure_ad, hybrid_azure_ad. Changing it replaces the profile.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(autopilotJoinAzureAD, autopilotJoinHybridAzureAD),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"hybrid_skip_connectivity_check": schema.BoolAttribute{
+				Description: "Skip the domain controller connectivity check during OOBE, for devices that reach the domain " +
+					"through a VPN. Hybrid join only.",
+				Optional: true,
+			},
+			"deployment_mode": schema.StringAttribute{
+				Description: "The deployment mode. Valid values: user_driven, self_deploying. Defaults to user_driven. " +
+					"Self-deploying mode requires azure_ad join.",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(autopilotModeUserDriven),
+				Validators: []validator.String{
+					stringvalidator.OneOf(autopilotModeUserDriven, autopilotModeSelfDeploying),
+				},
+			},
+			"device_name_template": schema.StringAttribute{
+				Description: "The template devices are named by, e.g. CORP-%SERIAL% or CORP-%RAND:5%. Azure AD join only.",
+				Optional:    true,
+				Validators: []validator.String{
+					deviceNameTemplateValidator{},
+				},
+			},
+			"language": schema.StringAttribute{
+				Description: "The language of the OOBE: os-default, user-select or a locale such as en-US. Defaults to os-default.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("os-default"),
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(autopilotLanguagePattern, "must be os-default, user-select or a locale such as en-US"),
+				},
+			},
+			"pre_provisioning_allowed": schema.BoolAttribute{
+				Description: "Allow pre-provisioning (formerly white glove) of devices by pressing the Windows key five times " +
+					"during OOBE. Not supported in self-deploying mode.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"convert_all_targeted_devices": schema.BoolAttribute{
+				Description: "Register all devices the profile is assigned to with Autopilot, including devices that were not " +
+					"enrolled through Autopilot.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"oobe_user_account_type": schema.StringAttribute{
+				Description: "The account type of the user that sets up the device. Valid values: standard, administrator. " +
+					"Defaults to standard.",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString("standard"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("standard", "administrator"),
+				},
+			},
+			"oobe_hide_privacy_settings": schema.BoolAttribute{
+				Description: "Hide the privacy settings during OOBE. Defaults to true.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"oobe_hide_eula": schema.BoolAttribute{
+				Description: "Hide the Microsoft Software License Terms during OOBE. Defaults to true.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"oobe_hide_change_account_options": schema.BoolAttribute{
+				Description: "Hide the options to change the account on the sign-in page during OOBE. Defaults to true.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"oobe_skip_keyboard_selection": schema.BoolAttribute{
+				Description: "Skip the keyboard selection page when language is a locale. Defaults to true.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"role_scope_tag_ids": schema.ListAttribute{
+				Description: "List of scope tag IDs for this profile.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"created_date_time": schema.StringAttribute{
+				Description: "The date and time the profile was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_modified_date_time": schema.StringAttribute{
+				Description: "The date and time the profile was last modified.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"assignment": AssignmentBlockSchema(),
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *AutopilotDeploymentProfileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+}
+
+// ValidateConfig checks the settings that depend on the join type and deployment mode, and that
+// the profile is only assigned to devices
+func (r *AutopilotDeploymentProfileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data AutopilotDeploymentProfileResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	hybrid := data.JoinType.ValueString() == autopilotJoinHybridAzureAD
+	if !data.JoinType.IsUnknown() {
+		if hybrid && !data.DeviceNameTemplate.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("device_name_template"),
+				"Unsupported Device Name Template",
+				"device_name_template is only supported for azure_ad join, hybrid joined devices are named by their domain join profile.",
+			)
+		}
+		if hybrid && data.DeploymentMode.ValueString() == autopilotModeSelfDeploying {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("deployment_mode"),
+				"Unsupported Deployment Mode",
+				"Self-deploying mode is only supported for azure_ad join.",
+			)
+		}
+		if !hybrid && !data.HybridSkipConnectivityCheck.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("hybrid_skip_connectivity_check"),
+				"Unsupported Connectivity Check Setting",
+				"hybrid_skip_connectivity_check is only supported for hybrid_azure_ad join.",
+			)
+		}
+	}
+
+	if data.DeploymentMode.ValueString() == autopilotModeSelfDeploying && data.PreProvisioningAllowed.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("pre_provisioning_allowed"),
+			"Unsupported Pre-Provisioning",
+			"Pre-provisioning is not supported in self-deploying mode.",
+		)
+	}
+
+	for i, assignment := range data.Assignment {
+		for j, target := range assignment.Targets {
+			if target.Type.ValueString() == AssignmentTargetAllUsers {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("assignment").AtListIndex(i).AtName("target").AtListIndex(j).AtName("type"),
+					"Unsupported Assignment Target",
+					"Autopilot profiles are assigned to devices, use all_devices or a device group instead of all_users.",
+				)
+			}
+		}
+	}
+}
+
+// buildProfile converts the model into an Autopilot deployment profile
+func (r *AutopilotDeploymentProfileResource) buildProfile(ctx context.Context, data *AutopilotDeploymentProfileResourceModel, diags *diag.Diagnostics) *clients.WindowsAutopilotDeploymentProfile {
+	profile := &clients.WindowsAutopilotDeploymentProfile{
+		ODataType:           autopilotJoinODataTypes[data.JoinType.ValueString()],
+		DisplayName:         data.DisplayName.ValueString(),
+		Description:         data.Description.ValueString(),
+		Language:            data.Language.ValueString(),
+		DeviceNameTemplate:  data.DeviceNameTemplate.ValueString(),
+		DeviceType:          "windowsPc",
+		EnableWhiteGlove:    data.PreProvisioningAllowed.ValueBool(),
+		ExtractHardwareHash: data.ConvertAllTargetedDevices.ValueBool(),
+		OutOfBoxExperienceSettings: &clients.OutOfBoxExperienceSettings{
+			HidePrivacySettings:       data.OOBEHidePrivacySettings.ValueBool(),
+			HideEULA:                  data.OOBEHideEULA.ValueBool(),
+			UserType:                  data.OOBEUserAccountType.ValueString(),
+			DeviceUsageType:           autopilotDeviceUsageTypes[data.DeploymentMode.ValueString()],
+			SkipKeyboardSelectionPage: data.OOBESkipKeyboardSelection.ValueBool(),
+			HideEscapeLink:            data.OOBEHideChangeAccountOptions.ValueBool(),
+		},
+		RoleScopeTagIds: []string{DefaultScopeTagID},
+	}
+
+	// The connectivity check setting only exists on hybrid profiles
+	if data.JoinType.ValueString() == autopilotJoinHybridAzureAD {
+		skip := data.HybridSkipConnectivityCheck.ValueBool()
+		profile.HybridAzureADJoinSkipConnectivityCheck = &skip
+	}
+
+	// Add role scope tag IDs if specified
+	if !data.RoleScopeTagIds.IsNull() {
+		var tagIds []string
+		diags.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		profile.RoleScopeTagIds = tagIds
+	}
+
+	return profile
+}
+
+// updateModel updates the Terraform model from the API profile
+func (r *AutopilotDeploymentProfileResource) updateModel(ctx context.Context, data *AutopilotDeploymentProfileResourceModel, profile *clients.WindowsAutopilotDeploymentProfile, diags *diag.Diagnostics) {
+	data.Type = types.StringValue(PolicyTypeAutopilot)
+	data.DisplayName = types.StringValue(profile.DisplayName)
+	data.Description = optionalStringValue(data.Description, profile.Description)
+	data.DeviceNameTemplate = optionalStringValue(data.DeviceNameTemplate, profile.DeviceNameTemplate)
+	data.Language = types.StringValue(profile.Language)
+	data.PreProvisioningAllowed = types.BoolValue(profile.EnableWhiteGlove)
+	data.ConvertAllTargetedDevices = types.BoolValue(profile.ExtractHardwareHash)
+	data.CreatedDateTime = types.StringValue(profile.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(profile.LastModifiedDateTime)
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, profile.RoleScopeTagIds, diags)
+
+	for joinType, odataType := range autopilotJoinODataTypes {
+		if odataType == profile.ODataType {
+			data.JoinType = types.StringValue(joinType)
+		}
+	}
+
+	// An unset connectivity check setting stays unset while the check is performed
+	if skip := profile.HybridAzureADJoinSkipConnectivityCheck; skip != nil && (*skip || !data.HybridSkipConnectivityCheck.IsNull()) {
+		data.HybridSkipConnectivityCheck = types.BoolValue(*skip)
+	}
+
+	if oobe := profile.OutOfBoxExperienceSettings; oobe != nil {
+		for mode, usageType := range autopilotDeviceUsageTypes {
+			if usageType == oobe.DeviceUsageType {
+				data.DeploymentMode = types.StringValue(mode)
+			}
+		}
+		data.OOBEUserAccountType = types.StringValue(oobe.UserType)
+		data.OOBEHidePrivacySettings = types.BoolValue(oobe.HidePrivacySettings)
+		data.OOBEHideEULA = types.BoolValue(oobe.HideEULA)
+		data.OOBEHideChangeAccountOptions = types.BoolValue(oobe.HideEscapeLink)
+		data.OOBESkipKeyboardSelection = types.BoolValue(oobe.SkipKeyboardSelectionPage)
+	}
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *AutopilotDeploymentProfileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data AutopilotDeploymentProfileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating Autopilot deployment profile", map[string]interface{}{
+		"display_name": data.DisplayName.ValueString(),
+	})
+
+	profile := r.buildProfile(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateAutopilotDeploymentProfile(ctx, profile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Autopilot Deployment Profile",
+			fmt.Sprintf("Could not create profile: %s", err),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(created.ID)
+	r.updateModel(ctx, &data, created, &resp.Diagnostics)
+
+	// Handle assignments if specified
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeAutopilot, created.ID, assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Assigning Profile",
+				fmt.Sprintf("Profile was created but assignment failed: %s", err),
+			)
+			return
+		}
+	}
+
+	tflog.Debug(ctx, "Created Autopilot deployment profile", map[string]interface{}{
+		"id": created.ID,
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data
+func (r *AutopilotDeploymentProfileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AutopilotDeploymentProfileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Reading Autopilot deployment profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	// Get the profile, together with its assignments if the state had assignments configured
+	profilePath := fmt.Sprintf("%s/%s", clients.PathAutopilotDeploymentProfiles, data.ID.ValueString())
+	result, err := readPolicyWithAssignments[clients.WindowsAutopilotDeploymentProfile](ctx, r.client, PolicyTypeAutopilot, profilePath, data.ID.ValueString(), len(data.Assignment) > 0)
+	if err != nil {
+		// Check if the profile was deleted
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Autopilot Deployment Profile",
+			fmt.Sprintf("Could not read profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, result.Policy, &resp.Diagnostics)
+
+	// Update assignments if the state had assignments configured
+	if len(data.Assignment) > 0 {
+		if result.AssignmentsErr != nil {
+			tflog.Warn(ctx, "Failed to read profile assignments", map[string]interface{}{
+				"error": result.AssignmentsErr.Error(),
+			})
+		} else {
+			data.Assignment = PreserveAssignments(data.Assignment, result.Assignments)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update updates the resource and sets the updated Terraform state
+func (r *AutopilotDeploymentProfileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data AutopilotDeploymentProfileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating Autopilot deployment profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	profile := r.buildProfile(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateAutopilotDeploymentProfile(ctx, data.ID.ValueString(), profile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Autopilot Deployment Profile",
+			fmt.Sprintf("Could not update profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, updated, &resp.Diagnostics)
+
+	// Handle assignments
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeAutopilot, data.ID.ValueString(), assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Profile Assignments",
+				fmt.Sprintf("Could not update assignments: %s", err),
+			)
+			return
+		}
+	} else {
+		// Clear assignments if none specified
+		if err := AssignPolicy(ctx, r.client, PolicyTypeAutopilot, data.ID.ValueString(), []clients.PolicyAssignment{}); err != nil {
+			tflog.Warn(ctx, "Failed to clear profile assignments", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete deletes the resource and removes the Terraform state
+func (r *AutopilotDeploymentProfileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data AutopilotDeploymentProfileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting Autopilot deployment profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	err := r.client.DeleteAutopilotDeploymentProfile(ctx, data.ID.ValueString())
+	if err != nil {
+		// Ignore not found errors during delete
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Autopilot Deployment Profile",
+			fmt.Sprintf("Could not delete profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the resource state
+func (r *AutopilotDeploymentProfileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// deviceNameTemplateValidator checks device name templates of Autopilot profiles
+type deviceNameTemplateValidator struct{}
+
+// Description describes the validation
+func (v deviceNameTemplateValidator) Description(ctx context.Context) string {
+	return "value must be a device name template of at most 15 characters with a %SERIAL% or %RAND:x% macro"
+}
+
+// MarkdownDescription describes the validation in Markdown
+func (v deviceNameTemplateValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString validates the template
+func (v deviceNameTemplateValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	for _, err := range validateDeviceNameTemplate(req.ConfigValue.ValueString()) {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Device Name Template", err)
+	}
+}
+
+// deviceNameMacro matches the macros of device name templates, and anything else between percent
+// signs so unknown macros can be reported
+var deviceNameMacro = regexp.MustCompile(`%[^%]*%`)
+
+// validateDeviceNameTemplate returns the problems of a device name template. Device names are
+// NetBIOS names: at most 15 letters, digits and hyphens. %SERIAL% is truncated to fit, the digits
+// of %RAND:x% count towards the limit.
+func validateDeviceNameTemplate(template string) []string {
+	var problems []string
+	length, macros := 0, 0
+	for _, literal := range deviceNameMacro.Split(template, -1) {
+		length += len(literal)
+		for _, c := range literal {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				problems = append(problems, fmt.Sprintf("The character %q is not allowed, device names can only contain letters, digits and hyphens.", c))
+				break
+			}
+		}
+	}
+
+	for _, macro := range deviceNameMacro.FindAllString(template, -1) {
+		macros++
+		if macro == "%SERIAL%" {
+			continue
+		}
+		digits, ok := strings.CutPrefix(strings.TrimSuffix(macro[1:], "%"), "RAND:")
+		n, err := strconv.Atoi(digits)
+		if !ok || err != nil || n < 1 {
+			problems = append(problems, fmt.Sprintf("%s is not a supported macro, use %%SERIAL%% or %%RAND:x%% with x random digits.", macro))
+			continue
+		}
+		length += n
+	}
+
+	if macros == 0 {
+		problems = append(problems, "The template must contain %SERIAL% or %RAND:x%, otherwise every device gets the same name.")
+	}
+	if length > 15 {
+		problems = append(problems, fmt.Sprintf("The template expands to %d characters, device names are limited to 15.", length))
+	}
+	return problems
+}
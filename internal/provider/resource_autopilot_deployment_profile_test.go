@@ -0,0 +1,199 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+// autopilotAssignmentIDs returns the IDs of the assignments of an Autopilot profile by target
+func autopilotAssignmentIDs(env *testEnv, profileID string) map[string]string {
+	ids := make(map[string]string)
+	for _, assignment := range env.graph.Assignments(fakegraph.AutopilotProfiles, profileID) {
+		a := assignment.(map[string]interface{})
+		target := a["target"].(map[string]interface{})
+		key, _ := target["groupId"].(string)
+		if key == "" {
+			key = target["@odata.type"].(string)
+		}
+		ids[key] = a["id"].(string)
+	}
+	return ids
+}
+
+func TestAccAutopilotDeploymentProfileResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Corporate laptops",
+		"join_type": "azure_ad",
+		"device_name_template": "CORP-%SERIAL%",
+		"language": "en-US",
+		"pre_provisioning_allowed": true,
+		"assignment": [{"target": [
+			{"type": "group", "group_id": "00000000-0000-0000-0000-00000000000a"},
+			{"type": "all_devices"}
+		]}]
+	}`
+	res := env.apply("intune_autopilot_deployment_profile", nil, config)
+	assertAttr(t, res.attrs(), "type", PolicyTypeAutopilot)
+	assertAttr(t, res.attrs(), "deployment_mode", "user_driven")
+	assertAttr(t, res.attrs(), "oobe_user_account_type", "standard")
+	assertAttr(t, res.attrs(), "hybrid_skip_connectivity_check", nil)
+
+	profile := env.graph.Object(fakegraph.AutopilotProfiles, res.id())
+	assertAttr(t, profile, "@odata.type", "#microsoft.graph.azureADWindowsAutopilotDeploymentProfile")
+	assertAttr(t, profile, "deviceNameTemplate", "CORP-%SERIAL%")
+	assertAttr(t, profile, "enableWhiteGlove", true)
+	assertAttr(t, profile, "outOfBoxExperienceSettings", map[string]interface{}{
+		"hidePrivacySettings":       true,
+		"hideEULA":                  true,
+		"userType":                  "standard",
+		"deviceUsageType":           "singleUser",
+		"skipKeyboardSelectionPage": true,
+		"hideEscapeLink":            true,
+	})
+	if _, ok := profile["hybridAzureADJoinSkipConnectivityCheck"]; ok {
+		t.Errorf("expected no connectivity check setting on an Azure AD profile")
+	}
+	before := autopilotAssignmentIDs(env, res.id())
+	if len(before) != 2 {
+		t.Fatalf("expected 2 assignments in Graph, got %v", before)
+	}
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	// Self-deploying kiosks, keeping the group and replacing all devices by another group
+	updated := `{
+		"display_name": "Kiosks",
+		"join_type": "azure_ad",
+		"deployment_mode": "self_deploying",
+		"device_name_template": "KIOSK-%RAND:5%",
+		"oobe_hide_eula": false,
+		"assignment": [{"target": [
+			{"type": "group", "group_id": "00000000-0000-0000-0000-00000000000a"},
+			{"type": "group", "group_id": "00000000-0000-0000-0000-00000000000b"}
+		]}]
+	}`
+	res = env.apply("intune_autopilot_deployment_profile", res, updated)
+	profile = env.graph.Object(fakegraph.AutopilotProfiles, res.id())
+	assertAttr(t, profile, "language", "os-default")
+	assertAttr(t, profile, "enableWhiteGlove", false)
+	oobe := profile["outOfBoxExperienceSettings"].(map[string]interface{})
+	assertAttr(t, oobe, "deviceUsageType", "shared")
+	assertAttr(t, oobe, "hideEULA", false)
+
+	// The unchanged target keeps its assignment, the others are replaced one at a time
+	after := autopilotAssignmentIDs(env, res.id())
+	if len(after) != 2 || after["00000000-0000-0000-0000-00000000000a"] != before["00000000-0000-0000-0000-00000000000a"] || after["00000000-0000-0000-0000-00000000000b"] == "" {
+		t.Errorf("expected group a to keep its assignment next to group b, got %v (was %v)", after, before)
+	}
+	for _, request := range env.graph.Requests() {
+		if strings.HasSuffix(request, "/assign") {
+			t.Errorf("unexpected assign action for an Autopilot profile: %s", request)
+		}
+	}
+	env.assertNoOp(env.refresh(res), updated)
+
+	withoutAssignments := `{
+		"display_name": "Kiosks",
+		"join_type": "azure_ad",
+		"deployment_mode": "self_deploying",
+		"device_name_template": "KIOSK-%RAND:5%",
+		"oobe_hide_eula": false
+	}`
+	imported := env.importState("intune_autopilot_deployment_profile", res.id())
+	assertAttr(t, imported.attrs(), "join_type", "azure_ad")
+	assertAttr(t, imported.attrs(), "deployment_mode", "self_deploying")
+	env.assertNoOp(imported, withoutAssignments)
+
+	res = env.apply("intune_autopilot_deployment_profile", res, withoutAssignments)
+	if n := len(env.graph.Assignments(fakegraph.AutopilotProfiles, res.id())); n != 0 {
+		t.Errorf("expected assignments to be removed, got %d", n)
+	}
+
+	// The profile can be assigned through the generic assignment resources
+	assignment := env.apply("intune_policy_assignment", nil, fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "autopilot",
+		"target": [{"type": "all_devices"}]
+	}`, res.id()))
+	group := env.apply("intune_policy_group_assignment", nil, fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "autopilot",
+		"target_type": "exclusion",
+		"group_id": "00000000-0000-0000-0000-00000000000c"
+	}`, res.id()))
+	if n := len(env.graph.Assignments(fakegraph.AutopilotProfiles, res.id())); n != 2 {
+		t.Errorf("expected 2 assignments in Graph, got %d", n)
+	}
+	env.destroy(group)
+	env.destroy(assignment)
+	if n := len(env.graph.Assignments(fakegraph.AutopilotProfiles, res.id())); n != 0 {
+		t.Errorf("expected assignments to be removed, got %d", n)
+	}
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.AutopilotProfiles, res.id()) != nil {
+		t.Errorf("profile %s still exists after destroy", res.id())
+	}
+}
+
+func TestAccAutopilotDeploymentProfileResource_hybrid(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Hybrid laptops",
+		"join_type": "hybrid_azure_ad",
+		"hybrid_skip_connectivity_check": true,
+		"oobe_user_account_type": "administrator"
+	}`
+	res := env.apply("intune_autopilot_deployment_profile", nil, config)
+	profile := env.graph.Object(fakegraph.AutopilotProfiles, res.id())
+	assertAttr(t, profile, "@odata.type", "#microsoft.graph.activeDirectoryWindowsAutopilotDeploymentProfile")
+	assertAttr(t, profile, "hybridAzureADJoinSkipConnectivityCheck", true)
+	assertAttr(t, res.attrs(), "device_name_template", nil)
+	env.assertNoOp(env.refresh(res), config)
+
+	// An unset connectivity check setting is sent as false and stays unset
+	checked := `{"display_name": "Hybrid laptops", "join_type": "hybrid_azure_ad"}`
+	res = env.apply("intune_autopilot_deployment_profile", res, checked)
+	assertAttr(t, env.graph.Object(fakegraph.AutopilotProfiles, res.id()), "hybridAzureADJoinSkipConnectivityCheck", false)
+	assertAttr(t, res.attrs(), "hybrid_skip_connectivity_check", nil)
+	env.assertNoOp(env.refresh(res), checked)
+
+	imported := env.importState("intune_autopilot_deployment_profile", res.id())
+	assertAttr(t, imported.attrs(), "join_type", "hybrid_azure_ad")
+	env.assertNoOp(imported, checked)
+}
+
+func TestAccAutopilotDeploymentProfileResource_invalid(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		config string
+		want   string
+	}{
+		{`{"display_name": "x", "join_type": "azure_ad", "device_name_template": "CORP"}`, "every device gets the same name"},
+		{`{"display_name": "x", "join_type": "azure_ad", "device_name_template": "CORP_%SERIAL%"}`, "letters, digits and hyphens"},
+		{`{"display_name": "x", "join_type": "azure_ad", "device_name_template": "CORPORATE-%RAND:7%"}`, "17 characters"},
+		{`{"display_name": "x", "join_type": "azure_ad", "device_name_template": "CORP-%RANDOM%"}`, "%RANDOM% is not a supported macro"},
+		{`{"display_name": "x", "join_type": "azure_ad", "device_name_template": "CORP-%RAND:0%"}`, "%RAND:0% is not a supported macro"},
+		{`{"display_name": "x", "join_type": "azure_ad", "language": "English"}`, "locale"},
+		{`{"display_name": "x", "join_type": "hybrid_azure_ad", "device_name_template": "CORP-%SERIAL%"}`, "domain join profile"},
+		{`{"display_name": "x", "join_type": "hybrid_azure_ad", "deployment_mode": "self_deploying"}`, "Self-deploying mode"},
+		{`{"display_name": "x", "join_type": "azure_ad", "hybrid_skip_connectivity_check": false}`, "hybrid_skip_connectivity_check"},
+		{`{"display_name": "x", "join_type": "azure_ad", "deployment_mode": "self_deploying", "pre_provisioning_allowed": true}`, "Pre-provisioning"},
+		{`{"display_name": "x", "join_type": "azure_ad", "assignment": [{"target": [{"type": "all_users"}]}]}`, "assigned to devices"},
+	} {
+		if msg := env.applyExpectError("intune_autopilot_deployment_profile", nil, tc.config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.config, tc.want, msg)
+		}
+	}
+}
@@ -6,14 +6,17 @@ package provider
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
@@ -27,6 +30,9 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces
 var _ resource.Resource = &CompliancePolicyResource{}
 var _ resource.ResourceWithImportState = &CompliancePolicyResource{}
+var _ resource.ResourceWithUpgradeState = &CompliancePolicyResource{}
+var _ resource.ResourceWithValidateConfig = &CompliancePolicyResource{}
+var _ resource.ResourceWithModifyPlan = &CompliancePolicyResource{}
 
 // NewCompliancePolicyResource creates a new resource instance
 func NewCompliancePolicyResource() resource.Resource {
@@ -38,10 +44,12 @@ type CompliancePolicyResource struct {
 	client *clients.GraphClient
 }
 
-// CompliancePolicyResourceModel describes the resource data model for Windows 10 compliance
+// CompliancePolicyResourceModel describes the resource data model. The top-level settings are
+// those of Windows 10 compliance; other platforms are configured in their block.
 type CompliancePolicyResourceModel struct {
 	ID                                  types.String `tfsdk:"id"`
 	Type                                types.String `tfsdk:"type"`
+	Platform                            types.String `tfsdk:"platform"`
 	DisplayName                         types.String `tfsdk:"display_name"`
 	Description                         types.String `tfsdk:"description"`
 	RoleScopeTagIds                     types.List   `tfsdk:"role_scope_tag_ids"`
@@ -95,19 +103,75 @@ type CompliancePolicyResourceModel struct {
 
 	// Scheduled actions
 	ScheduledActionsForRule             types.List   `tfsdk:"scheduled_actions_for_rule"`
+
+	// Custom compliance
+	CustomCompliance                    types.List   `tfsdk:"custom_compliance"`
+
+	// Valid OS build ranges
+	ValidOsBuildRanges                  types.List   `tfsdk:"valid_os_build_range"`
+
+	// Other platforms
+	MacOS                               types.List   `tfsdk:"macos"`
+	IOS                                 types.List   `tfsdk:"ios"`
+	AndroidDeviceOwner                  types.List   `tfsdk:"android_device_owner"`
+	AndroidWorkProfile                  types.List   `tfsdk:"android_work_profile"`
 }
 
-// ScheduledActionModel represents scheduled action configuration
-type ScheduledActionModel struct {
-	RuleName                       types.String `tfsdk:"rule_name"`
-	ScheduledActionConfigurations  types.List   `tfsdk:"scheduled_action_configurations"`
+// platformBlocks returns the platform blocks of the model, keyed by block name
+func (m *CompliancePolicyResourceModel) platformBlocks() map[string]*types.List {
+	return map[string]*types.List{
+		"macos":                &m.MacOS,
+		"ios":                  &m.IOS,
+		"android_device_owner": &m.AndroidDeviceOwner,
+		"android_work_profile": &m.AndroidWorkProfile,
+	}
 }
 
-// ScheduledActionConfigurationModel represents action configuration
-type ScheduledActionConfigurationModel struct {
-	ActionType             types.String `tfsdk:"action_type"`
-	GracePeriodHours       types.Int64  `tfsdk:"grace_period_hours"`
-	NotificationTemplateId types.String `tfsdk:"notification_template_id"`
+// configuredPlatform returns the platform whose block is set, or nil for Windows
+func (m *CompliancePolicyResourceModel) configuredPlatform() *compliancePlatform {
+	blocks := m.platformBlocks()
+	for i := range compliancePlatforms {
+		if block := blocks[compliancePlatforms[i].block]; len(block.Elements()) > 0 {
+			return &compliancePlatforms[i]
+		}
+	}
+	return nil
+}
+
+// windowsSettings returns the Windows settings of the model, keyed by attribute name
+func (m *CompliancePolicyResourceModel) windowsSettings() map[string]attr.Value {
+	return map[string]attr.Value{
+		"password_required":                                m.PasswordRequired,
+		"password_block_simple":                            m.PasswordBlockSimple,
+		"password_required_to_unlock_from_idle":            m.PasswordRequiredToUnlockFromIdle,
+		"password_minutes_of_inactivity_before_lock":       m.PasswordMinutesOfInactivityBeforeLock,
+		"password_expiration_days":                         m.PasswordExpirationDays,
+		"password_minimum_length":                          m.PasswordMinimumLength,
+		"password_minimum_character_set_count":             m.PasswordMinimumCharacterSetCount,
+		"password_required_type":                           m.PasswordRequiredType,
+		"password_previous_password_block_count":           m.PasswordPreviousPasswordBlockCount,
+		"os_minimum_version":                               m.OsMinimumVersion,
+		"os_maximum_version":                               m.OsMaximumVersion,
+		"mobile_os_minimum_version":                        m.MobileOsMinimumVersion,
+		"mobile_os_maximum_version":                        m.MobileOsMaximumVersion,
+		"require_healthy_device_report":                    m.RequireHealthyDeviceReport,
+		"early_launch_anti_malware_driver_enabled":         m.EarlyLaunchAntiMalwareDriverEnabled,
+		"bitlocker_enabled":                                m.BitLockerEnabled,
+		"secure_boot_enabled":                              m.SecureBootEnabled,
+		"code_integrity_enabled":                           m.CodeIntegrityEnabled,
+		"storage_require_encryption":                       m.StorageRequireEncryption,
+		"tpm_required":                                     m.TpmRequired,
+		"active_firewall_required":                         m.ActiveFirewallRequired,
+		"defender_enabled":                                 m.DefenderEnabled,
+		"defender_version":                                 m.DefenderVersion,
+		"signature_out_of_date":                            m.SignatureOutOfDate,
+		"rtp_enabled":                                      m.RtpEnabled,
+		"antivirus_required":                               m.AntivirusRequired,
+		"anti_spyware_required":                            m.AntiSpywareRequired,
+		"device_threat_protection_enabled":                 m.DeviceThreatProtectionEnabled,
+		"device_threat_protection_required_security_level": m.DeviceThreatProtectionRequiredSecurityLevel,
+		"configuration_manager_compliance_required":        m.ConfigurationManagerComplianceRequired,
+	}
 }
 
 // Metadata returns the resource type name
@@ -118,13 +182,19 @@ func (r *CompliancePolicyResource) Metadata(ctx context.Context, req resource.Me
 // Schema defines the schema for the resource
 func (r *CompliancePolicyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		Description: "Manages a Windows 10/11 device compliance policy in Microsoft Intune.",
+		Version:     1,
+		Description: "Manages a Windows 10/11, macOS, iOS/iPadOS or Android Enterprise device compliance policy in Microsoft Intune.",
 		MarkdownDescription: `
-Manages a Windows 10/11 device compliance policy in Microsoft Intune.
+Manages a Windows 10/11, macOS, iOS/iPadOS or Android Enterprise device compliance policy in Microsoft Intune.
 
 Compliance policies define the rules and settings that devices must meet to be considered compliant.
 Non-compliant devices can be blocked from accessing corporate resources.
 
+The top-level settings configure a Windows policy. Policies for other platforms are configured in
+one of the ` + "`macos`" + `, ` + "`ios`" + `, ` + "`android_device_owner`" + ` and ` + "`android_work_profile`" + `
+blocks instead, which cannot be combined with the Windows settings. Linux compliance policies are
+built from Settings Catalog settings and are managed with ` + "`intune_linux_compliance_policy`" + `.
+
 ## Example Usage
 
 ` + "```hcl" + `
@@ -166,6 +236,20 @@ resource "intune_compliance_policy" "windows" {
   }
 }
 ` + "```" + `
+
+` + "```hcl" + `
+resource "intune_compliance_policy" "macos" {
+  display_name = "macOS Compliance Policy"
+
+  macos {
+    password_required                   = true
+    password_minimum_length             = 8
+    storage_require_encryption          = true
+    system_integrity_protection_enabled = true
+    os_minimum_version                  = "14.5"
+  }
+}
+` + "```" + `
 `,
 
 		Attributes: map[string]schema.Attribute{
@@ -183,6 +267,11 @@ resource "intune_compliance_policy" "windows" {
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"platform": schema.StringAttribute{
+				Description: "The platform of the policy, set by the platform block: windows10, macOS, iOS, " +
+					"androidDeviceOwner or androidWorkProfile. Changing the platform replaces the policy.",
+				Computed: true,
+			},
 			"display_name": schema.StringAttribute{
 				Description: "The display name of the compliance policy.",
 				Required:    true,
@@ -258,12 +347,18 @@ resource "intune_compliance_policy" "windows" {
 
 			// OS version settings
 			"os_minimum_version": schema.StringAttribute{
-				Description: "Minimum OS version required.",
+				Description: "Minimum OS version required, e.g. 10.0.22631.2506. A version without revision starts at revision 0 of its build.",
 				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(windowsVersionPattern, windowsVersionPatternMessage),
+				},
 			},
 			"os_maximum_version": schema.StringAttribute{
-				Description: "Maximum OS version allowed.",
+				Description: "Maximum OS version allowed. A version without revision includes every revision of its build.",
 				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(windowsVersionPattern, windowsVersionPatternMessage),
+				},
 			},
 			"mobile_os_minimum_version": schema.StringAttribute{
 				Description: "Minimum mobile OS version required.",
@@ -386,48 +481,16 @@ resource "intune_compliance_policy" "windows" {
 			},
 		},
 		Blocks: map[string]schema.Block{
-			"assignment": AssignmentBlockSchema(),
-			"scheduled_actions_for_rule": schema.ListNestedBlock{
-				Description: "Scheduled actions for non-compliance.",
-				NestedObject: schema.NestedBlockObject{
-					Attributes: map[string]schema.Attribute{
-						"rule_name": schema.StringAttribute{
-							Description: "The rule name. Use 'DeviceNotCompliant' for the default rule.",
-							Optional:    true,
-							Computed:    true,
-							Default:     stringdefault.StaticString("DeviceNotCompliant"),
-						},
-					},
-					Blocks: map[string]schema.Block{
-						"scheduled_action_configurations": schema.ListNestedBlock{
-							Description: "Action configurations for non-compliance.",
-							NestedObject: schema.NestedBlockObject{
-								Attributes: map[string]schema.Attribute{
-									"action_type": schema.StringAttribute{
-										Description: "The action type. Valid values: block, retire, wipe, removeResourceAccess, pushNotification.",
-										Required:    true,
-										Validators: []validator.String{
-											stringvalidator.OneOf("block", "retire", "wipe", "removeResourceAccessOutsideResource", "pushNotification"),
-										},
-									},
-									"grace_period_hours": schema.Int64Attribute{
-										Description: "Number of hours before the action is enforced. 0 for immediate.",
-										Optional:    true,
-										Computed:    true,
-										Default:     int64default.StaticInt64(0),
-									},
-									"notification_template_id": schema.StringAttribute{
-										Description: "The notification template ID to use.",
-										Optional:    true,
-									},
-								},
-							},
-						},
-					},
-				},
-			},
+			"assignment":                 AssignmentBlockSchema(),
+			"scheduled_actions_for_rule": ScheduledActionsBlockSchema(),
+			"custom_compliance":          customComplianceBlockSchema(),
+			"valid_os_build_range":       osBuildRangeBlockSchema(),
 		},
 	}
+
+	for i := range compliancePlatforms {
+		resp.Schema.Blocks[compliancePlatforms[i].block] = compliancePlatforms[i].schema()
+	}
 }
 
 // Configure adds the provider configured client to the resource
@@ -448,6 +511,112 @@ func (r *CompliancePolicyResource) Configure(ctx context.Context, req resource.C
 	r.client = providerData.GraphClient
 }
 
+// ValidateConfig checks the scheduled actions, that at most one platform block is set, and that
+// policies for other platforms do not set Windows settings
+func (r *CompliancePolicyResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data CompliancePolicyResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ValidateScheduledActions(ctx, data.ScheduledActionsForRule, &resp.Diagnostics)
+	validateWindowsVersions(ctx, &data, &resp.Diagnostics)
+
+	var configured []string
+	for name, block := range data.platformBlocks() {
+		if block.IsUnknown() {
+			return
+		}
+		if len(block.Elements()) > 0 {
+			configured = append(configured, name)
+		}
+	}
+	if len(configured) == 0 {
+		return
+	}
+	if len(data.CustomCompliance.Elements()) > 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("custom_compliance"),
+			"Conflicting Compliance Settings",
+			fmt.Sprintf("custom_compliance is only supported by Windows policies and cannot be combined with the %s block.", strings.Join(configured, ", ")),
+		)
+	}
+	if len(data.ValidOsBuildRanges.Elements()) > 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("valid_os_build_range"),
+			"Conflicting Compliance Settings",
+			fmt.Sprintf("valid_os_build_range is only supported by Windows policies and cannot be combined with the %s block.", strings.Join(configured, ", ")),
+		)
+	}
+	if len(configured) > 1 {
+		sort.Strings(configured)
+		resp.Diagnostics.AddError(
+			"Conflicting Compliance Platforms",
+			fmt.Sprintf("Only one platform block may be specified, got: %s.", strings.Join(configured, ", ")),
+		)
+		return
+	}
+
+	for name, value := range data.windowsSettings() {
+		if !value.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(name),
+				"Conflicting Compliance Settings",
+				fmt.Sprintf("%s is a Windows setting and cannot be combined with the %s block.", name, configured[0]),
+			)
+		}
+	}
+}
+
+// ModifyPlan sets platform from the platform block and replaces the policy when its platform
+// changes, as Graph cannot change the type of a policy. It also checks that the referenced
+// notification templates exist.
+func (r *CompliancePolicyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var actions types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("scheduled_actions_for_rule"), &actions)...)
+	ValidateNotificationTemplates(ctx, r.client, actions, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var config CompliancePolicyResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for _, block := range config.platformBlocks() {
+		if block.IsUnknown() {
+			return
+		}
+	}
+
+	platform := types.StringValue(config.platform())
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("platform"), platform)...)
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var statePlatform types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("platform"), &statePlatform)...)
+	if !statePlatform.IsNull() && !statePlatform.Equal(platform) {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("platform"))
+	}
+}
+
+// platform returns the platform of the policy configured in the model
+func (m *CompliancePolicyResourceModel) platform() string {
+	if platform := m.configuredPlatform(); platform != nil {
+		return platform.platform
+	}
+	return windowsCompliancePlatform
+}
+
 // Create creates the resource and sets the initial Terraform state
 func (r *CompliancePolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data CompliancePolicyResourceModel
@@ -462,7 +631,7 @@ func (r *CompliancePolicyResource) Create(ctx context.Context, req resource.Crea
 	})
 
 	// Build the policy object
-	policy := r.buildPolicy(&data, &resp.Diagnostics)
+	policy := r.buildPolicy(ctx, &data, false, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -480,9 +649,21 @@ func (r *CompliancePolicyResource) Create(ctx context.Context, req resource.Crea
 	// Update the model with the created policy data
 	data.ID = types.StringValue(created.ID)
 	data.Type = types.StringValue(PolicyTypeCompliance)
+	data.Platform = types.StringValue(data.platform())
 	data.CreatedDateTime = types.StringValue(created.CreatedDateTime)
 	data.LastModifiedDateTime = types.StringValue(created.LastModifiedDateTime)
 
+	// Read back the scheduled actions for the IDs Graph assigned to them
+	actions, err := r.client.GetCompliancePolicyScheduledActions(ctx, created.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Scheduled Actions",
+			fmt.Sprintf("Policy was created but its scheduled actions could not be read: %s", err),
+		)
+		return
+	}
+	data.ScheduledActionsForRule = ScheduledActionsValue(ctx, data.ScheduledActionsForRule, actions, &resp.Diagnostics)
+
 	// Handle assignments if specified
 	if len(data.Assignment) > 0 {
 		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
@@ -519,11 +700,13 @@ func (r *CompliancePolicyResource) Read(ctx context.Context, req resource.ReadRe
 		"id": data.ID.ValueString(),
 	})
 
-	// Get the policy
-	policy, err := r.client.GetCompliancePolicy(ctx, data.ID.ValueString())
+	// Get the policy and its scheduled actions, together with its assignments if the state had
+	// assignments configured
+	path := fmt.Sprintf("%s/%s?$expand=scheduledActionsForRule($expand=scheduledActionConfigurations)", clients.PathCompliancePolicies, data.ID.ValueString())
+	result, err := readPolicyWithAssignments[clients.CompliancePolicy](ctx, r.client, PolicyTypeCompliance, path, data.ID.ValueString(), len(data.Assignment) > 0)
 	if err != nil {
 		// Check if policy was deleted
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			resp.State.RemoveResource(ctx)
 			return
 		}
@@ -535,20 +718,20 @@ func (r *CompliancePolicyResource) Read(ctx context.Context, req resource.ReadRe
 	}
 
 	// Update the model
-	r.updateModel(&data, policy, &resp.Diagnostics)
+	r.updateModel(&data, result.Policy, &resp.Diagnostics)
+	data.ScheduledActionsForRule = ScheduledActionsValue(ctx, data.ScheduledActionsForRule, result.Policy.ScheduledActionsForRule, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Read assignments if the state had assignments configured
+	// Update assignments if the state had assignments configured
 	if len(data.Assignment) > 0 {
-		assignments, err := ReadPolicyAssignments(ctx, r.client, PolicyTypeCompliance, data.ID.ValueString())
-		if err != nil {
+		if result.AssignmentsErr != nil {
 			tflog.Warn(ctx, "Failed to read policy assignments", map[string]interface{}{
-				"error": err.Error(),
+				"error": result.AssignmentsErr.Error(),
 			})
 		} else {
-			data.Assignment = assignments
+			data.Assignment = PreserveAssignments(data.Assignment, result.Assignments)
 		}
 	}
 
@@ -558,8 +741,10 @@ func (r *CompliancePolicyResource) Read(ctx context.Context, req resource.ReadRe
 // Update updates the resource and sets the updated Terraform state
 func (r *CompliancePolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var data CompliancePolicyResourceModel
+	var stateActions types.List
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("scheduled_actions_for_rule"), &stateActions)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -568,11 +753,14 @@ func (r *CompliancePolicyResource) Update(ctx context.Context, req resource.Upda
 		"id": data.ID.ValueString(),
 	})
 
-	// Build the policy update object
-	policy := r.buildPolicy(&data, &resp.Diagnostics)
+	// Build the policy update object. Graph does not update scheduled actions with the policy.
+	policy := r.buildPolicy(ctx, &data, true, &resp.Diagnostics)
+	priorActions := BuildScheduledActions(ctx, stateActions, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	actions := policy.ScheduledActionsForRule
+	policy.ScheduledActionsForRule = nil
 
 	// Update the policy
 	updated, err := r.client.UpdateCompliancePolicy(ctx, data.ID.ValueString(), policy)
@@ -584,7 +772,28 @@ func (r *CompliancePolicyResource) Update(ctx context.Context, req resource.Upda
 		return
 	}
 
+	// Replace the scheduled actions if they changed, and read them back for their IDs
+	if !reflect.DeepEqual(actions, priorActions) {
+		if err := r.client.ScheduleCompliancePolicyActions(ctx, data.ID.ValueString(), actions); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Scheduled Actions",
+				fmt.Sprintf("Could not update scheduled actions of policy ID %s: %s", data.ID.ValueString(), err),
+			)
+			return
+		}
+	}
+	scheduled, err := r.client.GetCompliancePolicyScheduledActions(ctx, data.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Scheduled Actions",
+			fmt.Sprintf("Could not read scheduled actions of policy ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+	data.ScheduledActionsForRule = ScheduledActionsValue(ctx, data.ScheduledActionsForRule, scheduled, &resp.Diagnostics)
+
 	// Update the model with the updated policy data
+	data.Platform = types.StringValue(data.platform())
 	data.LastModifiedDateTime = types.StringValue(updated.LastModifiedDateTime)
 
 	// Handle assignments
@@ -629,7 +838,7 @@ func (r *CompliancePolicyResource) Delete(ctx context.Context, req resource.Dele
 	err := r.client.DeleteCompliancePolicy(ctx, data.ID.ValueString())
 	if err != nil {
 		// Ignore not found errors during delete
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			return
 		}
 		resp.Diagnostics.AddError(
@@ -640,17 +849,53 @@ func (r *CompliancePolicyResource) Delete(ctx context.Context, req resource.Dele
 	}
 }
 
+// UpgradeState upgrades the assignment blocks of prior schema versions
+func (r *CompliancePolicyResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return assignmentStateUpgraders()
+}
+
 // ImportState imports the resource state
 func (r *CompliancePolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
 
-// buildPolicy builds the API policy object from the Terraform model
-func (r *CompliancePolicyResource) buildPolicy(data *CompliancePolicyResourceModel, diags *diag.Diagnostics) *clients.CompliancePolicy {
+// buildPolicy builds the API policy object from the Terraform model. Policies for other platforms
+// are built from their block like device configuration profiles: unset booleans are false, and
+// on update unset strings and lists are cleared.
+func (r *CompliancePolicyResource) buildPolicy(ctx context.Context, data *CompliancePolicyResourceModel, update bool, diags *diag.Diagnostics) *clients.CompliancePolicy {
+	var policy *clients.CompliancePolicy
+	if platform := data.configuredPlatform(); platform != nil {
+		policy = &clients.CompliancePolicy{
+			ODataType:  platform.odataType,
+			Properties: buildProfileBlock(ctx, r.client, platform.fields, *data.platformBlocks()[platform.block], update, diags),
+		}
+	} else {
+		policy = r.buildWindowsPolicy(ctx, data, update, diags)
+	}
+	policy.DisplayName = data.DisplayName.ValueString()
+	policy.Description = data.Description.ValueString()
+
+	// Role scope tags
+	if !data.RoleScopeTagIds.IsNull() {
+		var tagIds []string
+		diags.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		policy.RoleScopeTagIds = tagIds
+	} else {
+		policy.RoleScopeTagIds = []string{"0"}
+	}
+
+	// Scheduled actions - default to marking device non-compliant immediately if not specified
+	policy.ScheduledActionsForRule = BuildScheduledActions(ctx, data.ScheduledActionsForRule, diags)
+
+	return policy
+}
+
+// buildWindowsPolicy builds a Windows 10 compliance policy from the top-level settings, the
+// custom_compliance block and the valid_os_build_range blocks. On update, a removed
+// custom_compliance block detaches the script, and removed build ranges and OS versions are cleared.
+func (r *CompliancePolicyResource) buildWindowsPolicy(ctx context.Context, data *CompliancePolicyResourceModel, update bool, diags *diag.Diagnostics) *clients.CompliancePolicy {
 	policy := &clients.CompliancePolicy{
-		ODataType:   "#microsoft.graph.windows10CompliancePolicy",
-		DisplayName: data.DisplayName.ValueString(),
-		Description: data.Description.ValueString(),
+		ODataType: windowsComplianceODataType,
 
 		// Password settings
 		PasswordRequired:                 data.PasswordRequired.ValueBool(),
@@ -707,10 +952,10 @@ func (r *CompliancePolicyResource) buildPolicy(data *CompliancePolicyResourceMod
 
 	// Optional string fields
 	if !data.OsMinimumVersion.IsNull() {
-		policy.OsMinimumVersion = data.OsMinimumVersion.ValueString()
+		policy.OsMinimumVersion = normalizeWindowsVersion(data.OsMinimumVersion.ValueString(), false)
 	}
 	if !data.OsMaximumVersion.IsNull() {
-		policy.OsMaximumVersion = data.OsMaximumVersion.ValueString()
+		policy.OsMaximumVersion = normalizeWindowsVersion(data.OsMaximumVersion.ValueString(), true)
 	}
 	if !data.MobileOsMinimumVersion.IsNull() {
 		policy.MobileOsMinimumVersion = data.MobileOsMinimumVersion.ValueString()
@@ -722,39 +967,66 @@ func (r *CompliancePolicyResource) buildPolicy(data *CompliancePolicyResourceMod
 		policy.DefenderVersion = data.DefenderVersion.ValueString()
 	}
 
-	// Role scope tags
-	if !data.RoleScopeTagIds.IsNull() {
-		var tagIds []string
-		diags.Append(data.RoleScopeTagIds.ElementsAs(context.Background(), &tagIds, false)...)
-		policy.RoleScopeTagIds = tagIds
-	} else {
-		policy.RoleScopeTagIds = []string{"0"}
-	}
+	// Custom compliance and valid OS build ranges
+	policy.DeviceCompliancePolicyScript = buildCustomCompliance(ctx, data.CustomCompliance, diags)
+	policy.ValidOperatingSystemBuildRanges = buildOsBuildRanges(ctx, data.ValidOsBuildRanges, diags)
 
-	// Scheduled actions - default to marking device non-compliant immediately if not specified
-	policy.ScheduledActionsForRule = []clients.ComplianceScheduledAction{
-		{
-			RuleName: "DeviceNotCompliant",
-			ScheduledActionConfigurations: []clients.ScheduledActionConfiguration{
-				{
-					ActionType:       "block",
-					GracePeriodHours: 0,
-				},
-			},
-		},
+	// On update, clear what is no longer configured
+	if update {
+		policy.Properties = make(map[string]interface{})
+		if policy.DeviceCompliancePolicyScript == nil {
+			policy.Properties["deviceCompliancePolicyScript"] = nil
+		}
+		if len(policy.ValidOperatingSystemBuildRanges) == 0 {
+			policy.Properties["validOperatingSystemBuildRanges"] = []interface{}{}
+		}
+		if data.OsMinimumVersion.IsNull() {
+			policy.Properties["osMinimumVersion"] = nil
+		}
+		if data.OsMaximumVersion.IsNull() {
+			policy.Properties["osMaximumVersion"] = nil
+		}
 	}
 
 	return policy
 }
 
-// updateModel updates the Terraform model from the API policy
+// updateModel updates the Terraform model from the API policy. Policies for other platforms are
+// read into the block of their platform, and their Windows settings keep the schema defaults.
 func (r *CompliancePolicyResource) updateModel(data *CompliancePolicyResourceModel, policy *clients.CompliancePolicy, diags *diag.Diagnostics) {
 	data.DisplayName = types.StringValue(policy.DisplayName)
 	data.Type = types.StringValue(PolicyTypeCompliance)
-	data.Description = types.StringValue(policy.Description)
+	data.Description = optionalStringValue(data.Description, policy.Description)
 	data.CreatedDateTime = types.StringValue(policy.CreatedDateTime)
 	data.LastModifiedDateTime = types.StringValue(policy.LastModifiedDateTime)
 
+	// Role scope tags
+	data.RoleScopeTagIds = roleScopeTagIdsValue(context.Background(), data.RoleScopeTagIds, policy.RoleScopeTagIds, diags)
+
+	if policy.ODataType == windowsComplianceODataType {
+		data.Platform = types.StringValue(windowsCompliancePlatform)
+		r.updateWindowsSettings(data, policy)
+		data.CustomCompliance = customComplianceValue(context.Background(), data.CustomCompliance, policy.DeviceCompliancePolicyScript, diags)
+		data.ValidOsBuildRanges = osBuildRangesValue(context.Background(), data.ValidOsBuildRanges, policy.ValidOperatingSystemBuildRanges, diags)
+		return
+	}
+
+	platform := findCompliancePlatform(policy.ODataType)
+	if platform == nil {
+		diags.AddError(
+			"Unsupported Compliance Policy Type",
+			fmt.Sprintf("Policy ID %s is a %s, which intune_compliance_policy does not support.", policy.ID, policy.ODataType),
+		)
+		return
+	}
+	data.Platform = types.StringValue(platform.platform)
+	data.resetWindowsSettings()
+	block := data.platformBlocks()[platform.block]
+	*block = readProfileBlock(context.Background(), platform.fields, *block, policy.Properties, diags)
+}
+
+// updateWindowsSettings updates the Windows settings of the model from a Windows 10 policy
+func (r *CompliancePolicyResource) updateWindowsSettings(data *CompliancePolicyResourceModel, policy *clients.CompliancePolicy) {
 	// Password settings
 	data.PasswordRequired = types.BoolValue(policy.PasswordRequired)
 	data.PasswordBlockSimple = types.BoolValue(policy.PasswordBlockSimple)
@@ -804,10 +1076,10 @@ func (r *CompliancePolicyResource) updateModel(data *CompliancePolicyResourceMod
 
 	// Optional string fields
 	if policy.OsMinimumVersion != "" {
-		data.OsMinimumVersion = types.StringValue(policy.OsMinimumVersion)
+		data.OsMinimumVersion = windowsVersionValue(data.OsMinimumVersion, policy.OsMinimumVersion, false)
 	}
 	if policy.OsMaximumVersion != "" {
-		data.OsMaximumVersion = types.StringValue(policy.OsMaximumVersion)
+		data.OsMaximumVersion = windowsVersionValue(data.OsMaximumVersion, policy.OsMaximumVersion, true)
 	}
 	if policy.MobileOsMinimumVersion != "" {
 		data.MobileOsMinimumVersion = types.StringValue(policy.MobileOsMinimumVersion)
@@ -818,11 +1090,31 @@ func (r *CompliancePolicyResource) updateModel(data *CompliancePolicyResourceMod
 	if policy.DefenderVersion != "" {
 		data.DefenderVersion = types.StringValue(policy.DefenderVersion)
 	}
+}
 
-	// Role scope tags
-	if len(policy.RoleScopeTagIds) > 0 {
-		tagIds, d := types.ListValueFrom(context.Background(), types.StringType, policy.RoleScopeTagIds)
-		diags.Append(d...)
-		data.RoleScopeTagIds = tagIds
+// resetWindowsSettings sets the Windows settings of the model to the values of an unconfigured
+// policy, so policies for other platforms do not plan changes to them
+func (m *CompliancePolicyResourceModel) resetWindowsSettings() {
+	for _, b := range []*types.Bool{
+		&m.PasswordRequired, &m.PasswordBlockSimple, &m.PasswordRequiredToUnlockFromIdle, &m.RequireHealthyDeviceReport,
+		&m.EarlyLaunchAntiMalwareDriverEnabled, &m.BitLockerEnabled, &m.SecureBootEnabled, &m.CodeIntegrityEnabled,
+		&m.StorageRequireEncryption, &m.TpmRequired, &m.ActiveFirewallRequired, &m.DefenderEnabled, &m.SignatureOutOfDate,
+		&m.RtpEnabled, &m.AntivirusRequired, &m.AntiSpywareRequired, &m.DeviceThreatProtectionEnabled,
+		&m.ConfigurationManagerComplianceRequired,
+	} {
+		*b = types.BoolValue(false)
+	}
+	for _, n := range []*types.Int64{
+		&m.PasswordMinutesOfInactivityBeforeLock, &m.PasswordExpirationDays, &m.PasswordMinimumLength,
+		&m.PasswordMinimumCharacterSetCount, &m.PasswordPreviousPasswordBlockCount,
+	} {
+		*n = types.Int64Null()
+	}
+	for _, s := range []*types.String{
+		&m.OsMinimumVersion, &m.OsMaximumVersion, &m.MobileOsMinimumVersion, &m.MobileOsMaximumVersion, &m.DefenderVersion,
+	} {
+		*s = types.StringNull()
 	}
+	m.PasswordRequiredType = types.StringValue("deviceDefault")
+	m.DeviceThreatProtectionRequiredSecurityLevel = types.StringValue("notSet")
 }
@@ -0,0 +1,497 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+func TestAccCompliancePolicyResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Windows baseline",
+		"password_required": true,
+		"password_minimum_length": 12,
+		"bitlocker_enabled": true,
+		"os_minimum_version": "10.0.19045",
+		"assignment": [{"target": [{"type": "all_devices"}]}]
+	}`
+	res := env.apply("intune_compliance_policy", nil, config)
+	assertAttr(t, res.attrs(), "type", PolicyTypeCompliance)
+
+	policy := env.graph.Object(fakegraph.DeviceCompliancePolicies, res.id())
+	assertAttr(t, policy, "passwordMinimumLength", 12)
+	assertAttr(t, policy, "bitLockerEnabled", true)
+	if n := len(env.graph.Assignments(fakegraph.DeviceCompliancePolicies, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment in Graph, got %d", n)
+	}
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	updated := `{
+		"display_name": "Windows baseline",
+		"description": "Requires BitLocker and a strong password",
+		"password_required": true,
+		"password_minimum_length": 14,
+		"bitlocker_enabled": true,
+		"os_minimum_version": "10.0.19045",
+		"assignment": [{"target": [{"type": "group", "group_id": "00000000-0000-0000-0000-000000000001"}]}]
+	}`
+	res = env.apply("intune_compliance_policy", res, updated)
+	assertAttr(t, env.graph.Object(fakegraph.DeviceCompliancePolicies, res.id()), "passwordMinimumLength", 14)
+	res = env.refresh(res)
+	env.assertNoOp(res, updated)
+
+	imported := env.importState("intune_compliance_policy", res.id())
+	assertAttr(t, imported.attrs(), "password_minimum_length", 14)
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.DeviceCompliancePolicies, res.id()) != nil {
+		t.Errorf("policy %s still exists after destroy", res.id())
+	}
+}
+
+func TestAccCompliancePolicyResource_driftIsDetected(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{"display_name": "Windows baseline", "password_required": true}`
+	res := env.apply("intune_compliance_policy", nil, config)
+
+	env.graph.Update(fakegraph.DeviceCompliancePolicies, res.id(), map[string]interface{}{
+		"passwordRequired": false,
+	})
+
+	res = env.refresh(res)
+	assertAttr(t, res.attrs(), "password_required", false)
+
+	res = env.apply("intune_compliance_policy", res, config)
+	assertAttr(t, env.graph.Object(fakegraph.DeviceCompliancePolicies, res.id()), "passwordRequired", true)
+}
+
+func TestAccCompliancePolicyResource_upgradeAssignments(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Windows baseline",
+		"password_minimum_length": 12,
+		"assignment": [{"target": [
+			{"type": "group", "group_id": "00000000-0000-0000-0000-000000000001", "filter_id": "filter-x", "filter_type": "exclude"},
+			{"type": "all_users", "filter_id": "filter-x", "filter_type": "exclude"},
+			{"type": "exclusion", "group_id": "00000000-0000-0000-0000-000000000002"}
+		]}]
+	}`
+	created := env.apply("intune_compliance_policy", nil, config)
+
+	// Schema version 0 listed groups and applied one filter to every target
+	state := created.attrs()
+	state["assignment"] = []interface{}{map[string]interface{}{
+		"include_groups": []interface{}{"00000000-0000-0000-0000-000000000001"},
+		"exclude_groups": []interface{}{"00000000-0000-0000-0000-000000000002"},
+		"all_devices":    nil,
+		"all_users":      true,
+		"filter_id":      "filter-x",
+		"filter_type":    "exclude",
+	}}
+	legacy, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("encoding state: %s", err)
+	}
+	res := env.upgradeState("intune_compliance_policy", 0, string(legacy))
+	assertAttr(t, res.attrs(), "password_minimum_length", 12)
+
+	env.assertNoOp(env.refresh(res), config)
+}
+
+func TestAccCompliancePolicyResource_platforms(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		block     string
+		settings  string
+		platform  string
+		odataType string
+		property  string
+		want      interface{}
+	}{
+		{"macos", `{"password_required": true, "password_minimum_length": 8, "os_minimum_version": "14.5", "gatekeeper_allowed_app_source": "macAppStore"}`,
+			"macOS", "#microsoft.graph.macOSCompliancePolicy", "passwordMinimumLength", 8},
+		{"ios", `{"passcode_required": true, "security_block_jailbroken_devices": true, "os_minimum_version": "17.0"}`,
+			"iOS", "#microsoft.graph.iosCompliancePolicy", "securityBlockJailbrokenDevices", true},
+		{"android_device_owner", `{"password_required": true, "password_required_type": "numericComplex", "min_android_security_patch_level": "2024-05-01"}`,
+			"androidDeviceOwner", "#microsoft.graph.androidDeviceOwnerCompliancePolicy", "minAndroidSecurityPatchLevel", "2024-05-01"},
+		{"android_work_profile", `{"required_password_complexity": "high", "security_block_jailbroken_devices": true}`,
+			"androidWorkProfile", "#microsoft.graph.androidWorkProfileCompliancePolicy", "requiredPasswordComplexity", "high"},
+	} {
+		config := `{"display_name": "` + tc.block + ` baseline", "` + tc.block + `": [` + tc.settings + `],
+			"assignment": [{"target": [{"type": "all_devices"}]}]}`
+		res := env.apply("intune_compliance_policy", nil, config)
+		assertAttr(t, res.attrs(), "platform", tc.platform)
+
+		policy := env.graph.Object(fakegraph.DeviceCompliancePolicies, res.id())
+		assertAttr(t, policy, "@odata.type", tc.odataType)
+		assertAttr(t, policy, tc.property, tc.want)
+		if _, ok := policy["bitLockerEnabled"]; ok {
+			t.Errorf("%s: expected no Windows settings in Graph, got %v", tc.block, policy)
+		}
+
+		res = env.refresh(res)
+		env.assertNoOp(res, config)
+
+		imported := env.importState("intune_compliance_policy", res.id())
+		assertAttr(t, imported.attrs(), "platform", tc.platform)
+		if blocks, _ := imported.attrs()[tc.block].([]interface{}); len(blocks) != 1 {
+			t.Errorf("%s: expected the imported policy to have one %s block, got %v", tc.block, tc.block, imported.attrs()[tc.block])
+		}
+
+		env.destroy(res)
+	}
+}
+
+func TestAccCompliancePolicyResource_platformChangeReplaces(t *testing.T) {
+	env := newTestEnv(t)
+
+	res := env.apply("intune_compliance_policy", nil, `{"display_name": "Baseline", "bitlocker_enabled": true}`)
+	assertAttr(t, res.attrs(), "platform", "windows10")
+
+	config := `{"display_name": "Baseline", "macos": [{"storage_require_encryption": true}]}`
+	replaced := env.apply("intune_compliance_policy", res, config)
+	if replaced.id() == res.id() {
+		t.Errorf("expected a new policy when the platform changes, got the same ID %s", res.id())
+	}
+	if env.graph.Object(fakegraph.DeviceCompliancePolicies, res.id()) != nil {
+		t.Errorf("policy %s still exists after the replacement", res.id())
+	}
+	assertAttr(t, env.graph.Object(fakegraph.DeviceCompliancePolicies, replaced.id()), "@odata.type", "#microsoft.graph.macOSCompliancePolicy")
+
+	// Changing settings of the same platform updates in place
+	updated := env.apply("intune_compliance_policy", replaced, `{"display_name": "Baseline", "macos": [{"storage_require_encryption": true, "firewall_enabled": true}]}`)
+	if updated.id() != replaced.id() {
+		t.Errorf("expected the policy to be updated in place")
+	}
+	assertAttr(t, env.graph.Object(fakegraph.DeviceCompliancePolicies, updated.id()), "firewallEnabled", true)
+}
+
+func TestAccCompliancePolicyResource_invalidPlatform(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		config string
+		want   string
+	}{
+		{`{"display_name": "x", "bitlocker_enabled": true, "macos": [{"firewall_enabled": true}]}`, "Conflicting Compliance Settings"},
+		{`{"display_name": "x", "macos": [{"firewall_enabled": true}], "ios": [{"passcode_required": true}]}`, "Conflicting Compliance Platforms"},
+		{`{"display_name": "x", "ios": [{"passcode_minimum_length": 2}]}`, "passcode_minimum_length"},
+		{`{"display_name": "x", "android_work_profile": [{"min_android_security_patch_level": "May 2024"}]}`, "YYYY-MM-DD"},
+		{`{"display_name": "x", "macos": [{"os_minimum_version": "Sonoma"}]}`, "version number"},
+	} {
+		if msg := env.applyExpectError("intune_compliance_policy", nil, tc.config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.config, tc.want, msg)
+		}
+	}
+}
+
+// testComplianceRules is a valid custom compliance rules document
+const testComplianceRules = `{
+  "Rules": [
+    {
+      "SettingName": "BiosVersion",
+      "Operator": "GreaterEquals",
+      "DataType": "Version",
+      "Operand": "2.3",
+      "MoreInfoUrl": "https://example.com/bios",
+      "RemediationStrings": [
+        {"Language": "en_US", "Title": "BIOS is out of date", "Description": "Update the BIOS to 2.3 or later."}
+      ]
+    }
+  ]
+}`
+
+func TestAccCompliancePolicyResource_customCompliance(t *testing.T) {
+	env := newTestEnv(t)
+
+	script := env.apply("intune_device_compliance_script", nil, fmt.Sprintf(`{"display_name": "BIOS version", "detection_script_content": %q}`, testComplianceScript))
+
+	config := fmt.Sprintf(`{
+		"display_name": "Windows baseline",
+		"custom_compliance": [{"script_id": %q, "rules_json": %q}]
+	}`, script.id(), testComplianceRules)
+	res := env.apply("intune_compliance_policy", nil, config)
+
+	attached, _ := env.graph.Object(fakegraph.DeviceCompliancePolicies, res.id())["deviceCompliancePolicyScript"].(map[string]interface{})
+	assertAttr(t, attached, "deviceComplianceScriptId", script.id())
+	rules, _ := base64.StdEncoding.DecodeString(fmt.Sprint(attached["rulesContent"]))
+	if !sameJSON(string(rules), testComplianceRules) {
+		t.Errorf("unexpected rules in Graph: %s", rules)
+	}
+
+	// Graph returns the rules without the configured formatting
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(testComplianceRules)); err != nil {
+		t.Fatal(err)
+	}
+	attached["rulesContent"] = base64.StdEncoding.EncodeToString(compact.Bytes())
+	env.graph.Update(fakegraph.DeviceCompliancePolicies, res.id(), map[string]interface{}{"deviceCompliancePolicyScript": attached})
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	imported := env.importState("intune_compliance_policy", res.id())
+	if blocks, _ := imported.attrs()["custom_compliance"].([]interface{}); len(blocks) != 1 {
+		t.Errorf("expected the imported policy to have one custom_compliance block, got %v", imported.attrs()["custom_compliance"])
+	}
+
+	// Removing the block detaches the script
+	res = env.apply("intune_compliance_policy", res, `{"display_name": "Windows baseline"}`)
+	if attached := env.graph.Object(fakegraph.DeviceCompliancePolicies, res.id())["deviceCompliancePolicyScript"]; attached != nil {
+		t.Errorf("expected the script to be detached, got %v", attached)
+	}
+	env.assertNoOp(env.refresh(res), `{"display_name": "Windows baseline"}`)
+}
+
+func TestAccCompliancePolicyResource_invalidCustomCompliance(t *testing.T) {
+	env := newTestEnv(t)
+
+	rule := func(replace ...string) string {
+		return strings.NewReplacer(replace...).Replace(testComplianceRules)
+	}
+	for _, tc := range []struct {
+		rules string
+		want  string
+	}{
+		{`{"Rules": []}`, "at least one rule"},
+		{`{"Rules": [{"SettingName": "BiosVersion", "Severity": "High"}]}`, "Severity"},
+		{rule(`"Version"`, `"Float"`), "DataType"},
+		{rule(`"GreaterEquals"`, `"Contains"`), "Operator"},
+		{rule(`"Version"`, `"String"`, `"2.3"`, `"2.3"`), "cannot be used with DataType String"},
+		{rule(`"2.3"`, `"latest"`), "not a valid Version value"},
+		{rule(`https://example.com/bios`, `example.com/bios`), "MoreInfoUrl"},
+		{rule(`"Title": "BIOS is out of date", `, ``), "Title"},
+	} {
+		config := fmt.Sprintf(`{"display_name": "x", "custom_compliance": [{"script_id": "00000000-0000-0000-0000-000000000001", "rules_json": %q}]}`, tc.rules)
+		if msg := env.applyExpectError("intune_compliance_policy", nil, config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.rules, tc.want, msg)
+		}
+	}
+
+	// The script must exist
+	config := fmt.Sprintf(`{"display_name": "x", "custom_compliance": [{"script_id": "00000000-0000-0000-0000-000000000001", "rules_json": %q}]}`, testComplianceRules)
+	if msg := env.applyExpectError("intune_compliance_policy", nil, config); !strings.Contains(msg, "does not exist") {
+		t.Errorf("expected a missing script to fail, got: %s", msg)
+	}
+
+	// Custom compliance is Windows only
+	config = fmt.Sprintf(`{"display_name": "x", "macos": [{"firewall_enabled": true}], "custom_compliance": [{"script_id": "s", "rules_json": %q}]}`, testComplianceRules)
+	if msg := env.applyExpectError("intune_compliance_policy", nil, config); !strings.Contains(msg, "only supported by Windows policies") {
+		t.Errorf("expected custom_compliance with macos to fail, got: %s", msg)
+	}
+}
+
+// scheduledActionIDs returns the IDs of the scheduled action configurations of the first
+// scheduled_actions_for_rule block in the state of a compliance policy
+func scheduledActionIDs(t *testing.T, res *testResource) []string {
+	t.Helper()
+	rules, _ := res.attrs()["scheduled_actions_for_rule"].([]interface{})
+	if len(rules) == 0 {
+		t.Fatalf("expected scheduled_actions_for_rule in the state")
+	}
+	configs, _ := rules[0].(map[string]interface{})["scheduled_action_configurations"].([]interface{})
+	ids := make([]string, 0, len(configs))
+	for _, config := range configs {
+		id, _ := config.(map[string]interface{})["id"].(string)
+		if id == "" {
+			t.Errorf("expected every scheduled action to have an ID, got %v", config)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestAccCompliancePolicyResource_scheduledActions(t *testing.T) {
+	env := newTestEnv(t)
+
+	template := env.apply("intune_notification_message_template", nil, `{
+		"display_name": "Device not compliant",
+		"localized_message": [{"locale": "en-us", "subject": "s", "body": "b", "is_default": true}]
+	}`)
+
+	config := fmt.Sprintf(`{
+		"display_name": "Windows baseline",
+		"scheduled_actions_for_rule": [{"scheduled_action_configurations": [
+			{"action_type": "block", "grace_period_hours": 24},
+			{"action_type": "notification", "notification_template_id": %q, "notification_message_cc_list": ["00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001"]},
+			{"action_type": "pushNotification", "grace_period_hours": 48},
+			{"action_type": "remoteLock", "grace_period_hours": 72},
+			{"action_type": "retire", "grace_period_hours": 720}
+		]}]
+	}`, template.id())
+	res := env.apply("intune_compliance_policy", nil, config)
+	ids := scheduledActionIDs(t, res)
+	if len(ids) != 5 {
+		t.Fatalf("expected 5 scheduled actions in the state, got %d", len(ids))
+	}
+
+	actions := env.graph.ScheduledActions(fakegraph.DeviceCompliancePolicies, res.id())
+	configs := actions[0].(map[string]interface{})["scheduledActionConfigurations"].([]interface{})
+	if len(configs) != 5 {
+		t.Fatalf("expected 5 scheduled actions in Graph, got %d", len(configs))
+	}
+	assertAttr(t, configs[1].(map[string]interface{}), "notificationTemplateId", template.id())
+	if cc, _ := configs[1].(map[string]interface{})["notificationMessageCCList"].([]interface{}); len(cc) != 2 {
+		t.Errorf("expected 2 notification copy recipients in Graph, got %v", cc)
+	}
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	// Graph returning the actions in a different order does not cause drift
+	for i, j := 0, len(configs)-1; i < j; i, j = i+1, j-1 {
+		configs[i], configs[j] = configs[j], configs[i]
+	}
+	actions[0].(map[string]interface{})["scheduledActionConfigurations"] = configs
+	env.graph.SetScheduledActions(fakegraph.DeviceCompliancePolicies, res.id(), actions)
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+	if got := scheduledActionIDs(t, res); strings.Join(got, ",") != strings.Join(ids, ",") {
+		t.Errorf("expected the scheduled actions to keep their order %v, got %v", ids, got)
+	}
+
+	// Changed actions replace the scheduled actions of the policy
+	updated := fmt.Sprintf(`{
+		"display_name": "Windows baseline",
+		"scheduled_actions_for_rule": [{"scheduled_action_configurations": [
+			{"action_type": "notification", "notification_template_id": %q},
+			{"action_type": "block", "grace_period_hours": 12}
+		]}]
+	}`, template.id())
+	res = env.apply("intune_compliance_policy", res, updated)
+	configs = env.graph.ScheduledActions(fakegraph.DeviceCompliancePolicies, res.id())[0].(map[string]interface{})["scheduledActionConfigurations"].([]interface{})
+	if len(configs) != 2 {
+		t.Fatalf("expected 2 scheduled actions in Graph, got %d", len(configs))
+	}
+	assertAttr(t, configs[1].(map[string]interface{}), "gracePeriodHours", 12)
+	if len(scheduledActionIDs(t, res)) != 2 {
+		t.Errorf("expected 2 scheduled actions in the state")
+	}
+	env.assertNoOp(env.refresh(res), updated)
+
+	imported := env.importState("intune_compliance_policy", res.id())
+	if got := scheduledActionIDs(t, imported); len(got) != 2 {
+		t.Errorf("expected 2 imported scheduled actions, got %v", got)
+	}
+}
+
+func TestAccCompliancePolicyResource_invalidScheduledActions(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		configs string
+		want    string
+	}{
+		{`{"action_type": "retire"}`, "Exactly one scheduled action must be of type block"},
+		{`{"action_type": "block"}, {"action_type": "block", "grace_period_hours": 24}`, "Exactly one scheduled action must be of type block"},
+		{`{"action_type": "block"}, {"action_type": "notification"}`, "need a notification_template_id"},
+		{`{"action_type": "block", "notification_message_cc_list": ["00000000-0000-0000-0000-000000000001"]}`, "only supported by notification actions"},
+		{`{"action_type": "block", "grace_period_hours": 9000}`, "grace_period_hours"},
+		{`{"action_type": "removeResourceAccessOutsideResource"}`, "action_type"},
+	} {
+		config := `{"display_name": "x", "scheduled_actions_for_rule": [{"scheduled_action_configurations": [` + tc.configs + `]}]}`
+		if msg := env.applyExpectError("intune_compliance_policy", nil, config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.configs, tc.want, msg)
+		}
+	}
+}
+
+func TestAccCompliancePolicyResource_osBuildRanges(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Windows baseline",
+		"os_minimum_version": "10.0.22621",
+		"os_maximum_version": "10.0.22631.9999",
+		"valid_os_build_range": [
+			{"description": "Windows 11 22H2", "lowest_version": "10.0.22621.2428", "highest_version": "10.0.22621"},
+			{"description": "Windows 11 23H2", "lowest_version": "10.0.22631.2428", "highest_version": "10.0.22631.9999"}
+		]
+	}`
+	res := env.apply("intune_compliance_policy", nil, config)
+
+	policy := env.graph.Object(fakegraph.DeviceCompliancePolicies, res.id())
+	assertAttr(t, policy, "osMinimumVersion", "10.0.22621.0")
+	assertAttr(t, policy, "osMaximumVersion", "10.0.22631.9999")
+	ranges, _ := policy["validOperatingSystemBuildRanges"].([]interface{})
+	if len(ranges) != 2 {
+		t.Fatalf("expected 2 build ranges in Graph, got %v", policy["validOperatingSystemBuildRanges"])
+	}
+	assertAttr(t, ranges[0].(map[string]interface{}), "highestVersion", "10.0.22621.65535")
+	assertAttr(t, ranges[1].(map[string]interface{}), "lowestVersion", "10.0.22631.2428")
+
+	// The configured spelling of the versions is kept
+	res = env.refresh(res)
+	assertAttr(t, res.attrs(), "os_minimum_version", "10.0.22621")
+	env.assertNoOp(res, config)
+
+	updated := `{
+		"display_name": "Windows baseline",
+		"valid_os_build_range": [
+			{"lowest_version": "10.0.22631.2428", "highest_version": "10.0.22631.9999"}
+		]
+	}`
+	res = env.apply("intune_compliance_policy", res, updated)
+	ranges, _ = env.graph.Object(fakegraph.DeviceCompliancePolicies, res.id())["validOperatingSystemBuildRanges"].([]interface{})
+	if len(ranges) != 1 {
+		t.Errorf("expected 1 build range in Graph, got %v", ranges)
+	}
+	env.assertNoOp(env.refresh(res), updated)
+
+	// Removed ranges are cleared
+	res = env.apply("intune_compliance_policy", res, `{"display_name": "Windows baseline"}`)
+	ranges, _ = env.graph.Object(fakegraph.DeviceCompliancePolicies, res.id())["validOperatingSystemBuildRanges"].([]interface{})
+	if len(ranges) != 0 {
+		t.Errorf("expected the build ranges to be cleared, got %v", ranges)
+	}
+	env.assertNoOp(env.refresh(res), `{"display_name": "Windows baseline"}`)
+}
+
+func TestAccCompliancePolicyResource_invalidOsBuildRanges(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		config string
+		want   string
+	}{
+		{`{"display_name": "x", "os_minimum_version": "10.0.22631", "os_maximum_version": "10.0.22621.9999"}`, "is higher than os_maximum_version"},
+		{`{"display_name": "x", "os_minimum_version": "10.0"}`, "must be a Windows version"},
+		{`{"display_name": "x", "valid_os_build_range": [{"lowest_version": "10.0.22631", "highest_version": "10.0.22621"}]}`, "is higher than highest_version"},
+		{`{"display_name": "x", "valid_os_build_range": [{"lowest_version": "10.0.22631.x", "highest_version": "10.0.22631"}]}`, "must be a Windows version"},
+		{`{"display_name": "x", "valid_os_build_range": [
+			{"lowest_version": "10.0.22631.2428", "highest_version": "10.0.22631"},
+			{"lowest_version": "10.0.22621", "highest_version": "10.0.22631.3000"}
+		]}`, "Overlapping OS Build Ranges"},
+		// The third range overlaps the first one, not the second one it follows
+		{`{"display_name": "x", "valid_os_build_range": [
+			{"lowest_version": "10.0.1", "highest_version": "10.0.100"},
+			{"lowest_version": "10.0.2", "highest_version": "10.0.3"},
+			{"lowest_version": "10.0.50", "highest_version": "10.0.60"}
+		]}`, "The range 10.0.50.0 - 10.0.60.65535 overlaps the range 10.0.1.0 - 10.0.100.65535"},
+		{`{"display_name": "x", "macos": [{"firewall_enabled": true}], "valid_os_build_range": [{"lowest_version": "10.0.22631", "highest_version": "10.0.22631"}]}`, "only supported by Windows policies"},
+	} {
+		if msg := env.applyExpectError("intune_compliance_policy", nil, tc.config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.config, tc.want, msg)
+		}
+	}
+
+	// Adjacent ranges do not overlap
+	env.apply("intune_compliance_policy", nil, `{"display_name": "x", "valid_os_build_range": [
+		{"lowest_version": "10.0.22631.2428", "highest_version": "10.0.22631"},
+		{"lowest_version": "10.0.22621", "highest_version": "10.0.22621.9999"}
+	]}`)
+}
@@ -0,0 +1,465 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &CustomOmaURIProfileResource{}
+var _ resource.ResourceWithImportState = &CustomOmaURIProfileResource{}
+var _ resource.ResourceWithValidateConfig = &CustomOmaURIProfileResource{}
+
+// customConfigurationODataType is the OData type of Windows 10/11 custom profiles
+const customConfigurationODataType = "#microsoft.graph.windows10CustomConfiguration"
+
+// NewCustomOmaURIProfileResource creates a new resource instance
+func NewCustomOmaURIProfileResource() resource.Resource {
+	return &CustomOmaURIProfileResource{}
+}
+
+// CustomOmaURIProfileResource defines the resource implementation
+type CustomOmaURIProfileResource struct {
+	client *clients.GraphClient
+}
+
+// CustomOmaURIProfileResourceModel describes the resource data model
+type CustomOmaURIProfileResourceModel struct {
+	ID                   types.String      `tfsdk:"id"`
+	Type                 types.String      `tfsdk:"type"`
+	DisplayName          types.String      `tfsdk:"display_name"`
+	Description          types.String      `tfsdk:"description"`
+	RoleScopeTagIds      types.List        `tfsdk:"role_scope_tag_ids"`
+	OmaSettings          types.List        `tfsdk:"oma_setting"`
+	Assignment           []AssignmentModel `tfsdk:"assignment"`
+	CreatedDateTime      types.String      `tfsdk:"created_date_time"`
+	LastModifiedDateTime types.String      `tfsdk:"last_modified_date_time"`
+}
+
+// Metadata returns the resource type name
+func (r *CustomOmaURIProfileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_custom_oma_uri_profile"
+}
+
+// Schema defines the schema for the resource
+func (r *CustomOmaURIProfileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	omaSettings := omaSettingBlockSchema()
+	omaSettings.Validators = []validator.List{
+		listvalidator.SizeAtLeast(1),
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Manages a Windows 10/11 custom profile of OMA-URI settings in Microsoft Intune.",
+		MarkdownDescription: `
+Manages a Windows 10/11 custom profile of OMA-URI settings in Microsoft Intune.
+
+Custom profiles configure CSP settings that are not part of the Settings Catalog. Each
+` + "`oma_setting`" + ` block is one setting; its ` + "`value`" + ` is converted to the setting's
+` + "`value_type`" + `.
+
+## Example Usage
+
+` + "```hcl" + `
+resource "intune_custom_oma_uri_profile" "start" {
+  display_name = "Start Menu Customization"
+
+  oma_setting {
+    display_name = "Hide sleep"
+    oma_uri      = "./Device/Vendor/MSFT/Policy/Config/Start/HideSleep"
+    value_type   = "integer"
+    value        = "1"
+  }
+
+  oma_setting {
+    display_name = "Start layout"
+    oma_uri      = "./User/Vendor/MSFT/Policy/Config/Start/StartLayout"
+    value_type   = "xml"
+    value        = file("start-layout.xml")
+  }
+
+  oma_setting {
+    display_name = "VPN pre-shared key"
+    oma_uri      = "./Device/Vendor/MSFT/VPNv2/Corp/NativeProfile/L2tpPsk"
+    value_type   = "string"
+    secret_value = var.vpn_psk
+  }
+
+  assignment {
+    target {
+      type = "all_devices"
+    }
+  }
+}
+` + "```" + `
+
+## Secret Values
+
+String settings configured with ` + "`secret_value`" + ` instead of ` + "`value`" + ` are stored
+encrypted by Intune and hidden in plans. Intune only returns their cipher text when the profile is
+read, so the provider reads the plain text through ` + "`getOmaSettingPlainTextValue`" + ` and keeps
+only its SHA-256 hash in ` + "`secret_value_sha256`" + `. A secret changed in the Intune portal no longer
+matches the configured value and is planned to be updated. Imported profiles do not have the plain
+text in state, so the first apply after an import sends the configured secrets again.
+`,
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for the profile.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Description: "The policy type for use with intune_policy_assignment. Always 'device_configuration'.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"display_name": schema.StringAttribute{
+				Description: "The display name of the profile.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the profile.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+			},
+			"role_scope_tag_ids": schema.ListAttribute{
+				Description: "List of scope tag IDs for this profile.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"created_date_time": schema.StringAttribute{
+				Description: "The date and time the profile was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_modified_date_time": schema.StringAttribute{
+				Description: "The date and time the profile was last modified.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"oma_setting": omaSettings,
+			"assignment":  AssignmentBlockSchema(),
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *CustomOmaURIProfileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+}
+
+// ValidateConfig checks the values of the settings against their value types
+func (r *CustomOmaURIProfileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var settings []OmaSettingModel
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("oma_setting"), &settings)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateOmaSettings(settings, path.Root("oma_setting"), &resp.Diagnostics)
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *CustomOmaURIProfileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data CustomOmaURIProfileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating custom OMA-URI profile", map[string]interface{}{
+		"name": data.DisplayName.ValueString(),
+	})
+
+	profile := r.buildProfile(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateDeviceConfiguration(ctx, profile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Custom OMA-URI Profile",
+			fmt.Sprintf("Could not create profile: %s", err),
+		)
+		return
+	}
+
+	// Update the model with the created profile data
+	data.ID = types.StringValue(created.ID)
+	data.Type = types.StringValue(PolicyTypeDeviceConfig)
+	data.CreatedDateTime = types.StringValue(created.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(created.LastModifiedDateTime)
+
+	// Handle assignments if specified
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeDeviceConfig, created.ID, assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Assigning Policy",
+				fmt.Sprintf("Profile was created but assignment failed: %s", err),
+			)
+			return
+		}
+	}
+
+	tflog.Debug(ctx, "Created custom OMA-URI profile", map[string]interface{}{
+		"id": created.ID,
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data
+func (r *CustomOmaURIProfileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data CustomOmaURIProfileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Reading custom OMA-URI profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	// Get the profile, together with its assignments if the state had assignments configured
+	profilePath := fmt.Sprintf("%s/%s", clients.PathDeviceConfigurations, data.ID.ValueString())
+	result, err := readPolicyWithAssignments[clients.DeviceConfiguration](ctx, r.client, PolicyTypeDeviceConfig, profilePath, data.ID.ValueString(), len(data.Assignment) > 0)
+	if err != nil {
+		// Check if profile was deleted
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Custom OMA-URI Profile",
+			fmt.Sprintf("Could not read profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	if result.Policy.ODataType != customConfigurationODataType {
+		resp.Diagnostics.AddError(
+			"Unexpected Profile Type",
+			fmt.Sprintf("Profile ID %s is a %s, not a custom profile. Use intune_device_configuration to manage it.", data.ID.ValueString(), result.Policy.ODataType),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, result.Policy, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Update assignments if the state had assignments configured
+	if len(data.Assignment) > 0 {
+		if result.AssignmentsErr != nil {
+			tflog.Warn(ctx, "Failed to read policy assignments", map[string]interface{}{
+				"error": result.AssignmentsErr.Error(),
+			})
+		} else {
+			data.Assignment = PreserveAssignments(data.Assignment, result.Assignments)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update updates the resource and sets the updated Terraform state
+func (r *CustomOmaURIProfileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data CustomOmaURIProfileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating custom OMA-URI profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	profile := r.buildProfile(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateDeviceConfiguration(ctx, data.ID.ValueString(), profile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Custom OMA-URI Profile",
+			fmt.Sprintf("Could not update profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	// Update the model with the updated profile data
+	data.LastModifiedDateTime = types.StringValue(updated.LastModifiedDateTime)
+
+	// Handle assignments
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeDeviceConfig, data.ID.ValueString(), assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Policy Assignments",
+				fmt.Sprintf("Could not update assignments: %s", err),
+			)
+			return
+		}
+	} else {
+		// Clear assignments if none specified
+		if err := AssignPolicy(ctx, r.client, PolicyTypeDeviceConfig, data.ID.ValueString(), []clients.PolicyAssignment{}); err != nil {
+			tflog.Warn(ctx, "Failed to clear policy assignments", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete deletes the resource and removes the Terraform state
+func (r *CustomOmaURIProfileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data CustomOmaURIProfileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting custom OMA-URI profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	err := r.client.DeleteDeviceConfiguration(ctx, data.ID.ValueString())
+	if err != nil {
+		// Ignore not found errors during delete
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Custom OMA-URI Profile",
+			fmt.Sprintf("Could not delete profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the resource state
+func (r *CustomOmaURIProfileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// buildProfile builds the API profile object from the Terraform model
+func (r *CustomOmaURIProfileResource) buildProfile(ctx context.Context, data *CustomOmaURIProfileResourceModel, diags *diag.Diagnostics) *clients.DeviceConfiguration {
+	var settings []OmaSettingModel
+	diags.Append(data.OmaSettings.ElementsAs(ctx, &settings, false)...)
+	if diags.HasError() {
+		return nil
+	}
+
+	omaSettings, err := buildOmaSettings(settings)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("oma_setting"),
+			"Invalid OMA-URI Setting Value",
+			err.Error(),
+		)
+		return nil
+	}
+
+	profile := &clients.DeviceConfiguration{
+		ODataType:   customConfigurationODataType,
+		DisplayName: data.DisplayName.ValueString(),
+		Description: data.Description.ValueString(),
+		Properties: map[string]interface{}{
+			"omaSettings": omaSettings,
+		},
+	}
+
+	// Role scope tags
+	if !data.RoleScopeTagIds.IsNull() {
+		var tagIds []string
+		diags.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		profile.RoleScopeTagIds = tagIds
+	} else {
+		profile.RoleScopeTagIds = []string{DefaultScopeTagID}
+	}
+
+	return profile
+}
+
+// updateModel updates the Terraform model from the API profile, reading the plain text of
+// encrypted settings
+func (r *CustomOmaURIProfileResource) updateModel(ctx context.Context, data *CustomOmaURIProfileResourceModel, profile *clients.DeviceConfiguration, diags *diag.Diagnostics) {
+	data.DisplayName = types.StringValue(profile.DisplayName)
+	data.Type = types.StringValue(PolicyTypeDeviceConfig)
+	data.Description = optionalStringValue(data.Description, profile.Description)
+	data.CreatedDateTime = types.StringValue(profile.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(profile.LastModifiedDateTime)
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, profile.RoleScopeTagIds, diags)
+
+	raw, _ := profile.Properties["omaSettings"].([]interface{})
+	if err := resolveOmaSettingSecrets(ctx, r.client, profile.ID, raw); err != nil {
+		diags.AddError(
+			"Error Reading Custom OMA-URI Profile",
+			fmt.Sprintf("Could not read the encrypted settings of profile ID %s: %s", profile.ID, err),
+		)
+		return
+	}
+
+	var prior []OmaSettingModel
+	if !data.OmaSettings.IsNull() {
+		diags.Append(data.OmaSettings.ElementsAs(ctx, &prior, false)...)
+	}
+	settings, d := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: omaSettingAttrTypes}, omaSettingModels(prior, raw))
+	diags.Append(d...)
+	data.OmaSettings = settings
+}
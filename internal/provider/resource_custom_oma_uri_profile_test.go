@@ -0,0 +1,193 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+// graphOmaSettings returns the OMA-URI settings of a profile in Graph, keyed by OMA-URI
+func graphOmaSettings(env *testEnv, id string) map[string]map[string]interface{} {
+	settings := make(map[string]map[string]interface{})
+	raw, _ := env.graph.Object(fakegraph.DeviceConfigurations, id)["omaSettings"].([]interface{})
+	for _, item := range raw {
+		setting := item.(map[string]interface{})
+		settings[setting["omaUri"].(string)] = setting
+	}
+	return settings
+}
+
+func TestAccCustomOmaURIProfileResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Start menu",
+		"oma_setting": [
+			{"display_name": "Greeting", "oma_uri": "./Device/Test/String", "value_type": "string", "value": "hello"},
+			{"display_name": "Hide sleep", "oma_uri": "./Device/Test/Integer", "value_type": "integer", "value": "1"},
+			{"display_name": "Telemetry", "oma_uri": "./Device/Test/Boolean", "value_type": "boolean", "value": "TRUE"},
+			{"display_name": "Blob", "oma_uri": "./Device/Test/Base64", "value_type": "base64", "value": "aGVsbG8="},
+			{"display_name": "Layout", "oma_uri": "./Device/Test/Xml", "value_type": "xml", "value": "<Layout><Tile id=\"1\"/></Layout>"},
+			{"display_name": "Start", "oma_uri": "./Device/Test/DateTime", "value_type": "date_time", "value": "2024-05-01T10:00:00+02:00"},
+			{"display_name": "Ratio", "oma_uri": "./Device/Test/Float", "value_type": "floating_point", "value": "1.50"}
+		],
+		"assignment": [{"target": [{"type": "all_devices"}]}]
+	}`
+	res := env.apply("intune_custom_oma_uri_profile", nil, config)
+	assertAttr(t, res.attrs(), "type", PolicyTypeDeviceConfig)
+
+	profile := env.graph.Object(fakegraph.DeviceConfigurations, res.id())
+	assertAttr(t, profile, "@odata.type", "#microsoft.graph.windows10CustomConfiguration")
+	settings := graphOmaSettings(env, res.id())
+	assertAttr(t, settings["./Device/Test/Integer"], "value", 1)
+	assertAttr(t, settings["./Device/Test/Boolean"], "value", true)
+	assertAttr(t, settings["./Device/Test/Xml"], "@odata.type", "#microsoft.graph.omaSettingStringXml")
+	assertAttr(t, settings["./Device/Test/Xml"], "value", base64.StdEncoding.EncodeToString([]byte(`<Layout><Tile id="1"/></Layout>`)))
+	assertAttr(t, settings["./Device/Test/DateTime"], "value", "2024-05-01T08:00:00Z")
+	assertAttr(t, settings["./Device/Test/Float"], "value", 1.5)
+	if n := len(env.graph.Assignments(fakegraph.DeviceConfigurations, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment in Graph, got %d", n)
+	}
+
+	// Equivalent values keep their configured spelling
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	updated := strings.Replace(config, `"value": "1.50"`, `"value": "2.25"`, 1)
+	res = env.apply("intune_custom_oma_uri_profile", res, updated)
+	assertAttr(t, graphOmaSettings(env, res.id())["./Device/Test/Float"], "value", 2.25)
+	env.assertNoOp(env.refresh(res), updated)
+
+	imported := env.importState("intune_custom_oma_uri_profile", res.id())
+	importedSettings := imported.attrs()["oma_setting"].([]interface{})
+	if len(importedSettings) != 7 {
+		t.Fatalf("expected 7 imported settings, got %d", len(importedSettings))
+	}
+	assertAttr(t, importedSettings[2].(map[string]interface{}), "value", "true")
+	assertAttr(t, importedSettings[4].(map[string]interface{}), "value", `<Layout><Tile id="1"/></Layout>`)
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.DeviceConfigurations, res.id()) != nil {
+		t.Errorf("profile %s still exists after destroy", res.id())
+	}
+}
+
+func TestAccCustomOmaURIProfileResource_secretValue(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "VPN",
+		"oma_setting": [
+			{"display_name": "Pre-shared key", "oma_uri": "./Device/Vendor/MSFT/VPNv2/Corp/NativeProfile/L2tpPsk", "value_type": "string", "secret_value": "s3cr3t"}
+		]
+	}`
+	res := env.apply("intune_custom_oma_uri_profile", nil, config)
+	assertAttr(t, res.attrs()["oma_setting"].([]interface{})[0].(map[string]interface{}), "secret_value_sha256", omaSecretValueHash("s3cr3t").ValueString())
+
+	// Graph stores the value encrypted
+	setting := graphOmaSettings(env, res.id())["./Device/Vendor/MSFT/VPNv2/Corp/NativeProfile/L2tpPsk"]
+	assertAttr(t, setting, "isEncrypted", true)
+	if setting["value"] == "s3cr3t" {
+		t.Errorf("expected the value to be encrypted in Graph, got the plain text")
+	}
+	secretId, _ := setting["secretReferenceValueId"].(string)
+	assertAttr(t, env.graph.Secrets(fakegraph.DeviceConfigurations, res.id()), secretId, "s3cr3t")
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	// A secret changed in the portal is detected through its plain text value
+	_, err := env.client().UpdateDeviceConfiguration(env.ctx, res.id(), &clients.DeviceConfiguration{
+		ODataType:   customConfigurationODataType,
+		DisplayName: "VPN",
+		Properties: map[string]interface{}{
+			"omaSettings": []interface{}{map[string]interface{}{
+				"@odata.type": "#microsoft.graph.omaSettingString",
+				"displayName": "Pre-shared key",
+				"omaUri":      "./Device/Vendor/MSFT/VPNv2/Corp/NativeProfile/L2tpPsk",
+				"value":       "changed",
+				"isEncrypted": true,
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("UpdateDeviceConfiguration: %s", err)
+	}
+	res = env.refresh(res)
+	setting = res.attrs()["oma_setting"].([]interface{})[0].(map[string]interface{})
+	assertAttr(t, setting, "secret_value", nil)
+	assertAttr(t, setting, "secret_value_sha256", omaSecretValueHash("changed").ValueString())
+	assertAttr(t, setting, "value", nil)
+
+	res = env.apply("intune_custom_oma_uri_profile", res, config)
+	setting = graphOmaSettings(env, res.id())["./Device/Vendor/MSFT/VPNv2/Corp/NativeProfile/L2tpPsk"]
+	secretId, _ = setting["secretReferenceValueId"].(string)
+	assertAttr(t, env.graph.Secrets(fakegraph.DeviceConfigurations, res.id()), secretId, "s3cr3t")
+
+	// The plain text is never read back, only its hash
+	imported := env.importState("intune_custom_oma_uri_profile", res.id())
+	setting = imported.attrs()["oma_setting"].([]interface{})[0].(map[string]interface{})
+	assertAttr(t, setting, "secret_value", nil)
+	assertAttr(t, setting, "secret_value_sha256", omaSecretValueHash("s3cr3t").ValueString())
+	if state := fmt.Sprint(imported.attrs()); strings.Contains(state, "s3cr3t") {
+		t.Errorf("expected no plain text secret in the imported state, got %s", state)
+	}
+
+	res = env.apply("intune_custom_oma_uri_profile", imported, config)
+	env.assertNoOp(env.refresh(res), config)
+}
+
+func TestAccCustomOmaURIProfileResource_invalid(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		setting string
+		want    string
+	}{
+		{`{"display_name": "x", "oma_uri": "./Device/x", "value_type": "integer", "value": "4294967296"}`, "Invalid OMA-URI Setting Value"},
+		{`{"display_name": "x", "oma_uri": "./Device/x", "value_type": "floating_point", "value": "1,5"}`, "Invalid OMA-URI Setting Value"},
+		{`{"display_name": "x", "oma_uri": "./Device/x", "value_type": "date_time", "value": "2024-05-01"}`, "Invalid OMA-URI Setting Value"},
+		{`{"display_name": "x", "oma_uri": "./Device/x", "value_type": "base64", "value": "not base64!"}`, "Invalid OMA-URI Setting Value"},
+		{`{"display_name": "x", "oma_uri": "./Device/x", "value_type": "xml", "value": "<open>"}`, "Invalid OMA-URI Setting Value"},
+		{`{"display_name": "x", "oma_uri": "./Device/x", "value_type": "string"}`, "Invalid OMA-URI Setting"},
+		{`{"display_name": "x", "oma_uri": "./Device/x", "value_type": "string", "value": "a", "secret_value": "b"}`, "Invalid OMA-URI Setting"},
+		{`{"display_name": "x", "oma_uri": "./Device/x", "value_type": "integer", "secret_value": "1"}`, "only supported for string settings"},
+	} {
+		config := `{"display_name": "Invalid", "oma_setting": [` + tc.setting + `]}`
+		if msg := env.applyExpectError("intune_custom_oma_uri_profile", nil, config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.setting, tc.want, msg)
+		}
+	}
+
+	// Profiles of other types cannot be managed as custom profiles
+	wifi := env.apply("intune_device_configuration", nil, `{
+		"display_name": "Corporate Wi-Fi",
+		"wifi": [{"ssid": "corp", "network_name": "Corporate", "security_type": "open"}]
+	}`)
+	imported, err := env.server.ImportResourceState(env.ctx, &tfprotov6.ImportResourceStateRequest{
+		TypeName: "intune_custom_oma_uri_profile",
+		ID:       wifi.id(),
+	})
+	if err != nil {
+		t.Fatalf("ImportResourceState: %s", err)
+	}
+	read, err := env.server.ReadResource(env.ctx, &tfprotov6.ReadResourceRequest{
+		TypeName:     "intune_custom_oma_uri_profile",
+		CurrentState: imported.ImportedResources[0].State,
+	})
+	if err != nil {
+		t.Fatalf("ReadResource: %s", err)
+	}
+	if msg := errorSummary(read.Diagnostics); !strings.Contains(msg, "Unexpected Profile Type") {
+		t.Errorf("expected an unexpected profile type error, got: %q", msg)
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &DeviceComplianceScriptResource{}
+var _ resource.ResourceWithImportState = &DeviceComplianceScriptResource{}
+var _ resource.ResourceWithModifyPlan = &DeviceComplianceScriptResource{}
+
+// NewDeviceComplianceScriptResource returns a new device compliance script resource
+func NewDeviceComplianceScriptResource() resource.Resource {
+	return &DeviceComplianceScriptResource{}
+}
+
+// DeviceComplianceScriptResource defines the resource implementation
+type DeviceComplianceScriptResource struct {
+	client *clients.GraphClient
+}
+
+// DeviceComplianceScriptResourceModel describes the resource data model
+type DeviceComplianceScriptResourceModel struct {
+	ID                           types.String `tfsdk:"id"`
+	DisplayName                  types.String `tfsdk:"display_name"`
+	Description                  types.String `tfsdk:"description"`
+	Publisher                    types.String `tfsdk:"publisher"`
+	DetectionScriptContent       types.String `tfsdk:"detection_script_content"`
+	DetectionScriptContentSHA256 types.String `tfsdk:"detection_script_content_sha256"`
+	RunAsAccount                 types.String `tfsdk:"run_as_account"`
+	RunAs32Bit                   types.Bool   `tfsdk:"run_as_32_bit"`
+	EnforceSignatureCheck        types.Bool   `tfsdk:"enforce_signature_check"`
+	RoleScopeTagIds              types.List   `tfsdk:"role_scope_tag_ids"`
+	CreatedDateTime              types.String `tfsdk:"created_date_time"`
+	LastModifiedDateTime         types.String `tfsdk:"last_modified_date_time"`
+}
+
+// Metadata returns the resource type name
+func (r *DeviceComplianceScriptResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_device_compliance_script"
+}
+
+// Schema defines the schema for the resource
+func (r *DeviceComplianceScriptResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages an Intune device compliance script.",
+		MarkdownDescription: `
+Manages an Intune device compliance script.
+
+Compliance scripts are PowerShell discovery scripts that return settings as JSON. Windows
+compliance policies check the returned settings against the rules of their ` + "`custom_compliance`" + ` block.
+
+The script content is compared by its SHA-256 hash, so changes made to the script outside of
+OpenTofu are detected and reverted on the next apply.
+
+## Example Usage
+
+` + "```hcl" + `
+resource "intune_device_compliance_script" "bios" {
+  display_name             = "BIOS version"
+  publisher                = "IT"
+  detection_script_content = file("${path.module}/scripts/bios.ps1")
+  run_as_account           = "system"
+}
+
+resource "intune_compliance_policy" "windows" {
+  display_name = "Windows baseline"
+
+  custom_compliance {
+    script_id  = intune_device_compliance_script.bios.id
+    rules_json = file("${path.module}/rules/bios.json")
+  }
+}
+` + "```" + `
+
+## Import
+
+Device compliance scripts can be imported using the script ID:
+
+` + "```shell" + `
+terraform import intune_device_compliance_script.example 00000000-0000-0000-0000-000000000000
+` + "```" + `
+`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for the compliance script.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"display_name": schema.StringAttribute{
+				Description: "The display name of the compliance script.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the compliance script.",
+				Optional:    true,
+			},
+			"publisher": schema.StringAttribute{
+				Description: "The publisher of the compliance script.",
+				Optional:    true,
+			},
+			"detection_script_content": schema.StringAttribute{
+				Description: "The PowerShell content of the discovery script.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"detection_script_content_sha256": schema.StringAttribute{
+				Description: "The SHA-256 hash of the script content in Intune.",
+				Computed:    true,
+			},
+			"run_as_account": schema.StringAttribute{
+				Description: "The account the script runs as. Valid values: system, user.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("system"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("system", "user"),
+				},
+			},
+			"run_as_32_bit": schema.BoolAttribute{
+				Description: "Run the script in a 32-bit PowerShell host on 64-bit devices.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"enforce_signature_check": schema.BoolAttribute{
+				Description: "Require the script to be signed by a trusted publisher.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"role_scope_tag_ids": schema.ListAttribute{
+				Description: "List of role scope tag IDs for this script.",
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"created_date_time": schema.StringAttribute{
+				Description: "The date and time the script was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_modified_date_time": schema.StringAttribute{
+				Description: "The date and time the script was last modified.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *DeviceComplianceScriptResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+}
+
+// ModifyPlan plans the hash of the configured script content
+func (r *DeviceComplianceScriptResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var content types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("detection_script_content"), &content)...)
+	if resp.Diagnostics.HasError() || content.IsUnknown() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("detection_script_content_sha256"), scriptContentHash(content.ValueString()))...)
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *DeviceComplianceScriptResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data DeviceComplianceScriptResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	script := r.buildScript(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateDeviceComplianceScript(ctx, script)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Device Compliance Script",
+			fmt.Sprintf("Could not create device compliance script: %s", err),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(created.ID)
+	r.updateModel(ctx, &data, created, &resp.Diagnostics)
+
+	tflog.Debug(ctx, "Created device compliance script", map[string]interface{}{
+		"id":           created.ID,
+		"display_name": created.DisplayName,
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data
+func (r *DeviceComplianceScriptResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data DeviceComplianceScriptResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	script, err := r.client.GetDeviceComplianceScript(ctx, data.ID.ValueString())
+	if err != nil {
+		// Check if the resource was deleted outside of Terraform
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Device Compliance Script",
+			fmt.Sprintf("Could not read device compliance script ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, script, &resp.Diagnostics)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update updates the resource and sets the updated Terraform state
+func (r *DeviceComplianceScriptResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data DeviceComplianceScriptResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	script := r.buildScript(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateDeviceComplianceScript(ctx, data.ID.ValueString(), script)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Device Compliance Script",
+			fmt.Sprintf("Could not update device compliance script ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, updated, &resp.Diagnostics)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete deletes the resource and removes the Terraform state
+func (r *DeviceComplianceScriptResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data DeviceComplianceScriptResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteDeviceComplianceScript(ctx, data.ID.ValueString())
+	if err != nil {
+		// Ignore "not found" errors as the resource is already deleted
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Device Compliance Script",
+			fmt.Sprintf("Could not delete device compliance script ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the resource state
+func (r *DeviceComplianceScriptResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// buildScript builds the API script from the Terraform model. Graph takes the content base64
+// encoded.
+func (r *DeviceComplianceScriptResource) buildScript(ctx context.Context, data *DeviceComplianceScriptResourceModel, diags *diag.Diagnostics) *clients.DeviceComplianceScript {
+	script := &clients.DeviceComplianceScript{
+		DisplayName:            data.DisplayName.ValueString(),
+		Description:            data.Description.ValueString(),
+		Publisher:              data.Publisher.ValueString(),
+		DetectionScriptContent: base64.StdEncoding.EncodeToString([]byte(data.DetectionScriptContent.ValueString())),
+		RunAsAccount:           data.RunAsAccount.ValueString(),
+		RunAs32Bit:             data.RunAs32Bit.ValueBool(),
+		EnforceSignatureCheck:  data.EnforceSignatureCheck.ValueBool(),
+	}
+
+	if !data.RoleScopeTagIds.IsNull() && !data.RoleScopeTagIds.IsUnknown() {
+		var tagIds []string
+		diags.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		script.RoleScopeTagIds = tagIds
+	} else {
+		script.RoleScopeTagIds = []string{"0"}
+	}
+
+	return script
+}
+
+// updateModel updates the Terraform model from the API script. The content in the model is only
+// replaced when its hash differs from the content in Graph, so the configured spelling is kept.
+func (r *DeviceComplianceScriptResource) updateModel(ctx context.Context, data *DeviceComplianceScriptResourceModel, script *clients.DeviceComplianceScript, diags *diag.Diagnostics) {
+	data.DisplayName = types.StringValue(script.DisplayName)
+	data.Description = optionalStringValue(data.Description, script.Description)
+	data.Publisher = optionalStringValue(data.Publisher, script.Publisher)
+	data.RunAsAccount = types.StringValue(script.RunAsAccount)
+	data.RunAs32Bit = types.BoolValue(script.RunAs32Bit)
+	data.EnforceSignatureCheck = types.BoolValue(script.EnforceSignatureCheck)
+	data.CreatedDateTime = types.StringValue(script.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(script.LastModifiedDateTime)
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, script.RoleScopeTagIds, diags)
+
+	// Graph only returns the content of a single script
+	if script.DetectionScriptContent == "" {
+		return
+	}
+	content, err := base64.StdEncoding.DecodeString(script.DetectionScriptContent)
+	if err != nil {
+		diags.AddError(
+			"Error Decoding Device Compliance Script",
+			fmt.Sprintf("The content of device compliance script ID %s is not base64 encoded: %s", script.ID, err),
+		)
+		return
+	}
+	hash := scriptContentHash(string(content))
+	if data.DetectionScriptContent.IsNull() || !scriptContentHash(data.DetectionScriptContent.ValueString()).Equal(hash) {
+		data.DetectionScriptContent = types.StringValue(string(content))
+	}
+	data.DetectionScriptContentSHA256 = hash
+}
+
+// scriptContentHash returns the hex encoded SHA-256 hash of script content
+func scriptContentHash(content string) types.String {
+	sum := sha256.Sum256([]byte(content))
+	return types.StringValue(hex.EncodeToString(sum[:]))
+}
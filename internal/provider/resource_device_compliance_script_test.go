@@ -0,0 +1,94 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+// testComplianceScript is the content of the compliance scripts created by the tests
+const testComplianceScript = "$bios = Get-CimInstance Win32_BIOS\nreturn @{ BiosVersion = $bios.SMBIOSBIOSVersion } | ConvertTo-Json -Compress\n"
+
+func TestAccDeviceComplianceScriptResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := fmt.Sprintf(`{
+		"display_name": "BIOS version",
+		"publisher": "IT",
+		"detection_script_content": %q
+	}`, testComplianceScript)
+	res := env.apply("intune_device_compliance_script", nil, config)
+	assertAttr(t, res.attrs(), "run_as_account", "system")
+	assertAttr(t, res.attrs(), "detection_script_content_sha256", scriptContentHash(testComplianceScript).ValueString())
+
+	script := env.graph.Object(fakegraph.ComplianceScripts, res.id())
+	assertAttr(t, script, "detectionScriptContent", base64.StdEncoding.EncodeToString([]byte(testComplianceScript)))
+	assertAttr(t, script, "runAs32Bit", false)
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	updated := fmt.Sprintf(`{
+		"display_name": "BIOS version",
+		"publisher": "IT",
+		"detection_script_content": %q,
+		"run_as_account": "user",
+		"run_as_32_bit": true,
+		"enforce_signature_check": true
+	}`, testComplianceScript+"# v2\n")
+	res = env.apply("intune_device_compliance_script", res, updated)
+	script = env.graph.Object(fakegraph.ComplianceScripts, res.id())
+	assertAttr(t, script, "runAsAccount", "user")
+	assertAttr(t, script, "runAs32Bit", true)
+	assertAttr(t, script, "enforceSignatureCheck", true)
+	assertAttr(t, res.attrs(), "detection_script_content_sha256", scriptContentHash(testComplianceScript+"# v2\n").ValueString())
+	env.assertNoOp(env.refresh(res), updated)
+
+	imported := env.importState("intune_device_compliance_script", res.id())
+	env.assertNoOp(imported, updated)
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.ComplianceScripts, res.id()) != nil {
+		t.Errorf("script %s still exists after destroy", res.id())
+	}
+}
+
+func TestAccDeviceComplianceScriptResource_contentDrift(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := fmt.Sprintf(`{"display_name": "BIOS version", "detection_script_content": %q}`, testComplianceScript)
+	res := env.apply("intune_device_compliance_script", nil, config)
+
+	env.graph.Update(fakegraph.ComplianceScripts, res.id(), map[string]interface{}{
+		"detectionScriptContent": base64.StdEncoding.EncodeToString([]byte("return '{}'")),
+	})
+
+	res = env.refresh(res)
+	assertAttr(t, res.attrs(), "detection_script_content", "return '{}'")
+	assertAttr(t, res.attrs(), "detection_script_content_sha256", scriptContentHash("return '{}'").ValueString())
+
+	res = env.apply("intune_device_compliance_script", res, config)
+	assertAttr(t, env.graph.Object(fakegraph.ComplianceScripts, res.id()), "detectionScriptContent", base64.StdEncoding.EncodeToString([]byte(testComplianceScript)))
+}
+
+func TestAccDeviceComplianceScriptResource_invalid(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		config string
+		want   string
+	}{
+		{`{"display_name": "x", "detection_script_content": "return 1", "run_as_account": "admin"}`, "run_as_account"},
+		{`{"display_name": "x", "detection_script_content": ""}`, "detection_script_content"},
+	} {
+		if msg := env.applyExpectError("intune_device_compliance_script", nil, tc.config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.config, tc.want, msg)
+		}
+	}
+}
@@ -0,0 +1,703 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &DeviceConfigurationResource{}
+var _ resource.ResourceWithImportState = &DeviceConfigurationResource{}
+var _ resource.ResourceWithValidateConfig = &DeviceConfigurationResource{}
+var _ resource.ResourceWithModifyPlan = &DeviceConfigurationResource{}
+
+// deviceConfigurationCommonProperties are the profile properties managed by their own attributes,
+// which settings_json may not contain
+var deviceConfigurationCommonProperties = []string{
+	"@odata.type", "id", "displayName", "description", "roleScopeTagIds", "createdDateTime", "lastModifiedDateTime", "version",
+}
+
+// deviceConfigurationReadOnlyProperties are computed by Intune and left out of imported settings_json
+var deviceConfigurationReadOnlyProperties = []string{"version", "supportsScopeTags"}
+
+// NewDeviceConfigurationResource creates a new resource instance
+func NewDeviceConfigurationResource() resource.Resource {
+	return &DeviceConfigurationResource{}
+}
+
+// DeviceConfigurationResource defines the resource implementation
+type DeviceConfigurationResource struct {
+	client *clients.GraphClient
+}
+
+// DeviceConfigurationResourceModel describes the resource data model
+type DeviceConfigurationResourceModel struct {
+	ID                     types.String      `tfsdk:"id"`
+	Type                   types.String      `tfsdk:"type"`
+	DisplayName            types.String      `tfsdk:"display_name"`
+	Description            types.String      `tfsdk:"description"`
+	RoleScopeTagIds        types.List        `tfsdk:"role_scope_tag_ids"`
+	ODataType              types.String      `tfsdk:"odata_type"`
+	SettingsJSON           types.String      `tfsdk:"settings_json"`
+	Custom                 types.List        `tfsdk:"custom"`
+	TrustedRootCertificate types.List        `tfsdk:"trusted_root_certificate"`
+	Wifi                   types.List        `tfsdk:"wifi"`
+	Vpn                    types.List        `tfsdk:"vpn"`
+	ScepCertificate        types.List        `tfsdk:"scep_certificate"`
+	PkcsCertificate        types.List        `tfsdk:"pkcs_certificate"`
+	UpdateRing             types.List        `tfsdk:"update_ring"`
+	Assignment             []AssignmentModel `tfsdk:"assignment"`
+	CreatedDateTime        types.String      `tfsdk:"created_date_time"`
+	LastModifiedDateTime   types.String      `tfsdk:"last_modified_date_time"`
+}
+
+// profileBlocks returns the typed profile blocks of the model, keyed by block name
+func (m *DeviceConfigurationResourceModel) profileBlocks() map[string]*types.List {
+	return map[string]*types.List{
+		"custom":                   &m.Custom,
+		"trusted_root_certificate": &m.TrustedRootCertificate,
+		"wifi":                     &m.Wifi,
+		"vpn":                      &m.Vpn,
+		"scep_certificate":         &m.ScepCertificate,
+		"pkcs_certificate":         &m.PkcsCertificate,
+		"update_ring":              &m.UpdateRing,
+	}
+}
+
+// configuredProfile returns the typed profile whose block is set, or nil
+func (m *DeviceConfigurationResourceModel) configuredProfile() *deviceConfigurationProfile {
+	blocks := m.profileBlocks()
+	for i := range deviceConfigurationProfiles {
+		if block := blocks[deviceConfigurationProfiles[i].block]; len(block.Elements()) > 0 {
+			return &deviceConfigurationProfiles[i]
+		}
+	}
+	return nil
+}
+
+// Metadata returns the resource type name
+func (r *DeviceConfigurationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_device_configuration"
+}
+
+// Schema defines the schema for the resource
+func (r *DeviceConfigurationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	blocks := map[string]schema.Block{
+		"assignment": AssignmentBlockSchema(),
+	}
+	for i := range deviceConfigurationProfiles {
+		blocks[deviceConfigurationProfiles[i].block] = deviceConfigurationProfiles[i].schema()
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Manages a device configuration profile in Microsoft Intune.",
+		MarkdownDescription: `
+Manages a device configuration profile (` + "`deviceManagement/deviceConfigurations`" + `) in Microsoft Intune.
+
+The common Windows profile types have a block of their own: ` + "`custom`" + ` (OMA-URI settings),
+` + "`trusted_root_certificate`" + `, ` + "`wifi`" + `, ` + "`vpn`" + `, ` + "`scep_certificate`" + `,
+` + "`pkcs_certificate`" + ` and ` + "`update_ring`" + ` (Windows Update for Business). Any other profile type
+can be managed with ` + "`odata_type`" + ` and the properties of the type in ` + "`settings_json`" + `.
+
+## Example Usage
+
+` + "```hcl" + `
+resource "intune_device_configuration" "update_ring" {
+  display_name = "Windows Update Ring - Pilot"
+
+  update_ring {
+    quality_updates_deferral_period_in_days = 0
+    feature_updates_deferral_period_in_days = 30
+    automatic_update_mode                   = "autoInstallAtMaintenanceTime"
+    deadline_for_quality_updates_in_days    = 3
+  }
+
+  assignment {
+    target {
+      type     = "group"
+      group_id = "00000000-0000-0000-0000-000000000000"
+    }
+  }
+}
+
+resource "intune_device_configuration" "edition_upgrade" {
+  display_name = "Windows Enterprise Upgrade"
+  odata_type   = "#microsoft.graph.editionUpgradeConfiguration"
+
+  settings_json = jsonencode({
+    licenseType   = "licenseKey"
+    targetEdition = "windows10Enterprise"
+    productKey    = var.product_key
+  })
+}
+` + "```" + `
+
+## settings_json
+
+Only the properties in ` + "`settings_json`" + ` are compared with Intune. Properties Intune does not
+return, such as secrets, keep their configured value. Properties removed from ` + "`settings_json`" + `
+keep their value in Intune; set them to ` + "`null`" + ` to clear them.
+
+Changing the profile type replaces the profile.
+`,
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for the profile.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Description: "The policy type for use with intune_policy_assignment. Always 'device_configuration'.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"display_name": schema.StringAttribute{
+				Description: "The display name of the profile.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the profile.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+			},
+			"role_scope_tag_ids": schema.ListAttribute{
+				Description: "List of scope tag IDs for this profile.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"odata_type": schema.StringAttribute{
+				Description: "The OData type of the profile, e.g. #microsoft.graph.editionUpgradeConfiguration. Required " +
+					"with settings_json, and set from the profile block otherwise. Changing it replaces the profile.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"settings_json": schema.StringAttribute{
+				Description: "The properties of the profile type as a JSON object, for profile types without a block. " +
+					"Conflicts with the profile blocks.",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"created_date_time": schema.StringAttribute{
+				Description: "The date and time the profile was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_modified_date_time": schema.StringAttribute{
+				Description: "The date and time the profile was last modified.",
+				Computed:    true,
+			},
+		},
+		Blocks: blocks,
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *DeviceConfigurationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+}
+
+// ValidateConfig checks that the profile is configured with exactly one of settings_json and the
+// profile blocks, and that odata_type matches
+func (r *DeviceConfigurationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data DeviceConfigurationResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var configured []string
+	for name, block := range data.profileBlocks() {
+		if block.IsUnknown() {
+			return
+		}
+		if len(block.Elements()) > 0 {
+			configured = append(configured, name)
+		}
+	}
+	if data.SettingsJSON.IsUnknown() {
+		return
+	}
+	if !data.SettingsJSON.IsNull() {
+		configured = append(configured, "settings_json")
+	}
+
+	switch len(configured) {
+	case 0:
+		resp.Diagnostics.AddError(
+			"Missing Device Configuration Settings",
+			"Either settings_json or one of the profile blocks must be specified.",
+		)
+		return
+	case 1:
+	default:
+		resp.Diagnostics.AddError(
+			"Conflicting Device Configuration Settings",
+			fmt.Sprintf("Only one of settings_json and the profile blocks may be specified, got: %s.", strings.Join(configured, ", ")),
+		)
+		return
+	}
+
+	if !data.SettingsJSON.IsNull() {
+		r.validateSettingsJSON(&data, &resp.Diagnostics)
+		return
+	}
+
+	profile := data.configuredProfile()
+	if !data.ODataType.IsNull() && !data.ODataType.IsUnknown() && data.ODataType.ValueString() != profile.odataType {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("odata_type"),
+			"Conflicting OData Type",
+			fmt.Sprintf("The %s block configures a %s profile, but odata_type is %s.", profile.block, profile.odataType, data.ODataType.ValueString()),
+		)
+	}
+
+	if profile.block == "custom" {
+		var custom []struct {
+			OmaSettings []OmaSettingModel `tfsdk:"oma_setting"`
+		}
+		resp.Diagnostics.Append(data.Custom.ElementsAs(ctx, &custom, false)...)
+		if len(custom) > 0 {
+			validateOmaSettings(custom[0].OmaSettings, path.Root("custom").AtListIndex(0).AtName("oma_setting"), &resp.Diagnostics)
+		}
+	}
+}
+
+// validateSettingsJSON checks settings_json and the odata_type it requires
+func (r *DeviceConfigurationResource) validateSettingsJSON(data *DeviceConfigurationResourceModel, diags *diag.Diagnostics) {
+	if data.ODataType.IsNull() {
+		diags.AddAttributeError(
+			path.Root("odata_type"),
+			"Missing OData Type",
+			"odata_type must be specified with settings_json.",
+		)
+	} else if !data.ODataType.IsUnknown() && !strings.HasPrefix(data.ODataType.ValueString(), "#microsoft.graph.") {
+		diags.AddAttributeError(
+			path.Root("odata_type"),
+			"Invalid OData Type",
+			fmt.Sprintf("odata_type must start with #microsoft.graph., got: %s", data.ODataType.ValueString()),
+		)
+	}
+
+	settings, err := DecodeSettingsJSON(data.SettingsJSON.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("settings_json"),
+			"Invalid Settings JSON",
+			fmt.Sprintf("settings_json must be a JSON object: %s", err),
+		)
+		return
+	}
+	for _, key := range deviceConfigurationCommonProperties {
+		if _, ok := settings[key]; ok {
+			diags.AddAttributeError(
+				path.Root("settings_json"),
+				"Invalid Settings JSON",
+				fmt.Sprintf("settings_json may not contain %s, which is managed by its own attribute or by Intune.", key),
+			)
+		}
+	}
+}
+
+// ModifyPlan sets odata_type from the profile block and replaces the profile when its type changes
+func (r *DeviceConfigurationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var config DeviceConfigurationResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	odataType := config.ODataType
+	if profile := config.configuredProfile(); profile != nil && odataType.IsNull() {
+		odataType = types.StringValue(profile.odataType)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("odata_type"), odataType)...)
+	}
+
+	if req.State.Raw.IsNull() || odataType.IsNull() || odataType.IsUnknown() {
+		return
+	}
+
+	var stateODataType types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("odata_type"), &stateODataType)...)
+	if !stateODataType.IsNull() && !stateODataType.Equal(odataType) {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("odata_type"))
+	}
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *DeviceConfigurationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data DeviceConfigurationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating device configuration profile", map[string]interface{}{
+		"name":      data.DisplayName.ValueString(),
+		"odataType": data.ODataType.ValueString(),
+	})
+
+	profile := r.buildProfile(ctx, &data, false, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateDeviceConfiguration(ctx, profile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Device Configuration",
+			fmt.Sprintf("Could not create profile: %s", err),
+		)
+		return
+	}
+
+	// Update the model with the created profile data
+	data.ID = types.StringValue(created.ID)
+	data.Type = types.StringValue(PolicyTypeDeviceConfig)
+	data.CreatedDateTime = types.StringValue(created.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(created.LastModifiedDateTime)
+
+	// Handle assignments if specified
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeDeviceConfig, created.ID, assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Assigning Policy",
+				fmt.Sprintf("Profile was created but assignment failed: %s", err),
+			)
+			return
+		}
+	}
+
+	tflog.Debug(ctx, "Created device configuration profile", map[string]interface{}{
+		"id": created.ID,
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data
+func (r *DeviceConfigurationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data DeviceConfigurationResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Reading device configuration profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	// Get the profile, together with its assignments if the state had assignments configured
+	profilePath := fmt.Sprintf("%s/%s", clients.PathDeviceConfigurations, data.ID.ValueString())
+	result, err := readPolicyWithAssignments[clients.DeviceConfiguration](ctx, r.client, PolicyTypeDeviceConfig, profilePath, data.ID.ValueString(), len(data.Assignment) > 0)
+	if err != nil {
+		// Check if profile was deleted
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Device Configuration",
+			fmt.Sprintf("Could not read profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, result.Policy, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Update assignments if the state had assignments configured
+	if len(data.Assignment) > 0 {
+		if result.AssignmentsErr != nil {
+			tflog.Warn(ctx, "Failed to read policy assignments", map[string]interface{}{
+				"error": result.AssignmentsErr.Error(),
+			})
+		} else {
+			data.Assignment = PreserveAssignments(data.Assignment, result.Assignments)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update updates the resource and sets the updated Terraform state
+func (r *DeviceConfigurationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data DeviceConfigurationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating device configuration profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	profile := r.buildProfile(ctx, &data, true, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateDeviceConfiguration(ctx, data.ID.ValueString(), profile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Device Configuration",
+			fmt.Sprintf("Could not update profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	// Update the model with the updated profile data
+	data.LastModifiedDateTime = types.StringValue(updated.LastModifiedDateTime)
+
+	// Handle assignments
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeDeviceConfig, data.ID.ValueString(), assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Policy Assignments",
+				fmt.Sprintf("Could not update assignments: %s", err),
+			)
+			return
+		}
+	} else {
+		// Clear assignments if none specified
+		if err := AssignPolicy(ctx, r.client, PolicyTypeDeviceConfig, data.ID.ValueString(), []clients.PolicyAssignment{}); err != nil {
+			tflog.Warn(ctx, "Failed to clear policy assignments", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete deletes the resource and removes the Terraform state
+func (r *DeviceConfigurationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data DeviceConfigurationResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting device configuration profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	err := r.client.DeleteDeviceConfiguration(ctx, data.ID.ValueString())
+	if err != nil {
+		// Ignore not found errors during delete
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Device Configuration",
+			fmt.Sprintf("Could not delete profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the resource state
+func (r *DeviceConfigurationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// buildProfile builds the API profile object from the Terraform model
+func (r *DeviceConfigurationResource) buildProfile(ctx context.Context, data *DeviceConfigurationResourceModel, update bool, diags *diag.Diagnostics) *clients.DeviceConfiguration {
+	profile := &clients.DeviceConfiguration{
+		ODataType:   data.ODataType.ValueString(),
+		DisplayName: data.DisplayName.ValueString(),
+		Description: data.Description.ValueString(),
+	}
+
+	if !data.SettingsJSON.IsNull() {
+		settings, err := DecodeSettingsJSON(data.SettingsJSON.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("settings_json"),
+				"Invalid Settings JSON",
+				fmt.Sprintf("settings_json must be a JSON object: %s", err),
+			)
+			return nil
+		}
+		profile.Properties = settings
+	} else if typed := data.configuredProfile(); typed != nil {
+		profile.ODataType = typed.odataType
+		profile.Properties = typed.build(ctx, r.client, *data.profileBlocks()[typed.block], update, diags)
+	}
+
+	// Role scope tags
+	if !data.RoleScopeTagIds.IsNull() {
+		var tagIds []string
+		diags.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		profile.RoleScopeTagIds = tagIds
+	} else {
+		profile.RoleScopeTagIds = []string{DefaultScopeTagID}
+	}
+
+	return profile
+}
+
+// updateModel updates the Terraform model from the API profile. The profile is read into the
+// block the state uses; imported profiles use the block of their type, or settings_json.
+func (r *DeviceConfigurationResource) updateModel(ctx context.Context, data *DeviceConfigurationResourceModel, profile *clients.DeviceConfiguration, diags *diag.Diagnostics) {
+	data.DisplayName = types.StringValue(profile.DisplayName)
+	data.Type = types.StringValue(PolicyTypeDeviceConfig)
+	data.Description = optionalStringValue(data.Description, profile.Description)
+	data.ODataType = types.StringValue(profile.ODataType)
+	data.CreatedDateTime = types.StringValue(profile.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(profile.LastModifiedDateTime)
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, profile.RoleScopeTagIds, diags)
+
+	typed := data.configuredProfile()
+	if typed == nil && data.SettingsJSON.IsNull() {
+		typed = findDeviceConfigurationProfile(profile.ODataType)
+	}
+
+	if typed == nil {
+		settingsJSON, err := deviceConfigurationSettingsJSON(data.SettingsJSON.ValueString(), profile.Properties)
+		if err != nil {
+			diags.AddError(
+				"Error Reading Device Configuration",
+				fmt.Sprintf("Could not encode the properties of profile ID %s: %s", profile.ID, err),
+			)
+			return
+		}
+		data.SettingsJSON = types.StringValue(settingsJSON)
+		return
+	}
+
+	// A profile whose type changed outside of Terraform keeps its block, so the plan replaces it
+	if typed.odataType != profile.ODataType {
+		return
+	}
+	if settings, ok := profile.Properties["omaSettings"].([]interface{}); ok {
+		if err := resolveOmaSettingSecrets(ctx, r.client, profile.ID, settings); err != nil {
+			diags.AddError(
+				"Error Reading Device Configuration",
+				fmt.Sprintf("Could not read the encrypted OMA-URI settings of profile ID %s: %s", profile.ID, err),
+			)
+			return
+		}
+	}
+	block := data.profileBlocks()[typed.block]
+	*block = typed.read(ctx, *block, profile.Properties, diags)
+}
+
+// deviceConfigurationSettingsJSON returns settings_json for the properties of a profile. With a
+// prior value, only its properties are included; properties Intune does not return, such as
+// secrets, keep their prior value. An equivalent value returns prior unchanged, so formatting
+// does not produce a diff.
+func deviceConfigurationSettingsJSON(prior string, props map[string]interface{}) (string, error) {
+	var priorSettings map[string]interface{}
+	if prior != "" {
+		priorSettings, _ = DecodeSettingsJSON(prior)
+	}
+
+	var result map[string]interface{}
+	if priorSettings == nil {
+		result = make(map[string]interface{}, len(props))
+		for key, value := range props {
+			if value != nil {
+				result[key] = value
+			}
+		}
+		for _, key := range deviceConfigurationReadOnlyProperties {
+			delete(result, key)
+		}
+	} else {
+		result = projectSettings(priorSettings, props)
+		if reflect.DeepEqual(result, priorSettings) {
+			return prior, nil
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(result); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// projectSettings returns the values of props for the keys of prior, recursing into nested objects
+func projectSettings(prior, props map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(prior))
+	for key, priorValue := range prior {
+		value, ok := props[key]
+		if !ok || value == nil {
+			result[key] = priorValue
+			continue
+		}
+		priorObject, priorIsObject := priorValue.(map[string]interface{})
+		object, isObject := value.(map[string]interface{})
+		if priorIsObject && isObject {
+			result[key] = projectSettings(priorObject, object)
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
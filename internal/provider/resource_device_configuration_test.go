@@ -0,0 +1,291 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+// profileBlock returns the first element of a profile block in the attributes of a resource
+func profileBlock(t *testing.T, attrs map[string]interface{}, name string) map[string]interface{} {
+	t.Helper()
+
+	block, _ := attrs[name].([]interface{})
+	if len(block) != 1 {
+		t.Fatalf("expected one %s block, got %v", name, attrs[name])
+	}
+	return block[0].(map[string]interface{})
+}
+
+func TestAccDeviceConfigurationResource_settingsJSON(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Windows Enterprise Upgrade",
+		"odata_type": "#microsoft.graph.editionUpgradeConfiguration",
+		"settings_json": "{\"licenseType\": \"licenseKey\", \"targetEdition\": \"windows10Enterprise\", \"productKey\": \"XXXXX-XXXXX\"}",
+		"assignment": [{"target": [{"type": "all_devices"}]}]
+	}`
+	res := env.apply("intune_device_configuration", nil, config)
+	assertAttr(t, res.attrs(), "type", PolicyTypeDeviceConfig)
+
+	profile := env.graph.Object(fakegraph.DeviceConfigurations, res.id())
+	assertAttr(t, profile, "@odata.type", "#microsoft.graph.editionUpgradeConfiguration")
+	assertAttr(t, profile, "targetEdition", "windows10Enterprise")
+	if n := len(env.graph.Assignments(fakegraph.DeviceConfigurations, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment in Graph, got %d", n)
+	}
+
+	// Properties Graph adds and the formatting of settings_json do not produce a diff
+	env.graph.Update(fakegraph.DeviceConfigurations, res.id(), map[string]interface{}{"edition": "windows10Pro"})
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	// Drift of a configured property is detected and corrected
+	env.graph.Update(fakegraph.DeviceConfigurations, res.id(), map[string]interface{}{"targetEdition": "windows10Education"})
+	res = env.refresh(res)
+	if !strings.Contains(res.attrs()["settings_json"].(string), "windows10Education") {
+		t.Errorf("expected the drift to be read into settings_json, got %v", res.attrs()["settings_json"])
+	}
+	res = env.apply("intune_device_configuration", res, config)
+	assertAttr(t, env.graph.Object(fakegraph.DeviceConfigurations, res.id()), "targetEdition", "windows10Enterprise")
+
+	imported := env.importState("intune_device_configuration", res.id())
+	assertAttr(t, imported.attrs(), "odata_type", "#microsoft.graph.editionUpgradeConfiguration")
+	settingsJSON := imported.attrs()["settings_json"].(string)
+	for _, want := range []string{`"targetEdition":"windows10Enterprise"`, `"edition":"windows10Pro"`} {
+		if !strings.Contains(settingsJSON, want) {
+			t.Errorf("expected imported settings_json to contain %s, got %s", want, settingsJSON)
+		}
+	}
+	if strings.Contains(settingsJSON, "version") {
+		t.Errorf("expected read-only properties to be left out of settings_json, got %s", settingsJSON)
+	}
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.DeviceConfigurations, res.id()) != nil {
+		t.Errorf("profile %s still exists after destroy", res.id())
+	}
+}
+
+func TestAccDeviceConfigurationResource_wifi(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Corporate Wi-Fi",
+		"wifi": [{
+			"ssid": "corp",
+			"network_name": "Corporate",
+			"security_type": "wpa2Personal",
+			"pre_shared_key": "correct horse battery staple",
+			"connect_automatically": true
+		}]
+	}`
+	res := env.apply("intune_device_configuration", nil, config)
+	assertAttr(t, res.attrs(), "odata_type", "#microsoft.graph.windowsWifiConfiguration")
+
+	profile := env.graph.Object(fakegraph.DeviceConfigurations, res.id())
+	assertAttr(t, profile, "ssid", "corp")
+	assertAttr(t, profile, "connectAutomatically", true)
+	assertAttr(t, profile, "preSharedKey", nil)
+	assertAttr(t, env.graph.Secrets(fakegraph.DeviceConfigurations, res.id()), "preSharedKey", "correct horse battery staple")
+
+	// The pre-shared key is not returned by Graph and keeps its configured value
+	res = env.refresh(res)
+	assertAttr(t, profileBlock(t, res.attrs(), "wifi"), "pre_shared_key", "correct horse battery staple")
+	env.assertNoOp(res, config)
+
+	// Updates without a change of the key leave it in place
+	updated := strings.Replace(config, `"connect_automatically": true`, `"proxy_setting": "manual", "proxy_manual_address": "proxy.example.com", "proxy_manual_port": 8080`, 1)
+	res = env.apply("intune_device_configuration", res, updated)
+	profile = env.graph.Object(fakegraph.DeviceConfigurations, res.id())
+	assertAttr(t, profile, "connectAutomatically", false)
+	assertAttr(t, profile, "proxyManualPort", 8080)
+	assertAttr(t, env.graph.Secrets(fakegraph.DeviceConfigurations, res.id()), "preSharedKey", "correct horse battery staple")
+	env.assertNoOp(env.refresh(res), updated)
+
+	imported := env.importState("intune_device_configuration", res.id())
+	wifi := profileBlock(t, imported.attrs(), "wifi")
+	assertAttr(t, wifi, "ssid", "corp")
+	assertAttr(t, wifi, "proxy_manual_port", 8080)
+	assertAttr(t, wifi, "pre_shared_key", nil)
+	assertAttr(t, wifi, "connect_automatically", nil)
+}
+
+func TestAccDeviceConfigurationResource_custom(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Start menu",
+		"custom": [{
+			"oma_setting": [
+				{"display_name": "Hide sleep", "oma_uri": "./Device/Vendor/MSFT/Policy/Config/Start/HideSleep", "value_type": "integer", "value": "1"},
+				{"display_name": "Allow telemetry", "oma_uri": "./Device/Vendor/MSFT/Policy/Config/System/AllowTelemetry", "value_type": "boolean", "value": "TRUE"},
+				{"display_name": "Layout", "description": "Pinned tiles", "oma_uri": "./User/Vendor/MSFT/Policy/Config/Start/StartLayout", "value_type": "string", "value": "<LayoutModificationTemplate/>"}
+			]
+		}]
+	}`
+	res := env.apply("intune_device_configuration", nil, config)
+	assertAttr(t, res.attrs(), "odata_type", "#microsoft.graph.windows10CustomConfiguration")
+
+	settings := env.graph.Object(fakegraph.DeviceConfigurations, res.id())["omaSettings"].([]interface{})
+	if len(settings) != 3 {
+		t.Fatalf("expected 3 OMA-URI settings in Graph, got %d", len(settings))
+	}
+	assertAttr(t, settings[0].(map[string]interface{}), "@odata.type", "#microsoft.graph.omaSettingInteger")
+	assertAttr(t, settings[0].(map[string]interface{}), "value", 1)
+	assertAttr(t, settings[1].(map[string]interface{}), "value", true)
+
+	// Equivalent values keep their configured spelling
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	imported := env.importState("intune_device_configuration", res.id())
+	custom := profileBlock(t, imported.attrs(), "custom")
+	omaSettings := custom["oma_setting"].([]interface{})
+	assertAttr(t, omaSettings[1].(map[string]interface{}), "value", "true")
+	assertAttr(t, omaSettings[0].(map[string]interface{}), "description", nil)
+	assertAttr(t, omaSettings[2].(map[string]interface{}), "description", "Pinned tiles")
+}
+
+func TestAccDeviceConfigurationResource_updateRing(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Update ring - Pilot",
+		"update_ring": [{
+			"quality_updates_deferral_period_in_days": 0,
+			"feature_updates_deferral_period_in_days": 30,
+			"automatic_update_mode": "autoInstallAtMaintenanceTime",
+			"deadline_for_quality_updates_in_days": 3
+		}],
+		"assignment": [{"target": [{"type": "group", "group_id": "00000000-0000-0000-0000-000000000001"}]}]
+	}`
+	res := env.apply("intune_device_configuration", nil, config)
+
+	profile := env.graph.Object(fakegraph.DeviceConfigurations, res.id())
+	assertAttr(t, profile, "featureUpdatesDeferralPeriodInDays", 30)
+	assertAttr(t, profile, "automaticUpdateMode", "autoInstallAtMaintenanceTime")
+	assertAttr(t, profile, "qualityUpdatesPaused", false)
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	// Pausing updates outside of Terraform is detected
+	env.graph.Update(fakegraph.DeviceConfigurations, res.id(), map[string]interface{}{"qualityUpdatesPaused": true})
+	res = env.refresh(res)
+	assertAttr(t, profileBlock(t, res.attrs(), "update_ring"), "quality_updates_paused", true)
+	res = env.apply("intune_device_configuration", res, config)
+	assertAttr(t, env.graph.Object(fakegraph.DeviceConfigurations, res.id()), "qualityUpdatesPaused", false)
+
+	// The ring can be assigned by intune_policy_group_assignment as well
+	env.apply("intune_policy_group_assignment", nil, fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "device_configuration",
+		"target_type": "exclusion",
+		"group_id": "00000000-0000-0000-0000-000000000002"
+	}`, res.id()))
+	if n := len(env.graph.Assignments(fakegraph.DeviceConfigurations, res.id())); n != 2 {
+		t.Errorf("expected 2 assignments in Graph, got %d", n)
+	}
+}
+
+func TestAccDeviceConfigurationResource_certificates(t *testing.T) {
+	env := newTestEnv(t)
+
+	root := env.apply("intune_device_configuration", nil, `{
+		"display_name": "Corporate Root CA",
+		"trusted_root_certificate": [{
+			"certificate": "MIIBszCCAVmgAwIBAgIU",
+			"cert_file_name": "root.cer",
+			"destination_store": "computerCertStoreRoot"
+		}]
+	}`)
+
+	config := fmt.Sprintf(`{
+		"display_name": "Corporate SCEP",
+		"scep_certificate": [{
+			"root_certificate_id": %q,
+			"scep_server_urls": ["https://ndes.example.com/certsrv/mscep/mscep.dll"],
+			"subject_name_format": "commonName",
+			"key_size": "size2048",
+			"renewal_threshold_percentage": 20,
+			"extended_key_usage": [{"name": "Client Authentication", "object_identifier": "1.3.6.1.5.5.7.3.2"}]
+		}]
+	}`, root.id())
+	res := env.apply("intune_device_configuration", nil, config)
+
+	profile := env.graph.Object(fakegraph.DeviceConfigurations, res.id())
+	assertAttr(t, profile, "@odata.type", "#microsoft.graph.windows81SCEPCertificateProfile")
+	usages := profile["extendedKeyUsages"].([]interface{})
+	assertAttr(t, usages[0].(map[string]interface{}), "objectIdentifier", "1.3.6.1.5.5.7.3.2")
+	bind := env.graph.Secrets(fakegraph.DeviceConfigurations, res.id())["rootCertificate@odata.bind"]
+	if s, _ := bind.(string); !strings.HasSuffix(s, "/deviceManagement/deviceConfigurations/"+root.id()) {
+		t.Errorf("expected the root certificate to be bound, got %v", bind)
+	}
+
+	res = env.refresh(res)
+	assertAttr(t, profileBlock(t, res.attrs(), "scep_certificate"), "root_certificate_id", root.id())
+	env.assertNoOp(res, config)
+}
+
+func TestAccDeviceConfigurationResource_typeChangeReplaces(t *testing.T) {
+	env := newTestEnv(t)
+
+	res := env.apply("intune_device_configuration", nil, `{
+		"display_name": "Corporate Wi-Fi",
+		"wifi": [{"ssid": "corp", "network_name": "Corporate", "security_type": "open"}]
+	}`)
+	oldID := res.id()
+
+	res = env.apply("intune_device_configuration", res, `{
+		"display_name": "Corporate Wi-Fi",
+		"odata_type": "#microsoft.graph.windowsWiredNetworkConfiguration",
+		"settings_json": "{\"authenticationMethod\": \"certificate\"}"
+	}`)
+	if res.id() == oldID {
+		t.Errorf("expected a new profile after changing the profile type")
+	}
+	if env.graph.Object(fakegraph.DeviceConfigurations, oldID) != nil {
+		t.Errorf("profile %s still exists after replacement", oldID)
+	}
+	assertAttr(t, env.graph.Object(fakegraph.DeviceConfigurations, res.id()), "@odata.type", "#microsoft.graph.windowsWiredNetworkConfiguration")
+}
+
+func TestAccDeviceConfigurationResource_invalid(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		config string
+		want   string
+	}{
+		{`{"display_name": "Empty"}`, "Missing Device Configuration Settings"},
+		{`{
+			"display_name": "Both",
+			"odata_type": "#microsoft.graph.windowsWifiConfiguration",
+			"settings_json": "{}",
+			"wifi": [{"ssid": "corp", "network_name": "Corporate", "security_type": "open"}]
+		}`, "Conflicting Device Configuration Settings"},
+		{`{"display_name": "Untyped", "settings_json": "{}"}`, "Missing OData Type"},
+		{`{"display_name": "Array", "odata_type": "#microsoft.graph.windowsWiredNetworkConfiguration", "settings_json": "[]"}`, "Invalid Settings JSON"},
+		{`{"display_name": "Name", "odata_type": "#microsoft.graph.windowsWiredNetworkConfiguration", "settings_json": "{\"displayName\": \"x\"}"}`, "Invalid Settings JSON"},
+		{`{
+			"display_name": "Mismatch",
+			"odata_type": "#microsoft.graph.windows10VpnConfiguration",
+			"wifi": [{"ssid": "corp", "network_name": "Corporate", "security_type": "open"}]
+		}`, "Conflicting OData Type"},
+		{`{
+			"display_name": "Custom",
+			"custom": [{"oma_setting": [{"display_name": "x", "oma_uri": "./Device/x", "value_type": "integer", "value": "one"}]}]
+		}`, "Invalid OMA-URI Setting Value"},
+	} {
+		if msg := env.applyExpectError("intune_device_configuration", nil, tc.config); !strings.Contains(msg, tc.want) {
+			t.Errorf("expected %q, got: %s", tc.want, msg)
+		}
+	}
+}
@@ -0,0 +1,761 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &EndpointSecurityConfigurationPolicyResource{}
+var _ resource.ResourceWithImportState = &EndpointSecurityConfigurationPolicyResource{}
+var _ resource.ResourceWithUpgradeState = &EndpointSecurityConfigurationPolicyResource{}
+var _ resource.ResourceWithModifyPlan = &EndpointSecurityConfigurationPolicyResource{}
+var _ resource.ResourceWithMoveState = &EndpointSecurityConfigurationPolicyResource{}
+
+// legacyIntentKey marks, in private state, a resource moved from intune_endpoint_security_policy
+// whose intent has not been migrated to a configuration policy yet. The resource ID is the intent ID.
+const legacyIntentKey = "legacy_intent"
+
+// defaultEndpointSecurityPlatform is the platform templates are looked up for when platforms is not set
+const defaultEndpointSecurityPlatform = "windows10"
+
+// NewEndpointSecurityConfigurationPolicyResource creates a new resource instance
+func NewEndpointSecurityConfigurationPolicyResource() resource.Resource {
+	return &EndpointSecurityConfigurationPolicyResource{}
+}
+
+// EndpointSecurityConfigurationPolicyResource defines the resource implementation
+type EndpointSecurityConfigurationPolicyResource struct {
+	client *clients.GraphClient
+}
+
+// EndpointSecurityConfigurationPolicyResourceModel describes the resource data model
+type EndpointSecurityConfigurationPolicyResourceModel struct {
+	ID                   types.String      `tfsdk:"id"`
+	Type                 types.String      `tfsdk:"type"`
+	Name                 types.String      `tfsdk:"name"`
+	Description          types.String      `tfsdk:"description"`
+	TemplateType         types.String      `tfsdk:"template_type"`
+	TemplateId           types.String      `tfsdk:"template_id"`
+	Platforms            types.String      `tfsdk:"platforms"`
+	Technologies         types.String      `tfsdk:"technologies"`
+	RoleScopeTagIds      types.List        `tfsdk:"role_scope_tag_ids"`
+	Settings             types.List        `tfsdk:"setting"`
+	Assignment           []AssignmentModel `tfsdk:"assignment"`
+	CreatedDateTime      types.String      `tfsdk:"created_date_time"`
+	LastModifiedDateTime types.String      `tfsdk:"last_modified_date_time"`
+}
+
+// Metadata returns the resource type name
+func (r *EndpointSecurityConfigurationPolicyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_endpoint_security_configuration_policy"
+}
+
+// Schema defines the schema for the resource
+func (r *EndpointSecurityConfigurationPolicyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version:     1,
+		Description: "Manages an Intune endpoint security policy created from a Settings Catalog template.",
+		MarkdownDescription: `
+Manages an Intune endpoint security policy created from a Settings Catalog template.
+
+Microsoft has moved the Antivirus, Disk Encryption, Firewall, Endpoint Detection and Response,
+Attack Surface Reduction and Account Protection templates from intents to Settings Catalog
+configuration policies that reference a template. This resource manages those policies; use it
+instead of ` + "`intune_endpoint_security_policy`" + ` for new policies.
+
+The settings use the same ` + "`setting`" + ` blocks as ` + "`intune_settings_catalog_policy_settings`" + `,
+and may only contain settings of the template. The references to the setting templates that
+Graph requires are added automatically.
+
+## Example Usage
+
+` + "```hcl" + `
+resource "intune_endpoint_security_configuration_policy" "antivirus" {
+  name          = "Corporate Antivirus Settings"
+  template_type = "antivirus"
+
+  setting {
+    definition_id = "device_vendor_msft_policy_config_defender_allowrealtimemonitoring"
+    value_type    = "choice"
+    value         = "device_vendor_msft_policy_config_defender_allowrealtimemonitoring_1"
+  }
+
+  assignment {
+    target {
+      type = "all_devices"
+    }
+  }
+}
+` + "```" + `
+
+## Templates
+
+When only ` + "`template_type`" + ` is set, the newest active template of that type for ` + "`platforms`" + `
+is looked up at plan time. Existing policies keep their template when Microsoft publishes a newer
+version. Set ` + "`template_id`" + ` to choose a specific template.
+
+## Migrating From intune_endpoint_security_policy
+
+Intents cannot be converted in place. Replace the resource type and add a ` + "`moved`" + ` block:
+
+` + "```hcl" + `
+moved {
+  from = intune_endpoint_security_policy.antivirus
+  to   = intune_endpoint_security_configuration_policy.antivirus
+}
+` + "```" + `
+
+The next apply creates the configuration policy with the configured settings and assignments and
+then deletes the intent. The policy gets a new ID.
+`,
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for the policy.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Description: "The policy type for use with policy assignments. Always 'settings_catalog' for this resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The display name of the policy.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the policy.",
+				Optional:    true,
+			},
+			"template_type": schema.StringAttribute{
+				Description: "The type of endpoint security template. Valid values: antivirus, diskEncryption, firewall, " +
+					"endpointDetectionAndResponse, attackSurfaceReduction, accountProtection. Either template_type or template_id must be specified.",
+				Optional: true,
+				Computed: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						"antivirus",
+						"diskEncryption",
+						"firewall",
+						"endpointDetectionAndResponse",
+						"attackSurfaceReduction",
+						"accountProtection",
+					),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"template_id": schema.StringAttribute{
+				Description: "The ID of the configuration policy template. When only template_type is set, this is the " +
+					"newest matching template at creation time.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"platforms": schema.StringAttribute{
+				Description: "The platform of the template, e.g. windows10, macOS or linux. Defaults to windows10 when the " +
+					"template is looked up by template_type.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"technologies": schema.StringAttribute{
+				Description: "The technologies of the template.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"role_scope_tag_ids": schema.ListAttribute{
+				Description: "List of scope tag IDs for this policy.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"created_date_time": schema.StringAttribute{
+				Description: "The date and time the policy was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_modified_date_time": schema.StringAttribute{
+				Description: "The date and time the policy was last modified.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"setting":    SettingBlockSchema(1),
+			"assignment": AssignmentBlockSchema(),
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *EndpointSecurityConfigurationPolicyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+}
+
+// privateData is implemented by the private state of every resource operation
+type privateData interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+// isLegacyIntent reports whether the resource still refers to an intent moved from intune_endpoint_security_policy
+func isLegacyIntent(ctx context.Context, private privateData, diags *diag.Diagnostics) bool {
+	value, d := private.GetKey(ctx, legacyIntentKey)
+	diags.Append(d...)
+	return len(value) > 0
+}
+
+// ModifyPlan resolves template_type to the newest matching template and plans the migration of
+// moved intents, which get a new ID
+func (r *EndpointSecurityConfigurationPolicyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	legacy := !req.State.Raw.IsNull() && isLegacyIntent(ctx, req.Private, &resp.Diagnostics)
+	if legacy {
+		// The migration creates a new policy anyway, so template changes need no replacement
+		resp.RequiresReplace = nil
+		for _, attr := range []string{"id", "created_date_time", "last_modified_date_time"} {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(attr), types.StringUnknown())...)
+		}
+	}
+
+	var templateId, templateType, platforms types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("template_id"), &templateId)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("template_type"), &templateType)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("platforms"), &platforms)...)
+	if resp.Diagnostics.HasError() || !templateId.IsNull() || templateType.IsUnknown() || platforms.IsUnknown() {
+		return
+	}
+
+	if templateType.IsNull() {
+		if req.State.Raw.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("template_type"),
+				"Missing Endpoint Security Template",
+				"Either template_id or template_type must be specified.",
+			)
+		}
+		return
+	}
+
+	// Existing policies keep their template while template_type and platforms are unchanged
+	if !req.State.Raw.IsNull() && !legacy {
+		var stateTemplateType, statePlatforms types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("template_type"), &stateTemplateType)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("platforms"), &statePlatforms)...)
+		if resp.Diagnostics.HasError() || (stateTemplateType.Equal(templateType) && (platforms.IsNull() || statePlatforms.Equal(platforms))) {
+			return
+		}
+	}
+
+	if r.client == nil {
+		return
+	}
+
+	template, err := r.resolveTemplate(ctx, templateType.ValueString(), platforms.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("template_type"),
+			"Error Resolving Endpoint Security Template",
+			err.Error(),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("template_id"), template.ID)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("platforms"), template.Platforms)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("technologies"), template.Technologies)...)
+}
+
+// resolveTemplate returns the newest active template of a template type for a platform
+func (r *EndpointSecurityConfigurationPolicyResource) resolveTemplate(ctx context.Context, templateType, platforms string) (*clients.ConfigurationPolicyTemplate, error) {
+	if platforms == "" {
+		platforms = defaultEndpointSecurityPlatform
+	}
+
+	templates, err := r.client.ListConfigurationPolicyTemplates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list templates: %w", err)
+	}
+
+	template := findConfigurationPolicyTemplate(templates, endpointSecurityTemplateFamilies[templateType], platforms)
+	if template == nil {
+		return nil, fmt.Errorf("no active %s template exists for platform %q", templateType, platforms)
+	}
+
+	tflog.Debug(ctx, "Resolved endpoint security configuration policy template", map[string]interface{}{
+		"templateType": templateType,
+		"templateId":   template.ID,
+		"version":      template.Version,
+	})
+
+	return template, nil
+}
+
+// buildSettings converts the setting blocks into settings referencing the setting templates of the template
+func (r *EndpointSecurityConfigurationPolicyResource) buildSettings(ctx context.Context, templateId string, settingsList types.List, diags *diag.Diagnostics) []clients.SettingsCatalogPolicySetting {
+	settings := BuildSettingInstances(SettingModelsFromList(settingsList), path.Root("setting"), diags)
+	if diags.HasError() || len(settings) == 0 {
+		return settings
+	}
+
+	settingTemplates, err := r.client.GetConfigurationPolicyTemplateSettings(ctx, templateId)
+	if err != nil {
+		diags.AddError(
+			"Error Reading Endpoint Security Template",
+			fmt.Sprintf("Could not read the setting templates of template %s: %s", templateId, err),
+		)
+		return nil
+	}
+
+	ApplySettingTemplates(settings, templateId, settingTemplateIndex(settingTemplates), diags)
+	return settings
+}
+
+// create creates the configuration policy described by data and its assignments, and fills in
+// the computed attributes
+func (r *EndpointSecurityConfigurationPolicyResource) create(ctx context.Context, data *EndpointSecurityConfigurationPolicyResourceModel, diags *diag.Diagnostics) {
+	// The template is normally resolved at plan time, unless template_type was unknown then
+	var template *clients.ConfigurationPolicyTemplate
+	var err error
+	if templateId := data.TemplateId.ValueString(); templateId != "" {
+		template, err = r.client.GetConfigurationPolicyTemplate(ctx, templateId)
+	} else if !data.TemplateType.IsNull() && !data.TemplateType.IsUnknown() {
+		template, err = r.resolveTemplate(ctx, data.TemplateType.ValueString(), data.Platforms.ValueString())
+	} else {
+		err = fmt.Errorf("either template_id or template_type must be specified")
+	}
+	if err != nil {
+		diags.AddError(
+			"Error Resolving Endpoint Security Template",
+			fmt.Sprintf("Could not determine the template of the policy: %s", err),
+		)
+		return
+	}
+
+	settings := r.buildSettings(ctx, template.ID, data.Settings, diags)
+	if diags.HasError() {
+		return
+	}
+
+	policy := &clients.SettingsCatalogPolicy{
+		Name:              data.Name.ValueString(),
+		Description:       data.Description.ValueString(),
+		Platforms:         template.Platforms,
+		Technologies:      template.Technologies,
+		RoleScopeTagIds:   []string{"0"},
+		Settings:          settings,
+		TemplateReference: &clients.SettingsCatalogTemplateReference{TemplateId: template.ID},
+	}
+
+	// Add role scope tag IDs if specified
+	if !data.RoleScopeTagIds.IsNull() {
+		var tagIds []string
+		diags.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		if diags.HasError() {
+			return
+		}
+		policy.RoleScopeTagIds = tagIds
+	}
+
+	created, err := r.client.CreateSettingsCatalogPolicy(ctx, policy)
+	if err != nil {
+		diags.AddError(
+			"Error Creating Endpoint Security Policy",
+			fmt.Sprintf("Could not create policy: %s", err),
+		)
+		return
+	}
+
+	// Update the model with the created policy data
+	data.ID = types.StringValue(created.ID)
+	data.Type = types.StringValue(PolicyTypeSettingsCatalog)
+	data.TemplateId = types.StringValue(template.ID)
+	data.TemplateType = types.StringValue(endpointSecurityTemplateTypeForFamily(template.TemplateFamily))
+	data.Platforms = types.StringValue(template.Platforms)
+	data.Technologies = types.StringValue(template.Technologies)
+	data.CreatedDateTime = types.StringValue(created.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(created.LastModifiedDateTime)
+
+	// Handle assignments if specified
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, diags)
+		if diags.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeSettingsCatalog, created.ID, assignments); err != nil {
+			diags.AddError(
+				"Error Assigning Policy",
+				fmt.Sprintf("Policy was created but assignment failed: %s", err),
+			)
+			return
+		}
+	}
+
+	tflog.Debug(ctx, "Created endpoint security configuration policy", map[string]interface{}{
+		"id":         created.ID,
+		"templateId": template.ID,
+	})
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *EndpointSecurityConfigurationPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data EndpointSecurityConfigurationPolicyResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating endpoint security configuration policy", map[string]interface{}{
+		"name": data.Name.ValueString(),
+	})
+
+	r.create(ctx, &data, &resp.Diagnostics)
+	if data.ID.IsUnknown() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data
+func (r *EndpointSecurityConfigurationPolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data EndpointSecurityConfigurationPolicyResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Reading endpoint security configuration policy", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	// A moved intent that has not been migrated yet only needs to exist
+	if isLegacyIntent(ctx, req.Private, &resp.Diagnostics) {
+		_, err := r.client.Get(ctx, fmt.Sprintf("%s/%s", clients.PathEndpointSecurityPolicies, data.ID.ValueString()))
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+		} else if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reading Endpoint Security Policy",
+				fmt.Sprintf("Could not read intent ID %s: %s", data.ID.ValueString(), err),
+			)
+		}
+		return
+	}
+
+	// Get the policy, together with its assignments if the state had assignments configured
+	policyPath := fmt.Sprintf("%s('%s')?$expand=settings", clients.PathSettingsCatalogPolicies, data.ID.ValueString())
+	result, err := readPolicyWithAssignments[clients.SettingsCatalogPolicy](ctx, r.client, PolicyTypeSettingsCatalog, policyPath, data.ID.ValueString(), len(data.Assignment) > 0)
+	if err != nil {
+		// Check if policy was deleted
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Endpoint Security Policy",
+			fmt.Sprintf("Could not read policy ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	// Update the model
+	policy := result.Policy
+	data.Type = types.StringValue(PolicyTypeSettingsCatalog)
+	data.Name = types.StringValue(policy.Name)
+	data.Description = optionalStringValue(data.Description, policy.Description)
+	data.Platforms = types.StringValue(policy.Platforms)
+	data.Technologies = types.StringValue(policy.Technologies)
+	data.CreatedDateTime = types.StringValue(policy.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(policy.LastModifiedDateTime)
+	if policy.TemplateReference != nil {
+		data.TemplateId = types.StringValue(policy.TemplateReference.TemplateId)
+		if templateType := endpointSecurityTemplateTypeForFamily(policy.TemplateReference.TemplateFamily); templateType != "" {
+			data.TemplateType = types.StringValue(templateType)
+		}
+	}
+
+	// Handle role scope tag IDs
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, policy.RoleScopeTagIds, &resp.Diagnostics)
+
+	// Convert the settings, keeping the configured spelling of equal values
+	settings, unsupported := FlattenSettingInstances(policy.Settings)
+	for _, definitionID := range unsupported {
+		tflog.Warn(ctx, "Unknown setting type", map[string]interface{}{
+			"definition_id": definitionID,
+		})
+	}
+	PreserveSettingValues(SettingModelsFromList(data.Settings), settings)
+	data.Settings = SettingModelsToList(settings, 1, &resp.Diagnostics)
+
+	// Update assignments if the state had assignments configured
+	if len(data.Assignment) > 0 {
+		if result.AssignmentsErr != nil {
+			tflog.Warn(ctx, "Failed to read policy assignments", map[string]interface{}{
+				"error": result.AssignmentsErr.Error(),
+			})
+		} else {
+			data.Assignment = PreserveAssignments(data.Assignment, result.Assignments)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update updates the resource and sets the updated Terraform state
+func (r *EndpointSecurityConfigurationPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data EndpointSecurityConfigurationPolicyResourceModel
+	var state EndpointSecurityConfigurationPolicyResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if isLegacyIntent(ctx, req.Private, &resp.Diagnostics) {
+		r.migrateIntent(ctx, state.ID.ValueString(), &data, resp)
+		return
+	}
+
+	tflog.Debug(ctx, "Updating endpoint security configuration policy", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	settings := r.buildSettings(ctx, data.TemplateId.ValueString(), data.Settings, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	policy := &clients.SettingsCatalogPolicy{
+		Name:         data.Name.ValueString(),
+		Description:  data.Description.ValueString(),
+		Platforms:    data.Platforms.ValueString(),
+		Technologies: data.Technologies.ValueString(),
+	}
+
+	// Add role scope tag IDs if specified
+	if !data.RoleScopeTagIds.IsNull() {
+		var tagIds []string
+		resp.Diagnostics.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		policy.RoleScopeTagIds = tagIds
+	}
+
+	if _, err := r.client.UpdateSettingsCatalogPolicy(ctx, data.ID.ValueString(), policy); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Endpoint Security Policy",
+			fmt.Sprintf("Could not update policy ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	if err := r.client.UpdateSettingsCatalogPolicySettings(ctx, data.ID.ValueString(), settings); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Policy Settings",
+			fmt.Sprintf("Could not update policy settings: %s", err),
+		)
+		return
+	}
+
+	updated, err := r.client.GetSettingsCatalogPolicy(ctx, data.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Endpoint Security Policy",
+			fmt.Sprintf("Could not read policy ID %s after updating it: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+	data.LastModifiedDateTime = types.StringValue(updated.LastModifiedDateTime)
+
+	// Handle assignments
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeSettingsCatalog, data.ID.ValueString(), assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Policy Assignments",
+				fmt.Sprintf("Could not update assignments: %s", err),
+			)
+			return
+		}
+	} else {
+		// Clear assignments if none specified
+		if err := AssignPolicy(ctx, r.client, PolicyTypeSettingsCatalog, data.ID.ValueString(), []clients.PolicyAssignment{}); err != nil {
+			tflog.Warn(ctx, "Failed to clear policy assignments", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// migrateIntent replaces a moved intent with a configuration policy. The policy is created before
+// the intent is deleted, so that devices are not left without the policy in between.
+func (r *EndpointSecurityConfigurationPolicyResource) migrateIntent(ctx context.Context, intentId string, data *EndpointSecurityConfigurationPolicyResourceModel, resp *resource.UpdateResponse) {
+	tflog.Debug(ctx, "Migrating endpoint security intent to a configuration policy", map[string]interface{}{
+		"intentId": intentId,
+	})
+
+	r.create(ctx, data, &resp.Diagnostics)
+	if data.ID.IsUnknown() {
+		return
+	}
+
+	// From here on the state refers to the configuration policy
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, legacyIntentKey, nil)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+
+	err := r.client.Delete(ctx, fmt.Sprintf("%s/%s", clients.PathEndpointSecurityPolicies, intentId))
+	if err != nil && !clients.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Error Deleting Migrated Intent",
+			fmt.Sprintf("Configuration policy %s was created, but the intent %s it replaces could not be deleted and must be removed manually: %s", data.ID.ValueString(), intentId, err),
+		)
+	}
+}
+
+// Delete deletes the resource and removes the Terraform state
+func (r *EndpointSecurityConfigurationPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data EndpointSecurityConfigurationPolicyResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting endpoint security configuration policy", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	var err error
+	if isLegacyIntent(ctx, req.Private, &resp.Diagnostics) {
+		err = r.client.Delete(ctx, fmt.Sprintf("%s/%s", clients.PathEndpointSecurityPolicies, data.ID.ValueString()))
+	} else {
+		err = r.client.DeleteSettingsCatalogPolicy(ctx, data.ID.ValueString())
+	}
+	if err != nil {
+		// Ignore not found errors during delete
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Endpoint Security Policy",
+			fmt.Sprintf("Could not delete policy ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// UpgradeState upgrades the assignment blocks of prior schema versions
+func (r *EndpointSecurityConfigurationPolicyResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return assignmentStateUpgraders()
+}
+
+// ImportState imports the resource state
+func (r *EndpointSecurityConfigurationPolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// MoveState accepts moved blocks from intune_endpoint_security_policy. The moved resource keeps
+// referring to the intent until the next apply migrates it.
+func (r *EndpointSecurityConfigurationPolicyResource) MoveState(ctx context.Context) []resource.StateMover {
+	var sourceSchema resource.SchemaResponse
+	NewEndpointSecurityPolicyResource().Schema(ctx, resource.SchemaRequest{}, &sourceSchema)
+
+	return []resource.StateMover{
+		{
+			SourceSchema: &sourceSchema.Schema,
+			StateMover:   r.moveFromIntent,
+		},
+	}
+}
+
+// moveFromIntent converts the state of an intune_endpoint_security_policy
+func (r *EndpointSecurityConfigurationPolicyResource) moveFromIntent(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+	if req.SourceTypeName != "intune_endpoint_security_policy" || req.SourceState == nil {
+		return
+	}
+
+	var source EndpointSecurityPolicyResourceModel
+	resp.Diagnostics.Append(req.SourceState.Get(ctx, &source)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	target := EndpointSecurityConfigurationPolicyResourceModel{
+		ID:                   source.ID,
+		Type:                 types.StringValue(PolicyTypeSettingsCatalog),
+		Name:                 source.DisplayName,
+		Description:          source.Description,
+		TemplateType:         source.TemplateType,
+		TemplateId:           types.StringNull(),
+		Platforms:            types.StringNull(),
+		Technologies:         types.StringNull(),
+		RoleScopeTagIds:      source.RoleScopeTagIds,
+		Settings:             types.ListNull(types.ObjectType{AttrTypes: SettingModelAttrTypes(1)}),
+		Assignment:           source.Assignment,
+		CreatedDateTime:      source.CreatedDateTime,
+		LastModifiedDateTime: source.LastModifiedDateTime,
+	}
+
+	resp.Diagnostics.Append(resp.TargetState.Set(ctx, &target)...)
+	resp.Diagnostics.Append(resp.TargetPrivate.SetKey(ctx, legacyIntentKey, []byte("true"))...)
+}
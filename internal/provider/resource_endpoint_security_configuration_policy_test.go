@@ -0,0 +1,211 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+const (
+	testRequireEncryption    = "device_vendor_msft_bitlocker_requiredeviceencryption"
+	testStartupAuthenticate  = "device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication"
+	testAllowCloudProtection = "device_vendor_msft_defender_configuration_allowcloudprotection"
+)
+
+// policyTemplate returns a configuration policy template of a family with choice setting templates
+// for the given definitions. Instance template IDs are the definition ID suffixed with -tpl, value
+// template IDs with -val.
+func policyTemplate(id, family string, version int, definitionIDs ...string) map[string]interface{} {
+	settingTemplates := make([]interface{}, 0, len(definitionIDs))
+	for _, definitionID := range definitionIDs {
+		settingTemplates = append(settingTemplates, map[string]interface{}{
+			"id": id + "_" + definitionID,
+			"settingInstanceTemplate": map[string]interface{}{
+				"@odata.type":               "#microsoft.graph.deviceManagementConfigurationChoiceSettingInstanceTemplate",
+				"settingInstanceTemplateId": definitionID + "-tpl",
+				"settingDefinitionId":       definitionID,
+				"choiceSettingValueTemplate": map[string]interface{}{
+					"settingValueTemplateId": definitionID + "-val",
+				},
+			},
+		})
+	}
+	return map[string]interface{}{
+		"id":               id,
+		"baseId":           family,
+		"version":          version,
+		"displayName":      family,
+		"displayVersion":   fmt.Sprintf("Version %d", version),
+		"lifecycleState":   "active",
+		"platforms":        "windows10",
+		"technologies":     "mdm,microsoftSense",
+		"templateFamily":   family,
+		"settingTemplates": settingTemplates,
+	}
+}
+
+// addPolicyTemplates registers disk encryption and firewall configuration policy templates
+func addPolicyTemplates(env *testEnv) {
+	addSettingDefinitions(env)
+	env.graph.AddPolicyTemplate(policyTemplate("de-1", "endpointSecurityDiskEncryption", 1, testRequireEncryption))
+	env.graph.AddPolicyTemplate(policyTemplate("de-2", "endpointSecurityDiskEncryption", 2, testRequireEncryption, testStartupAuthenticate))
+	env.graph.AddPolicyTemplate(policyTemplate("fw-cp", "endpointSecurityFirewall", 1, testAllowCloudProtection))
+}
+
+func TestAccEndpointSecurityConfigurationPolicyResource(t *testing.T) {
+	env := newTestEnv(t)
+	addPolicyTemplates(env)
+
+	config := `{
+		"name": "BitLocker",
+		"template_type": "diskEncryption",
+		"setting": [{
+			"definition_id": "device_vendor_msft_bitlocker_requiredeviceencryption",
+			"value_type": "choice",
+			"value": "device_vendor_msft_bitlocker_requiredeviceencryption_1",
+			"children": [{
+				"definition_id": "device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication",
+				"value_type": "choice",
+				"value": "device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication_0"
+			}]
+		}],
+		"assignment": [{"target": [{"type": "all_devices"}]}]
+	}`
+	res := env.apply("intune_endpoint_security_configuration_policy", nil, config)
+	assertAttr(t, res.attrs(), "type", PolicyTypeSettingsCatalog)
+	assertAttr(t, res.attrs(), "template_id", "de-2")
+	assertAttr(t, res.attrs(), "platforms", "windows10")
+	assertAttr(t, res.attrs(), "technologies", "mdm,microsoftSense")
+
+	policy := env.graph.Object(fakegraph.ConfigurationPolicies, res.id())
+	reference, _ := policy["templateReference"].(map[string]interface{})
+	assertAttr(t, reference, "templateFamily", "endpointSecurityDiskEncryption")
+
+	// Every setting references the setting templates of its definition
+	settings := env.graph.Settings(fakegraph.ConfigurationPolicies, res.id())
+	if len(settings) != 1 {
+		t.Fatalf("expected 1 setting, got %d", len(settings))
+	}
+	instance := settings[0].(map[string]interface{})["settingInstance"].(map[string]interface{})
+	assertAttr(t, instance["settingInstanceTemplateReference"].(map[string]interface{}), "settingInstanceTemplateId", testRequireEncryption+"-tpl")
+	value := instance["choiceSettingValue"].(map[string]interface{})
+	assertAttr(t, value["settingValueTemplateReference"].(map[string]interface{}), "settingValueTemplateId", testRequireEncryption+"-val")
+	child := value["children"].([]interface{})[0].(map[string]interface{})
+	assertAttr(t, child["settingInstanceTemplateReference"].(map[string]interface{}), "settingInstanceTemplateId", testStartupAuthenticate+"-tpl")
+	if n := len(env.graph.Assignments(fakegraph.ConfigurationPolicies, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment, got %d", n)
+	}
+
+	// A newer template does not replace existing policies
+	env.graph.AddPolicyTemplate(policyTemplate("de-3", "endpointSecurityDiskEncryption", 3, testRequireEncryption))
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	updated := strings.Replace(config, "requiredeviceencryption_1", "requiredeviceencryption_0", 1)
+	updated = strings.Replace(updated, `"name": "BitLocker"`, `"name": "BitLocker", "description": "Managed by OpenTofu"`, 1)
+	res = env.apply("intune_endpoint_security_configuration_policy", res, updated)
+	assertAttr(t, env.graph.Object(fakegraph.ConfigurationPolicies, res.id()), "description", "Managed by OpenTofu")
+	instance = env.graph.Settings(fakegraph.ConfigurationPolicies, res.id())[0].(map[string]interface{})["settingInstance"].(map[string]interface{})
+	assertAttr(t, instance["choiceSettingValue"].(map[string]interface{}), "value", testRequireEncryption+"_0")
+	env.assertNoOp(env.refresh(res), updated)
+
+	imported := env.importState("intune_endpoint_security_configuration_policy", res.id())
+	assertAttr(t, imported.attrs(), "name", "BitLocker")
+	assertAttr(t, imported.attrs(), "template_type", "diskEncryption")
+	assertAttr(t, imported.attrs(), "template_id", "de-2")
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.ConfigurationPolicies, res.id()) != nil {
+		t.Errorf("policy %s still exists after destroy", res.id())
+	}
+}
+
+func TestAccEndpointSecurityConfigurationPolicyResource_templates(t *testing.T) {
+	env := newTestEnv(t)
+	addPolicyTemplates(env)
+
+	// An explicit template_id pins an older template
+	res := env.apply("intune_endpoint_security_configuration_policy", nil, `{"name": "BitLocker v1", "template_id": "de-1"}`)
+	assertAttr(t, res.attrs(), "template_type", "diskEncryption")
+
+	// Settings the template does not define are rejected before the policy is created
+	msg := env.applyExpectError("intune_endpoint_security_configuration_policy", nil, `{
+		"name": "BitLocker v1",
+		"template_id": "de-1",
+		"setting": [{
+			"definition_id": "device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication",
+			"value_type": "choice",
+			"value": "device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication_0"
+		}]
+	}`)
+	if !strings.Contains(msg, "Setting Not In Template") {
+		t.Errorf("expected a setting not in template error, got: %s", msg)
+	}
+
+	msg = env.applyExpectError("intune_endpoint_security_configuration_policy", nil, `{"name": "Antivirus", "template_type": "antivirus"}`)
+	if !strings.Contains(msg, "Error Resolving Endpoint Security Template") {
+		t.Errorf("expected a template resolution error, got: %s", msg)
+	}
+
+	msg = env.applyExpectError("intune_endpoint_security_configuration_policy", nil, `{"name": "Nothing"}`)
+	if !strings.Contains(msg, "Missing Endpoint Security Template") {
+		t.Errorf("expected a missing template error, got: %s", msg)
+	}
+}
+
+func TestAccEndpointSecurityConfigurationPolicyResource_moveFromIntent(t *testing.T) {
+	env := newTestEnv(t)
+	addEndpointSecurityTemplates(env)
+	addPolicyTemplates(env)
+
+	intent := env.apply("intune_endpoint_security_policy", nil, `{
+		"display_name": "Firewall",
+		"description": "Block inbound",
+		"template_type": "firewall",
+		"settings_json": "{}",
+		"assignment": [{"target": [{"type": "all_devices"}]}]
+	}`)
+
+	moved := env.moveState(intent, "intune_endpoint_security_configuration_policy")
+	assertAttr(t, moved.attrs(), "id", intent.id())
+	assertAttr(t, moved.attrs(), "name", "Firewall")
+	assertAttr(t, moved.attrs(), "template_type", "firewall")
+
+	// Until the next apply the moved resource still refers to the intent
+	moved = env.refresh(moved)
+	assertAttr(t, moved.attrs(), "id", intent.id())
+
+	config := `{
+		"name": "Firewall",
+		"description": "Block inbound",
+		"template_type": "firewall",
+		"setting": [{
+			"definition_id": "device_vendor_msft_defender_configuration_allowcloudprotection",
+			"value_type": "choice",
+			"value": "device_vendor_msft_defender_configuration_allowcloudprotection_1"
+		}],
+		"assignment": [{"target": [{"type": "all_devices"}]}]
+	}`
+	res := env.apply("intune_endpoint_security_configuration_policy", moved, config)
+	if res.id() == intent.id() {
+		t.Fatalf("expected the migrated policy to get a new ID, got %s", res.id())
+	}
+	assertAttr(t, res.attrs(), "template_id", "fw-cp")
+	if env.graph.Object(fakegraph.Intents, intent.id()) != nil {
+		t.Errorf("intent %s still exists after the migration", intent.id())
+	}
+	if n := len(env.graph.Assignments(fakegraph.ConfigurationPolicies, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment on the migrated policy, got %d", n)
+	}
+	env.assertNoOp(env.refresh(res), config)
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.ConfigurationPolicies, res.id()) != nil {
+		t.Errorf("policy %s still exists after destroy", res.id())
+	}
+}
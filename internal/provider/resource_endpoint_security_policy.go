@@ -5,10 +5,10 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -24,6 +24,8 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces
 var _ resource.Resource = &EndpointSecurityPolicyResource{}
 var _ resource.ResourceWithImportState = &EndpointSecurityPolicyResource{}
+var _ resource.ResourceWithUpgradeState = &EndpointSecurityPolicyResource{}
+var _ resource.ResourceWithModifyPlan = &EndpointSecurityPolicyResource{}
 
 // NewEndpointSecurityPolicyResource creates a new resource instance
 func NewEndpointSecurityPolicyResource() resource.Resource {
@@ -70,6 +72,7 @@ func (r *EndpointSecurityPolicyResource) Metadata(ctx context.Context, req resou
 // Schema defines the schema for the resource
 func (r *EndpointSecurityPolicyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version:     1,
 		Description: "Manages an Intune Endpoint Security policy.",
 		MarkdownDescription: `
 Manages an Intune Endpoint Security policy.
@@ -82,30 +85,11 @@ Endpoint Security policies provide security baselines for:
 - Attack Surface Reduction
 - Account Protection
 
-## Example Usage
-
-### Antivirus Policy
-
-` + "```hcl" + `
-resource "intune_endpoint_security_policy" "antivirus" {
-  display_name  = "Corporate Antivirus Settings"
-  description   = "Microsoft Defender Antivirus configuration"
-  template_type = "antivirus"
+These policies use the intent-based templates, which Microsoft is retiring in favor of Settings
+Catalog templates. New policies should use ` + "`intune_endpoint_security_configuration_policy`" + `,
+which existing policies can be moved to with a ` + "`moved`" + ` block.
 
-  settings_json = jsonencode({
-    "allowArchiveScanning"         = true
-    "allowBehaviorMonitoring"      = true
-    "allowCloudProtection"         = true
-    "allowFullScanRemovableDriveScanning" = true
-    "allowIntrusionPreventionSystem" = true
-    "allowOnAccessProtection"      = true
-    "allowRealtimeMonitoring"      = true
-    "allowScanningNetworkFiles"    = true
-    "allowScriptScanning"          = true
-    "cloudBlockLevel"              = "high"
-  })
-}
-` + "```" + `
+## Example Usage
 
 ### Firewall Policy
 
@@ -116,15 +100,28 @@ resource "intune_endpoint_security_policy" "firewall" {
   template_type = "firewall"
 
   settings_json = jsonencode({
-    "enableFirewall"         = true
-    "enableStealthMode"      = true
-    "enablePacketQueue"      = true
-    "defaultInboundAction"   = "block"
-    "defaultOutboundAction"  = "allow"
+    firewallBlockStatefulFTP                           = true
+    firewallIdleTimeoutForSecurityAssociationInSeconds = 300
+    firewallPreSharedKeyEncodingMethod                 = "utF8"
+    firewallProfileDomain = {
+      firewallEnabled = "allowed"
+    }
   })
 }
 ` + "```" + `
 
+## Settings
+
+` + "`settings_json`" + ` is an object keyed by the setting definition IDs of the template, such as
+` + "`deviceConfiguration--windows10EndpointProtectionConfiguration_firewallBlockStatefulFTP`" + `, or by
+the setting name that follows the configuration class (` + "`firewallBlockStatefulFTP`" + `) when that
+name is unique within the template. Values must match the type of the setting: booleans, integers and strings as
+JSON scalars, collections as lists and complex settings as objects. ` + "`null`" + ` clears a setting.
+
+Unknown settings and values of the wrong type are errors. Only the settings listed in
+` + "`settings_json`" + ` are managed and checked for drift; settings removed from it keep their value
+in Intune.
+
 ## Template Types
 
 | Type | Description |
@@ -135,6 +132,11 @@ resource "intune_endpoint_security_policy" "firewall" {
 | endpointDetectionAndResponse | Microsoft Defender for Endpoint EDR settings |
 | attackSurfaceReduction | Attack Surface Reduction rules |
 | accountProtection | Windows Hello and Credential Guard settings |
+
+When only ` + "`template_type`" + ` is set, the newest non-deprecated Windows template of that type is
+looked up at plan time. Existing policies keep their template when Microsoft publishes a newer
+version; use the ` + "`intune_endpoint_security_template`" + ` data source to pin or choose a
+specific template.
 `,
 
 		Attributes: map[string]schema.Attribute{
@@ -161,10 +163,12 @@ resource "intune_endpoint_security_policy" "firewall" {
 				Optional:    true,
 			},
 			"template_id": schema.StringAttribute{
-				Description: "The template ID to base the policy on. Either template_id or template_type must be specified.",
-				Optional:    true,
-				Computed:    true,
+				Description: "The template ID to base the policy on. Either template_id or template_type must be specified. " +
+					"When only template_type is set, this is the newest matching template at creation time.",
+				Optional: true,
+				Computed: true,
 				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
@@ -192,8 +196,9 @@ resource "intune_endpoint_security_policy" "firewall" {
 				ElementType: types.StringType,
 			},
 			"settings_json": schema.StringAttribute{
-				Description: "The policy settings as a JSON string. The structure depends on the template type.",
-				Required:    true,
+				Description: "The policy settings as a JSON object keyed by setting definition ID or setting name. " +
+					"The available settings depend on the template.",
+				Required: true,
 			},
 			"created_date_time": schema.StringAttribute{
 				Description: "The date and time the policy was created.",
@@ -241,27 +246,24 @@ func (r *EndpointSecurityPolicyResource) Create(ctx context.Context, req resourc
 		"name": data.DisplayName.ValueString(),
 	})
 
-	// Validate settings JSON
-	var settings map[string]interface{}
-	if err := json.Unmarshal([]byte(data.Settings.ValueString()), &settings); err != nil {
-		resp.Diagnostics.AddError(
-			"Invalid Settings JSON",
-			fmt.Sprintf("Could not parse settings_json: %s", err),
-		)
-		return
+	// The template is normally resolved at plan time, unless template_type was unknown then
+	templateId := data.TemplateId.ValueString()
+	if templateId == "" {
+		var err error
+		templateId, err = r.resolveTemplateID(ctx, data.TemplateType.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("template_type"),
+				"Error Resolving Endpoint Security Template",
+				err.Error(),
+			)
+			return
+		}
 	}
 
-	// Determine template ID
-	templateId := data.TemplateId.ValueString()
-	if templateId == "" && !data.TemplateType.IsNull() {
-		// Look up template ID from type
-		// In a real implementation, we would query the Graph API for available templates
-		// For now, we'll use a placeholder that requires the user to specify template_id
-		resp.Diagnostics.AddError(
-			"Template ID Required",
-			"When using template_type, you must also specify the template_id. "+
-				"Use the intune_endpoint_security_template data source to look up the template ID.",
-		)
+	// Map the settings before creating the policy, so that invalid settings do not leave it behind
+	settings := r.intentSettings(ctx, templateId, data.Settings.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
@@ -285,7 +287,7 @@ func (r *EndpointSecurityPolicyResource) Create(ctx context.Context, req resourc
 	}
 
 	// Create the policy via Graph API
-	response, err := r.client.Post(ctx, "/deviceManagement/intents", policyRequest)
+	created, err := clients.PostInto[clients.EndpointSecurityPolicy](ctx, r.client, "/deviceManagement/intents", policyRequest)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Creating Endpoint Security Policy",
@@ -294,29 +296,8 @@ func (r *EndpointSecurityPolicyResource) Create(ctx context.Context, req resourc
 		return
 	}
 
-	// Parse the response to get the ID
-	var created struct {
-		ID                   string `json:"id"`
-		CreatedDateTime      string `json:"createdDateTime"`
-		LastModifiedDateTime string `json:"lastModifiedDateTime"`
-	}
-	respBytes, _ := json.Marshal(response)
-	if err := json.Unmarshal(respBytes, &created); err != nil {
-		resp.Diagnostics.AddError(
-			"Error Parsing Response",
-			fmt.Sprintf("Could not parse created policy response: %s", err),
-		)
-		return
-	}
-
-	if created.ID == "" {
-		created.ID = response.ID
-	}
-
-	// Update settings for the policy
-	// Endpoint security settings are managed through categories
-	err = r.updatePolicySettings(ctx, created.ID, settings)
-	if err != nil {
+	// Write the settings of the policy
+	if err := r.updatePolicySettings(ctx, created.ID, settings); err != nil {
 		// Clean up the created policy since settings failed
 		_ = r.client.Delete(ctx, fmt.Sprintf("/deviceManagement/intents/%s", created.ID))
 		resp.Diagnostics.AddError(
@@ -356,76 +337,122 @@ func (r *EndpointSecurityPolicyResource) Create(ctx context.Context, req resourc
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
-// updatePolicySettings updates the settings for an endpoint security policy
-func (r *EndpointSecurityPolicyResource) updatePolicySettings(ctx context.Context, policyId string, settings map[string]interface{}) error {
-	// Get the policy categories
-	categoriesPath := fmt.Sprintf("/deviceManagement/intents/%s/categories", policyId)
-	categoriesResp, err := r.client.Get(ctx, categoriesPath)
-	if err != nil {
-		return fmt.Errorf("failed to get policy categories: %w", err)
+// ModifyPlan resolves template_type to the newest matching template. Policies that already
+// exist keep their template as long as template_type is unchanged, so that Microsoft
+// publishing a newer template version does not force a replacement.
+func (r *EndpointSecurityPolicyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
 	}
 
-	var categories []struct {
-		ID          string `json:"id"`
-		DisplayName string `json:"displayName"`
+	var templateId, templateType types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("template_id"), &templateId)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("template_type"), &templateType)...)
+	if resp.Diagnostics.HasError() || !templateId.IsNull() {
+		return
 	}
-	if categoriesResp.Value != nil {
-		if err := json.Unmarshal(categoriesResp.Value, &categories); err != nil {
-			return fmt.Errorf("failed to parse categories: %w", err)
+
+	if templateType.IsNull() {
+		if req.State.Raw.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("template_type"),
+				"Missing Endpoint Security Template",
+				"Either template_id or template_type must be specified.",
+			)
 		}
+		return
+	}
+	if templateType.IsUnknown() {
+		return
 	}
 
-	// For each category, update settings
-	for _, category := range categories {
-		// Get settings for this category
-		settingsPath := fmt.Sprintf("/deviceManagement/intents/%s/categories/%s/settings", policyId, category.ID)
-		settingsResp, err := r.client.Get(ctx, settingsPath)
-		if err != nil {
-			continue
+	if !req.State.Raw.IsNull() {
+		var stateTemplateType types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("template_type"), &stateTemplateType)...)
+		if resp.Diagnostics.HasError() || stateTemplateType.Equal(templateType) {
+			return
 		}
+	}
 
-		var categorySettings []struct {
-			ID           string `json:"id"`
-			DefinitionId string `json:"definitionId"`
-		}
-		if settingsResp.Value != nil {
-			if err := json.Unmarshal(settingsResp.Value, &categorySettings); err != nil {
-				continue
-			}
-		}
+	if r.client == nil {
+		return
+	}
 
-		// Update each setting if we have a value for it
-		for _, setting := range categorySettings {
-			// Extract setting name from definition ID
-			// Definition IDs are typically like "deviceConfiguration--windows10EndpointProtectionConfiguration_settingName"
-			// We need to match this with our settings map
-			for key, value := range settings {
-				// Simple matching - in production, you'd want more sophisticated matching
-				if containsSettingName(setting.DefinitionId, key) {
-					updatePath := fmt.Sprintf("/deviceManagement/intents/%s/categories/%s/settings/%s", policyId, category.ID, setting.ID)
-					updateBody := map[string]interface{}{
-						"value": value,
-					}
-					_, err = r.client.Patch(ctx, updatePath, updateBody)
-					if err != nil {
-						tflog.Warn(ctx, "Failed to update setting", map[string]interface{}{
-							"setting": key,
-							"error":   err.Error(),
-						})
-					}
-					break
-				}
-			}
-		}
+	resolved, err := r.resolveTemplateID(ctx, templateType.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("template_type"),
+			"Error Resolving Endpoint Security Template",
+			err.Error(),
+		)
+		return
 	}
 
-	return nil
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("template_id"), resolved)...)
 }
 
-// containsSettingName checks if a definition ID contains a setting name
-func containsSettingName(definitionId, settingName string) bool {
-	// Simple substring match - could be more sophisticated
-	return len(definitionId) > 0 && len(settingName) > 0
+// resolveTemplateID returns the ID of the newest Windows template of the given type
+func (r *EndpointSecurityPolicyResource) resolveTemplateID(ctx context.Context, templateType string) (string, error) {
+	templates, err := r.client.ListEndpointSecurityTemplates(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not list templates: %w", err)
+	}
+
+	query := endpointSecurityTemplateQuery{Subtype: templateType, Platform: "windows10AndLater"}
+	matches := findEndpointSecurityTemplates(templates, query)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no endpoint security template matches %s", query)
+	}
+
+	tflog.Debug(ctx, "Resolved endpoint security template", map[string]interface{}{
+		"templateType": templateType,
+		"templateId":   matches[0].ID,
+		"version":      matches[0].VersionInfo,
+	})
+
+	return matches[0].ID, nil
+}
+
+// intentSettings maps settings_json to setting instances of the template. The template is only
+// read when there are settings to map.
+func (r *EndpointSecurityPolicyResource) intentSettings(ctx context.Context, templateId, settingsJSON string, diags *diag.Diagnostics) []clients.IntentSetting {
+	settings, err := DecodeSettingsJSON(settingsJSON)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("settings_json"),
+			"Invalid Settings JSON",
+			fmt.Sprintf("Could not parse settings_json: %s", err),
+		)
+		return nil
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+
+	templateSettings, err := r.client.GetEndpointSecurityTemplateSettings(ctx, templateId)
+	if err != nil {
+		diags.AddError(
+			"Error Reading Endpoint Security Template",
+			fmt.Sprintf("Could not read the settings of template %s: %s", templateId, err),
+		)
+		return nil
+	}
+
+	return BuildIntentSettings(settings, templateSettings, diags)
+}
+
+// updatePolicySettings writes setting instances to an endpoint security policy
+func (r *EndpointSecurityPolicyResource) updatePolicySettings(ctx context.Context, policyId string, settings []clients.IntentSetting) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	tflog.Debug(ctx, "Updating Endpoint Security policy settings", map[string]interface{}{
+		"id":    policyId,
+		"count": len(settings),
+	})
+
+	return r.client.UpdateEndpointSecurityPolicySettings(ctx, policyId, settings)
 }
 
 // Read refreshes the Terraform state with the latest data
@@ -441,12 +468,12 @@ func (r *EndpointSecurityPolicyResource) Read(ctx context.Context, req resource.
 		"id": data.ID.ValueString(),
 	})
 
-	// Get the policy
+	// Get the policy, together with its assignments if the state had assignments configured
 	path := fmt.Sprintf("/deviceManagement/intents/%s", data.ID.ValueString())
-	response, err := r.client.Get(ctx, path)
+	result, err := readPolicyWithAssignments[clients.EndpointSecurityPolicy](ctx, r.client, PolicyTypeEndpointSecurity, path, data.ID.ValueString(), len(data.Assignment) > 0)
 	if err != nil {
 		// Check if policy was deleted
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			resp.State.RemoveResource(ctx)
 			return
 		}
@@ -457,49 +484,45 @@ func (r *EndpointSecurityPolicyResource) Read(ctx context.Context, req resource.
 		return
 	}
 
-	// Parse the response
-	var policy struct {
-		ID                   string   `json:"id"`
-		DisplayName          string   `json:"displayName"`
-		Description          string   `json:"description"`
-		TemplateId           string   `json:"templateId"`
-		RoleScopeTagIds      []string `json:"roleScopeTagIds"`
-		CreatedDateTime      string   `json:"createdDateTime"`
-		LastModifiedDateTime string   `json:"lastModifiedDateTime"`
-	}
-	respBytes, _ := json.Marshal(response)
-	if err := json.Unmarshal(respBytes, &policy); err != nil {
-		resp.Diagnostics.AddError(
-			"Error Parsing Response",
-			fmt.Sprintf("Could not parse policy response: %s", err),
-		)
-		return
-	}
-
 	// Update the model
+	policy := result.Policy
 	data.Type = types.StringValue(PolicyTypeEndpointSecurity)
 	data.DisplayName = types.StringValue(policy.DisplayName)
-	data.Description = types.StringValue(policy.Description)
+	data.Description = optionalStringValue(data.Description, policy.Description)
 	data.TemplateId = types.StringValue(policy.TemplateId)
 	data.CreatedDateTime = types.StringValue(policy.CreatedDateTime)
 	data.LastModifiedDateTime = types.StringValue(policy.LastModifiedDateTime)
 
 	// Handle role scope tag IDs
-	if len(policy.RoleScopeTagIds) > 0 {
-		tagIds, diags := types.ListValueFrom(ctx, types.StringType, policy.RoleScopeTagIds)
-		resp.Diagnostics.Append(diags...)
-		data.RoleScopeTagIds = tagIds
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, policy.RoleScopeTagIds, &resp.Diagnostics)
+
+	// Rebuild settings_json from the intent, so that changes made outside of Terraform show up
+	intentSettings, err := r.client.GetEndpointSecurityPolicySettings(ctx, data.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Endpoint Security Policy",
+			fmt.Sprintf("Could not read settings of policy ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+	settingsJSON, err := IntentSettingsJSON(data.Settings.ValueString(), intentSettings)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Endpoint Security Policy",
+			fmt.Sprintf("Could not encode settings of policy ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
 	}
+	data.Settings = types.StringValue(settingsJSON)
 
-	// Read assignments if the state had assignments configured
+	// Update assignments if the state had assignments configured
 	if len(data.Assignment) > 0 {
-		assignments, err := ReadPolicyAssignments(ctx, r.client, PolicyTypeEndpointSecurity, data.ID.ValueString())
-		if err != nil {
+		if result.AssignmentsErr != nil {
 			tflog.Warn(ctx, "Failed to read policy assignments", map[string]interface{}{
-				"error": err.Error(),
+				"error": result.AssignmentsErr.Error(),
 			})
 		} else {
-			data.Assignment = assignments
+			data.Assignment = PreserveAssignments(data.Assignment, result.Assignments)
 		}
 	}
 
@@ -519,13 +542,8 @@ func (r *EndpointSecurityPolicyResource) Update(ctx context.Context, req resourc
 		"id": data.ID.ValueString(),
 	})
 
-	// Validate settings JSON
-	var settings map[string]interface{}
-	if err := json.Unmarshal([]byte(data.Settings.ValueString()), &settings); err != nil {
-		resp.Diagnostics.AddError(
-			"Invalid Settings JSON",
-			fmt.Sprintf("Could not parse settings_json: %s", err),
-		)
+	settings := r.intentSettings(ctx, data.TemplateId.ValueString(), data.Settings.ValueString(), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
@@ -557,8 +575,7 @@ func (r *EndpointSecurityPolicyResource) Update(ctx context.Context, req resourc
 	}
 
 	// Update settings
-	err = r.updatePolicySettings(ctx, data.ID.ValueString(), settings)
-	if err != nil {
+	if err := r.updatePolicySettings(ctx, data.ID.ValueString(), settings); err != nil {
 		resp.Diagnostics.AddError(
 			"Error Updating Policy Settings",
 			fmt.Sprintf("Could not update policy settings: %s", err),
@@ -609,7 +626,7 @@ func (r *EndpointSecurityPolicyResource) Delete(ctx context.Context, req resourc
 	err := r.client.Delete(ctx, path)
 	if err != nil {
 		// Ignore not found errors during delete
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			return
 		}
 		resp.Diagnostics.AddError(
@@ -620,6 +637,11 @@ func (r *EndpointSecurityPolicyResource) Delete(ctx context.Context, req resourc
 	}
 }
 
+// UpgradeState upgrades the assignment blocks of prior schema versions
+func (r *EndpointSecurityPolicyResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return assignmentStateUpgraders()
+}
+
 // ImportState imports the resource state
 func (r *EndpointSecurityPolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
@@ -0,0 +1,198 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+func TestAccEndpointSecurityPolicyResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Firewall",
+		"template_id": "4356d05c-a4ab-4a07-9ece-739f7c792910",
+		"settings_json": "{}",
+		"assignment": [{"target": [{"type": "all_devices"}]}]
+	}`
+	res := env.apply("intune_endpoint_security_policy", nil, config)
+	assertAttr(t, res.attrs(), "type", PolicyTypeEndpointSecurity)
+	assertAttr(t, env.graph.Object(fakegraph.Intents, res.id()), "templateId", "4356d05c-a4ab-4a07-9ece-739f7c792910")
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	imported := env.importState("intune_endpoint_security_policy", res.id())
+	assertAttr(t, imported.attrs(), "display_name", "Firewall")
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.Intents, res.id()) != nil {
+		t.Errorf("policy %s still exists after destroy", res.id())
+	}
+}
+
+// endpointSecurityTemplate returns a Graph endpoint security template
+func endpointSecurityTemplate(id, subtype, platform, version, published string, deprecated bool) map[string]interface{} {
+	return map[string]interface{}{
+		"@odata.type":       "#microsoft.graph.securityBaselineTemplate",
+		"id":                id,
+		"displayName":       subtype + " " + version,
+		"versionInfo":       version,
+		"isDeprecated":      deprecated,
+		"templateType":      "securityTemplate",
+		"templateSubtype":   subtype,
+		"platformType":      platform,
+		"publishedDateTime": published,
+	}
+}
+
+// addEndpointSecurityTemplates registers firewall and endpoint detection and response templates
+func addEndpointSecurityTemplates(env *testEnv) {
+	for _, template := range []map[string]interface{}{
+		endpointSecurityTemplate("fw-1", "firewall", "windows10AndLater", "1", "2020-05-01T00:00:00Z", false),
+		endpointSecurityTemplate("fw-2", "firewall", "windows10AndLater", "2", "2022-03-01T00:00:00Z", false),
+		endpointSecurityTemplate("fw-3", "firewall", "windows10AndLater", "3", "2023-09-01T00:00:00Z", true),
+		endpointSecurityTemplate("fw-mac", "firewall", "macOS", "1", "2024-01-01T00:00:00Z", false),
+		endpointSecurityTemplate("edr-1", "endpointDetectionReponse", "windows10AndLater", "1", "2021-01-01T00:00:00Z", false),
+	} {
+		env.graph.AddTemplate(template)
+	}
+	env.graph.AddTemplate(map[string]interface{}{
+		"id":              "baseline",
+		"displayName":     "Security Baseline for Windows 10",
+		"templateType":    "securityBaseline",
+		"templateSubtype": "none",
+		"platformType":    "windows10AndLater",
+	})
+}
+
+func TestAccEndpointSecurityPolicyResource_templateType(t *testing.T) {
+	env := newTestEnv(t)
+	addEndpointSecurityTemplates(env)
+
+	config := `{"display_name": "Firewall", "template_type": "firewall", "settings_json": "{}"}`
+	res := env.apply("intune_endpoint_security_policy", nil, config)
+	assertAttr(t, res.attrs(), "template_id", "fw-2")
+	assertAttr(t, env.graph.Object(fakegraph.Intents, res.id()), "templateId", "fw-2")
+
+	// A newer template does not replace existing policies
+	env.graph.AddTemplate(endpointSecurityTemplate("fw-4", "firewall", "windows10AndLater", "4", "2025-02-01T00:00:00Z", false))
+	env.assertNoOp(env.refresh(res), config)
+
+	edr := env.apply("intune_endpoint_security_policy", nil, `{
+		"display_name": "EDR",
+		"template_type": "endpointDetectionAndResponse",
+		"settings_json": "{}"
+	}`)
+	assertAttr(t, edr.attrs(), "template_id", "edr-1")
+
+	msg := env.applyExpectError("intune_endpoint_security_policy", nil, `{"display_name": "ASR", "template_type": "attackSurfaceReduction", "settings_json": "{}"}`)
+	if !strings.Contains(msg, "Error Resolving Endpoint Security Template") {
+		t.Errorf("expected a template resolution error, got: %s", msg)
+	}
+
+	msg = env.applyExpectError("intune_endpoint_security_policy", nil, `{"display_name": "None", "settings_json": "{}"}`)
+	if !strings.Contains(msg, "Missing Endpoint Security Template") {
+		t.Errorf("expected a missing template error, got: %s", msg)
+	}
+}
+
+// firewallSetting returns a setting instance of the Windows 10 endpoint protection configuration
+func firewallSetting(name, odataType, valueJson string) map[string]interface{} {
+	return map[string]interface{}{
+		"@odata.type":  odataType,
+		"definitionId": "deviceConfiguration--windows10EndpointProtectionConfiguration_" + name,
+		"valueJson":    valueJson,
+	}
+}
+
+func TestAccEndpointSecurityPolicyResource_settings(t *testing.T) {
+	env := newTestEnv(t)
+
+	template := endpointSecurityTemplate("fw", "firewall", "windows10AndLater", "1", "2023-01-01T00:00:00Z", false)
+	template["settings"] = []interface{}{
+		firewallSetting("firewallBlockStatefulFTP", odataIntentBooleanSetting, "null"),
+		firewallSetting("firewallIdleTimeoutForSecurityAssociationInSeconds", odataIntentIntegerSetting, "null"),
+		firewallSetting("firewallPreSharedKeyEncodingMethod", odataIntentStringSetting, `"deviceDefault"`),
+		firewallSetting("firewallProfileDomain", odataIntentComplexSetting, "null"),
+		firewallSetting("firewallRules", odataIntentCollectionSetting, "[]"),
+		map[string]interface{}{
+			"@odata.type":  odataIntentBooleanSetting,
+			"definitionId": "deviceConfiguration--windows10GeneralConfiguration_firewallBlockStatefulFTP",
+			"valueJson":    "null",
+		},
+	}
+	env.graph.AddTemplate(template)
+
+	config := func(settings string) string {
+		return fmt.Sprintf(`{"display_name": "Firewall", "template_id": "fw", "settings_json": %q}`, settings)
+	}
+	settings := `{"deviceConfiguration--windows10EndpointProtectionConfiguration_firewallBlockStatefulFTP":true,` +
+		`"firewallIdleTimeoutForSecurityAssociationInSeconds":300,"firewallPreSharedKeyEncodingMethod":"utF8",` +
+		`"firewallProfileDomain":{"firewallEnabled":"allowed"},"firewallRules":[{"displayName":"Block SMB"}]}`
+	res := env.apply("intune_endpoint_security_policy", nil, config(settings))
+
+	written := func(name string) map[string]interface{} {
+		for _, setting := range env.graph.Settings(fakegraph.Intents, res.id()) {
+			if setting := setting.(map[string]interface{}); setting["definitionId"] == firewallSetting(name, "", "")["definitionId"] {
+				return setting
+			}
+		}
+		return nil
+	}
+	assertAttr(t, written("firewallBlockStatefulFTP"), "valueJson", "true")
+	assertAttr(t, written("firewallBlockStatefulFTP"), "@odata.type", odataIntentBooleanSetting)
+	assertAttr(t, written("firewallIdleTimeoutForSecurityAssociationInSeconds"), "valueJson", "300")
+	assertAttr(t, written("firewallPreSharedKeyEncodingMethod"), "valueJson", `"utF8"`)
+	assertAttr(t, written("firewallProfileDomain"), "valueJson", `{"firewallEnabled":"allowed"}`)
+	assertAttr(t, written("firewallRules"), "valueJson", `[{"displayName":"Block SMB"}]`)
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config(settings))
+
+	// A change made in Intune shows up in settings_json
+	if err := env.client().UpdateEndpointSecurityPolicySettings(env.ctx, res.id(), []clients.IntentSetting{{
+		ODataType:    odataIntentIntegerSetting,
+		DefinitionId: "deviceConfiguration--windows10EndpointProtectionConfiguration_firewallIdleTimeoutForSecurityAssociationInSeconds",
+		ValueJson:    "600",
+	}}); err != nil {
+		t.Fatalf("changing a setting: %s", err)
+	}
+	drifted := env.refresh(res)
+	if got := drifted.attrs()["settings_json"].(string); !strings.Contains(got, `"firewallIdleTimeoutForSecurityAssociationInSeconds":600`) {
+		t.Errorf("expected the changed timeout in settings_json, got %s", got)
+	}
+	res = env.apply("intune_endpoint_security_policy", drifted, config(settings))
+	assertAttr(t, written("firewallIdleTimeoutForSecurityAssociationInSeconds"), "valueJson", "300")
+
+	// Importing reads every setting, keyed by definition ID
+	imported := env.importState("intune_endpoint_security_policy", res.id())
+	if got := imported.attrs()["settings_json"].(string); !strings.Contains(got, `"deviceConfiguration--windows10EndpointProtectionConfiguration_firewallRules":[{"displayName":"Block SMB"}]`) {
+		t.Errorf("expected all settings after import, got %s", got)
+	}
+
+	for name, tc := range map[string]struct {
+		settings string
+		want     string
+	}{
+		"unknown":        {`{"firewallDoesNotExist": true}`, "Unknown Endpoint Security Setting"},
+		"ambiguous":      {`{"firewallBlockStatefulFTP": true}`, "Ambiguous Endpoint Security Setting"},
+		"quoted integer": {`{"firewallIdleTimeoutForSecurityAssociationInSeconds": "300"}`, "expected an integer, got a string"},
+		"fraction":       {`{"firewallIdleTimeoutForSecurityAssociationInSeconds": 1.5}`, "expected an integer, got a number"},
+		"collection":     {`{"firewallRules": {"displayName": "Block SMB"}}`, "expected a list, got an object"},
+		"invalid json":   {`{"firewallRules": `, "Invalid Settings JSON"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			msg := env.applyExpectError("intune_endpoint_security_policy", nil, config(tc.settings))
+			if !strings.Contains(msg, tc.want) {
+				t.Errorf("expected an error containing %q, got: %s", tc.want, msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,530 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &EnrollmentStatusPageResource{}
+var _ resource.ResourceWithImportState = &EnrollmentStatusPageResource{}
+
+// NewEnrollmentStatusPageResource returns a new Enrollment Status Page resource
+func NewEnrollmentStatusPageResource() resource.Resource {
+	return &EnrollmentStatusPageResource{}
+}
+
+// EnrollmentStatusPageResource defines the resource implementation
+type EnrollmentStatusPageResource struct {
+	client *clients.GraphClient
+}
+
+// EnrollmentStatusPageResourceModel describes the resource data model
+type EnrollmentStatusPageResourceModel struct {
+	ID                          types.String      `tfsdk:"id"`
+	Type                        types.String      `tfsdk:"type"`
+	DisplayName                 types.String      `tfsdk:"display_name"`
+	Description                 types.String      `tfsdk:"description"`
+	Priority                    types.Int64       `tfsdk:"priority"`
+	ShowInstallationProgress    types.Bool        `tfsdk:"show_installation_progress"`
+	InstallProgressTimeout      types.Int64       `tfsdk:"install_progress_timeout_minutes"`
+	CustomErrorMessage          types.String      `tfsdk:"custom_error_message"`
+	BlockingAppIds              types.Set         `tfsdk:"blocking_app_ids"`
+	AllowLogCollectionOnFailure types.Bool        `tfsdk:"allow_log_collection_on_failure"`
+	AllowDeviceResetOnFailure   types.Bool        `tfsdk:"allow_device_reset_on_failure"`
+	AllowDeviceUseOnFailure     types.Bool        `tfsdk:"allow_device_use_on_failure"`
+	BlockDeviceSetupRetryByUser types.Bool        `tfsdk:"block_device_setup_retry_by_user"`
+	TrackAutopilotOnly          types.Bool        `tfsdk:"track_autopilot_only"`
+	RoleScopeTagIds             types.List        `tfsdk:"role_scope_tag_ids"`
+	CreatedDateTime             types.String      `tfsdk:"created_date_time"`
+	LastModifiedDateTime        types.String      `tfsdk:"last_modified_date_time"`
+	Assignment                  []AssignmentModel `tfsdk:"assignment"`
+}
+
+// Metadata returns the resource type name
+func (r *EnrollmentStatusPageResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_enrollment_status_page"
+}
+
+// Schema defines the schema for the resource
+func (r *EnrollmentStatusPageResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages an Intune Enrollment Status Page.",
+		MarkdownDescription: `
+Manages an Intune Enrollment Status Page.
+
+The Enrollment Status Page shows the installation progress of apps and policies while a
+device enrolls, and keeps the device from being used until the ` + "`blocking_app_ids`" + ` are
+installed, or all required apps when none are listed. The settings other than
+` + "`show_installation_progress`" + ` only apply while the progress is shown.
+
+A device gets the Enrollment Status Page with the lowest ` + "`priority`" + ` number among those
+assigned to it. New pages get the lowest priority; setting ` + "`priority`" + ` moves the page
+and shifts the priorities of the pages in between, which shows up as drift on pages that
+configure their own priority. Give every page that sets a priority a distinct one.
+
+## Example Usage
+
+` + "```hcl" + `
+resource "intune_enrollment_status_page" "autopilot" {
+  display_name                     = "Autopilot devices"
+  priority                         = 1
+  install_progress_timeout_minutes = 90
+  custom_error_message             = "Setup failed, please contact the service desk."
+  allow_log_collection_on_failure  = true
+  track_autopilot_only             = true
+
+  blocking_app_ids = [
+    var.company_portal_app_id,
+  ]
+
+  assignment {
+    target {
+      type     = "group"
+      group_id = azuread_group.autopilot_devices.object_id
+    }
+  }
+}
+` + "```" + `
+
+## Import
+
+Enrollment Status Pages can be imported using the configuration ID:
+
+` + "```shell" + `
+terraform import intune_enrollment_status_page.example 00000000-0000-0000-0000-000000000000_Windows10EnrollmentCompletionPageConfiguration
+` + "```" + `
+`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for the Enrollment Status Page.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Description: "The policy type for use with policy assignments. Always 'enrollment_status_page' for this resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"display_name": schema.StringAttribute{
+				Description: "The display name of the Enrollment Status Page.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the Enrollment Status Page.",
+				Optional:    true,
+			},
+			"priority": schema.Int64Attribute{
+				Description: "The priority of the Enrollment Status Page, 1 being the highest. Defaults to the lowest priority " +
+					"when the page is created.",
+				Optional: true,
+				Computed: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"show_installation_progress": schema.BoolAttribute{
+				Description: "Show the installation progress of apps and policies during enrollment. Defaults to true.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"install_progress_timeout_minutes": schema.Int64Attribute{
+				Description: "The minutes after which an error is shown when the installation has not finished, between 1 " +
+					"and 1440. Defaults to 60.",
+				Optional: true,
+				Computed: true,
+				Default:  int64default.StaticInt64(60),
+				Validators: []validator.Int64{
+					int64validator.Between(1, 1440),
+				},
+			},
+			"custom_error_message": schema.StringAttribute{
+				Description: "The message shown when the installation fails or times out.",
+				Optional:    true,
+			},
+			"blocking_app_ids": schema.SetAttribute{
+				Description: "The IDs of the apps that must be installed before the device can be used. All required apps " +
+					"block the device when unset.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"allow_log_collection_on_failure": schema.BoolAttribute{
+				Description: "Let users collect logs when the installation fails. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"allow_device_reset_on_failure": schema.BoolAttribute{
+				Description: "Let users reset the device when the installation fails. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"allow_device_use_on_failure": schema.BoolAttribute{
+				Description: "Let users continue to use the device when the installation fails. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"block_device_setup_retry_by_user": schema.BoolAttribute{
+				Description: "Keep users from retrying the installation when it fails. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"track_autopilot_only": schema.BoolAttribute{
+				Description: "Only show the Enrollment Status Page to devices provisioned by Windows Autopilot. Defaults to false.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"role_scope_tag_ids": schema.ListAttribute{
+				Description: "List of scope tag IDs for this Enrollment Status Page.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"created_date_time": schema.StringAttribute{
+				Description: "The date and time the Enrollment Status Page was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_modified_date_time": schema.StringAttribute{
+				Description: "The date and time the Enrollment Status Page was last modified.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"assignment": AssignmentBlockSchema(),
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *EnrollmentStatusPageResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+}
+
+// buildConfiguration converts the model into an Enrollment Status Page. The priority is set
+// separately, see setPriority.
+func (r *EnrollmentStatusPageResource) buildConfiguration(ctx context.Context, data *EnrollmentStatusPageResourceModel, diags *diag.Diagnostics) *clients.EnrollmentCompletionPageConfiguration {
+	config := &clients.EnrollmentCompletionPageConfiguration{
+		ODataType:                            clients.ODataTypeEnrollmentCompletionPage,
+		DisplayName:                          data.DisplayName.ValueString(),
+		Description:                          data.Description.ValueString(),
+		ShowInstallationProgress:             data.ShowInstallationProgress.ValueBool(),
+		InstallProgressTimeoutInMinutes:      int(data.InstallProgressTimeout.ValueInt64()),
+		CustomErrorMessage:                   data.CustomErrorMessage.ValueString(),
+		AllowLogCollectionOnInstallFailure:   data.AllowLogCollectionOnFailure.ValueBool(),
+		AllowDeviceResetOnInstallFailure:     data.AllowDeviceResetOnFailure.ValueBool(),
+		AllowDeviceUseOnInstallFailure:       data.AllowDeviceUseOnFailure.ValueBool(),
+		BlockDeviceSetupRetryByUser:          data.BlockDeviceSetupRetryByUser.ValueBool(),
+		TrackInstallProgressForAutopilotOnly: data.TrackAutopilotOnly.ValueBool(),
+		SelectedMobileAppIds:                 []string{},
+		RoleScopeTagIds:                      []string{DefaultScopeTagID},
+	}
+
+	if !data.BlockingAppIds.IsNull() {
+		diags.Append(data.BlockingAppIds.ElementsAs(ctx, &config.SelectedMobileAppIds, false)...)
+	}
+
+	// Add role scope tag IDs if specified
+	if !data.RoleScopeTagIds.IsNull() {
+		var tagIds []string
+		diags.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		config.RoleScopeTagIds = tagIds
+	}
+
+	return config
+}
+
+// updateModel updates the Terraform model from the API configuration
+func (r *EnrollmentStatusPageResource) updateModel(ctx context.Context, data *EnrollmentStatusPageResourceModel, config *clients.EnrollmentCompletionPageConfiguration, diags *diag.Diagnostics) {
+	data.Type = types.StringValue(PolicyTypeEnrollmentStatusPage)
+	data.DisplayName = types.StringValue(config.DisplayName)
+	data.Description = optionalStringValue(data.Description, config.Description)
+	data.Priority = types.Int64Value(int64(config.Priority))
+	data.ShowInstallationProgress = types.BoolValue(config.ShowInstallationProgress)
+	data.InstallProgressTimeout = types.Int64Value(int64(config.InstallProgressTimeoutInMinutes))
+	data.CustomErrorMessage = optionalStringValue(data.CustomErrorMessage, config.CustomErrorMessage)
+	data.AllowLogCollectionOnFailure = types.BoolValue(config.AllowLogCollectionOnInstallFailure)
+	data.AllowDeviceResetOnFailure = types.BoolValue(config.AllowDeviceResetOnInstallFailure)
+	data.AllowDeviceUseOnFailure = types.BoolValue(config.AllowDeviceUseOnInstallFailure)
+	data.BlockDeviceSetupRetryByUser = types.BoolValue(config.BlockDeviceSetupRetryByUser)
+	data.TrackAutopilotOnly = types.BoolValue(config.TrackInstallProgressForAutopilotOnly)
+	data.CreatedDateTime = types.StringValue(config.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(config.LastModifiedDateTime)
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, config.RoleScopeTagIds, diags)
+
+	// No blocking apps keeps an unset blocking_app_ids unset
+	if len(config.SelectedMobileAppIds) > 0 || !data.BlockingAppIds.IsNull() {
+		appIds, d := types.SetValueFrom(ctx, types.StringType, config.SelectedMobileAppIds)
+		diags.Append(d...)
+		data.BlockingAppIds = appIds
+	}
+}
+
+// setPriority moves the Enrollment Status Page to the configured priority, if any, and returns the
+// configuration as read afterwards. Unconfigured priorities are left to Intune, so pages that do
+// not set one follow the shifts made by other pages.
+func (r *EnrollmentStatusPageResource) setPriority(ctx context.Context, cfg tfsdk.Config, config *clients.EnrollmentCompletionPageConfiguration, diags *diag.Diagnostics) *clients.EnrollmentCompletionPageConfiguration {
+	var priority types.Int64
+	diags.Append(cfg.GetAttribute(ctx, path.Root("priority"), &priority)...)
+	if diags.HasError() || priority.IsNull() || priority.IsUnknown() || int(priority.ValueInt64()) == config.Priority {
+		return config
+	}
+
+	if err := r.client.SetEnrollmentConfigurationPriority(ctx, config.ID, int(priority.ValueInt64())); err != nil {
+		diags.AddError(
+			"Error Setting Enrollment Status Page Priority",
+			fmt.Sprintf("Could not set the priority of ID %s: %s", config.ID, err),
+		)
+		return config
+	}
+
+	updated, err := r.client.GetEnrollmentCompletionPageConfiguration(ctx, config.ID)
+	if err != nil {
+		diags.AddError(
+			"Error Reading Enrollment Status Page",
+			fmt.Sprintf("Could not read ID %s: %s", config.ID, err),
+		)
+		return config
+	}
+	return updated
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *EnrollmentStatusPageResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data EnrollmentStatusPageResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating Enrollment Status Page", map[string]interface{}{
+		"display_name": data.DisplayName.ValueString(),
+	})
+
+	config := r.buildConfiguration(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateEnrollmentCompletionPageConfiguration(ctx, config)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Enrollment Status Page",
+			fmt.Sprintf("Could not create Enrollment Status Page: %s", err),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(created.ID)
+	created = r.setPriority(ctx, req.Config, created, &resp.Diagnostics)
+	r.updateModel(ctx, &data, created, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	// Handle assignments if specified
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeEnrollmentStatusPage, created.ID, assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Assigning Enrollment Status Page",
+				fmt.Sprintf("Enrollment Status Page was created but assignment failed: %s", err),
+			)
+			return
+		}
+	}
+
+	tflog.Debug(ctx, "Created Enrollment Status Page", map[string]interface{}{
+		"id": created.ID,
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data
+func (r *EnrollmentStatusPageResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data EnrollmentStatusPageResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Reading Enrollment Status Page", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	// Get the configuration, together with its assignments if the state had assignments configured
+	configPath := fmt.Sprintf("%s/%s", clients.PathDeviceEnrollmentConfigurations, data.ID.ValueString())
+	result, err := readPolicyWithAssignments[clients.EnrollmentCompletionPageConfiguration](ctx, r.client, PolicyTypeEnrollmentStatusPage, configPath, data.ID.ValueString(), len(data.Assignment) > 0)
+	if err != nil {
+		// Check if the configuration was deleted
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Enrollment Status Page",
+			fmt.Sprintf("Could not read ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, result.Policy, &resp.Diagnostics)
+
+	// Update assignments if the state had assignments configured
+	if len(data.Assignment) > 0 {
+		if result.AssignmentsErr != nil {
+			tflog.Warn(ctx, "Failed to read Enrollment Status Page assignments", map[string]interface{}{
+				"error": result.AssignmentsErr.Error(),
+			})
+		} else {
+			data.Assignment = PreserveAssignments(data.Assignment, result.Assignments)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update updates the resource and sets the updated Terraform state
+func (r *EnrollmentStatusPageResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data EnrollmentStatusPageResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating Enrollment Status Page", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	config := r.buildConfiguration(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateEnrollmentCompletionPageConfiguration(ctx, data.ID.ValueString(), config)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Enrollment Status Page",
+			fmt.Sprintf("Could not update ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	updated = r.setPriority(ctx, req.Config, updated, &resp.Diagnostics)
+	r.updateModel(ctx, &data, updated, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Handle assignments
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeEnrollmentStatusPage, data.ID.ValueString(), assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Enrollment Status Page Assignments",
+				fmt.Sprintf("Could not update assignments: %s", err),
+			)
+			return
+		}
+	} else {
+		// Clear assignments if none specified
+		if err := AssignPolicy(ctx, r.client, PolicyTypeEnrollmentStatusPage, data.ID.ValueString(), []clients.PolicyAssignment{}); err != nil {
+			tflog.Warn(ctx, "Failed to clear Enrollment Status Page assignments", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete deletes the resource and removes the Terraform state
+func (r *EnrollmentStatusPageResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data EnrollmentStatusPageResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting Enrollment Status Page", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	err := r.client.DeleteEnrollmentCompletionPageConfiguration(ctx, data.ID.ValueString())
+	if err != nil {
+		// Ignore not found errors during delete
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Enrollment Status Page",
+			fmt.Sprintf("Could not delete ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the resource state
+func (r *EnrollmentStatusPageResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+func TestAccEnrollmentStatusPageResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	// A page without a priority gets the lowest one
+	defaultConfig := `{
+		"display_name": "All devices",
+		"assignment": [{"target": [{"type": "all_devices"}, {"type": "all_users"}]}]
+	}`
+	page := env.apply("intune_enrollment_status_page", nil, defaultConfig)
+	assertAttr(t, page.attrs(), "type", PolicyTypeEnrollmentStatusPage)
+	assertAttr(t, page.attrs(), "priority", float64(1))
+	assertAttr(t, page.attrs(), "blocking_app_ids", nil)
+
+	config := env.graph.Object(fakegraph.EnrollmentConfigurations, page.id())
+	assertAttr(t, config, "@odata.type", "#microsoft.graph.windows10EnrollmentCompletionPageConfiguration")
+	assertAttr(t, config, "installProgressTimeoutInMinutes", float64(60))
+	assertAttr(t, config, "showInstallationProgress", true)
+	if n := len(env.graph.Assignments(fakegraph.EnrollmentConfigurations, page.id())); n != 2 {
+		t.Errorf("expected 2 assignments in Graph, got %d", n)
+	}
+	page = env.refresh(page)
+	env.assertNoOp(page, defaultConfig)
+
+	// A page with a priority moves ahead of the first one
+	autopilotConfig := `{
+		"display_name": "Autopilot devices",
+		"priority": 1,
+		"install_progress_timeout_minutes": 90,
+		"custom_error_message": "Setup failed, please contact the service desk.",
+		"allow_log_collection_on_failure": true,
+		"track_autopilot_only": true,
+		"blocking_app_ids": ["app-1", "app-2"],
+		"assignment": [{"target": [{"type": "group", "group_id": "00000000-0000-0000-0000-00000000000a"}]}]
+	}`
+	autopilot := env.apply("intune_enrollment_status_page", nil, autopilotConfig)
+	assertAttr(t, autopilot.attrs(), "priority", float64(1))
+	config = env.graph.Object(fakegraph.EnrollmentConfigurations, autopilot.id())
+	assertAttr(t, config, "priority", float64(1))
+	assertAttr(t, config, "selectedMobileAppIds", []interface{}{"app-1", "app-2"})
+	assertAttr(t, config, "trackInstallProgressForAutopilotOnly", true)
+	assertAttr(t, env.graph.Object(fakegraph.EnrollmentConfigurations, page.id()), "priority", float64(2))
+	env.assertNoOp(env.refresh(autopilot), autopilotConfig)
+
+	// The shifted page follows without a diff, as it does not configure its priority
+	page = env.refresh(page)
+	assertAttr(t, page.attrs(), "priority", float64(2))
+	env.assertNoOp(page, defaultConfig)
+
+	setPriority := 0
+	for _, request := range env.graph.Requests() {
+		if strings.HasSuffix(request, "/setPriority") {
+			setPriority++
+		}
+	}
+	if setPriority != 1 {
+		t.Errorf("expected 1 setPriority request, got %d", setPriority)
+	}
+
+	// Moving the page back down shifts the other page up again
+	updated := `{
+		"display_name": "Autopilot devices",
+		"priority": 2,
+		"show_installation_progress": true,
+		"install_progress_timeout_minutes": 120
+	}`
+	autopilot = env.apply("intune_enrollment_status_page", autopilot, updated)
+	assertAttr(t, autopilot.attrs(), "priority", float64(2))
+	config = env.graph.Object(fakegraph.EnrollmentConfigurations, autopilot.id())
+	assertAttr(t, config, "selectedMobileAppIds", []interface{}{})
+	assertAttr(t, config, "customErrorMessage", "")
+	assertAttr(t, env.graph.Object(fakegraph.EnrollmentConfigurations, page.id()), "priority", float64(1))
+	if n := len(env.graph.Assignments(fakegraph.EnrollmentConfigurations, autopilot.id())); n != 0 {
+		t.Errorf("expected assignments to be removed, got %d", n)
+	}
+	env.assertNoOp(env.refresh(autopilot), updated)
+
+	imported := env.importState("intune_enrollment_status_page", autopilot.id())
+	assertAttr(t, imported.attrs(), "install_progress_timeout_minutes", float64(120))
+	env.assertNoOp(imported, updated)
+
+	// The page can be assigned through the generic assignment resource
+	group := env.apply("intune_policy_group_assignment", nil, fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "enrollment_status_page",
+		"target_type": "group",
+		"group_id": "00000000-0000-0000-0000-00000000000b"
+	}`, autopilot.id()))
+	if n := len(env.graph.Assignments(fakegraph.EnrollmentConfigurations, autopilot.id())); n != 1 {
+		t.Errorf("expected 1 assignment in Graph, got %d", n)
+	}
+	env.destroy(group)
+
+	env.destroy(autopilot)
+	env.destroy(page)
+	if env.graph.Object(fakegraph.EnrollmentConfigurations, page.id()) != nil {
+		t.Errorf("page %s still exists after destroy", page.id())
+	}
+}
+
+func TestAccEnrollmentStatusPageResource_invalid(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		config string
+		want   string
+	}{
+		{`{"display_name": "x", "priority": 0}`, "priority"},
+		{`{"display_name": "x", "install_progress_timeout_minutes": 0}`, "install_progress_timeout_minutes"},
+		{`{"display_name": "x", "install_progress_timeout_minutes": 1441}`, "install_progress_timeout_minutes"},
+	} {
+		if msg := env.applyExpectError("intune_enrollment_status_page", nil, tc.config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.config, tc.want, msg)
+		}
+	}
+}
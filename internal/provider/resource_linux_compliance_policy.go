@@ -0,0 +1,508 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &LinuxCompliancePolicyResource{}
+var _ resource.ResourceWithImportState = &LinuxCompliancePolicyResource{}
+var _ resource.ResourceWithValidateConfig = &LinuxCompliancePolicyResource{}
+var _ resource.ResourceWithModifyPlan = &LinuxCompliancePolicyResource{}
+
+// Linux compliance policies are Settings Catalog compliance policies for these platforms and technologies
+const (
+	linuxCompliancePlatforms    = "linux"
+	linuxComplianceTechnologies = "linuxMdm"
+)
+
+// linuxComplianceSettingPrefix is the prefix of the definition IDs of Linux compliance settings
+const linuxComplianceSettingPrefix = "linux_"
+
+// NewLinuxCompliancePolicyResource creates a new resource instance
+func NewLinuxCompliancePolicyResource() resource.Resource {
+	return &LinuxCompliancePolicyResource{}
+}
+
+// LinuxCompliancePolicyResource defines the resource implementation
+type LinuxCompliancePolicyResource struct {
+	client      *clients.GraphClient
+	definitions *SettingDefinitionCache
+}
+
+// LinuxCompliancePolicyResourceModel describes the resource data model
+type LinuxCompliancePolicyResourceModel struct {
+	ID                      types.String      `tfsdk:"id"`
+	Type                    types.String      `tfsdk:"type"`
+	Name                    types.String      `tfsdk:"name"`
+	Description             types.String      `tfsdk:"description"`
+	RoleScopeTagIds         types.List        `tfsdk:"role_scope_tag_ids"`
+	Settings                types.List        `tfsdk:"setting"`
+	Assignment              []AssignmentModel `tfsdk:"assignment"`
+	ScheduledActionsForRule types.List        `tfsdk:"scheduled_actions_for_rule"`
+	CreatedDateTime         types.String      `tfsdk:"created_date_time"`
+	LastModifiedDateTime    types.String      `tfsdk:"last_modified_date_time"`
+}
+
+// Metadata returns the resource type name
+func (r *LinuxCompliancePolicyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_linux_compliance_policy"
+}
+
+// Schema defines the schema for the resource
+func (r *LinuxCompliancePolicyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages an Intune compliance policy for Linux devices.",
+		MarkdownDescription: `
+Manages an Intune compliance policy for Linux devices.
+
+Linux compliance policies are built from Settings Catalog settings instead of the typed properties
+of the other platforms. The settings use the same ` + "`setting`" + ` blocks as
+` + "`intune_settings_catalog_policy_settings`" + ` and must be Linux compliance settings, whose
+definition IDs start with ` + "`linux_`" + `.
+
+## Example Usage
+
+` + "```hcl" + `
+resource "intune_linux_compliance_policy" "baseline" {
+  name = "Linux baseline"
+
+  setting {
+    definition_id = "linux_deviceencryption_required"
+    value_type    = "choice"
+    value         = "linux_deviceencryption_required_true"
+  }
+
+  scheduled_actions_for_rule {
+    scheduled_action_configurations {
+      action_type        = "block"
+      grace_period_hours = 24
+    }
+  }
+
+  assignment {
+    target {
+      type = "all_devices"
+    }
+  }
+}
+` + "```" + `
+`,
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for the policy.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Description: "The policy type for use with policy assignments. Always 'linux_compliance' for this resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The display name of the policy.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the policy.",
+				Optional:    true,
+			},
+			"role_scope_tag_ids": schema.ListAttribute{
+				Description: "List of scope tag IDs for this policy.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"created_date_time": schema.StringAttribute{
+				Description: "The date and time the policy was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_modified_date_time": schema.StringAttribute{
+				Description: "The date and time the policy was last modified.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"setting":                    SettingBlockSchema(1),
+			"assignment":                 AssignmentBlockSchema(),
+			"scheduled_actions_for_rule": ScheduledActionsBlockSchema(),
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *LinuxCompliancePolicyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+	r.definitions = providerData.SettingDefinitions
+}
+
+// ValidateConfig checks the shape and syntax of the settings, that they are Linux compliance
+// settings, and the scheduled actions
+func (r *LinuxCompliancePolicyResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var settingsList, actions types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("setting"), &settingsList)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("scheduled_actions_for_rule"), &actions)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ValidateScheduledActions(ctx, actions, &resp.Diagnostics)
+
+	settings := SettingModelsFromList(settingsList)
+	ValidateSettings(settings, path.Root("setting"), &resp.Diagnostics)
+	for i, setting := range settings {
+		if setting.DefinitionID.IsUnknown() || strings.HasPrefix(setting.DefinitionID.ValueString(), linuxComplianceSettingPrefix) {
+			continue
+		}
+		resp.Diagnostics.AddAttributeError(
+			path.Root("setting").AtListIndex(i).AtName("definition_id"),
+			"Invalid Linux Compliance Setting",
+			fmt.Sprintf("Setting %s is not a Linux compliance setting. Their definition IDs start with %q.", setting.DefinitionID.ValueString(), linuxComplianceSettingPrefix),
+		)
+	}
+}
+
+// ModifyPlan checks the planned settings against their Settings Catalog definitions, and that
+// the referenced notification templates exist
+func (r *LinuxCompliancePolicyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var actions types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("scheduled_actions_for_rule"), &actions)...)
+	ValidateNotificationTemplates(ctx, r.client, actions, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() || r.definitions == nil {
+		return
+	}
+
+	var settingsList types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("setting"), &settingsList)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	settings := SettingModelsFromList(settingsList)
+	ids := SettingDefinitionIDs(settings)
+	if len(ids) == 0 {
+		return
+	}
+
+	definitions, err := r.definitions.Load(ctx, ids)
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Could Not Validate Settings",
+			fmt.Sprintf("Could not read setting definitions from the Settings Catalog, settings will be validated by Intune at apply time: %s", err),
+		)
+		return
+	}
+
+	ValidateSettingDefinitions(settings, definitions, path.Root("setting"), &resp.Diagnostics)
+}
+
+// buildPolicy converts the model into a compliance policy
+func (r *LinuxCompliancePolicyResource) buildPolicy(ctx context.Context, data *LinuxCompliancePolicyResourceModel, diags *diag.Diagnostics) *clients.SettingsCompliancePolicy {
+	policy := &clients.SettingsCompliancePolicy{
+		Name:            data.Name.ValueString(),
+		Description:     data.Description.ValueString(),
+		Platforms:       linuxCompliancePlatforms,
+		Technologies:    linuxComplianceTechnologies,
+		RoleScopeTagIds: []string{"0"},
+		Settings:        BuildSettingInstances(SettingModelsFromList(data.Settings), path.Root("setting"), diags),
+	}
+
+	// Add role scope tag IDs if specified
+	if !data.RoleScopeTagIds.IsNull() {
+		var tagIds []string
+		diags.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		policy.RoleScopeTagIds = tagIds
+	}
+
+	policy.ScheduledActionsForRule = BuildScheduledActions(ctx, data.ScheduledActionsForRule, diags)
+	return policy
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *LinuxCompliancePolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data LinuxCompliancePolicyResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating Linux compliance policy", map[string]interface{}{
+		"name": data.Name.ValueString(),
+	})
+
+	policy := r.buildPolicy(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateSettingsCompliancePolicy(ctx, policy)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Linux Compliance Policy",
+			fmt.Sprintf("Could not create policy: %s", err),
+		)
+		return
+	}
+
+	// Update the model with the created policy data
+	data.ID = types.StringValue(created.ID)
+	data.Type = types.StringValue(PolicyTypeLinuxCompliance)
+	data.CreatedDateTime = types.StringValue(created.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(created.LastModifiedDateTime)
+
+	// Read back the scheduled actions for the IDs Graph assigned to them
+	actions, err := r.client.GetSettingsCompliancePolicyScheduledActions(ctx, created.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Scheduled Actions",
+			fmt.Sprintf("Policy was created but its scheduled actions could not be read: %s", err),
+		)
+		return
+	}
+	data.ScheduledActionsForRule = ScheduledActionsValue(ctx, data.ScheduledActionsForRule, actions, &resp.Diagnostics)
+
+	// Handle assignments if specified
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeLinuxCompliance, created.ID, assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Assigning Policy",
+				fmt.Sprintf("Policy was created but assignment failed: %s", err),
+			)
+			return
+		}
+	}
+
+	tflog.Debug(ctx, "Created Linux compliance policy", map[string]interface{}{
+		"id": created.ID,
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data
+func (r *LinuxCompliancePolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data LinuxCompliancePolicyResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Reading Linux compliance policy", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	// Get the policy with its settings and scheduled actions, together with its assignments if the
+	// state had assignments configured
+	policyPath := fmt.Sprintf("%s('%s')?$expand=settings,scheduledActionsForRule($expand=scheduledActionConfigurations)", clients.PathSettingsCompliancePolicies, data.ID.ValueString())
+	result, err := readPolicyWithAssignments[clients.SettingsCompliancePolicy](ctx, r.client, PolicyTypeLinuxCompliance, policyPath, data.ID.ValueString(), len(data.Assignment) > 0)
+	if err != nil {
+		// Check if policy was deleted
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Linux Compliance Policy",
+			fmt.Sprintf("Could not read policy ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	policy := result.Policy
+	if policy.Platforms != linuxCompliancePlatforms {
+		resp.Diagnostics.AddError(
+			"Unexpected Compliance Policy Platform",
+			fmt.Sprintf("Policy ID %s is a compliance policy for %s, not for Linux.", data.ID.ValueString(), policy.Platforms),
+		)
+		return
+	}
+
+	// Update the model
+	data.Type = types.StringValue(PolicyTypeLinuxCompliance)
+	data.Name = types.StringValue(policy.Name)
+	data.Description = optionalStringValue(data.Description, policy.Description)
+	data.CreatedDateTime = types.StringValue(policy.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(policy.LastModifiedDateTime)
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, policy.RoleScopeTagIds, &resp.Diagnostics)
+	data.ScheduledActionsForRule = ScheduledActionsValue(ctx, data.ScheduledActionsForRule, policy.ScheduledActionsForRule, &resp.Diagnostics)
+
+	// Convert the settings, keeping the configured spelling of equal values
+	settings, unsupported := FlattenSettingInstances(policy.Settings)
+	for _, definitionID := range unsupported {
+		tflog.Warn(ctx, "Unknown setting type", map[string]interface{}{
+			"definition_id": definitionID,
+		})
+	}
+	PreserveSettingValues(SettingModelsFromList(data.Settings), settings)
+	data.Settings = SettingModelsToList(settings, 1, &resp.Diagnostics)
+
+	// Update assignments if the state had assignments configured
+	if len(data.Assignment) > 0 {
+		if result.AssignmentsErr != nil {
+			tflog.Warn(ctx, "Failed to read policy assignments", map[string]interface{}{
+				"error": result.AssignmentsErr.Error(),
+			})
+		} else {
+			data.Assignment = PreserveAssignments(data.Assignment, result.Assignments)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update updates the resource and sets the updated Terraform state
+func (r *LinuxCompliancePolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data LinuxCompliancePolicyResourceModel
+	var stateActions types.List
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("scheduled_actions_for_rule"), &stateActions)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating Linux compliance policy", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	policy := r.buildPolicy(ctx, &data, &resp.Diagnostics)
+	priorActions := BuildScheduledActions(ctx, stateActions, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateSettingsCompliancePolicy(ctx, data.ID.ValueString(), policy)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Linux Compliance Policy",
+			fmt.Sprintf("Could not update policy ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+	data.LastModifiedDateTime = types.StringValue(updated.LastModifiedDateTime)
+
+	// Replace the scheduled actions if they changed, and read them back for their IDs
+	if !reflect.DeepEqual(policy.ScheduledActionsForRule, priorActions) {
+		if err := r.client.SetSettingsCompliancePolicyScheduledActions(ctx, data.ID.ValueString(), policy.ScheduledActionsForRule); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Scheduled Actions",
+				fmt.Sprintf("Could not update scheduled actions of policy ID %s: %s", data.ID.ValueString(), err),
+			)
+			return
+		}
+	}
+	actions, err := r.client.GetSettingsCompliancePolicyScheduledActions(ctx, data.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Scheduled Actions",
+			fmt.Sprintf("Could not read scheduled actions of policy ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+	data.ScheduledActionsForRule = ScheduledActionsValue(ctx, data.ScheduledActionsForRule, actions, &resp.Diagnostics)
+
+	// Handle assignments
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeLinuxCompliance, data.ID.ValueString(), assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Policy Assignments",
+				fmt.Sprintf("Could not update assignments: %s", err),
+			)
+			return
+		}
+	} else {
+		// Clear assignments if none specified
+		if err := AssignPolicy(ctx, r.client, PolicyTypeLinuxCompliance, data.ID.ValueString(), []clients.PolicyAssignment{}); err != nil {
+			tflog.Warn(ctx, "Failed to clear policy assignments", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete deletes the resource and removes the Terraform state
+func (r *LinuxCompliancePolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data LinuxCompliancePolicyResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting Linux compliance policy", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	err := r.client.DeleteSettingsCompliancePolicy(ctx, data.ID.ValueString())
+	if err != nil {
+		// Ignore not found errors during delete
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Linux Compliance Policy",
+			fmt.Sprintf("Could not delete policy ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the resource state
+func (r *LinuxCompliancePolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
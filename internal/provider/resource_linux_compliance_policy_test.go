@@ -0,0 +1,145 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+// addLinuxComplianceDefinitions registers the Settings Catalog definitions of the Linux compliance tests
+func addLinuxComplianceDefinitions(env *testEnv) {
+	env.graph.AddSettingDefinition(choiceDefinition("linux_deviceencryption_required", 2))
+	env.graph.AddSettingDefinition(simpleDefinition(odataTestSimpleDefinition, "linux_passwordpolicy_minimumlength", map[string]interface{}{
+		"@odata.type":  "#microsoft.graph.deviceManagementConfigurationIntegerSettingValueDefinition",
+		"minimumValue": 1,
+		"maximumValue": 64,
+	}))
+}
+
+func TestAccLinuxCompliancePolicyResource(t *testing.T) {
+	env := newTestEnv(t)
+	addLinuxComplianceDefinitions(env)
+
+	config := `{
+		"name": "Linux baseline",
+		"setting": [
+			{"definition_id": "linux_deviceencryption_required", "value_type": "choice", "value": "linux_deviceencryption_required_1"},
+			{"definition_id": "linux_passwordpolicy_minimumlength", "value_type": "integer", "value": "12"}
+		],
+		"scheduled_actions_for_rule": [{"scheduled_action_configurations": [{"action_type": "block", "grace_period_hours": 24}]}],
+		"assignment": [{"target": [{"type": "all_devices"}]}]
+	}`
+	res := env.apply("intune_linux_compliance_policy", nil, config)
+	assertAttr(t, res.attrs(), "type", PolicyTypeLinuxCompliance)
+
+	policy := env.graph.Object(fakegraph.CompliancePolicies, res.id())
+	assertAttr(t, policy, "platforms", "linux")
+	assertAttr(t, policy, "technologies", "linuxMdm")
+	if n := len(env.graph.Settings(fakegraph.CompliancePolicies, res.id())); n != 2 {
+		t.Errorf("expected 2 settings in Graph, got %d", n)
+	}
+	if n := len(env.graph.Assignments(fakegraph.CompliancePolicies, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment in Graph, got %d", n)
+	}
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	updated := `{
+		"name": "Linux baseline",
+		"description": "Requires disk encryption",
+		"setting": [
+			{"definition_id": "linux_deviceencryption_required", "value_type": "choice", "value": "linux_deviceencryption_required_1"}
+		],
+		"scheduled_actions_for_rule": [{"scheduled_action_configurations": [{"action_type": "block", "grace_period_hours": 24}]}]
+	}`
+	res = env.apply("intune_linux_compliance_policy", res, updated)
+	assertAttr(t, env.graph.Object(fakegraph.CompliancePolicies, res.id()), "description", "Requires disk encryption")
+	if n := len(env.graph.Settings(fakegraph.CompliancePolicies, res.id())); n != 1 {
+		t.Errorf("expected 1 setting in Graph after the update, got %d", n)
+	}
+	if n := len(env.graph.Assignments(fakegraph.CompliancePolicies, res.id())); n != 0 {
+		t.Errorf("expected assignments to be removed, got %d", n)
+	}
+	env.assertNoOp(env.refresh(res), updated)
+
+	imported := env.importState("intune_linux_compliance_policy", res.id())
+	assertAttr(t, imported.attrs(), "name", "Linux baseline")
+	if settings, _ := imported.attrs()["setting"].([]interface{}); len(settings) != 1 {
+		t.Errorf("expected 1 imported setting, got %v", imported.attrs()["setting"])
+	}
+
+	// The policy can be assigned through the generic assignment resource
+	assignment := env.apply("intune_policy_assignment", nil, fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "linux_compliance",
+		"target": [{"type": "all_users"}]
+	}`, res.id()))
+	if n := len(env.graph.Assignments(fakegraph.CompliancePolicies, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment in Graph, got %d", n)
+	}
+	env.destroy(assignment)
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.CompliancePolicies, res.id()) != nil {
+		t.Errorf("policy %s still exists after destroy", res.id())
+	}
+}
+
+func TestAccLinuxCompliancePolicyResource_invalid(t *testing.T) {
+	env := newTestEnv(t)
+	addLinuxComplianceDefinitions(env)
+	addSettingDefinitions(env)
+
+	for _, tc := range []struct {
+		setting string
+		want    string
+	}{
+		{`{"definition_id": "device_vendor_msft_bitlocker_requiredeviceencryption", "value_type": "choice", "value": "device_vendor_msft_bitlocker_requiredeviceencryption_1"}`,
+			"Invalid Linux Compliance Setting"},
+		{`{"definition_id": "linux_passwordpolicy_minimumlength", "value_type": "integer", "value": "twelve"}`, "Invalid Integer Value"},
+		{`{"definition_id": "linux_passwordpolicy_minimumlength", "value_type": "integer", "value": "100"}`, "64"},
+		{`{"definition_id": "linux_deviceencryption_required", "value_type": "choice", "value": "linux_deviceencryption_required_5"}`, "linux_deviceencryption_required_5"},
+	} {
+		config := `{"name": "Invalid", "setting": [` + tc.setting + `]}`
+		if msg := env.applyExpectError("intune_linux_compliance_policy", nil, config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.setting, tc.want, msg)
+		}
+	}
+}
+
+func TestAccLinuxCompliancePolicyResource_scheduledActions(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"name": "Linux baseline",
+		"scheduled_actions_for_rule": [{"scheduled_action_configurations": [
+			{"action_type": "block", "grace_period_hours": 24},
+			{"action_type": "retire", "grace_period_hours": 720}
+		]}]
+	}`
+	res := env.apply("intune_linux_compliance_policy", nil, config)
+	if ids := scheduledActionIDs(t, res); len(ids) != 2 {
+		t.Fatalf("expected 2 scheduled actions in the state, got %d", len(ids))
+	}
+	env.assertNoOp(env.refresh(res), config)
+
+	updated := `{
+		"name": "Linux baseline",
+		"scheduled_actions_for_rule": [{"scheduled_action_configurations": [
+			{"action_type": "block", "grace_period_hours": 48}
+		]}]
+	}`
+	res = env.apply("intune_linux_compliance_policy", res, updated)
+	configs := env.graph.ScheduledActions(fakegraph.CompliancePolicies, res.id())[0].(map[string]interface{})["scheduledActionConfigurations"].([]interface{})
+	if len(configs) != 1 {
+		t.Fatalf("expected 1 scheduled action in Graph, got %d", len(configs))
+	}
+	assertAttr(t, configs[0].(map[string]interface{}), "gracePeriodHours", 48)
+	env.assertNoOp(env.refresh(res), updated)
+}
@@ -0,0 +1,561 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &NotificationMessageTemplateResource{}
+var _ resource.ResourceWithImportState = &NotificationMessageTemplateResource{}
+var _ resource.ResourceWithValidateConfig = &NotificationMessageTemplateResource{}
+
+// brandingOptionNone is the branding option Graph reports when no branding is included
+const brandingOptionNone = "none"
+
+// NewNotificationMessageTemplateResource returns a new notification message template resource
+func NewNotificationMessageTemplateResource() resource.Resource {
+	return &NotificationMessageTemplateResource{}
+}
+
+// NotificationMessageTemplateResource defines the resource implementation
+type NotificationMessageTemplateResource struct {
+	client *clients.GraphClient
+}
+
+// NotificationMessageTemplateResourceModel describes the resource data model
+type NotificationMessageTemplateResourceModel struct {
+	ID                   types.String            `tfsdk:"id"`
+	DisplayName          types.String            `tfsdk:"display_name"`
+	Description          types.String            `tfsdk:"description"`
+	BrandingOptions      types.List              `tfsdk:"branding_options"`
+	DefaultLocale        types.String            `tfsdk:"default_locale"`
+	RoleScopeTagIds      types.List              `tfsdk:"role_scope_tag_ids"`
+	LastModifiedDateTime types.String            `tfsdk:"last_modified_date_time"`
+	LocalizedMessage     []LocalizedMessageModel `tfsdk:"localized_message"`
+}
+
+// LocalizedMessageModel represents a localized_message block
+type LocalizedMessageModel struct {
+	Locale    types.String `tfsdk:"locale"`
+	Subject   types.String `tfsdk:"subject"`
+	Body      types.String `tfsdk:"body"`
+	IsDefault types.Bool   `tfsdk:"is_default"`
+}
+
+// Metadata returns the resource type name
+func (r *NotificationMessageTemplateResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_notification_message_template"
+}
+
+// Schema defines the schema for the resource
+func (r *NotificationMessageTemplateResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages an Intune notification message template for compliance policy notifications.",
+		MarkdownDescription: `
+Manages an Intune notification message template.
+
+Notification message templates hold the email sent by the ` + "`notification`" + ` action of a compliance
+policy, with one ` + "`localized_message`" + ` block per language. Exactly one message is the default, used
+for users whose language has no message.
+
+## Example Usage
+
+` + "```hcl" + `
+resource "intune_notification_message_template" "noncompliant" {
+  display_name     = "Device not compliant"
+  branding_options = ["includeCompanyLogo", "includeContactInformation"]
+
+  localized_message {
+    locale     = "en-us"
+    subject    = "Your device is not compliant"
+    body       = "Update your device to keep access to company resources."
+    is_default = true
+  }
+
+  localized_message {
+    locale  = "de-de"
+    subject = "Ihr Gerät ist nicht konform"
+    body    = "Aktualisieren Sie Ihr Gerät, um den Zugriff auf Unternehmensressourcen zu behalten."
+  }
+}
+` + "```" + `
+
+## Import
+
+Notification message templates can be imported using the template ID:
+
+` + "```shell" + `
+terraform import intune_notification_message_template.example 00000000-0000-0000-0000-000000000000
+` + "```" + `
+`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for the template.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"display_name": schema.StringAttribute{
+				Description: "The display name of the template.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the template.",
+				Optional:    true,
+			},
+			"branding_options": schema.ListAttribute{
+				Description: "Branding included in the message. Valid values: includeCompanyLogo, includeCompanyName, " +
+					"includeContactInformation, includeCompanyPortalLink, includeDeviceDetails.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+					listvalidator.ValueStringsAre(stringvalidator.OneOf(
+						"includeCompanyLogo",
+						"includeCompanyName",
+						"includeContactInformation",
+						"includeCompanyPortalLink",
+						"includeDeviceDetails",
+					)),
+				},
+			},
+			"default_locale": schema.StringAttribute{
+				Description: "The locale of the default message.",
+				Computed:    true,
+			},
+			"role_scope_tag_ids": schema.ListAttribute{
+				Description: "List of role scope tag IDs for this template.",
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"last_modified_date_time": schema.StringAttribute{
+				Description: "The date and time the template was last modified.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"localized_message": schema.ListNestedBlock{
+				Description: "A message of the template in one locale.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"locale": schema.StringAttribute{
+							Description: "The locale of the message, for example en-us.",
+							Required:    true,
+						},
+						"subject": schema.StringAttribute{
+							Description: "The subject of the message.",
+							Required:    true,
+						},
+						"body": schema.StringAttribute{
+							Description: "The body of the message.",
+							Required:    true,
+						},
+						"is_default": schema.BoolAttribute{
+							Description: "Whether this is the default message, used for locales without a message.",
+							Optional:    true,
+							Computed:    true,
+							Default:     booldefault.StaticBool(false),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *NotificationMessageTemplateResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+}
+
+// ValidateConfig checks that locales are unique and that exactly one message is the default
+func (r *NotificationMessageTemplateResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data NotificationMessageTemplateResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	locales := make(map[string]bool, len(data.LocalizedMessage))
+	defaults := 0
+	for _, message := range data.LocalizedMessage {
+		if message.Locale.IsUnknown() || message.IsDefault.IsUnknown() {
+			return
+		}
+		locale := strings.ToLower(message.Locale.ValueString())
+		if locales[locale] {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("localized_message"),
+				"Duplicate Localized Message",
+				fmt.Sprintf("The locale %s has more than one localized_message block.", message.Locale.ValueString()),
+			)
+		}
+		locales[locale] = true
+		if message.IsDefault.ValueBool() {
+			defaults++
+		}
+	}
+	if len(data.LocalizedMessage) > 0 && defaults != 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("localized_message"),
+			"Invalid Default Localized Message",
+			fmt.Sprintf("Exactly one localized_message block must set is_default, got %d.", defaults),
+		)
+	}
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *NotificationMessageTemplateResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data NotificationMessageTemplateResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	template := r.buildTemplate(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateNotificationMessageTemplate(ctx, template)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Notification Message Template",
+			fmt.Sprintf("Could not create notification message template: %s", err),
+		)
+		return
+	}
+	data.ID = types.StringValue(created.ID)
+
+	// Localized messages are created one by one once the template exists. If one fails, the
+	// template is deleted again so it is not left in Intune without being tracked in state.
+	for _, message := range data.LocalizedMessage {
+		if _, err := r.client.CreateLocalizedNotificationMessage(ctx, created.ID, buildLocalizedMessage(message)); err != nil {
+			detail := fmt.Sprintf("The %s message could not be created: %s", message.Locale.ValueString(), err)
+			if deleteErr := r.client.DeleteNotificationMessageTemplate(ctx, created.ID); deleteErr != nil && !clients.IsNotFound(deleteErr) {
+				detail += fmt.Sprintf("\n\nThe template ID %s could not be deleted again and must be removed manually: %s", created.ID, deleteErr)
+			}
+			resp.Diagnostics.AddError("Error Creating Localized Notification Message", detail)
+			return
+		}
+	}
+
+	tflog.Debug(ctx, "Created notification message template", map[string]interface{}{
+		"id":           created.ID,
+		"display_name": created.DisplayName,
+	})
+
+	r.read(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data
+func (r *NotificationMessageTemplateResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data NotificationMessageTemplateResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	template, err := r.client.GetNotificationMessageTemplate(ctx, data.ID.ValueString())
+	if err != nil {
+		// Check if the resource was deleted outside of Terraform
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Notification Message Template",
+			fmt.Sprintf("Could not read notification message template ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, template, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update updates the resource and sets the updated Terraform state. Localized messages are
+// matched by locale: removed locales are deleted first, then existing ones are updated and new
+// ones are created.
+func (r *NotificationMessageTemplateResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data NotificationMessageTemplateResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	template := r.buildTemplate(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := data.ID.ValueString()
+	if err := r.client.UpdateNotificationMessageTemplate(ctx, id, template); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Notification Message Template",
+			fmt.Sprintf("Could not update notification message template ID %s: %s", id, err),
+		)
+		return
+	}
+
+	current, err := r.client.GetNotificationMessageTemplate(ctx, id)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Notification Message Template",
+			fmt.Sprintf("Could not read notification message template ID %s: %s", id, err),
+		)
+		return
+	}
+
+	existing := make(map[string]clients.LocalizedNotificationMessage, len(current.LocalizedNotificationMessages))
+	for _, message := range current.LocalizedNotificationMessages {
+		existing[strings.ToLower(message.Locale)] = message
+	}
+	planned := make(map[string]bool, len(data.LocalizedMessage))
+	for _, message := range data.LocalizedMessage {
+		planned[strings.ToLower(message.Locale.ValueString())] = true
+	}
+
+	for locale, message := range existing {
+		if planned[locale] {
+			continue
+		}
+		if err := r.client.DeleteLocalizedNotificationMessage(ctx, id, message.ID); err != nil && !clients.IsNotFound(err) {
+			resp.Diagnostics.AddError(
+				"Error Deleting Localized Notification Message",
+				fmt.Sprintf("Could not delete the %s message: %s", message.Locale, err),
+			)
+			return
+		}
+	}
+	for _, message := range data.LocalizedMessage {
+		localized := buildLocalizedMessage(message)
+		if prior, ok := existing[strings.ToLower(localized.Locale)]; ok {
+			localized.ID = prior.ID
+			err = r.client.UpdateLocalizedNotificationMessage(ctx, id, localized)
+		} else {
+			_, err = r.client.CreateLocalizedNotificationMessage(ctx, id, localized)
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Localized Notification Message",
+				fmt.Sprintf("Could not update the %s message: %s", localized.Locale, err),
+			)
+			return
+		}
+	}
+
+	r.read(ctx, &data, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete deletes the resource and removes the Terraform state
+func (r *NotificationMessageTemplateResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data NotificationMessageTemplateResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteNotificationMessageTemplate(ctx, data.ID.ValueString())
+	if err != nil {
+		// Ignore "not found" errors as the resource is already deleted
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Notification Message Template",
+			fmt.Sprintf("Could not delete notification message template ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the resource state
+func (r *NotificationMessageTemplateResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// read reads the template back after a write
+func (r *NotificationMessageTemplateResource) read(ctx context.Context, data *NotificationMessageTemplateResourceModel, diags *diag.Diagnostics) {
+	template, err := r.client.GetNotificationMessageTemplate(ctx, data.ID.ValueString())
+	if err != nil {
+		diags.AddError(
+			"Error Reading Notification Message Template",
+			fmt.Sprintf("Could not read notification message template ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+	r.updateModel(ctx, data, template, diags)
+}
+
+// buildTemplate builds the API template from the Terraform model, without its localized messages
+func (r *NotificationMessageTemplateResource) buildTemplate(ctx context.Context, data *NotificationMessageTemplateResourceModel, diags *diag.Diagnostics) *clients.NotificationMessageTemplate {
+	template := &clients.NotificationMessageTemplate{
+		DisplayName:     data.DisplayName.ValueString(),
+		Description:     data.Description.ValueString(),
+		BrandingOptions: brandingOptionNone,
+	}
+
+	var options []string
+	diags.Append(data.BrandingOptions.ElementsAs(ctx, &options, false)...)
+	if len(options) > 0 {
+		template.BrandingOptions = strings.Join(options, ",")
+	}
+
+	for _, message := range data.LocalizedMessage {
+		if message.IsDefault.ValueBool() {
+			template.DefaultLocale = message.Locale.ValueString()
+		}
+	}
+
+	if !data.RoleScopeTagIds.IsNull() && !data.RoleScopeTagIds.IsUnknown() {
+		var tagIds []string
+		diags.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		template.RoleScopeTagIds = tagIds
+	} else {
+		template.RoleScopeTagIds = []string{"0"}
+	}
+
+	return template
+}
+
+// buildLocalizedMessage converts a localized_message block into an API message
+func buildLocalizedMessage(message LocalizedMessageModel) *clients.LocalizedNotificationMessage {
+	return &clients.LocalizedNotificationMessage{
+		Locale:          message.Locale.ValueString(),
+		Subject:         message.Subject.ValueString(),
+		MessageTemplate: message.Body.ValueString(),
+		IsDefault:       message.IsDefault.ValueBool(),
+	}
+}
+
+// updateModel updates the Terraform model from the API template. Messages keep the order and
+// locale spelling of the prior blocks; messages for other locales follow, sorted by locale.
+func (r *NotificationMessageTemplateResource) updateModel(ctx context.Context, data *NotificationMessageTemplateResourceModel, template *clients.NotificationMessageTemplate, diags *diag.Diagnostics) {
+	data.DisplayName = types.StringValue(template.DisplayName)
+	data.Description = optionalStringValue(data.Description, template.Description)
+	data.DefaultLocale = types.StringValue(template.DefaultLocale)
+	data.LastModifiedDateTime = types.StringValue(template.LastModifiedDateTime)
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, template.RoleScopeTagIds, diags)
+
+	var options []string
+	for _, option := range strings.Split(template.BrandingOptions, ",") {
+		if option = strings.TrimSpace(option); option != "" && option != brandingOptionNone {
+			options = append(options, option)
+		}
+	}
+	if len(options) == 0 && len(data.BrandingOptions.Elements()) == 0 {
+		data.BrandingOptions = types.ListNull(types.StringType)
+	} else {
+		data.BrandingOptions = preserveStringOrder(ctx, data.BrandingOptions, options, diags)
+	}
+
+	messages := make(map[string]clients.LocalizedNotificationMessage, len(template.LocalizedNotificationMessages))
+	for _, message := range template.LocalizedNotificationMessages {
+		messages[strings.ToLower(message.Locale)] = message
+	}
+
+	result := make([]LocalizedMessageModel, 0, len(messages))
+	for _, prior := range data.LocalizedMessage {
+		key := strings.ToLower(prior.Locale.ValueString())
+		message, ok := messages[key]
+		if !ok {
+			continue
+		}
+		delete(messages, key)
+		result = append(result, localizedMessageModel(prior.Locale, message))
+	}
+	remaining := make([]string, 0, len(messages))
+	for key := range messages {
+		remaining = append(remaining, key)
+	}
+	sort.Strings(remaining)
+	for _, key := range remaining {
+		result = append(result, localizedMessageModel(types.StringValue(messages[key].Locale), messages[key]))
+	}
+	if len(result) == 0 && data.LocalizedMessage == nil {
+		result = nil
+	}
+	data.LocalizedMessage = result
+}
+
+// localizedMessageModel converts an API message into a localized_message block
+func localizedMessageModel(locale types.String, message clients.LocalizedNotificationMessage) LocalizedMessageModel {
+	return LocalizedMessageModel{
+		Locale:    locale,
+		Subject:   types.StringValue(message.Subject),
+		Body:      types.StringValue(message.MessageTemplate),
+		IsDefault: types.BoolValue(message.IsDefault),
+	}
+}
+
+// preserveStringOrder returns values as a list, in the order of the prior list when it holds the
+// same values
+func preserveStringOrder(ctx context.Context, prior types.List, values []string, diags *diag.Diagnostics) types.List {
+	var priorValues []string
+	if len(prior.Elements()) > 0 {
+		diags.Append(prior.ElementsAs(ctx, &priorValues, false)...)
+	}
+	if len(priorValues) == len(values) {
+		seen := make(map[string]bool, len(values))
+		for _, v := range values {
+			seen[v] = true
+		}
+		same := true
+		for _, v := range priorValues {
+			same = same && seen[v]
+		}
+		if same {
+			return prior
+		}
+	}
+
+	list, d := types.ListValueFrom(ctx, types.StringType, values)
+	diags.Append(d...)
+	return list
+}
@@ -0,0 +1,152 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+func TestAccNotificationMessageTemplateResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Device not compliant",
+		"branding_options": ["includeCompanyLogo", "includeContactInformation"],
+		"localized_message": [
+			{"locale": "en-us", "subject": "Your device is not compliant", "body": "Update your device.", "is_default": true},
+			{"locale": "de-de", "subject": "Ihr Gerät ist nicht konform", "body": "Aktualisieren Sie Ihr Gerät."}
+		]
+	}`
+	res := env.apply("intune_notification_message_template", nil, config)
+	assertAttr(t, res.attrs(), "default_locale", "en-us")
+
+	template := env.graph.Object(fakegraph.NotificationTemplates, res.id())
+	assertAttr(t, template, "brandingOptions", "includeCompanyLogo,includeContactInformation")
+	if n := len(env.graph.LocalizedMessages(res.id())); n != 2 {
+		t.Errorf("expected 2 localized messages in Graph, got %d", n)
+	}
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	// Messages are updated in place, added and removed by locale
+	updated := `{
+		"display_name": "Device not compliant",
+		"description": "Sent after one day",
+		"localized_message": [
+			{"locale": "en-us", "subject": "Action required", "body": "Update your device.", "is_default": true},
+			{"locale": "fr-fr", "subject": "Appareil non conforme", "body": "Mettez à jour votre appareil."}
+		]
+	}`
+	res = env.apply("intune_notification_message_template", res, updated)
+	assertAttr(t, env.graph.Object(fakegraph.NotificationTemplates, res.id()), "brandingOptions", "none")
+	messages := env.graph.LocalizedMessages(res.id())
+	locales := make(map[string]string)
+	for _, m := range messages {
+		message := m.(map[string]interface{})
+		locales[message["locale"].(string)] = message["subject"].(string)
+	}
+	if len(locales) != 2 || locales["en-us"] != "Action required" || locales["fr-fr"] == "" {
+		t.Errorf("unexpected localized messages in Graph: %v", messages)
+	}
+	env.assertNoOp(env.refresh(res), updated)
+
+	imported := env.importState("intune_notification_message_template", res.id())
+	env.assertNoOp(imported, updated)
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.NotificationTemplates, res.id()) != nil {
+		t.Errorf("template %s still exists after destroy", res.id())
+	}
+}
+
+func TestAccNotificationMessageTemplateResource_messageError(t *testing.T) {
+	env := newTestEnv(t)
+
+	// Graph rejects the second message, after the template and the first message were created
+	msg := env.applyExpectError("intune_notification_message_template", nil, `{
+		"display_name": "Device not compliant",
+		"localized_message": [
+			{"locale": "en-us", "subject": "Your device is not compliant", "body": "Update your device.", "is_default": true},
+			{"locale": "de-de", "subject": "Ihr Gerät ist nicht konform", "body": ""}
+		]
+	}`)
+	if !strings.Contains(msg, "Error Creating Localized Notification Message") {
+		t.Errorf("expected a localized message error, got: %s", msg)
+	}
+
+	// The template is deleted again instead of being left untracked
+	var templates []string
+	for _, request := range env.graph.Requests() {
+		if strings.HasPrefix(request, "POST ") && strings.HasSuffix(request, "/notificationMessageTemplates") {
+			templates = append(templates, request)
+		}
+	}
+	if len(templates) != 1 {
+		t.Fatalf("expected 1 template to be created, got %v", templates)
+	}
+	remaining, err := env.client().ListAll(env.ctx, "/deviceManagement/notificationMessageTemplates")
+	if err != nil {
+		t.Fatalf("ListAll: %s", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("expected the template to be deleted, got %d templates", len(remaining))
+	}
+}
+
+func TestAccNotificationMessageTemplateResource_invalid(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		messages string
+		want     string
+	}{
+		{`{"locale": "en-us", "subject": "s", "body": "b"}`, "Exactly one localized_message block must set is_default"},
+		{`{"locale": "en-us", "subject": "s", "body": "b", "is_default": true}, {"locale": "EN-US", "subject": "s", "body": "b"}`, "Duplicate Localized Message"},
+	} {
+		config := `{"display_name": "x", "localized_message": [` + tc.messages + `]}`
+		if msg := env.applyExpectError("intune_notification_message_template", nil, config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.messages, tc.want, msg)
+		}
+	}
+
+	config := `{"display_name": "x", "branding_options": ["includeLogo"], "localized_message": [{"locale": "en-us", "subject": "s", "body": "b", "is_default": true}]}`
+	if msg := env.applyExpectError("intune_notification_message_template", nil, config); !strings.Contains(msg, "branding_options") {
+		t.Errorf("expected an invalid branding option to fail, got: %s", msg)
+	}
+}
+
+func TestAccCompliancePolicyResource_notificationTemplate(t *testing.T) {
+	env := newTestEnv(t)
+
+	template := env.apply("intune_notification_message_template", nil, `{
+		"display_name": "Device not compliant",
+		"localized_message": [{"locale": "en-us", "subject": "s", "body": "b", "is_default": true}]
+	}`)
+
+	actions := `[{"scheduled_action_configurations": [
+		{"action_type": "block", "grace_period_hours": 24},
+		{"action_type": "pushNotification", "notification_template_id": %q}
+	]}]`
+	res := env.apply("intune_compliance_policy", nil, fmt.Sprintf(`{
+		"display_name": "Windows baseline",
+		"scheduled_actions_for_rule": `+actions+`
+	}`, template.id()))
+	env.destroy(res)
+
+	for _, resourceType := range []string{"intune_compliance_policy", "intune_linux_compliance_policy"} {
+		name := "display_name"
+		if resourceType == "intune_linux_compliance_policy" {
+			name = "name"
+		}
+		config := fmt.Sprintf(`{%q: "Baseline", "scheduled_actions_for_rule": `+actions+`}`, name, "00000000-0000-0000-0000-000000000099")
+		if msg := env.applyExpectError(resourceType, nil, config); !strings.Contains(msg, "Invalid Notification Template") {
+			t.Errorf("%s: expected a missing template to fail, got: %s", resourceType, msg)
+		}
+	}
+}
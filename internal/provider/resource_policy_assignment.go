@@ -5,12 +5,10 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
-	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -26,6 +24,7 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces
 var _ resource.Resource = &PolicyAssignmentResource{}
 var _ resource.ResourceWithImportState = &PolicyAssignmentResource{}
+var _ resource.ResourceWithUpgradeState = &PolicyAssignmentResource{}
 
 // NewPolicyAssignmentResource creates a new resource instance
 func NewPolicyAssignmentResource() resource.Resource {
@@ -39,23 +38,24 @@ type PolicyAssignmentResource struct {
 
 // PolicyAssignmentResourceModel describes the resource data model
 type PolicyAssignmentResourceModel struct {
-	ID            types.String `tfsdk:"id"`
-	PolicyID      types.String `tfsdk:"policy_id"`
-	PolicyType    types.String `tfsdk:"policy_type"`
-	IncludeGroups types.List   `tfsdk:"include_groups"`
-	ExcludeGroups types.List   `tfsdk:"exclude_groups"`
-	AllDevices    types.Bool   `tfsdk:"all_devices"`
-	AllUsers      types.Bool   `tfsdk:"all_users"`
-	FilterID      types.String `tfsdk:"filter_id"`
-	FilterType    types.String `tfsdk:"filter_type"`
+	ID         types.String            `tfsdk:"id"`
+	PolicyID   types.String            `tfsdk:"policy_id"`
+	PolicyType types.String            `tfsdk:"policy_type"`
+	Targets    []AssignmentTargetModel `tfsdk:"target"`
 }
 
 // PolicyType constants
 const (
-	PolicyTypeSettingsCatalog  = "settings_catalog"
-	PolicyTypeCompliance       = "compliance"
-	PolicyTypeEndpointSecurity = "endpoint_security"
-	PolicyTypeDeviceConfig     = "device_configuration"
+	PolicyTypeSettingsCatalog      = "settings_catalog"
+	PolicyTypeCompliance           = "compliance"
+	PolicyTypeEndpointSecurity     = "endpoint_security"
+	PolicyTypeDeviceConfig         = "device_configuration"
+	PolicyTypeLinuxCompliance      = "linux_compliance"
+	PolicyTypeFeatureUpdate        = "feature_update"
+	PolicyTypeQualityUpdate        = "quality_update"
+	PolicyTypeDriverUpdate         = "driver_update"
+	PolicyTypeAutopilot            = "autopilot"
+	PolicyTypeEnrollmentStatusPage = "enrollment_status_page"
 )
 
 // Metadata returns the resource type name
@@ -66,12 +66,14 @@ func (r *PolicyAssignmentResource) Metadata(ctx context.Context, req resource.Me
 // Schema defines the schema for the resource
 func (r *PolicyAssignmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version:     1,
 		Description: "Assigns an Intune policy to Azure AD groups.",
 		MarkdownDescription: `
 Assigns an Intune policy to Azure AD groups.
 
 This resource manages the assignment of any Intune policy type to groups, supporting
-both include and exclude group assignments, as well as assignment filters.
+both include and exclude group assignments, as well as assignment filters. Each ` + "`target`" + `
+block is one assignment target with its own filter.
 
 ## Example Usage
 
@@ -82,14 +84,20 @@ resource "intune_policy_assignment" "example" {
   policy_id   = intune_settings_catalog_policy.example.id
   policy_type = intune_settings_catalog_policy.example.type
 
-  include_groups = [
-    data.azuread_group.all_devices.id,
-    data.azuread_group.it_department.id,
-  ]
+  target {
+    type     = "group"
+    group_id = data.azuread_group.all_devices.id
+  }
 
-  exclude_groups = [
-    data.azuread_group.test_devices.id,
-  ]
+  target {
+    type     = "group"
+    group_id = data.azuread_group.it_department.id
+  }
+
+  target {
+    type     = "exclusion"
+    group_id = data.azuread_group.test_devices.id
+  }
 }
 ` + "```" + `
 
@@ -99,21 +107,37 @@ resource "intune_policy_assignment" "example" {
 resource "intune_policy_assignment" "all_devices" {
   policy_id   = intune_compliance_policy.windows.id
   policy_type = intune_compliance_policy.windows.type
-  all_devices = true
+
+  target {
+    type = "all_devices"
+  }
 }
 ` + "```" + `
 
-### With Assignment Filter
+### With Assignment Filters
 
 ` + "```hcl" + `
 resource "intune_policy_assignment" "filtered" {
   policy_id   = intune_settings_catalog_policy.example.id
   policy_type = intune_settings_catalog_policy.example.type
 
-  include_groups = [data.azuread_group.all_devices.id]
-
-  filter_id   = "00000000-0000-0000-0000-000000000000"
-  filter_type = "include"
+  target {
+    type        = "group"
+    group_id    = data.azuread_group.pilot.id
+    filter_id   = intune_assignment_filter.corporate.id
+    filter_type = "include"
+  }
+
+  target {
+    type        = "group"
+    group_id    = data.azuread_group.broad.id
+    filter_id   = intune_assignment_filter.kiosk.id
+    filter_type = "exclude"
+  }
+
+  target {
+    type = "all_users"
+  }
 }
 ` + "```" + `
 
@@ -130,6 +154,12 @@ The ` + "`policy_type`" + ` can be referenced directly from the policy resource'
 | compliance | Device compliance policies |
 | endpoint_security | Endpoint security policies |
 | device_configuration | Device configuration profiles |
+| linux_compliance | Linux compliance policies |
+| feature_update | Windows feature update profiles |
+| quality_update | Windows quality update profiles |
+| driver_update | Windows driver update profiles |
+| autopilot | Windows Autopilot deployment profiles |
+| enrollment_status_page | Enrollment Status Page configurations |
 `,
 
 		Attributes: map[string]schema.Attribute{
@@ -148,7 +178,7 @@ The ` + "`policy_type`" + ` can be referenced directly from the policy resource'
 				},
 			},
 			"policy_type": schema.StringAttribute{
-				Description: "The type of policy. Valid values: settings_catalog, compliance, endpoint_security, device_configuration.",
+				Description: "The type of policy. Valid values: settings_catalog, compliance, endpoint_security, device_configuration, linux_compliance, feature_update, quality_update, driver_update, autopilot, enrollment_status_page.",
 				Required:    true,
 				Validators: []validator.String{
 					stringvalidator.OneOf(
@@ -156,41 +186,21 @@ The ` + "`policy_type`" + ` can be referenced directly from the policy resource'
 						PolicyTypeCompliance,
 						PolicyTypeEndpointSecurity,
 						PolicyTypeDeviceConfig,
+						PolicyTypeLinuxCompliance,
+						PolicyTypeFeatureUpdate,
+						PolicyTypeQualityUpdate,
+						PolicyTypeDriverUpdate,
+						PolicyTypeAutopilot,
+						PolicyTypeEnrollmentStatusPage,
 					),
 				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
-			"include_groups": schema.ListAttribute{
-				Description: "List of Azure AD group IDs to include in the assignment.",
-				Optional:    true,
-				ElementType: types.StringType,
-			},
-			"exclude_groups": schema.ListAttribute{
-				Description: "List of Azure AD group IDs to exclude from the assignment.",
-				Optional:    true,
-				ElementType: types.StringType,
-			},
-			"all_devices": schema.BoolAttribute{
-				Description: "Assign to all devices. Cannot be used with include_groups.",
-				Optional:    true,
-			},
-			"all_users": schema.BoolAttribute{
-				Description: "Assign to all users. Cannot be used with include_groups.",
-				Optional:    true,
-			},
-			"filter_id": schema.StringAttribute{
-				Description: "The ID of the assignment filter to apply.",
-				Optional:    true,
-			},
-			"filter_type": schema.StringAttribute{
-				Description: "The type of filter. Valid values: include, exclude.",
-				Optional:    true,
-				Validators: []validator.String{
-					stringvalidator.OneOf("include", "exclude"),
-				},
-			},
+		},
+		Blocks: map[string]schema.Block{
+			"target": AssignmentTargetBlockSchema(),
 		},
 	}
 }
@@ -224,6 +234,19 @@ func (r *PolicyAssignmentResource) getAssignmentPath(policyType, policyId string
 		return fmt.Sprintf("/deviceManagement/intents/%s/assign", policyId)
 	case PolicyTypeDeviceConfig:
 		return fmt.Sprintf("/deviceManagement/deviceConfigurations/%s/assign", policyId)
+	case PolicyTypeLinuxCompliance:
+		return fmt.Sprintf("/deviceManagement/compliancePolicies('%s')/assign", policyId)
+	case PolicyTypeFeatureUpdate:
+		return fmt.Sprintf("/deviceManagement/windowsFeatureUpdateProfiles/%s/assign", policyId)
+	case PolicyTypeQualityUpdate:
+		return fmt.Sprintf("/deviceManagement/windowsQualityUpdateProfiles/%s/assign", policyId)
+	case PolicyTypeDriverUpdate:
+		return fmt.Sprintf("/deviceManagement/windowsDriverUpdateProfiles/%s/assign", policyId)
+	case PolicyTypeAutopilot:
+		// Autopilot profiles have no assign action, see postAssignments
+		return fmt.Sprintf("/deviceManagement/windowsAutopilotDeploymentProfiles/%s/assignments", policyId)
+	case PolicyTypeEnrollmentStatusPage:
+		return fmt.Sprintf("/deviceManagement/deviceEnrollmentConfigurations/%s/assign", policyId)
 	default:
 		return ""
 	}
@@ -240,108 +263,23 @@ func (r *PolicyAssignmentResource) getAssignmentsPath(policyType, policyId strin
 		return fmt.Sprintf("/deviceManagement/intents/%s/assignments", policyId)
 	case PolicyTypeDeviceConfig:
 		return fmt.Sprintf("/deviceManagement/deviceConfigurations/%s/assignments", policyId)
+	case PolicyTypeLinuxCompliance:
+		return fmt.Sprintf("/deviceManagement/compliancePolicies('%s')/assignments", policyId)
+	case PolicyTypeFeatureUpdate:
+		return fmt.Sprintf("/deviceManagement/windowsFeatureUpdateProfiles/%s/assignments", policyId)
+	case PolicyTypeQualityUpdate:
+		return fmt.Sprintf("/deviceManagement/windowsQualityUpdateProfiles/%s/assignments", policyId)
+	case PolicyTypeDriverUpdate:
+		return fmt.Sprintf("/deviceManagement/windowsDriverUpdateProfiles/%s/assignments", policyId)
+	case PolicyTypeAutopilot:
+		return fmt.Sprintf("/deviceManagement/windowsAutopilotDeploymentProfiles/%s/assignments", policyId)
+	case PolicyTypeEnrollmentStatusPage:
+		return fmt.Sprintf("/deviceManagement/deviceEnrollmentConfigurations/%s/assignments", policyId)
 	default:
 		return ""
 	}
 }
 
-// buildAssignments builds the assignment objects for the API
-func (r *PolicyAssignmentResource) buildAssignments(ctx context.Context, data *PolicyAssignmentResourceModel, diags *diag.Diagnostics) []clients.PolicyAssignment {
-	var assignments []clients.PolicyAssignment
-
-	// Handle include groups
-	if !data.IncludeGroups.IsNull() {
-		var groupIds []string
-		diags.Append(data.IncludeGroups.ElementsAs(ctx, &groupIds, false)...)
-		if diags.HasError() {
-			return nil
-		}
-
-		for _, groupId := range groupIds {
-			target := &clients.AssignmentTarget{
-				ODataType: "#microsoft.graph.groupAssignmentTarget",
-				GroupId:   groupId,
-			}
-
-			// Add filter if specified
-			if !data.FilterID.IsNull() && data.FilterID.ValueString() != "" {
-				target.DeviceAndAppManagementAssignmentFilterId = data.FilterID.ValueString()
-				filterType := "include"
-				if !data.FilterType.IsNull() {
-					filterType = data.FilterType.ValueString()
-				}
-				target.DeviceAndAppManagementAssignmentFilterType = filterType
-			}
-
-			assignments = append(assignments, clients.PolicyAssignment{
-				Target: target,
-			})
-		}
-	}
-
-	// Handle all devices
-	if !data.AllDevices.IsNull() && data.AllDevices.ValueBool() {
-		target := &clients.AssignmentTarget{
-			ODataType: "#microsoft.graph.allDevicesAssignmentTarget",
-		}
-
-		// Add filter if specified
-		if !data.FilterID.IsNull() && data.FilterID.ValueString() != "" {
-			target.DeviceAndAppManagementAssignmentFilterId = data.FilterID.ValueString()
-			filterType := "include"
-			if !data.FilterType.IsNull() {
-				filterType = data.FilterType.ValueString()
-			}
-			target.DeviceAndAppManagementAssignmentFilterType = filterType
-		}
-
-		assignments = append(assignments, clients.PolicyAssignment{
-			Target: target,
-		})
-	}
-
-	// Handle all users
-	if !data.AllUsers.IsNull() && data.AllUsers.ValueBool() {
-		target := &clients.AssignmentTarget{
-			ODataType: "#microsoft.graph.allLicensedUsersAssignmentTarget",
-		}
-
-		// Add filter if specified
-		if !data.FilterID.IsNull() && data.FilterID.ValueString() != "" {
-			target.DeviceAndAppManagementAssignmentFilterId = data.FilterID.ValueString()
-			filterType := "include"
-			if !data.FilterType.IsNull() {
-				filterType = data.FilterType.ValueString()
-			}
-			target.DeviceAndAppManagementAssignmentFilterType = filterType
-		}
-
-		assignments = append(assignments, clients.PolicyAssignment{
-			Target: target,
-		})
-	}
-
-	// Handle exclude groups
-	if !data.ExcludeGroups.IsNull() {
-		var groupIds []string
-		diags.Append(data.ExcludeGroups.ElementsAs(ctx, &groupIds, false)...)
-		if diags.HasError() {
-			return nil
-		}
-
-		for _, groupId := range groupIds {
-			assignments = append(assignments, clients.PolicyAssignment{
-				Target: &clients.AssignmentTarget{
-					ODataType: "#microsoft.graph.exclusionGroupAssignmentTarget",
-					GroupId:   groupId,
-				},
-			})
-		}
-	}
-
-	return assignments
-}
-
 // Create creates the resource and sets the initial Terraform state
 func (r *PolicyAssignmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data PolicyAssignmentResourceModel
@@ -360,10 +298,7 @@ func (r *PolicyAssignmentResource) Create(ctx context.Context, req resource.Crea
 	})
 
 	// Build assignments
-	assignments := r.buildAssignments(ctx, &data, &resp.Diagnostics)
-	if resp.Diagnostics.HasError() {
-		return
-	}
+	assignments := BuildAssignmentTargets(data.Targets)
 
 	// Create the assignments
 	assignPath := r.getAssignmentPath(policyType, policyId)
@@ -375,11 +310,7 @@ func (r *PolicyAssignmentResource) Create(ctx context.Context, req resource.Crea
 		return
 	}
 
-	body := map[string]interface{}{
-		"assignments": assignments,
-	}
-
-	_, err := r.client.Post(ctx, assignPath, body)
+	err := postAssignments(ctx, r.client, policyType, assignPath, assignments)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Creating Policy Assignment",
@@ -425,10 +356,10 @@ func (r *PolicyAssignmentResource) Read(ctx context.Context, req resource.ReadRe
 		return
 	}
 
-	response, err := r.client.Get(ctx, assignmentsPath)
+	apiAssignments, err := clients.ListInto[clients.PolicyAssignment](ctx, r.client, assignmentsPath)
 	if err != nil {
 		// Check if policy was deleted
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			resp.State.RemoveResource(ctx)
 			return
 		}
@@ -439,68 +370,8 @@ func (r *PolicyAssignmentResource) Read(ctx context.Context, req resource.ReadRe
 		return
 	}
 
-	// Parse assignments
-	var assignments []struct {
-		ID     string `json:"id"`
-		Target struct {
-			ODataType string `json:"@odata.type"`
-			GroupId   string `json:"groupId"`
-			FilterId  string `json:"deviceAndAppManagementAssignmentFilterId"`
-			FilterType string `json:"deviceAndAppManagementAssignmentFilterType"`
-		} `json:"target"`
-	}
-
-	if response.Value != nil {
-		if err := json.Unmarshal(response.Value, &assignments); err != nil {
-			resp.Diagnostics.AddError(
-				"Error Parsing Response",
-				fmt.Sprintf("Could not parse assignments: %s", err),
-			)
-			return
-		}
-	}
-
-	// Extract group IDs
-	var includeGroups []string
-	var excludeGroups []string
-	var allDevices, allUsers bool
-
-	for _, assignment := range assignments {
-		switch {
-		case strings.Contains(assignment.Target.ODataType, "groupAssignmentTarget"):
-			includeGroups = append(includeGroups, assignment.Target.GroupId)
-		case strings.Contains(assignment.Target.ODataType, "exclusionGroupAssignmentTarget"):
-			excludeGroups = append(excludeGroups, assignment.Target.GroupId)
-		case strings.Contains(assignment.Target.ODataType, "allDevicesAssignmentTarget"):
-			allDevices = true
-		case strings.Contains(assignment.Target.ODataType, "allLicensedUsersAssignmentTarget"):
-			allUsers = true
-		}
-
-		// Capture filter info from first assignment
-		if assignment.Target.FilterId != "" && data.FilterID.IsNull() {
-			data.FilterID = types.StringValue(assignment.Target.FilterId)
-			data.FilterType = types.StringValue(assignment.Target.FilterType)
-		}
-	}
-
-	// Update model
-	if len(includeGroups) > 0 {
-		groupList, diags := types.ListValueFrom(ctx, types.StringType, includeGroups)
-		resp.Diagnostics.Append(diags...)
-		data.IncludeGroups = groupList
-	}
-	if len(excludeGroups) > 0 {
-		groupList, diags := types.ListValueFrom(ctx, types.StringType, excludeGroups)
-		resp.Diagnostics.Append(diags...)
-		data.ExcludeGroups = groupList
-	}
-	if allDevices {
-		data.AllDevices = types.BoolValue(true)
-	}
-	if allUsers {
-		data.AllUsers = types.BoolValue(true)
-	}
+	// Update model, keeping the order of the configured targets
+	data.Targets = PreserveAssignmentTargets(data.Targets, assignmentTargetModels(apiAssignments))
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -523,10 +394,7 @@ func (r *PolicyAssignmentResource) Update(ctx context.Context, req resource.Upda
 	})
 
 	// Build new assignments
-	assignments := r.buildAssignments(ctx, &data, &resp.Diagnostics)
-	if resp.Diagnostics.HasError() {
-		return
-	}
+	assignments := BuildAssignmentTargets(data.Targets)
 
 	// Update assignments (this replaces all assignments)
 	assignPath := r.getAssignmentPath(policyType, policyId)
@@ -538,11 +406,7 @@ func (r *PolicyAssignmentResource) Update(ctx context.Context, req resource.Upda
 		return
 	}
 
-	body := map[string]interface{}{
-		"assignments": assignments,
-	}
-
-	_, err := r.client.Post(ctx, assignPath, body)
+	err := postAssignments(ctx, r.client, policyType, assignPath, assignments)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Updating Policy Assignment",
@@ -581,14 +445,10 @@ func (r *PolicyAssignmentResource) Delete(ctx context.Context, req resource.Dele
 		return
 	}
 
-	body := map[string]interface{}{
-		"assignments": []interface{}{},
-	}
-
-	_, err := r.client.Post(ctx, assignPath, body)
+	err := postAssignments(ctx, r.client, policyType, assignPath, []interface{}{})
 	if err != nil {
 		// Ignore not found errors during delete
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			return
 		}
 		resp.Diagnostics.AddError(
@@ -599,6 +459,20 @@ func (r *PolicyAssignmentResource) Delete(ctx context.Context, req resource.Dele
 	}
 }
 
+// UpgradeState upgrades states of schema version 0, which listed groups in include_groups and
+// exclude_groups and applied a single filter to all targets
+func (r *PolicyAssignmentResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				upgradeStateJSON(req, resp, func(state map[string]interface{}) {
+					state["target"] = legacyAssignmentTargets(state)
+				})
+			},
+		},
+	}
+}
+
 // ImportState imports the resource state
 func (r *PolicyAssignmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// Import format: policy_type:policy_id
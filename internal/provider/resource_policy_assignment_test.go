@@ -0,0 +1,163 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+func TestAccPolicyAssignmentResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	policy := env.apply("intune_compliance_policy", nil, `{"display_name": "Windows baseline"}`)
+
+	config := fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "compliance",
+		"target": [
+			{"type": "group", "group_id": "00000000-0000-0000-0000-000000000001"},
+			{"type": "exclusion", "group_id": "00000000-0000-0000-0000-000000000002"}
+		]
+	}`, policy.id())
+	res := env.apply("intune_policy_assignment", nil, config)
+	if n := len(env.graph.Assignments(fakegraph.DeviceCompliancePolicies, policy.id())); n != 2 {
+		t.Fatalf("expected 2 assignments in Graph, got %d", n)
+	}
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	updated := fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "compliance",
+		"target": [{"type": "all_devices"}]
+	}`, policy.id())
+	res = env.apply("intune_policy_assignment", res, updated)
+	assignments := env.graph.Assignments(fakegraph.DeviceCompliancePolicies, policy.id())
+	if len(assignments) != 1 {
+		t.Fatalf("expected 1 assignment in Graph, got %d", len(assignments))
+	}
+	env.assertNoOp(env.refresh(res), updated)
+
+	env.destroy(res)
+	if n := len(env.graph.Assignments(fakegraph.DeviceCompliancePolicies, policy.id())); n != 0 {
+		t.Errorf("expected assignments to be removed, got %d", n)
+	}
+}
+
+func TestAccPolicyAssignmentResource_targetFilters(t *testing.T) {
+	env := newTestEnv(t)
+
+	policy := env.apply("intune_compliance_policy", nil, `{"display_name": "Windows baseline"}`)
+
+	config := fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "compliance",
+		"target": [
+			{"type": "group", "group_id": "00000000-0000-0000-0000-00000000000a", "filter_id": "filter-x", "filter_type": "include"},
+			{"type": "group", "group_id": "00000000-0000-0000-0000-00000000000b", "filter_id": "filter-y", "filter_type": "exclude"},
+			{"type": "all_users"},
+			{"type": "all_devices", "filter_id": "filter-x"}
+		]
+	}`, policy.id())
+	res := env.apply("intune_policy_assignment", nil, config)
+
+	filters := make(map[string]string)
+	for _, assignment := range env.graph.Assignments(fakegraph.DeviceCompliancePolicies, policy.id()) {
+		target := assignment.(map[string]interface{})["target"].(map[string]interface{})
+		key, _ := target["groupId"].(string)
+		if key == "" {
+			key = target["@odata.type"].(string)
+		}
+		filterID, _ := target["deviceAndAppManagementAssignmentFilterId"].(string)
+		filterType, _ := target["deviceAndAppManagementAssignmentFilterType"].(string)
+		filters[key] = filterID + "/" + filterType
+	}
+	for key, want := range map[string]string{
+		"00000000-0000-0000-0000-00000000000a":              "filter-x/include",
+		"00000000-0000-0000-0000-00000000000b":              "filter-y/exclude",
+		"#microsoft.graph.allLicensedUsersAssignmentTarget": "/",
+		"#microsoft.graph.allDevicesAssignmentTarget":       "filter-x/include",
+	} {
+		if filters[key] != want {
+			t.Errorf("target %s: expected filter %q, got %q", key, want, filters[key])
+		}
+	}
+
+	// The targets round-trip in the configured order, also when Graph returns them in another order
+	assignments := env.graph.Assignments(fakegraph.DeviceCompliancePolicies, policy.id())
+	reversed := make([]interface{}, 0, len(assignments))
+	for i := len(assignments) - 1; i >= 0; i-- {
+		reversed = append(reversed, assignments[i])
+	}
+	env.graph.SetAssignments(fakegraph.DeviceCompliancePolicies, policy.id(), reversed)
+	env.assertNoOp(env.refresh(res), config)
+
+	imported := env.importState("intune_policy_assignment", "compliance:"+policy.id())
+	if n := len(imported.attrs()["target"].([]interface{})); n != 4 {
+		t.Errorf("expected 4 imported targets, got %d", n)
+	}
+}
+
+func TestAccPolicyAssignmentResource_upgradeState(t *testing.T) {
+	env := newTestEnv(t)
+
+	policy := env.apply("intune_compliance_policy", nil, `{"display_name": "Windows baseline"}`)
+	config := fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "compliance",
+		"target": [
+			{"type": "group", "group_id": "00000000-0000-0000-0000-000000000001", "filter_id": "filter-x"},
+			{"type": "all_devices", "filter_id": "filter-x"},
+			{"type": "exclusion", "group_id": "00000000-0000-0000-0000-000000000002"}
+		]
+	}`, policy.id())
+	env.apply("intune_policy_assignment", nil, config)
+
+	res := env.upgradeState("intune_policy_assignment", 0, fmt.Sprintf(`{
+		"id": %[1]q,
+		"policy_id": %[1]q,
+		"policy_type": "compliance",
+		"include_groups": ["00000000-0000-0000-0000-000000000001"],
+		"exclude_groups": ["00000000-0000-0000-0000-000000000002"],
+		"all_devices": true,
+		"all_users": null,
+		"filter_id": "filter-x",
+		"filter_type": null
+	}`, policy.id()))
+	env.assertNoOp(env.refresh(res), config)
+}
+
+func TestAccPolicyAssignmentResource_invalidTarget(t *testing.T) {
+	env := newTestEnv(t)
+
+	msg := env.applyExpectError("intune_policy_assignment", nil, `{
+		"policy_id": "00000000-0000-0000-0000-000000000000",
+		"policy_type": "compliance",
+		"target": [{"type": "all_devices"}]
+	}`)
+	if !strings.Contains(msg, "ResourceNotFound") {
+		t.Errorf("expected a not found error, got: %s", msg)
+	}
+
+	for target, want := range map[string]string{
+		`{"type": "group"}`: "Missing Assignment Group",
+		`{"type": "all_users", "group_id": "00000000-0000-0000-0000-000000000001"}`:                          "Unexpected Assignment Group",
+		`{"type": "exclusion", "group_id": "00000000-0000-0000-0000-000000000001", "filter_id": "filter-x"}`: "Unsupported Assignment Filter",
+		`{"type": "group", "group_id": "00000000-0000-0000-0000-000000000001", "filter_type": "exclude"}`:    "Missing Assignment Filter",
+	} {
+		msg := env.applyExpectError("intune_policy_assignment", nil, fmt.Sprintf(`{
+			"policy_id": "00000000-0000-0000-0000-000000000000",
+			"policy_type": "compliance",
+			"target": [%s]
+		}`, target))
+		if !strings.Contains(msg, want) {
+			t.Errorf("target %s: expected %q, got: %s", target, want, msg)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &PolicyGroupAssignmentResource{}
+var _ resource.ResourceWithImportState = &PolicyGroupAssignmentResource{}
+var _ resource.ResourceWithValidateConfig = &PolicyGroupAssignmentResource{}
+
+// NewPolicyGroupAssignmentResource creates a new resource instance
+func NewPolicyGroupAssignmentResource() resource.Resource {
+	return &PolicyGroupAssignmentResource{}
+}
+
+// PolicyGroupAssignmentResource defines the resource implementation
+type PolicyGroupAssignmentResource struct {
+	client *clients.GraphClient
+}
+
+// PolicyGroupAssignmentResourceModel describes the resource data model
+type PolicyGroupAssignmentResourceModel struct {
+	ID         types.String `tfsdk:"id"`
+	PolicyID   types.String `tfsdk:"policy_id"`
+	PolicyType types.String `tfsdk:"policy_type"`
+	TargetType types.String `tfsdk:"target_type"`
+	GroupID    types.String `tfsdk:"group_id"`
+	FilterID   types.String `tfsdk:"filter_id"`
+	FilterType types.String `tfsdk:"filter_type"`
+}
+
+// target returns the assignment target the model describes
+func (m *PolicyGroupAssignmentResourceModel) target() AssignmentTargetModel {
+	return AssignmentTargetModel{
+		Type:       m.TargetType,
+		GroupID:    m.GroupID,
+		FilterID:   m.FilterID,
+		FilterType: m.FilterType,
+	}
+}
+
+// Metadata returns the resource type name
+func (r *PolicyGroupAssignmentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_policy_group_assignment"
+}
+
+// Schema defines the schema for the resource
+func (r *PolicyGroupAssignmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Assigns an Intune policy to a single target without touching its other assignments.",
+		MarkdownDescription: `
+Assigns an Intune policy to a single target without touching its other assignments.
+
+Graph only supports replacing all assignments of a policy at once. This resource reads the
+current assignments, adds, changes or removes only its own target and posts the list back, so
+several teams can assign the same policy to their own groups. Changes to the assignments of a
+policy are serialized within the provider.
+
+Do not combine this resource with ` + "`intune_policy_assignment`" + ` or an inline ` + "`assignment`" + `
+block for the same policy: those replace all assignments, including the targets managed here.
+
+## Example Usage
+
+` + "```hcl" + `
+resource "intune_policy_group_assignment" "helpdesk" {
+  policy_id   = intune_settings_catalog_policy.example.id
+  policy_type = intune_settings_catalog_policy.example.type
+  target_type = "group"
+  group_id    = data.azuread_group.helpdesk.id
+  filter_id   = intune_assignment_filter.corporate.id
+}
+
+resource "intune_policy_group_assignment" "kiosks" {
+  policy_id   = intune_settings_catalog_policy.example.id
+  policy_type = intune_settings_catalog_policy.example.type
+  target_type = "exclusion"
+  group_id    = data.azuread_group.kiosks.id
+}
+` + "```" + `
+
+## Import
+
+The import ID is ` + "`policy_type:policy_id:target`" + `, where the target is ` + "`all_devices`" + `,
+` + "`all_users`" + ` or the target type and group ID separated by a slash:
+
+` + "```shell" + `
+tofu import intune_policy_group_assignment.helpdesk settings_catalog:00000000-0000-0000-0000-000000000000:group/11111111-1111-1111-1111-111111111111
+` + "```" + `
+`,
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for this assignment, in the format policy_type:policy_id:target.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"policy_id": schema.StringAttribute{
+				Description: "The ID of the policy to assign.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"policy_type": schema.StringAttribute{
+				Description: "The type of policy. Valid values: settings_catalog, compliance, endpoint_security, device_configuration, linux_compliance, feature_update, quality_update, driver_update, autopilot, enrollment_status_page.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						PolicyTypeSettingsCatalog,
+						PolicyTypeCompliance,
+						PolicyTypeEndpointSecurity,
+						PolicyTypeDeviceConfig,
+						PolicyTypeLinuxCompliance,
+						PolicyTypeFeatureUpdate,
+						PolicyTypeQualityUpdate,
+						PolicyTypeDriverUpdate,
+						PolicyTypeAutopilot,
+						PolicyTypeEnrollmentStatusPage,
+					),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"target_type": schema.StringAttribute{
+				Description: "The target type. Valid values: group, exclusion, all_devices, all_users.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						AssignmentTargetGroup,
+						AssignmentTargetExclusion,
+						AssignmentTargetAllDevices,
+						AssignmentTargetAllUsers,
+					),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"group_id": schema.StringAttribute{
+				Description: "The ID of the Azure AD group. Required for group and exclusion targets.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"filter_id": schema.StringAttribute{
+				Description: "The ID of an assignment filter to apply to the target. Not supported for exclusion targets.",
+				Optional:    true,
+			},
+			"filter_type": schema.StringAttribute{
+				Description: "The type of filter: 'include' or 'exclude'. Defaults to include when filter_id is set.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("include", "exclude"),
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *PolicyGroupAssignmentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+}
+
+// ValidateConfig checks that group_id and the filter match the target type
+func (r *PolicyGroupAssignmentResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data PolicyGroupAssignmentResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateAssignmentTarget(data.target(), path.Empty(), &resp.Diagnostics)
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *PolicyGroupAssignmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data PolicyGroupAssignmentResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating policy group assignment", map[string]interface{}{
+		"policy_id":   data.PolicyID.ValueString(),
+		"policy_type": data.PolicyType.ValueString(),
+		"target":      policyGroupAssignmentTarget(data.TargetType.ValueString(), data.GroupID.ValueString()),
+	})
+
+	if err := r.updateAssignments(ctx, &data, true); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Policy Group Assignment",
+			fmt.Sprintf("Could not assign policy %s: %s", data.PolicyID.ValueString(), err),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(policyGroupAssignmentID(data.PolicyType.ValueString(), data.PolicyID.ValueString(),
+		data.TargetType.ValueString(), data.GroupID.ValueString()))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data
+func (r *PolicyGroupAssignmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data PolicyGroupAssignmentResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	policyId := data.PolicyID.ValueString()
+	policyType := data.PolicyType.ValueString()
+
+	tflog.Debug(ctx, "Reading policy group assignment", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	readPath := getAssignmentsReadPath(policyType, policyId)
+	if readPath == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Policy Type",
+			fmt.Sprintf("Unknown policy type: %s", policyType),
+		)
+		return
+	}
+
+	apiAssignments, err := clients.ListInto[clients.PolicyAssignment](ctx, r.client, readPath)
+	if err != nil {
+		// Check if policy was deleted
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Policy Group Assignment",
+			fmt.Sprintf("Could not read assignments for policy ID %s: %s", policyId, err),
+		)
+		return
+	}
+
+	prior := data.target()
+	for _, target := range assignmentTargetModels(apiAssignments) {
+		if !target.Type.Equal(prior.Type) || target.GroupID.ValueString() != prior.GroupID.ValueString() {
+			continue
+		}
+		target = PreserveAssignmentTargets([]AssignmentTargetModel{prior}, []AssignmentTargetModel{target})[0]
+		data.FilterID = target.FilterID
+		data.FilterType = target.FilterType
+
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	// The target was removed outside of this resource
+	resp.State.RemoveResource(ctx)
+}
+
+// Update updates the resource and sets the updated Terraform state
+func (r *PolicyGroupAssignmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data PolicyGroupAssignmentResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating policy group assignment", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	if err := r.updateAssignments(ctx, &data, true); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Policy Group Assignment",
+			fmt.Sprintf("Could not update assignment %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete deletes the resource and removes the Terraform state
+func (r *PolicyGroupAssignmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data PolicyGroupAssignmentResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting policy group assignment", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	if err := r.updateAssignments(ctx, &data, false); err != nil {
+		// Ignore not found errors during delete
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Policy Group Assignment",
+			fmt.Sprintf("Could not delete assignment %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// updateAssignments reads the assignments of the policy, replaces the target of the resource, or
+// removes it when assign is false, and posts the assignments back. Other targets are posted as
+// they were read, so fields the provider does not know survive the round trip.
+func (r *PolicyGroupAssignmentResource) updateAssignments(ctx context.Context, data *PolicyGroupAssignmentResourceModel, assign bool) error {
+	policyId := data.PolicyID.ValueString()
+	policyType := data.PolicyType.ValueString()
+
+	readPath := getAssignmentsReadPath(policyType, policyId)
+	assignPath := getAssignPath(policyType, policyId)
+	if readPath == "" || assignPath == "" {
+		return fmt.Errorf("unknown policy type: %s", policyType)
+	}
+
+	unlock := policyLocks.Lock(policyId)
+	defer unlock()
+
+	current, err := clients.ListInto[map[string]interface{}](ctx, r.client, readPath)
+	if err != nil {
+		return fmt.Errorf("failed to read assignments: %w", err)
+	}
+
+	odataType := assignmentTargetODataTypes[data.TargetType.ValueString()]
+	groupId := data.GroupID.ValueString()
+
+	assignments := make([]interface{}, 0, len(current)+1)
+	for _, assignment := range current {
+		target, ok := assignment["target"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		targetType, _ := target["@odata.type"].(string)
+		targetGroupId, _ := target["groupId"].(string)
+		if targetType == odataType && targetGroupId == groupId {
+			continue
+		}
+		assignments = append(assignments, map[string]interface{}{"target": target})
+	}
+	if assign {
+		assignments = append(assignments, BuildAssignmentTargets([]AssignmentTargetModel{data.target()})[0])
+	}
+
+	if err := postAssignments(ctx, r.client, policyType, assignPath, assignments); err != nil {
+		return err
+	}
+
+	tflog.Debug(ctx, "Updated policy assignments", map[string]interface{}{
+		"policy_id":   policyId,
+		"policy_type": policyType,
+		"assignments": len(assignments),
+	})
+
+	return nil
+}
+
+// policyGroupAssignmentTarget returns the target part of a resource ID: the target type, followed
+// by the group ID for group and exclusion targets
+func policyGroupAssignmentTarget(targetType, groupId string) string {
+	if groupId == "" {
+		return targetType
+	}
+	return targetType + "/" + groupId
+}
+
+// policyGroupAssignmentID returns the ID of a policy group assignment
+func policyGroupAssignmentID(policyType, policyId, targetType, groupId string) string {
+	return strings.Join([]string{policyType, policyId, policyGroupAssignmentTarget(targetType, groupId)}, ":")
+}
+
+// ImportState imports the resource state
+func (r *PolicyGroupAssignmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Import format: policy_type:policy_id:target
+	parts := strings.Split(req.ID, ":")
+	var targetType, groupId string
+	if len(parts) == 3 {
+		targetType, groupId, _ = strings.Cut(parts[2], "/")
+	}
+
+	_, known := assignmentTargetODataTypes[targetType]
+	needsGroup := targetType == AssignmentTargetGroup || targetType == AssignmentTargetExclusion
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || !known || needsGroup != (groupId != "") {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			"Import ID must be in format: policy_type:policy_id:target, where target is all_devices, all_users, group/<group_id> or exclusion/<group_id>",
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("policy_type"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("policy_id"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("target_type"), targetType)...)
+	if groupId != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("group_id"), groupId)...)
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
@@ -0,0 +1,169 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+// assignedTargets returns the targets of the assignments of a policy in Graph, keyed by group ID
+// or, for targets without a group, by OData type
+func assignedTargets(env *testEnv, collection, policyID string) map[string]map[string]interface{} {
+	targets := make(map[string]map[string]interface{})
+	for _, assignment := range env.graph.Assignments(collection, policyID) {
+		target := assignment.(map[string]interface{})["target"].(map[string]interface{})
+		key, _ := target["groupId"].(string)
+		if key == "" {
+			key = target["@odata.type"].(string)
+		}
+		targets[key] = target
+	}
+	return targets
+}
+
+func TestAccPolicyGroupAssignmentResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	policy := env.apply("intune_settings_catalog_policy", nil, `{
+		"name": "Baseline",
+		"platforms": "windows10",
+		"technologies": "mdm",
+		"assignment": [{"target": [{"type": "all_users"}]}]
+	}`)
+
+	helpdeskConfig := fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "settings_catalog",
+		"target_type": "group",
+		"group_id": "00000000-0000-0000-0000-00000000000a",
+		"filter_id": "filter-x"
+	}`, policy.id())
+	helpdesk := env.apply("intune_policy_group_assignment", nil, helpdeskConfig)
+	assertAttr(t, helpdesk.attrs(), "id", "settings_catalog:"+policy.id()+":group/00000000-0000-0000-0000-00000000000a")
+
+	kiosks := env.apply("intune_policy_group_assignment", nil, fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "settings_catalog",
+		"target_type": "exclusion",
+		"group_id": "00000000-0000-0000-0000-00000000000b"
+	}`, policy.id()))
+
+	// Both targets are added next to the assignment of the policy
+	targets := assignedTargets(env, fakegraph.ConfigurationPolicies, policy.id())
+	if len(targets) != 3 {
+		t.Fatalf("expected 3 assignments in Graph, got %d", len(targets))
+	}
+	assertAttr(t, targets["00000000-0000-0000-0000-00000000000a"], "deviceAndAppManagementAssignmentFilterId", "filter-x")
+	assertAttr(t, targets["00000000-0000-0000-0000-00000000000a"], "deviceAndAppManagementAssignmentFilterType", "include")
+	assertAttr(t, targets["00000000-0000-0000-0000-00000000000b"], "@odata.type", "#microsoft.graph.exclusionGroupAssignmentTarget")
+	env.assertNoOp(env.refresh(helpdesk), helpdeskConfig)
+
+	// Changing the filter only touches the target of the resource
+	updated := strings.Replace(helpdeskConfig, `"filter_id": "filter-x"`, `"filter_id": "filter-y", "filter_type": "exclude"`, 1)
+	helpdesk = env.apply("intune_policy_group_assignment", helpdesk, updated)
+	targets = assignedTargets(env, fakegraph.ConfigurationPolicies, policy.id())
+	if len(targets) != 3 {
+		t.Fatalf("expected 3 assignments in Graph, got %d", len(targets))
+	}
+	assertAttr(t, targets["00000000-0000-0000-0000-00000000000a"], "deviceAndAppManagementAssignmentFilterType", "exclude")
+	env.assertNoOp(env.refresh(helpdesk), updated)
+
+	imported := env.importState("intune_policy_group_assignment", helpdesk.id())
+	assertAttr(t, imported.attrs(), "target_type", "group")
+	assertAttr(t, imported.attrs(), "group_id", "00000000-0000-0000-0000-00000000000a")
+	assertAttr(t, imported.attrs(), "filter_id", "filter-y")
+
+	env.destroy(kiosks)
+	targets = assignedTargets(env, fakegraph.ConfigurationPolicies, policy.id())
+	if len(targets) != 2 || targets["00000000-0000-0000-0000-00000000000b"] != nil {
+		t.Errorf("expected only the exclusion to be removed, got %v", targets)
+	}
+
+	// A target removed outside of the resource is dropped from state
+	env.graph.SetAssignments(fakegraph.ConfigurationPolicies, policy.id(), nil)
+	if res := env.refresh(helpdesk); res != nil {
+		t.Errorf("expected the resource to be removed from state, got %v", res.attrs())
+	}
+}
+
+func TestAccPolicyGroupAssignmentResource_concurrent(t *testing.T) {
+	env := newTestEnv(t)
+
+	policy := env.apply("intune_compliance_policy", nil, `{"display_name": "Windows baseline"}`)
+
+	const groups = 8
+	var wg sync.WaitGroup
+	errs := make([]string, groups)
+	for i := 0; i < groups; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, diags := env.tryApply("intune_policy_group_assignment", nil, fmt.Sprintf(`{
+				"policy_id": %q,
+				"policy_type": "compliance",
+				"target_type": "group",
+				"group_id": "00000000-0000-0000-0000-%012d"
+			}`, policy.id(), i))
+			errs[i] = errorSummary(diags)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, msg := range errs {
+		if msg != "" {
+			t.Errorf("apply %d: %s", i, msg)
+		}
+	}
+	if n := len(env.graph.Assignments(fakegraph.DeviceCompliancePolicies, policy.id())); n != groups {
+		t.Errorf("expected %d assignments in Graph, got %d", groups, n)
+	}
+}
+
+func TestAccPolicyGroupAssignmentResource_invalid(t *testing.T) {
+	env := newTestEnv(t)
+
+	msg := env.applyExpectError("intune_policy_group_assignment", nil, `{
+		"policy_id": "policy",
+		"policy_type": "compliance",
+		"target_type": "group"
+	}`)
+	if !strings.Contains(msg, "Missing Assignment Group") {
+		t.Errorf("expected a missing group error, got: %s", msg)
+	}
+
+	msg = env.applyExpectError("intune_policy_group_assignment", nil, `{
+		"policy_id": "policy",
+		"policy_type": "compliance",
+		"target_type": "all_devices",
+		"group_id": "00000000-0000-0000-0000-000000000001"
+	}`)
+	if !strings.Contains(msg, "Unexpected Assignment Group") {
+		t.Errorf("expected an unexpected group error, got: %s", msg)
+	}
+
+	for _, id := range []string{
+		"compliance:policy",
+		"compliance:policy:group",
+		"compliance:policy:all_devices/00000000-0000-0000-0000-000000000001",
+		"compliance:policy:everyone",
+	} {
+		resp, err := env.server.ImportResourceState(env.ctx, &tfprotov6.ImportResourceStateRequest{
+			TypeName: "intune_policy_group_assignment",
+			ID:       id,
+		})
+		if err != nil {
+			t.Fatalf("ImportResourceState: %s", err)
+		}
+		if msg := errorSummary(resp.Diagnostics); !strings.Contains(msg, "Invalid Import ID") {
+			t.Errorf("import %s: expected an invalid import ID error, got: %q", id, msg)
+		}
+	}
+}
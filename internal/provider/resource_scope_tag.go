@@ -163,7 +163,7 @@ func (r *ScopeTagResource) Create(ctx context.Context, req resource.CreateReques
 	// Update the model with the created scope tag data
 	data.ID = types.StringValue(created.ID)
 	data.DisplayName = types.StringValue(created.DisplayName)
-	data.Description = types.StringValue(created.Description)
+	data.Description = optionalStringValue(data.Description, created.Description)
 	data.IsBuiltIn = types.BoolValue(created.IsBuiltIn)
 
 	tflog.Debug(ctx, "Created scope tag", map[string]interface{}{
@@ -186,7 +186,7 @@ func (r *ScopeTagResource) Read(ctx context.Context, req resource.ReadRequest, r
 	tag, err := r.client.GetScopeTag(ctx, data.ID.ValueString())
 	if err != nil {
 		// Check if the resource was deleted outside of Terraform
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			resp.State.RemoveResource(ctx)
 			return
 		}
@@ -199,7 +199,7 @@ func (r *ScopeTagResource) Read(ctx context.Context, req resource.ReadRequest, r
 
 	// Update the model with the read data
 	data.DisplayName = types.StringValue(tag.DisplayName)
-	data.Description = types.StringValue(tag.Description)
+	data.Description = optionalStringValue(data.Description, tag.Description)
 	data.IsBuiltIn = types.BoolValue(tag.IsBuiltIn)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -232,7 +232,7 @@ func (r *ScopeTagResource) Update(ctx context.Context, req resource.UpdateReques
 
 	// Update the model with the updated scope tag data
 	data.DisplayName = types.StringValue(updated.DisplayName)
-	data.Description = types.StringValue(updated.Description)
+	data.Description = optionalStringValue(data.Description, updated.Description)
 	data.IsBuiltIn = types.BoolValue(updated.IsBuiltIn)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -259,7 +259,7 @@ func (r *ScopeTagResource) Delete(ctx context.Context, req resource.DeleteReques
 	err := r.client.DeleteScopeTag(ctx, data.ID.ValueString())
 	if err != nil {
 		// Ignore "not found" errors as the resource is already deleted
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			return
 		}
 		resp.Diagnostics.AddError(
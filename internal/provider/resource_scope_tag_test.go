@@ -0,0 +1,46 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+func TestAccScopeTagResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{"display_name": "Helpdesk", "description": "Helpdesk devices"}`
+	res := env.apply("intune_scope_tag", nil, config)
+	assertAttr(t, res.attrs(), "display_name", "Helpdesk")
+	assertAttr(t, res.attrs(), "is_built_in", false)
+	env.assertNoOp(res, config)
+
+	updated := `{"display_name": "Helpdesk", "description": "Updated"}`
+	res = env.apply("intune_scope_tag", res, updated)
+	if got := env.graph.Object(fakegraph.RoleScopeTags, res.id())["description"]; got != "Updated" {
+		t.Errorf("description in Graph: got %v, want Updated", got)
+	}
+	env.assertNoOp(res, updated)
+
+	imported := env.importState("intune_scope_tag", res.id())
+	env.assertNoOp(imported, updated)
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.RoleScopeTags, res.id()) != nil {
+		t.Errorf("scope tag %s still exists after destroy", res.id())
+	}
+}
+
+func TestAccScopeTagResource_deletedOutOfBand(t *testing.T) {
+	env := newTestEnv(t)
+
+	res := env.apply("intune_scope_tag", nil, `{"display_name": "Helpdesk"}`)
+	env.graph.Remove(fakegraph.RoleScopeTags, res.id())
+
+	if refreshed := env.refresh(res); refreshed != nil {
+		t.Fatalf("expected resource to be removed from state, got %v", refreshed.attrs())
+	}
+}
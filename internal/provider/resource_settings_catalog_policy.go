@@ -23,6 +23,7 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces
 var _ resource.Resource = &SettingsCatalogPolicyResource{}
 var _ resource.ResourceWithImportState = &SettingsCatalogPolicyResource{}
+var _ resource.ResourceWithUpgradeState = &SettingsCatalogPolicyResource{}
 
 // NewSettingsCatalogPolicyResource creates a new resource instance
 func NewSettingsCatalogPolicyResource() resource.Resource {
@@ -58,6 +59,7 @@ func (r *SettingsCatalogPolicyResource) Metadata(ctx context.Context, req resour
 // Schema defines the schema for the resource
 func (r *SettingsCatalogPolicyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: 1,
 		Description: "Manages an Intune Settings Catalog policy. This resource creates the policy container; " +
 			"use intune_settings_catalog_policy_settings to add settings to the policy.",
 		MarkdownDescription: `
@@ -78,7 +80,9 @@ resource "intune_settings_catalog_policy" "windows_security" {
   technologies = "mdm"
 
   assignment {
-    all_devices = true
+    target {
+      type = "all_devices"
+    }
   }
 }
 ` + "```" + `
@@ -93,8 +97,14 @@ resource "intune_settings_catalog_policy" "windows_security" {
   technologies = "mdm"
 
   assignment {
-    include_groups = [data.azuread_group.all_devices.id]
-    exclude_groups = [data.azuread_group.test_devices.id]
+    target {
+      type     = "group"
+      group_id = data.azuread_group.all_devices.id
+    }
+    target {
+      type     = "exclusion"
+      group_id = data.azuread_group.test_devices.id
+    }
   }
 }
 
@@ -320,11 +330,12 @@ func (r *SettingsCatalogPolicyResource) Read(ctx context.Context, req resource.R
 		"id": data.ID.ValueString(),
 	})
 
-	// Get the policy
-	policy, err := r.client.GetSettingsCatalogPolicy(ctx, data.ID.ValueString())
+	// Get the policy, together with its assignments if the state had assignments configured
+	path := fmt.Sprintf("%s('%s')?$expand=settings", clients.PathSettingsCatalogPolicies, data.ID.ValueString())
+	result, err := readPolicyWithAssignments[clients.SettingsCatalogPolicy](ctx, r.client, PolicyTypeSettingsCatalog, path, data.ID.ValueString(), len(data.Assignment) > 0)
 	if err != nil {
 		// Check if policy was deleted
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			resp.State.RemoveResource(ctx)
 			return
 		}
@@ -336,9 +347,10 @@ func (r *SettingsCatalogPolicyResource) Read(ctx context.Context, req resource.R
 	}
 
 	// Update the model
+	policy := result.Policy
 	data.Name = types.StringValue(policy.Name)
 	data.Type = types.StringValue(PolicyTypeSettingsCatalog)
-	data.Description = types.StringValue(policy.Description)
+	data.Description = optionalStringValue(data.Description, policy.Description)
 	data.Platforms = types.StringValue(policy.Platforms)
 	data.Technologies = types.StringValue(policy.Technologies)
 	data.CreatedDateTime = types.StringValue(policy.CreatedDateTime)
@@ -346,26 +358,21 @@ func (r *SettingsCatalogPolicyResource) Read(ctx context.Context, req resource.R
 	data.SettingCount = types.Int64Value(int64(policy.SettingCount))
 
 	// Handle role scope tag IDs
-	if len(policy.RoleScopeTagIds) > 0 {
-		tagIds, diags := types.ListValueFrom(ctx, types.StringType, policy.RoleScopeTagIds)
-		resp.Diagnostics.Append(diags...)
-		data.RoleScopeTagIds = tagIds
-	}
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, policy.RoleScopeTagIds, &resp.Diagnostics)
 
 	// Handle template reference
 	if policy.TemplateReference != nil && policy.TemplateReference.TemplateId != "" {
 		data.TemplateId = types.StringValue(policy.TemplateReference.TemplateId)
 	}
 
-	// Read assignments if the state had assignments configured
+	// Update assignments if the state had assignments configured
 	if len(data.Assignment) > 0 {
-		assignments, err := ReadPolicyAssignments(ctx, r.client, PolicyTypeSettingsCatalog, data.ID.ValueString())
-		if err != nil {
+		if result.AssignmentsErr != nil {
 			tflog.Warn(ctx, "Failed to read policy assignments", map[string]interface{}{
-				"error": err.Error(),
+				"error": result.AssignmentsErr.Error(),
 			})
 		} else {
-			data.Assignment = assignments
+			data.Assignment = PreserveAssignments(data.Assignment, result.Assignments)
 		}
 	}
 
@@ -387,8 +394,10 @@ func (r *SettingsCatalogPolicyResource) Update(ctx context.Context, req resource
 
 	// Build the policy update object
 	policy := &clients.SettingsCatalogPolicy{
-		Name:        data.Name.ValueString(),
-		Description: data.Description.ValueString(),
+		Name:         data.Name.ValueString(),
+		Description:  data.Description.ValueString(),
+		Platforms:    data.Platforms.ValueString(),
+		Technologies: data.Technologies.ValueString(),
 	}
 
 	// Add role scope tag IDs if specified
@@ -457,7 +466,7 @@ func (r *SettingsCatalogPolicyResource) Delete(ctx context.Context, req resource
 	err := r.client.DeleteSettingsCatalogPolicy(ctx, data.ID.ValueString())
 	if err != nil {
 		// Ignore not found errors during delete
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			return
 		}
 		resp.Diagnostics.AddError(
@@ -468,6 +477,11 @@ func (r *SettingsCatalogPolicyResource) Delete(ctx context.Context, req resource
 	}
 }
 
+// UpgradeState upgrades the assignment blocks of prior schema versions
+func (r *SettingsCatalogPolicyResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return assignmentStateUpgraders()
+}
+
 // ImportState imports the resource state
 func (r *SettingsCatalogPolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
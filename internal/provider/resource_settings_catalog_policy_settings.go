@@ -5,19 +5,15 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strconv"
+	"sort"
 
-	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
-	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
-	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
@@ -27,6 +23,8 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces
 var _ resource.Resource = &SettingsCatalogPolicySettingsResource{}
 var _ resource.ResourceWithImportState = &SettingsCatalogPolicySettingsResource{}
+var _ resource.ResourceWithValidateConfig = &SettingsCatalogPolicySettingsResource{}
+var _ resource.ResourceWithModifyPlan = &SettingsCatalogPolicySettingsResource{}
 
 // NewSettingsCatalogPolicySettingsResource creates a new resource instance
 func NewSettingsCatalogPolicySettingsResource() resource.Resource {
@@ -35,7 +33,8 @@ func NewSettingsCatalogPolicySettingsResource() resource.Resource {
 
 // SettingsCatalogPolicySettingsResource defines the resource implementation
 type SettingsCatalogPolicySettingsResource struct {
-	client *clients.GraphClient
+	client      *clients.GraphClient
+	definitions *SettingDefinitionCache
 }
 
 // SettingsCatalogPolicySettingsResourceModel describes the resource data model
@@ -45,40 +44,6 @@ type SettingsCatalogPolicySettingsResourceModel struct {
 	Settings types.List   `tfsdk:"setting"`
 }
 
-// SettingModel represents a single setting in the policy
-type SettingModel struct {
-	DefinitionID types.String `tfsdk:"definition_id"`
-	ValueType    types.String `tfsdk:"value_type"`
-	Value        types.String `tfsdk:"value"`
-	Children     types.List   `tfsdk:"children"`
-}
-
-// SettingModelAttrTypes returns the attribute types for SettingModel
-func SettingModelAttrTypes() map[string]attr.Type {
-	return map[string]attr.Type{
-		"definition_id": types.StringType,
-		"value_type":    types.StringType,
-		"value":         types.StringType,
-		"children":      types.ListType{ElemType: types.ObjectType{AttrTypes: ChildSettingModelAttrTypes()}},
-	}
-}
-
-// ChildSettingModel represents a child setting (for choice or group settings)
-type ChildSettingModel struct {
-	DefinitionID types.String `tfsdk:"definition_id"`
-	ValueType    types.String `tfsdk:"value_type"`
-	Value        types.String `tfsdk:"value"`
-}
-
-// ChildSettingModelAttrTypes returns the attribute types for ChildSettingModel
-func ChildSettingModelAttrTypes() map[string]attr.Type {
-	return map[string]attr.Type{
-		"definition_id": types.StringType,
-		"value_type":    types.StringType,
-		"value":         types.StringType,
-	}
-}
-
 // Metadata returns the resource type name
 func (r *SettingsCatalogPolicySettingsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_settings_catalog_policy_settings"
@@ -96,6 +61,11 @@ This resource allows modular management of settings within a policy. You can use
 instances of this resource (or modules that wrap it) to compose a complete policy from
 separate, reusable components.
 
+Each instance only owns the setting definition IDs it declares. Creating, updating or
+deleting an instance merges its settings into the policy and leaves settings declared by
+other instances pointing at the same ` + "`policy_id`" + ` untouched. Two instances must not
+declare the same definition ID.
+
 ## Example Usage
 
 ### Basic Settings
@@ -142,8 +112,46 @@ module "defender_settings" {
 - ` + "`integer`" + `: An integer value
 - ` + "`boolean`" + `: A boolean value ("true" or "false")
 - ` + "`choice`" + `: A choice from predefined options (use the choice value ID)
-- ` + "`collection`" + `: A collection of values (JSON array as string)
+- ` + "`collection`" + `: A collection of values (JSON array of strings or integers, e.g. ` + "`jsonencode([\"a\", \"b\"])`" + `)
 - ` + "`group`" + `: A group of child settings
+- ` + "`group_collection`" + `: One element of a group collection. Consecutive blocks with the same
+  ` + "`definition_id`" + ` form the elements of a single collection.
+
+Choice, group and group_collection settings nest further settings in ` + "`children`" + ` blocks, up to
+ten levels deep.
+
+## Validation
+
+During plan, each ` + "`definition_id`" + ` is looked up in the Settings Catalog and the setting is checked
+against its definition: the value type, choice options, integer ranges, string lengths and formats,
+the number of collection values and which children a setting accepts. Definitions are fetched once
+per run. If the Settings Catalog cannot be reached, a warning is shown and Intune validates the
+settings at apply time.
+
+### Nested Settings
+
+` + "```hcl" + `
+resource "intune_settings_catalog_policy_settings" "asr" {
+  policy_id = intune_settings_catalog_policy.example.id
+
+  setting {
+    definition_id = "device_vendor_msft_policy_config_defender_attacksurfacereductionrules"
+    value_type    = "group_collection"
+
+    children {
+      definition_id = "device_vendor_msft_policy_config_defender_attacksurfacereductionrules_blockexecutionofpotentiallyobfuscatedscripts"
+      value_type    = "choice"
+      value         = "device_vendor_msft_policy_config_defender_attacksurfacereductionrules_blockexecutionofpotentiallyobfuscatedscripts_block"
+    }
+
+    children {
+      definition_id = "device_vendor_msft_policy_config_defender_attacksurfacereductionrules_blockexecutionofpotentiallyobfuscatedscripts_perruleexclusions"
+      value_type    = "collection"
+      value         = jsonencode(["C:\\Tools\\build.ps1"])
+    }
+  }
+}
+` + "```" + `
 `,
 
 		Attributes: map[string]schema.Attribute{
@@ -163,53 +171,7 @@ module "defender_settings" {
 			},
 		},
 		Blocks: map[string]schema.Block{
-			"setting": schema.ListNestedBlock{
-				Description: "A setting to include in the policy.",
-				NestedObject: schema.NestedBlockObject{
-					Attributes: map[string]schema.Attribute{
-						"definition_id": schema.StringAttribute{
-							Description: "The setting definition ID. This identifies the specific setting in the Settings Catalog.",
-							Required:    true,
-						},
-						"value_type": schema.StringAttribute{
-							Description: "The type of value. Valid values: string, integer, boolean, choice, collection, group.",
-							Required:    true,
-							Validators: []validator.String{
-								stringvalidator.OneOf("string", "integer", "boolean", "choice", "collection", "group"),
-							},
-						},
-						"value": schema.StringAttribute{
-							Description: "The value for the setting. For boolean, use 'true' or 'false'. " +
-								"For choice, use the choice option ID. For collection, use a JSON array string.",
-							Optional: true,
-						},
-					},
-					Blocks: map[string]schema.Block{
-						"children": schema.ListNestedBlock{
-							Description: "Child settings for choice or group settings.",
-							NestedObject: schema.NestedBlockObject{
-								Attributes: map[string]schema.Attribute{
-									"definition_id": schema.StringAttribute{
-										Description: "The child setting definition ID.",
-										Required:    true,
-									},
-									"value_type": schema.StringAttribute{
-										Description: "The type of value for the child setting.",
-										Required:    true,
-										Validators: []validator.String{
-											stringvalidator.OneOf("string", "integer", "boolean", "choice"),
-										},
-									},
-									"value": schema.StringAttribute{
-										Description: "The value for the child setting.",
-										Required:    true,
-									},
-								},
-							},
-						},
-					},
-				},
-			},
+			"setting": SettingBlockSchema(1),
 		},
 	}
 }
@@ -230,314 +192,168 @@ func (r *SettingsCatalogPolicySettingsResource) Configure(ctx context.Context, r
 	}
 
 	r.client = providerData.GraphClient
+	r.definitions = providerData.SettingDefinitions
 }
 
-// convertToAPISettings converts the Terraform model to API settings
-func (r *SettingsCatalogPolicySettingsResource) convertToAPISettings(ctx context.Context, data *SettingsCatalogPolicySettingsResourceModel, diags *diag.Diagnostics) []clients.SettingsCatalogPolicySetting {
-	var settings []SettingModel
-	diags.Append(data.Settings.ElementsAs(ctx, &settings, false)...)
-	if diags.HasError() {
-		return nil
-	}
-
-	var apiSettings []clients.SettingsCatalogPolicySetting
-
-	for _, setting := range settings {
-		apiSetting := clients.SettingsCatalogPolicySetting{
-			ODataType:       "#microsoft.graph.deviceManagementConfigurationSetting",
-			SettingInstance: r.convertSettingInstance(ctx, setting, diags),
-		}
-		apiSettings = append(apiSettings, apiSetting)
+// ValidateConfig checks the shape and syntax of the settings without contacting Graph
+func (r *SettingsCatalogPolicySettingsResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var settings types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("setting"), &settings)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	return apiSettings
+	ValidateSettings(SettingModelsFromList(settings), path.Root("setting"), &resp.Diagnostics)
 }
 
-// convertSettingInstance converts a setting model to an API setting instance
-func (r *SettingsCatalogPolicySettingsResource) convertSettingInstance(ctx context.Context, setting SettingModel, diags *diag.Diagnostics) *clients.SettingInstance {
-	instance := &clients.SettingInstance{
-		SettingDefinitionId: setting.DefinitionID.ValueString(),
+// ModifyPlan checks the planned settings against their Settings Catalog definitions
+func (r *SettingsCatalogPolicySettingsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.definitions == nil {
+		return
 	}
 
-	valueType := setting.ValueType.ValueString()
-	value := setting.Value.ValueString()
-
-	switch valueType {
-	case "string":
-		instance.ODataType = "#microsoft.graph.deviceManagementConfigurationSimpleSettingInstance"
-		instance.SimpleSettingValue = &clients.SimpleSettingValue{
-			ODataType: "#microsoft.graph.deviceManagementConfigurationStringSettingValue",
-			Value:     value,
-		}
-
-	case "integer":
-		instance.ODataType = "#microsoft.graph.deviceManagementConfigurationSimpleSettingInstance"
-		intVal, err := strconv.ParseInt(value, 10, 64)
-		if err != nil {
-			diags.AddError("Invalid Integer Value", fmt.Sprintf("Could not parse '%s' as integer: %s", value, err))
-			return nil
-		}
-		instance.SimpleSettingValue = &clients.SimpleSettingValue{
-			ODataType: "#microsoft.graph.deviceManagementConfigurationIntegerSettingValue",
-			Value:     intVal,
-		}
-
-	case "boolean":
-		instance.ODataType = "#microsoft.graph.deviceManagementConfigurationSimpleSettingInstance"
-		boolVal := value == "true"
-		instance.SimpleSettingValue = &clients.SimpleSettingValue{
-			ODataType: "#microsoft.graph.deviceManagementConfigurationBooleanSettingValue",
-			Value:     boolVal,
-		}
-
-	case "choice":
-		instance.ODataType = "#microsoft.graph.deviceManagementConfigurationChoiceSettingInstance"
-		instance.ChoiceSettingValue = &clients.ChoiceSettingValue{
-			ODataType: "#microsoft.graph.deviceManagementConfigurationChoiceSettingValue",
-			Value:     value,
-		}
-
-		// Handle children for choice settings
-		if !setting.Children.IsNull() && len(setting.Children.Elements()) > 0 {
-			var children []ChildSettingModel
-			diags.Append(setting.Children.ElementsAs(ctx, &children, false)...)
-			if diags.HasError() {
-				return nil
-			}
-
-			for _, child := range children {
-				childSetting := r.convertChildSetting(child, diags)
-				if childSetting != nil {
-					instance.ChoiceSettingValue.Children = append(instance.ChoiceSettingValue.Children, *childSetting)
-				}
-			}
-		}
-
-	case "collection":
-		instance.ODataType = "#microsoft.graph.deviceManagementConfigurationSimpleSettingCollectionInstance"
-		// Parse JSON array
-		var values []interface{}
-		if err := json.Unmarshal([]byte(value), &values); err != nil {
-			diags.AddError("Invalid Collection Value", fmt.Sprintf("Could not parse '%s' as JSON array: %s", value, err))
-			return nil
-		}
-		for _, v := range values {
-			instance.SimpleSettingCollectionValue = append(instance.SimpleSettingCollectionValue, clients.SimpleSettingValue{
-				ODataType: "#microsoft.graph.deviceManagementConfigurationStringSettingValue",
-				Value:     fmt.Sprintf("%v", v),
-			})
-		}
-
-	case "group":
-		instance.ODataType = "#microsoft.graph.deviceManagementConfigurationGroupSettingInstance"
-		instance.GroupSettingValue = &clients.GroupSettingValue{
-			ODataType: "#microsoft.graph.deviceManagementConfigurationGroupSettingValue",
-		}
-
-		// Handle children for group settings
-		if !setting.Children.IsNull() && len(setting.Children.Elements()) > 0 {
-			var children []ChildSettingModel
-			diags.Append(setting.Children.ElementsAs(ctx, &children, false)...)
-			if diags.HasError() {
-				return nil
-			}
-
-			for _, child := range children {
-				childSetting := r.convertChildSetting(child, diags)
-				if childSetting != nil {
-					instance.GroupSettingValue.Children = append(instance.GroupSettingValue.Children, *childSetting)
-				}
-			}
-		}
+	var settingsList types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("setting"), &settingsList)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	return instance
-}
-
-// convertChildSetting converts a child setting model to an API setting
-func (r *SettingsCatalogPolicySettingsResource) convertChildSetting(child ChildSettingModel, diags *diag.Diagnostics) *clients.SettingsCatalogPolicySetting {
-	childInstance := &clients.SettingInstance{
-		SettingDefinitionId: child.DefinitionID.ValueString(),
+	settings := SettingModelsFromList(settingsList)
+	ids := SettingDefinitionIDs(settings)
+	if len(ids) == 0 {
+		return
 	}
 
-	valueType := child.ValueType.ValueString()
-	value := child.Value.ValueString()
-
-	switch valueType {
-	case "string":
-		childInstance.ODataType = "#microsoft.graph.deviceManagementConfigurationSimpleSettingInstance"
-		childInstance.SimpleSettingValue = &clients.SimpleSettingValue{
-			ODataType: "#microsoft.graph.deviceManagementConfigurationStringSettingValue",
-			Value:     value,
-		}
-
-	case "integer":
-		childInstance.ODataType = "#microsoft.graph.deviceManagementConfigurationSimpleSettingInstance"
-		intVal, err := strconv.ParseInt(value, 10, 64)
-		if err != nil {
-			diags.AddError("Invalid Integer Value", fmt.Sprintf("Could not parse '%s' as integer: %s", value, err))
-			return nil
-		}
-		childInstance.SimpleSettingValue = &clients.SimpleSettingValue{
-			ODataType: "#microsoft.graph.deviceManagementConfigurationIntegerSettingValue",
-			Value:     intVal,
-		}
-
-	case "boolean":
-		childInstance.ODataType = "#microsoft.graph.deviceManagementConfigurationSimpleSettingInstance"
-		boolVal := value == "true"
-		childInstance.SimpleSettingValue = &clients.SimpleSettingValue{
-			ODataType: "#microsoft.graph.deviceManagementConfigurationBooleanSettingValue",
-			Value:     boolVal,
-		}
-
-	case "choice":
-		childInstance.ODataType = "#microsoft.graph.deviceManagementConfigurationChoiceSettingInstance"
-		childInstance.ChoiceSettingValue = &clients.ChoiceSettingValue{
-			ODataType: "#microsoft.graph.deviceManagementConfigurationChoiceSettingValue",
-			Value:     value,
-		}
+	definitions, err := r.definitions.Load(ctx, ids)
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Could Not Validate Settings",
+			fmt.Sprintf("Could not read setting definitions from the Settings Catalog, settings will be validated by Intune at apply time: %s", err),
+		)
+		return
 	}
 
-	return &clients.SettingsCatalogPolicySetting{
-		ODataType:       "#microsoft.graph.deviceManagementConfigurationSetting",
-		SettingInstance: childInstance,
-	}
+	ValidateSettingDefinitions(settings, definitions, path.Root("setting"), &resp.Diagnostics)
 }
 
-// convertAPISettingsToModel converts API settings back to the Terraform model format
-func (r *SettingsCatalogPolicySettingsResource) convertAPISettingsToModel(ctx context.Context, apiSettings []clients.SettingsCatalogPolicySetting, diags *diag.Diagnostics) types.List {
-	var settings []SettingModel
-
-	for _, apiSetting := range apiSettings {
-		if apiSetting.SettingInstance == nil {
-			continue
-		}
-
-		instance := apiSetting.SettingInstance
-		setting := SettingModel{
-			DefinitionID: types.StringValue(instance.SettingDefinitionId),
-			Children:     types.ListNull(types.ObjectType{AttrTypes: ChildSettingModelAttrTypes()}),
-		}
+// convertToAPISettings converts the Terraform model to API settings
+func (r *SettingsCatalogPolicySettingsResource) convertToAPISettings(data *SettingsCatalogPolicySettingsResourceModel, diags *diag.Diagnostics) []clients.SettingsCatalogPolicySetting {
+	return BuildSettingInstances(SettingModelsFromList(data.Settings), path.Root("setting"), diags)
+}
 
-		// Determine the value type and extract the value
-		switch {
-		case instance.SimpleSettingValue != nil:
-			setting.ValueType, setting.Value = r.parseSimpleSettingValue(instance.SimpleSettingValue)
-
-		case instance.ChoiceSettingValue != nil:
-			setting.ValueType = types.StringValue("choice")
-			setting.Value = types.StringValue(instance.ChoiceSettingValue.Value)
-			// Handle children for choice settings
-			if len(instance.ChoiceSettingValue.Children) > 0 {
-				setting.Children = r.parseChildSettings(ctx, instance.ChoiceSettingValue.Children, diags)
-			}
+// convertAPISettingsToModel converts API settings back to the Terraform model format. Values that are
+// semantically equal to the prior settings keep their prior spelling.
+func (r *SettingsCatalogPolicySettingsResource) convertAPISettingsToModel(ctx context.Context, apiSettings []clients.SettingsCatalogPolicySetting, prior types.List, diags *diag.Diagnostics) types.List {
+	settings, unsupported := FlattenSettingInstances(apiSettings)
+	for _, definitionID := range unsupported {
+		tflog.Warn(ctx, "Unknown setting type", map[string]interface{}{
+			"definition_id": definitionID,
+		})
+	}
 
-		case len(instance.SimpleSettingCollectionValue) > 0:
-			setting.ValueType = types.StringValue("collection")
-			var values []string
-			for _, v := range instance.SimpleSettingCollectionValue {
-				values = append(values, fmt.Sprintf("%v", v.Value))
-			}
-			jsonBytes, _ := json.Marshal(values)
-			setting.Value = types.StringValue(string(jsonBytes))
-
-		case instance.GroupSettingValue != nil:
-			setting.ValueType = types.StringValue("group")
-			setting.Value = types.StringNull()
-			if len(instance.GroupSettingValue.Children) > 0 {
-				setting.Children = r.parseChildSettings(ctx, instance.GroupSettingValue.Children, diags)
-			}
+	PreserveSettingValues(SettingModelsFromList(prior), settings)
 
-		default:
-			// Unknown setting type, skip
-			tflog.Warn(ctx, "Unknown setting type", map[string]interface{}{
-				"definition_id": instance.SettingDefinitionId,
-				"odata_type":    instance.ODataType,
-			})
-			continue
-		}
+	return SettingModelsToList(settings, 1, diags)
+}
 
-		settings = append(settings, setting)
+// ownedDefinitionIDs returns the top-level definition IDs declared in a settings list, mapped to their
+// position in the list
+func ownedDefinitionIDs(settingsList types.List) map[string]int {
+	owned := make(map[string]int)
+	if settingsList.IsNull() || settingsList.IsUnknown() {
+		return owned
 	}
 
-	// Convert to types.List
-	if len(settings) == 0 {
-		return types.ListNull(types.ObjectType{AttrTypes: SettingModelAttrTypes()})
+	for i, setting := range SettingModelsFromList(settingsList) {
+		owned[setting.DefinitionID.ValueString()] = i
 	}
 
-	settingsList, listDiags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: SettingModelAttrTypes()}, settings)
-	diags.Append(listDiags...)
-
-	return settingsList
+	return owned
 }
 
-// parseSimpleSettingValue parses a simple setting value and returns the value type and value
-func (r *SettingsCatalogPolicySettingsResource) parseSimpleSettingValue(ssv *clients.SimpleSettingValue) (types.String, types.String) {
-	if ssv == nil {
-		return types.StringNull(), types.StringNull()
+// mergePolicySettings merges the settings owned by this resource into the settings already on the policy.
+// Existing settings whose definition ID is in remove are dropped, settings present in add replace the
+// existing setting with the same definition ID in place, and any remaining settings in add are appended.
+// Settings owned by other resources are left untouched.
+func mergePolicySettings(existing []clients.SettingsCatalogPolicySetting, remove map[string]int, add []clients.SettingsCatalogPolicySetting) []clients.SettingsCatalogPolicySetting {
+	addByID := make(map[string]clients.SettingsCatalogPolicySetting, len(add))
+	for _, setting := range add {
+		addByID[settingDefinitionID(setting)] = setting
 	}
 
-	switch ssv.ODataType {
-	case "#microsoft.graph.deviceManagementConfigurationStringSettingValue":
-		return types.StringValue("string"), types.StringValue(fmt.Sprintf("%v", ssv.Value))
-
-	case "#microsoft.graph.deviceManagementConfigurationIntegerSettingValue":
-		return types.StringValue("integer"), types.StringValue(fmt.Sprintf("%v", ssv.Value))
-
-	case "#microsoft.graph.deviceManagementConfigurationBooleanSettingValue":
-		boolVal, ok := ssv.Value.(bool)
-		if !ok {
-			return types.StringValue("boolean"), types.StringValue(fmt.Sprintf("%v", ssv.Value))
+	merged := make([]clients.SettingsCatalogPolicySetting, 0, len(existing)+len(add))
+	written := make(map[string]bool, len(add))
+	for _, setting := range existing {
+		id := settingDefinitionID(setting)
+		if replacement, ok := addByID[id]; ok {
+			if !written[id] {
+				merged = append(merged, replacement)
+				written[id] = true
+			}
+			continue
 		}
-		if boolVal {
-			return types.StringValue("boolean"), types.StringValue("true")
+		if _, ok := remove[id]; ok {
+			continue
 		}
-		return types.StringValue("boolean"), types.StringValue("false")
-
-	default:
-		// Treat unknown types as string
-		return types.StringValue("string"), types.StringValue(fmt.Sprintf("%v", ssv.Value))
+		// Strip server-assigned IDs so the settings can be written back
+		setting.ID = ""
+		merged = append(merged, setting)
 	}
-}
 
-// parseChildSettings parses child settings from the API format
-func (r *SettingsCatalogPolicySettingsResource) parseChildSettings(ctx context.Context, apiChildren []clients.SettingsCatalogPolicySetting, diags *diag.Diagnostics) types.List {
-	var children []ChildSettingModel
-
-	for _, apiChild := range apiChildren {
-		if apiChild.SettingInstance == nil {
+	for _, setting := range add {
+		id := settingDefinitionID(setting)
+		if written[id] {
 			continue
 		}
+		merged = append(merged, setting)
+		written[id] = true
+	}
 
-		instance := apiChild.SettingInstance
-		child := ChildSettingModel{
-			DefinitionID: types.StringValue(instance.SettingDefinitionId),
-		}
-
-		switch {
-		case instance.SimpleSettingValue != nil:
-			child.ValueType, child.Value = r.parseSimpleSettingValue(instance.SimpleSettingValue)
-
-		case instance.ChoiceSettingValue != nil:
-			child.ValueType = types.StringValue("choice")
-			child.Value = types.StringValue(instance.ChoiceSettingValue.Value)
+	return merged
+}
 
-		default:
-			continue
+// filterPolicySettings returns the settings whose definition ID is in owned, in the order they are
+// declared in. Graph keeps its own order, so settings are matched by definition ID rather than by
+// position and reordering the declared settings does not show up as drift.
+func filterPolicySettings(settings []clients.SettingsCatalogPolicySetting, owned map[string]int) []clients.SettingsCatalogPolicySetting {
+	var filtered []clients.SettingsCatalogPolicySetting
+	for _, setting := range settings {
+		if _, ok := owned[settingDefinitionID(setting)]; ok {
+			filtered = append(filtered, setting)
 		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return owned[settingDefinitionID(filtered[i])] < owned[settingDefinitionID(filtered[j])]
+	})
+	return filtered
+}
 
-		children = append(children, child)
+// settingDefinitionID returns the definition ID of a policy setting, or an empty string if it has no instance
+func settingDefinitionID(setting clients.SettingsCatalogPolicySetting) string {
+	if setting.SettingInstance == nil {
+		return ""
 	}
+	return setting.SettingInstance.SettingDefinitionId
+}
 
-	if len(children) == 0 {
-		return types.ListNull(types.ObjectType{AttrTypes: ChildSettingModelAttrTypes()})
+// applySettings reads the current policy settings, merges in the given settings and writes the result back.
+// The policy lock must be held by the caller.
+func (r *SettingsCatalogPolicySettingsResource) applySettings(ctx context.Context, policyID string, remove map[string]int, add []clients.SettingsCatalogPolicySetting) error {
+	existing, err := r.client.GetSettingsCatalogPolicySettings(ctx, policyID)
+	if err != nil {
+		return err
 	}
 
-	childList, listDiags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: ChildSettingModelAttrTypes()}, children)
-	diags.Append(listDiags...)
+	merged := mergePolicySettings(existing, remove, add)
+
+	tflog.Debug(ctx, "Merged Settings Catalog policy settings", map[string]interface{}{
+		"policy_id":      policyID,
+		"existing_count": len(existing),
+		"owned_count":    len(add),
+		"merged_count":   len(merged),
+	})
 
-	return childList
+	return r.client.UpdateSettingsCatalogPolicySettings(ctx, policyID, merged)
 }
 
 // Create creates the resource and sets the initial Terraform state
@@ -556,13 +372,16 @@ func (r *SettingsCatalogPolicySettingsResource) Create(ctx context.Context, req
 	})
 
 	// Convert settings to API format
-	apiSettings := r.convertToAPISettings(ctx, &data, &resp.Diagnostics)
+	apiSettings := r.convertToAPISettings(&data, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Update the policy with the settings
-	err := r.client.UpdateSettingsCatalogPolicySettings(ctx, policyID, apiSettings)
+	// Merge our settings into the policy without touching settings owned by other resources
+	unlock := policyLocks.Lock(policyID)
+	defer unlock()
+
+	err := r.applySettings(ctx, policyID, nil, apiSettings)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Creating Settings Catalog Policy Settings",
@@ -596,11 +415,11 @@ func (r *SettingsCatalogPolicySettingsResource) Read(ctx context.Context, req re
 		"policy_id": policyID,
 	})
 
-	// Get the policy with settings
-	policy, err := r.client.GetSettingsCatalogPolicy(ctx, policyID)
+	// Get the settings currently on the policy
+	policySettings, err := r.client.GetSettingsCatalogPolicySettings(ctx, policyID)
 	if err != nil {
 		// Check if policy was deleted
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			resp.State.RemoveResource(ctx)
 			return
 		}
@@ -611,21 +430,31 @@ func (r *SettingsCatalogPolicySettingsResource) Read(ctx context.Context, req re
 		return
 	}
 
-	// If no settings exist, remove the resource
-	if len(policy.Settings) == 0 {
+	// Only report the settings this resource owns. On import the state has no settings yet,
+	// in which case the resource adopts every setting on the policy.
+	owned := ownedDefinitionIDs(data.Settings)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if len(owned) > 0 {
+		policySettings = filterPolicySettings(policySettings, owned)
+	}
+
+	// If none of our settings exist anymore, remove the resource
+	if len(policySettings) == 0 {
 		resp.State.RemoveResource(ctx)
 		return
 	}
 
 	// Convert API settings back to Terraform model
-	data.Settings = r.convertAPISettingsToModel(ctx, policy.Settings, &resp.Diagnostics)
+	data.Settings = r.convertAPISettingsToModel(ctx, policySettings, data.Settings, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	tflog.Debug(ctx, "Read Settings Catalog policy settings", map[string]interface{}{
 		"policy_id":      policyID,
-		"settings_count": len(policy.Settings),
+		"settings_count": len(policySettings),
 	})
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -634,8 +463,10 @@ func (r *SettingsCatalogPolicySettingsResource) Read(ctx context.Context, req re
 // Update updates the resource and sets the updated Terraform state
 func (r *SettingsCatalogPolicySettingsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var data SettingsCatalogPolicySettingsResourceModel
+	var state SettingsCatalogPolicySettingsResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -647,13 +478,21 @@ func (r *SettingsCatalogPolicySettingsResource) Update(ctx context.Context, req
 	})
 
 	// Convert settings to API format
-	apiSettings := r.convertToAPISettings(ctx, &data, &resp.Diagnostics)
+	apiSettings := r.convertToAPISettings(&data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Settings that were owned before but are no longer declared must be removed from the policy
+	removed := ownedDefinitionIDs(state.Settings)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Update the policy with the settings
-	err := r.client.UpdateSettingsCatalogPolicySettings(ctx, policyID, apiSettings)
+	unlock := policyLocks.Lock(policyID)
+	defer unlock()
+
+	err := r.applySettings(ctx, policyID, removed, apiSettings)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Updating Settings Catalog Policy Settings",
@@ -680,16 +519,24 @@ func (r *SettingsCatalogPolicySettingsResource) Delete(ctx context.Context, req
 		"policy_id": policyID,
 	})
 
-	// Clear settings by updating with an empty array
-	err := r.client.UpdateSettingsCatalogPolicySettings(ctx, policyID, []clients.SettingsCatalogPolicySetting{})
+	owned := ownedDefinitionIDs(data.Settings)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Remove only our own settings, leaving settings managed by other resources in place
+	unlock := policyLocks.Lock(policyID)
+	defer unlock()
+
+	err := r.applySettings(ctx, policyID, owned, nil)
 	if err != nil {
 		// Ignore not found errors during delete
-		if graphErr, ok := err.(*clients.GraphError); ok && graphErr.Code == "NotFound" {
+		if clients.IsNotFound(err) {
 			return
 		}
 		resp.Diagnostics.AddError(
 			"Error Deleting Settings Catalog Policy Settings",
-			fmt.Sprintf("Could not clear policy settings: %s", err),
+			fmt.Sprintf("Could not remove policy settings: %s", err),
 		)
 		return
 	}
@@ -0,0 +1,409 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+func TestAccSettingsCatalogPolicyResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"name": "Defender baseline",
+		"platforms": "windows10",
+		"technologies": "mdm",
+		"assignment": [{"target": [{"type": "group", "group_id": "00000000-0000-0000-0000-000000000001", "filter_id": "f1", "filter_type": "include"}]}]
+	}`
+	res := env.apply("intune_settings_catalog_policy", nil, config)
+	assertAttr(t, res.attrs(), "type", PolicyTypeSettingsCatalog)
+	assertAttr(t, res.attrs(), "setting_count", 0)
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	updated := `{
+		"name": "Defender baseline",
+		"description": "Managed by OpenTofu",
+		"platforms": "windows10",
+		"technologies": "mdm"
+	}`
+	res = env.apply("intune_settings_catalog_policy", res, updated)
+	assertAttr(t, env.graph.Object(fakegraph.ConfigurationPolicies, res.id()), "description", "Managed by OpenTofu")
+	if n := len(env.graph.Assignments(fakegraph.ConfigurationPolicies, res.id())); n != 0 {
+		t.Errorf("expected assignments to be removed, got %d", n)
+	}
+	env.assertNoOp(env.refresh(res), updated)
+
+	imported := env.importState("intune_settings_catalog_policy", res.id())
+	assertAttr(t, imported.attrs(), "name", "Defender baseline")
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.ConfigurationPolicies, res.id()) != nil {
+		t.Errorf("policy %s still exists after destroy", res.id())
+	}
+}
+
+const (
+	odataTestChoiceDefinition     = "#microsoft.graph.deviceManagementConfigurationChoiceSettingDefinition"
+	odataTestSimpleDefinition     = "#microsoft.graph.deviceManagementConfigurationSimpleSettingDefinition"
+	odataTestCollectionDefinition = "#microsoft.graph.deviceManagementConfigurationSimpleSettingCollectionDefinition"
+	odataTestGroupDefinition      = "#microsoft.graph.deviceManagementConfigurationSettingGroupDefinition"
+	odataTestGroupCollection      = "#microsoft.graph.deviceManagementConfigurationSettingGroupCollectionDefinition"
+)
+
+// choiceDefinition returns a choice setting definition with options suffixed _0 to _n-1
+func choiceDefinition(id string, n int) map[string]interface{} {
+	options := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		options = append(options, map[string]interface{}{"itemId": fmt.Sprintf("%s_%d", id, i)})
+	}
+	return map[string]interface{}{"@odata.type": odataTestChoiceDefinition, "id": id, "options": options}
+}
+
+// simpleDefinition returns a simple setting definition with the given value definition
+func simpleDefinition(odataType, id string, valueDefinition map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"@odata.type": odataType, "id": id, "valueDefinition": valueDefinition}
+}
+
+// groupDefinition returns a group or group collection definition with the given children
+func groupDefinition(odataType, id string, childIDs ...string) map[string]interface{} {
+	children := make([]interface{}, 0, len(childIDs))
+	for _, child := range childIDs {
+		children = append(children, child)
+	}
+	return map[string]interface{}{"@odata.type": odataType, "id": id, "childIds": children}
+}
+
+// addSettingDefinitions registers the Settings Catalog definitions used by the tests
+func addSettingDefinitions(env *testEnv) {
+	const firewall = "vendor_msft_firewall_mdmstore_firewallrules_{firewallrulename}"
+	integer := map[string]interface{}{"@odata.type": "#microsoft.graph.deviceManagementConfigurationIntegerSettingValueDefinition"}
+	str := map[string]interface{}{"@odata.type": "#microsoft.graph.deviceManagementConfigurationStringSettingValueDefinition"}
+
+	for _, def := range []map[string]interface{}{
+		choiceDefinition("device_vendor_msft_defender_configuration_allowcloudprotection", 2),
+		{
+			"@odata.type":  odataTestSimpleDefinition,
+			"id":           "device_vendor_msft_defender_configuration_disablelocaladminmerge",
+			"defaultValue": map[string]interface{}{"@odata.type": "#microsoft.graph.deviceManagementConfigurationBooleanSettingValue", "value": false},
+		},
+		groupDefinition(odataTestGroupCollection, firewall, firewall+"_name", firewall+"_action_type", firewall+"_localportranges", firewall+"_priority"),
+		simpleDefinition(odataTestSimpleDefinition, firewall+"_name", map[string]interface{}{
+			"@odata.type": str["@odata.type"], "minimumLength": 1, "maximumLength": 64,
+		}),
+		choiceDefinition(firewall+"_action_type", 2),
+		simpleDefinition(odataTestCollectionDefinition, firewall+"_localportranges", str),
+		simpleDefinition(odataTestSimpleDefinition, firewall+"_priority", map[string]interface{}{
+			"@odata.type": integer["@odata.type"], "minimumValue": 0, "maximumValue": 1000,
+		}),
+		choiceDefinition("device_vendor_msft_bitlocker_requiredeviceencryption", 2),
+		choiceDefinition("device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication", 2),
+		choiceDefinition("device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication_configuretpmstartupkeyusagedropdown_name", 3),
+		groupDefinition(odataTestGroupDefinition, "device_vendor_msft_bitlocker_encryptionmethodbydrivetype_group",
+			"device_vendor_msft_bitlocker_minimumpinlength", "device_vendor_msft_bitlocker_allowedports"),
+		simpleDefinition(odataTestSimpleDefinition, "device_vendor_msft_bitlocker_minimumpinlength", map[string]interface{}{
+			"@odata.type": integer["@odata.type"], "minimumValue": 4, "maximumValue": 20,
+		}),
+		{
+			"@odata.type":     odataTestCollectionDefinition,
+			"id":              "device_vendor_msft_bitlocker_allowedports",
+			"valueDefinition": integer,
+			"maximumCount":    3,
+		},
+		simpleDefinition(odataTestSimpleDefinition, "device_vendor_msft_policy_config_update_deferupdateperiodid", map[string]interface{}{
+			"@odata.type": str["@odata.type"], "format": "guid",
+		}),
+	} {
+		env.graph.AddSettingDefinition(def)
+	}
+}
+
+func TestAccSettingsCatalogPolicySettingsResource(t *testing.T) {
+	env := newTestEnv(t)
+	addSettingDefinitions(env)
+
+	policy := env.apply("intune_settings_catalog_policy", nil, `{
+		"name": "Defender baseline",
+		"platforms": "windows10",
+		"technologies": "mdm"
+	}`)
+
+	config := fmt.Sprintf(`{
+		"policy_id": %q,
+		"setting": [
+			{
+				"definition_id": "device_vendor_msft_defender_configuration_allowcloudprotection",
+				"value_type": "choice",
+				"value": "device_vendor_msft_defender_configuration_allowcloudprotection_1"
+			},
+			{
+				"definition_id": "device_vendor_msft_defender_configuration_disablelocaladminmerge",
+				"value_type": "boolean",
+				"value": "true"
+			}
+		]
+	}`, policy.id())
+	res := env.apply("intune_settings_catalog_policy_settings", nil, config)
+	if n := len(env.graph.Settings(fakegraph.ConfigurationPolicies, policy.id())); n != 2 {
+		t.Fatalf("expected 2 settings in Graph, got %d", n)
+	}
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	imported := env.importState("intune_settings_catalog_policy_settings", policy.id())
+	env.assertNoOp(imported, config)
+
+	// Graph keeps the settings in its own order, so reordering them only changes the state
+	reordered := fmt.Sprintf(`{
+		"policy_id": %q,
+		"setting": [
+			{
+				"definition_id": "device_vendor_msft_defender_configuration_disablelocaladminmerge",
+				"value_type": "boolean",
+				"value": "true"
+			},
+			{
+				"definition_id": "device_vendor_msft_defender_configuration_allowcloudprotection",
+				"value_type": "choice",
+				"value": "device_vendor_msft_defender_configuration_allowcloudprotection_1"
+			}
+		]
+	}`, policy.id())
+	res = env.apply("intune_settings_catalog_policy_settings", res, reordered)
+	env.assertNoOp(env.refresh(res), reordered)
+
+	env.destroy(res)
+	if n := len(env.graph.Settings(fakegraph.ConfigurationPolicies, policy.id())); n != 0 {
+		t.Errorf("expected settings to be removed, got %d", n)
+	}
+}
+
+func TestAccSettingsCatalogPolicySettingsResource_nested(t *testing.T) {
+	env := newTestEnv(t)
+	addSettingDefinitions(env)
+
+	policy := env.apply("intune_settings_catalog_policy", nil, `{
+		"name": "Firewall rules",
+		"platforms": "windows10",
+		"technologies": "mdm"
+	}`)
+
+	rule := func(name, action string, ports string) string {
+		return fmt.Sprintf(`{
+			"definition_id": "vendor_msft_firewall_mdmstore_firewallrules_{firewallrulename}",
+			"value_type": "group_collection",
+			"children": [
+				{"definition_id": "vendor_msft_firewall_mdmstore_firewallrules_{firewallrulename}_name", "value_type": "string", "value": %q},
+				{
+					"definition_id": "vendor_msft_firewall_mdmstore_firewallrules_{firewallrulename}_action_type",
+					"value_type": "choice",
+					"value": %q,
+					"children": [
+						{"definition_id": "vendor_msft_firewall_mdmstore_firewallrules_{firewallrulename}_localportranges", "value_type": "collection", "value": %q}
+					]
+				},
+				{"definition_id": "vendor_msft_firewall_mdmstore_firewallrules_{firewallrulename}_priority", "value_type": "integer", "value": "100"}
+			]
+		}`, name, action, ports)
+	}
+
+	config := fmt.Sprintf(`{
+		"policy_id": %q,
+		"setting": [
+			%s,
+			%s,
+			{
+				"definition_id": "device_vendor_msft_bitlocker_requiredeviceencryption",
+				"value_type": "choice",
+				"value": "device_vendor_msft_bitlocker_requiredeviceencryption_1",
+				"children": [{
+					"definition_id": "device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication",
+					"value_type": "choice",
+					"value": "device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication_1",
+					"children": [{
+						"definition_id": "device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication_configuretpmstartupkeyusagedropdown_name",
+						"value_type": "choice",
+						"value": "device_vendor_msft_bitlocker_systemdrivesrequirestartupauthentication_configuretpmstartupkeyusagedropdown_name_2",
+						"children": [{
+							"definition_id": "device_vendor_msft_bitlocker_encryptionmethodbydrivetype_group",
+							"value_type": "group",
+							"children": [
+								{"definition_id": "device_vendor_msft_bitlocker_minimumpinlength", "value_type": "integer", "value": "6"},
+								{"definition_id": "device_vendor_msft_bitlocker_allowedports", "value_type": "collection", "value": "[443, 8443]"}
+							]
+						}]
+					}]
+				}]
+			}
+		]
+	}`, policy.id(), rule("Allow HTTPS", "vendor_msft_firewall_mdmstore_firewallrules_{firewallrulename}_action_type_1", `["443"]`),
+		rule("Block SMB", "vendor_msft_firewall_mdmstore_firewallrules_{firewallrulename}_action_type_0", `["139", "445"]`))
+
+	res := env.apply("intune_settings_catalog_policy_settings", nil, config)
+
+	// Both firewall rules are sent as a single group collection instance
+	settings := env.graph.Settings(fakegraph.ConfigurationPolicies, policy.id())
+	if len(settings) != 2 {
+		t.Fatalf("expected 2 top-level settings in Graph, got %d", len(settings))
+	}
+	instance := settings[0].(map[string]interface{})["settingInstance"].(map[string]interface{})
+	if groups := instance["groupSettingCollectionValue"].([]interface{}); len(groups) != 2 {
+		t.Errorf("expected 2 values in the group collection, got %d", len(groups))
+	}
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	imported := env.importState("intune_settings_catalog_policy_settings", policy.id())
+	leaf := imported.attrs()["setting"].([]interface{})[2].(map[string]interface{})
+	for i := 0; i < 3; i++ {
+		leaf = leaf["children"].([]interface{})[0].(map[string]interface{})
+	}
+	ports := leaf["children"].([]interface{})[1].(map[string]interface{})
+	assertAttr(t, ports, "value", "[443,8443]")
+}
+
+func TestAccSettingsCatalogPolicySettingsResource_invalidNesting(t *testing.T) {
+	env := newTestEnv(t)
+
+	msg := env.applyExpectError("intune_settings_catalog_policy_settings", nil, `{
+		"policy_id": "00000000-0000-0000-0000-000000000000",
+		"setting": [{
+			"definition_id": "device_vendor_msft_bitlocker_requiredeviceencryption",
+			"value_type": "choice",
+			"value": "device_vendor_msft_bitlocker_requiredeviceencryption_1",
+			"children": [{
+				"definition_id": "device_vendor_msft_bitlocker_minimumpinlength",
+				"value_type": "integer",
+				"value": "6",
+				"children": [{"definition_id": "device_vendor_msft_bitlocker_other", "value_type": "string", "value": "x"}]
+			}]
+		}]
+	}`)
+	if !strings.Contains(msg, `AttributeName("children").ElementKeyInt(0).AttributeName("children"):`) || !strings.Contains(msg, "Unexpected Child Settings") {
+		t.Errorf("expected an error at the nested children block, got: %s", msg)
+	}
+}
+
+func TestAccSettingsCatalogPolicySettingsResource_definitionValidation(t *testing.T) {
+	const policyID = "00000000-0000-0000-0000-000000000000"
+
+	setting := func(definitionID, valueType, value string) string {
+		return fmt.Sprintf(`{"definition_id": %q, "value_type": %q, "value": %q}`, definitionID, valueType, value)
+	}
+
+	cases := map[string]struct {
+		setting string
+		path    string
+		summary string
+	}{
+		"unknown definition": {
+			setting: setting("device_vendor_msft_defender_configuration_doesnotexist", "boolean", "true"),
+			path:    `AttributeName("setting").ElementKeyInt(0).AttributeName("definition_id")`,
+			summary: "Unknown Setting Definition",
+		},
+		"wrong value type": {
+			setting: setting("device_vendor_msft_defender_configuration_disablelocaladminmerge", "string", "true"),
+			path:    `AttributeName("setting").ElementKeyInt(0).AttributeName("value_type")`,
+			summary: "Setting Value Type Mismatch",
+		},
+		"unknown choice option": {
+			setting: setting("device_vendor_msft_defender_configuration_allowcloudprotection", "choice", "device_vendor_msft_defender_configuration_allowcloudprotection_7"),
+			path:    `AttributeName("setting").ElementKeyInt(0).AttributeName("value")`,
+			summary: "Invalid Choice Option",
+		},
+		"integer out of range": {
+			setting: setting("device_vendor_msft_bitlocker_minimumpinlength", "integer", "2"),
+			path:    `AttributeName("setting").ElementKeyInt(0).AttributeName("value")`,
+			summary: "Setting Value Out Of Range",
+		},
+		"string format": {
+			setting: setting("device_vendor_msft_policy_config_update_deferupdateperiodid", "string", "not-a-guid"),
+			path:    `AttributeName("setting").ElementKeyInt(0).AttributeName("value")`,
+			summary: "Invalid Setting Value Format",
+		},
+		"too many collection values": {
+			setting: setting("device_vendor_msft_bitlocker_allowedports", "collection", "[80, 443, 8080, 8443]"),
+			path:    `AttributeName("setting").ElementKeyInt(0).AttributeName("value")`,
+			summary: "Invalid Number Of Values",
+		},
+		"nested string too long": {
+			setting: `{
+				"definition_id": "vendor_msft_firewall_mdmstore_firewallrules_{firewallrulename}",
+				"value_type": "group_collection",
+				"children": [` + setting("vendor_msft_firewall_mdmstore_firewallrules_{firewallrulename}_name", "string", strings.Repeat("x", 65)) + `]
+			}`,
+			path:    `AttributeName("setting").ElementKeyInt(0).AttributeName("children").ElementKeyInt(0).AttributeName("value")`,
+			summary: "Invalid Setting Value Length",
+		},
+		"child of another setting": {
+			setting: `{
+				"definition_id": "device_vendor_msft_bitlocker_encryptionmethodbydrivetype_group",
+				"value_type": "group",
+				"children": [` + setting("device_vendor_msft_defender_configuration_disablelocaladminmerge", "boolean", "true") + `]
+			}`,
+			path:    `AttributeName("setting").ElementKeyInt(0).AttributeName("children").ElementKeyInt(0).AttributeName("definition_id")`,
+			summary: "Invalid Child Setting",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			env := newTestEnv(t)
+			addSettingDefinitions(env)
+
+			msg := env.applyExpectError("intune_settings_catalog_policy_settings", nil,
+				fmt.Sprintf(`{"policy_id": %q, "setting": [%s]}`, policyID, tc.setting))
+			if !strings.Contains(msg, tc.path+": "+tc.summary) {
+				t.Errorf("expected %q at %s, got: %s", tc.summary, tc.path, msg)
+			}
+		})
+	}
+}
+
+func TestAccSettingsCatalogPolicySettingsResource_definitionCache(t *testing.T) {
+	env := newTestEnv(t)
+	addSettingDefinitions(env)
+
+	policy := env.apply("intune_settings_catalog_policy", nil, `{
+		"name": "Defender baseline",
+		"platforms": "windows10",
+		"technologies": "mdm"
+	}`)
+
+	config := fmt.Sprintf(`{
+		"policy_id": %q,
+		"setting": [{
+			"definition_id": "device_vendor_msft_defender_configuration_allowcloudprotection",
+			"value_type": "choice",
+			"value": "device_vendor_msft_defender_configuration_allowcloudprotection_1"
+		}]
+	}`, policy.id())
+
+	batches := func() int {
+		n := 0
+		for _, request := range env.graph.Requests() {
+			if strings.HasSuffix(request, "/$batch") {
+				n++
+			}
+		}
+		return n
+	}
+
+	res := env.apply("intune_settings_catalog_policy_settings", nil, config)
+
+	// The definition was looked up by the first plan, later plans in the same run use the cache
+	before := batches()
+	env.assertNoOp(res, config)
+	env.assertNoOp(res, config)
+	if n := batches() - before; n != 0 {
+		t.Errorf("expected cached definitions to be reused, got %d batch requests", n)
+	}
+}
@@ -0,0 +1,332 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &WindowsDriverUpdateApprovalResource{}
+var _ resource.ResourceWithImportState = &WindowsDriverUpdateApprovalResource{}
+var _ resource.ResourceWithValidateConfig = &WindowsDriverUpdateApprovalResource{}
+
+// driverApprovalStatusApproved is the approval status of approved drivers in a driver inventory
+const driverApprovalStatusApproved = "approved"
+
+// NewWindowsDriverUpdateApprovalResource returns a new Windows driver update approval resource
+func NewWindowsDriverUpdateApprovalResource() resource.Resource {
+	return &WindowsDriverUpdateApprovalResource{}
+}
+
+// WindowsDriverUpdateApprovalResource defines the resource implementation
+type WindowsDriverUpdateApprovalResource struct {
+	client *clients.GraphClient
+}
+
+// WindowsDriverUpdateApprovalResourceModel describes the resource data model
+type WindowsDriverUpdateApprovalResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	ProfileID      types.String `tfsdk:"profile_id"`
+	DriverIDs      types.Set    `tfsdk:"driver_ids"`
+	DeploymentDate types.String `tfsdk:"deployment_date"`
+}
+
+// Metadata returns the resource type name
+func (r *WindowsDriverUpdateApprovalResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_windows_driver_update_approval"
+}
+
+// Schema defines the schema for the resource
+func (r *WindowsDriverUpdateApprovalResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Approves drivers of an Intune Windows driver update profile.",
+		MarkdownDescription: `
+Approves drivers of an Intune Windows driver update profile.
+
+Approved drivers are deployed to the devices of the profile from ` + "`deployment_date`" + `, or
+immediately without it. Drivers removed from ` + "`driver_ids`" + ` and the drivers of a destroyed
+approval are suspended. Drivers approved outside of OpenTofu are left in place.
+
+The drivers applicable to a profile are listed by the ` + "`intune_driver_inventory`" + ` data source.
+
+## Example Usage
+
+` + "```hcl" + `
+data "intune_driver_inventory" "pilot" {
+  profile_id = intune_windows_driver_update_profile.pilot.id
+}
+
+resource "intune_windows_driver_update_approval" "pilot" {
+  profile_id = intune_windows_driver_update_profile.pilot.id
+  driver_ids = [
+    for driver in data.intune_driver_inventory.pilot.drivers : driver.id
+    if driver.category == "recommended" && driver.driver_class != "Firmware"
+  ]
+  deployment_date = "2026-11-02T00:00:00Z"
+}
+` + "```" + `
+
+## Import
+
+Approvals can be imported using the profile ID, which imports every approved driver of the profile:
+
+` + "```shell" + `
+terraform import intune_windows_driver_update_approval.example 00000000-0000-0000-0000-000000000000
+` + "```" + `
+`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The identifier of the approval, the ID of the profile.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"profile_id": schema.StringAttribute{
+				Description: "The ID of the driver update profile.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"driver_ids": schema.SetAttribute{
+				Description: "The IDs of the drivers to approve, from the driver inventory of the profile.",
+				Required:    true,
+				ElementType: types.StringType,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"deployment_date": schema.StringAttribute{
+				Description: "The date and time approved drivers are deployed from, in RFC 3339 format. " +
+					"Drivers are deployed immediately when unset.",
+				Optional: true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *WindowsDriverUpdateApprovalResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+}
+
+// ValidateConfig checks that the deployment date is an RFC 3339 date-time
+func (r *WindowsDriverUpdateApprovalResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var deploymentDate types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("deployment_date"), &deploymentDate)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dateTimeAttribute(deploymentDate, path.Root("deployment_date"), &resp.Diagnostics)
+}
+
+// driverIDs returns the driver IDs of a driver_ids set
+func driverIDs(ctx context.Context, set types.Set, diags *diag.Diagnostics) []string {
+	var ids []string
+	if len(set.Elements()) > 0 {
+		diags.Append(set.ElementsAs(ctx, &ids, false)...)
+	}
+	return ids
+}
+
+// deploymentDate returns the deployment date sent to Graph, empty to deploy immediately
+func (m *WindowsDriverUpdateApprovalResourceModel) deploymentDate() string {
+	if m.DeploymentDate.IsNull() {
+		return ""
+	}
+	return normalizeDateTime(m.DeploymentDate.ValueString())
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *WindowsDriverUpdateApprovalResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data WindowsDriverUpdateApprovalResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids := driverIDs(ctx, data.DriverIDs, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Approving drivers", map[string]interface{}{
+		"profile_id": data.ProfileID.ValueString(),
+		"driver_ids": ids,
+	})
+
+	if err := r.client.ExecuteWindowsDriverUpdateAction(ctx, data.ProfileID.ValueString(), clients.DriverActionApprove, ids, data.deploymentDate()); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Approving Drivers",
+			fmt.Sprintf("Could not approve drivers of profile ID %s: %s", data.ProfileID.ValueString(), err),
+		)
+		return
+	}
+
+	data.ID = data.ProfileID
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data. Drivers of the state that are no
+// longer approved are removed from it; an imported approval takes every approved driver.
+func (r *WindowsDriverUpdateApprovalResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data WindowsDriverUpdateApprovalResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	drivers, err := r.client.ListWindowsDriverUpdateInventories(ctx, data.ID.ValueString())
+	if err != nil {
+		// Check if the profile was deleted
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Driver Approvals",
+			fmt.Sprintf("Could not read drivers of profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	managed := make(map[string]bool)
+	for _, id := range driverIDs(ctx, data.DriverIDs, &resp.Diagnostics) {
+		managed[id] = true
+	}
+
+	approved := []string{}
+	for _, driver := range drivers {
+		if driver.ApprovalStatus != driverApprovalStatusApproved {
+			continue
+		}
+		if data.DriverIDs.IsNull() || managed[driver.ID] {
+			approved = append(approved, driver.ID)
+		}
+	}
+
+	data.ProfileID = data.ID
+	driverSet, d := types.SetValueFrom(ctx, types.StringType, approved)
+	resp.Diagnostics.Append(d...)
+	data.DriverIDs = driverSet
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update updates the resource and sets the updated Terraform state. Removed drivers are
+// suspended; added drivers are approved, or every driver when the deployment date changed.
+func (r *WindowsDriverUpdateApprovalResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state WindowsDriverUpdateApprovalResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids := driverIDs(ctx, data.DriverIDs, &resp.Diagnostics)
+	priorIDs := driverIDs(ctx, state.DriverIDs, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var removed, added []string
+	for _, id := range priorIDs {
+		if !containsString(ids, id) {
+			removed = append(removed, id)
+		}
+	}
+	for _, id := range ids {
+		if !containsString(priorIDs, id) || data.deploymentDate() != state.deploymentDate() {
+			added = append(added, id)
+		}
+	}
+
+	if len(removed) > 0 {
+		if err := r.client.ExecuteWindowsDriverUpdateAction(ctx, data.ProfileID.ValueString(), clients.DriverActionSuspend, removed, ""); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Suspending Drivers",
+				fmt.Sprintf("Could not suspend drivers of profile ID %s: %s", data.ProfileID.ValueString(), err),
+			)
+			return
+		}
+	}
+	if len(added) > 0 {
+		if err := r.client.ExecuteWindowsDriverUpdateAction(ctx, data.ProfileID.ValueString(), clients.DriverActionApprove, added, data.deploymentDate()); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Approving Drivers",
+				fmt.Sprintf("Could not approve drivers of profile ID %s: %s", data.ProfileID.ValueString(), err),
+			)
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete suspends the approved drivers and removes the Terraform state
+func (r *WindowsDriverUpdateApprovalResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data WindowsDriverUpdateApprovalResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids := driverIDs(ctx, data.DriverIDs, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() || len(ids) == 0 {
+		return
+	}
+
+	err := r.client.ExecuteWindowsDriverUpdateAction(ctx, data.ProfileID.ValueString(), clients.DriverActionSuspend, ids, "")
+	if err != nil {
+		// Drivers of a deleted profile are no longer deployed
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Suspending Drivers",
+			fmt.Sprintf("Could not suspend drivers of profile ID %s: %s", data.ProfileID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the resource state
+func (r *WindowsDriverUpdateApprovalResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
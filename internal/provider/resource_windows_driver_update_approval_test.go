@@ -0,0 +1,78 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAccWindowsDriverUpdateApprovalResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	profile := env.apply("intune_windows_driver_update_profile", nil, `{"display_name": "Drivers - pilot", "approval_type": "manual"}`)
+	addDriverInventory(env, profile.id())
+
+	config := fmt.Sprintf(`{
+		"profile_id": %q,
+		"driver_ids": ["driver-display"],
+		"deployment_date": "2026-11-02T01:00:00+01:00"
+	}`, profile.id())
+	res := env.apply("intune_windows_driver_update_approval", nil, config)
+	assertAttr(t, driverApprovalStatuses(env, profile.id()), "driver-display", "approved")
+	for _, d := range env.graph.DriverInventories(profile.id()) {
+		if driver := d.(map[string]interface{}); driver["id"] == "driver-display" {
+			assertAttr(t, driver, "deployDateTime", "2026-11-02T00:00:00Z")
+		}
+	}
+
+	// Drivers approved outside of OpenTofu are left out of the state
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	updated := fmt.Sprintf(`{
+		"profile_id": %q,
+		"driver_ids": ["driver-firmware"],
+		"deployment_date": "2026-11-02T01:00:00+01:00"
+	}`, profile.id())
+	res = env.apply("intune_windows_driver_update_approval", res, updated)
+	statuses := driverApprovalStatuses(env, profile.id())
+	assertAttr(t, statuses, "driver-display", "suspended")
+	assertAttr(t, statuses, "driver-firmware", "approved")
+	assertAttr(t, statuses, "driver-audio", "approved")
+	env.assertNoOp(env.refresh(res), updated)
+
+	// An imported approval takes every approved driver of the profile
+	imported := env.importState("intune_windows_driver_update_approval", profile.id())
+	assertAttr(t, imported.attrs(), "profile_id", profile.id())
+	if ids, _ := imported.attrs()["driver_ids"].([]interface{}); len(ids) != 2 {
+		t.Errorf("expected 2 imported drivers, got %v", imported.attrs()["driver_ids"])
+	}
+
+	env.destroy(res)
+	statuses = driverApprovalStatuses(env, profile.id())
+	assertAttr(t, statuses, "driver-firmware", "suspended")
+	assertAttr(t, statuses, "driver-audio", "approved")
+}
+
+func TestAccWindowsDriverUpdateApprovalResource_invalid(t *testing.T) {
+	env := newTestEnv(t)
+
+	profile := env.apply("intune_windows_driver_update_profile", nil, `{"display_name": "Drivers - pilot", "approval_type": "manual"}`)
+	addDriverInventory(env, profile.id())
+
+	for _, tc := range []struct {
+		config string
+		want   string
+	}{
+		{fmt.Sprintf(`{"profile_id": %q, "driver_ids": []}`, profile.id()), "driver_ids"},
+		{fmt.Sprintf(`{"profile_id": %q, "driver_ids": ["driver-display"], "deployment_date": "tomorrow"}`, profile.id()), "RFC 3339"},
+		{fmt.Sprintf(`{"profile_id": %q, "driver_ids": ["driver-unknown"]}`, profile.id()), "not applicable"},
+	} {
+		if msg := env.applyExpectError("intune_windows_driver_update_approval", nil, tc.config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.config, tc.want, msg)
+		}
+	}
+}
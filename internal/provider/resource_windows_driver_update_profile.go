@@ -0,0 +1,428 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &WindowsDriverUpdateProfileResource{}
+var _ resource.ResourceWithImportState = &WindowsDriverUpdateProfileResource{}
+var _ resource.ResourceWithValidateConfig = &WindowsDriverUpdateProfileResource{}
+
+// Approval types of driver update profiles
+const (
+	driverApprovalManual    = "manual"
+	driverApprovalAutomatic = "automatic"
+)
+
+// NewWindowsDriverUpdateProfileResource returns a new Windows driver update profile resource
+func NewWindowsDriverUpdateProfileResource() resource.Resource {
+	return &WindowsDriverUpdateProfileResource{}
+}
+
+// WindowsDriverUpdateProfileResource defines the resource implementation
+type WindowsDriverUpdateProfileResource struct {
+	client *clients.GraphClient
+}
+
+// WindowsDriverUpdateProfileResourceModel describes the resource data model
+type WindowsDriverUpdateProfileResourceModel struct {
+	ID                     types.String      `tfsdk:"id"`
+	Type                   types.String      `tfsdk:"type"`
+	DisplayName            types.String      `tfsdk:"display_name"`
+	Description            types.String      `tfsdk:"description"`
+	ApprovalType           types.String      `tfsdk:"approval_type"`
+	DeploymentDeferralDays types.Int64       `tfsdk:"deployment_deferral_days"`
+	RoleScopeTagIds        types.List        `tfsdk:"role_scope_tag_ids"`
+	CreatedDateTime        types.String      `tfsdk:"created_date_time"`
+	LastModifiedDateTime   types.String      `tfsdk:"last_modified_date_time"`
+	Assignment             []AssignmentModel `tfsdk:"assignment"`
+}
+
+// Metadata returns the resource type name
+func (r *WindowsDriverUpdateProfileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_windows_driver_update_profile"
+}
+
+// Schema defines the schema for the resource
+func (r *WindowsDriverUpdateProfileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages an Intune Windows driver update profile.",
+		MarkdownDescription: `
+Manages an Intune Windows driver update profile.
+
+Driver update profiles deploy driver updates to their assigned devices. With ` + "`manual`" + ` approval
+drivers are only deployed once approved, e.g. with ` + "`intune_windows_driver_update_approval`" + `.
+With ` + "`automatic`" + ` approval recommended drivers are approved after ` + "`deployment_deferral_days`" + `.
+The drivers applicable to the devices of a profile are listed by the ` + "`intune_driver_inventory`" + `
+data source. The approval type cannot be changed, changing it replaces the profile.
+
+## Example Usage
+
+` + "```hcl" + `
+resource "intune_windows_driver_update_profile" "pilot" {
+  display_name  = "Drivers - pilot"
+  approval_type = "manual"
+
+  assignment {
+    target {
+      type     = "group"
+      group_id = azuread_group.pilot.object_id
+    }
+  }
+}
+
+resource "intune_windows_driver_update_profile" "broad" {
+  display_name             = "Drivers - broad"
+  approval_type            = "automatic"
+  deployment_deferral_days = 14
+}
+` + "```" + `
+
+## Import
+
+Driver update profiles can be imported using the profile ID:
+
+` + "```shell" + `
+terraform import intune_windows_driver_update_profile.example 00000000-0000-0000-0000-000000000000
+` + "```" + `
+`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for the profile.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Description: "The policy type for use with policy assignments. Always 'driver_update' for this resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"display_name": schema.StringAttribute{
+				Description: "The display name of the profile.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the profile.",
+				Optional:    true,
+			},
+			"approval_type": schema.StringAttribute{
+				Description: "How drivers are approved. Valid values: manual, automatic. Changing it replaces the profile.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(driverApprovalManual, driverApprovalAutomatic),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"deployment_deferral_days": schema.Int64Attribute{
+				Description: "The days recommended drivers are deferred before they are approved, between 0 and 30. " +
+					"Automatic approval only.",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.Between(0, 30),
+				},
+			},
+			"role_scope_tag_ids": schema.ListAttribute{
+				Description: "List of scope tag IDs for this profile.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"created_date_time": schema.StringAttribute{
+				Description: "The date and time the profile was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_modified_date_time": schema.StringAttribute{
+				Description: "The date and time the profile was last modified.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"assignment": AssignmentBlockSchema(),
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *WindowsDriverUpdateProfileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+}
+
+// ValidateConfig checks that only automatic profiles defer their approvals
+func (r *WindowsDriverUpdateProfileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var approvalType types.String
+	var deferral types.Int64
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("approval_type"), &approvalType)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("deployment_deferral_days"), &deferral)...)
+	if resp.Diagnostics.HasError() || approvalType.IsUnknown() || deferral.IsNull() {
+		return
+	}
+
+	if approvalType.ValueString() != driverApprovalAutomatic {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("deployment_deferral_days"),
+			"Invalid Deployment Deferral",
+			"deployment_deferral_days is only supported with automatic approval, manually approved drivers are deployed on their approval date.",
+		)
+	}
+}
+
+// buildProfile converts the model into a driver update profile
+func (r *WindowsDriverUpdateProfileResource) buildProfile(ctx context.Context, data *WindowsDriverUpdateProfileResourceModel, diags *diag.Diagnostics) *clients.WindowsDriverUpdateProfile {
+	profile := &clients.WindowsDriverUpdateProfile{
+		DisplayName:              data.DisplayName.ValueString(),
+		Description:              data.Description.ValueString(),
+		ApprovalType:             data.ApprovalType.ValueString(),
+		DeploymentDeferralInDays: int(data.DeploymentDeferralDays.ValueInt64()),
+		RoleScopeTagIds:          []string{DefaultScopeTagID},
+	}
+
+	// Add role scope tag IDs if specified
+	if !data.RoleScopeTagIds.IsNull() {
+		var tagIds []string
+		diags.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		profile.RoleScopeTagIds = tagIds
+	}
+
+	return profile
+}
+
+// updateModel updates the Terraform model from the API profile. No deferral keeps an unset
+// deployment_deferral_days unset.
+func (r *WindowsDriverUpdateProfileResource) updateModel(ctx context.Context, data *WindowsDriverUpdateProfileResourceModel, profile *clients.WindowsDriverUpdateProfile, diags *diag.Diagnostics) {
+	data.Type = types.StringValue(PolicyTypeDriverUpdate)
+	data.DisplayName = types.StringValue(profile.DisplayName)
+	data.Description = optionalStringValue(data.Description, profile.Description)
+	data.ApprovalType = types.StringValue(profile.ApprovalType)
+	data.CreatedDateTime = types.StringValue(profile.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(profile.LastModifiedDateTime)
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, profile.RoleScopeTagIds, diags)
+
+	if profile.DeploymentDeferralInDays != 0 || !data.DeploymentDeferralDays.IsNull() {
+		data.DeploymentDeferralDays = types.Int64Value(int64(profile.DeploymentDeferralInDays))
+	}
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *WindowsDriverUpdateProfileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data WindowsDriverUpdateProfileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating Windows driver update profile", map[string]interface{}{
+		"display_name": data.DisplayName.ValueString(),
+	})
+
+	profile := r.buildProfile(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateWindowsDriverUpdateProfile(ctx, profile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Windows Driver Update Profile",
+			fmt.Sprintf("Could not create profile: %s", err),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(created.ID)
+	r.updateModel(ctx, &data, created, &resp.Diagnostics)
+
+	// Handle assignments if specified
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeDriverUpdate, created.ID, assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Assigning Profile",
+				fmt.Sprintf("Profile was created but assignment failed: %s", err),
+			)
+			return
+		}
+	}
+
+	tflog.Debug(ctx, "Created Windows driver update profile", map[string]interface{}{
+		"id": created.ID,
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data
+func (r *WindowsDriverUpdateProfileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data WindowsDriverUpdateProfileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Reading Windows driver update profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	// Get the profile, together with its assignments if the state had assignments configured
+	profilePath := fmt.Sprintf("%s/%s", clients.PathWindowsDriverUpdateProfiles, data.ID.ValueString())
+	result, err := readPolicyWithAssignments[clients.WindowsDriverUpdateProfile](ctx, r.client, PolicyTypeDriverUpdate, profilePath, data.ID.ValueString(), len(data.Assignment) > 0)
+	if err != nil {
+		// Check if the profile was deleted
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Windows Driver Update Profile",
+			fmt.Sprintf("Could not read profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, result.Policy, &resp.Diagnostics)
+
+	// Update assignments if the state had assignments configured
+	if len(data.Assignment) > 0 {
+		if result.AssignmentsErr != nil {
+			tflog.Warn(ctx, "Failed to read profile assignments", map[string]interface{}{
+				"error": result.AssignmentsErr.Error(),
+			})
+		} else {
+			data.Assignment = PreserveAssignments(data.Assignment, result.Assignments)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update updates the resource and sets the updated Terraform state
+func (r *WindowsDriverUpdateProfileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data WindowsDriverUpdateProfileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating Windows driver update profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	profile := r.buildProfile(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateWindowsDriverUpdateProfile(ctx, data.ID.ValueString(), profile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Windows Driver Update Profile",
+			fmt.Sprintf("Could not update profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, updated, &resp.Diagnostics)
+
+	// Handle assignments
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeDriverUpdate, data.ID.ValueString(), assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Profile Assignments",
+				fmt.Sprintf("Could not update assignments: %s", err),
+			)
+			return
+		}
+	} else {
+		// Clear assignments if none specified
+		if err := AssignPolicy(ctx, r.client, PolicyTypeDriverUpdate, data.ID.ValueString(), []clients.PolicyAssignment{}); err != nil {
+			tflog.Warn(ctx, "Failed to clear profile assignments", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete deletes the resource and removes the Terraform state
+func (r *WindowsDriverUpdateProfileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data WindowsDriverUpdateProfileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting Windows driver update profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	err := r.client.DeleteWindowsDriverUpdateProfile(ctx, data.ID.ValueString())
+	if err != nil {
+		// Ignore not found errors during delete
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Windows Driver Update Profile",
+			fmt.Sprintf("Could not delete profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the resource state
+func (r *WindowsDriverUpdateProfileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+// addDriverInventory gives a driver update profile the drivers Intune would report for its devices
+func addDriverInventory(env *testEnv, profileID string) {
+	env.graph.SetDriverInventories(profileID, []interface{}{
+		map[string]interface{}{
+			"id": "driver-display", "name": "Intel Graphics", "version": "31.0.101.5382", "manufacturer": "Intel",
+			"driverClass": "Display", "releaseDateTime": "2026-09-01T00:00:00Z", "applicableDeviceCount": 42,
+			"approvalStatus": "needsReview", "category": "recommended",
+		},
+		map[string]interface{}{
+			"id": "driver-firmware", "name": "System Firmware", "version": "1.24.0", "manufacturer": "Contoso",
+			"driverClass": "Firmware", "releaseDateTime": "2026-08-15T00:00:00Z", "applicableDeviceCount": 17,
+			"approvalStatus": "needsReview", "category": "recommended",
+		},
+		map[string]interface{}{
+			"id": "driver-audio", "name": "Realtek Audio", "version": "6.0.9600.1", "manufacturer": "Realtek",
+			"driverClass": "Media", "releaseDateTime": "2026-07-20T00:00:00Z", "applicableDeviceCount": 3,
+			"approvalStatus": "approved", "category": "previouslyApproved", "deployDateTime": "2026-08-01T00:00:00Z",
+		},
+	})
+}
+
+// driverApprovalStatuses returns the approval status of every driver of a profile by driver ID
+func driverApprovalStatuses(env *testEnv, profileID string) map[string]interface{} {
+	statuses := make(map[string]interface{})
+	for _, d := range env.graph.DriverInventories(profileID) {
+		driver := d.(map[string]interface{})
+		statuses[driver["id"].(string)] = driver["approvalStatus"]
+	}
+	return statuses
+}
+
+func TestAccWindowsDriverUpdateProfileResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Drivers - broad",
+		"approval_type": "automatic",
+		"deployment_deferral_days": 14,
+		"assignment": [{"target": [{"type": "all_devices"}]}]
+	}`
+	res := env.apply("intune_windows_driver_update_profile", nil, config)
+	assertAttr(t, res.attrs(), "type", PolicyTypeDriverUpdate)
+
+	profile := env.graph.Object(fakegraph.DriverUpdateProfiles, res.id())
+	assertAttr(t, profile, "approvalType", "automatic")
+	assertAttr(t, profile, "deploymentDeferralInDays", float64(14))
+	if n := len(env.graph.Assignments(fakegraph.DriverUpdateProfiles, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment in Graph, got %d", n)
+	}
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	updated := `{
+		"display_name": "Drivers - broad",
+		"description": "Recommended drivers after two weeks",
+		"approval_type": "automatic",
+		"deployment_deferral_days": 7
+	}`
+	res = env.apply("intune_windows_driver_update_profile", res, updated)
+	assertAttr(t, env.graph.Object(fakegraph.DriverUpdateProfiles, res.id()), "deploymentDeferralInDays", float64(7))
+	if n := len(env.graph.Assignments(fakegraph.DriverUpdateProfiles, res.id())); n != 0 {
+		t.Errorf("expected assignments to be removed, got %d", n)
+	}
+	env.assertNoOp(env.refresh(res), updated)
+
+	imported := env.importState("intune_windows_driver_update_profile", res.id())
+	assertAttr(t, imported.attrs(), "approval_type", "automatic")
+	assertAttr(t, imported.attrs(), "deployment_deferral_days", float64(7))
+
+	// The profile can be assigned through the generic assignment resource
+	assignment := env.apply("intune_policy_assignment", nil, fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "driver_update",
+		"target": [{"type": "all_users"}]
+	}`, res.id()))
+	if n := len(env.graph.Assignments(fakegraph.DriverUpdateProfiles, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment in Graph, got %d", n)
+	}
+	env.destroy(assignment)
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.DriverUpdateProfiles, res.id()) != nil {
+		t.Errorf("profile %s still exists after destroy", res.id())
+	}
+}
+
+func TestAccWindowsDriverUpdateProfileResource_manual(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{"display_name": "Drivers - pilot", "approval_type": "manual"}`
+	res := env.apply("intune_windows_driver_update_profile", nil, config)
+	assertAttr(t, res.attrs(), "deployment_deferral_days", nil)
+	env.assertNoOp(env.refresh(res), config)
+
+	imported := env.importState("intune_windows_driver_update_profile", res.id())
+	env.assertNoOp(imported, config)
+}
+
+func TestAccWindowsDriverUpdateProfileResource_invalid(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		config string
+		want   string
+	}{
+		{`{"display_name": "x", "approval_type": "scheduled"}`, "approval_type"},
+		{`{"display_name": "x", "approval_type": "manual", "deployment_deferral_days": 7}`, "only supported with automatic approval"},
+		{`{"display_name": "x", "approval_type": "automatic", "deployment_deferral_days": 31}`, "deployment_deferral_days"},
+	} {
+		if msg := env.applyExpectError("intune_windows_driver_update_profile", nil, tc.config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.config, tc.want, msg)
+		}
+	}
+}
@@ -0,0 +1,510 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &WindowsFeatureUpdateProfileResource{}
+var _ resource.ResourceWithImportState = &WindowsFeatureUpdateProfileResource{}
+var _ resource.ResourceWithValidateConfig = &WindowsFeatureUpdateProfileResource{}
+
+// featureUpdateVersionPattern matches feature update versions as Intune names them, e.g.
+// "Windows 11, version 23H2" or "Windows 10, version 2004"
+var featureUpdateVersionPattern = regexp.MustCompile(`^Windows (10|11), version (\d{4}|\d{2}H[12])$`)
+
+// NewWindowsFeatureUpdateProfileResource returns a new Windows feature update profile resource
+func NewWindowsFeatureUpdateProfileResource() resource.Resource {
+	return &WindowsFeatureUpdateProfileResource{}
+}
+
+// WindowsFeatureUpdateProfileResource defines the resource implementation
+type WindowsFeatureUpdateProfileResource struct {
+	client *clients.GraphClient
+}
+
+// WindowsFeatureUpdateProfileResourceModel describes the resource data model
+type WindowsFeatureUpdateProfileResourceModel struct {
+	ID                                                types.String           `tfsdk:"id"`
+	Type                                              types.String           `tfsdk:"type"`
+	DisplayName                                       types.String           `tfsdk:"display_name"`
+	Description                                       types.String           `tfsdk:"description"`
+	FeatureUpdateVersion                              types.String           `tfsdk:"feature_update_version"`
+	InstallLatestWindows10OnWindows11IneligibleDevice types.Bool             `tfsdk:"install_latest_windows10_on_windows11_ineligible_device"`
+	RoleScopeTagIds                                   types.List             `tfsdk:"role_scope_tag_ids"`
+	EndOfSupportDate                                  types.String           `tfsdk:"end_of_support_date"`
+	CreatedDateTime                                   types.String           `tfsdk:"created_date_time"`
+	LastModifiedDateTime                              types.String           `tfsdk:"last_modified_date_time"`
+	RolloutSettings                                   []RolloutSettingsModel `tfsdk:"rollout_settings"`
+	Assignment                                        []AssignmentModel      `tfsdk:"assignment"`
+}
+
+// RolloutSettingsModel represents a rollout_settings block
+type RolloutSettingsModel struct {
+	StartDateTime types.String `tfsdk:"start_date_time"`
+	EndDateTime   types.String `tfsdk:"end_date_time"`
+	IntervalDays  types.Int64  `tfsdk:"interval_days"`
+}
+
+// Metadata returns the resource type name
+func (r *WindowsFeatureUpdateProfileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_windows_feature_update_profile"
+}
+
+// Schema defines the schema for the resource
+func (r *WindowsFeatureUpdateProfileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages an Intune Windows feature update profile.",
+		MarkdownDescription: `
+Manages an Intune Windows feature update profile.
+
+Feature update profiles keep devices on a Windows feature update version until the profile is
+changed, regardless of the feature update deferral of their update ring. Without a
+` + "`rollout_settings`" + ` block the update is offered as soon as possible. With only a start the update is
+offered from that date; with an end and an interval it is offered to groups of devices every
+interval between start and end.
+
+## Example Usage
+
+` + "```hcl" + `
+resource "intune_windows_feature_update_profile" "win11" {
+  display_name           = "Windows 11 23H2"
+  feature_update_version = "Windows 11, version 23H2"
+
+  install_latest_windows10_on_windows11_ineligible_device = true
+
+  rollout_settings {
+    start_date_time = "2026-11-02T00:00:00Z"
+    end_date_time   = "2026-11-30T00:00:00Z"
+    interval_days   = 7
+  }
+
+  assignment {
+    target {
+      type     = "group"
+      group_id = azuread_group.pilot.object_id
+    }
+  }
+}
+` + "```" + `
+
+## Import
+
+Feature update profiles can be imported using the profile ID:
+
+` + "```shell" + `
+terraform import intune_windows_feature_update_profile.example 00000000-0000-0000-0000-000000000000
+` + "```" + `
+`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for the profile.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Description: "The policy type for use with policy assignments. Always 'feature_update' for this resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"display_name": schema.StringAttribute{
+				Description: "The display name of the profile.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the profile.",
+				Optional:    true,
+			},
+			"feature_update_version": schema.StringAttribute{
+				Description: "The feature update devices are kept on, e.g. Windows 11, version 23H2.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(featureUpdateVersionPattern, "must be a feature update version such as Windows 11, version 23H2"),
+				},
+			},
+			"install_latest_windows10_on_windows11_ineligible_device": schema.BoolAttribute{
+				Description: "Install the latest Windows 10 feature update on devices that cannot run Windows 11, " +
+					"when the profile offers Windows 11.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"role_scope_tag_ids": schema.ListAttribute{
+				Description: "List of scope tag IDs for this profile.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"end_of_support_date": schema.StringAttribute{
+				Description: "The date support for the feature update version ends.",
+				Computed:    true,
+			},
+			"created_date_time": schema.StringAttribute{
+				Description: "The date and time the profile was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_modified_date_time": schema.StringAttribute{
+				Description: "The date and time the profile was last modified.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"rollout_settings": schema.ListNestedBlock{
+				Description: "When the update is offered. Without this block the update is offered as soon as possible.",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"start_date_time": schema.StringAttribute{
+							Description: "The date and time the update is first offered, in RFC 3339 format.",
+							Required:    true,
+						},
+						"end_date_time": schema.StringAttribute{
+							Description: "The date and time the update is offered to the last devices of a gradual rollout, " +
+								"in RFC 3339 format. Requires interval_days.",
+							Optional: true,
+						},
+						"interval_days": schema.Int64Attribute{
+							Description: "The days between the groups of a gradual rollout. Requires end_date_time.",
+							Optional:    true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+					},
+				},
+			},
+			"assignment": AssignmentBlockSchema(),
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *WindowsFeatureUpdateProfileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+}
+
+// ValidateConfig checks that the rollout dates are RFC 3339 date-times, that a gradual rollout
+// sets both its end and interval, and that it ends after it starts
+func (r *WindowsFeatureUpdateProfileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var rollout []RolloutSettingsModel
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rollout_settings"), &rollout)...)
+	if resp.Diagnostics.HasError() || len(rollout) == 0 {
+		return
+	}
+
+	settings := rollout[0]
+	blockPath := path.Root("rollout_settings").AtListIndex(0)
+	start, startOK := dateTimeAttribute(settings.StartDateTime, blockPath.AtName("start_date_time"), &resp.Diagnostics)
+	end, endOK := dateTimeAttribute(settings.EndDateTime, blockPath.AtName("end_date_time"), &resp.Diagnostics)
+
+	if !settings.EndDateTime.IsUnknown() && !settings.IntervalDays.IsUnknown() && settings.EndDateTime.IsNull() != settings.IntervalDays.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			blockPath,
+			"Incomplete Gradual Rollout",
+			"A gradual rollout requires both end_date_time and interval_days.",
+		)
+	}
+
+	if startOK && endOK && !end.After(start) {
+		resp.Diagnostics.AddAttributeError(
+			blockPath.AtName("end_date_time"),
+			"Invalid Rollout Dates",
+			fmt.Sprintf("end_date_time %s must be after start_date_time %s.", settings.EndDateTime.ValueString(), settings.StartDateTime.ValueString()),
+		)
+	}
+}
+
+// buildProfile converts the model into a feature update profile
+func (r *WindowsFeatureUpdateProfileResource) buildProfile(ctx context.Context, data *WindowsFeatureUpdateProfileResourceModel, diags *diag.Diagnostics) *clients.WindowsFeatureUpdateProfile {
+	profile := &clients.WindowsFeatureUpdateProfile{
+		DisplayName:          data.DisplayName.ValueString(),
+		Description:          data.Description.ValueString(),
+		FeatureUpdateVersion: data.FeatureUpdateVersion.ValueString(),
+		InstallLatestWindows10OnWindows11IneligibleDevice: data.InstallLatestWindows10OnWindows11IneligibleDevice.ValueBool(),
+		RoleScopeTagIds: []string{DefaultScopeTagID},
+	}
+
+	// Add role scope tag IDs if specified
+	if !data.RoleScopeTagIds.IsNull() {
+		var tagIds []string
+		diags.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		profile.RoleScopeTagIds = tagIds
+	}
+
+	// Without rollout settings the update is offered as soon as possible
+	if len(data.RolloutSettings) > 0 {
+		settings := data.RolloutSettings[0]
+		profile.RolloutSettings = &clients.WindowsUpdateRolloutSettings{
+			OfferStartDateTimeInUTC: normalizeDateTime(settings.StartDateTime.ValueString()),
+			OfferIntervalInDays:     int(settings.IntervalDays.ValueInt64()),
+		}
+		if !settings.EndDateTime.IsNull() {
+			profile.RolloutSettings.OfferEndDateTimeInUTC = normalizeDateTime(settings.EndDateTime.ValueString())
+		}
+	}
+
+	return profile
+}
+
+// updateModel updates the Terraform model from the API profile
+func (r *WindowsFeatureUpdateProfileResource) updateModel(ctx context.Context, data *WindowsFeatureUpdateProfileResourceModel, profile *clients.WindowsFeatureUpdateProfile, diags *diag.Diagnostics) {
+	data.Type = types.StringValue(PolicyTypeFeatureUpdate)
+	data.DisplayName = types.StringValue(profile.DisplayName)
+	data.Description = optionalStringValue(data.Description, profile.Description)
+	data.FeatureUpdateVersion = types.StringValue(profile.FeatureUpdateVersion)
+	data.InstallLatestWindows10OnWindows11IneligibleDevice = types.BoolValue(profile.InstallLatestWindows10OnWindows11IneligibleDevice)
+	data.EndOfSupportDate = types.StringValue(profile.EndOfSupportDate)
+	data.CreatedDateTime = types.StringValue(profile.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(profile.LastModifiedDateTime)
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, profile.RoleScopeTagIds, diags)
+
+	// Graph returns empty rollout settings for profiles offered as soon as possible
+	if profile.RolloutSettings == nil || profile.RolloutSettings.OfferStartDateTimeInUTC == "" {
+		data.RolloutSettings = nil
+		return
+	}
+	prior := RolloutSettingsModel{
+		StartDateTime: types.StringNull(),
+		EndDateTime:   types.StringNull(),
+		IntervalDays:  types.Int64Null(),
+	}
+	if len(data.RolloutSettings) > 0 {
+		prior = data.RolloutSettings[0]
+	}
+	settings := RolloutSettingsModel{
+		StartDateTime: dateTimeValue(prior.StartDateTime, profile.RolloutSettings.OfferStartDateTimeInUTC),
+		EndDateTime:   dateTimeValue(prior.EndDateTime, profile.RolloutSettings.OfferEndDateTimeInUTC),
+		IntervalDays:  types.Int64Null(),
+	}
+	if profile.RolloutSettings.OfferIntervalInDays > 0 {
+		settings.IntervalDays = types.Int64Value(int64(profile.RolloutSettings.OfferIntervalInDays))
+	}
+	data.RolloutSettings = []RolloutSettingsModel{settings}
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *WindowsFeatureUpdateProfileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data WindowsFeatureUpdateProfileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating Windows feature update profile", map[string]interface{}{
+		"display_name": data.DisplayName.ValueString(),
+	})
+
+	profile := r.buildProfile(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateWindowsFeatureUpdateProfile(ctx, profile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Windows Feature Update Profile",
+			fmt.Sprintf("Could not create profile: %s", err),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(created.ID)
+	r.updateModel(ctx, &data, created, &resp.Diagnostics)
+
+	// Handle assignments if specified
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeFeatureUpdate, created.ID, assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Assigning Profile",
+				fmt.Sprintf("Profile was created but assignment failed: %s", err),
+			)
+			return
+		}
+	}
+
+	tflog.Debug(ctx, "Created Windows feature update profile", map[string]interface{}{
+		"id": created.ID,
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data
+func (r *WindowsFeatureUpdateProfileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data WindowsFeatureUpdateProfileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Reading Windows feature update profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	// Get the profile, together with its assignments if the state had assignments configured
+	profilePath := fmt.Sprintf("%s/%s", clients.PathWindowsFeatureUpdateProfiles, data.ID.ValueString())
+	result, err := readPolicyWithAssignments[clients.WindowsFeatureUpdateProfile](ctx, r.client, PolicyTypeFeatureUpdate, profilePath, data.ID.ValueString(), len(data.Assignment) > 0)
+	if err != nil {
+		// Check if the profile was deleted
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Windows Feature Update Profile",
+			fmt.Sprintf("Could not read profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, result.Policy, &resp.Diagnostics)
+
+	// Update assignments if the state had assignments configured
+	if len(data.Assignment) > 0 {
+		if result.AssignmentsErr != nil {
+			tflog.Warn(ctx, "Failed to read profile assignments", map[string]interface{}{
+				"error": result.AssignmentsErr.Error(),
+			})
+		} else {
+			data.Assignment = PreserveAssignments(data.Assignment, result.Assignments)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update updates the resource and sets the updated Terraform state
+func (r *WindowsFeatureUpdateProfileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data WindowsFeatureUpdateProfileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating Windows feature update profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	profile := r.buildProfile(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateWindowsFeatureUpdateProfile(ctx, data.ID.ValueString(), profile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Windows Feature Update Profile",
+			fmt.Sprintf("Could not update profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, updated, &resp.Diagnostics)
+
+	// Handle assignments
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeFeatureUpdate, data.ID.ValueString(), assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Profile Assignments",
+				fmt.Sprintf("Could not update assignments: %s", err),
+			)
+			return
+		}
+	} else {
+		// Clear assignments if none specified
+		if err := AssignPolicy(ctx, r.client, PolicyTypeFeatureUpdate, data.ID.ValueString(), []clients.PolicyAssignment{}); err != nil {
+			tflog.Warn(ctx, "Failed to clear profile assignments", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete deletes the resource and removes the Terraform state
+func (r *WindowsFeatureUpdateProfileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data WindowsFeatureUpdateProfileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting Windows feature update profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	err := r.client.DeleteWindowsFeatureUpdateProfile(ctx, data.ID.ValueString())
+	if err != nil {
+		// Ignore not found errors during delete
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Windows Feature Update Profile",
+			fmt.Sprintf("Could not delete profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the resource state
+func (r *WindowsFeatureUpdateProfileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+func TestAccWindowsFeatureUpdateProfileResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Windows 11 23H2",
+		"feature_update_version": "Windows 11, version 23H2",
+		"assignment": [{"target": [{"type": "all_devices"}]}]
+	}`
+	res := env.apply("intune_windows_feature_update_profile", nil, config)
+	assertAttr(t, res.attrs(), "type", PolicyTypeFeatureUpdate)
+	assertAttr(t, res.attrs(), "end_of_support_date", "2026-11-10T00:00:00Z")
+	assertAttr(t, res.attrs(), "install_latest_windows10_on_windows11_ineligible_device", false)
+
+	profile := env.graph.Object(fakegraph.FeatureUpdateProfiles, res.id())
+	assertAttr(t, profile, "featureUpdateVersion", "Windows 11, version 23H2")
+	if profile["rolloutSettings"] != nil {
+		t.Errorf("expected no rollout settings, got %v", profile["rolloutSettings"])
+	}
+	if n := len(env.graph.Assignments(fakegraph.FeatureUpdateProfiles, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment in Graph, got %d", n)
+	}
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	// A gradual rollout, with dates in another offset than Graph returns them
+	updated := `{
+		"display_name": "Windows 11 24H2",
+		"description": "Gradual rollout",
+		"feature_update_version": "Windows 11, version 24H2",
+		"install_latest_windows10_on_windows11_ineligible_device": true,
+		"rollout_settings": [{
+			"start_date_time": "2026-11-02T01:00:00+01:00",
+			"end_date_time": "2026-11-30T00:00:00Z",
+			"interval_days": 7
+		}]
+	}`
+	res = env.apply("intune_windows_feature_update_profile", res, updated)
+	profile = env.graph.Object(fakegraph.FeatureUpdateProfiles, res.id())
+	assertAttr(t, profile, "installLatestWindows10OnWindows11IneligibleDevice", true)
+	assertAttr(t, profile, "rolloutSettings", map[string]interface{}{
+		"offerStartDateTimeInUTC": "2026-11-02T00:00:00Z",
+		"offerEndDateTimeInUTC":   "2026-11-30T00:00:00Z",
+		"offerIntervalInDays":     float64(7),
+	})
+	assertAttr(t, res.attrs(), "end_of_support_date", "2027-10-12T00:00:00Z")
+	if n := len(env.graph.Assignments(fakegraph.FeatureUpdateProfiles, res.id())); n != 0 {
+		t.Errorf("expected assignments to be removed, got %d", n)
+	}
+	res = env.refresh(res)
+	env.assertNoOp(res, updated)
+
+	imported := env.importState("intune_windows_feature_update_profile", res.id())
+	assertAttr(t, imported.attrs(), "feature_update_version", "Windows 11, version 24H2")
+	env.assertNoOp(imported, `{
+		"display_name": "Windows 11 24H2",
+		"description": "Gradual rollout",
+		"feature_update_version": "Windows 11, version 24H2",
+		"install_latest_windows10_on_windows11_ineligible_device": true,
+		"rollout_settings": [{
+			"start_date_time": "2026-11-02T00:00:00Z",
+			"end_date_time": "2026-11-30T00:00:00Z",
+			"interval_days": 7
+		}]
+	}`)
+
+	// Removing the rollout settings offers the update as soon as possible
+	res = env.apply("intune_windows_feature_update_profile", res, config)
+	if rollout := env.graph.Object(fakegraph.FeatureUpdateProfiles, res.id())["rolloutSettings"]; rollout != nil {
+		t.Errorf("expected rollout settings to be removed, got %v", rollout)
+	}
+	env.assertNoOp(env.refresh(res), config)
+
+	// The profile can be assigned through the generic assignment resource
+	assignment := env.apply("intune_policy_assignment", nil, fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "feature_update",
+		"target": [{"type": "all_users"}]
+	}`, res.id()))
+	if n := len(env.graph.Assignments(fakegraph.FeatureUpdateProfiles, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment in Graph, got %d", n)
+	}
+	env.destroy(assignment)
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.FeatureUpdateProfiles, res.id()) != nil {
+		t.Errorf("profile %s still exists after destroy", res.id())
+	}
+}
+
+func TestAccWindowsFeatureUpdateProfileResource_invalid(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		config string
+		want   string
+	}{
+		{`{"display_name": "x", "feature_update_version": "23H2"}`, "feature_update_version"},
+		{`{"display_name": "x", "feature_update_version": "Windows 11, version 23H2", "rollout_settings": [{"start_date_time": "2026-11-02"}]}`, "RFC 3339"},
+		{`{"display_name": "x", "feature_update_version": "Windows 11, version 23H2", "rollout_settings": [{"start_date_time": "2026-11-02T00:00:00Z", "end_date_time": "2026-11-30T00:00:00Z"}]}`, "interval_days"},
+		{`{"display_name": "x", "feature_update_version": "Windows 11, version 23H2", "rollout_settings": [{"start_date_time": "2026-11-02T00:00:00Z", "interval_days": 7}]}`, "end_date_time"},
+		{`{"display_name": "x", "feature_update_version": "Windows 11, version 23H2", "rollout_settings": [{"start_date_time": "2026-11-30T00:00:00Z", "end_date_time": "2026-11-02T00:00:00Z", "interval_days": 7}]}`, "must be after"},
+		{`{"display_name": "x", "feature_update_version": "Windows 11, version 23H2", "rollout_settings": [{"start_date_time": "2026-11-02T00:00:00Z", "end_date_time": "2026-11-30T00:00:00Z", "interval_days": 0}]}`, "interval_days"},
+	} {
+		if msg := env.applyExpectError("intune_windows_feature_update_profile", nil, tc.config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.config, tc.want, msg)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &WindowsQualityUpdateProfileResource{}
+var _ resource.ResourceWithImportState = &WindowsQualityUpdateProfileResource{}
+var _ resource.ResourceWithValidateConfig = &WindowsQualityUpdateProfileResource{}
+
+// NewWindowsQualityUpdateProfileResource returns a new Windows quality update profile resource
+func NewWindowsQualityUpdateProfileResource() resource.Resource {
+	return &WindowsQualityUpdateProfileResource{}
+}
+
+// WindowsQualityUpdateProfileResource defines the resource implementation
+type WindowsQualityUpdateProfileResource struct {
+	client *clients.GraphClient
+}
+
+// WindowsQualityUpdateProfileResourceModel describes the resource data model
+type WindowsQualityUpdateProfileResourceModel struct {
+	ID                           types.String      `tfsdk:"id"`
+	Type                         types.String      `tfsdk:"type"`
+	DisplayName                  types.String      `tfsdk:"display_name"`
+	Description                  types.String      `tfsdk:"description"`
+	QualityUpdateRelease         types.String      `tfsdk:"quality_update_release"`
+	DaysUntilForcedReboot        types.Int64       `tfsdk:"days_until_forced_reboot"`
+	RoleScopeTagIds              types.List        `tfsdk:"role_scope_tag_ids"`
+	ReleaseDateDisplayText       types.String      `tfsdk:"release_date_display_text"`
+	DeployableContentDisplayName types.String      `tfsdk:"deployable_content_display_name"`
+	CreatedDateTime              types.String      `tfsdk:"created_date_time"`
+	LastModifiedDateTime         types.String      `tfsdk:"last_modified_date_time"`
+	Assignment                   []AssignmentModel `tfsdk:"assignment"`
+}
+
+// Metadata returns the resource type name
+func (r *WindowsQualityUpdateProfileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_windows_quality_update_profile"
+}
+
+// Schema defines the schema for the resource
+func (r *WindowsQualityUpdateProfileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages an Intune Windows quality update profile that expedites a quality update.",
+		MarkdownDescription: `
+Manages an Intune Windows quality update profile.
+
+Quality update profiles expedite a quality update release: devices below the release install it
+as soon as possible, regardless of the quality update deferral of their update ring, and restart
+at the latest after ` + "`days_until_forced_reboot`" + ` days.
+
+## Example Usage
+
+` + "```hcl" + `
+resource "intune_windows_quality_update_profile" "october" {
+  display_name             = "Expedite October 2026"
+  quality_update_release   = "2026-10-13T00:00:00Z"
+  days_until_forced_reboot = 1
+
+  assignment {
+    target {
+      type = "all_devices"
+    }
+  }
+}
+` + "```" + `
+
+## Import
+
+Quality update profiles can be imported using the profile ID:
+
+` + "```shell" + `
+terraform import intune_windows_quality_update_profile.example 00000000-0000-0000-0000-000000000000
+` + "```" + `
+`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for the profile.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Description: "The policy type for use with policy assignments. Always 'quality_update' for this resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"display_name": schema.StringAttribute{
+				Description: "The display name of the profile.",
+				Required:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "The description of the profile.",
+				Optional:    true,
+			},
+			"quality_update_release": schema.StringAttribute{
+				Description: "The release date of the quality update to expedite in RFC 3339 format, e.g. 2026-10-13T00:00:00Z. " +
+					"Devices below this release install it.",
+				Required: true,
+			},
+			"days_until_forced_reboot": schema.Int64Attribute{
+				Description: "The days devices may wait before they restart to install the update, between 0 and 2. Defaults to 1.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(1),
+				Validators: []validator.Int64{
+					int64validator.Between(0, 2),
+				},
+			},
+			"role_scope_tag_ids": schema.ListAttribute{
+				Description: "List of scope tag IDs for this profile.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"release_date_display_text": schema.StringAttribute{
+				Description: "The release date of the expedited update as shown in the Intune portal.",
+				Computed:    true,
+			},
+			"deployable_content_display_name": schema.StringAttribute{
+				Description: "The name of the expedited update.",
+				Computed:    true,
+			},
+			"created_date_time": schema.StringAttribute{
+				Description: "The date and time the profile was created.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_modified_date_time": schema.StringAttribute{
+				Description: "The date and time the profile was last modified.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"assignment": AssignmentBlockSchema(),
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource
+func (r *WindowsQualityUpdateProfileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.client = providerData.GraphClient
+}
+
+// ValidateConfig checks that the quality update release is an RFC 3339 date-time
+func (r *WindowsQualityUpdateProfileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var release types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("quality_update_release"), &release)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	dateTimeAttribute(release, path.Root("quality_update_release"), &resp.Diagnostics)
+}
+
+// buildProfile converts the model into a quality update profile
+func (r *WindowsQualityUpdateProfileResource) buildProfile(ctx context.Context, data *WindowsQualityUpdateProfileResourceModel, diags *diag.Diagnostics) *clients.WindowsQualityUpdateProfile {
+	profile := &clients.WindowsQualityUpdateProfile{
+		DisplayName: data.DisplayName.ValueString(),
+		Description: data.Description.ValueString(),
+		ExpeditedUpdateSettings: &clients.ExpeditedWindowsQualityUpdateSettings{
+			QualityUpdateRelease:  normalizeDateTime(data.QualityUpdateRelease.ValueString()),
+			DaysUntilForcedReboot: int(data.DaysUntilForcedReboot.ValueInt64()),
+		},
+		RoleScopeTagIds: []string{DefaultScopeTagID},
+	}
+
+	// Add role scope tag IDs if specified
+	if !data.RoleScopeTagIds.IsNull() {
+		var tagIds []string
+		diags.Append(data.RoleScopeTagIds.ElementsAs(ctx, &tagIds, false)...)
+		profile.RoleScopeTagIds = tagIds
+	}
+
+	return profile
+}
+
+// updateModel updates the Terraform model from the API profile
+func (r *WindowsQualityUpdateProfileResource) updateModel(ctx context.Context, data *WindowsQualityUpdateProfileResourceModel, profile *clients.WindowsQualityUpdateProfile, diags *diag.Diagnostics) {
+	data.Type = types.StringValue(PolicyTypeQualityUpdate)
+	data.DisplayName = types.StringValue(profile.DisplayName)
+	data.Description = optionalStringValue(data.Description, profile.Description)
+	data.ReleaseDateDisplayText = types.StringValue(profile.ReleaseDateDisplayText)
+	data.DeployableContentDisplayName = types.StringValue(profile.DeployableContentDisplayName)
+	data.CreatedDateTime = types.StringValue(profile.CreatedDateTime)
+	data.LastModifiedDateTime = types.StringValue(profile.LastModifiedDateTime)
+	data.RoleScopeTagIds = roleScopeTagIdsValue(ctx, data.RoleScopeTagIds, profile.RoleScopeTagIds, diags)
+
+	if settings := profile.ExpeditedUpdateSettings; settings != nil {
+		data.QualityUpdateRelease = dateTimeValue(data.QualityUpdateRelease, settings.QualityUpdateRelease)
+		data.DaysUntilForcedReboot = types.Int64Value(int64(settings.DaysUntilForcedReboot))
+	}
+}
+
+// Create creates the resource and sets the initial Terraform state
+func (r *WindowsQualityUpdateProfileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data WindowsQualityUpdateProfileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Creating Windows quality update profile", map[string]interface{}{
+		"display_name": data.DisplayName.ValueString(),
+	})
+
+	profile := r.buildProfile(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateWindowsQualityUpdateProfile(ctx, profile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Windows Quality Update Profile",
+			fmt.Sprintf("Could not create profile: %s", err),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(created.ID)
+	r.updateModel(ctx, &data, created, &resp.Diagnostics)
+
+	// Handle assignments if specified
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeQualityUpdate, created.ID, assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Assigning Profile",
+				fmt.Sprintf("Profile was created but assignment failed: %s", err),
+			)
+			return
+		}
+	}
+
+	tflog.Debug(ctx, "Created Windows quality update profile", map[string]interface{}{
+		"id": created.ID,
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Read refreshes the Terraform state with the latest data
+func (r *WindowsQualityUpdateProfileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data WindowsQualityUpdateProfileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Reading Windows quality update profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	// Get the profile, together with its assignments if the state had assignments configured
+	profilePath := fmt.Sprintf("%s/%s", clients.PathWindowsQualityUpdateProfiles, data.ID.ValueString())
+	result, err := readPolicyWithAssignments[clients.WindowsQualityUpdateProfile](ctx, r.client, PolicyTypeQualityUpdate, profilePath, data.ID.ValueString(), len(data.Assignment) > 0)
+	if err != nil {
+		// Check if the profile was deleted
+		if clients.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading Windows Quality Update Profile",
+			fmt.Sprintf("Could not read profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, result.Policy, &resp.Diagnostics)
+
+	// Update assignments if the state had assignments configured
+	if len(data.Assignment) > 0 {
+		if result.AssignmentsErr != nil {
+			tflog.Warn(ctx, "Failed to read profile assignments", map[string]interface{}{
+				"error": result.AssignmentsErr.Error(),
+			})
+		} else {
+			data.Assignment = PreserveAssignments(data.Assignment, result.Assignments)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Update updates the resource and sets the updated Terraform state
+func (r *WindowsQualityUpdateProfileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data WindowsQualityUpdateProfileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating Windows quality update profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	profile := r.buildProfile(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateWindowsQualityUpdateProfile(ctx, data.ID.ValueString(), profile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Windows Quality Update Profile",
+			fmt.Sprintf("Could not update profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+
+	r.updateModel(ctx, &data, updated, &resp.Diagnostics)
+
+	// Handle assignments
+	if len(data.Assignment) > 0 {
+		assignments := BuildAssignmentsFromBlocks(ctx, data.Assignment, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if err := AssignPolicy(ctx, r.client, PolicyTypeQualityUpdate, data.ID.ValueString(), assignments); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Profile Assignments",
+				fmt.Sprintf("Could not update assignments: %s", err),
+			)
+			return
+		}
+	} else {
+		// Clear assignments if none specified
+		if err := AssignPolicy(ctx, r.client, PolicyTypeQualityUpdate, data.ID.ValueString(), []clients.PolicyAssignment{}); err != nil {
+			tflog.Warn(ctx, "Failed to clear profile assignments", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Delete deletes the resource and removes the Terraform state
+func (r *WindowsQualityUpdateProfileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data WindowsQualityUpdateProfileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Deleting Windows quality update profile", map[string]interface{}{
+		"id": data.ID.ValueString(),
+	})
+
+	err := r.client.DeleteWindowsQualityUpdateProfile(ctx, data.ID.ValueString())
+	if err != nil {
+		// Ignore not found errors during delete
+		if clients.IsNotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Windows Quality Update Profile",
+			fmt.Sprintf("Could not delete profile ID %s: %s", data.ID.ValueString(), err),
+		)
+		return
+	}
+}
+
+// ImportState imports the resource state
+func (r *WindowsQualityUpdateProfileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MANCHTOOLS/tofutune/internal/fakegraph"
+)
+
+func TestAccWindowsQualityUpdateProfileResource(t *testing.T) {
+	env := newTestEnv(t)
+
+	config := `{
+		"display_name": "Expedite October 2026",
+		"quality_update_release": "2026-10-13T00:00:00Z",
+		"assignment": [{"target": [{"type": "all_devices"}]}]
+	}`
+	res := env.apply("intune_windows_quality_update_profile", nil, config)
+	assertAttr(t, res.attrs(), "type", PolicyTypeQualityUpdate)
+	assertAttr(t, res.attrs(), "days_until_forced_reboot", float64(1))
+	assertAttr(t, res.attrs(), "deployable_content_display_name", "2026.10 B Security Updates")
+
+	profile := env.graph.Object(fakegraph.QualityUpdateProfiles, res.id())
+	assertAttr(t, profile, "expeditedUpdateSettings", map[string]interface{}{
+		"qualityUpdateRelease":  "2026-10-13T00:00:00Z",
+		"daysUntilForcedReboot": float64(1),
+	})
+	if n := len(env.graph.Assignments(fakegraph.QualityUpdateProfiles, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment in Graph, got %d", n)
+	}
+
+	res = env.refresh(res)
+	env.assertNoOp(res, config)
+
+	// The release is sent in UTC and keeps its configured spelling
+	updated := `{
+		"display_name": "Expedite November 2026",
+		"description": "Emergency patch",
+		"quality_update_release": "2026-11-10T02:00:00+02:00",
+		"days_until_forced_reboot": 0
+	}`
+	res = env.apply("intune_windows_quality_update_profile", res, updated)
+	assertAttr(t, env.graph.Object(fakegraph.QualityUpdateProfiles, res.id()), "expeditedUpdateSettings", map[string]interface{}{
+		"qualityUpdateRelease":  "2026-11-10T00:00:00Z",
+		"daysUntilForcedReboot": float64(0),
+	})
+	assertAttr(t, res.attrs(), "quality_update_release", "2026-11-10T02:00:00+02:00")
+	if n := len(env.graph.Assignments(fakegraph.QualityUpdateProfiles, res.id())); n != 0 {
+		t.Errorf("expected assignments to be removed, got %d", n)
+	}
+	env.assertNoOp(env.refresh(res), updated)
+
+	imported := env.importState("intune_windows_quality_update_profile", res.id())
+	assertAttr(t, imported.attrs(), "quality_update_release", "2026-11-10T00:00:00Z")
+	assertAttr(t, imported.attrs(), "days_until_forced_reboot", float64(0))
+
+	// The profile can be assigned through the generic assignment resource
+	assignment := env.apply("intune_policy_assignment", nil, fmt.Sprintf(`{
+		"policy_id": %q,
+		"policy_type": "quality_update",
+		"target": [{"type": "all_users"}]
+	}`, res.id()))
+	if n := len(env.graph.Assignments(fakegraph.QualityUpdateProfiles, res.id())); n != 1 {
+		t.Errorf("expected 1 assignment in Graph, got %d", n)
+	}
+	env.destroy(assignment)
+
+	env.destroy(res)
+	if env.graph.Object(fakegraph.QualityUpdateProfiles, res.id()) != nil {
+		t.Errorf("profile %s still exists after destroy", res.id())
+	}
+}
+
+func TestAccWindowsQualityUpdateProfileResource_invalid(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, tc := range []struct {
+		config string
+		want   string
+	}{
+		{`{"display_name": "x", "quality_update_release": "October 2026"}`, "RFC 3339"},
+		{`{"display_name": "x", "quality_update_release": "2026-10-13T00:00:00Z", "days_until_forced_reboot": 3}`, "days_until_forced_reboot"},
+	} {
+		if msg := env.applyExpectError("intune_windows_quality_update_profile", nil, tc.config); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s: expected %q, got: %s", tc.config, tc.want, msg)
+		}
+	}
+}
@@ -0,0 +1,447 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// defaultComplianceRule is the rule scheduled actions apply to
+const defaultComplianceRule = "DeviceNotCompliant"
+
+// noNotificationTemplate is the template ID Graph reports for actions without a notification template
+const noNotificationTemplate = "00000000-0000-0000-0000-000000000000"
+
+// scheduledActionTypes are the actions Intune can take on non-compliant devices. block marks the
+// device non-compliant, which every rule needs exactly once.
+var scheduledActionTypes = []string{"block", "notification", "pushNotification", "remoteLock", "retire", "wipe", "removeResourceAccessProfiles"}
+
+// maxGracePeriodHours is the longest grace period Intune accepts, one year
+const maxGracePeriodHours = 8760
+
+// ScheduledActionModel represents scheduled action configuration
+type ScheduledActionModel struct {
+	RuleName                      types.String `tfsdk:"rule_name"`
+	ScheduledActionConfigurations types.List   `tfsdk:"scheduled_action_configurations"`
+}
+
+// ScheduledActionConfigurationModel represents action configuration
+type ScheduledActionConfigurationModel struct {
+	ID                        types.String `tfsdk:"id"`
+	ActionType                types.String `tfsdk:"action_type"`
+	GracePeriodHours          types.Int64  `tfsdk:"grace_period_hours"`
+	NotificationTemplateId    types.String `tfsdk:"notification_template_id"`
+	NotificationMessageCCList types.List   `tfsdk:"notification_message_cc_list"`
+}
+
+// scheduledActionConfigurationAttrTypes are the attribute types of a scheduled_action_configurations block
+var scheduledActionConfigurationAttrTypes = map[string]attr.Type{
+	"id":                           types.StringType,
+	"action_type":                  types.StringType,
+	"grace_period_hours":           types.Int64Type,
+	"notification_template_id":     types.StringType,
+	"notification_message_cc_list": types.ListType{ElemType: types.StringType},
+}
+
+// scheduledActionAttrTypes are the attribute types of a scheduled_actions_for_rule block
+var scheduledActionAttrTypes = map[string]attr.Type{
+	"rule_name": types.StringType,
+	"scheduled_action_configurations": types.ListType{
+		ElemType: types.ObjectType{AttrTypes: scheduledActionConfigurationAttrTypes},
+	},
+}
+
+// ScheduledActionsBlockSchema returns the schema for the scheduled_actions_for_rule blocks of
+// compliance policies
+func ScheduledActionsBlockSchema() schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		Description: "Scheduled actions for non-compliance.",
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"rule_name": schema.StringAttribute{
+					Description: "The rule name. Use 'DeviceNotCompliant' for the default rule.",
+					Optional:    true,
+					Computed:    true,
+					Default:     stringdefault.StaticString(defaultComplianceRule),
+				},
+			},
+			Blocks: map[string]schema.Block{
+				"scheduled_action_configurations": schema.ListNestedBlock{
+					Description: "Action configurations for non-compliance. Exactly one action must be block.",
+					Validators: []validator.List{
+						listvalidator.SizeAtLeast(1),
+					},
+					NestedObject: schema.NestedBlockObject{
+						Attributes: map[string]schema.Attribute{
+							"id": schema.StringAttribute{
+								Description: "The ID Graph assigned to the action.",
+								Computed:    true,
+							},
+							"action_type": schema.StringAttribute{
+								Description: "The action type. Valid values: block (mark the device non-compliant), notification (send an email), " +
+									"pushNotification, remoteLock, retire, wipe, removeResourceAccessProfiles.",
+								Required: true,
+								Validators: []validator.String{
+									stringvalidator.OneOf(scheduledActionTypes...),
+								},
+							},
+							"grace_period_hours": schema.Int64Attribute{
+								Description: "Number of hours before the action is enforced. 0 for immediate, at most 8760.",
+								Optional:    true,
+								Computed:    true,
+								Default:     int64default.StaticInt64(0),
+								Validators: []validator.Int64{
+									int64validator.Between(0, maxGracePeriodHours),
+								},
+							},
+							"notification_template_id": schema.StringAttribute{
+								Description: "The ID of the intune_notification_message_template to send. The template must exist. " +
+									"Required for notification actions.",
+								Optional: true,
+							},
+							"notification_message_cc_list": schema.ListAttribute{
+								Description: "The IDs of the groups that receive a copy of the notification email. Only for notification actions.",
+								Optional:    true,
+								ElementType: types.StringType,
+								Validators: []validator.List{
+									listvalidator.UniqueValues(),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// BuildScheduledActions converts scheduled_actions_for_rule blocks into the scheduled actions of a
+// compliance policy. Without blocks, devices are marked non-compliant immediately.
+func BuildScheduledActions(ctx context.Context, list types.List, diags *diag.Diagnostics) []clients.ComplianceScheduledAction {
+	var rules []ScheduledActionModel
+	diags.Append(list.ElementsAs(ctx, &rules, false)...)
+	if len(rules) == 0 {
+		return []clients.ComplianceScheduledAction{
+			{
+				RuleName: defaultComplianceRule,
+				ScheduledActionConfigurations: []clients.ScheduledActionConfiguration{
+					{
+						ActionType:       "block",
+						GracePeriodHours: 0,
+					},
+				},
+			},
+		}
+	}
+
+	actions := make([]clients.ComplianceScheduledAction, 0, len(rules))
+	for _, rule := range rules {
+		var configs []ScheduledActionConfigurationModel
+		diags.Append(rule.ScheduledActionConfigurations.ElementsAs(ctx, &configs, false)...)
+
+		action := clients.ComplianceScheduledAction{
+			RuleName:                      rule.RuleName.ValueString(),
+			ScheduledActionConfigurations: make([]clients.ScheduledActionConfiguration, 0, len(configs)),
+		}
+		for _, config := range configs {
+			var ccList []string
+			if len(config.NotificationMessageCCList.Elements()) > 0 {
+				diags.Append(config.NotificationMessageCCList.ElementsAs(ctx, &ccList, false)...)
+			}
+			action.ScheduledActionConfigurations = append(action.ScheduledActionConfigurations, clients.ScheduledActionConfiguration{
+				ActionType:                config.ActionType.ValueString(),
+				GracePeriodHours:          int(config.GracePeriodHours.ValueInt64()),
+				NotificationTemplateId:    config.NotificationTemplateId.ValueString(),
+				NotificationMessageCCList: ccList,
+			})
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// ValidateNotificationTemplates checks that the notification templates referenced by
+// scheduled_actions_for_rule blocks exist. Template IDs that are not known yet are skipped.
+func ValidateNotificationTemplates(ctx context.Context, client *clients.GraphClient, list types.List, diags *diag.Diagnostics) {
+	if client == nil || list.IsUnknown() {
+		return
+	}
+
+	var rules []ScheduledActionModel
+	diags.Append(list.ElementsAs(ctx, &rules, false)...)
+	checked := make(map[string]bool)
+	for _, rule := range rules {
+		if rule.ScheduledActionConfigurations.IsUnknown() {
+			continue
+		}
+		var configs []ScheduledActionConfigurationModel
+		diags.Append(rule.ScheduledActionConfigurations.ElementsAs(ctx, &configs, false)...)
+		for _, config := range configs {
+			id := config.NotificationTemplateId.ValueString()
+			if config.NotificationTemplateId.IsUnknown() || id == "" || id == noNotificationTemplate || checked[id] {
+				continue
+			}
+			checked[id] = true
+
+			if _, err := client.GetNotificationMessageTemplate(ctx, id); err != nil {
+				if clients.IsNotFound(err) {
+					diags.AddAttributeError(
+						path.Root("scheduled_actions_for_rule"),
+						"Invalid Notification Template",
+						fmt.Sprintf("Notification message template %s does not exist.", id),
+					)
+					continue
+				}
+				diags.AddError(
+					"Error Reading Notification Message Template",
+					fmt.Sprintf("Could not read notification message template ID %s: %s", id, err),
+				)
+			}
+		}
+	}
+}
+
+// ValidateScheduledActions checks scheduled_actions_for_rule blocks the way Intune does: every
+// policy marks devices non-compliant with exactly one block action, emails need a template and
+// only emails have copy recipients.
+func ValidateScheduledActions(ctx context.Context, list types.List, diags *diag.Diagnostics) {
+	if list.IsUnknown() || len(list.Elements()) == 0 {
+		return
+	}
+
+	var rules []ScheduledActionModel
+	diags.Append(list.ElementsAs(ctx, &rules, false)...)
+	blocks := 0
+	for _, rule := range rules {
+		if rule.ScheduledActionConfigurations.IsUnknown() {
+			return
+		}
+		var configs []ScheduledActionConfigurationModel
+		diags.Append(rule.ScheduledActionConfigurations.ElementsAs(ctx, &configs, false)...)
+		for _, config := range configs {
+			if config.ActionType.IsUnknown() {
+				return
+			}
+			actionType := config.ActionType.ValueString()
+			if actionType == "block" {
+				blocks++
+			}
+			if actionType == "notification" && config.NotificationTemplateId.IsNull() {
+				diags.AddAttributeError(
+					path.Root("scheduled_actions_for_rule"),
+					"Missing Notification Template",
+					"Scheduled actions of type notification need a notification_template_id.",
+				)
+			}
+			if actionType != "notification" && len(config.NotificationMessageCCList.Elements()) > 0 {
+				diags.AddAttributeError(
+					path.Root("scheduled_actions_for_rule"),
+					"Invalid Notification Recipients",
+					fmt.Sprintf("notification_message_cc_list is only supported by notification actions, not by %s actions.", actionType),
+				)
+			}
+		}
+	}
+
+	if blocks != 1 {
+		diags.AddAttributeError(
+			path.Root("scheduled_actions_for_rule"),
+			"Invalid Scheduled Actions",
+			fmt.Sprintf("Exactly one scheduled action must be of type block, which marks devices non-compliant, found %d.", blocks),
+		)
+	}
+}
+
+// ScheduledActionsValue converts the scheduled actions of a compliance policy into
+// scheduled_actions_for_rule blocks. Actions are matched to the prior blocks by ID, or by their
+// settings when the ID is not known yet, so that the order Graph returns them in does not cause
+// drift. The default action of policies without blocks is not shown.
+func ScheduledActionsValue(ctx context.Context, prior types.List, actions []clients.ComplianceScheduledAction, diags *diag.Diagnostics) types.List {
+	if len(prior.Elements()) == 0 && isDefaultScheduledActions(actions) {
+		return prior
+	}
+
+	var priorRules []ScheduledActionModel
+	if len(prior.Elements()) > 0 {
+		diags.Append(prior.ElementsAs(ctx, &priorRules, false)...)
+	}
+
+	configurationsType := types.ObjectType{AttrTypes: scheduledActionConfigurationAttrTypes}
+	matched := make([]bool, len(actions))
+	rules := make([]ScheduledActionModel, 0, len(actions))
+	for _, priorRule := range priorRules {
+		for i, action := range actions {
+			if matched[i] || scheduledActionRuleName(action) != priorRule.RuleName.ValueString() {
+				continue
+			}
+			matched[i] = true
+			rules = append(rules, ScheduledActionModel{
+				RuleName:                      priorRule.RuleName,
+				ScheduledActionConfigurations: scheduledActionConfigurationsValue(ctx, priorRule.ScheduledActionConfigurations, action.ScheduledActionConfigurations, diags),
+			})
+			break
+		}
+	}
+	for i, action := range actions {
+		if matched[i] {
+			continue
+		}
+		rules = append(rules, ScheduledActionModel{
+			RuleName:                      types.StringValue(scheduledActionRuleName(action)),
+			ScheduledActionConfigurations: scheduledActionConfigurationsValue(ctx, types.ListNull(configurationsType), action.ScheduledActionConfigurations, diags),
+		})
+	}
+
+	value, d := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: scheduledActionAttrTypes}, rules)
+	diags.Append(d...)
+	return value
+}
+
+// scheduledActionConfigurationsValue converts the configurations of a scheduled action, keeping
+// the order of the prior configurations and appending configurations that are new in Graph
+func scheduledActionConfigurationsValue(ctx context.Context, prior types.List, configs []clients.ScheduledActionConfiguration, diags *diag.Diagnostics) types.List {
+	var priorConfigs []ScheduledActionConfigurationModel
+	if len(prior.Elements()) > 0 {
+		diags.Append(prior.ElementsAs(ctx, &priorConfigs, false)...)
+	}
+
+	matched := make([]bool, len(configs))
+	find := func(same func(clients.ScheduledActionConfiguration) bool) int {
+		for i, config := range configs {
+			if !matched[i] && same(config) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	models := make([]ScheduledActionConfigurationModel, 0, len(configs))
+	for _, p := range priorConfigs {
+		i := -1
+		if id := p.ID.ValueString(); id != "" {
+			i = find(func(config clients.ScheduledActionConfiguration) bool { return config.ID == id })
+		}
+		if i < 0 {
+			i = find(func(config clients.ScheduledActionConfiguration) bool {
+				return sameScheduledActionConfiguration(ctx, p, config)
+			})
+		}
+		if i < 0 {
+			continue
+		}
+		matched[i] = true
+		models = append(models, scheduledActionConfigurationModel(ctx, &p, configs[i], diags))
+	}
+	for i, config := range configs {
+		if !matched[i] {
+			models = append(models, scheduledActionConfigurationModel(ctx, nil, config, diags))
+		}
+	}
+
+	value, d := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: scheduledActionConfigurationAttrTypes}, models)
+	diags.Append(d...)
+	return value
+}
+
+// scheduledActionConfigurationModel converts a scheduled action configuration, keeping how the
+// prior configuration spelled unset values
+func scheduledActionConfigurationModel(ctx context.Context, prior *ScheduledActionConfigurationModel, config clients.ScheduledActionConfiguration, diags *diag.Diagnostics) ScheduledActionConfigurationModel {
+	model := ScheduledActionConfigurationModel{
+		ID:                        types.StringValue(config.ID),
+		ActionType:                types.StringValue(config.ActionType),
+		GracePeriodHours:          types.Int64Value(int64(config.GracePeriodHours)),
+		NotificationTemplateId:    types.StringNull(),
+		NotificationMessageCCList: types.ListNull(types.StringType),
+	}
+
+	if template := scheduledActionTemplate(config.NotificationTemplateId); template != "" {
+		model.NotificationTemplateId = types.StringValue(template)
+	} else if prior != nil && prior.NotificationTemplateId.ValueString() == noNotificationTemplate {
+		model.NotificationTemplateId = prior.NotificationTemplateId
+	}
+
+	priorCCList := types.ListNull(types.StringType)
+	if prior != nil {
+		priorCCList = prior.NotificationMessageCCList
+	}
+	if len(config.NotificationMessageCCList) > 0 {
+		model.NotificationMessageCCList = preserveStringOrder(ctx, priorCCList, config.NotificationMessageCCList, diags)
+	} else if !priorCCList.IsNull() && !priorCCList.IsUnknown() {
+		model.NotificationMessageCCList = priorCCList
+	}
+	return model
+}
+
+// sameScheduledActionConfiguration reports whether a configuration block has the settings of a
+// scheduled action configuration in Graph
+func sameScheduledActionConfiguration(ctx context.Context, model ScheduledActionConfigurationModel, config clients.ScheduledActionConfiguration) bool {
+	if model.ActionType.ValueString() != config.ActionType ||
+		model.GracePeriodHours.ValueInt64() != int64(config.GracePeriodHours) ||
+		scheduledActionTemplate(model.NotificationTemplateId.ValueString()) != scheduledActionTemplate(config.NotificationTemplateId) {
+		return false
+	}
+
+	var ccList []string
+	if len(model.NotificationMessageCCList.Elements()) > 0 {
+		if d := model.NotificationMessageCCList.ElementsAs(ctx, &ccList, false); d.HasError() {
+			return false
+		}
+	}
+	if len(ccList) != len(config.NotificationMessageCCList) {
+		return false
+	}
+	for _, id := range ccList {
+		if !containsString(config.NotificationMessageCCList, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// isDefaultScheduledActions reports whether scheduled actions are the ones BuildScheduledActions
+// creates for policies without scheduled_actions_for_rule blocks
+func isDefaultScheduledActions(actions []clients.ComplianceScheduledAction) bool {
+	if len(actions) == 0 {
+		return true
+	}
+	if len(actions) > 1 || scheduledActionRuleName(actions[0]) != defaultComplianceRule || len(actions[0].ScheduledActionConfigurations) != 1 {
+		return false
+	}
+	config := actions[0].ScheduledActionConfigurations[0]
+	return config.ActionType == "block" && config.GracePeriodHours == 0 &&
+		scheduledActionTemplate(config.NotificationTemplateId) == "" && len(config.NotificationMessageCCList) == 0
+}
+
+// scheduledActionRuleName returns the rule of a scheduled action. Graph leaves the rule name of
+// the default rule empty.
+func scheduledActionRuleName(action clients.ComplianceScheduledAction) string {
+	if action.RuleName == "" {
+		return defaultComplianceRule
+	}
+	return action.RuleName
+}
+
+// scheduledActionTemplate returns the notification template of an action, or "" without one
+func scheduledActionTemplate(id string) string {
+	if strings.EqualFold(id, noNotificationTemplate) {
+		return ""
+	}
+	return id
+}
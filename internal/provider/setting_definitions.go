@@ -0,0 +1,364 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// Setting definition OData types
+const (
+	odataChoiceSettingDefinition           = "#microsoft.graph.deviceManagementConfigurationChoiceSettingDefinition"
+	odataSimpleSettingDefinition           = "#microsoft.graph.deviceManagementConfigurationSimpleSettingDefinition"
+	odataSimpleSettingCollectionDefinition = "#microsoft.graph.deviceManagementConfigurationSimpleSettingCollectionDefinition"
+	odataSettingGroupDefinition            = "#microsoft.graph.deviceManagementConfigurationSettingGroupDefinition"
+	odataSettingGroupCollectionDefinition  = "#microsoft.graph.deviceManagementConfigurationSettingGroupCollectionDefinition"
+	odataIntegerSettingValueDefinition     = "#microsoft.graph.deviceManagementConfigurationIntegerSettingValueDefinition"
+	odataStringSettingValueDefinition      = "#microsoft.graph.deviceManagementConfigurationStringSettingValueDefinition"
+)
+
+// guidPattern matches a GUID without braces
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// SettingDefinitionCache caches Settings Catalog setting definitions for the lifetime of the provider,
+// so each definition is fetched at most once per run
+type SettingDefinitionCache struct {
+	client *clients.GraphClient
+
+	mu          sync.Mutex
+	definitions map[string]*clients.SettingDefinition
+}
+
+// NewSettingDefinitionCache creates an empty setting definition cache
+func NewSettingDefinitionCache(client *clients.GraphClient) *SettingDefinitionCache {
+	return &SettingDefinitionCache{
+		client:      client,
+		definitions: make(map[string]*clients.SettingDefinition),
+	}
+}
+
+// Load returns the definitions with the given IDs, fetching the ones not cached yet. Definitions
+// that do not exist are returned as nil.
+func (c *SettingDefinitionCache) Load(ctx context.Context, ids []string) (map[string]*clients.SettingDefinition, error) {
+	result := make(map[string]*clients.SettingDefinition, len(ids))
+	var missing []string
+
+	c.mu.Lock()
+	for _, id := range ids {
+		if def, ok := c.definitions[id]; ok {
+			result[id] = def
+		} else if _, queued := result[id]; !queued {
+			result[id] = nil
+			missing = append(missing, id)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	fetched, err := c.client.GetSettingDefinitions(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range missing {
+		c.definitions[id] = fetched[id]
+		result[id] = fetched[id]
+	}
+
+	return result, nil
+}
+
+// SettingDefinitionIDs returns the known definition IDs of settings and their children, sorted and without duplicates
+func SettingDefinitionIDs(settings []SettingModel) []string {
+	seen := make(map[string]bool)
+	var collect func([]SettingModel)
+	collect = func(settings []SettingModel) {
+		for _, setting := range settings {
+			if !setting.DefinitionID.IsUnknown() && !setting.DefinitionID.IsNull() {
+				seen[setting.DefinitionID.ValueString()] = true
+			}
+			collect(setting.Children)
+		}
+	}
+	collect(settings)
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ValidateSettingDefinitions checks settings against their Settings Catalog definitions: the
+// definition must exist, the value type must match, and values must satisfy the choice options,
+// integer ranges, string lengths and formats, and occurrence bounds of the definition. Unknown
+// values are skipped.
+func ValidateSettingDefinitions(settings []SettingModel, definitions map[string]*clients.SettingDefinition, p path.Path, diags *diag.Diagnostics) {
+	validateSettingDefinitions(settings, definitions, nil, p, diags)
+}
+
+// validateSettingDefinitions validates the children of parent, or top-level settings when parent is nil
+func validateSettingDefinitions(settings []SettingModel, definitions map[string]*clients.SettingDefinition, parent *clients.SettingDefinition, p path.Path, diags *diag.Diagnostics) {
+	for i, setting := range settings {
+		if setting.DefinitionID.IsUnknown() || setting.DefinitionID.IsNull() {
+			continue
+		}
+
+		settingPath := p.AtListIndex(i)
+		definitionID := setting.DefinitionID.ValueString()
+
+		def, ok := definitions[definitionID]
+		if !ok {
+			continue
+		}
+		if def == nil {
+			diags.AddAttributeError(settingPath.AtName("definition_id"), "Unknown Setting Definition",
+				fmt.Sprintf("The Settings Catalog has no setting definition %q. "+
+					"Use the intune_setting_definition data source to look up definition IDs.", definitionID))
+			continue
+		}
+
+		if parent != nil && len(parent.ChildIds) > 0 && !containsString(parent.ChildIds, definitionID) {
+			diags.AddAttributeError(settingPath.AtName("definition_id"), "Invalid Child Setting",
+				fmt.Sprintf("Setting %q is not a child of %q. Valid children: %s.", definitionID, parent.ID, strings.Join(parent.ChildIds, ", ")))
+			continue
+		}
+
+		expected := definitionValueType(def)
+		if expected != "" && !setting.ValueType.IsUnknown() && setting.ValueType.ValueString() != expected {
+			diags.AddAttributeError(settingPath.AtName("value_type"), "Setting Value Type Mismatch",
+				fmt.Sprintf("Setting %q is a %s setting in the Settings Catalog, but value_type is %q.", definitionID, expected, setting.ValueType.ValueString()))
+			continue
+		}
+
+		// The elements of a group collection are consecutive blocks; count them on the first one
+		if setting.ValueType.ValueString() == SettingValueGroupCollection && (i == 0 || settings[i-1].DefinitionID.ValueString() != definitionID) {
+			count := 0
+			for j := i; j < len(settings) && settings[j].DefinitionID.ValueString() == definitionID; j++ {
+				count++
+			}
+			checkSettingCount(def, count, settingPath, diags)
+		}
+
+		if !setting.Value.IsUnknown() && !setting.Value.IsNull() {
+			validateSettingValue(def, setting, settingPath.AtName("value"), diags)
+		}
+
+		validateSettingDefinitions(setting.Children, definitions, def, settingPath.AtName("children"), diags)
+	}
+}
+
+// definitionValueType returns the value_type matching a setting definition, or an empty string if
+// the provider cannot tell
+func definitionValueType(def *clients.SettingDefinition) string {
+	switch def.ODataType {
+	case odataChoiceSettingDefinition:
+		return SettingValueChoice
+	case odataSimpleSettingCollectionDefinition:
+		return SettingValueCollection
+	case odataSettingGroupDefinition:
+		return SettingValueGroup
+	case odataSettingGroupCollectionDefinition:
+		return SettingValueGroupCollection
+	case odataSimpleSettingDefinition:
+		valueType := ""
+		if def.DefaultValue != nil {
+			valueType = def.DefaultValue.ODataType
+		}
+		if def.ValueDefinition != nil {
+			valueType = def.ValueDefinition.ODataType
+		}
+		switch valueType {
+		case odataIntegerSettingValue, odataIntegerSettingValueDefinition:
+			return SettingValueInteger
+		case odataBooleanSettingValue:
+			return SettingValueBoolean
+		case "":
+			return ""
+		default:
+			return SettingValueString
+		}
+	}
+	return ""
+}
+
+// validateSettingValue checks a configured value against the constraints of its definition
+func validateSettingValue(def *clients.SettingDefinition, setting SettingModel, p path.Path, diags *diag.Diagnostics) {
+	definitionID := setting.DefinitionID.ValueString()
+	value := setting.Value.ValueString()
+
+	switch setting.ValueType.ValueString() {
+	case SettingValueChoice:
+		if len(def.Options) == 0 {
+			return
+		}
+		options := make([]string, 0, len(def.Options))
+		for _, option := range def.Options {
+			if option.ItemId == value {
+				return
+			}
+			options = append(options, option.ItemId)
+		}
+		diags.AddAttributeError(p, "Invalid Choice Option",
+			fmt.Sprintf("%q is not an option of setting %q. Valid options: %s.", value, definitionID, strings.Join(options, ", ")))
+
+	case SettingValueInteger:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			checkIntegerValue(def.ValueDefinition, definitionID, n, p, diags)
+		}
+
+	case SettingValueString:
+		checkStringValue(def.ValueDefinition, definitionID, value, p, diags)
+
+	case SettingValueCollection:
+		values, err := parseCollectionValue(value)
+		if err != nil {
+			return
+		}
+		checkSettingCount(def, len(values), p, diags)
+		for _, v := range values {
+			switch v := v.Value.(type) {
+			case int64:
+				checkIntegerValue(def.ValueDefinition, definitionID, v, p, diags)
+			case string:
+				checkStringValue(def.ValueDefinition, definitionID, v, p, diags)
+			}
+		}
+	}
+}
+
+// checkIntegerValue checks an integer against the range of a value definition
+func checkIntegerValue(valueDef *clients.SettingValueDefinition, definitionID string, n int64, p path.Path, diags *diag.Diagnostics) {
+	if valueDef == nil {
+		return
+	}
+	if (valueDef.MinimumValue != nil && n < *valueDef.MinimumValue) || (valueDef.MaximumValue != nil && n > *valueDef.MaximumValue) {
+		diags.AddAttributeError(p, "Setting Value Out Of Range",
+			fmt.Sprintf("%d is outside the allowed range of setting %q (%s).", n, definitionID, formatBounds(valueDef.MinimumValue, valueDef.MaximumValue)))
+	}
+}
+
+// checkStringValue checks a string against the length and format of a value definition
+func checkStringValue(valueDef *clients.SettingValueDefinition, definitionID, value string, p path.Path, diags *diag.Diagnostics) {
+	if valueDef == nil {
+		return
+	}
+
+	length := int64(len([]rune(value)))
+	if (valueDef.MinimumLength != nil && length < *valueDef.MinimumLength) || (valueDef.MaximumLength != nil && *valueDef.MaximumLength > 0 && length > *valueDef.MaximumLength) {
+		diags.AddAttributeError(p, "Invalid Setting Value Length",
+			fmt.Sprintf("%q has %d characters, setting %q allows %s.", value, length, definitionID, formatBounds(valueDef.MinimumLength, valueDef.MaximumLength)))
+		return
+	}
+
+	var valid bool
+	switch strings.ToLower(valueDef.Format) {
+	case "guid":
+		valid = guidPattern.MatchString(strings.Trim(value, "{}"))
+	case "email":
+		_, err := mail.ParseAddress(value)
+		valid = err == nil
+	case "ip":
+		valid = net.ParseIP(value) != nil
+	case "url":
+		u, err := url.Parse(value)
+		valid = err == nil && u.Scheme != "" && u.Host != ""
+	case "base64":
+		_, err := base64.StdEncoding.DecodeString(value)
+		valid = err == nil
+	case "json":
+		valid = json.Valid([]byte(value))
+	case "regex":
+		// Intune evaluates regular expressions with the .NET engine, whose syntax is a superset of
+		// Go's, so a pattern Go cannot compile is only reported as a warning
+		if _, err := regexp.Compile(value); err != nil {
+			diags.AddAttributeWarning(p, "Possibly Invalid Regular Expression",
+				fmt.Sprintf("Setting %q expects a regular expression, and %q could not be parsed: %s", definitionID, value, err))
+		}
+		return
+	default:
+		return
+	}
+
+	if !valid {
+		diags.AddAttributeError(p, "Invalid Setting Value Format",
+			fmt.Sprintf("%q is not a valid %s, which setting %q requires.", value, valueDef.Format, definitionID))
+	}
+}
+
+// checkSettingCount checks the number of values of a collection or group collection against the
+// count and occurrence bounds of its definition
+func checkSettingCount(def *clients.SettingDefinition, count int, p path.Path, diags *diag.Diagnostics) {
+	minimum, maximum := 0, 0
+	if def.MinimumCount != nil {
+		minimum = *def.MinimumCount
+	}
+	if def.MaximumCount != nil {
+		maximum = *def.MaximumCount
+	}
+	if def.Occurrence != nil {
+		if def.Occurrence.MinDeviceOccurrence > minimum {
+			minimum = def.Occurrence.MinDeviceOccurrence
+		}
+		if def.Occurrence.MaxDeviceOccurrence > 0 && (maximum == 0 || def.Occurrence.MaxDeviceOccurrence < maximum) {
+			maximum = def.Occurrence.MaxDeviceOccurrence
+		}
+	}
+
+	if count < minimum || (maximum > 0 && count > maximum) {
+		min64, max64 := int64(minimum), int64(maximum)
+		upper := &max64
+		if maximum == 0 {
+			upper = nil
+		}
+		diags.AddAttributeError(p, "Invalid Number Of Values",
+			fmt.Sprintf("Setting %q has %d values, the Settings Catalog allows %s.", def.ID, count, formatBounds(&min64, upper)))
+	}
+}
+
+// formatBounds describes an inclusive range with optional bounds
+func formatBounds(minimum, maximum *int64) string {
+	switch {
+	case minimum != nil && maximum != nil:
+		return fmt.Sprintf("%d to %d", *minimum, *maximum)
+	case minimum != nil:
+		return fmt.Sprintf("at least %d", *minimum)
+	case maximum != nil:
+		return fmt.Sprintf("at most %d", *maximum)
+	default:
+		return "any value"
+	}
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// endpointSecurityTemplateFamilies maps endpoint security template types to the families of the
+// configuration policy templates that replaced the intent templates
+var endpointSecurityTemplateFamilies = map[string]string{
+	"antivirus":                    "endpointSecurityAntivirus",
+	"diskEncryption":               "endpointSecurityDiskEncryption",
+	"firewall":                     "endpointSecurityFirewall",
+	"endpointDetectionAndResponse": "endpointSecurityEndpointDetectionAndResponse",
+	"attackSurfaceReduction":       "endpointSecurityAttackSurfaceReduction",
+	"accountProtection":            "endpointSecurityAccountProtection",
+}
+
+// endpointSecurityTemplateTypeForFamily returns the template type of a configuration policy
+// template family, or an empty string for families that are not endpoint security
+func endpointSecurityTemplateTypeForFamily(family string) string {
+	for templateType, templateFamily := range endpointSecurityTemplateFamilies {
+		if templateFamily == family {
+			return templateType
+		}
+	}
+	return ""
+}
+
+// findConfigurationPolicyTemplate returns the newest active template of a family for a platform,
+// or nil if there is none
+func findConfigurationPolicyTemplate(templates []clients.ConfigurationPolicyTemplate, family, platforms string) *clients.ConfigurationPolicyTemplate {
+	var newest *clients.ConfigurationPolicyTemplate
+	for i, template := range templates {
+		if template.TemplateFamily != family || template.Platforms != platforms ||
+			(template.LifecycleState != "" && template.LifecycleState != "active") {
+			continue
+		}
+		if newest == nil || template.Version > newest.Version {
+			newest = &templates[i]
+		}
+	}
+	return newest
+}
+
+// settingTemplateIndex indexes the setting instance templates of a template, including the
+// templates of child settings, by setting definition ID
+func settingTemplateIndex(settingTemplates []clients.ConfigurationSettingTemplate) map[string]*clients.SettingInstanceTemplate {
+	index := make(map[string]*clients.SettingInstanceTemplate)
+	var add func(instances []clients.SettingInstanceTemplate)
+	add = func(instances []clients.SettingInstanceTemplate) {
+		for i := range instances {
+			instance := &instances[i]
+			index[instance.SettingDefinitionId] = instance
+			for _, value := range settingValueTemplates(instance) {
+				add(value.Children)
+			}
+		}
+	}
+
+	for _, settingTemplate := range settingTemplates {
+		if settingTemplate.SettingInstanceTemplate != nil {
+			add([]clients.SettingInstanceTemplate{*settingTemplate.SettingInstanceTemplate})
+		}
+	}
+	return index
+}
+
+// settingValueTemplates returns the value templates of a setting instance template
+func settingValueTemplates(instance *clients.SettingInstanceTemplate) []clients.SettingValueTemplate {
+	var values []clients.SettingValueTemplate
+	for _, value := range []*clients.SettingValueTemplate{instance.SimpleSettingValueTemplate, instance.ChoiceSettingValueTemplate, instance.GroupSettingValueTemplate} {
+		if value != nil {
+			values = append(values, *value)
+		}
+	}
+	values = append(values, instance.SimpleSettingCollectionValueTemplate...)
+	return append(values, instance.GroupSettingCollectionValueTemplate...)
+}
+
+// ApplySettingTemplates adds the setting instance and value template references Graph requires
+// for template-backed policies. Settings the template does not define are reported as errors.
+func ApplySettingTemplates(settings []clients.SettingsCatalogPolicySetting, templateID string, index map[string]*clients.SettingInstanceTemplate, diags *diag.Diagnostics) {
+	for _, setting := range settings {
+		if setting.SettingInstance != nil {
+			applySettingInstanceTemplate(setting.SettingInstance, templateID, index, diags)
+		}
+	}
+}
+
+// applySettingInstanceTemplate adds template references to a setting instance and its children
+func applySettingInstanceTemplate(instance *clients.SettingInstance, templateID string, index map[string]*clients.SettingInstanceTemplate, diags *diag.Diagnostics) {
+	template, ok := index[instance.SettingDefinitionId]
+	if !ok {
+		diags.AddAttributeError(
+			path.Root("setting"),
+			"Setting Not In Template",
+			fmt.Sprintf("Setting %s is not part of template %s. Template-backed policies can only contain the settings of their template.", instance.SettingDefinitionId, templateID),
+		)
+		return
+	}
+
+	instance.SettingInstanceTemplateRef = &clients.SettingInstanceTemplateRef{
+		SettingInstanceTemplateId: template.SettingInstanceTemplateId,
+	}
+
+	var children []clients.SettingInstance
+	switch {
+	case instance.SimpleSettingValue != nil:
+		instance.SimpleSettingValue.SettingValueTemplateReference = settingValueTemplateRef(template.SimpleSettingValueTemplate)
+	case instance.ChoiceSettingValue != nil:
+		instance.ChoiceSettingValue.SettingValueTemplateReference = settingValueTemplateRef(template.ChoiceSettingValueTemplate)
+		children = instance.ChoiceSettingValue.Children
+	case instance.GroupSettingValue != nil:
+		instance.GroupSettingValue.SettingValueTemplateReference = settingValueTemplateRef(template.GroupSettingValueTemplate)
+		children = instance.GroupSettingValue.Children
+	case instance.GroupSettingCollectionValue != nil:
+		for i := range instance.GroupSettingCollectionValue {
+			value := &instance.GroupSettingCollectionValue[i]
+			if len(template.GroupSettingCollectionValueTemplate) > 0 {
+				value.SettingValueTemplateReference = settingValueTemplateRef(&template.GroupSettingCollectionValueTemplate[0])
+			}
+			for j := range value.Children {
+				applySettingInstanceTemplate(&value.Children[j], templateID, index, diags)
+			}
+		}
+	}
+
+	for i := range children {
+		applySettingInstanceTemplate(&children[i], templateID, index, diags)
+	}
+}
+
+// settingValueTemplateRef references a value template, or returns nil if the template has none
+func settingValueTemplateRef(template *clients.SettingValueTemplate) *clients.SettingValueTemplateRef {
+	if template == nil || template.SettingValueTemplateId == "" {
+		return nil
+	}
+	return &clients.SettingValueTemplateRef{SettingValueTemplateId: template.SettingValueTemplateId}
+}
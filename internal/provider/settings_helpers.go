@@ -0,0 +1,571 @@
+// Copyright (c) TofuTune Contributors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/MANCHTOOLS/tofutune/internal/clients"
+)
+
+// MaxSettingDepth is the number of setting levels supported by the nested children blocks.
+// Terraform schemas cannot be recursive, so the children block is repeated this many times.
+const MaxSettingDepth = 10
+
+// Setting value types
+const (
+	SettingValueString          = "string"
+	SettingValueInteger         = "integer"
+	SettingValueBoolean         = "boolean"
+	SettingValueChoice          = "choice"
+	SettingValueCollection      = "collection"
+	SettingValueGroup           = "group"
+	SettingValueGroupCollection = "group_collection"
+)
+
+// Setting instance and value OData types
+const (
+	odataSetting                         = "#microsoft.graph.deviceManagementConfigurationSetting"
+	odataSimpleSettingInstance           = "#microsoft.graph.deviceManagementConfigurationSimpleSettingInstance"
+	odataSimpleSettingCollectionInstance = "#microsoft.graph.deviceManagementConfigurationSimpleSettingCollectionInstance"
+	odataChoiceSettingInstance           = "#microsoft.graph.deviceManagementConfigurationChoiceSettingInstance"
+	odataGroupSettingInstance            = "#microsoft.graph.deviceManagementConfigurationGroupSettingInstance"
+	odataGroupSettingCollectionInstance  = "#microsoft.graph.deviceManagementConfigurationGroupSettingCollectionInstance"
+	odataStringSettingValue              = "#microsoft.graph.deviceManagementConfigurationStringSettingValue"
+	odataIntegerSettingValue             = "#microsoft.graph.deviceManagementConfigurationIntegerSettingValue"
+	odataBooleanSettingValue             = "#microsoft.graph.deviceManagementConfigurationBooleanSettingValue"
+	odataChoiceSettingValue              = "#microsoft.graph.deviceManagementConfigurationChoiceSettingValue"
+	odataGroupSettingValue               = "#microsoft.graph.deviceManagementConfigurationGroupSettingValue"
+)
+
+// settingValueTypes lists the valid values of value_type
+var settingValueTypes = []string{
+	SettingValueString,
+	SettingValueInteger,
+	SettingValueBoolean,
+	SettingValueChoice,
+	SettingValueCollection,
+	SettingValueGroup,
+	SettingValueGroupCollection,
+}
+
+// SettingModel represents a setting and its nested child settings
+type SettingModel struct {
+	DefinitionID types.String
+	ValueType    types.String
+	Value        types.String
+	Children     []SettingModel
+}
+
+// SettingModelAttrTypes returns the attribute types of a setting block at the given depth,
+// where top-level settings have depth 1
+func SettingModelAttrTypes(depth int) map[string]attr.Type {
+	attrTypes := map[string]attr.Type{
+		"definition_id": types.StringType,
+		"value_type":    types.StringType,
+		"value":         types.StringType,
+	}
+	if depth < MaxSettingDepth {
+		attrTypes["children"] = types.ListType{ElemType: types.ObjectType{AttrTypes: SettingModelAttrTypes(depth + 1)}}
+	}
+	return attrTypes
+}
+
+// SettingBlockSchema returns the schema of a setting block at the given depth
+func SettingBlockSchema(depth int) schema.ListNestedBlock {
+	block := schema.ListNestedBlock{
+		Description: "A setting to include in the policy.",
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"definition_id": schema.StringAttribute{
+					Description: "The setting definition ID. This identifies the specific setting in the Settings Catalog.",
+					Required:    true,
+				},
+				"value_type": schema.StringAttribute{
+					Description: "The type of value. Valid values: string, integer, boolean, choice, collection, group, group_collection.",
+					Required:    true,
+					Validators: []validator.String{
+						stringvalidator.OneOf(settingValueTypes...),
+					},
+				},
+				"value": schema.StringAttribute{
+					Description: "The value for the setting. For boolean, use 'true' or 'false'. " +
+						"For choice, use the choice option ID. For collection, use a JSON array string. " +
+						"Not used for group and group_collection settings.",
+					Optional: true,
+				},
+			},
+		},
+	}
+	if depth > 1 {
+		block.Description = "A child setting of a choice, group or group_collection setting."
+	}
+	if depth < MaxSettingDepth {
+		block.NestedObject.Blocks = map[string]schema.Block{
+			"children": SettingBlockSchema(depth + 1),
+		}
+	}
+	return block
+}
+
+// SettingModelsFromList reads the setting blocks of a list value, including their children
+func SettingModelsFromList(list types.List) []SettingModel {
+	if list.IsNull() || list.IsUnknown() {
+		return nil
+	}
+
+	settings := make([]SettingModel, 0, len(list.Elements()))
+	for _, elem := range list.Elements() {
+		obj, ok := elem.(types.Object)
+		if !ok || obj.IsNull() || obj.IsUnknown() {
+			continue
+		}
+
+		attrs := obj.Attributes()
+		setting := SettingModel{
+			DefinitionID: stringAttr(attrs, "definition_id"),
+			ValueType:    stringAttr(attrs, "value_type"),
+			Value:        stringAttr(attrs, "value"),
+		}
+		if children, ok := attrs["children"].(types.List); ok {
+			setting.Children = SettingModelsFromList(children)
+		}
+		settings = append(settings, setting)
+	}
+
+	return settings
+}
+
+// SettingModelsToList builds the list value of setting blocks at the given depth. Children are
+// left null when a setting has none, as Terraform does for an omitted block.
+func SettingModelsToList(settings []SettingModel, depth int, diags *diag.Diagnostics) types.List {
+	elemType := types.ObjectType{AttrTypes: SettingModelAttrTypes(depth)}
+	if len(settings) == 0 {
+		return types.ListNull(elemType)
+	}
+
+	elems := make([]attr.Value, 0, len(settings))
+	for _, setting := range settings {
+		attrs := map[string]attr.Value{
+			"definition_id": setting.DefinitionID,
+			"value_type":    setting.ValueType,
+			"value":         setting.Value,
+		}
+		if depth < MaxSettingDepth {
+			attrs["children"] = SettingModelsToList(setting.Children, depth+1, diags)
+		} else if len(setting.Children) > 0 {
+			diags.AddError(
+				"Setting Nested Too Deeply",
+				fmt.Sprintf("Setting %s has children nested deeper than the supported %d levels.", setting.DefinitionID.ValueString(), MaxSettingDepth),
+			)
+		}
+
+		obj, d := types.ObjectValue(elemType.AttrTypes, attrs)
+		diags.Append(d...)
+		elems = append(elems, obj)
+	}
+
+	list, d := types.ListValue(elemType, elems)
+	diags.Append(d...)
+	return list
+}
+
+// stringAttr returns a string attribute of an object, or null if it is missing
+func stringAttr(attrs map[string]attr.Value, name string) types.String {
+	if v, ok := attrs[name].(types.String); ok {
+		return v
+	}
+	return types.StringNull()
+}
+
+// BuildSettingInstances converts setting models into API settings. Errors are reported at the
+// path of the offending block, below the given path.
+func BuildSettingInstances(settings []SettingModel, p path.Path, diags *diag.Diagnostics) []clients.SettingsCatalogPolicySetting {
+	var result []clients.SettingsCatalogPolicySetting
+	for _, instance := range buildSettingInstanceList(settings, p, diags) {
+		instance := instance
+		result = append(result, clients.SettingsCatalogPolicySetting{
+			ODataType:       odataSetting,
+			SettingInstance: &instance,
+		})
+	}
+	return result
+}
+
+// buildSettingInstanceList converts setting models into setting instances. Consecutive
+// group_collection settings with the same definition ID become the values of a single
+// group collection instance.
+func buildSettingInstanceList(settings []SettingModel, p path.Path, diags *diag.Diagnostics) []clients.SettingInstance {
+	var result []clients.SettingInstance
+
+	for i, setting := range settings {
+		settingPath := p.AtListIndex(i)
+
+		// Further elements of a group collection extend the previous instance
+		if setting.ValueType.ValueString() == SettingValueGroupCollection && len(result) > 0 {
+			previous := &result[len(result)-1]
+			if previous.ODataType == odataGroupSettingCollectionInstance && previous.SettingDefinitionId == setting.DefinitionID.ValueString() {
+				previous.GroupSettingCollectionValue = append(previous.GroupSettingCollectionValue, clients.GroupSettingValue{
+					ODataType: odataGroupSettingValue,
+					Children:  buildSettingInstanceList(setting.Children, settingPath.AtName("children"), diags),
+				})
+				continue
+			}
+		}
+
+		if instance := buildSettingInstance(setting, settingPath, diags); instance != nil {
+			result = append(result, *instance)
+		}
+	}
+
+	return result
+}
+
+// buildSettingInstance converts a single setting model into an API setting instance
+func buildSettingInstance(setting SettingModel, p path.Path, diags *diag.Diagnostics) *clients.SettingInstance {
+	instance := &clients.SettingInstance{
+		SettingDefinitionId: setting.DefinitionID.ValueString(),
+	}
+
+	valueType := setting.ValueType.ValueString()
+	value := setting.Value.ValueString()
+	childrenPath := p.AtName("children")
+
+	if !checkSettingShape(setting, p, diags) {
+		return nil
+	}
+
+	switch valueType {
+	case SettingValueString, SettingValueInteger, SettingValueBoolean:
+		simpleValue := buildSimpleSettingValue(valueType, value, p.AtName("value"), diags)
+		if simpleValue == nil {
+			return nil
+		}
+		instance.ODataType = odataSimpleSettingInstance
+		instance.SimpleSettingValue = simpleValue
+
+	case SettingValueChoice:
+		instance.ODataType = odataChoiceSettingInstance
+		instance.ChoiceSettingValue = &clients.ChoiceSettingValue{
+			ODataType: odataChoiceSettingValue,
+			Value:     value,
+			Children:  buildSettingInstanceList(setting.Children, childrenPath, diags),
+		}
+
+	case SettingValueCollection:
+		values, err := parseCollectionValue(value)
+		if err != nil {
+			diags.AddAttributeError(p.AtName("value"), "Invalid Collection Value", fmt.Sprintf("The collection value is invalid: %s", err))
+			return nil
+		}
+		instance.ODataType = odataSimpleSettingCollectionInstance
+		instance.SimpleSettingCollectionValue = values
+
+	case SettingValueGroup:
+		instance.ODataType = odataGroupSettingInstance
+		instance.GroupSettingValue = &clients.GroupSettingValue{
+			ODataType: odataGroupSettingValue,
+			Children:  buildSettingInstanceList(setting.Children, childrenPath, diags),
+		}
+
+	case SettingValueGroupCollection:
+		instance.ODataType = odataGroupSettingCollectionInstance
+		instance.GroupSettingCollectionValue = []clients.GroupSettingValue{
+			{
+				ODataType: odataGroupSettingValue,
+				Children:  buildSettingInstanceList(setting.Children, childrenPath, diags),
+			},
+		}
+
+	default:
+		diags.AddAttributeError(p.AtName("value_type"), "Invalid Setting Value Type",
+			fmt.Sprintf("Setting %s has unsupported value type %q.", instance.SettingDefinitionId, valueType))
+		return nil
+	}
+
+	return instance
+}
+
+// checkSettingShape checks that a setting sets value and children as its value type requires.
+// Unknown values are accepted.
+func checkSettingShape(setting SettingModel, p path.Path, diags *diag.Diagnostics) bool {
+	if setting.ValueType.IsUnknown() {
+		return true
+	}
+
+	definitionID := setting.DefinitionID.ValueString()
+	valueType := setting.ValueType.ValueString()
+
+	switch valueType {
+	case SettingValueString, SettingValueInteger, SettingValueBoolean, SettingValueChoice, SettingValueCollection:
+		if setting.Value.IsNull() {
+			diags.AddAttributeError(p.AtName("value"), "Missing Setting Value",
+				fmt.Sprintf("Setting %s of type %s requires a value.", definitionID, valueType))
+			return false
+		}
+	default:
+		if !setting.Value.IsNull() {
+			diags.AddAttributeError(p.AtName("value"), "Unexpected Setting Value",
+				fmt.Sprintf("Setting %s of type %s takes its values from child settings and must not set value.", definitionID, valueType))
+			return false
+		}
+	}
+
+	switch valueType {
+	case SettingValueChoice, SettingValueGroup, SettingValueGroupCollection:
+	default:
+		if len(setting.Children) > 0 {
+			diags.AddAttributeError(p.AtName("children"), "Unexpected Child Settings",
+				fmt.Sprintf("Setting %s of type %s cannot have child settings. Only choice, group and group_collection settings have children.", definitionID, valueType))
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidateSettings checks the structure and value syntax of settings and their children without
+// consulting the Settings Catalog. Unknown values are skipped.
+func ValidateSettings(settings []SettingModel, p path.Path, diags *diag.Diagnostics) {
+	for i, setting := range settings {
+		settingPath := p.AtListIndex(i)
+		if !checkSettingShape(setting, settingPath, diags) {
+			continue
+		}
+
+		if !setting.ValueType.IsUnknown() && !setting.Value.IsNull() && !setting.Value.IsUnknown() {
+			valueType := setting.ValueType.ValueString()
+			switch valueType {
+			case SettingValueInteger, SettingValueBoolean:
+				buildSimpleSettingValue(valueType, setting.Value.ValueString(), settingPath.AtName("value"), diags)
+			case SettingValueCollection:
+				if _, err := parseCollectionValue(setting.Value.ValueString()); err != nil {
+					diags.AddAttributeError(settingPath.AtName("value"), "Invalid Collection Value", fmt.Sprintf("The collection value is invalid: %s", err))
+				}
+			}
+		}
+
+		ValidateSettings(setting.Children, settingPath.AtName("children"), diags)
+	}
+}
+
+// parseCollectionValue parses the value of a collection setting, a JSON array of strings or of integers
+func parseCollectionValue(value string) ([]clients.SimpleSettingValue, error) {
+	var values []interface{}
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("could not parse '%s' as JSON array: %s", value, err)
+	}
+
+	result := make([]clients.SimpleSettingValue, 0, len(values))
+	for _, v := range values {
+		switch v := v.(type) {
+		case json.Number:
+			intVal, err := v.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("element %s is not an integer: %s", v, err)
+			}
+			result = append(result, clients.SimpleSettingValue{ODataType: odataIntegerSettingValue, Value: intVal})
+		case string:
+			result = append(result, clients.SimpleSettingValue{ODataType: odataStringSettingValue, Value: v})
+		default:
+			return nil, fmt.Errorf("element %v must be a string or an integer", v)
+		}
+	}
+
+	return result, nil
+}
+
+// buildSimpleSettingValue converts a string, integer or boolean value into an API value
+func buildSimpleSettingValue(valueType, value string, p path.Path, diags *diag.Diagnostics) *clients.SimpleSettingValue {
+	switch valueType {
+	case SettingValueInteger:
+		intVal, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			diags.AddAttributeError(p, "Invalid Integer Value", fmt.Sprintf("Could not parse '%s' as integer: %s", value, err))
+			return nil
+		}
+		return &clients.SimpleSettingValue{ODataType: odataIntegerSettingValue, Value: intVal}
+
+	case SettingValueBoolean:
+		boolVal, err := strconv.ParseBool(value)
+		if err != nil {
+			diags.AddAttributeError(p, "Invalid Boolean Value", fmt.Sprintf("Could not parse '%s' as boolean, use 'true' or 'false'", value))
+			return nil
+		}
+		return &clients.SimpleSettingValue{ODataType: odataBooleanSettingValue, Value: boolVal}
+
+	default:
+		return &clients.SimpleSettingValue{ODataType: odataStringSettingValue, Value: value}
+	}
+}
+
+// FlattenSettingInstances converts API settings into setting models. Setting types the provider
+// does not model are skipped and returned as unsupported definition IDs.
+func FlattenSettingInstances(apiSettings []clients.SettingsCatalogPolicySetting) ([]SettingModel, []string) {
+	instances := make([]clients.SettingInstance, 0, len(apiSettings))
+	for _, apiSetting := range apiSettings {
+		if apiSetting.SettingInstance != nil {
+			instances = append(instances, *apiSetting.SettingInstance)
+		}
+	}
+	return flattenSettingInstanceList(instances)
+}
+
+// flattenSettingInstanceList converts setting instances into setting models. Each value of a
+// group collection becomes its own group_collection setting, mirroring buildSettingInstanceList.
+func flattenSettingInstanceList(instances []clients.SettingInstance) ([]SettingModel, []string) {
+	var settings []SettingModel
+	var unsupported []string
+
+	for _, instance := range instances {
+
+		setting := SettingModel{
+			DefinitionID: types.StringValue(instance.SettingDefinitionId),
+			Value:        types.StringNull(),
+		}
+
+		switch {
+		case instance.SimpleSettingValue != nil:
+			setting.ValueType, setting.Value = flattenSimpleSettingValue(instance.SimpleSettingValue)
+
+		case instance.ChoiceSettingValue != nil:
+			setting.ValueType = types.StringValue(SettingValueChoice)
+			setting.Value = types.StringValue(instance.ChoiceSettingValue.Value)
+			children, childUnsupported := flattenSettingInstanceList(instance.ChoiceSettingValue.Children)
+			setting.Children = children
+			unsupported = append(unsupported, childUnsupported...)
+
+		case instance.SimpleSettingCollectionValue != nil || instance.ODataType == odataSimpleSettingCollectionInstance:
+			values := make([]interface{}, 0, len(instance.SimpleSettingCollectionValue))
+			for _, v := range instance.SimpleSettingCollectionValue {
+				if v.ODataType == odataIntegerSettingValue {
+					values = append(values, json.RawMessage(formatSettingValue(v.Value)))
+				} else {
+					values = append(values, formatSettingValue(v.Value))
+				}
+			}
+			jsonBytes, _ := json.Marshal(values)
+			setting.ValueType = types.StringValue(SettingValueCollection)
+			setting.Value = types.StringValue(string(jsonBytes))
+
+		case instance.GroupSettingValue != nil:
+			setting.ValueType = types.StringValue(SettingValueGroup)
+			children, childUnsupported := flattenSettingInstanceList(instance.GroupSettingValue.Children)
+			setting.Children = children
+			unsupported = append(unsupported, childUnsupported...)
+
+		case instance.GroupSettingCollectionValue != nil:
+			for _, group := range instance.GroupSettingCollectionValue {
+				children, childUnsupported := flattenSettingInstanceList(group.Children)
+				unsupported = append(unsupported, childUnsupported...)
+				settings = append(settings, SettingModel{
+					DefinitionID: setting.DefinitionID,
+					ValueType:    types.StringValue(SettingValueGroupCollection),
+					Value:        types.StringNull(),
+					Children:     children,
+				})
+			}
+			continue
+
+		default:
+			unsupported = append(unsupported, instance.SettingDefinitionId)
+			continue
+		}
+
+		settings = append(settings, setting)
+	}
+
+	return settings, unsupported
+}
+
+// flattenSimpleSettingValue returns the value type and value of a simple setting value
+func flattenSimpleSettingValue(ssv *clients.SimpleSettingValue) (types.String, types.String) {
+	switch ssv.ODataType {
+	case odataIntegerSettingValue:
+		return types.StringValue(SettingValueInteger), types.StringValue(formatSettingValue(ssv.Value))
+
+	case odataBooleanSettingValue:
+		return types.StringValue(SettingValueBoolean), types.StringValue(formatSettingValue(ssv.Value))
+
+	default:
+		// Secret and other string-like values are treated as strings
+		return types.StringValue(SettingValueString), types.StringValue(formatSettingValue(ssv.Value))
+	}
+}
+
+// formatSettingValue formats a decoded JSON value without switching numbers to exponent notation
+func formatSettingValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// PreserveSettingValues keeps the configured spelling of values that are semantically equal to
+// the values read from the API, such as collections with different JSON formatting or booleans
+// written as "True". Settings are matched by position and definition ID.
+func PreserveSettingValues(prior, read []SettingModel) {
+	for i := range read {
+		if i >= len(prior) || prior[i].DefinitionID.ValueString() != read[i].DefinitionID.ValueString() ||
+			prior[i].ValueType.ValueString() != read[i].ValueType.ValueString() {
+			continue
+		}
+
+		if settingValuesEqual(read[i].ValueType.ValueString(), prior[i].Value, read[i].Value) {
+			read[i].Value = prior[i].Value
+		}
+		PreserveSettingValues(prior[i].Children, read[i].Children)
+	}
+}
+
+// settingValuesEqual reports whether two values of the given type are semantically equal
+func settingValuesEqual(valueType string, a, b types.String) bool {
+	if a.IsNull() || a.IsUnknown() || b.IsNull() || b.IsUnknown() {
+		return false
+	}
+
+	switch valueType {
+	case SettingValueBoolean:
+		x, errX := strconv.ParseBool(a.ValueString())
+		y, errY := strconv.ParseBool(b.ValueString())
+		return errX == nil && errY == nil && x == y
+
+	case SettingValueInteger:
+		x, errX := strconv.ParseInt(a.ValueString(), 10, 64)
+		y, errY := strconv.ParseInt(b.ValueString(), 10, 64)
+		return errX == nil && errY == nil && x == y
+
+	case SettingValueCollection:
+		var x, y []interface{}
+		if json.Unmarshal([]byte(a.ValueString()), &x) != nil || json.Unmarshal([]byte(b.ValueString()), &y) != nil {
+			return false
+		}
+		return reflect.DeepEqual(x, y)
+
+	default:
+		return a.ValueString() == b.ValueString()
+	}
+}